@@ -19,6 +19,7 @@ import (
 	"github.com/garrettallen/aiboards/backend/internal/database"
 	"github.com/garrettallen/aiboards/backend/internal/database/repository"
 	"github.com/garrettallen/aiboards/backend/internal/handlers"
+	"github.com/garrettallen/aiboards/backend/internal/metrics"
 	"github.com/garrettallen/aiboards/backend/internal/middleware"
 	"github.com/garrettallen/aiboards/backend/internal/services"
 	"github.com/garrettallen/aiboards/backend/pkg/migration"
@@ -69,6 +70,14 @@ func main() {
 		log.Printf("Warning: Failed to ensure admin user: %v", err)
 	}
 
+	// Start background workers
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go app.runDigestWorker(workerCtx)
+	if cfg.SoftDeleteRetention > 0 {
+		go app.runPurgeWorker(workerCtx)
+	}
+
 	// Start server
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -82,12 +91,35 @@ func main() {
 
 	// Start the server in a goroutine
 	go func() {
+		if cfg.TLSEnabled() {
+			log.Printf("Server starting with TLS on port %s in %s mode", port, cfg.Environment)
+			if err := srv.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Failed to start server: %v", err)
+			}
+			return
+		}
+
 		log.Printf("Server starting on port %s in %s mode", port, cfg.Environment)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Failed to start server: %v", err)
 		}
 	}()
 
+	// Optionally serve plain HTTP alongside HTTPS; the router redirects it to HTTPS
+	var redirectSrv *http.Server
+	if cfg.TLSEnabled() && cfg.HTTPRedirectPort > 0 {
+		redirectSrv = &http.Server{
+			Addr:    fmt.Sprintf(":%d", cfg.HTTPRedirectPort),
+			Handler: app.Router,
+		}
+		go func() {
+			log.Printf("Redirecting HTTP on port %d to HTTPS", cfg.HTTPRedirectPort)
+			if err := redirectSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Failed to start HTTP redirect server: %v", err)
+			}
+		}()
+	}
+
 	// Wait for interrupt signal to gracefully shut down the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -99,6 +131,11 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	if redirectSrv != nil {
+		if err := redirectSrv.Shutdown(ctx); err != nil {
+			log.Printf("HTTP redirect server forced to shutdown: %v", err)
+		}
+	}
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
@@ -132,16 +169,70 @@ func NewApp(db *sqlx.DB, cfg *config.Config) *App {
 	return app
 }
 
+// runDigestWorker sends the daily notification digests that are due, at startup and then
+// every services.DigestCheckInterval until ctx is cancelled. Whether an agent is due depends
+// on when its last digest was sent, so restarts neither skip nor repeat a day.
+func (a *App) runDigestWorker(ctx context.Context) {
+	ticker := time.NewTicker(services.DigestCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		sent, err := a.Services.Notification.SendDailyDigests(ctx)
+		if err != nil {
+			log.Printf("Failed to send daily digests: %v", err)
+		} else if sent > 0 {
+			log.Printf("Sent %d daily digest emails", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeInterval is how often soft-deleted content past the retention window is purged. The
+// purge is idempotent, so running it often only keeps restarts from postponing it.
+const purgeInterval = time.Hour
+
+// runPurgeWorker hard-deletes soft-deleted content past the retention window, at startup and
+// then every purgeInterval until ctx is cancelled
+func (a *App) runPurgeWorker(ctx context.Context) {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		result, err := a.Services.Admin.PurgeSoftDeleted(ctx, a.Config.SoftDeleteRetention)
+		if err != nil {
+			log.Printf("Failed to purge soft-deleted content: %v", err)
+		} else if result.PostsPurged+result.RepliesPurged+result.VotesPurged > 0 {
+			log.Printf("Purged %d posts, %d replies and %d votes deleted before %s",
+				result.PostsPurged, result.RepliesPurged, result.VotesPurged, result.Cutoff.Format(time.RFC3339))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // Repositories holds all repository instances
 type Repositories struct {
-	User         repository.UserRepository
-	Agent        repository.AgentRepository
-	Board        repository.BoardRepository
-	Post         repository.PostRepository
-	Reply        repository.ReplyRepository
-	Vote         repository.VoteRepository
-	Notification repository.NotificationRepository
-	BetaCode     repository.BetaCodeRepository
+	User                   repository.UserRepository
+	Agent                  repository.AgentRepository
+	Board                  repository.BoardRepository
+	Post                   repository.PostRepository
+	Reply                  repository.ReplyRepository
+	Vote                   repository.VoteRepository
+	Notification           repository.NotificationRepository
+	BetaCode               repository.BetaCodeRepository
+	Webhook                repository.WebhookRepository
+	NotificationPreference repository.NotificationPreferenceRepository
+	AuditLog               repository.AuditLogRepository
+	RateLimit              repository.RateLimitRepository
 }
 
 // Services holds all service instances
@@ -156,6 +247,11 @@ type Services struct {
 	Notification services.NotificationService
 	BetaCode     services.BetaCodeService
 	Storage      services.StorageService
+	Webhook      services.WebhookService
+	Email        services.EmailService
+	Audit        services.AuditService
+	Admin        services.AdminService
+	Renderer     services.MarkdownRenderer
 }
 
 // Handlers holds all handler instances
@@ -171,19 +267,29 @@ type Handlers struct {
 	Notification *handlers.NotificationHandler
 	Media        *handlers.MediaHandler
 	Admin        *handlers.AdminHandler
+	Webhook      *handlers.WebhookHandler
+	Feed         *handlers.FeedHandler
+	Metrics      *handlers.MetricsHandler
+	Render       *handlers.RenderHandler
 }
 
 // initRepositories initializes all repositories
 func (a *App) initRepositories() {
+	repository.SetSlowQueryThreshold(a.Config.SlowQueryThreshold)
+
 	a.Repositories = &Repositories{
-		User:         repository.NewUserRepository(a.DB),
-		Agent:        repository.NewAgentRepository(a.DB),
-		Board:        repository.NewBoardRepository(a.DB),
-		Post:         repository.NewPostRepository(a.DB),
-		Reply:        repository.NewReplyRepository(a.DB),
-		Vote:         repository.NewVoteRepository(a.DB),
-		Notification: repository.NewNotificationRepository(a.DB),
-		BetaCode:     repository.NewBetaCodeRepository(a.DB),
+		User:                   repository.NewUserRepository(a.DB),
+		Agent:                  repository.NewAgentRepository(a.DB),
+		Board:                  repository.NewBoardRepository(a.DB),
+		Post:                   repository.NewPostRepository(a.DB),
+		Reply:                  repository.NewReplyRepository(a.DB),
+		Vote:                   repository.NewVoteRepository(a.DB),
+		Notification:           repository.NewNotificationRepository(a.DB),
+		BetaCode:               repository.NewBetaCodeRepository(a.DB),
+		Webhook:                repository.NewWebhookRepository(a.DB),
+		NotificationPreference: repository.NewNotificationPreferenceRepository(a.DB),
+		AuditLog:               repository.NewAuditLogRepository(a.DB),
+		RateLimit:              repository.NewRateLimitRepository(a.DB),
 	}
 }
 
@@ -198,9 +304,6 @@ func (a *App) initServices() {
 		}
 	}
 
-	accessTokenExpiry := 1 * time.Hour
-	refreshTokenExpiry := 7 * 24 * time.Hour
-
 	// Initialize services with proper dependencies
 	a.Services = &Services{}
 
@@ -211,32 +314,56 @@ func (a *App) initServices() {
 	}
 	a.Services.Storage = storageService
 
+	// Initialize content filter
+	contentFilter := services.NewBlocklistFilter(a.Config.ContentBlocklist, services.ContentFilterAction(a.Config.ContentFilterAction))
+
+	// Initialize content sanitizer; without one content is stored as written
+	var contentSanitizer services.ContentSanitizer
+	if a.Config.ContentSanitizeEnabled {
+		contentSanitizer = services.NewHTMLSanitizer()
+	}
+
 	// Initialize services in the correct order to handle dependencies
 	a.Services.User = services.NewUserService(a.Repositories.User)
 	a.Services.BetaCode = services.NewBetaCodeService(a.Repositories.BetaCode, a.Repositories.User)
-	a.Services.Auth = services.NewAuthService(a.Repositories.User, a.Repositories.BetaCode, jwtSecret, accessTokenExpiry, refreshTokenExpiry)
-	a.Services.Agent = services.NewAgentService(a.Repositories.Agent, a.Repositories.User)
-	a.Services.Board = services.NewBoardService(a.Repositories.Board, a.Repositories.Agent)
-	a.Services.Post = services.NewPostService(a.Repositories.Post, a.Repositories.Board, a.Repositories.Agent, a.Services.Agent)
-	a.Services.Reply = services.NewReplyService(a.Repositories.Reply, a.Repositories.Post, a.Repositories.Agent, a.Services.Agent)
-	a.Services.Vote = services.NewVoteService(a.Repositories.Vote, a.Repositories.Post, a.Repositories.Reply, a.Repositories.Agent)
-	a.Services.Notification = services.NewNotificationService(a.Repositories.Notification, a.Repositories.User, a.Repositories.Agent)
+	a.Services.Auth = services.NewAuthService(a.Repositories.User, a.Repositories.BetaCode, jwtSecret, a.Config.AccessTokenDuration, a.Config.RefreshTokenDuration, a.Config.JWTIssuer, a.Config.JWTAudience)
+	a.Services.Agent = services.NewAgentService(a.Repositories.Agent, a.Repositories.User, a.Repositories.Board, a.Repositories.Post, a.Repositories.Reply, a.Repositories.Vote, a.Config.MaxAgentsPerUser, a.Config.DefaultAgentDailyLimit, a.Config.APIKeyGracePeriod, a.Config.ReservedAgentNames)
+	a.Services.Audit = services.NewAuditService(a.Repositories.AuditLog)
+	a.Services.Post = services.NewPostService(a.Repositories.Post, a.Repositories.Board, a.Repositories.Agent, a.Repositories.Reply, a.Services.Agent, a.Config.MaxPostLength, contentFilter, contentSanitizer, a.Config.DuplicatePostWindow, a.Services.Audit)
+	if a.Config.BoardCacheEnabled {
+		a.Services.Board = services.NewCachedBoardService(a.Repositories.Board, a.Repositories.Agent, a.Repositories.User, a.Services.Post, a.Config.MaxBoardsPerUser, a.Config.BoardCacheTTL)
+	} else {
+		a.Services.Board = services.NewBoardService(a.Repositories.Board, a.Repositories.Agent, a.Repositories.User, a.Services.Post, a.Config.MaxBoardsPerUser)
+	}
+	a.Services.Email = services.NewEmailService(a.Config)
+	a.Services.Notification = services.NewNotificationService(a.Repositories.Notification, a.Repositories.NotificationPreference, a.Repositories.User, a.Repositories.Agent, a.Services.Email, a.Config.NotificationDedupWindow)
+	a.Services.Reply = services.NewReplyService(a.Repositories.Reply, a.Repositories.Post, a.Repositories.Board, a.Repositories.Agent, a.Services.Agent, a.Config.MaxReplyLength, contentFilter, contentSanitizer, a.Config.ReplyEditWindow, a.Services.Notification)
+	a.Services.Vote = services.NewVoteService(a.Repositories.Vote, a.Repositories.Post, a.Repositories.Reply, a.Repositories.Board, a.Repositories.Agent, a.Services.Notification)
+	a.Services.Webhook = services.NewWebhookService(a.Repositories.Webhook, a.Repositories.Post, a.Repositories.Reply, a.Repositories.Agent, a.Config.WebhookAllowPrivateAddresses)
+	a.Services.Admin = services.NewAdminService(a.Repositories.Post, a.Repositories.Reply, a.Repositories.Vote)
+	a.Services.Renderer = services.NewMarkdownRenderer(contentSanitizer, a.Config.MaxPostLength)
 }
 
 // initHandlers initializes all handlers
 func (a *App) initHandlers() {
+	handlers.SetMaxPageSize(a.Config.MaxPageSize)
+
 	a.Handlers = &Handlers{
 		Auth:         handlers.NewAuthHandler(a.Services.Auth),
 		User:         handlers.NewUserHandler(a.Services.User, a.Services.Auth),
 		Agent:        handlers.NewAgentHandler(a.Services.Agent),
 		BetaCode:     handlers.NewBetaCodeHandler(a.Services.BetaCode),
-		Board:        handlers.NewBoardHandler(a.Services.Board),
-		Post:         handlers.NewPostHandler(a.Services.Post),
-		Reply:        handlers.NewReplyHandler(a.Services.Reply),
-		Vote:         handlers.NewVoteHandler(a.Services.Vote),
+		Board:        handlers.NewBoardHandler(a.Services.Board, a.Services.Agent),
+		Post:         handlers.NewPostHandler(a.Services.Post, a.Services.Agent),
+		Reply:        handlers.NewReplyHandler(a.Services.Reply, a.Services.Agent, a.Services.Webhook),
+		Vote:         handlers.NewVoteHandler(a.Services.Vote, a.Services.Webhook),
 		Notification: handlers.NewNotificationHandler(a.Services.Notification),
 		Media:        handlers.NewMediaHandler(a.Services.Storage),
-		Admin:        handlers.NewAdminHandler(a.Services.User, a.Services.Agent, a.Services.Board, a.Services.Post, a.Services.Reply),
+		Admin:        handlers.NewAdminHandler(a.Services.User, a.Services.Agent, a.Services.Board, a.Services.Post, a.Services.Reply, a.Services.Auth, a.Services.Audit, a.Services.Admin, a.Services.Notification, a.Config.SoftDeleteRetention, a.Config.BoardInactivityPeriod),
+		Webhook:      handlers.NewWebhookHandler(a.Services.Webhook),
+		Feed:         handlers.NewFeedHandler(a.Services.Board, a.Services.Post, a.Services.Agent),
+		Metrics:      handlers.NewMetricsHandler(metrics.Default, a.Config.MetricsToken),
+		Render:       handlers.NewRenderHandler(a.Services.Renderer),
 	}
 }
 
@@ -244,9 +371,27 @@ func (a *App) initHandlers() {
 func (a *App) setupRouter() {
 	router := gin.Default()
 
+	// Handlers pass the gin context itself to services, so it has to carry the request
+	// context's deadline and cancellation, which the timeout middleware relies on
+	router.ContextWithFallback = true
+
+	// Only believe X-Forwarded-For from configured proxies, so clients can't spoof their IP
+	if err := router.SetTrustedProxies(a.Config.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
+	// When serving HTTPS, send plain HTTP requests to the HTTPS port and enable HSTS
+	if a.Config.TLSEnabled() {
+		router.Use(middleware.HTTPSRedirect(a.Config.Port))
+		router.Use(middleware.HSTS(a.Config.HSTSMaxAge))
+	}
+
 	// Set up CORS
 	router.Use(middleware.CORS())
 
+	// Record request metrics
+	router.Use(middleware.Metrics())
+
 	// Create middleware
 	authMiddleware := middleware.AuthMiddleware(a.Services.Auth)
 	adminMiddleware := middleware.AdminMiddleware(a.Services.User)
@@ -257,7 +402,37 @@ func (a *App) setupRouter() {
 	if rateLimit <= 0 {
 		rateLimit = 100 // Default to 100 requests per minute
 	}
-	globalRateLimiter := middleware.GlobalRateLimiter(rateLimit)
+	limiter := middleware.NewMemoryRateLimiter()
+	if a.Config.RateLimitStore == "database" {
+		limiter = middleware.NewStoreRateLimiter(a.Repositories.RateLimit)
+	}
+	globalRateLimiter := middleware.GlobalRateLimiter(limiter, rateLimit, a.Config.AdminRateLimit, a.Services.Auth)
+	authRateLimiter := middleware.IPRateLimiter(limiter, "auth", a.Config.AuthRateLimit, a.Config.AuthRateLimitWindow)
+	renderRateLimiter := middleware.IPRateLimiter(limiter, "render", a.Config.RenderRateLimit, time.Minute)
+
+	// Configure request body limits from config; media uploads get a higher limit
+	maxBodySize := a.Config.MaxRequestBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = middleware.DefaultMaxBodySize
+	}
+	maxUploadSize := a.Config.MaxUploadBodySize
+	if maxUploadSize <= 0 {
+		maxUploadSize = middleware.DefaultMaxUploadSize
+	}
+	bodySizeLimiter := middleware.MaxBodySizeWithOverrides(maxBodySize, map[string]int64{
+		"/api/v1/media/upload": maxUploadSize,
+	})
+
+	// Cancel requests that run past the timeout; uploads of large files and streamed
+	// data exports are exempt
+	var requestTimeout gin.HandlerFunc
+	if a.Config.RequestTimeout > 0 {
+		requestTimeout = middleware.TimeoutWithExemptions(a.Config.RequestTimeout, []string{
+			"/api/v1/media/upload",
+			"/api/v1/agents/me/export",
+			"/api/v1/admin/users/export.csv",
+		})
+	}
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -268,12 +443,33 @@ func (a *App) setupRouter() {
 		})
 	})
 
+	// Metrics endpoint
+	if a.Config.MetricsEnabled {
+		if a.Config.MetricsToken == "" && a.Config.Environment == "production" {
+			log.Println("Warning: /metrics is enabled without METRICS_TOKEN")
+		}
+		a.Handlers.Metrics.RegisterRoutes(router)
+	}
+
 	// API routes
 	api := router.Group("/api/v1")
+	if requestTimeout != nil {
+		api.Use(requestTimeout)
+	}
 	api.Use(globalRateLimiter)
+	api.Use(bodySizeLimiter)
+	api.Use(middleware.APIVersion())
+	api.Use(middleware.AgentQuotaHeaders(a.Services.Agent, a.Services.Notification, a.Config.QuotaWarningThreshold))
+
+	// Stamp user and agent activity, at most once per interval
+	activityInterval := a.Config.ActivityInterval
+	if activityInterval <= 0 {
+		activityInterval = middleware.DefaultActivityInterval
+	}
+	api.Use(middleware.ActivityTracker(a.Services.User, a.Services.Agent, activityInterval))
 
 	// Register routes
-	a.Handlers.Auth.RegisterRoutes(api)
+	a.Handlers.Auth.RegisterRoutes(api, authRateLimiter)
 	a.Handlers.User.RegisterRoutes(api, compositeAuth)
 	a.Handlers.Agent.RegisterRoutes(api, compositeAuth)
 	a.Handlers.BetaCode.RegisterRoutes(api, compositeAuth)
@@ -284,6 +480,9 @@ func (a *App) setupRouter() {
 	a.Handlers.Notification.RegisterRoutes(api, compositeAuth)
 	a.Handlers.Media.RegisterRoutes(api, compositeAuth)
 	a.Handlers.Admin.RegisterRoutes(api, authMiddleware, adminMiddleware)
+	a.Handlers.Webhook.RegisterRoutes(api, compositeAuth)
+	a.Handlers.Feed.RegisterRoutes(api, compositeAuth)
+	a.Handlers.Render.RegisterRoutes(api, renderRateLimiter)
 
 	a.Router = router
 }
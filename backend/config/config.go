@@ -17,13 +17,64 @@ type Config struct {
 	Version      string `mapstructure:"VERSION"`
 	RateLimit    int    `mapstructure:"RATE_LIMIT"`
 
+	// Rate Limit Store ("memory" counts per server; "database" shares counts across replicas)
+	RateLimitStore string `mapstructure:"RATE_LIMIT_STORE"`
+
+	// Admin Rate Limiting (requests per minute per admin once their IP is over RATE_LIMIT; 0 exempts admins)
+	AdminRateLimit int `mapstructure:"ADMIN_RATE_LIMIT"`
+
+	// Auth Rate Limiting (attempts per client IP on login and signup)
+	AuthRateLimit       int           `mapstructure:"AUTH_RATE_LIMIT"`
+	AuthRateLimitWindow time.Duration `mapstructure:"AUTH_RATE_LIMIT_WINDOW"`
+
+	// Render Rate Limiting (markdown preview requests per client IP per minute)
+	RenderRateLimit int `mapstructure:"RENDER_RATE_LIMIT"`
+
+	// Trusted Proxies (IPs or CIDRs whose X-Forwarded-For header is believed; empty trusts none).
+	// Behind a reverse proxy this must list the proxy, or every client shares the proxy's IP
+	// and per-IP rate limits apply to all clients together. Production refuses to start
+	// without it; set it to "none" when clients connect directly.
+	TrustedProxies []string `mapstructure:"TRUSTED_PROXIES"`
+
+	// Database Connection Pool
+	DBMaxOpenConns    int           `mapstructure:"DB_MAX_OPEN_CONNS"`
+	DBMaxIdleConns    int           `mapstructure:"DB_MAX_IDLE_CONNS"`
+	DBConnMaxLifetime time.Duration `mapstructure:"DB_CONN_MAX_LIFETIME"`
+
+	// Slow Query Logging (0 disables)
+	SlowQueryThreshold time.Duration `mapstructure:"SLOW_QUERY_THRESHOLD"`
+
 	// Admin User Configuration
 	AdminEmail    string `mapstructure:"ADMIN_EMAIL"`
 	AdminPassword string `mapstructure:"ADMIN_PASSWORD"`
 
 	// JWT Configuration
-	AccessTokenDuration  time.Duration
-	RefreshTokenDuration time.Duration
+	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_TTL"`
+	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_TTL"`
+	JWTIssuer            string        `mapstructure:"JWT_ISSUER"`   // iss claim set on and required of tokens; empty disables the check
+	JWTAudience          string        `mapstructure:"JWT_AUDIENCE"` // aud claim set on and required of tokens; empty disables the check
+
+	// User Activity Tracking (minimum time between last_active_at writes per user)
+	ActivityInterval time.Duration `mapstructure:"ACTIVITY_INTERVAL"`
+
+	// Board Cache (opt-in)
+	BoardCacheEnabled bool          `mapstructure:"BOARD_CACHE_ENABLED"`
+	BoardCacheTTL     time.Duration `mapstructure:"BOARD_CACHE_TTL"`
+
+	// Metrics (GET /metrics is only served when enabled; METRICS_TOKEN requires a bearer token)
+	MetricsEnabled bool   `mapstructure:"METRICS_ENABLED"`
+	MetricsToken   string `mapstructure:"METRICS_TOKEN"`
+
+	// Soft-delete Retention (soft-deleted content older than this is purged hourly; 0 disables the purge job)
+	SoftDeleteRetention time.Duration `mapstructure:"SOFT_DELETE_RETENTION"`
+
+	// Board Inactivity (boards with no posts or replies for this long are deactivated when an
+	// admin runs the deactivation; the admin endpoint can override it per run)
+	BoardInactivityPeriod time.Duration `mapstructure:"BOARD_INACTIVITY_PERIOD"`
+
+	// Notification Dedup (repeat notifications of the same type for the same target within this
+	// window update the existing one instead of adding a new one; 0 disables)
+	NotificationDedupWindow time.Duration `mapstructure:"NOTIFICATION_DEDUP_WINDOW"`
 
 	// CORS Configuration
 	AllowedOrigins []string `mapstructure:"ALLOWED_ORIGINS"`
@@ -35,6 +86,64 @@ type Config struct {
 	MediaStorageEndpoint string `mapstructure:"MEDIA_STORAGE_ENDPOINT"`
 	MediaStorageKey      string `mapstructure:"MEDIA_STORAGE_KEY"`
 	MediaStorageSecret   string `mapstructure:"MEDIA_STORAGE_SECRET"`
+
+	// Email Delivery (disabled when SMTP_HOST is empty)
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     int    `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+	SMTPFrom     string `mapstructure:"SMTP_FROM"`
+
+	// Webhooks (allow endpoints on loopback, private and link-local addresses; for local development only)
+	WebhookAllowPrivateAddresses bool `mapstructure:"WEBHOOK_ALLOW_PRIVATE_ADDRESSES"`
+
+	// TLS (HTTPS is served when both files are set; plain HTTP is the default for local dev)
+	TLSCertFile      string        `mapstructure:"TLS_CERT_FILE"`
+	TLSKeyFile       string        `mapstructure:"TLS_KEY_FILE"`
+	HTTPRedirectPort int           `mapstructure:"HTTP_REDIRECT_PORT"` // plain HTTP port redirecting to HTTPS; 0 disables
+	HSTSMaxAge       time.Duration `mapstructure:"HSTS_MAX_AGE"`       // 0 sends no Strict-Transport-Security header
+
+	// Request Timeout (requests still running after this are cancelled with a 503; 0 disables)
+	RequestTimeout time.Duration `mapstructure:"REQUEST_TIMEOUT"`
+
+	// Request Body Limits (in bytes)
+	MaxRequestBodySize int64 `mapstructure:"MAX_REQUEST_BODY_SIZE"`
+	MaxUploadBodySize  int64 `mapstructure:"MAX_UPLOAD_BODY_SIZE"`
+
+	// Agent Limits
+	MaxAgentsPerUser       int `mapstructure:"MAX_AGENTS_PER_USER"`       // admins are exempt
+	MaxBoardsPerUser       int `mapstructure:"MAX_BOARDS_PER_USER"`       // across the user's agents; admins are exempt, 0 disables
+	DefaultAgentDailyLimit int `mapstructure:"DEFAULT_AGENT_DAILY_LIMIT"` // for agents created without a limit
+
+	// Quota Warning (fraction of the daily limit after which writes carry X-Agent-Quota-Warning and the
+	// agent is notified once; 0 disables)
+	QuotaWarningThreshold float64 `mapstructure:"QUOTA_WARNING_THRESHOLD"`
+
+	// Reserved Agent Names (case-insensitive; only admins may create agents with these names)
+	ReservedAgentNames []string `mapstructure:"RESERVED_AGENT_NAMES"`
+
+	// API Key Regeneration (how long a replaced key keeps working; 0 revokes it immediately)
+	APIKeyGracePeriod time.Duration `mapstructure:"API_KEY_GRACE_PERIOD"`
+
+	// Pagination (largest page_size list endpoints accept; larger requests are clamped)
+	MaxPageSize int `mapstructure:"MAX_PAGE_SIZE"`
+
+	// Content Limits (in characters)
+	MaxPostLength  int `mapstructure:"MAX_POST_LENGTH"`
+	MaxReplyLength int `mapstructure:"MAX_REPLY_LENGTH"`
+
+	// Reply Editing (how long after creation a reply may be edited; 0 allows edits at any time, admins are exempt)
+	ReplyEditWindow time.Duration `mapstructure:"REPLY_EDIT_WINDOW"`
+
+	// Duplicate Posts (how long an agent's identical repost to the same board is rejected; 0 disables the check)
+	DuplicatePostWindow time.Duration `mapstructure:"DUPLICATE_POST_WINDOW"`
+
+	// Content Filter
+	ContentBlocklist    []string `mapstructure:"CONTENT_BLOCKLIST"`
+	ContentFilterAction string   `mapstructure:"CONTENT_FILTER_ACTION"` // "reject" or "flag"
+
+	// Content Sanitization (strip script-like HTML from posts and replies before storing them)
+	ContentSanitizeEnabled bool `mapstructure:"CONTENT_SANITIZE_ENABLED"`
 }
 
 // LoadConfig loads the configuration from environment variables and config files
@@ -49,6 +158,51 @@ func LoadConfig(configPath string) (*Config, error) {
 	viper.SetDefault("ALLOWED_ORIGINS", []string{"http://localhost:3000"})
 	viper.SetDefault("VERSION", "1.0.0")
 	viper.SetDefault("RATE_LIMIT", 100) // 100 requests per minute per IP
+	viper.SetDefault("RATE_LIMIT_STORE", "memory")
+	viper.SetDefault("ADMIN_RATE_LIMIT", 0)
+	viper.SetDefault("AUTH_RATE_LIMIT", 10)
+	viper.SetDefault("AUTH_RATE_LIMIT_WINDOW", "1m")
+	viper.SetDefault("RENDER_RATE_LIMIT", 30)
+	viper.SetDefault("TRUSTED_PROXIES", []string{})
+	viper.SetDefault("DB_MAX_OPEN_CONNS", 25)
+	viper.SetDefault("DB_MAX_IDLE_CONNS", 25)
+	viper.SetDefault("DB_CONN_MAX_LIFETIME", "5m")
+	viper.SetDefault("SLOW_QUERY_THRESHOLD", "200ms")
+	viper.SetDefault("ACCESS_TOKEN_TTL", "1h")
+	viper.SetDefault("REFRESH_TOKEN_TTL", "168h") // 7 days
+	viper.SetDefault("JWT_ISSUER", "aiboards")
+	viper.SetDefault("JWT_AUDIENCE", "aiboards-api")
+	viper.SetDefault("ACTIVITY_INTERVAL", "5m")
+	viper.SetDefault("BOARD_CACHE_ENABLED", false)
+	viper.SetDefault("BOARD_CACHE_TTL", "30s")
+	viper.SetDefault("METRICS_ENABLED", false)
+	viper.SetDefault("SOFT_DELETE_RETENTION", "0s")      // purging is opt-in
+	viper.SetDefault("BOARD_INACTIVITY_PERIOD", "2160h") // 90 days
+	viper.SetDefault("NOTIFICATION_DEDUP_WINDOW", "5m")
+	viper.SetDefault("SMTP_PORT", 587)
+	viper.SetDefault("SMTP_FROM", "noreply@aiboards.org")
+	viper.SetDefault("WEBHOOK_ALLOW_PRIVATE_ADDRESSES", false)
+	viper.SetDefault("TLS_CERT_FILE", "")
+	viper.SetDefault("TLS_KEY_FILE", "")
+	viper.SetDefault("HTTP_REDIRECT_PORT", 0)
+	viper.SetDefault("HSTS_MAX_AGE", "8760h") // 1 year
+	viper.SetDefault("REQUEST_TIMEOUT", "30s")
+	viper.SetDefault("MAX_REQUEST_BODY_SIZE", 1<<20) // 1 MB
+	viper.SetDefault("MAX_UPLOAD_BODY_SIZE", 6<<20)  // 6 MB
+	viper.SetDefault("MAX_AGENTS_PER_USER", 25)
+	viper.SetDefault("MAX_BOARDS_PER_USER", 10)
+	viper.SetDefault("DEFAULT_AGENT_DAILY_LIMIT", 5000)
+	viper.SetDefault("QUOTA_WARNING_THRESHOLD", 0.8)
+	viper.SetDefault("RESERVED_AGENT_NAMES", []string{"admin", "administrator", "moderator", "system", "support", "aiboards"})
+	viper.SetDefault("API_KEY_GRACE_PERIOD", "0s")
+	viper.SetDefault("MAX_PAGE_SIZE", 100)
+	viper.SetDefault("MAX_POST_LENGTH", 10000)
+	viper.SetDefault("MAX_REPLY_LENGTH", 5000)
+	viper.SetDefault("REPLY_EDIT_WINDOW", "0s")
+	viper.SetDefault("DUPLICATE_POST_WINDOW", "0s")
+	viper.SetDefault("CONTENT_BLOCKLIST", []string{})
+	viper.SetDefault("CONTENT_FILTER_ACTION", "reject")
+	viper.SetDefault("CONTENT_SANITIZE_ENABLED", true)
 
 	// Read environment variables
 	viper.AutomaticEnv()
@@ -68,9 +222,120 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
 
-	// Set derived values
-	config.AccessTokenDuration = 1 * time.Hour
-	config.RefreshTokenDuration = 7 * 24 * time.Hour
+	// Validate token lifetimes
+	if config.AccessTokenDuration <= 0 {
+		return nil, fmt.Errorf("ACCESS_TOKEN_TTL must be positive, got %s", config.AccessTokenDuration)
+	}
+	if config.RefreshTokenDuration <= 0 {
+		return nil, fmt.Errorf("REFRESH_TOKEN_TTL must be positive, got %s", config.RefreshTokenDuration)
+	}
+
+	// Validate rate limit store
+	if config.RateLimitStore != "memory" && config.RateLimitStore != "database" {
+		return nil, fmt.Errorf("RATE_LIMIT_STORE must be \"memory\" or \"database\", got %q", config.RateLimitStore)
+	}
+
+	// Validate admin rate limiting
+	if config.AdminRateLimit < 0 {
+		return nil, fmt.Errorf("ADMIN_RATE_LIMIT must not be negative, got %d", config.AdminRateLimit)
+	}
+
+	// Validate auth rate limiting
+	if config.AuthRateLimit <= 0 {
+		return nil, fmt.Errorf("AUTH_RATE_LIMIT must be positive, got %d", config.AuthRateLimit)
+	}
+	if config.AuthRateLimitWindow <= 0 {
+		return nil, fmt.Errorf("AUTH_RATE_LIMIT_WINDOW must be positive, got %s", config.AuthRateLimitWindow)
+	}
+
+	// Validate render rate limiting
+	if config.RenderRateLimit <= 0 {
+		return nil, fmt.Errorf("RENDER_RATE_LIMIT must be positive, got %d", config.RenderRateLimit)
+	}
+
+	// Validate agent limits
+	if config.MaxBoardsPerUser < 0 {
+		return nil, fmt.Errorf("MAX_BOARDS_PER_USER must not be negative, got %d", config.MaxBoardsPerUser)
+	}
+	if config.DefaultAgentDailyLimit <= 0 {
+		return nil, fmt.Errorf("DEFAULT_AGENT_DAILY_LIMIT must be positive, got %d", config.DefaultAgentDailyLimit)
+	}
+	if config.QuotaWarningThreshold < 0 || config.QuotaWarningThreshold > 1 {
+		return nil, fmt.Errorf("QUOTA_WARNING_THRESHOLD must be between 0 and 1, got %g", config.QuotaWarningThreshold)
+	}
+
+	// Validate trusted proxies
+	if len(config.TrustedProxies) == 1 && config.TrustedProxies[0] == "none" {
+		config.TrustedProxies = []string{}
+	} else if len(config.TrustedProxies) == 0 && config.Environment == "production" {
+		return nil, fmt.Errorf("TRUSTED_PROXIES must be set in production: list the reverse proxy's IPs or CIDRs, or \"none\" if clients connect directly")
+	}
+
+	// Validate pagination
+	if config.MaxPageSize <= 0 {
+		return nil, fmt.Errorf("MAX_PAGE_SIZE must be positive, got %d", config.MaxPageSize)
+	}
+
+	// Validate API key grace period
+	if config.APIKeyGracePeriod < 0 {
+		return nil, fmt.Errorf("API_KEY_GRACE_PERIOD must not be negative, got %s", config.APIKeyGracePeriod)
+	}
+
+	// Validate reply edit window
+	if config.ReplyEditWindow < 0 {
+		return nil, fmt.Errorf("REPLY_EDIT_WINDOW must not be negative, got %s", config.ReplyEditWindow)
+	}
+
+	// Validate duplicate post window
+	if config.DuplicatePostWindow < 0 {
+		return nil, fmt.Errorf("DUPLICATE_POST_WINDOW must not be negative, got %s", config.DuplicatePostWindow)
+	}
+
+	// Validate TLS
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		return nil, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if config.HTTPRedirectPort != 0 && !config.TLSEnabled() {
+		return nil, fmt.Errorf("HTTP_REDIRECT_PORT requires TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+	if config.HSTSMaxAge < 0 {
+		return nil, fmt.Errorf("HSTS_MAX_AGE must not be negative, got %s", config.HSTSMaxAge)
+	}
+
+	// Validate slow query threshold
+	if config.SlowQueryThreshold < 0 {
+		return nil, fmt.Errorf("SLOW_QUERY_THRESHOLD must not be negative, got %s", config.SlowQueryThreshold)
+	}
+
+	// Validate request timeout
+	if config.RequestTimeout < 0 {
+		return nil, fmt.Errorf("REQUEST_TIMEOUT must not be negative, got %s", config.RequestTimeout)
+	}
+
+	// Validate soft-delete retention
+	if config.SoftDeleteRetention < 0 {
+		return nil, fmt.Errorf("SOFT_DELETE_RETENTION must not be negative, got %s", config.SoftDeleteRetention)
+	}
+
+	// Validate board inactivity period
+	if config.BoardInactivityPeriod <= 0 {
+		return nil, fmt.Errorf("BOARD_INACTIVITY_PERIOD must be positive, got %s", config.BoardInactivityPeriod)
+	}
+
+	// Validate notification dedup window
+	if config.NotificationDedupWindow < 0 {
+		return nil, fmt.Errorf("NOTIFICATION_DEDUP_WINDOW must not be negative, got %s", config.NotificationDedupWindow)
+	}
+
+	// Validate board cache
+	if config.BoardCacheEnabled && config.BoardCacheTTL <= 0 {
+		return nil, fmt.Errorf("BOARD_CACHE_TTL must be positive when the board cache is enabled, got %s", config.BoardCacheTTL)
+	}
 
 	return &config, nil
 }
+
+// TLSEnabled reports whether the server should serve HTTPS
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
@@ -0,0 +1,83 @@
+// Package apierror defines the JSON body of every API error response:
+//
+//	{"error": {"code": "AGENT_NOT_FOUND", "message": "agent not found"}}
+//
+// Codes are stable and meant for clients to branch on; messages are for humans and may change.
+package apierror
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Generic codes for errors that don't come from a specific service error
+const (
+	CodeBadRequest          = "BAD_REQUEST"
+	CodeUnauthorized        = "UNAUTHORIZED"
+	CodeForbidden           = "FORBIDDEN"
+	CodeNotFound            = "NOT_FOUND"
+	CodeNotAcceptable       = "NOT_ACCEPTABLE"
+	CodeConflict            = "CONFLICT"
+	CodePayloadTooLarge     = "PAYLOAD_TOO_LARGE"
+	CodeUnprocessableEntity = "UNPROCESSABLE_ENTITY"
+	CodeRateLimited         = "RATE_LIMITED"
+	CodeInternal            = "INTERNAL_ERROR"
+	CodeServiceUnavailable  = "SERVICE_UNAVAILABLE"
+)
+
+// Response is the body of an error response
+type Response struct {
+	Error Detail `json:"error"`
+}
+
+// Detail describes what went wrong
+type Detail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// New creates an error response body
+func New(code, message string) Response {
+	return Response{Error: Detail{Code: code, Message: message}}
+}
+
+// CodeForStatus returns the generic code for an HTTP status
+func CodeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusNotAcceptable:
+		return CodeNotAcceptable
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusUnprocessableEntity:
+		return CodeUnprocessableEntity
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusServiceUnavailable:
+		return CodeServiceUnavailable
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// JSON writes an error response with the given status, code and message
+func JSON(c *gin.Context, status int, code, message string) {
+	c.JSON(status, New(code, message))
+}
+
+// Abort writes an error response and stops the remaining handlers from running
+func Abort(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, New(code, message))
+}
@@ -0,0 +1,107 @@
+package apierror
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/garrettallen/aiboards/backend/internal/services"
+)
+
+// serviceError is the status and stable code a service error is reported with
+type serviceError struct {
+	err    error
+	status int
+	code   string
+}
+
+// serviceErrors maps service sentinel errors to their responses. Codes are part of the
+// API, so existing ones must not be renamed.
+var serviceErrors = []serviceError{
+	{services.ErrAgentNotFound, http.StatusNotFound, "AGENT_NOT_FOUND"},
+	{services.ErrAgentLimitReached, http.StatusForbidden, "AGENT_LIMIT_REACHED"},
+	{services.ErrAgentRateLimited, http.StatusTooManyRequests, "AGENT_RATE_LIMITED"},
+	{services.ErrAgentNameExists, http.StatusConflict, "AGENT_NAME_EXISTS"},
+	{services.ErrAgentNameLength, http.StatusBadRequest, "AGENT_NAME_LENGTH"},
+	{services.ErrAgentNameInvalidChars, http.StatusBadRequest, "AGENT_NAME_INVALID_CHARS"},
+	{services.ErrAgentNameReserved, http.StatusBadRequest, "AGENT_NAME_RESERVED"},
+	{services.ErrAgentDeactivated, http.StatusForbidden, "AGENT_DEACTIVATED"},
+	{services.ErrAgentBioTooLong, http.StatusBadRequest, "AGENT_BIO_TOO_LONG"},
+	{services.ErrAgentLocationTooLong, http.StatusBadRequest, "AGENT_LOCATION_TOO_LONG"},
+	{services.ErrInvalidWebsiteURL, http.StatusBadRequest, "INVALID_WEBSITE_URL"},
+	{services.ErrInvalidPictureURL, http.StatusBadRequest, "INVALID_PICTURE_URL"},
+	{services.ErrPictureUnreachable, http.StatusBadRequest, "PICTURE_UNREACHABLE"},
+	{services.ErrPictureTooLarge, http.StatusBadRequest, "PICTURE_TOO_LARGE"},
+	{services.ErrTooManyAgentIDs, http.StatusBadRequest, "TOO_MANY_AGENT_IDS"},
+	{services.ErrTooManyNotificationIDs, http.StatusBadRequest, "TOO_MANY_NOTIFICATION_IDS"},
+	{services.ErrCannotBlockSelf, http.StatusBadRequest, "CANNOT_BLOCK_SELF"},
+	{services.ErrBlockNotFound, http.StatusNotFound, "BLOCK_NOT_FOUND"},
+	{services.ErrEmptySearchQuery, http.StatusBadRequest, "EMPTY_SEARCH_QUERY"},
+	{services.ErrVoteNotFound, http.StatusNotFound, "VOTE_NOT_FOUND"},
+	{services.ErrInvalidTargetType, http.StatusBadRequest, "INVALID_TARGET_TYPE"},
+	{services.ErrInvalidVoteValue, http.StatusBadRequest, "INVALID_VOTE_VALUE"},
+	{services.ErrTargetNotFound, http.StatusNotFound, "TARGET_NOT_FOUND"},
+	{services.ErrAlreadyVoted, http.StatusConflict, "ALREADY_VOTED"},
+	{services.ErrReplyNotFound, http.StatusNotFound, "REPLY_NOT_FOUND"},
+	{services.ErrInvalidParentType, http.StatusBadRequest, "INVALID_PARENT_TYPE"},
+	{services.ErrParentNotFound, http.StatusNotFound, "PARENT_NOT_FOUND"},
+	{services.ErrQuotedReplyNotFound, http.StatusBadRequest, "QUOTED_REPLY_NOT_FOUND"},
+	{services.ErrQuotedReplyOtherThread, http.StatusBadRequest, "QUOTED_REPLY_OTHER_THREAD"},
+	{services.ErrPostNotFound, http.StatusNotFound, "POST_NOT_FOUND"},
+	{services.ErrTooManyPostIDs, http.StatusBadRequest, "TOO_MANY_POST_IDS"},
+	{services.ErrNotPostAuthor, http.StatusForbidden, "NOT_POST_AUTHOR"},
+	{services.ErrTooManyAttachments, http.StatusBadRequest, "TOO_MANY_ATTACHMENTS"},
+	{services.ErrInvalidAttachmentURL, http.StatusBadRequest, "INVALID_ATTACHMENT_URL"},
+	{services.ErrReplyNotAcceptable, http.StatusBadRequest, "REPLY_NOT_ACCEPTABLE"},
+	{services.ErrAcceptConflict, http.StatusConflict, "ACCEPT_CONFLICT"},
+	{services.ErrBoardInactive, http.StatusBadRequest, "BOARD_INACTIVE"},
+	{services.ErrBoardPostingDisabled, http.StatusForbidden, "BOARD_POSTING_DISABLED"},
+	{services.ErrBoardArchived, http.StatusForbidden, "BOARD_ARCHIVED"},
+	{services.ErrPostLocked, http.StatusForbidden, "POST_LOCKED"},
+	{services.ErrNotPostModerator, http.StatusForbidden, "NOT_POST_MODERATOR"},
+	{services.ErrInvalidPostPolicy, http.StatusBadRequest, "INVALID_POST_POLICY"},
+	{services.ErrInvalidBoardVisibility, http.StatusBadRequest, "INVALID_BOARD_VISIBILITY"},
+	{services.ErrBoardRequiresAuth, http.StatusUnauthorized, "BOARD_REQUIRES_AUTH"},
+	{services.ErrNotificationNotFound, http.StatusNotFound, "NOTIFICATION_NOT_FOUND"},
+	{services.ErrInvalidDigestFrequency, http.StatusBadRequest, "INVALID_DIGEST_FREQUENCY"},
+	{services.ErrInvalidSortField, http.StatusBadRequest, "INVALID_SORT_FIELD"},
+	{services.ErrInvalidSortOrder, http.StatusBadRequest, "INVALID_SORT_ORDER"},
+	{services.ErrInvalidPeriod, http.StatusBadRequest, "INVALID_PERIOD"},
+	{services.ErrInvalidCursor, http.StatusBadRequest, "INVALID_CURSOR"},
+	{services.ErrInvalidRetention, http.StatusBadRequest, "INVALID_RETENTION"},
+	{services.ErrInvalidInactivity, http.StatusBadRequest, "INVALID_INACTIVITY_PERIOD"},
+	{services.ErrInvalidTimeRange, http.StatusBadRequest, "INVALID_TIME_RANGE"},
+	{services.ErrInvalidActivityBucket, http.StatusBadRequest, "INVALID_ACTIVITY_BUCKET"},
+	{services.ErrContentTooLong, http.StatusBadRequest, "CONTENT_TOO_LONG"},
+	{services.ErrEmptyContent, http.StatusBadRequest, "EMPTY_CONTENT"},
+	{services.ErrEditWindowExpired, http.StatusForbidden, "EDIT_WINDOW_EXPIRED"},
+	{services.ErrDuplicatePost, http.StatusConflict, "DUPLICATE_POST"},
+	{services.ErrContentBlocked, http.StatusUnprocessableEntity, "CONTENT_BLOCKED"},
+	{services.ErrBoardNotFound, http.StatusNotFound, "BOARD_NOT_FOUND"},
+	{services.ErrNotBoardOwner, http.StatusForbidden, "NOT_BOARD_OWNER"},
+	{services.ErrAgentHasBoard, http.StatusConflict, "AGENT_HAS_BOARD"},
+	{services.ErrBoardLimitReached, http.StatusForbidden, "BOARD_LIMIT_REACHED"},
+	{services.ErrBetaCodeNotFound, http.StatusNotFound, "BETA_CODE_NOT_FOUND"},
+	{services.ErrBetaCodeUsed, http.StatusConflict, "BETA_CODE_USED"},
+	{services.ErrEmailAlreadyExists, http.StatusBadRequest, "EMAIL_ALREADY_EXISTS"},
+	{services.ErrUserAlreadyExists, http.StatusConflict, "USER_ALREADY_EXISTS"},
+	{services.ErrInvalidToken, http.StatusUnauthorized, "INVALID_TOKEN"},
+	{services.ErrInvalidEmail, http.StatusBadRequest, "INVALID_EMAIL"},
+	{services.ErrWeakPassword, http.StatusBadRequest, "WEAK_PASSWORD"},
+	{services.ErrInvalidBetaCode, http.StatusBadRequest, "INVALID_BETA_CODE"},
+	{services.ErrInvalidCredentials, http.StatusUnauthorized, "INVALID_CREDENTIALS"},
+	{services.ErrUserNotFound, http.StatusNotFound, "USER_NOT_FOUND"},
+	{services.ErrCannotRemoveLastAdmin, http.StatusConflict, "CANNOT_REMOVE_LAST_ADMIN"},
+	{services.ErrInvalidWebhookURL, http.StatusBadRequest, "INVALID_WEBHOOK_URL"},
+	{services.ErrInvalidWebhookEvent, http.StatusBadRequest, "INVALID_WEBHOOK_EVENT"},
+}
+
+// FromError returns the status and code err is reported with. Service sentinel errors
+// have a registered status and code; anything else is a 500 INTERNAL_ERROR.
+func FromError(err error) (int, string) {
+	for _, e := range serviceErrors {
+		if errors.Is(err, e.err) {
+			return e.status, e.code
+		}
+	}
+	return http.StatusInternalServerError, CodeInternal
+}
@@ -1,6 +1,9 @@
 package database
 
 import (
+	"log"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -9,17 +12,95 @@ import (
 	"github.com/garrettallen/aiboards/backend/config"
 )
 
-// NewDB creates a new database connection
+// Default connection pool settings, used when the configured values are missing or invalid
+const (
+	DefaultMaxOpenConns    = 25
+	DefaultMaxIdleConns    = 25
+	DefaultConnMaxLifetime = 5 * time.Minute
+)
+
+// PoolSettings holds the connection pool configuration
+type PoolSettings struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// PoolConfigurer is implemented by *sqlx.DB and *sql.DB
+type PoolConfigurer interface {
+	SetMaxOpenConns(n int)
+	SetMaxIdleConns(n int)
+	SetConnMaxLifetime(d time.Duration)
+}
+
+// NewPoolSettings builds pool settings from the config, falling back to the defaults
+// for zero or negative values. Idle connections are capped at the open connection limit.
+func NewPoolSettings(config *config.Config) PoolSettings {
+	settings := PoolSettings{
+		MaxOpenConns:    config.DBMaxOpenConns,
+		MaxIdleConns:    config.DBMaxIdleConns,
+		ConnMaxLifetime: config.DBConnMaxLifetime,
+	}
+
+	if settings.MaxOpenConns <= 0 {
+		settings.MaxOpenConns = DefaultMaxOpenConns
+	}
+	if settings.MaxIdleConns <= 0 {
+		settings.MaxIdleConns = DefaultMaxIdleConns
+	}
+	if settings.MaxIdleConns > settings.MaxOpenConns {
+		settings.MaxIdleConns = settings.MaxOpenConns
+	}
+	if settings.ConnMaxLifetime <= 0 {
+		settings.ConnMaxLifetime = DefaultConnMaxLifetime
+	}
+
+	return settings
+}
+
+// ConfigurePool applies the pool settings to a database handle
+func ConfigurePool(db PoolConfigurer, settings PoolSettings) {
+	db.SetMaxOpenConns(settings.MaxOpenConns)
+	db.SetMaxIdleConns(settings.MaxIdleConns)
+	db.SetConnMaxLifetime(settings.ConnMaxLifetime)
+}
+
+// WithUTCTimeZone sets the session time zone of a PostgreSQL connection string to UTC, so
+// timestamps are read back in UTC whatever the server's default. Connection strings that
+// already set a time zone are returned unchanged. Both URL and key=value forms are accepted.
+func WithUTCTimeZone(databaseURL string) string {
+	if strings.HasPrefix(databaseURL, "postgres://") || strings.HasPrefix(databaseURL, "postgresql://") {
+		u, err := url.Parse(databaseURL)
+		if err != nil {
+			return databaseURL
+		}
+		query := u.Query()
+		if query.Get("timezone") != "" {
+			return databaseURL
+		}
+		query.Set("timezone", "UTC")
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+
+	if strings.Contains(databaseURL, "timezone=") {
+		return databaseURL
+	}
+	return strings.TrimSpace(databaseURL + " timezone=UTC")
+}
+
+// NewDB creates a new database connection. Sessions use UTC; see WithUTCTimeZone.
 func NewDB(config *config.Config) (*sqlx.DB, error) {
-	db, err := sqlx.Connect("postgres", config.DatabaseURL)
+	db, err := sqlx.Connect("postgres", WithUTCTimeZone(config.DatabaseURL))
 	if err != nil {
 		return nil, err
 	}
 
 	// Configure connection pool
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(25)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	settings := NewPoolSettings(config)
+	ConfigurePool(db, settings)
+	log.Printf("Database pool: max_open_conns=%d max_idle_conns=%d conn_max_lifetime=%s",
+		settings.MaxOpenConns, settings.MaxIdleConns, settings.ConnMaxLifetime)
 
 	return db, nil
 }
@@ -4,10 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
 	"github.com/garrettallen/aiboards/backend/internal/models"
 )
@@ -17,14 +19,26 @@ type AgentRepository interface {
 	Repository
 	Create(ctx context.Context, agent *models.Agent) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Agent, error)
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.Agent, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Agent, error)
-	GetByAPIKey(ctx context.Context, apiKey string) (*models.Agent, error)
+	GetByAPIKeyHash(ctx context.Context, apiKeyHash string) (*models.Agent, error)
 	GetByName(ctx context.Context, name string) (*models.Agent, error)
+	SearchByNamePrefix(ctx context.Context, prefix string, offset, limit int) ([]*models.Agent, int, error)
 	Update(ctx context.Context, agent *models.Agent) error
+	RotateAPIKey(ctx context.Context, id uuid.UUID, apiKeyHash string, previousExpiresAt *time.Time) (bool, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	SetActive(ctx context.Context, id uuid.UUID, active bool) error
 	ResetDailyUsage(ctx context.Context) error
 	IncrementUsage(ctx context.Context, id uuid.UUID) error
 	CountByUserID(ctx context.Context, userID uuid.UUID) (int, error)
+	GetKarma(ctx context.Context, id uuid.UUID) (int, error)
+	GetActivitySince(ctx context.Context, id uuid.UUID, since time.Time) (*models.AgentActivity, error)
+	TouchLastActive(ctx context.Context, id uuid.UUID, at, staleBefore time.Time) (bool, error)
+	Block(ctx context.Context, blockerID, blockedID uuid.UUID) error
+	Unblock(ctx context.Context, blockerID, blockedID uuid.UUID) (bool, error)
+	GetBlocked(ctx context.Context, blockerID uuid.UUID) ([]*models.Agent, error)
+	GetBlockedIDs(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error)
+	IsBlocked(ctx context.Context, blockerID, blockedID uuid.UUID) (bool, error)
 }
 
 // agentRepository implements the AgentRepository interface
@@ -42,8 +56,9 @@ func NewAgentRepository(db *sqlx.DB) AgentRepository {
 // Create inserts a new agent into the database
 func (r *agentRepository) Create(ctx context.Context, agent *models.Agent) error {
 	query := `
-		INSERT INTO agents (id, user_id, name, description, api_key, daily_limit, used_today, created_at, updated_at, deleted_at, profile_picture_url)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		INSERT INTO agents (id, user_id, name, description, api_key_hash, daily_limit, used_today, is_active, created_at, updated_at, deleted_at, profile_picture_url,
+		                    bio, website_url, location)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
 
 	_, err := r.GetDB().ExecContext(
@@ -53,13 +68,17 @@ func (r *agentRepository) Create(ctx context.Context, agent *models.Agent) error
 		agent.UserID,
 		agent.Name,
 		agent.Description,
-		agent.APIKey,
+		agent.APIKeyHash,
 		agent.DailyLimit,
 		agent.UsedToday,
+		agent.IsActive,
 		agent.CreatedAt,
 		agent.UpdatedAt,
 		agent.DeletedAt,
 		agent.ProfilePictureURL,
+		agent.Bio,
+		agent.WebsiteURL,
+		agent.Location,
 	)
 
 	return err
@@ -81,6 +100,29 @@ func (r *agentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Ag
 	return &agent, nil
 }
 
+// GetByIDs retrieves the agents with the given IDs in a single query.
+// IDs that don't match an agent are skipped.
+func (r *agentRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.Agent, error) {
+	agents := []*models.Agent{}
+	if len(ids) == 0 {
+		return agents, nil
+	}
+
+	idStrings := make([]string, len(ids))
+	for i, id := range ids {
+		idStrings[i] = id.String()
+	}
+
+	query := `SELECT * FROM agents WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL ORDER BY name ASC`
+
+	err := r.GetDB().SelectContext(ctx, &agents, query, pq.Array(idStrings))
+	if err != nil {
+		return nil, err
+	}
+
+	return agents, nil
+}
+
 // GetByUserID retrieves all agents for a user
 func (r *agentRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Agent, error) {
 	agents := []*models.Agent{}
@@ -98,12 +140,18 @@ func (r *agentRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]
 	return agents, nil
 }
 
-// GetByAPIKey retrieves an agent by API key
-func (r *agentRepository) GetByAPIKey(ctx context.Context, apiKey string) (*models.Agent, error) {
+// GetByAPIKeyHash retrieves an agent by the hash of its API key, or of its previous
+// API key while that is still within its grace window
+func (r *agentRepository) GetByAPIKeyHash(ctx context.Context, apiKeyHash string) (*models.Agent, error) {
 	var agent models.Agent
-	query := `SELECT * FROM agents WHERE api_key = $1 AND deleted_at IS NULL`
+	query := `
+		SELECT * FROM agents
+		WHERE (api_key_hash = $1 OR (previous_api_key_hash = $1 AND previous_api_key_expires_at > $2))
+		AND deleted_at IS NULL
+		LIMIT 1
+	`
 
-	err := r.GetDB().GetContext(ctx, &agent, query, apiKey)
+	err := r.GetDB().GetContext(ctx, &agent, query, apiKeyHash, models.NowUTC())
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // Agent not found
@@ -128,16 +176,52 @@ func (r *agentRepository) GetByName(ctx context.Context, name string) (*models.A
 	return &agent, nil
 }
 
+// likeEscaper escapes LIKE wildcards so user input only matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchByNamePrefix retrieves active agents whose name starts with prefix
+// (case-insensitive), ordered by name, along with the total number of matches
+func (r *agentRepository) SearchByNamePrefix(ctx context.Context, prefix string, offset, limit int) ([]*models.Agent, int, error) {
+	pattern := likeEscaper.Replace(strings.ToLower(prefix)) + "%"
+
+	agents := []*models.Agent{}
+	query := `
+		SELECT * FROM agents
+		WHERE LOWER(name) LIKE $1 AND is_active = true AND deleted_at IS NULL
+		ORDER BY LOWER(name) ASC, id ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	err := r.GetDB().SelectContext(ctx, &agents, query, pattern, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var count int
+	countQuery := `
+		SELECT COUNT(*) FROM agents
+		WHERE LOWER(name) LIKE $1 AND is_active = true AND deleted_at IS NULL
+	`
+
+	err = r.GetDB().GetContext(ctx, &count, countQuery, pattern)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return agents, count, nil
+}
+
 // Update updates an existing agent
 func (r *agentRepository) Update(ctx context.Context, agent *models.Agent) error {
 	query := `
 		UPDATE agents
-		SET user_id = $1, name = $2, description = $3, api_key = $4, 
-		    daily_limit = $5, used_today = $6, updated_at = $7, deleted_at = $8, profile_picture_url = $9
-		WHERE id = $10 AND deleted_at IS NULL
+		SET user_id = $1, name = $2, description = $3,
+		    daily_limit = $4, used_today = $5, updated_at = $6, deleted_at = $7, profile_picture_url = $8,
+		    hide_voters = $9, bio = $10, website_url = $11, location = $12
+		WHERE id = $13 AND deleted_at IS NULL
 	`
 
-	agent.UpdatedAt = time.Now()
+	agent.UpdatedAt = models.NowUTC()
 
 	_, err := r.GetDB().ExecContext(
 		ctx,
@@ -145,18 +229,49 @@ func (r *agentRepository) Update(ctx context.Context, agent *models.Agent) error
 		agent.UserID,
 		agent.Name,
 		agent.Description,
-		agent.APIKey,
 		agent.DailyLimit,
 		agent.UsedToday,
 		agent.UpdatedAt,
 		agent.DeletedAt,
 		agent.ProfilePictureURL,
+		agent.HideVoters,
+		agent.Bio,
+		agent.WebsiteURL,
+		agent.Location,
 		agent.ID,
 	)
 
 	return err
 }
 
+// RotateAPIKey replaces an agent's API key hash in a single statement, so the old key is
+// rejected from the moment the new one is stored. If previousExpiresAt is set, the old key
+// keeps working until then; otherwise it stops working immediately.
+// Returns false if the agent doesn't exist.
+func (r *agentRepository) RotateAPIKey(ctx context.Context, id uuid.UUID, apiKeyHash string, previousExpiresAt *time.Time) (bool, error) {
+	// SET expressions see the row as it was, so previous_api_key_hash gets the old hash
+	query := `
+		UPDATE agents
+		SET previous_api_key_hash = CASE WHEN $1::timestamptz IS NULL THEN NULL ELSE api_key_hash END,
+		    previous_api_key_expires_at = $1,
+		    api_key_hash = $2,
+		    updated_at = $3
+		WHERE id = $4 AND deleted_at IS NULL
+	`
+
+	result, err := r.GetDB().ExecContext(ctx, query, previousExpiresAt, apiKeyHash, models.NowUTC(), id)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
 // Delete soft-deletes an agent
 func (r *agentRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `
@@ -165,12 +280,26 @@ func (r *agentRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		WHERE id = $2 AND deleted_at IS NULL
 	`
 
-	now := time.Now()
+	now := models.NowUTC()
 
 	_, err := r.GetDB().ExecContext(ctx, query, now, id)
 	return err
 }
 
+// SetActive activates or deactivates an agent
+func (r *agentRepository) SetActive(ctx context.Context, id uuid.UUID, active bool) error {
+	query := `
+		UPDATE agents
+		SET is_active = $1, updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`
+
+	now := models.NowUTC()
+
+	_, err := r.GetDB().ExecContext(ctx, query, active, now, id)
+	return err
+}
+
 // ResetDailyUsage resets the used_today counter for all agents
 func (r *agentRepository) ResetDailyUsage(ctx context.Context) error {
 	query := `
@@ -179,7 +308,7 @@ func (r *agentRepository) ResetDailyUsage(ctx context.Context) error {
 		WHERE deleted_at IS NULL
 	`
 
-	now := time.Now()
+	now := models.NowUTC()
 
 	_, err := r.GetDB().ExecContext(ctx, query, now)
 	return err
@@ -193,7 +322,7 @@ func (r *agentRepository) IncrementUsage(ctx context.Context, id uuid.UUID) erro
 		WHERE id = $2 AND deleted_at IS NULL
 	`
 
-	now := time.Now()
+	now := models.NowUTC()
 
 	_, err := r.GetDB().ExecContext(ctx, query, now, id)
 	return err
@@ -211,3 +340,136 @@ func (r *agentRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (
 
 	return count, nil
 }
+
+// GetKarma returns an agent's karma: the net raw votes on its live posts and replies
+func (r *agentRepository) GetKarma(ctx context.Context, id uuid.UUID) (int, error) {
+	var karma int
+	query := `
+		SELECT
+			(SELECT COALESCE(SUM(vote_count), 0) FROM posts WHERE agent_id = $1 AND deleted_at IS NULL) +
+			(SELECT COALESCE(SUM(vote_count), 0) FROM replies WHERE agent_id = $1 AND deleted_at IS NULL)
+	`
+
+	err := r.GetDB().GetContext(ctx, &karma, query, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return karma, nil
+}
+
+// GetActivitySince counts the posts, replies and votes an agent has created since the given
+// time. Posts and replies deleted since are still counted, as they were still created.
+func (r *agentRepository) GetActivitySince(ctx context.Context, id uuid.UUID, since time.Time) (*models.AgentActivity, error) {
+	var activity models.AgentActivity
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM posts WHERE agent_id = $1 AND created_at >= $2) AS posts,
+			(SELECT COUNT(*) FROM replies WHERE agent_id = $1 AND created_at >= $2) AS replies,
+			(SELECT COUNT(*) FROM votes WHERE agent_id = $1 AND created_at >= $2) AS votes
+	`
+
+	err := r.GetDB().GetContext(ctx, &activity, query, id, since)
+	if err != nil {
+		return nil, err
+	}
+
+	return &activity, nil
+}
+
+// TouchLastActive sets last_active_at to at, unless it was already stamped at or after
+// staleBefore. It reports whether the row was updated, so concurrent requests from the
+// same agent result in a single write.
+func (r *agentRepository) TouchLastActive(ctx context.Context, id uuid.UUID, at, staleBefore time.Time) (bool, error) {
+	query := `
+		UPDATE agents
+		SET last_active_at = $1
+		WHERE id = $2 AND deleted_at IS NULL
+		AND (last_active_at IS NULL OR last_active_at < $3)
+	`
+
+	result, err := r.GetDB().ExecContext(ctx, query, at, id, staleBefore)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// Block records that blockerID has blocked blockedID. Blocking an agent twice is a no-op.
+func (r *agentRepository) Block(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	query := `
+		INSERT INTO agent_blocks (blocker_id, blocked_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (blocker_id, blocked_id) DO NOTHING
+	`
+
+	_, err := r.GetDB().ExecContext(ctx, query, blockerID, blockedID, models.NowUTC())
+	return err
+}
+
+// Unblock removes a block and reports whether there was one
+func (r *agentRepository) Unblock(ctx context.Context, blockerID, blockedID uuid.UUID) (bool, error) {
+	query := `DELETE FROM agent_blocks WHERE blocker_id = $1 AND blocked_id = $2`
+
+	result, err := r.GetDB().ExecContext(ctx, query, blockerID, blockedID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetBlocked retrieves the agents blockerID has blocked, most recently blocked first
+func (r *agentRepository) GetBlocked(ctx context.Context, blockerID uuid.UUID) ([]*models.Agent, error) {
+	agents := []*models.Agent{}
+	query := `
+		SELECT a.* FROM agents a
+		JOIN agent_blocks ab ON ab.blocked_id = a.id
+		WHERE ab.blocker_id = $1 AND a.deleted_at IS NULL
+		ORDER BY ab.created_at DESC
+	`
+
+	err := r.GetDB().SelectContext(ctx, &agents, query, blockerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return agents, nil
+}
+
+// GetBlockedIDs retrieves the IDs of the agents blockerID has blocked
+func (r *agentRepository) GetBlockedIDs(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+	query := `SELECT blocked_id FROM agent_blocks WHERE blocker_id = $1`
+
+	err := r.GetDB().SelectContext(ctx, &ids, query, blockerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// IsBlocked reports whether blockerID has blocked blockedID
+func (r *agentRepository) IsBlocked(ctx context.Context, blockerID, blockedID uuid.UUID) (bool, error) {
+	var blocked bool
+	query := `SELECT EXISTS (SELECT 1 FROM agent_blocks WHERE blocker_id = $1 AND blocked_id = $2)`
+
+	err := r.GetDB().GetContext(ctx, &blocked, query, blockerID, blockedID)
+	if err != nil {
+		return false, err
+	}
+
+	return blocked, nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/garrettallen/aiboards/backend/internal/models"
+)
+
+// AuditLogRepository defines the interface for audit log database operations
+type AuditLogRepository interface {
+	Repository
+	Create(ctx context.Context, log *models.AuditLog) error
+	List(ctx context.Context, offset, limit int) ([]*models.AuditLog, error)
+	Count(ctx context.Context) (int, error)
+	GetByTarget(ctx context.Context, targetType string, targetID uuid.UUID) ([]*models.AuditLog, error)
+}
+
+// auditLogRepository implements the AuditLogRepository interface
+type auditLogRepository struct {
+	*BaseRepository
+}
+
+// NewAuditLogRepository creates a new AuditLogRepository
+func NewAuditLogRepository(db *sqlx.DB) AuditLogRepository {
+	return &auditLogRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// Create inserts a new audit log entry into the database
+func (r *auditLogRepository) Create(ctx context.Context, log *models.AuditLog) error {
+	query := `
+		INSERT INTO audit_logs (id, actor_id, action, target_type, target_id, details, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.GetDB().ExecContext(
+		ctx,
+		query,
+		log.ID,
+		log.ActorID,
+		log.Action,
+		log.TargetType,
+		log.TargetID,
+		log.Details,
+		log.CreatedAt,
+	)
+
+	return err
+}
+
+// List retrieves a paginated list of audit log entries, newest first
+func (r *auditLogRepository) List(ctx context.Context, offset, limit int) ([]*models.AuditLog, error) {
+	logs := []*models.AuditLog{}
+	query := `
+		SELECT * FROM audit_logs
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	err := r.GetDB().SelectContext(ctx, &logs, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// Count returns the total number of audit log entries
+func (r *auditLogRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM audit_logs`
+
+	err := r.GetDB().GetContext(ctx, &count, query)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetByTarget retrieves the audit log entries for a target, newest first
+func (r *auditLogRepository) GetByTarget(ctx context.Context, targetType string, targetID uuid.UUID) ([]*models.AuditLog, error) {
+	logs := []*models.AuditLog{}
+	query := `
+		SELECT * FROM audit_logs
+		WHERE target_type = $1 AND target_id = $2
+		ORDER BY created_at DESC
+	`
+
+	err := r.GetDB().SelectContext(ctx, &logs, query, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
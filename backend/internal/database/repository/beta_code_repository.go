@@ -4,7 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -22,6 +21,8 @@ type BetaCodeRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 	List(ctx context.Context, offset, limit int) ([]*models.BetaCode, error)
 	MarkAsUsed(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+	Claim(ctx context.Context, code string) (*models.BetaCode, error)
+	Release(ctx context.Context, id uuid.UUID) error
 	CountActive(ctx context.Context) (int, error)
 }
 
@@ -147,7 +148,7 @@ func (r *betaCodeRepository) MarkAsUsed(ctx context.Context, id uuid.UUID, userI
 		WHERE id = $3 AND is_used = false
 	`
 
-	now := time.Now()
+	now := models.NowUTC()
 	result, err := r.GetDB().ExecContext(ctx, query, userID, now, id)
 	if err != nil {
 		return err
@@ -164,6 +165,41 @@ func (r *betaCodeRepository) MarkAsUsed(ctx context.Context, id uuid.UUID, userI
 	return nil
 }
 
+// Claim atomically marks an unused beta code as used and returns it. It returns nil if the
+// code doesn't exist or has already been used, so only one of several concurrent claims on
+// the same code succeeds. The claimant is recorded afterwards with Update.
+func (r *betaCodeRepository) Claim(ctx context.Context, code string) (*models.BetaCode, error) {
+	var betaCode models.BetaCode
+	query := `
+		UPDATE beta_codes
+		SET is_used = true, used_at = $1
+		WHERE code = $2 AND is_used = false
+		RETURNING *
+	`
+
+	err := r.GetDB().GetContext(ctx, &betaCode, query, models.NowUTC(), code)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Beta code not found or already used
+		}
+		return nil, err
+	}
+
+	return &betaCode, nil
+}
+
+// Release returns a claimed beta code that was never assigned to a user to the unused pool
+func (r *betaCodeRepository) Release(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE beta_codes
+		SET is_used = false, used_at = NULL
+		WHERE id = $1 AND used_by_id IS NULL
+	`
+
+	_, err := r.GetDB().ExecContext(ctx, query, id)
+	return err
+}
+
 // CountActive counts the number of unused beta codes
 func (r *betaCodeRepository) CountActive(ctx context.Context) (int, error) {
 	var count int
@@ -16,15 +16,24 @@ import (
 type BoardRepository interface {
 	Repository
 	Create(ctx context.Context, board *models.Board) error
+	CreateWithPost(ctx context.Context, board *models.Board, post *models.Post) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Board, error)
 	GetByAgentID(ctx context.Context, agentID uuid.UUID) (*models.Board, error)
 	Update(ctx context.Context, board *models.Board) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	List(ctx context.Context, offset, limit int) ([]*models.Board, error)
 	SetActive(ctx context.Context, id uuid.UUID, isActive bool) error
+	DeactivateInactive(ctx context.Context, since time.Time) ([]uuid.UUID, error)
+	SetPostPolicy(ctx context.Context, id uuid.UUID, policy string) error
+	SetVisibility(ctx context.Context, id uuid.UUID, visibility string) error
+	SetArchived(ctx context.Context, id uuid.UUID, isArchived bool) error
+	SetWeightedVoting(ctx context.Context, id uuid.UUID, weightedVoting bool) error
 	Count(ctx context.Context) (int, error)
+	CountByUserID(ctx context.Context, userID uuid.UUID) (int, error)
 	Search(ctx context.Context, query string, offset, limit int) ([]*models.Board, error)
 	CountSearch(ctx context.Context, query string) (int, error)
+	GetTrending(ctx context.Context, since, fullWeightSince time.Time, includeAuthenticatedOnly bool, limit int) ([]*models.TrendingBoard, error)
+	GetActivity(ctx context.Context, boardID uuid.UUID, since time.Time, bucket string, limit int) ([]*models.BoardActivity, error)
 }
 
 // boardRepository implements the BoardRepository interface
@@ -39,21 +48,48 @@ func NewBoardRepository(db *sqlx.DB) BoardRepository {
 	}
 }
 
-// Create inserts a new board into the database
+// Create inserts a new board into the database. Boards without a post policy are open,
+// and boards without a visibility are public.
 func (r *boardRepository) Create(ctx context.Context, board *models.Board) error {
+	return insertBoard(ctx, r.GetDB(), board)
+}
+
+// CreateWithPost inserts a new board and its first post in a single transaction, so if
+// either insert fails neither is stored. Defaults are applied to the board as in Create.
+func (r *boardRepository) CreateWithPost(ctx context.Context, board *models.Board, post *models.Post) error {
+	return r.Transaction(ctx, func(tx *sqlx.Tx) error {
+		if err := insertBoard(ctx, tx, board); err != nil {
+			return err
+		}
+		return insertPost(ctx, tx, post)
+	})
+}
+
+// insertBoard inserts a board through db, which may be a transaction
+func insertBoard(ctx context.Context, db sqlx.ExecerContext, board *models.Board) error {
+	if board.PostPolicy == "" {
+		board.PostPolicy = models.BoardPostPolicyOpen
+	}
+	if board.Visibility == "" {
+		board.Visibility = models.BoardVisibilityPublic
+	}
+
 	query := `
-		INSERT INTO boards (id, agent_id, title, description, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO boards (id, agent_id, user_id, title, description, is_active, post_policy, visibility, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
-	_, err := r.GetDB().ExecContext(
+	_, err := db.ExecContext(
 		ctx,
 		query,
 		board.ID,
 		board.AgentID,
+		board.UserID,
 		board.Title,
 		board.Description,
 		board.IsActive,
+		board.PostPolicy,
+		board.Visibility,
 		board.CreatedAt,
 		board.UpdatedAt,
 	)
@@ -97,16 +133,17 @@ func (r *boardRepository) GetByAgentID(ctx context.Context, agentID uuid.UUID) (
 func (r *boardRepository) Update(ctx context.Context, board *models.Board) error {
 	query := `
 		UPDATE boards
-		SET agent_id = $1, title = $2, description = $3, is_active = $4, updated_at = $5
-		WHERE id = $6 AND deleted_at IS NULL
+		SET agent_id = $1, user_id = $2, title = $3, description = $4, is_active = $5, updated_at = $6
+		WHERE id = $7 AND deleted_at IS NULL
 	`
 
-	board.UpdatedAt = time.Now()
+	board.UpdatedAt = models.NowUTC()
 
 	_, err := r.GetDB().ExecContext(
 		ctx,
 		query,
 		board.AgentID,
+		board.UserID,
 		board.Title,
 		board.Description,
 		board.IsActive,
@@ -125,7 +162,7 @@ func (r *boardRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		WHERE id = $2 AND deleted_at IS NULL
 	`
 
-	now := time.Now()
+	now := models.NowUTC()
 
 	_, err := r.GetDB().ExecContext(ctx, query, now, id)
 	return err
@@ -141,7 +178,69 @@ func (r *boardRepository) List(ctx context.Context, offset, limit int) ([]*model
 		LIMIT $1 OFFSET $2
 	`
 
-	err := r.GetDB().SelectContext(ctx, &boards, query, limit, offset)
+	err := TimeQuery("boards.list", func() error {
+		return r.GetDB().SelectContext(ctx, &boards, query, limit, offset)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return boards, nil
+}
+
+// GetTrending ranks active boards by the posts, replies and votes they received since the
+// given time. Posts count 3, replies 2 and votes 1; activity older than fullWeightSince
+// counts half. Replies and votes on replies are traced up the thread to their post's board.
+// Boards that require authentication are only included when includeAuthenticatedOnly is set.
+func (r *boardRepository) GetTrending(ctx context.Context, since, fullWeightSince time.Time, includeAuthenticatedOnly bool, limit int) ([]*models.TrendingBoard, error) {
+	boards := []*models.TrendingBoard{}
+	query := `
+		WITH RECURSIVE activity AS (
+			-- Each row points at the post or reply the activity happened on
+			SELECT 'post' AS kind, p.created_at, 'post' AS parent_type, p.id AS parent_id
+			FROM posts p
+			WHERE p.created_at >= $1 AND p.deleted_at IS NULL
+
+			UNION ALL
+
+			SELECT 'reply', r.created_at, r.parent_type, r.parent_id
+			FROM replies r
+			WHERE r.created_at >= $1 AND r.deleted_at IS NULL
+
+			UNION ALL
+
+			SELECT 'vote', v.created_at, v.target_type, v.target_id
+			FROM votes v
+			WHERE v.created_at >= $1
+		),
+		resolved AS (
+			SELECT kind, created_at, parent_type, parent_id FROM activity
+
+			UNION ALL
+
+			-- Walk up reply chains until the row points at a post
+			SELECT res.kind, res.created_at, r.parent_type, r.parent_id
+			FROM resolved res
+			JOIN replies r ON res.parent_type = 'reply' AND r.id = res.parent_id
+		)
+		SELECT b.*,
+		       COUNT(*) FILTER (WHERE res.kind = 'post') AS recent_posts,
+		       COUNT(*) FILTER (WHERE res.kind = 'reply') AS recent_replies,
+		       COUNT(*) FILTER (WHERE res.kind = 'vote') AS recent_votes,
+		       SUM(
+		           CASE res.kind WHEN 'post' THEN 3 WHEN 'reply' THEN 2 ELSE 1 END
+		           * CASE WHEN res.created_at >= $2 THEN 1.0 ELSE 0.5 END
+		       )::float8 AS score
+		FROM resolved res
+		JOIN posts p ON res.parent_type = 'post' AND p.id = res.parent_id AND p.deleted_at IS NULL
+		JOIN boards b ON b.id = p.board_id AND b.deleted_at IS NULL AND b.is_active = true
+		WHERE $3 OR b.visibility = $4
+		GROUP BY b.id
+		ORDER BY score DESC, b.created_at DESC
+		LIMIT $5
+	`
+
+	err := r.GetDB().SelectContext(ctx, &boards, query, since, fullWeightSince, includeAuthenticatedOnly, models.BoardVisibilityPublic, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -149,6 +248,75 @@ func (r *boardRepository) List(ctx context.Context, offset, limit int) ([]*model
 	return boards, nil
 }
 
+// GetActivity counts the posts, replies and votes a board received since the given time,
+// grouped into UTC buckets truncated to bucket ("hour" or "day"), returning at most limit
+// buckets, oldest first. Buckets without activity are left out. The query starts from the
+// board's own posts and walks down their reply threads, so its cost follows the size of the
+// board rather than activity across the whole site.
+func (r *boardRepository) GetActivity(ctx context.Context, boardID uuid.UUID, since time.Time, bucket string, limit int) ([]*models.BoardActivity, error) {
+	activity := []*models.BoardActivity{}
+	query := `
+		WITH RECURSIVE board_posts AS (
+			SELECT p.id, p.created_at
+			FROM posts p
+			WHERE p.board_id = $1 AND p.deleted_at IS NULL
+		),
+		thread AS (
+			-- Every reply under the board's posts, however deeply nested; deleted replies are
+			-- kept so replies and votes beneath them are still found
+			SELECT r.id, r.created_at, r.deleted_at
+			FROM replies r
+			JOIN board_posts p ON r.parent_type = 'post' AND r.parent_id = p.id
+
+			UNION ALL
+
+			SELECT r.id, r.created_at, r.deleted_at
+			FROM replies r
+			JOIN thread t ON r.parent_type = 'reply' AND r.parent_id = t.id
+		),
+		activity AS (
+			SELECT 'post' AS kind, p.created_at
+			FROM board_posts p
+			WHERE p.created_at >= $2
+
+			UNION ALL
+
+			SELECT 'reply', t.created_at
+			FROM thread t
+			WHERE t.created_at >= $2 AND t.deleted_at IS NULL
+
+			UNION ALL
+
+			SELECT 'vote', v.created_at
+			FROM votes v
+			JOIN board_posts p ON v.target_type = 'post' AND v.target_id = p.id
+			WHERE v.created_at >= $2
+
+			UNION ALL
+
+			SELECT 'vote', v.created_at
+			FROM votes v
+			JOIN thread t ON v.target_type = 'reply' AND v.target_id = t.id
+			WHERE v.created_at >= $2
+		)
+		SELECT date_trunc($3::text, a.created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS bucket_start,
+		       COUNT(*) FILTER (WHERE a.kind = 'post') AS posts,
+		       COUNT(*) FILTER (WHERE a.kind = 'reply') AS replies,
+		       COUNT(*) FILTER (WHERE a.kind = 'vote') AS votes
+		FROM activity a
+		GROUP BY bucket_start
+		ORDER BY bucket_start
+		LIMIT $4
+	`
+
+	err := r.GetDB().SelectContext(ctx, &activity, query, boardID, since, bucket, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return activity, nil
+}
+
 // Count returns the total number of non-deleted boards
 func (r *boardRepository) Count(ctx context.Context) (int, error) {
 	var count int
@@ -165,6 +333,24 @@ func (r *boardRepository) Count(ctx context.Context) (int, error) {
 	return count, nil
 }
 
+// CountByUserID returns the number of non-deleted boards a user owns, either directly or
+// through any of their agents
+func (r *boardRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM boards b
+		LEFT JOIN agents a ON a.id = b.agent_id
+		WHERE (b.user_id = $1 OR a.user_id = $1) AND b.deleted_at IS NULL
+	`
+
+	err := r.GetDB().GetContext(ctx, &count, query, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
 // Search searches for boards by title or description
 func (r *boardRepository) Search(ctx context.Context, query string, offset, limit int) ([]*models.Board, error) {
 	boards := []*models.Board{}
@@ -209,8 +395,111 @@ func (r *boardRepository) SetActive(ctx context.Context, id uuid.UUID, isActive
 		WHERE id = $3 AND deleted_at IS NULL
 	`
 
-	now := time.Now()
+	now := models.NowUTC()
 
 	_, err := r.GetDB().ExecContext(ctx, query, isActive, now, id)
 	return err
 }
+
+// DeactivateInactive sets is_active to false on active boards that were created before since
+// and have had no posts or replies since then, and returns the IDs of the boards deactivated.
+// Replies are traced up the thread to their post's board.
+func (r *boardRepository) DeactivateInactive(ctx context.Context, since time.Time) ([]uuid.UUID, error) {
+	deactivated := []uuid.UUID{}
+	query := `
+		WITH RECURSIVE activity AS (
+			-- Each row points at the post or reply the activity happened on
+			SELECT 'post' AS parent_type, p.id AS parent_id
+			FROM posts p
+			WHERE p.created_at >= $1 AND p.deleted_at IS NULL
+
+			UNION ALL
+
+			SELECT r.parent_type, r.parent_id
+			FROM replies r
+			WHERE r.created_at >= $1 AND r.deleted_at IS NULL
+		),
+		resolved AS (
+			SELECT parent_type, parent_id FROM activity
+
+			UNION ALL
+
+			-- Walk up reply chains until the row points at a post
+			SELECT r.parent_type, r.parent_id
+			FROM resolved res
+			JOIN replies r ON res.parent_type = 'reply' AND r.id = res.parent_id
+		)
+		UPDATE boards
+		SET is_active = false, updated_at = $2
+		WHERE is_active = true AND deleted_at IS NULL AND created_at < $1
+		AND id NOT IN (
+			SELECT p.board_id
+			FROM resolved res
+			JOIN posts p ON res.parent_type = 'post' AND p.id = res.parent_id
+		)
+		RETURNING id
+	`
+
+	err := r.GetDB().SelectContext(ctx, &deactivated, query, since, models.NowUTC())
+	if err != nil {
+		return nil, err
+	}
+
+	return deactivated, nil
+}
+
+// SetPostPolicy sets the post_policy of a board
+func (r *boardRepository) SetPostPolicy(ctx context.Context, id uuid.UUID, policy string) error {
+	query := `
+		UPDATE boards
+		SET post_policy = $1, updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`
+
+	now := models.NowUTC()
+
+	_, err := r.GetDB().ExecContext(ctx, query, policy, now, id)
+	return err
+}
+
+// SetVisibility sets the visibility of a board
+func (r *boardRepository) SetVisibility(ctx context.Context, id uuid.UUID, visibility string) error {
+	query := `
+		UPDATE boards
+		SET visibility = $1, updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`
+
+	now := models.NowUTC()
+
+	_, err := r.GetDB().ExecContext(ctx, query, visibility, now, id)
+	return err
+}
+
+// SetArchived sets the is_archived status of a board
+func (r *boardRepository) SetArchived(ctx context.Context, id uuid.UUID, isArchived bool) error {
+	query := `
+		UPDATE boards
+		SET is_archived = $1, updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`
+
+	now := models.NowUTC()
+
+	_, err := r.GetDB().ExecContext(ctx, query, isArchived, now, id)
+	return err
+}
+
+// SetWeightedVoting sets the weighted_voting setting of a board
+func (r *boardRepository) SetWeightedVoting(ctx context.Context, id uuid.UUID, weightedVoting bool) error {
+	query := `
+		UPDATE boards
+		SET weighted_voting = $1, updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`
+
+	now := models.NowUTC()
+
+	_, err := r.GetDB().ExecContext(ctx, query, weightedVoting, now, id)
+	return err
+}
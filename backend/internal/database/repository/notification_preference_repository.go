@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/garrettallen/aiboards/backend/internal/models"
+)
+
+// NotificationPreferenceRepository defines the interface for notification preference database operations
+type NotificationPreferenceRepository interface {
+	Repository
+	GetByAgentID(ctx context.Context, agentID uuid.UUID) (*models.NotificationPreference, error)
+	Upsert(ctx context.Context, preference *models.NotificationPreference) error
+	GetByDigestFrequency(ctx context.Context, frequency models.DigestFrequency) ([]*models.NotificationPreference, error)
+	UpdateLastDigestAt(ctx context.Context, agentID uuid.UUID, sentAt time.Time) error
+	GetThreadReplySubscribers(ctx context.Context, postID uuid.UUID, excludeAgentIDs []uuid.UUID) ([]uuid.UUID, error)
+}
+
+// notificationPreferenceRepository implements the NotificationPreferenceRepository interface
+type notificationPreferenceRepository struct {
+	*BaseRepository
+}
+
+// NewNotificationPreferenceRepository creates a new NotificationPreferenceRepository
+func NewNotificationPreferenceRepository(db *sqlx.DB) NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// GetByAgentID retrieves the notification preferences of an agent
+func (r *notificationPreferenceRepository) GetByAgentID(ctx context.Context, agentID uuid.UUID) (*models.NotificationPreference, error) {
+	var preference models.NotificationPreference
+
+	query := `
+		SELECT agent_id, email_enabled, digest_frequency, thread_replies_enabled, last_digest_at, created_at, updated_at
+		FROM notification_preferences
+		WHERE agent_id = $1
+	`
+
+	err := r.GetDB().GetContext(ctx, &preference, query, agentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &preference, nil
+}
+
+// Upsert creates or updates the notification preferences of an agent
+func (r *notificationPreferenceRepository) Upsert(ctx context.Context, preference *models.NotificationPreference) error {
+	query := `
+		INSERT INTO notification_preferences (agent_id, email_enabled, digest_frequency, thread_replies_enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (agent_id) DO UPDATE
+		SET email_enabled = EXCLUDED.email_enabled, digest_frequency = EXCLUDED.digest_frequency,
+		    thread_replies_enabled = EXCLUDED.thread_replies_enabled, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.GetDB().ExecContext(
+		ctx,
+		query,
+		preference.AgentID,
+		preference.EmailEnabled,
+		preference.DigestFrequency,
+		preference.ThreadRepliesEnabled,
+		preference.CreatedAt,
+		preference.UpdatedAt,
+	)
+
+	return err
+}
+
+// GetByDigestFrequency retrieves the preferences of all agents subscribed to the given digest frequency
+func (r *notificationPreferenceRepository) GetByDigestFrequency(ctx context.Context, frequency models.DigestFrequency) ([]*models.NotificationPreference, error) {
+	preferences := []*models.NotificationPreference{}
+
+	query := `
+		SELECT agent_id, email_enabled, digest_frequency, thread_replies_enabled, last_digest_at, created_at, updated_at
+		FROM notification_preferences
+		WHERE digest_frequency = $1
+		ORDER BY agent_id
+	`
+
+	err := r.GetDB().SelectContext(ctx, &preferences, query, frequency)
+	if err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+// UpdateLastDigestAt records when the last digest email was sent to an agent
+func (r *notificationPreferenceRepository) UpdateLastDigestAt(ctx context.Context, agentID uuid.UUID, sentAt time.Time) error {
+	query := `
+		UPDATE notification_preferences
+		SET last_digest_at = $1
+		WHERE agent_id = $2
+	`
+
+	_, err := r.GetDB().ExecContext(ctx, query, sentAt, agentID)
+	return err
+}
+
+// GetThreadReplySubscribers returns the distinct agents who have replied anywhere in a post's
+// thread and haven't turned thread reply notifications off, leaving out excludeAgentIDs.
+// Agents without stored preferences get the default, which is enabled.
+func (r *notificationPreferenceRepository) GetThreadReplySubscribers(ctx context.Context, postID uuid.UUID, excludeAgentIDs []uuid.UUID) ([]uuid.UUID, error) {
+	agentIDs := []uuid.UUID{}
+
+	excludeStrings := make([]string, len(excludeAgentIDs))
+	for i, id := range excludeAgentIDs {
+		excludeStrings[i] = id.String()
+	}
+
+	query := `
+		WITH RECURSIVE thread AS (
+			SELECT id, agent_id FROM replies
+			WHERE parent_type = 'post' AND parent_id = $1 AND deleted_at IS NULL
+
+			UNION ALL
+
+			SELECT r.id, r.agent_id
+			FROM replies r
+			JOIN thread t ON r.parent_type = 'reply' AND r.parent_id = t.id
+			WHERE r.deleted_at IS NULL
+		)
+		SELECT DISTINCT t.agent_id
+		FROM thread t
+		LEFT JOIN notification_preferences np ON np.agent_id = t.agent_id
+		WHERE COALESCE(np.thread_replies_enabled, TRUE)
+		AND NOT (t.agent_id = ANY($2::uuid[]))
+		ORDER BY t.agent_id
+	`
+
+	err := r.GetDB().SelectContext(ctx, &agentIDs, query, postID, pq.Array(excludeStrings))
+	if err != nil {
+		return nil, err
+	}
+
+	return agentIDs, nil
+}
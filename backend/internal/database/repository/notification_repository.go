@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
 	"github.com/garrettallen/aiboards/backend/internal/models"
 )
@@ -18,10 +19,17 @@ type NotificationRepository interface {
 	Create(ctx context.Context, notification *models.Notification) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Notification, error)
 	GetByAgentID(ctx context.Context, agentID uuid.UUID, offset, limit int) ([]*models.Notification, error)
+	GetByAgentAndIDs(ctx context.Context, agentID uuid.UUID, ids []uuid.UUID) ([]*models.Notification, error)
 	MarkAsRead(ctx context.Context, id uuid.UUID) error
+	MarkAsUnread(ctx context.Context, id uuid.UUID) error
 	MarkAllAsRead(ctx context.Context, agentID uuid.UUID) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteReadBefore(ctx context.Context, agentID uuid.UUID, cutoff time.Time) (int, error)
 	CountUnread(ctx context.Context, agentID uuid.UUID) (int, error)
+	CountUnreadByType(ctx context.Context, agentID uuid.UUID) (map[string]int, error)
+	GetUnreadSince(ctx context.Context, agentID uuid.UUID, since time.Time) ([]*models.Notification, error)
+	RefreshRecent(ctx context.Context, notification *models.Notification, since time.Time) (*models.Notification, error)
+	CreateForAllAgents(ctx context.Context, notificationType, content, targetType string, targetID uuid.UUID, batchSize int) (int, error)
 }
 
 // notificationRepository implements the NotificationRepository interface
@@ -100,6 +108,34 @@ func (r *notificationRepository) GetByAgentID(ctx context.Context, agentID uuid.
 	return notifications, nil
 }
 
+// GetByAgentAndIDs retrieves the agent's notifications among ids, newest first. IDs that
+// don't exist or belong to another agent are left out.
+func (r *notificationRepository) GetByAgentAndIDs(ctx context.Context, agentID uuid.UUID, ids []uuid.UUID) ([]*models.Notification, error) {
+	notifications := []*models.Notification{}
+	if len(ids) == 0 {
+		return notifications, nil
+	}
+
+	idStrings := make([]string, len(ids))
+	for i, id := range ids {
+		idStrings[i] = id.String()
+	}
+
+	query := `
+		SELECT id, agent_id, type, content, target_type, target_id, is_read, created_at, read_at
+		FROM notifications
+		WHERE agent_id = $1 AND id = ANY($2::uuid[])
+		ORDER BY created_at DESC
+	`
+
+	err := r.GetDB().SelectContext(ctx, &notifications, query, agentID, pq.Array(idStrings))
+	if err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
 // MarkAsRead marks a notification as read
 func (r *notificationRepository) MarkAsRead(ctx context.Context, id uuid.UUID) error {
 	// Check if notification exists
@@ -108,7 +144,7 @@ func (r *notificationRepository) MarkAsRead(ctx context.Context, id uuid.UUID) e
 		return err
 	}
 
-	now := time.Now()
+	now := models.NowUTC()
 
 	query := `
 		UPDATE notifications
@@ -120,9 +156,21 @@ func (r *notificationRepository) MarkAsRead(ctx context.Context, id uuid.UUID) e
 	return err
 }
 
+// MarkAsUnread marks a notification as unread, clearing when it was read
+func (r *notificationRepository) MarkAsUnread(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE notifications
+		SET is_read = false, read_at = NULL
+		WHERE id = $1
+	`
+
+	_, err := r.GetDB().ExecContext(ctx, query, id)
+	return err
+}
+
 // MarkAllAsRead marks all notifications for an agent as read
 func (r *notificationRepository) MarkAllAsRead(ctx context.Context, agentID uuid.UUID) error {
-	now := time.Now()
+	now := models.NowUTC()
 
 	query := `
 		UPDATE notifications
@@ -134,6 +182,27 @@ func (r *notificationRepository) MarkAllAsRead(ctx context.Context, agentID uuid
 	return err
 }
 
+// DeleteReadBefore deletes an agent's read notifications created before cutoff and
+// returns how many were deleted
+func (r *notificationRepository) DeleteReadBefore(ctx context.Context, agentID uuid.UUID, cutoff time.Time) (int, error) {
+	query := `
+		DELETE FROM notifications
+		WHERE agent_id = $1 AND is_read = true AND created_at < $2
+	`
+
+	result, err := r.GetDB().ExecContext(ctx, query, agentID, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
+
 // Delete deletes a notification
 func (r *notificationRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	// Check if notification exists
@@ -168,3 +237,141 @@ func (r *notificationRepository) CountUnread(ctx context.Context, agentID uuid.U
 
 	return count, nil
 }
+
+// CountUnreadByType counts an agent's unread notifications per notification type. Types
+// without unread notifications are left out.
+func (r *notificationRepository) CountUnreadByType(ctx context.Context, agentID uuid.UUID) (map[string]int, error) {
+	var rows []struct {
+		Type  string `db:"type"`
+		Count int    `db:"count"`
+	}
+
+	query := `
+		SELECT type, COUNT(*) AS count
+		FROM notifications
+		WHERE agent_id = $1 AND is_read = false
+		GROUP BY type
+	`
+
+	err := r.GetDB().SelectContext(ctx, &rows, query, agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Type] = row.Count
+	}
+
+	return counts, nil
+}
+
+// GetUnreadSince retrieves the unread notifications for an agent created after the given time
+func (r *notificationRepository) GetUnreadSince(ctx context.Context, agentID uuid.UUID, since time.Time) ([]*models.Notification, error) {
+	notifications := []*models.Notification{}
+
+	query := `
+		SELECT id, agent_id, type, content, target_type, target_id, is_read, created_at, read_at
+		FROM notifications
+		WHERE agent_id = $1 AND is_read = false AND created_at > $2
+		ORDER BY created_at DESC
+	`
+
+	err := r.GetDB().SelectContext(ctx, &notifications, query, agentID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// RefreshRecent updates the agent's most recent notification with the same type and target created
+// after since, replacing its content and marking it unread again. It returns nil if there is none.
+func (r *notificationRepository) RefreshRecent(ctx context.Context, notification *models.Notification, since time.Time) (*models.Notification, error) {
+	var refreshed models.Notification
+
+	query := `
+		UPDATE notifications
+		SET content = $1, is_read = false, read_at = NULL, created_at = $2
+		WHERE id = (
+			SELECT id
+			FROM notifications
+			WHERE agent_id = $3 AND type = $4 AND target_type = $5 AND target_id = $6 AND created_at > $7
+			ORDER BY created_at DESC
+			LIMIT 1
+		)
+		RETURNING id, agent_id, type, content, target_type, target_id, is_read, created_at, read_at
+	`
+
+	err := r.GetDB().GetContext(
+		ctx,
+		&refreshed,
+		query,
+		notification.Content,
+		notification.CreatedAt,
+		notification.AgentID,
+		notification.Type,
+		notification.TargetType,
+		notification.TargetID,
+		since,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &refreshed, nil
+}
+
+// CreateForAllAgents gives every agent that hasn't been deleted an unread copy of the same
+// notification and returns the number created. Agents are walked in ID order, inserting
+// batchSize notifications per statement, so each agent gets exactly one copy however many
+// agents there are. All batches run in one transaction, so a failure leaves no copies behind
+// and the broadcast can be retried without duplicating it.
+func (r *notificationRepository) CreateForAllAgents(ctx context.Context, notificationType, content, targetType string, targetID uuid.UUID, batchSize int) (int, error) {
+	query := `
+		WITH batch AS (
+			SELECT id FROM agents
+			WHERE deleted_at IS NULL AND id > $1
+			ORDER BY id
+			LIMIT $2
+		),
+		inserted AS (
+			INSERT INTO notifications (agent_id, type, content, target_type, target_id, is_read, created_at)
+			SELECT id, $3, $4, $5, $6, false, $7
+			FROM batch
+		)
+		SELECT COUNT(*) AS count, (SELECT id FROM batch ORDER BY id DESC LIMIT 1) AS last_id
+		FROM batch
+	`
+
+	now := models.NowUTC()
+	total := 0
+
+	err := r.Transaction(ctx, func(tx *sqlx.Tx) error {
+		lastID := uuid.Nil
+		for {
+			var result struct {
+				Count  int           `db:"count"`
+				LastID uuid.NullUUID `db:"last_id"`
+			}
+			err := tx.GetContext(ctx, &result, query, lastID, batchSize, notificationType, content, targetType, targetID, now)
+			if err != nil {
+				return err
+			}
+
+			total += result.Count
+			if result.Count < batchSize || !result.LastID.Valid {
+				return nil
+			}
+			lastID = result.LastID.UUID
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
 	"github.com/garrettallen/aiboards/backend/internal/models"
 )
@@ -17,16 +18,37 @@ type PostRepository interface {
 	Repository
 	Create(ctx context.Context, post *models.Post) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Post, error)
+	FindByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*models.Post, error)
 	GetByBoardID(ctx context.Context, boardID uuid.UUID, offset, limit int) ([]*models.Post, error)
-	GetByAgentID(ctx context.Context, agentID uuid.UUID, offset, limit int) ([]*models.Post, error)
-	Update(ctx context.Context, post *models.Post) error
-	Delete(ctx context.Context, id uuid.UUID) error
-	UpdateVoteCount(ctx context.Context, id uuid.UUID, value int) error
+	GetByBoardIDBefore(ctx context.Context, boardID uuid.UUID, beforeCreatedAt *time.Time, beforeID uuid.UUID, limit int) ([]*models.Post, error)
+	GetByAgentID(ctx context.Context, agentID uuid.UUID, includeAuthenticatedOnly bool, offset, limit int) ([]*models.Post, error)
+	GetLatestByAgentAndBoard(ctx context.Context, agentID, boardID uuid.UUID) (*models.Post, error)
+	GetTopByBoardID(ctx context.Context, boardID uuid.UUID, since time.Time, limit int) ([]*models.Post, error)
+	UpdateContent(ctx context.Context, post *models.Post) error
+	Delete(ctx context.Context, db sqlx.ExecerContext, id uuid.UUID) error
+	DeleteByAgentID(ctx context.Context, db sqlx.QueryerContext, agentID uuid.UUID, ids []uuid.UUID) ([]uuid.UUID, error)
+	Restore(ctx context.Context, db sqlx.ExecerContext, id uuid.UUID) error
+	SetLocked(ctx context.Context, id uuid.UUID, isLocked bool) error
+	UpdateVoteCount(ctx context.Context, id uuid.UUID, value int, weightedValue float64) error
 	UpdateReplyCount(ctx context.Context, id uuid.UUID, value int) error
 	CountByBoardID(ctx context.Context, boardID uuid.UUID) (int, error)
-	CountByAgentID(ctx context.Context, agentID uuid.UUID) (int, error)
+	CountByAgentID(ctx context.Context, agentID uuid.UUID, includeAuthenticatedOnly bool) (int, error)
+	GetBoardsByAgentID(ctx context.Context, agentID uuid.UUID, includeAuthenticatedOnly bool) ([]*models.BoardWithPostCount, error)
 	Search(ctx context.Context, boardID uuid.UUID, query string, offset, limit int) ([]*models.Post, error)
 	CountSearch(ctx context.Context, boardID uuid.UUID, query string) (int, error)
+	SearchAll(ctx context.Context, query string, includeAuthenticatedOnly bool, offset, limit int) ([]*models.PostSearchResult, error)
+	AdminList(ctx context.Context, opts models.PostListOptions, offset, limit int) ([]*models.Post, error)
+	AdminCount(ctx context.Context, opts models.PostListOptions) (int, error)
+	CountSearchAll(ctx context.Context, query string, includeAuthenticatedOnly bool) (int, error)
+	GetRecent(ctx context.Context, viewerID uuid.UUID, includeAuthenticatedOnly bool, offset, limit int) ([]*models.RecentPost, error)
+	CountRecent(ctx context.Context, viewerID uuid.UUID, includeAuthenticatedOnly bool) (int, error)
+	GetSimilar(ctx context.Context, postID uuid.UUID, includeAuthenticatedOnly bool, limit int) ([]*models.PostSearchResult, error)
+	RecountStats(ctx context.Context, id uuid.UUID) (bool, error)
+	RecountAllStats(ctx context.Context) (int, error)
+	CreateAttachment(ctx context.Context, attachment *models.PostAttachment) error
+	GetAttachments(ctx context.Context, postID uuid.UUID) ([]*models.PostAttachment, error)
+	CountAttachments(ctx context.Context, postID uuid.UUID) (int, error)
+	PurgeDeleted(ctx context.Context, cutoff time.Time) (int, error)
 }
 
 // postRepository implements the PostRepository interface
@@ -43,12 +65,17 @@ func NewPostRepository(db *sqlx.DB) PostRepository {
 
 // Create inserts a new post into the database
 func (r *postRepository) Create(ctx context.Context, post *models.Post) error {
+	return insertPost(ctx, r.GetDB(), post)
+}
+
+// insertPost inserts a post through db, which may be a transaction
+func insertPost(ctx context.Context, db sqlx.ExecerContext, post *models.Post) error {
 	query := `
-		INSERT INTO posts (id, board_id, agent_id, content, media_url, vote_count, reply_count, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO posts (id, board_id, agent_id, content, media_url, vote_count, reply_count, is_flagged, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
-	_, err := r.GetDB().ExecContext(
+	_, err := db.ExecContext(
 		ctx,
 		query,
 		post.ID,
@@ -58,6 +85,7 @@ func (r *postRepository) Create(ctx context.Context, post *models.Post) error {
 		post.MediaURL,
 		post.VoteCount,
 		post.ReplyCount,
+		post.IsFlagged,
 		post.CreatedAt,
 		post.UpdatedAt,
 	)
@@ -65,10 +93,19 @@ func (r *postRepository) Create(ctx context.Context, post *models.Post) error {
 	return err
 }
 
-// GetByID retrieves a post by ID
+// GetByID retrieves a post by ID, excluding soft-deleted posts
 func (r *postRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Post, error) {
+	return r.FindByID(ctx, id, false)
+}
+
+// FindByID retrieves a post by ID. Soft-deleted posts are only returned when
+// includeDeleted is set, which is reserved for admin paths.
+func (r *postRepository) FindByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*models.Post, error) {
 	var post models.Post
-	query := `SELECT * FROM posts WHERE id = $1 AND deleted_at IS NULL`
+	query := `SELECT * FROM posts WHERE id = $1`
+	if !includeDeleted {
+		query += ` AND deleted_at IS NULL`
+	}
 
 	err := r.GetDB().GetContext(ctx, &post, query, id)
 	if err != nil {
@@ -99,17 +136,48 @@ func (r *postRepository) GetByBoardID(ctx context.Context, boardID uuid.UUID, of
 	return posts, nil
 }
 
-// GetByAgentID retrieves posts created by an agent with pagination
-func (r *postRepository) GetByAgentID(ctx context.Context, agentID uuid.UUID, offset, limit int) ([]*models.Post, error) {
+// GetByBoardIDBefore retrieves a page of a board's posts ordered newest first by (created_at, id),
+// starting after the post identified by beforeCreatedAt and beforeID. A nil beforeCreatedAt
+// starts from the newest post.
+func (r *postRepository) GetByBoardIDBefore(ctx context.Context, boardID uuid.UUID, beforeCreatedAt *time.Time, beforeID uuid.UUID, limit int) ([]*models.Post, error) {
+	posts := []*models.Post{}
+
+	var err error
+	if beforeCreatedAt == nil {
+		query := `
+			SELECT * FROM posts
+			WHERE board_id = $1 AND deleted_at IS NULL
+			ORDER BY created_at DESC, id DESC
+			LIMIT $2
+		`
+		err = r.GetDB().SelectContext(ctx, &posts, query, boardID, limit)
+	} else {
+		query := `
+			SELECT * FROM posts
+			WHERE board_id = $1 AND deleted_at IS NULL AND (created_at, id) < ($2, $3)
+			ORDER BY created_at DESC, id DESC
+			LIMIT $4
+		`
+		err = r.GetDB().SelectContext(ctx, &posts, query, boardID, *beforeCreatedAt, beforeID, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// GetTopByBoardID retrieves a board's highest-voted posts created since the given time
+func (r *postRepository) GetTopByBoardID(ctx context.Context, boardID uuid.UUID, since time.Time, limit int) ([]*models.Post, error) {
 	posts := []*models.Post{}
 	query := `
 		SELECT * FROM posts
-		WHERE agent_id = $1 AND deleted_at IS NULL
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
+		WHERE board_id = $1 AND created_at >= $2 AND deleted_at IS NULL
+		ORDER BY vote_count DESC, created_at DESC
+		LIMIT $3
 	`
 
-	err := r.GetDB().SelectContext(ctx, &posts, query, agentID, limit, offset)
+	err := r.GetDB().SelectContext(ctx, &posts, query, boardID, since, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -117,59 +185,153 @@ func (r *postRepository) GetByAgentID(ctx context.Context, agentID uuid.UUID, of
 	return posts, nil
 }
 
-// Update updates an existing post
-func (r *postRepository) Update(ctx context.Context, post *models.Post) error {
+// GetByAgentID retrieves posts created by an agent with pagination. Posts on boards that
+// require authentication are only included when includeAuthenticatedOnly is set.
+func (r *postRepository) GetByAgentID(ctx context.Context, agentID uuid.UUID, includeAuthenticatedOnly bool, offset, limit int) ([]*models.Post, error) {
+	posts := []*models.Post{}
+	query := `
+		SELECT * FROM posts p
+		WHERE p.agent_id = $1 AND p.deleted_at IS NULL
+		AND ($2 OR EXISTS (SELECT 1 FROM boards b WHERE b.id = p.board_id AND b.visibility = $3))
+		ORDER BY p.created_at DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	err := r.GetDB().SelectContext(ctx, &posts, query, agentID, includeAuthenticatedOnly, models.BoardVisibilityPublic, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// GetLatestByAgentAndBoard retrieves the newest post an agent has made on a board
+func (r *postRepository) GetLatestByAgentAndBoard(ctx context.Context, agentID, boardID uuid.UUID) (*models.Post, error) {
+	var post models.Post
+	query := `
+		SELECT * FROM posts
+		WHERE agent_id = $1 AND board_id = $2 AND deleted_at IS NULL
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`
+
+	err := r.GetDB().GetContext(ctx, &post, query, agentID, boardID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Agent has not posted on the board
+		}
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+// UpdateContent writes a post's content, media URL and edited_at. Vote and reply
+// counts are maintained separately by UpdateVoteCount and UpdateReplyCount.
+func (r *postRepository) UpdateContent(ctx context.Context, post *models.Post) error {
 	query := `
 		UPDATE posts
-		SET board_id = $1, agent_id = $2, content = $3, media_url = $4, 
-		    vote_count = $5, reply_count = $6, updated_at = $7, deleted_at = $8
-		WHERE id = $9
+		SET content = $1, media_url = $2, is_flagged = $3, edited_at = $4, updated_at = $5
+		WHERE id = $6
 	`
 
-	post.UpdatedAt = time.Now()
+	post.UpdatedAt = models.NowUTC()
 
 	_, err := r.GetDB().ExecContext(
 		ctx,
 		query,
-		post.BoardID,
-		post.AgentID,
 		post.Content,
 		post.MediaURL,
-		post.VoteCount,
-		post.ReplyCount,
+		post.IsFlagged,
+		post.EditedAt,
 		post.UpdatedAt,
-		post.DeletedAt,
 		post.ID,
 	)
 
 	return err
 }
 
-// Delete soft-deletes a post
-func (r *postRepository) Delete(ctx context.Context, id uuid.UUID) error {
+// Delete soft-deletes a post through db, which may be a transaction
+func (r *postRepository) Delete(ctx context.Context, db sqlx.ExecerContext, id uuid.UUID) error {
 	query := `
 		UPDATE posts
 		SET deleted_at = $1, updated_at = $1
 		WHERE id = $2 AND deleted_at IS NULL
 	`
 
-	now := time.Now()
+	now := models.NowUTC()
+
+	_, err := db.ExecContext(ctx, query, now, id)
+	return err
+}
+
+// DeleteByAgentID soft-deletes those of the given posts that belong to the agent, in a
+// single statement, and returns the IDs that were deleted. Other agents' posts, unknown
+// IDs and already-deleted posts are skipped. The statement runs through db, which may be a
+// transaction.
+func (r *postRepository) DeleteByAgentID(ctx context.Context, db sqlx.QueryerContext, agentID uuid.UUID, ids []uuid.UUID) ([]uuid.UUID, error) {
+	deleted := []uuid.UUID{}
+	if len(ids) == 0 {
+		return deleted, nil
+	}
+
+	idStrings := make([]string, len(ids))
+	for i, id := range ids {
+		idStrings[i] = id.String()
+	}
+
+	query := `
+		UPDATE posts
+		SET deleted_at = $1, updated_at = $1
+		WHERE id = ANY($2::uuid[]) AND agent_id = $3 AND deleted_at IS NULL
+		RETURNING id
+	`
+
+	now := models.NowUTC()
+
+	err := sqlx.SelectContext(ctx, db, &deleted, query, now, pq.Array(idStrings), agentID)
+	if err != nil {
+		return nil, err
+	}
 
-	_, err := r.GetDB().ExecContext(ctx, query, now, id)
+	return deleted, nil
+}
+
+// Restore clears the soft-delete marker of a post through db, which may be a transaction
+func (r *postRepository) Restore(ctx context.Context, db sqlx.ExecerContext, id uuid.UUID) error {
+	query := `
+		UPDATE posts
+		SET deleted_at = NULL, updated_at = $1
+		WHERE id = $2 AND deleted_at IS NOT NULL
+	`
+
+	_, err := db.ExecContext(ctx, query, models.NowUTC(), id)
 	return err
 }
 
-// UpdateVoteCount updates the vote count for a post
-func (r *postRepository) UpdateVoteCount(ctx context.Context, id uuid.UUID, value int) error {
+// SetLocked sets the is_locked status of a post
+func (r *postRepository) SetLocked(ctx context.Context, id uuid.UUID, isLocked bool) error {
 	query := `
 		UPDATE posts
-		SET vote_count = vote_count + $1, updated_at = $2
+		SET is_locked = $1, updated_at = $2
 		WHERE id = $3 AND deleted_at IS NULL
 	`
 
-	now := time.Now()
+	_, err := r.GetDB().ExecContext(ctx, query, isLocked, models.NowUTC(), id)
+	return err
+}
 
-	_, err := r.GetDB().ExecContext(ctx, query, value, now, id)
+// UpdateVoteCount adds value to the vote count and weightedValue to the weighted score of a post
+func (r *postRepository) UpdateVoteCount(ctx context.Context, id uuid.UUID, value int, weightedValue float64) error {
+	query := `
+		UPDATE posts
+		SET vote_count = vote_count + $1, weighted_score = weighted_score + $2, updated_at = $3
+		WHERE id = $4 AND deleted_at IS NULL
+	`
+
+	now := models.NowUTC()
+
+	_, err := r.GetDB().ExecContext(ctx, query, value, weightedValue, now, id)
 	return err
 }
 
@@ -181,7 +343,7 @@ func (r *postRepository) UpdateReplyCount(ctx context.Context, id uuid.UUID, val
 		WHERE id = $3 AND deleted_at IS NULL
 	`
 
-	now := time.Now()
+	now := models.NowUTC()
 
 	_, err := r.GetDB().ExecContext(ctx, query, value, now, id)
 	return err
@@ -200,12 +362,16 @@ func (r *postRepository) CountByBoardID(ctx context.Context, boardID uuid.UUID)
 	return count, nil
 }
 
-// CountByAgentID counts the number of posts created by an agent
-func (r *postRepository) CountByAgentID(ctx context.Context, agentID uuid.UUID) (int, error) {
+// CountByAgentID counts the posts GetByAgentID can return
+func (r *postRepository) CountByAgentID(ctx context.Context, agentID uuid.UUID, includeAuthenticatedOnly bool) (int, error) {
 	var count int
-	query := `SELECT COUNT(*) FROM posts WHERE agent_id = $1 AND deleted_at IS NULL`
+	query := `
+		SELECT COUNT(*) FROM posts p
+		WHERE p.agent_id = $1 AND p.deleted_at IS NULL
+		AND ($2 OR EXISTS (SELECT 1 FROM boards b WHERE b.id = p.board_id AND b.visibility = $3))
+	`
 
-	err := r.GetDB().GetContext(ctx, &count, query, agentID)
+	err := r.GetDB().GetContext(ctx, &count, query, agentID, includeAuthenticatedOnly, models.BoardVisibilityPublic)
 	if err != nil {
 		return 0, err
 	}
@@ -213,6 +379,29 @@ func (r *postRepository) CountByAgentID(ctx context.Context, agentID uuid.UUID)
 	return count, nil
 }
 
+// GetBoardsByAgentID retrieves the distinct boards an agent has posted to, with the
+// number of (non-deleted) posts the agent made on each. Boards that require authentication
+// are only included when includeAuthenticatedOnly is set.
+func (r *postRepository) GetBoardsByAgentID(ctx context.Context, agentID uuid.UUID, includeAuthenticatedOnly bool) ([]*models.BoardWithPostCount, error) {
+	boards := []*models.BoardWithPostCount{}
+	query := `
+		SELECT b.*, COUNT(p.id) AS post_count
+		FROM posts p
+		JOIN boards b ON b.id = p.board_id
+		WHERE p.agent_id = $1 AND p.deleted_at IS NULL AND b.deleted_at IS NULL
+		AND ($2 OR b.visibility = $3)
+		GROUP BY b.id
+		ORDER BY post_count DESC, b.title ASC
+	`
+
+	err := r.GetDB().SelectContext(ctx, &boards, query, agentID, includeAuthenticatedOnly, models.BoardVisibilityPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	return boards, nil
+}
+
 // Search searches for posts by content within a specific board
 func (r *postRepository) Search(ctx context.Context, boardID uuid.UUID, query string, offset, limit int) ([]*models.Post, error) {
 	posts := []*models.Post{}
@@ -225,7 +414,9 @@ func (r *postRepository) Search(ctx context.Context, boardID uuid.UUID, query st
 		LIMIT $3 OFFSET $4
 	`
 	
-	err := r.GetDB().SelectContext(ctx, &posts, searchQuery, boardID, "%"+query+"%", limit, offset)
+	err := TimeQuery("posts.search", func() error {
+		return r.GetDB().SelectContext(ctx, &posts, searchQuery, boardID, "%"+query+"%", limit, offset)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -250,3 +441,310 @@ func (r *postRepository) CountSearch(ctx context.Context, boardID uuid.UUID, que
 	
 	return count, nil
 }
+
+// SearchAll runs a full-text search over post content across all active boards,
+// ordered by relevance. The expression matches the idx_posts_content_fts index. Posts on
+// boards that require authentication are only included when includeAuthenticatedOnly is set.
+func (r *postRepository) SearchAll(ctx context.Context, query string, includeAuthenticatedOnly bool, offset, limit int) ([]*models.PostSearchResult, error) {
+	results := []*models.PostSearchResult{}
+	searchQuery := `
+		SELECT p.*, b.title AS board_title
+		FROM posts p
+		JOIN boards b ON b.id = p.board_id
+		WHERE p.deleted_at IS NULL
+		AND b.deleted_at IS NULL AND b.is_active
+		AND ($2 OR b.visibility = $3)
+		AND to_tsvector('english', p.content) @@ plainto_tsquery('english', $1)
+		ORDER BY ts_rank(to_tsvector('english', p.content), plainto_tsquery('english', $1)) DESC, p.created_at DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	err := TimeQuery("posts.search_all", func() error {
+		return r.GetDB().SelectContext(ctx, &results, searchQuery, query, includeAuthenticatedOnly, models.BoardVisibilityPublic, limit, offset)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// GetRecent retrieves the newest posts across all active boards, with their board's title
+// and author's name. Posts on boards that require authentication are only included when
+// includeAuthenticatedOnly is set, and posts by agents viewerID has blocked are left out.
+func (r *postRepository) GetRecent(ctx context.Context, viewerID uuid.UUID, includeAuthenticatedOnly bool, offset, limit int) ([]*models.RecentPost, error) {
+	posts := []*models.RecentPost{}
+	query := `
+		SELECT p.*, b.title AS board_title, COALESCE(a.name, '') AS agent_name
+		FROM posts p
+		JOIN boards b ON b.id = p.board_id
+		LEFT JOIN agents a ON a.id = p.agent_id
+		WHERE p.deleted_at IS NULL
+		AND b.deleted_at IS NULL AND b.is_active
+		AND ($1 OR b.visibility = $2)
+		AND NOT EXISTS (SELECT 1 FROM agent_blocks ab WHERE ab.blocker_id = $3 AND ab.blocked_id = p.agent_id)
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	err := r.GetDB().SelectContext(ctx, &posts, query, includeAuthenticatedOnly, models.BoardVisibilityPublic, viewerID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// CountRecent counts the posts GetRecent can return
+func (r *postRepository) CountRecent(ctx context.Context, viewerID uuid.UUID, includeAuthenticatedOnly bool) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*)
+		FROM posts p
+		JOIN boards b ON b.id = p.board_id
+		WHERE p.deleted_at IS NULL
+		AND b.deleted_at IS NULL AND b.is_active
+		AND ($1 OR b.visibility = $2)
+		AND NOT EXISTS (SELECT 1 FROM agent_blocks ab WHERE ab.blocker_id = $3 AND ab.blocked_id = p.agent_id)
+	`
+
+	err := r.GetDB().GetContext(ctx, &count, query, includeAuthenticatedOnly, models.BoardVisibilityPublic, viewerID)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CountSearchAll counts the posts SearchAll can return
+func (r *postRepository) CountSearchAll(ctx context.Context, query string, includeAuthenticatedOnly bool) (int, error) {
+	var count int
+	searchQuery := `
+		SELECT COUNT(*)
+		FROM posts p
+		JOIN boards b ON b.id = p.board_id
+		WHERE p.deleted_at IS NULL
+		AND b.deleted_at IS NULL AND b.is_active
+		AND ($2 OR b.visibility = $3)
+		AND to_tsvector('english', p.content) @@ plainto_tsquery('english', $1)
+	`
+
+	err := r.GetDB().GetContext(ctx, &count, searchQuery, query, includeAuthenticatedOnly, models.BoardVisibilityPublic)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetSimilar finds posts on active boards that share words with the given post, most
+// similar first. The source post's words are OR'ed into a full-text query and matches
+// are ranked with ts_rank, so the idx_posts_content_fts index serves the lookup. The
+// source post itself and deleted posts are left out, as are posts on boards that require
+// authentication unless includeAuthenticatedOnly is set.
+func (r *postRepository) GetSimilar(ctx context.Context, postID uuid.UUID, includeAuthenticatedOnly bool, limit int) ([]*models.PostSearchResult, error) {
+	results := []*models.PostSearchResult{}
+	query := `
+		WITH source AS (
+			SELECT replace(plainto_tsquery('english', content)::text, ' & ', ' | ')::tsquery AS query
+			FROM posts
+			WHERE id = $1
+		)
+		SELECT p.*, b.title AS board_title
+		FROM posts p
+		JOIN boards b ON b.id = p.board_id
+		CROSS JOIN source s
+		WHERE p.id <> $1
+		AND p.deleted_at IS NULL
+		AND b.deleted_at IS NULL AND b.is_active
+		AND ($2 OR b.visibility = $3)
+		AND to_tsvector('english', p.content) @@ s.query
+		ORDER BY ts_rank(to_tsvector('english', p.content), s.query) DESC, p.created_at DESC
+		LIMIT $4
+	`
+
+	err := r.GetDB().SelectContext(ctx, &results, query, postID, includeAuthenticatedOnly, models.BoardVisibilityPublic, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// AdminList retrieves posts across all boards matching opts, including soft-deleted posts
+func (r *postRepository) AdminList(ctx context.Context, opts models.PostListOptions, offset, limit int) ([]*models.Post, error) {
+	posts := []*models.Post{}
+	query := `
+		SELECT * FROM posts
+		WHERE ($1::timestamptz IS NULL OR created_at >= $1)
+		AND ($2::timestamptz IS NULL OR created_at <= $2)
+		AND ($3::uuid IS NULL OR agent_id = $3)
+		ORDER BY created_at DESC, id ASC
+		LIMIT $4 OFFSET $5
+	`
+
+	err := r.GetDB().SelectContext(ctx, &posts, query, opts.Since, opts.Until, opts.AgentID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// AdminCount counts the posts matching opts, including soft-deleted posts
+func (r *postRepository) AdminCount(ctx context.Context, opts models.PostListOptions) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM posts
+		WHERE ($1::timestamptz IS NULL OR created_at >= $1)
+		AND ($2::timestamptz IS NULL OR created_at <= $2)
+		AND ($3::uuid IS NULL OR agent_id = $3)
+	`
+
+	err := r.GetDB().GetContext(ctx, &count, query, opts.Since, opts.Until, opts.AgentID)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// RecountStats recomputes the denormalized vote count, weighted score and reply count for a
+// post from the replies and votes tables. Returns true if the stored counts were out of date.
+func (r *postRepository) RecountStats(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE posts p
+		SET reply_count = s.reply_count, vote_count = s.vote_count, weighted_score = s.weighted_score
+		FROM (
+			SELECT
+				(SELECT COUNT(*) FROM replies r
+				 WHERE r.parent_type = 'post' AND r.parent_id = $1 AND r.deleted_at IS NULL) AS reply_count,
+				(SELECT COALESCE(SUM(v.value), 0) FROM votes v
+				 WHERE v.target_type = 'post' AND v.target_id = $1) AS vote_count,
+				(SELECT COALESCE(SUM(v.value * v.weight), 0) FROM votes v
+				 WHERE v.target_type = 'post' AND v.target_id = $1) AS weighted_score
+		) s
+		WHERE p.id = $1 AND p.deleted_at IS NULL
+		AND (p.reply_count <> s.reply_count OR p.vote_count <> s.vote_count
+			OR ABS(p.weighted_score - s.weighted_score) > 1e-9)
+	`
+
+	result, err := r.GetDB().ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// RecountAllStats recomputes the denormalized vote counts, weighted scores and reply counts for every post
+// and returns the number of posts whose counts were corrected
+func (r *postRepository) RecountAllStats(ctx context.Context) (int, error) {
+	query := `
+		WITH stats AS (
+			SELECT p.id,
+				(SELECT COUNT(*) FROM replies r
+				 WHERE r.parent_type = 'post' AND r.parent_id = p.id AND r.deleted_at IS NULL) AS reply_count,
+				(SELECT COALESCE(SUM(v.value), 0) FROM votes v
+				 WHERE v.target_type = 'post' AND v.target_id = p.id) AS vote_count,
+				(SELECT COALESCE(SUM(v.value * v.weight), 0) FROM votes v
+				 WHERE v.target_type = 'post' AND v.target_id = p.id) AS weighted_score
+			FROM posts p
+			WHERE p.deleted_at IS NULL
+		)
+		UPDATE posts p
+		SET reply_count = stats.reply_count, vote_count = stats.vote_count, weighted_score = stats.weighted_score
+		FROM stats
+		WHERE p.id = stats.id
+		AND (p.reply_count <> stats.reply_count OR p.vote_count <> stats.vote_count
+			OR ABS(p.weighted_score - stats.weighted_score) > 1e-9)
+	`
+
+	result, err := r.GetDB().ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
+
+// CreateAttachment inserts a new post attachment
+func (r *postRepository) CreateAttachment(ctx context.Context, attachment *models.PostAttachment) error {
+	query := `
+		INSERT INTO post_attachments (id, post_id, url, position, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.GetDB().ExecContext(
+		ctx,
+		query,
+		attachment.ID,
+		attachment.PostID,
+		attachment.URL,
+		attachment.Position,
+		attachment.CreatedAt,
+	)
+
+	return err
+}
+
+// GetAttachments retrieves a post's attachments in order
+func (r *postRepository) GetAttachments(ctx context.Context, postID uuid.UUID) ([]*models.PostAttachment, error) {
+	attachments := []*models.PostAttachment{}
+	query := `SELECT * FROM post_attachments WHERE post_id = $1 ORDER BY position ASC`
+
+	err := r.GetDB().SelectContext(ctx, &attachments, query, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// CountAttachments counts the attachments on a post
+func (r *postRepository) CountAttachments(ctx context.Context, postID uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM post_attachments WHERE post_id = $1`
+
+	err := r.GetDB().GetContext(ctx, &count, query, postID)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// PurgeDeleted hard-deletes posts soft-deleted before cutoff that have no replies left.
+// Posts that still have replies are kept so threads are never orphaned.
+func (r *postRepository) PurgeDeleted(ctx context.Context, cutoff time.Time) (int, error) {
+	query := `
+		DELETE FROM posts p
+		WHERE p.deleted_at IS NOT NULL AND p.deleted_at < $1
+		AND NOT EXISTS (
+			SELECT 1 FROM replies r
+			WHERE r.parent_type = 'post' AND r.parent_id = p.id
+		)
+	`
+
+	result, err := r.GetDB().ExecContext(ctx, query, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// RateLimitRepository defines the interface for the rate limiter's shared hit counters
+type RateLimitRepository interface {
+	Repository
+	Increment(ctx context.Context, key string, windowStart, expiresAt time.Time) (int, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// rateLimitRepository implements the RateLimitRepository interface
+type rateLimitRepository struct {
+	*BaseRepository
+}
+
+// NewRateLimitRepository creates a new RateLimitRepository
+func NewRateLimitRepository(db *sqlx.DB) RateLimitRepository {
+	return &rateLimitRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// Increment adds a hit to key's counter for the window starting at windowStart and returns
+// the new count. The upsert is atomic, so concurrent hits from any server are all counted.
+func (r *rateLimitRepository) Increment(ctx context.Context, key string, windowStart, expiresAt time.Time) (int, error) {
+	var count int
+	query := `
+		INSERT INTO rate_limit_counters (key, window_start, count, expires_at)
+		VALUES ($1, $2, 1, $3)
+		ON CONFLICT (key, window_start) DO UPDATE SET count = rate_limit_counters.count + 1
+		RETURNING count
+	`
+
+	err := r.GetDB().GetContext(ctx, &count, query, key, windowStart.UTC(), expiresAt.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// DeleteExpired deletes counters that expired before now and returns how many were deleted
+func (r *rateLimitRepository) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	query := `DELETE FROM rate_limit_counters WHERE expires_at < $1`
+
+	result, err := r.GetDB().ExecContext(ctx, query, now.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
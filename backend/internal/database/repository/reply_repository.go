@@ -17,15 +17,25 @@ type ReplyRepository interface {
 	Repository
 	Create(ctx context.Context, reply *models.Reply) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Reply, error)
-	GetByParentID(ctx context.Context, parentType string, parentID uuid.UUID, offset, limit int) ([]*models.Reply, error)
-	GetByAgentID(ctx context.Context, agentID uuid.UUID, offset, limit int) ([]*models.Reply, error)
-	Update(ctx context.Context, reply *models.Reply) error
+	FindByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*models.Reply, error)
+	GetPostID(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	GetAncestors(ctx context.Context, id uuid.UUID) ([]*models.Reply, error)
+	GetByParentID(ctx context.Context, parentType string, parentID uuid.UUID, sort string, offset, limit int) ([]*models.Reply, error)
+	GetByAgentID(ctx context.Context, agentID uuid.UUID, includeAuthenticatedOnly bool, offset, limit int) ([]*models.Reply, error)
+	GetByAgentIDWithContext(ctx context.Context, agentID uuid.UUID, includeAuthenticatedOnly bool, offset, limit int) ([]*models.ReplyWithContext, error)
+	UpdateContent(ctx context.Context, reply *models.Reply) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	UpdateVoteCount(ctx context.Context, id uuid.UUID, value int) error
+	Restore(ctx context.Context, id uuid.UUID) error
+	DeleteThread(ctx context.Context, db sqlx.ExecerContext, postID uuid.UUID) (int, error)
+	RestoreThread(ctx context.Context, db sqlx.ExecerContext, postID uuid.UUID) (int, error)
+	UpdateVoteCount(ctx context.Context, id uuid.UUID, value int, weightedValue float64) error
 	UpdateReplyCount(ctx context.Context, id uuid.UUID, value int) error
+	SetAccepted(ctx context.Context, postID, replyID uuid.UUID) error
 	CountByParentID(ctx context.Context, parentType string, parentID uuid.UUID) (int, error)
-	CountByAgentID(ctx context.Context, agentID uuid.UUID) (int, error)
+	CountByAgentID(ctx context.Context, agentID uuid.UUID, includeAuthenticatedOnly bool) (int, error)
 	GetThreadedReplies(ctx context.Context, postID uuid.UUID) ([]*models.Reply, error)
+	RecountAllStats(ctx context.Context) (int, error)
+	PurgeDeletedLeaves(ctx context.Context, cutoff time.Time) (int, error)
 }
 
 // replyRepository implements the ReplyRepository interface
@@ -43,8 +53,8 @@ func NewReplyRepository(db *sqlx.DB) ReplyRepository {
 // Create inserts a new reply into the database
 func (r *replyRepository) Create(ctx context.Context, reply *models.Reply) error {
 	query := `
-		INSERT INTO replies (id, parent_type, parent_id, agent_id, content, media_url, vote_count, reply_count, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO replies (id, parent_type, parent_id, agent_id, content, media_url, quoted_reply_id, vote_count, reply_count, is_flagged, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
 
 	_, err := r.GetDB().ExecContext(
@@ -56,8 +66,10 @@ func (r *replyRepository) Create(ctx context.Context, reply *models.Reply) error
 		reply.AgentID,
 		reply.Content,
 		reply.MediaURL,
+		reply.QuotedReplyID,
 		reply.VoteCount,
 		reply.ReplyCount,
+		reply.IsFlagged,
 		reply.CreatedAt,
 		reply.UpdatedAt,
 	)
@@ -65,10 +77,19 @@ func (r *replyRepository) Create(ctx context.Context, reply *models.Reply) error
 	return err
 }
 
-// GetByID retrieves a reply by ID
+// GetByID retrieves a reply by ID, excluding soft-deleted replies
 func (r *replyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Reply, error) {
+	return r.FindByID(ctx, id, false)
+}
+
+// FindByID retrieves a reply by ID. Soft-deleted replies are only returned when
+// includeDeleted is set, which is reserved for admin paths.
+func (r *replyRepository) FindByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*models.Reply, error) {
 	var reply models.Reply
-	query := `SELECT * FROM replies WHERE id = $1 AND deleted_at IS NULL`
+	query := `SELECT * FROM replies WHERE id = $1`
+	if !includeDeleted {
+		query += ` AND deleted_at IS NULL`
+	}
 
 	err := r.GetDB().GetContext(ctx, &reply, query, id)
 	if err != nil {
@@ -81,13 +102,78 @@ func (r *replyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Re
 	return &reply, nil
 }
 
-// GetByParentID retrieves replies for a parent (post or reply) with pagination
-func (r *replyRepository) GetByParentID(ctx context.Context, parentType string, parentID uuid.UUID, offset, limit int) ([]*models.Reply, error) {
+// GetPostID returns the ID of the post at the root of a reply's thread, walking up
+// through parent replies. It returns uuid.Nil if the reply doesn't exist.
+func (r *replyRepository) GetPostID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	var postID uuid.UUID
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT parent_type, parent_id FROM replies WHERE id = $1
+
+			UNION ALL
+
+			SELECT r.parent_type, r.parent_id
+			FROM replies r
+			JOIN ancestors a ON a.parent_type = 'reply' AND r.id = a.parent_id
+		)
+		SELECT parent_id FROM ancestors WHERE parent_type = 'post'
+	`
+
+	err := r.GetDB().GetContext(ctx, &postID, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, nil // Reply not found
+		}
+		return uuid.Nil, err
+	}
+
+	return postID, nil
+}
+
+// GetAncestors retrieves a reply and the replies above it, walking up through parent
+// replies: the reply first, then its parent, and so on up to the reply made directly on
+// the post. Deleted replies are walked through but left out of the result.
+func (r *replyRepository) GetAncestors(ctx context.Context, id uuid.UUID) ([]*models.Reply, error) {
 	replies := []*models.Reply{}
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT r.*, 0 AS depth FROM replies r WHERE r.id = $1
+
+			UNION ALL
+
+			SELECT r.*, a.depth + 1
+			FROM replies r
+			JOIN ancestors a ON a.parent_type = 'reply' AND r.id = a.parent_id
+		)
+		SELECT id, parent_type, parent_id, agent_id, content, media_url, quoted_reply_id,
+		       vote_count, weighted_score, reply_count, is_flagged, is_accepted, created_at, updated_at, edited_at, deleted_at
+		FROM ancestors
+		WHERE deleted_at IS NULL
+		ORDER BY depth ASC
+	`
+
+	err := r.GetDB().SelectContext(ctx, &replies, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return replies, nil
+}
+
+// GetByParentID retrieves replies for a parent (post or reply) with pagination,
+// ordered by one of models.ReplySortOrders (oldest first if sort is unknown)
+func (r *replyRepository) GetByParentID(ctx context.Context, parentType string, parentID uuid.UUID, sort string, offset, limit int) ([]*models.Reply, error) {
+	replies := []*models.Reply{}
+
+	order, ok := models.ReplySortOrders[sort]
+	if !ok {
+		order = models.ReplySortOrders[models.ReplySortOld]
+	}
+
 	query := `
 		SELECT * FROM replies
 		WHERE parent_type = $1 AND parent_id = $2 AND deleted_at IS NULL
-		ORDER BY created_at ASC
+		ORDER BY ` + order + `, id ASC
 		LIMIT $3 OFFSET $4
 	`
 
@@ -99,17 +185,79 @@ func (r *replyRepository) GetByParentID(ctx context.Context, parentType string,
 	return replies, nil
 }
 
-// GetByAgentID retrieves replies created by an agent with pagination
-func (r *replyRepository) GetByAgentID(ctx context.Context, agentID uuid.UUID, offset, limit int) ([]*models.Reply, error) {
+// agentRepliesVisibleQuery selects the IDs of an agent's ($1) replies that the caller may see:
+// replies in threads whose post or board is deleted are left out, as are replies on boards
+// that require authentication unless $2 is true, with $3 the public board visibility
+const agentRepliesVisibleQuery = `
+	WITH RECURSIVE roots AS (
+		-- Walk up from each reply until reaching the post at the root of its thread
+		SELECT id AS reply_id, parent_type, parent_id FROM replies
+		WHERE agent_id = $1 AND deleted_at IS NULL
+
+		UNION ALL
+
+		SELECT roots.reply_id, r.parent_type, r.parent_id
+		FROM replies r
+		JOIN roots ON roots.parent_type = 'reply' AND r.id = roots.parent_id
+	)
+	SELECT roots.reply_id
+	FROM roots
+	JOIN posts p ON p.id = roots.parent_id AND p.deleted_at IS NULL
+	JOIN boards b ON b.id = p.board_id AND b.deleted_at IS NULL
+	WHERE roots.parent_type = 'post' AND ($2 OR b.visibility = $3)
+`
+
+// GetByAgentID retrieves replies created by an agent with pagination. Replies in threads on
+// boards that require authentication are only included when includeAuthenticatedOnly is set.
+func (r *replyRepository) GetByAgentID(ctx context.Context, agentID uuid.UUID, includeAuthenticatedOnly bool, offset, limit int) ([]*models.Reply, error) {
 	replies := []*models.Reply{}
 	query := `
 		SELECT * FROM replies
-		WHERE agent_id = $1 AND deleted_at IS NULL
+		WHERE id IN (` + agentRepliesVisibleQuery + `)
 		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
+		LIMIT $4 OFFSET $5
+	`
+
+	err := r.GetDB().SelectContext(ctx, &replies, query, agentID, includeAuthenticatedOnly, models.BoardVisibilityPublic, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return replies, nil
+}
+
+// GetByAgentIDWithContext retrieves an agent's replies with pagination, newest first, each
+// with its thread's root post and board and a snippet of the post or reply it answered.
+// A snippet of a deleted parent reply is left empty. The replies are those GetByAgentID returns.
+func (r *replyRepository) GetByAgentIDWithContext(ctx context.Context, agentID uuid.UUID, includeAuthenticatedOnly bool, offset, limit int) ([]*models.ReplyWithContext, error) {
+	replies := []*models.ReplyWithContext{}
+	query := `
+		WITH RECURSIVE page AS (
+			SELECT * FROM replies
+			WHERE id IN (` + agentRepliesVisibleQuery + `)
+			ORDER BY created_at DESC
+			LIMIT $4 OFFSET $5
+		), roots AS (
+			-- Walk up from each reply until reaching the post at the root of its thread
+			SELECT id AS reply_id, parent_type, parent_id FROM page
+
+			UNION ALL
+
+			SELECT roots.reply_id, r.parent_type, r.parent_id
+			FROM replies r
+			JOIN roots ON roots.parent_type = 'reply' AND r.id = roots.parent_id
+		)
+		SELECT page.*, p.id AS post_id, p.board_id, b.title AS board_title,
+		       LEFT(CASE WHEN page.parent_type = 'post' THEN p.content ELSE COALESCE(pr.content, '') END, $6) AS parent_snippet
+		FROM page
+		JOIN roots ON roots.reply_id = page.id AND roots.parent_type = 'post'
+		JOIN posts p ON p.id = roots.parent_id
+		JOIN boards b ON b.id = p.board_id
+		LEFT JOIN replies pr ON page.parent_type = 'reply' AND pr.id = page.parent_id AND pr.deleted_at IS NULL
+		ORDER BY page.created_at DESC
 	`
 
-	err := r.GetDB().SelectContext(ctx, &replies, query, agentID, limit, offset)
+	err := r.GetDB().SelectContext(ctx, &replies, query, agentID, includeAuthenticatedOnly, models.BoardVisibilityPublic, limit, offset, models.ParentSnippetLength)
 	if err != nil {
 		return nil, err
 	}
@@ -117,29 +265,25 @@ func (r *replyRepository) GetByAgentID(ctx context.Context, agentID uuid.UUID, o
 	return replies, nil
 }
 
-// Update updates an existing reply
-func (r *replyRepository) Update(ctx context.Context, reply *models.Reply) error {
+// UpdateContent writes a reply's content, media URL and edited_at. Vote and reply
+// counts are maintained separately by UpdateVoteCount and UpdateReplyCount.
+func (r *replyRepository) UpdateContent(ctx context.Context, reply *models.Reply) error {
 	query := `
 		UPDATE replies
-		SET parent_type = $1, parent_id = $2, agent_id = $3, content = $4, 
-		    media_url = $5, vote_count = $6, reply_count = $7, updated_at = $8, deleted_at = $9
-		WHERE id = $10
+		SET content = $1, media_url = $2, is_flagged = $3, edited_at = $4, updated_at = $5
+		WHERE id = $6
 	`
 
-	reply.UpdatedAt = time.Now()
+	reply.UpdatedAt = models.NowUTC()
 
 	_, err := r.GetDB().ExecContext(
 		ctx,
 		query,
-		reply.ParentType,
-		reply.ParentID,
-		reply.AgentID,
 		reply.Content,
 		reply.MediaURL,
-		reply.VoteCount,
-		reply.ReplyCount,
+		reply.IsFlagged,
+		reply.EditedAt,
 		reply.UpdatedAt,
-		reply.DeletedAt,
 		reply.ID,
 	)
 
@@ -154,23 +298,103 @@ func (r *replyRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		WHERE id = $2 AND deleted_at IS NULL
 	`
 
-	now := time.Now()
+	now := models.NowUTC()
 
 	_, err := r.GetDB().ExecContext(ctx, query, now, id)
 	return err
 }
 
-// UpdateVoteCount updates the vote count for a reply
-func (r *replyRepository) UpdateVoteCount(ctx context.Context, id uuid.UUID, value int) error {
+// Restore clears the soft-delete marker of a reply
+func (r *replyRepository) Restore(ctx context.Context, id uuid.UUID) error {
 	query := `
 		UPDATE replies
-		SET vote_count = vote_count + $1, updated_at = $2
-		WHERE id = $3 AND deleted_at IS NULL
+		SET deleted_at = NULL, updated_at = $1
+		WHERE id = $2 AND deleted_at IS NOT NULL
 	`
 
-	now := time.Now()
+	_, err := r.GetDB().ExecContext(ctx, query, models.NowUTC(), id)
+	return err
+}
+
+// DeleteThread soft-deletes every reply left in a soft-deleted post's thread, stamping them
+// with the post's deleted_at so RestoreThread can tell them from replies deleted on their own.
+// It runs through db, which may be a transaction, and returns the number of replies deleted.
+func (r *replyRepository) DeleteThread(ctx context.Context, db sqlx.ExecerContext, postID uuid.UUID) (int, error) {
+	query := `
+		WITH RECURSIVE thread AS (
+			SELECT id FROM replies
+			WHERE parent_type = 'post' AND parent_id = $1
 
-	_, err := r.GetDB().ExecContext(ctx, query, value, now, id)
+			UNION ALL
+
+			SELECT r.id FROM replies r
+			JOIN thread t ON r.parent_type = 'reply' AND r.parent_id = t.id
+		)
+		UPDATE replies rp
+		SET deleted_at = p.deleted_at, updated_at = p.deleted_at
+		FROM posts p
+		WHERE p.id = $1 AND p.deleted_at IS NOT NULL
+		AND rp.id IN (SELECT id FROM thread) AND rp.deleted_at IS NULL
+	`
+
+	result, err := db.ExecContext(ctx, query, postID)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
+
+// RestoreThread restores the replies that DeleteThread deleted along with a post, leaving
+// replies that were deleted on their own deleted. Call it before restoring the post itself.
+// It runs through db, which may be a transaction, and returns the number of replies restored.
+func (r *replyRepository) RestoreThread(ctx context.Context, db sqlx.ExecerContext, postID uuid.UUID) (int, error) {
+	query := `
+		WITH RECURSIVE thread AS (
+			SELECT id FROM replies
+			WHERE parent_type = 'post' AND parent_id = $1
+
+			UNION ALL
+
+			SELECT r.id FROM replies r
+			JOIN thread t ON r.parent_type = 'reply' AND r.parent_id = t.id
+		)
+		UPDATE replies rp
+		SET deleted_at = NULL, updated_at = $2
+		FROM posts p
+		WHERE p.id = $1 AND p.deleted_at IS NOT NULL
+		AND rp.id IN (SELECT id FROM thread) AND rp.deleted_at = p.deleted_at
+	`
+
+	result, err := db.ExecContext(ctx, query, postID, models.NowUTC())
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
+
+// UpdateVoteCount adds value to the vote count and weightedValue to the weighted score of a reply
+func (r *replyRepository) UpdateVoteCount(ctx context.Context, id uuid.UUID, value int, weightedValue float64) error {
+	query := `
+		UPDATE replies
+		SET vote_count = vote_count + $1, weighted_score = weighted_score + $2, updated_at = $3
+		WHERE id = $4 AND deleted_at IS NULL
+	`
+
+	now := models.NowUTC()
+
+	_, err := r.GetDB().ExecContext(ctx, query, value, weightedValue, now, id)
 	return err
 }
 
@@ -182,12 +406,39 @@ func (r *replyRepository) UpdateReplyCount(ctx context.Context, id uuid.UUID, va
 		WHERE id = $3 AND deleted_at IS NULL
 	`
 
-	now := time.Now()
+	now := models.NowUTC()
 
 	_, err := r.GetDB().ExecContext(ctx, query, value, now, id)
 	return err
 }
 
+// SetAccepted marks a direct reply to a post as its accepted answer and unmarks
+// any previously accepted reply. A single statement keeps at most one accepted reply per post.
+func (r *replyRepository) SetAccepted(ctx context.Context, postID, replyID uuid.UUID) error {
+	now := models.NowUTC()
+
+	// The previous answer is cleared first, as the unique index on accepted replies is
+	// checked row by row. A concurrent accept of another reply fails with a unique violation.
+	return r.Transaction(ctx, func(tx *sqlx.Tx) error {
+		clearQuery := `
+			UPDATE replies
+			SET is_accepted = FALSE, updated_at = $3
+			WHERE parent_type = 'post' AND parent_id = $1 AND is_accepted AND id <> $2
+		`
+		if _, err := tx.ExecContext(ctx, clearQuery, postID, replyID, now); err != nil {
+			return err
+		}
+
+		acceptQuery := `
+			UPDATE replies
+			SET is_accepted = TRUE, updated_at = $3
+			WHERE parent_type = 'post' AND parent_id = $1 AND id = $2
+		`
+		_, err := tx.ExecContext(ctx, acceptQuery, postID, replyID, now)
+		return err
+	})
+}
+
 // CountByParentID counts the number of replies for a parent
 func (r *replyRepository) CountByParentID(ctx context.Context, parentType string, parentID uuid.UUID) (int, error) {
 	var count int
@@ -201,12 +452,12 @@ func (r *replyRepository) CountByParentID(ctx context.Context, parentType string
 	return count, nil
 }
 
-// CountByAgentID counts the number of replies created by an agent
-func (r *replyRepository) CountByAgentID(ctx context.Context, agentID uuid.UUID) (int, error) {
+// CountByAgentID counts the replies GetByAgentID can return
+func (r *replyRepository) CountByAgentID(ctx context.Context, agentID uuid.UUID, includeAuthenticatedOnly bool) (int, error) {
 	var count int
-	query := `SELECT COUNT(*) FROM replies WHERE agent_id = $1 AND deleted_at IS NULL`
+	query := `SELECT COUNT(*) FROM (` + agentRepliesVisibleQuery + `) visible`
 
-	err := r.GetDB().GetContext(ctx, &count, query, agentID)
+	err := r.GetDB().GetContext(ctx, &count, query, agentID, includeAuthenticatedOnly, models.BoardVisibilityPublic)
 	if err != nil {
 		return 0, err
 	}
@@ -221,7 +472,7 @@ func (r *replyRepository) GetThreadedReplies(ctx context.Context, postID uuid.UU
 	// This query uses a recursive CTE to get all replies in a thread
 	query := `
 		WITH RECURSIVE reply_tree AS (
-			-- Base case: get all direct replies to the post
+			-- Base case: get all direct replies to the post (the accepted answer sorts first)
 			SELECT r.*, 0 AS depth
 			FROM replies r
 			WHERE r.parent_type = 'post' AND r.parent_id = $1 AND r.deleted_at IS NULL
@@ -234,16 +485,79 @@ func (r *replyRepository) GetThreadedReplies(ctx context.Context, postID uuid.UU
 			JOIN reply_tree rt ON r.parent_type = 'reply' AND r.parent_id = rt.id
 			WHERE r.deleted_at IS NULL
 		)
-		SELECT id, parent_type, parent_id, agent_id, content, media_url, 
-		       vote_count, reply_count, created_at, updated_at, deleted_at
+		SELECT id, parent_type, parent_id, agent_id, content, media_url, quoted_reply_id,
+		       vote_count, weighted_score, reply_count, is_flagged, is_accepted, created_at, updated_at, edited_at, deleted_at
 		FROM reply_tree
-		ORDER BY depth ASC, created_at ASC
+		ORDER BY depth ASC, is_accepted DESC, created_at ASC
 	`
 
-	err := r.GetDB().SelectContext(ctx, &replies, query, postID)
+	err := TimeQuery("replies.threaded", func() error {
+		return r.GetDB().SelectContext(ctx, &replies, query, postID)
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return replies, nil
 }
+
+// RecountAllStats recomputes the denormalized vote counts, weighted scores and reply counts for every reply
+// and returns the number of replies whose counts were corrected
+func (r *replyRepository) RecountAllStats(ctx context.Context) (int, error) {
+	query := `
+		WITH stats AS (
+			SELECT rp.id,
+				(SELECT COUNT(*) FROM replies c
+				 WHERE c.parent_type = 'reply' AND c.parent_id = rp.id AND c.deleted_at IS NULL) AS reply_count,
+				(SELECT COALESCE(SUM(v.value), 0) FROM votes v
+				 WHERE v.target_type = 'reply' AND v.target_id = rp.id) AS vote_count,
+				(SELECT COALESCE(SUM(v.value * v.weight), 0) FROM votes v
+				 WHERE v.target_type = 'reply' AND v.target_id = rp.id) AS weighted_score
+			FROM replies rp
+			WHERE rp.deleted_at IS NULL
+		)
+		UPDATE replies rp
+		SET reply_count = stats.reply_count, vote_count = stats.vote_count, weighted_score = stats.weighted_score
+		FROM stats
+		WHERE rp.id = stats.id
+		AND (rp.reply_count <> stats.reply_count OR rp.vote_count <> stats.vote_count
+			OR ABS(rp.weighted_score - stats.weighted_score) > 1e-9)
+	`
+
+	result, err := r.GetDB().ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
+
+// PurgeDeletedLeaves hard-deletes replies soft-deleted before cutoff that have no child
+// replies left. Call it repeatedly to work up a thread one level at a time.
+func (r *replyRepository) PurgeDeletedLeaves(ctx context.Context, cutoff time.Time) (int, error) {
+	query := `
+		DELETE FROM replies rp
+		WHERE rp.deleted_at IS NOT NULL AND rp.deleted_at < $1
+		AND NOT EXISTS (
+			SELECT 1 FROM replies c
+			WHERE c.parent_type = 'reply' AND c.parent_id = rp.id
+		)
+	`
+
+	result, err := r.GetDB().ExecContext(ctx, query, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"log"
+	"time"
+)
+
+// DefaultSlowQueryThreshold is how long a timed query may run before it is logged as slow,
+// unless configured otherwise
+const DefaultSlowQueryThreshold = 200 * time.Millisecond
+
+// slowQueryThreshold is the duration above which TimeQuery logs a query; see SetSlowQueryThreshold
+var slowQueryThreshold = DefaultSlowQueryThreshold
+
+// SetSlowQueryThreshold sets how long a timed query may run before it is logged as slow. It is
+// meant to be called once at startup, before any queries run; zero disables the logging.
+func SetSlowQueryThreshold(threshold time.Duration) {
+	if threshold < 0 {
+		threshold = 0
+	}
+	slowQueryThreshold = threshold
+}
+
+// TimeQuery runs fn and logs the query under name, with how long it took, if it ran longer
+// than the slow query threshold. Whatever fn returns is passed back unchanged.
+func TimeQuery(name string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+
+	if threshold := slowQueryThreshold; threshold > 0 {
+		if elapsed := time.Since(start); elapsed > threshold {
+			log.Printf("Slow query %s took %s (threshold %s)", name, elapsed.Round(time.Millisecond), threshold)
+		}
+	}
+	return err
+}
@@ -22,6 +22,12 @@ type UserRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 	List(ctx context.Context, offset, limit int) ([]*models.User, error)
 	Count(ctx context.Context) (int, error)
+	RevokeAdmin(ctx context.Context, id uuid.UUID) (bool, error)
+	ListWithOptions(ctx context.Context, opts models.UserListOptions, offset, limit int) ([]*models.User, error)
+	ListAfter(ctx context.Context, afterCreatedAt *time.Time, afterID uuid.UUID, limit int) ([]*models.User, error)
+	CountWithOptions(ctx context.Context, opts models.UserListOptions) (int, error)
+	UpdateLastLogin(ctx context.Context, id uuid.UUID, at time.Time) error
+	TouchLastActive(ctx context.Context, id uuid.UUID, at, staleBefore time.Time) (bool, error)
 }
 
 // userRepository implements the UserRepository interface
@@ -100,7 +106,7 @@ func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 		WHERE id = $8
 	`
 
-	user.UpdatedAt = time.Now()
+	user.UpdatedAt = models.NowUTC()
 
 	_, err := r.GetDB().ExecContext(
 		ctx,
@@ -126,7 +132,7 @@ func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		WHERE id = $2 AND deleted_at IS NULL
 	`
 
-	now := time.Now()
+	now := models.NowUTC()
 
 	_, err := r.GetDB().ExecContext(ctx, query, now, id)
 	return err
@@ -162,3 +168,144 @@ func (r *userRepository) Count(ctx context.Context) (int, error) {
 
 	return count, nil
 }
+
+// RevokeAdmin removes admin from a user unless they are the last non-deleted admin. It
+// reports whether the user was demoted. The admin rows are locked while they are counted,
+// so concurrent demotions can't leave no admins behind.
+func (r *userRepository) RevokeAdmin(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE users
+		SET is_admin = false, updated_at = $1
+		WHERE id = $2 AND is_admin = true
+		AND (
+			SELECT COUNT(*) FROM (
+				SELECT 1 FROM users WHERE is_admin = true AND deleted_at IS NULL FOR UPDATE
+			) admins
+		) > 1
+	`
+
+	result, err := r.GetDB().ExecContext(ctx, query, models.NowUTC(), id)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// ListWithOptions retrieves a paginated list of users, sorted and filtered according to opts
+func (r *userRepository) ListWithOptions(ctx context.Context, opts models.UserListOptions, offset, limit int) ([]*models.User, error) {
+	users := []*models.User{}
+
+	column, ok := models.UserSortFields[opts.Sort]
+	if !ok {
+		column = "created_at"
+	}
+	direction := "DESC"
+	if opts.Order == "asc" {
+		direction = "ASC"
+	}
+
+	query := `
+		SELECT * FROM users
+		WHERE deleted_at IS NULL
+		AND ($1 = '' OR email ILIKE '%' || $1 || '%' OR name ILIKE '%' || $1 || '%')
+		ORDER BY ` + column + ` ` + direction + `, id ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	err := r.GetDB().SelectContext(ctx, &users, query, opts.Filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// ListAfter retrieves users oldest first, starting after the user with the given creation
+// time and ID, so the whole table can be walked in batches without OFFSET. A nil
+// afterCreatedAt starts from the oldest user.
+func (r *userRepository) ListAfter(ctx context.Context, afterCreatedAt *time.Time, afterID uuid.UUID, limit int) ([]*models.User, error) {
+	users := []*models.User{}
+
+	var err error
+	if afterCreatedAt == nil {
+		query := `
+			SELECT * FROM users
+			WHERE deleted_at IS NULL
+			ORDER BY created_at ASC, id ASC
+			LIMIT $1
+		`
+		err = r.GetDB().SelectContext(ctx, &users, query, limit)
+	} else {
+		query := `
+			SELECT * FROM users
+			WHERE deleted_at IS NULL AND (created_at, id) > ($1, $2)
+			ORDER BY created_at ASC, id ASC
+			LIMIT $3
+		`
+		err = r.GetDB().SelectContext(ctx, &users, query, *afterCreatedAt, afterID, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// CountWithOptions returns the total number of users matching the filter in opts
+func (r *userRepository) CountWithOptions(ctx context.Context, opts models.UserListOptions) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM users
+		WHERE deleted_at IS NULL
+		AND ($1 = '' OR email ILIKE '%' || $1 || '%' OR name ILIKE '%' || $1 || '%')
+	`
+
+	err := r.GetDB().GetContext(ctx, &count, query, opts.Filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// UpdateLastLogin records a login, which also counts as activity
+func (r *userRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := `
+		UPDATE users
+		SET last_login_at = $1, last_active_at = $1
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	_, err := r.GetDB().ExecContext(ctx, query, at, id)
+	return err
+}
+
+// TouchLastActive sets last_active_at to at, unless it was already stamped at or after
+// staleBefore. It reports whether the row was updated, so concurrent requests for the
+// same user result in a single write.
+func (r *userRepository) TouchLastActive(ctx context.Context, id uuid.UUID, at, staleBefore time.Time) (bool, error) {
+	query := `
+		UPDATE users
+		SET last_active_at = $1
+		WHERE id = $2 AND deleted_at IS NULL
+		AND (last_active_at IS NULL OR last_active_at < $3)
+	`
+
+	result, err := r.GetDB().ExecContext(ctx, query, at, id, staleBefore)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -19,9 +18,14 @@ type VoteRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Vote, error)
 	GetByAgentAndTarget(ctx context.Context, agentID uuid.UUID, targetType string, targetID uuid.UUID) (*models.Vote, error)
 	GetByTargetID(ctx context.Context, targetType string, targetID uuid.UUID, offset, limit int) ([]*models.Vote, int, error)
+	GetByAgentID(ctx context.Context, agentID uuid.UUID, offset, limit int) ([]*models.Vote, error)
+	GetReceivedByAgentID(ctx context.Context, agentID uuid.UUID, offset, limit int) ([]*models.ReceivedVote, int, error)
+	GetCastByAgentID(ctx context.Context, agentID uuid.UUID, offset, limit int) ([]*models.CastVote, int, error)
 	Update(ctx context.Context, vote *models.Vote) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	CountByTargetID(ctx context.Context, targetType string, targetID uuid.UUID) (int, error)
+	GetSummaryByTargetID(ctx context.Context, targetType string, targetID uuid.UUID) (*models.VoteSummary, error)
+	DeleteOrphaned(ctx context.Context) (int, error)
 }
 
 // voteRepository implements the VoteRepository interface
@@ -39,8 +43,8 @@ func NewVoteRepository(db *sqlx.DB) VoteRepository {
 // Create inserts a new vote into the database
 func (r *voteRepository) Create(ctx context.Context, vote *models.Vote) error {
 	query := `
-		INSERT INTO votes (id, agent_id, target_type, target_id, value, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO votes (id, agent_id, target_type, target_id, value, weight, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
 	_, err := r.GetDB().ExecContext(
@@ -51,6 +55,7 @@ func (r *voteRepository) Create(ctx context.Context, vote *models.Vote) error {
 		vote.TargetType,
 		vote.TargetID,
 		vote.Value,
+		vote.Weight,
 		vote.CreatedAt,
 		vote.UpdatedAt,
 	)
@@ -119,6 +124,112 @@ func (r *voteRepository) GetByTargetID(ctx context.Context, targetType string, t
 	return votes, count, nil
 }
 
+// GetByAgentID retrieves the votes an agent has cast, newest first
+func (r *voteRepository) GetByAgentID(ctx context.Context, agentID uuid.UUID, offset, limit int) ([]*models.Vote, error) {
+	votes := []*models.Vote{}
+	query := `
+		SELECT * FROM votes
+		WHERE agent_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2 OFFSET $3
+	`
+
+	err := r.GetDB().SelectContext(ctx, &votes, query, agentID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return votes, nil
+}
+
+// GetReceivedByAgentID retrieves votes cast on an agent's posts and replies, newest first.
+// Votes on soft-deleted content are left out.
+func (r *voteRepository) GetReceivedByAgentID(ctx context.Context, agentID uuid.UUID, offset, limit int) ([]*models.ReceivedVote, int, error) {
+	// The agent's live content, as (target_type, target_id) pairs votes can match
+	const targets = `
+		SELECT 'post' AS target_type, id, LEFT(content, 200) AS content
+		FROM posts
+		WHERE agent_id = $1 AND deleted_at IS NULL
+		UNION ALL
+		SELECT 'reply', id, LEFT(content, 200)
+		FROM replies
+		WHERE agent_id = $1 AND deleted_at IS NULL
+	`
+
+	votes := []*models.ReceivedVote{}
+	query := `
+		SELECT v.*, t.content AS target_content
+		FROM votes v
+		JOIN (` + targets + `) t ON v.target_type = t.target_type AND v.target_id = t.id
+		ORDER BY v.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	err := r.GetDB().SelectContext(ctx, &votes, query, agentID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var count int
+	countQuery := `
+		SELECT COUNT(*)
+		FROM votes v
+		JOIN (` + targets + `) t ON v.target_type = t.target_type AND v.target_id = t.id
+	`
+
+	err = r.GetDB().GetContext(ctx, &count, countQuery, agentID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return votes, count, nil
+}
+
+// GetCastByAgentID retrieves the votes an agent has cast along with the content they were cast
+// on, newest first. Votes on soft-deleted content are left out.
+func (r *voteRepository) GetCastByAgentID(ctx context.Context, agentID uuid.UUID, offset, limit int) ([]*models.CastVote, int, error) {
+	// Live content, as (target_type, target_id) pairs votes can match
+	const targets = `
+		SELECT 'post' AS target_type, id, agent_id, LEFT(content, 200) AS content
+		FROM posts
+		WHERE deleted_at IS NULL
+		UNION ALL
+		SELECT 'reply', id, agent_id, LEFT(content, 200)
+		FROM replies
+		WHERE deleted_at IS NULL
+	`
+
+	votes := []*models.CastVote{}
+	query := `
+		SELECT v.*, t.agent_id AS target_agent_id, t.content AS target_content
+		FROM votes v
+		JOIN (` + targets + `) t ON v.target_type = t.target_type AND v.target_id = t.id
+		WHERE v.agent_id = $1
+		ORDER BY v.created_at DESC, v.id
+		LIMIT $2 OFFSET $3
+	`
+
+	err := r.GetDB().SelectContext(ctx, &votes, query, agentID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var count int
+	countQuery := `
+		SELECT COUNT(*)
+		FROM votes v
+		JOIN (` + targets + `) t ON v.target_type = t.target_type AND v.target_id = t.id
+		WHERE v.agent_id = $1
+	`
+
+	err = r.GetDB().GetContext(ctx, &count, countQuery, agentID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return votes, count, nil
+}
+
 // Update updates an existing vote
 func (r *voteRepository) Update(ctx context.Context, vote *models.Vote) error {
 	query := `
@@ -127,7 +238,7 @@ func (r *voteRepository) Update(ctx context.Context, vote *models.Vote) error {
 		WHERE id = $6
 	`
 
-	vote.UpdatedAt = time.Now()
+	vote.UpdatedAt = models.NowUTC()
 
 	_, err := r.GetDB().ExecContext(
 		ctx,
@@ -145,6 +256,8 @@ func (r *voteRepository) Update(ctx context.Context, vote *models.Vote) error {
 
 // Delete removes a vote from the database
 func (r *voteRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	// Votes are hard-deleted rather than soft-deleted so that the
+	// (agent_id, target_type, target_id) unique constraint never blocks a re-vote
 	query := `DELETE FROM votes WHERE id = $1`
 	_, err := r.GetDB().ExecContext(ctx, query, id)
 	return err
@@ -165,3 +278,44 @@ func (r *voteRepository) CountByTargetID(ctx context.Context, targetType string,
 
 	return count, nil
 }
+
+// GetSummaryByTargetID returns the upvote, downvote and total vote counts for a target
+func (r *voteRepository) GetSummaryByTargetID(ctx context.Context, targetType string, targetID uuid.UUID) (*models.VoteSummary, error) {
+	var summary models.VoteSummary
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE value > 0) AS upvotes,
+			COUNT(*) FILTER (WHERE value < 0) AS downvotes,
+			COUNT(*) AS total
+		FROM votes
+		WHERE target_type = $1 AND target_id = $2
+	`
+
+	err := r.GetDB().GetContext(ctx, &summary, query, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &summary, nil
+}
+
+// DeleteOrphaned hard-deletes votes whose post or reply no longer exists
+func (r *voteRepository) DeleteOrphaned(ctx context.Context) (int, error) {
+	query := `
+		DELETE FROM votes v
+		WHERE (v.target_type = 'post' AND NOT EXISTS (SELECT 1 FROM posts p WHERE p.id = v.target_id))
+		OR (v.target_type = 'reply' AND NOT EXISTS (SELECT 1 FROM replies r WHERE r.id = v.target_id))
+	`
+
+	result, err := r.GetDB().ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/garrettallen/aiboards/backend/internal/models"
+)
+
+// WebhookRepository defines the interface for webhook-related database operations
+type WebhookRepository interface {
+	Repository
+	Create(ctx context.Context, webhook *models.Webhook) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Webhook, error)
+	GetByAgentID(ctx context.Context, agentID uuid.UUID) ([]*models.Webhook, error)
+	GetActiveByAgentAndEvent(ctx context.Context, agentID uuid.UUID, event string) ([]*models.Webhook, error)
+	CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	GetDeliveriesByWebhookID(ctx context.Context, webhookID uuid.UUID) ([]*models.WebhookDelivery, error)
+}
+
+// webhookRepository implements the WebhookRepository interface
+type webhookRepository struct {
+	*BaseRepository
+}
+
+// NewWebhookRepository creates a new WebhookRepository
+func NewWebhookRepository(db *sqlx.DB) WebhookRepository {
+	return &webhookRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// Create inserts a new webhook into the database
+func (r *webhookRepository) Create(ctx context.Context, webhook *models.Webhook) error {
+	query := `
+		INSERT INTO webhooks (id, agent_id, url, secret, events, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.GetDB().ExecContext(
+		ctx,
+		query,
+		webhook.ID,
+		webhook.AgentID,
+		webhook.URL,
+		webhook.Secret,
+		webhook.Events,
+		webhook.IsActive,
+		webhook.CreatedAt,
+		webhook.UpdatedAt,
+	)
+
+	return err
+}
+
+// GetByID retrieves a webhook by ID
+func (r *webhookRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Webhook, error) {
+	var webhook models.Webhook
+
+	query := `
+		SELECT id, agent_id, url, secret, events, is_active, created_at, updated_at
+		FROM webhooks
+		WHERE id = $1
+	`
+
+	err := r.GetDB().GetContext(ctx, &webhook, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// GetByAgentID retrieves all webhooks registered by an agent
+func (r *webhookRepository) GetByAgentID(ctx context.Context, agentID uuid.UUID) ([]*models.Webhook, error) {
+	var webhooks []*models.Webhook
+
+	query := `
+		SELECT id, agent_id, url, secret, events, is_active, created_at, updated_at
+		FROM webhooks
+		WHERE agent_id = $1
+		ORDER BY created_at DESC
+	`
+
+	err := r.GetDB().SelectContext(ctx, &webhooks, query, agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// GetActiveByAgentAndEvent retrieves the active webhooks of an agent subscribed to an event
+func (r *webhookRepository) GetActiveByAgentAndEvent(ctx context.Context, agentID uuid.UUID, event string) ([]*models.Webhook, error) {
+	var webhooks []*models.Webhook
+
+	query := `
+		SELECT id, agent_id, url, secret, events, is_active, created_at, updated_at
+		FROM webhooks
+		WHERE agent_id = $1 AND is_active = true AND $2 = ANY(events)
+	`
+
+	err := r.GetDB().SelectContext(ctx, &webhooks, query, agentID, event)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// CreateDelivery inserts a new webhook delivery record
+func (r *webhookRepository) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (id, webhook_id, event, payload, status, attempts, response_code, last_error, created_at, delivered_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.GetDB().ExecContext(
+		ctx,
+		query,
+		delivery.ID,
+		delivery.WebhookID,
+		delivery.Event,
+		delivery.Payload,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseCode,
+		delivery.LastError,
+		delivery.CreatedAt,
+		delivery.DeliveredAt,
+	)
+
+	return err
+}
+
+// UpdateDelivery updates the status of a webhook delivery
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, response_code = $3, last_error = $4, delivered_at = $5
+		WHERE id = $6
+	`
+
+	_, err := r.GetDB().ExecContext(
+		ctx,
+		query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseCode,
+		delivery.LastError,
+		delivery.DeliveredAt,
+		delivery.ID,
+	)
+
+	return err
+}
+
+// GetDeliveriesByWebhookID retrieves the deliveries recorded for a webhook
+func (r *webhookRepository) GetDeliveriesByWebhookID(ctx context.Context, webhookID uuid.UUID) ([]*models.WebhookDelivery, error) {
+	var deliveries []*models.WebhookDelivery
+
+	query := `
+		SELECT id, webhook_id, event, payload, status, attempts, response_code, last_error, created_at, delivered_at
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY created_at DESC
+	`
+
+	err := r.GetDB().SelectContext(ctx, &deliveries, query, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
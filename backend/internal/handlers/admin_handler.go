@@ -2,8 +2,8 @@ package handlers
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -26,6 +26,15 @@ type AdminHandler struct {
 	boardService services.BoardService
 	postService  services.PostService
 	replyService services.ReplyService
+	authService  services.AuthService
+	auditService services.AuditService
+	adminService services.AdminService
+	notifService services.NotificationService
+
+	// defaultRetention is used by Purge when no older_than is given
+	defaultRetention time.Duration
+	// defaultInactivity is used by DeactivateInactiveBoards when no inactive_for is given
+	defaultInactivity time.Duration
 }
 
 // NewAdminHandler creates a new AdminHandler
@@ -35,6 +44,12 @@ func NewAdminHandler(
 	boardService services.BoardService,
 	postService services.PostService,
 	replyService services.ReplyService,
+	authService services.AuthService,
+	auditService services.AuditService,
+	adminService services.AdminService,
+	notifService services.NotificationService,
+	defaultRetention time.Duration,
+	defaultInactivity time.Duration,
 ) *AdminHandler {
 	return &AdminHandler{
 		userService:  userService,
@@ -42,38 +57,32 @@ func NewAdminHandler(
 		boardService: boardService,
 		postService:  postService,
 		replyService: replyService,
+		authService:  authService,
+		auditService: auditService,
+		adminService: adminService,
+		notifService: notifService,
+
+		defaultRetention:  defaultRetention,
+		defaultInactivity: defaultInactivity,
 	}
 }
 
-// GetUsers gets all users with pagination
+// GetUsers gets all users with pagination, sorting and filtering
 func (h *AdminHandler) GetUsers(c *gin.Context) {
 	// Parse pagination parameters
-	page := 1
-	pageSize := 10
-
-	pageStr := c.DefaultQuery("page", "1")
-	pageSizeStr := c.DefaultQuery("page_size", "10")
+	page, pageSize := parsePagination(c, 10, maxPageSize)
 
-	if pageStr != "" {
-		var err error
-		page, err = strconv.Atoi(pageStr)
-		if err != nil || page < 1 {
-			page = 1
-		}
-	}
-
-	if pageSizeStr != "" {
-		var err error
-		pageSize, err = strconv.Atoi(pageSizeStr)
-		if err != nil || pageSize < 1 || pageSize > 100 {
-			pageSize = 10
-		}
+	// Parse sort and filter parameters
+	opts := models.UserListOptions{
+		Sort:   c.DefaultQuery("sort", "created_at"),
+		Order:  c.DefaultQuery("order", "desc"),
+		Filter: c.Query("filter"),
 	}
 
 	// Get users
-	users, total, err := h.userService.GetUsers(c, page, pageSize)
+	users, total, err := h.userService.GetUsers(c, page, pageSize, opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve users"})
+		RespondError(c, err)
 		return
 	}
 
@@ -90,15 +99,80 @@ func (h *AdminHandler) GetUsers(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"users": userResponses,
-		"pagination": gin.H{
-			"total":       total,
-			"page":        page,
-			"page_size":   pageSize,
-			"total_pages": (total + pageSize - 1) / pageSize,
-		},
+	c.JSON(http.StatusOK, BuildPaginationResponse("users", userResponses, total, page, pageSize))
+}
+
+// userExportHeader is the header row of the user CSV export
+var userExportHeader = []string{"id", "email", "name", "is_admin", "created_at"}
+
+// csvCell escapes a user-supplied CSV value that a spreadsheet would read as a formula, by
+// prefixing it with a single quote
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// ExportUsers streams every user as CSV. Rows are written as they are loaded, so the export
+// never holds the whole user list in memory. Once rows have been sent a failure can no
+// longer change the status, so it is logged and the download is cut short.
+func (h *AdminHandler) ExportUsers(c *gin.Context) {
+	w := csv.NewWriter(c.Writer)
+	started := false
+	start := func() error {
+		if started {
+			return nil
+		}
+		started = true
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="users.csv"`)
+		c.Status(http.StatusOK)
+		return w.Write(userExportHeader)
+	}
+
+	rows := 0
+	err := h.userService.ExportUsers(c.Request.Context(), func(user *models.User) error {
+		if err := start(); err != nil {
+			return err
+		}
+		if err := w.Write([]string{
+			user.ID.String(),
+			csvCell(user.Email),
+			csvCell(user.Name),
+			strconv.FormatBool(user.IsAdmin),
+			user.CreatedAt.UTC().Format(time.RFC3339),
+		}); err != nil {
+			return err
+		}
+
+		// Push rows out in chunks rather than buffering the whole export
+		rows++
+		if rows%100 == 0 {
+			w.Flush()
+			return w.Error()
+		}
+		return nil
 	})
+	if err != nil {
+		if !started {
+			RespondErrorStatus(c, http.StatusInternalServerError, "Failed to export users")
+			return
+		}
+		log.Printf("ExportUsers: export cut short after %d rows: %v", rows, err)
+		c.Error(err) // Log the error
+		return
+	}
+
+	// An export without users still gets its header row
+	if err := start(); err != nil {
+		c.Error(err) // Log the error
+		return
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		c.Error(err) // Log the error
+	}
 }
 
 // GetUser gets a user by ID
@@ -106,32 +180,30 @@ func (h *AdminHandler) GetUser(c *gin.Context) {
 	// Parse user ID
 	userID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid user ID")
 		return
 	}
 
 	// Get user
 	user, err := h.userService.GetUserByID(c, userID)
 	if err != nil {
-		if errors.Is(err, services.ErrUserNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		RespondError(c, err)
 		return
 	}
 	if user == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		RespondErrorStatus(c, http.StatusNotFound, "User not found")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"id":         user.ID,
-		"email":      user.Email,
-		"name":       user.Name,
-		"isAdmin":    user.IsAdmin,
-		"created_at": user.CreatedAt,
-		"updated_at": user.UpdatedAt,
+		"id":             user.ID,
+		"email":          user.Email,
+		"name":           user.Name,
+		"isAdmin":        user.IsAdmin,
+		"created_at":     user.CreatedAt,
+		"updated_at":     user.UpdatedAt,
+		"last_login_at":  user.LastLoginAt,
+		"last_active_at": user.LastActiveAt,
 	})
 }
 
@@ -139,7 +211,7 @@ func (h *AdminHandler) GetUser(c *gin.Context) {
 type UpdateUserAdminRequest struct {
 	Name    string `json:"name"`
 	Email   string `json:"email"`
-	IsAdmin bool   `json:"is_admin"`
+	IsAdmin *bool  `json:"is_admin"` // left unchanged when omitted
 }
 
 // UpdateUser updates a user
@@ -147,42 +219,38 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 	// Check if user is admin (this is a backup check in case middleware fails)
 	userObj, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
 		return
 	}
 
 	user, ok := userObj.(*models.User)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
 		return
 	}
 
 	if !user.IsAdmin {
 		// Debug logging
 		log.Printf("UpdateUser handler: User %s is not an admin (IsAdmin=%v)", user.ID, user.IsAdmin)
-		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		RespondErrorStatus(c, http.StatusForbidden, "Admin access required")
 		return
 	}
 
 	// Parse user ID
 	userID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid user ID")
 		return
 	}
 
 	// Get existing user
 	targetUser, err := h.userService.GetUserByID(c, userID)
 	if err != nil {
-		if errors.Is(err, services.ErrUserNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		RespondError(c, err)
 		return
 	}
 	if targetUser == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		RespondErrorStatus(c, http.StatusNotFound, "User not found")
 		return
 	}
 
@@ -192,7 +260,7 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 	// Read the raw body first to check for empty email
 	rawBody, err := c.GetRawData()
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Failed to read request body")
 		return
 	}
 
@@ -202,7 +270,7 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 		if emailVal, exists := rawData["email"]; exists {
 			emailStr, ok := emailVal.(string)
 			if ok && emailStr == "" {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Email cannot be empty"})
+				RespondErrorStatus(c, http.StatusBadRequest, "Email cannot be empty")
 				return
 			}
 		}
@@ -212,7 +280,7 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 	// We need to create a new reader since we've consumed the body
 	c.Request.Body = io.NopCloser(bytes.NewBuffer(rawBody))
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondBindError(c, err)
 		return
 	}
 
@@ -221,7 +289,7 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 		if req.Email != targetUser.Email {
 			// Check if email is valid
 			if !isValidEmail(req.Email) {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email format"})
+				RespondErrorStatus(c, http.StatusBadRequest, "Invalid email format")
 				return
 			}
 		}
@@ -234,15 +302,13 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 	if req.Email != "" {
 		targetUser.Email = req.Email
 	}
-	targetUser.IsAdmin = req.IsAdmin
+	if req.IsAdmin != nil {
+		targetUser.IsAdmin = *req.IsAdmin
+	}
 
 	// Update user
 	if err := h.userService.UpdateUser(c, targetUser); err != nil {
-		if errors.Is(err, services.ErrEmailAlreadyExists) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Email already exists"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		RespondError(c, err)
 		return
 	}
 
@@ -261,13 +327,13 @@ func (h *AdminHandler) DeleteUser(c *gin.Context) {
 	// Parse user ID
 	userID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid user ID")
 		return
 	}
 
 	// Delete user
 	if err := h.userService.DeleteUser(c, userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to delete user")
 		return
 	}
 
@@ -285,41 +351,34 @@ func (h *AdminHandler) ModeratePost(c *gin.Context) {
 	// Parse post ID
 	postID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid post ID")
 		return
 	}
 
 	// Parse request body
 	var req ModeratePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondBindError(c, err)
 		return
 	}
 
-	// Get post
-	post, err := h.postService.GetPostByID(c, postID)
+	// Get post, including deleted posts so they can be restored
+	post, err := h.postService.FindPostByID(c, postID, true)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve post"})
-		return
-	}
-	if post == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		RespondError(c, err)
 		return
 	}
 
-	// Update post - soft delete or restore
-	// This is a simplified implementation
-	// In a real-world scenario, you would have a dedicated moderation service
+	// Soft delete or restore the post
 	if req.Delete {
-		post.SoftDelete()
+		if post.DeletedAt == nil {
+			err = h.postService.DeletePost(c, postID)
+		}
 	} else if post.DeletedAt != nil {
-		// Restore the post if it was previously deleted
-		post.DeletedAt = nil
-		post.UpdatedAt = time.Now()
+		err = h.postService.RestorePost(c, postID)
 	}
-
-	if err := h.postService.UpdatePost(c, post); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update post"})
+	if err != nil {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to update post")
 		return
 	}
 
@@ -330,6 +389,82 @@ func (h *AdminHandler) ModeratePost(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Post %s successfully", action)})
 }
 
+// ListPosts lists posts across all boards, including soft-deleted posts (admin only).
+// Optional since/until (RFC 3339) and agent_id query parameters narrow the listing.
+func (h *AdminHandler) ListPosts(c *gin.Context) {
+	// Parse pagination parameters
+	page, pageSize := parsePagination(c, 10, maxPageSize)
+
+	// Parse filters
+	var opts models.PostListOptions
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			RespondErrorStatus(c, http.StatusBadRequest, "Invalid since, expected an RFC 3339 timestamp")
+			return
+		}
+		opts.Since = &t
+	}
+	if until := c.Query("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			RespondErrorStatus(c, http.StatusBadRequest, "Invalid until, expected an RFC 3339 timestamp")
+			return
+		}
+		opts.Until = &t
+	}
+	if agentIDStr := c.Query("agent_id"); agentIDStr != "" {
+		agentID, err := uuid.Parse(agentIDStr)
+		if err != nil {
+			RespondErrorStatus(c, http.StatusBadRequest, "Invalid agent ID")
+			return
+		}
+		opts.AgentID = &agentID
+	}
+
+	posts, total, err := h.postService.AdminListPosts(c, opts, page, pageSize)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BuildPaginationResponse("posts", posts, total, page, pageSize))
+}
+
+// GetPost gets a post by ID, including soft-deleted posts (admin only)
+func (h *AdminHandler) GetPost(c *gin.Context) {
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	post, err := h.postService.FindPostByID(c, postID, true)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, post)
+}
+
+// GetReply gets a reply by ID, including soft-deleted replies (admin only)
+func (h *AdminHandler) GetReply(c *gin.Context) {
+	replyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid reply ID")
+		return
+	}
+
+	reply, err := h.replyService.FindReplyByID(c, replyID, true)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reply)
+}
+
 // ModerateReplyRequest represents the request body for moderating a reply
 type ModerateReplyRequest struct {
 	Delete bool   `json:"delete"`
@@ -341,41 +476,34 @@ func (h *AdminHandler) ModerateReply(c *gin.Context) {
 	// Parse reply ID
 	replyID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reply ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid reply ID")
 		return
 	}
 
 	// Parse request body
 	var req ModerateReplyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondBindError(c, err)
 		return
 	}
 
-	// Get reply
-	reply, err := h.replyService.GetReplyByID(c, replyID)
+	// Get reply, including deleted replies so they can be restored
+	reply, err := h.replyService.FindReplyByID(c, replyID, true)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reply"})
-		return
-	}
-	if reply == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Reply not found"})
+		RespondError(c, err)
 		return
 	}
 
-	// Update reply - soft delete or restore
-	// This is a simplified implementation
-	// In a real-world scenario, you would have a dedicated moderation service
+	// Soft delete or restore the reply
 	if req.Delete {
-		reply.SoftDelete()
+		if reply.DeletedAt == nil {
+			err = h.replyService.DeleteReply(c, replyID)
+		}
 	} else if reply.DeletedAt != nil {
-		// Restore the reply if it was previously deleted
-		reply.DeletedAt = nil
-		reply.UpdatedAt = time.Now()
+		err = h.replyService.RestoreReply(c, replyID)
 	}
-
-	if err := h.replyService.UpdateReply(c, reply); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reply"})
+	if err != nil {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to update reply")
 		return
 	}
 
@@ -390,12 +518,12 @@ func (h *AdminHandler) ModerateReply(c *gin.Context) {
 func (h *AdminHandler) ListAgentsForUser(c *gin.Context) {
 	userID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid user ID")
 		return
 	}
 	agents, err := h.agentService.GetAgentsByUserID(c, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve agents"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to retrieve agents")
 		return
 	}
 	response := make([]gin.H, len(agents))
@@ -404,9 +532,9 @@ func (h *AdminHandler) ListAgentsForUser(c *gin.Context) {
 			"id":          agent.ID,
 			"name":        agent.Name,
 			"description": agent.Description,
-			"api_key":     agent.APIKey,
 			"daily_limit": agent.DailyLimit,
 			"used_today":  agent.UsedToday,
+			"is_active":   agent.IsActive,
 			"created_at":  agent.CreatedAt,
 			"updated_at":  agent.UpdatedAt,
 		}
@@ -418,25 +546,25 @@ func (h *AdminHandler) ListAgentsForUser(c *gin.Context) {
 func (h *AdminHandler) GetAgentByID(c *gin.Context) {
 	agentID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid agent ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid agent ID")
 		return
 	}
 	agent, err := h.agentService.GetAgentByID(c, agentID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve agent"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to retrieve agent")
 		return
 	}
 	if agent == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Agent not found"})
+		RespondErrorStatus(c, http.StatusNotFound, "Agent not found")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"id":          agent.ID,
 		"name":        agent.Name,
 		"description": agent.Description,
-		"api_key":     agent.APIKey,
 		"daily_limit": agent.DailyLimit,
 		"used_today":  agent.UsedToday,
+		"is_active":   agent.IsActive,
 		"created_at":  agent.CreatedAt,
 		"updated_at":  agent.UpdatedAt,
 	})
@@ -446,21 +574,21 @@ func (h *AdminHandler) GetAgentByID(c *gin.Context) {
 func (h *AdminHandler) UpdateAgentByID(c *gin.Context) {
 	agentID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid agent ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid agent ID")
 		return
 	}
 	agent, err := h.agentService.GetAgentByID(c, agentID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve agent"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to retrieve agent")
 		return
 	}
 	if agent == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Agent not found"})
+		RespondErrorStatus(c, http.StatusNotFound, "Agent not found")
 		return
 	}
 	var req UpdateAgentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondBindError(c, err)
 		return
 	}
 	agent.Name = req.Name
@@ -469,16 +597,16 @@ func (h *AdminHandler) UpdateAgentByID(c *gin.Context) {
 		agent.DailyLimit = req.DailyLimit
 	}
 	if err := h.agentService.UpdateAgent(c, agent); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update agent"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to update agent")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"id":          agent.ID,
 		"name":        agent.Name,
 		"description": agent.Description,
-		"api_key":     agent.APIKey,
 		"daily_limit": agent.DailyLimit,
 		"used_today":  agent.UsedToday,
+		"is_active":   agent.IsActive,
 		"created_at":  agent.CreatedAt,
 		"updated_at":  agent.UpdatedAt,
 	})
@@ -488,16 +616,252 @@ func (h *AdminHandler) UpdateAgentByID(c *gin.Context) {
 func (h *AdminHandler) DeleteAgentByID(c *gin.Context) {
 	agentID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid agent ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid agent ID")
 		return
 	}
 	if err := h.agentService.DeleteAgent(c, agentID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete agent"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to delete agent")
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Agent deleted successfully"})
 }
 
+// SetAgentActive deactivates or reactivates a specific agent by ID (admin only)
+func (h *AdminHandler) SetAgentActive(c *gin.Context) {
+	agentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid agent ID")
+		return
+	}
+
+	var req struct {
+		IsActive *bool `json:"is_active" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondBindError(c, err)
+		return
+	}
+
+	agent, err := h.agentService.SetActive(c, agentID, *req.IsActive)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":         agent.ID,
+		"name":       agent.Name,
+		"is_active":  agent.IsActive,
+		"updated_at": agent.UpdatedAt,
+	})
+}
+
+// ImpersonateAgent mints a short-lived token that lets an admin act as an agent for debugging.
+// Every impersonation is recorded in the audit log before the token is issued.
+func (h *AdminHandler) ImpersonateAgent(c *gin.Context) {
+	agentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid agent ID")
+		return
+	}
+
+	// Get admin from context
+	userObj, exists := c.Get("user")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	admin, ok := userObj.(*models.User)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
+		return
+	}
+
+	agent, err := h.agentService.GetAgentByID(c, agentID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+	if agent == nil {
+		RespondErrorStatus(c, http.StatusNotFound, "Agent not found")
+		return
+	}
+
+	// Record the impersonation; no token is issued if it can't be recorded
+	details := fmt.Sprintf("admin %s impersonated agent %s", admin.Email, agent.Name)
+	if _, err := h.auditService.Record(c, admin.ID, models.AuditActionImpersonateAgent, "agent", agent.ID, details); err != nil {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to record impersonation")
+		return
+	}
+
+	tokens, err := h.authService.GenerateImpersonationToken(agent.ID, admin.ID)
+	if err != nil {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to generate impersonation token")
+		return
+	}
+
+	log.Printf("Admin %s issued impersonation token for agent %s", admin.ID, agent.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token":    tokens.AccessToken,
+		"expires_at":      tokens.ExpiresAt,
+		"agent_id":        agent.ID,
+		"impersonated_by": admin.ID,
+	})
+}
+
+// GetAuditLogs gets the audit log with pagination
+func (h *AdminHandler) GetAuditLogs(c *gin.Context) {
+	// Parse pagination parameters
+	page, pageSize := parsePagination(c, 10, maxPageSize)
+
+	logs, total, err := h.auditService.GetLogs(c, page, pageSize)
+	if err != nil {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to retrieve audit logs")
+		return
+	}
+
+	c.JSON(http.StatusOK, BuildPaginationResponse("audit_logs", logs, total, page, pageSize))
+}
+
+// Recount recomputes denormalized reply and vote counts from the source tables.
+// If post_id is given only that post is repaired, otherwise every post and reply is.
+func (h *AdminHandler) Recount(c *gin.Context) {
+	if postIDStr := c.Query("post_id"); postIDStr != "" {
+		postID, err := uuid.Parse(postIDStr)
+		if err != nil {
+			RespondErrorStatus(c, http.StatusBadRequest, "Invalid post ID")
+			return
+		}
+
+		post, err := h.postService.RecountStats(c, postID)
+		if err != nil {
+			RespondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, post)
+		return
+	}
+
+	result, err := h.postService.RecountAll(c)
+	if err != nil {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to recount stats")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Purge hard-deletes content that was soft-deleted longer ago than the retention window.
+// The window defaults to the configured retention and can be overridden with
+// ?older_than=<duration>, e.g. older_than=168h. When no retention is configured,
+// older_than is required.
+func (h *AdminHandler) Purge(c *gin.Context) {
+	olderThan := h.defaultRetention
+	if olderThanStr := c.Query("older_than"); olderThanStr != "" {
+		parsed, err := time.ParseDuration(olderThanStr)
+		if err != nil {
+			RespondErrorStatus(c, http.StatusBadRequest, "older_than must be a duration such as 720h")
+			return
+		}
+		olderThan = parsed
+	} else if olderThan <= 0 {
+		RespondErrorStatus(c, http.StatusBadRequest, "older_than is required when no soft-delete retention is configured")
+		return
+	}
+
+	result, err := h.adminService.PurgeSoftDeleted(c, olderThan)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeactivateInactiveBoards deactivates boards that have had no posts or replies for the
+// inactivity period. The period defaults to the configured one and can be overridden with
+// ?inactive_for=<duration>, e.g. inactive_for=720h.
+func (h *AdminHandler) DeactivateInactiveBoards(c *gin.Context) {
+	inactiveFor := h.defaultInactivity
+	if inactiveForStr := c.Query("inactive_for"); inactiveForStr != "" {
+		parsed, err := time.ParseDuration(inactiveForStr)
+		if err != nil {
+			RespondErrorStatus(c, http.StatusBadRequest, "inactive_for must be a duration such as 2160h")
+			return
+		}
+		inactiveFor = parsed
+	}
+
+	result, err := h.boardService.DeactivateInactiveBoards(c, inactiveFor)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// BroadcastNotification sends a system notification to every agent. The broadcast is
+// recorded in the audit log first, and nothing is sent if it can't be recorded.
+func (h *AdminHandler) BroadcastNotification(c *gin.Context) {
+	var req struct {
+		Content    string `json:"content" binding:"required"`
+		TargetType string `json:"target_type" binding:"required"`
+		TargetID   string `json:"target_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondBindError(c, err)
+		return
+	}
+
+	targetID, err := uuid.Parse(req.TargetID)
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid target ID")
+		return
+	}
+
+	// Get admin from context
+	userObj, exists := c.Get("user")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	admin, ok := userObj.(*models.User)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
+		return
+	}
+
+	// Validate the broadcast as Broadcast will, so only broadcasts that can be sent are audited
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		RespondError(c, services.ErrEmptyContent)
+		return
+	}
+	if _, err := models.ParseTargetType(req.TargetType); err != nil {
+		RespondError(c, services.ErrInvalidTargetType)
+		return
+	}
+
+	details := fmt.Sprintf("admin %s broadcast: %s", admin.Email, content)
+	if _, err := h.auditService.Record(c, admin.ID, models.AuditActionBroadcast, req.TargetType, targetID, details); err != nil {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to record broadcast")
+		return
+	}
+
+	notified, err := h.notifService.Broadcast(c, content, req.TargetType, targetID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notified": notified})
+}
+
 // RegisterRoutes registers the admin routes
 func (h *AdminHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, adminMiddleware gin.HandlerFunc) {
 	admin := router.Group("/admin")
@@ -505,6 +869,7 @@ func (h *AdminHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gi
 	{
 		// User management
 		admin.GET("/users", h.GetUsers)
+		admin.GET("/users/export.csv", h.ExportUsers)
 		admin.GET("/users/:id", h.GetUser)
 		admin.PUT("/users/:id", h.UpdateUser)
 		admin.DELETE("/users/:id", h.DeleteUser)
@@ -514,10 +879,26 @@ func (h *AdminHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gi
 		admin.GET("/agents/:id", h.GetAgentByID)
 		admin.PUT("/agents/:id", h.UpdateAgentByID)
 		admin.DELETE("/agents/:id", h.DeleteAgentByID)
+		admin.PUT("/agents/:id/active", h.SetAgentActive)
+		admin.POST("/agents/:id/impersonate", h.ImpersonateAgent)
 
 		// Content moderation
+		admin.GET("/posts", h.ListPosts)
+		admin.GET("/posts/:id", h.GetPost)
+		admin.GET("/replies/:id", h.GetReply)
 		admin.PUT("/posts/:id/moderate", h.ModeratePost)
 		admin.PUT("/replies/:id/moderate", h.ModerateReply)
+
+		// Maintenance
+		admin.POST("/recount", h.Recount)
+		admin.POST("/purge", h.Purge)
+		admin.POST("/boards/deactivate-inactive", h.DeactivateInactiveBoards)
+
+		// Announcements
+		admin.POST("/notifications/broadcast", h.BroadcastNotification)
+
+		// Audit log
+		admin.GET("/audit-logs", h.GetAuditLogs)
 	}
 }
 
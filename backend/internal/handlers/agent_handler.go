@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"errors"
+	"fmt"
 	"log"
 	"net/http"
 
@@ -25,10 +25,11 @@ func NewAgentHandler(agentService services.AgentService) *AgentHandler {
 }
 
 // CreateAgentRequest represents the request body for creating an agent
-// DailyLimit removed - now always defaults to 0 in backend
+// Only admins can set daily_limit; otherwise the configured default is used
 type CreateAgentRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description"`
+	DailyLimit  int    `json:"daily_limit" binding:"omitempty,min=1,max=500000"` // Only used by admins
 }
 
 // UpdateAgentRequest represents the request body for updating an agent
@@ -36,10 +37,19 @@ type CreateAgentRequest struct {
 // If a non-admin user sends daily_limit, it will be ignored and not updated
 // Admins can update daily_limit as usual
 type UpdateAgentRequest struct {
-	Name              string `json:"name" binding:"required"`
-	Description       string `json:"description"`
-	DailyLimit        int    `json:"daily_limit" binding:"min=1,max=500000"` // Only used by admins
-	ProfilePictureURL string `json:"profile_picture_url" binding:"omitempty,url"`
+	Name              string  `json:"name" binding:"required"`
+	Description       string  `json:"description"`
+	DailyLimit        int     `json:"daily_limit" binding:"min=1,max=500000"` // Only used by admins
+	ProfilePictureURL string  `json:"profile_picture_url" binding:"omitempty,url"`
+	HideVoters        *bool   `json:"hide_voters"`
+	Bio               *string `json:"bio"`         // Markdown; an empty string clears it
+	WebsiteURL        *string `json:"website_url"` // An empty string clears it
+	Location          *string `json:"location"`    // An empty string clears it
+}
+
+// UpdateProfilePictureRequest represents the request body for changing only an agent's profile picture
+type UpdateProfilePictureRequest struct {
+	ProfilePictureURL string `json:"profile_picture_url" binding:"required"`
 }
 
 // ListAgents returns all agents for the current user
@@ -48,20 +58,20 @@ func (h *AgentHandler) ListAgents(c *gin.Context) {
 	userObj, exists := c.Get("user")
 	log.Printf("AgentHandler.ListAgents: userObj: %+v, exists: %v", userObj, exists)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
 		return
 	}
 	user, ok := userObj.(*models.User)
 	log.Printf("AgentHandler.ListAgents: user type assertion ok? %v", ok)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
 		return
 	}
 
 	agents, err := h.agentService.GetAgentsByUserID(c, user.ID)
 	log.Printf("AgentHandler.ListAgents: agents: %+v, err: %v", agents, err)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve agents"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to retrieve agents")
 		return
 	}
 
@@ -72,7 +82,6 @@ func (h *AgentHandler) ListAgents(c *gin.Context) {
 			"id":          agent.ID,
 			"name":        agent.Name,
 			"description": agent.Description,
-			"api_key":     agent.APIKey,
 			"daily_limit": agent.DailyLimit,
 			"used_today":  agent.UsedToday,
 			"created_at":  agent.CreatedAt,
@@ -89,7 +98,7 @@ func (h *AgentHandler) GetAgent(c *gin.Context) {
 	agentID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		log.Printf("AgentHandler.GetAgent: invalid agent ID param: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid agent ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid agent ID")
 		return
 	}
 
@@ -97,30 +106,30 @@ func (h *AgentHandler) GetAgent(c *gin.Context) {
 	userObj, exists := c.Get("user")
 	log.Printf("AgentHandler.GetAgent: userObj: %+v, exists: %v", userObj, exists)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
 		return
 	}
 	user, ok := userObj.(*models.User)
 	log.Printf("AgentHandler.GetAgent: user type assertion ok? %v", ok)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
 		return
 	}
 
 	agent, err := h.agentService.GetAgentByID(c, agentID)
 	log.Printf("AgentHandler.GetAgent: agent: %+v, err: %v", agent, err)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve agent"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to retrieve agent")
 		return
 	}
 	if agent == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Agent not found"})
+		RespondErrorStatus(c, http.StatusNotFound, "Agent not found")
 		return
 	}
 
 	if agent.UserID != user.ID && !user.IsAdmin {
 		log.Printf("AgentHandler.GetAgent: forbidden, user %v is not owner or admin", user.ID)
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this agent"})
+		RespondErrorStatus(c, http.StatusForbidden, "You do not have permission to access this agent")
 		return
 	}
 
@@ -128,7 +137,6 @@ func (h *AgentHandler) GetAgent(c *gin.Context) {
 		"id":          agent.ID,
 		"name":        agent.Name,
 		"description": agent.Description,
-		"api_key":     agent.APIKey,
 		"daily_limit": agent.DailyLimit,
 		"used_today":  agent.UsedToday,
 		"created_at":  agent.CreatedAt,
@@ -141,48 +149,37 @@ func (h *AgentHandler) CreateAgent(c *gin.Context) {
 	// Get user from context
 	userObj, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
 		return
 	}
 
 	user, ok := userObj.(*models.User)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
 		return
 	}
 
 	// Parse request body
 	var req CreateAgentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Get existing agents for user
-	agents, err := h.agentService.GetAgentsByUserID(c, user.ID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check agent limit"})
+		RespondBindError(c, err)
 		return
 	}
 
-	// Check agent limit (max 25 agents per user)
-	if len(agents) >= 25 && !user.IsAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Maximum number of agents reached (25)"})
-		return
+	// Only admins can choose the daily limit; 0 gets the configured default
+	dailyLimit := 0
+	if user.IsAdmin {
+		dailyLimit = req.DailyLimit
 	}
 
-	// Create agent via service layer (default daily limit 50 if 0)
-	agent, err := h.agentService.CreateAgent(c, user.ID, req.Name, req.Description, 0)
+	// Create agent via service layer; the service enforces the agent limit
+	agent, err := h.agentService.CreateAgent(c, user.ID, req.Name, req.Description, dailyLimit)
 	if err != nil {
-		if errors.Is(err, services.ErrAgentNameExists) {
-			c.JSON(http.StatusConflict, gin.H{"error": "Agent name already exists. Please choose a different name."})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create agent"})
+		RespondError(c, err)
 		return
 	}
 
-	// Return created agent
+	// Return created agent; the raw API key is only ever shown here and on regeneration
 	c.JSON(http.StatusCreated, gin.H{
 		"id":          agent.ID,
 		"name":        agent.Name,
@@ -201,40 +198,40 @@ func (h *AgentHandler) UpdateAgent(c *gin.Context) {
 	agentIDStr := c.Param("id")
 	agentID, err := uuid.Parse(agentIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid agent ID format"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid agent ID format")
 		return
 	}
 
 	// Get user from context
 	userObj, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
 		return
 	}
 
 	user, ok := userObj.(*models.User)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
 		return
 	}
 
 	// Get agent
 	agent, err := h.agentService.GetAgentByID(c, agentID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve agent"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to retrieve agent")
 		return
 	}
 
 	// Check if agent belongs to user or user is admin
 	if agent.UserID != user.ID && !user.IsAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to update this agent"})
+		RespondErrorStatus(c, http.StatusForbidden, "You do not have permission to update this agent")
 		return
 	}
 
 	// Parse request body
 	var req UpdateAgentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondBindError(c, err)
 		return
 	}
 
@@ -254,8 +251,24 @@ func (h *AgentHandler) UpdateAgent(c *gin.Context) {
 		agent.ProfilePictureURL = req.ProfilePictureURL
 	}
 
+	// Only change the voter privacy setting if present
+	if req.HideVoters != nil {
+		agent.HideVoters = *req.HideVoters
+	}
+
+	// Only change the profile fields that are present
+	if req.Bio != nil {
+		agent.Bio = *req.Bio
+	}
+	if req.WebsiteURL != nil {
+		agent.WebsiteURL = *req.WebsiteURL
+	}
+	if req.Location != nil {
+		agent.Location = *req.Location
+	}
+
 	if err := h.agentService.UpdateAgent(c, agent); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update agent"})
+		RespondError(c, err)
 		return
 	}
 
@@ -264,53 +277,112 @@ func (h *AgentHandler) UpdateAgent(c *gin.Context) {
 		"id":          agent.ID,
 		"name":        agent.Name,
 		"description": agent.Description,
-		"api_key":     agent.APIKey,
 		"daily_limit": agent.DailyLimit,
 		"used_today":  agent.UsedToday,
+		"hide_voters": agent.HideVoters,
+		"bio":         agent.Bio,
+		"website_url": agent.WebsiteURL,
+		"location":    agent.Location,
 		"created_at":  agent.CreatedAt,
 		"updated_at":  agent.UpdatedAt,
 	})
 }
 
+// UpdateProfilePicture changes only an agent's profile picture, so clients don't have to
+// resend the agent's other fields
+func (h *AgentHandler) UpdateProfilePicture(c *gin.Context) {
+	// Parse agent ID from URL
+	agentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid agent ID format")
+		return
+	}
+
+	// Get user from context
+	userObj, exists := c.Get("user")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	user, ok := userObj.(*models.User)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
+		return
+	}
+
+	// Get agent
+	agent, err := h.agentService.GetAgentByID(c, agentID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	// Check if agent belongs to user or user is admin
+	if agent.UserID != user.ID && !user.IsAdmin {
+		RespondErrorStatus(c, http.StatusForbidden, "You do not have permission to update this agent")
+		return
+	}
+
+	// Parse request body
+	var req UpdateProfilePictureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondBindError(c, err)
+		return
+	}
+
+	agent, err = h.agentService.UpdateProfilePicture(c, agentID, req.ProfilePictureURL)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":                  agent.ID,
+		"profile_picture_url": agent.ProfilePictureURL,
+		"updated_at":          agent.UpdatedAt,
+	})
+}
+
 // DeleteAgent deletes an existing agent
 func (h *AgentHandler) DeleteAgent(c *gin.Context) {
 	// Parse agent ID from URL
 	agentIDStr := c.Param("id")
 	agentID, err := uuid.Parse(agentIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid agent ID format"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid agent ID format")
 		return
 	}
 
 	// Get user from context
 	userObj, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
 		return
 	}
 
 	user, ok := userObj.(*models.User)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
 		return
 	}
 
 	// Get agent
 	agent, err := h.agentService.GetAgentByID(c, agentID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve agent"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to retrieve agent")
 		return
 	}
 
 	// Check if agent belongs to user
 	if agent.UserID != user.ID && !user.IsAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to delete this agent"})
+		RespondErrorStatus(c, http.StatusForbidden, "You do not have permission to delete this agent")
 		return
 	}
 
 	// Delete agent
 	if err := h.agentService.DeleteAgent(c, agentID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete agent"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to delete agent")
 		return
 	}
 
@@ -323,40 +395,40 @@ func (h *AgentHandler) RegenerateAPIKey(c *gin.Context) {
 	agentIDStr := c.Param("id")
 	agentID, err := uuid.Parse(agentIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid agent ID format"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid agent ID format")
 		return
 	}
 
 	// Get user from context
 	userObj, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
 		return
 	}
 
 	user, ok := userObj.(*models.User)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
 		return
 	}
 
 	// Get agent
 	agent, err := h.agentService.GetAgentByID(c, agentID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve agent"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to retrieve agent")
 		return
 	}
 
 	// Check if agent belongs to user
 	if agent.UserID != user.ID && !user.IsAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to regenerate API key for this agent"})
+		RespondErrorStatus(c, http.StatusForbidden, "You do not have permission to regenerate API key for this agent")
 		return
 	}
 
 	// Regenerate API key
 	newAPIKey, err := h.agentService.RegenerateAPIKey(c, agentID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate API key"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to regenerate API key")
 		return
 	}
 
@@ -371,13 +443,13 @@ func (h *AgentHandler) GetCurrentAgent(c *gin.Context) {
 	agentObj, exists := c.Get("agent")
 	log.Printf("AgentHandler.GetCurrentAgent: agentObj: %+v, exists: %v", agentObj, exists)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Agent not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
 		return
 	}
 	agent, ok := agentObj.(*models.Agent)
 	log.Printf("AgentHandler.GetCurrentAgent: agent type assertion ok? %v", ok)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid agent type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
 		return
 	}
 
@@ -385,7 +457,6 @@ func (h *AgentHandler) GetCurrentAgent(c *gin.Context) {
 		"id":          agent.ID,
 		"name":        agent.Name,
 		"description": agent.Description,
-		"api_key":     agent.APIKey,
 		"daily_limit": agent.DailyLimit,
 		"used_today":  agent.UsedToday,
 		"created_at":  agent.CreatedAt,
@@ -393,18 +464,229 @@ func (h *AgentHandler) GetCurrentAgent(c *gin.Context) {
 	})
 }
 
+// GetCurrentAgentQuota returns the daily message quota for the authenticated agent
+func (h *AgentHandler) GetCurrentAgentQuota(c *gin.Context) {
+	agentObj, exists := c.Get("agent")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
+		return
+	}
+	agent, ok := agentObj.(*models.Agent)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"daily_limit": agent.DailyLimit,
+		"used_today":  agent.UsedToday,
+		"remaining":   agent.RemainingToday(),
+		"reset_at":    models.DailyUsageResetAt(),
+	})
+}
+
+// GetCurrentAgentTodayActivity returns how many posts, replies and votes the authenticated
+// agent has created today (UTC), breaking down the combined used_today count
+func (h *AgentHandler) GetCurrentAgentTodayActivity(c *gin.Context) {
+	agentObj, exists := c.Get("agent")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
+		return
+	}
+	agent, ok := agentObj.(*models.Agent)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+		return
+	}
+
+	activity, err := h.agentService.GetTodayActivity(c.Request.Context(), agent.ID)
+	if err != nil {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to retrieve today's activity")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"posts":      activity.Posts,
+		"replies":    activity.Replies,
+		"votes":      activity.Votes,
+		"since":      models.DailyUsageStartedAt(),
+		"used_today": agent.UsedToday,
+	})
+}
+
+// ExportAgentData streams everything an agent created as a JSON download. An agent
+// (API key) exports itself; a user (JWT) names one of their agents with ?agent_id=,
+// and admins may export any agent.
+func (h *AgentHandler) ExportAgentData(c *gin.Context) {
+	var agent *models.Agent
+	if agentObj, exists := c.Get("agent"); exists {
+		a, ok := agentObj.(*models.Agent)
+		if !ok {
+			RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+			return
+		}
+		agent = a
+	} else {
+		userObj, exists := c.Get("user")
+		if !exists {
+			RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
+			return
+		}
+		user, ok := userObj.(*models.User)
+		if !ok {
+			RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
+			return
+		}
+
+		agentID, err := uuid.Parse(c.Query("agent_id"))
+		if err != nil {
+			RespondErrorStatus(c, http.StatusBadRequest, "Invalid agent ID format")
+			return
+		}
+		agent, err = h.agentService.GetAgentByID(c, agentID)
+		if err != nil {
+			RespondErrorStatus(c, http.StatusInternalServerError, "Failed to retrieve agent")
+			return
+		}
+		if agent == nil {
+			RespondError(c, services.ErrAgentNotFound)
+			return
+		}
+		if agent.UserID != user.ID && !user.IsAdmin {
+			RespondErrorStatus(c, http.StatusForbidden, "You do not have permission to export this agent")
+			return
+		}
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="agent-%s-export.json"`, agent.ID))
+	c.Status(http.StatusOK)
+
+	if err := h.agentService.ExportData(c, agent.ID, c.Writer); err != nil {
+		// Once the document has started the status can't change; the client sees truncated JSON
+		if !c.Writer.Written() {
+			RespondError(c, err)
+			return
+		}
+		log.Printf("AgentHandler.ExportAgentData: export of agent %s failed: %v", agent.ID, err)
+	}
+}
+
+// blockRequest names the agent to block or unblock
+type blockRequest struct {
+	AgentID string `json:"agent_id" binding:"required"`
+}
+
+// ListBlockedAgents returns public info for the agents the authenticated agent has blocked
+func (h *AgentHandler) ListBlockedAgents(c *gin.Context) {
+	agentObj, exists := c.Get("agent")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
+		return
+	}
+	agent, ok := agentObj.(*models.Agent)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+		return
+	}
+
+	blocked, err := h.agentService.ListBlocked(c.Request.Context(), agent.ID)
+	if err != nil {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to retrieve blocked agents")
+		return
+	}
+
+	agentResponses := make([]gin.H, len(blocked))
+	for i, blockedAgent := range blocked {
+		agentResponses[i] = gin.H{
+			"id":                  blockedAgent.ID,
+			"name":                blockedAgent.Name,
+			"description":         blockedAgent.Description,
+			"profile_picture_url": blockedAgent.ProfilePictureURL,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"agents": agentResponses})
+}
+
+// BlockAgent blocks an agent for the authenticated agent. The blocked agent's posts and
+// replies are hidden from the blocker's listings and it no longer triggers notifications
+// for the blocker.
+func (h *AgentHandler) BlockAgent(c *gin.Context) {
+	agentObj, exists := c.Get("agent")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
+		return
+	}
+	agent, ok := agentObj.(*models.Agent)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+		return
+	}
+
+	var req blockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondBindError(c, err)
+		return
+	}
+	blockedID, err := uuid.Parse(req.AgentID)
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid agent ID format")
+		return
+	}
+
+	if err := h.agentService.BlockAgent(c.Request.Context(), agent.ID, blockedID); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"blocked": true, "agent_id": blockedID})
+}
+
+// UnblockAgent removes a block added by BlockAgent
+func (h *AgentHandler) UnblockAgent(c *gin.Context) {
+	agentObj, exists := c.Get("agent")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
+		return
+	}
+	agent, ok := agentObj.(*models.Agent)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+		return
+	}
+
+	var req blockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondBindError(c, err)
+		return
+	}
+	blockedID, err := uuid.Parse(req.AgentID)
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid agent ID format")
+		return
+	}
+
+	if err := h.agentService.UnblockAgent(c.Request.Context(), agent.ID, blockedID); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"blocked": false, "agent_id": blockedID})
+}
+
 // GetAgentPublic returns public info for an agent by ID (no auth required)
 func (h *AgentHandler) GetAgentPublic(c *gin.Context) {
 	agentIDStr := c.Param("id")
 	agentID, err := uuid.Parse(agentIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid agent ID format"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid agent ID format")
 		return
 	}
 
 	agent, err := h.agentService.GetAgentByID(c, agentID)
 	if err != nil || agent == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Agent not found"})
+		RespondErrorStatus(c, http.StatusNotFound, "Agent not found")
 		return
 	}
 
@@ -413,15 +695,106 @@ func (h *AgentHandler) GetAgentPublic(c *gin.Context) {
 		"name":                agent.Name,
 		"description":         agent.Description,
 		"profile_picture_url": agent.ProfilePictureURL,
+		"bio":                 agent.Bio,
+		"website_url":         agent.WebsiteURL,
+		"location":            agent.Location,
+		"last_active_at":      agent.LastActiveAt,
 	})
 }
 
+// GetAgentPresence reports whether an agent is online, away or offline (no auth required)
+func (h *AgentHandler) GetAgentPresence(c *gin.Context) {
+	agentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid agent ID format")
+		return
+	}
+
+	presence, err := h.agentService.GetPresence(c.Request.Context(), agentID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, presence)
+}
+
+// GetAgentsPublicBatch returns public info for several agents at once.
+// Unknown IDs are omitted from the response.
+func (h *AgentHandler) GetAgentsPublicBatch(c *gin.Context) {
+	var req struct {
+		IDs []string `json:"ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondBindError(c, err)
+		return
+	}
+
+	agentIDs := make([]uuid.UUID, len(req.IDs))
+	for i, idStr := range req.IDs {
+		agentID, err := uuid.Parse(idStr)
+		if err != nil {
+			RespondErrorStatus(c, http.StatusBadRequest, fmt.Sprintf("Invalid agent ID format: %s", idStr))
+			return
+		}
+		agentIDs[i] = agentID
+	}
+
+	agents, err := h.agentService.GetAgentsByIDs(c, agentIDs)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	agentResponses := make([]gin.H, len(agents))
+	for i, agent := range agents {
+		agentResponses[i] = gin.H{
+			"id":                  agent.ID,
+			"name":                agent.Name,
+			"description":         agent.Description,
+			"profile_picture_url": agent.ProfilePictureURL,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"agents": agentResponses})
+}
+
+// SearchAgents finds active agents by name prefix and returns their public info
+func (h *AgentHandler) SearchAgents(c *gin.Context) {
+	query := c.Query("q")
+
+	page, pageSize := parsePagination(c, 10, services.MaxAgentSearchPageSize)
+
+	agents, total, err := h.agentService.SearchAgents(c.Request.Context(), query, page, pageSize)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	agentResponses := make([]gin.H, len(agents))
+	for i, agent := range agents {
+		agentResponses[i] = gin.H{
+			"id":                  agent.ID,
+			"name":                agent.Name,
+			"description":         agent.Description,
+			"profile_picture_url": agent.ProfilePictureURL,
+		}
+	}
+
+	response := BuildPaginationResponse("agents", agentResponses, total, page, pageSize)
+	response["query"] = query
+	c.JSON(http.StatusOK, response)
+}
+
 // RegisterRoutes registers the agent routes
 func (h *AgentHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
 	agents := router.Group("/agents")
 
 	// Public route for agent info by ID
 	agents.GET("/public/:id", h.GetAgentPublic)
+	agents.POST("/public/batch", h.GetAgentsPublicBatch)
+	agents.GET("/search", h.SearchAgents)
+	agents.GET("/:id/presence", h.GetAgentPresence)
 
 	agents.Use(authMiddleware)
 	{
@@ -429,8 +802,15 @@ func (h *AgentHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gi
 		agents.GET("/:id", h.GetAgent)
 		agents.POST("", h.CreateAgent)
 		agents.PUT("/:id", h.UpdateAgent)
+		agents.PATCH("/:id/profile-picture", h.UpdateProfilePicture)
 		agents.DELETE("/:id", h.DeleteAgent)
 		agents.POST("/:id/regenerate-api-key", h.RegenerateAPIKey)
 		agents.GET("/me", h.GetCurrentAgent)
+		agents.GET("/me/quota", h.GetCurrentAgentQuota)
+		agents.GET("/me/today", h.GetCurrentAgentTodayActivity)
+		agents.GET("/me/export", h.ExportAgentData)
+		agents.GET("/me/blocks", h.ListBlockedAgents)
+		agents.POST("/me/blocks", h.BlockAgent)
+		agents.DELETE("/me/blocks", h.UnblockAgent)
 	}
 }
@@ -48,22 +48,15 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Printf("AuthHandler.Register: failed to bind JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondBindError(c, err)
 		return
 	}
 
 	user, tokens, err := h.authService.Register(c, req.Email, req.Password, req.Name, req.BetaCode)
 	log.Printf("AuthHandler.Register: user: %+v, tokens: %+v, err: %v", user, tokens, err)
 	if err != nil {
-		status := http.StatusInternalServerError
-		switch err {
-		case services.ErrUserAlreadyExists:
-			status = http.StatusConflict
-		case services.ErrInvalidBetaCode:
-			status = http.StatusBadRequest
-		}
-		log.Printf("AuthHandler.Register: error response status %d: %v", status, err)
-		c.JSON(status, gin.H{"error": err.Error()})
+		log.Printf("AuthHandler.Register: error response: %v", err)
+		RespondError(c, err)
 		return
 	}
 
@@ -99,19 +92,15 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Printf("AuthHandler.Login: failed to bind JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondBindError(c, err)
 		return
 	}
 
 	user, tokens, err := h.authService.Login(c, req.Email, req.Password)
 	log.Printf("AuthHandler.Login: user: %+v, tokens: %+v, err: %v", user, tokens, err)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == services.ErrInvalidCredentials {
-			status = http.StatusUnauthorized
-		}
-		log.Printf("AuthHandler.Login: error response status %d: %v", status, err)
-		c.JSON(status, gin.H{"error": err.Error()})
+		log.Printf("AuthHandler.Login: error response: %v", err)
+		RespondError(c, err)
 		return
 	}
 
@@ -147,7 +136,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	refreshToken, err := c.Cookie("refresh_token")
 	if err != nil {
 		log.Printf("AuthHandler.RefreshToken: no refresh token cookie: %v", err)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token not found"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "Refresh token not found")
 		return
 	}
 
@@ -155,7 +144,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	log.Printf("AuthHandler.RefreshToken: tokens: %+v, err: %v", tokens, err)
 	if err != nil {
 		log.Printf("AuthHandler.RefreshToken: invalid refresh token: %v", err)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "Invalid refresh token")
 		return
 	}
 
@@ -178,12 +167,13 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	})
 }
 
-// RegisterRoutes registers the auth routes
-func (h *AuthHandler) RegisterRoutes(router *gin.RouterGroup) {
+// RegisterRoutes registers the auth routes.
+// rateLimiter guards the endpoints that can be used to guess credentials or mass-create accounts.
+func (h *AuthHandler) RegisterRoutes(router *gin.RouterGroup, rateLimiter gin.HandlerFunc) {
 	auth := router.Group("/auth")
 	{
-		auth.POST("/signup", h.Register)
-		auth.POST("/login", h.Login)
+		auth.POST("/signup", rateLimiter, h.Register)
+		auth.POST("/login", rateLimiter, h.Login)
 		auth.POST("/refresh", h.RefreshToken)
 	}
 }
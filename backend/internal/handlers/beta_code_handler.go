@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -33,41 +32,29 @@ func (h *BetaCodeHandler) ListBetaCodes(c *gin.Context) {
 	// Get user from context
 	userObj, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
 		return
 	}
 
 	user, ok := userObj.(*models.User)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
 		return
 	}
 
 	// Only admin users can list beta codes
 	if !user.IsAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access beta codes"})
+		RespondErrorStatus(c, http.StatusForbidden, "You do not have permission to access beta codes")
 		return
 	}
 
 	// Parse pagination parameters
-	page := 1
-	pageSize := 20
-
-	pageStr := c.DefaultQuery("page", "1")
-	pageSizeStr := c.DefaultQuery("page_size", "20")
-
-	if pageVal, err := strconv.Atoi(pageStr); err == nil && pageVal > 0 {
-		page = pageVal
-	}
-
-	if pageSizeVal, err := strconv.Atoi(pageSizeStr); err == nil && pageSizeVal > 0 && pageSizeVal <= 100 {
-		pageSize = pageSizeVal
-	}
+	page, pageSize := parsePagination(c, 20, maxPageSize)
 
 	// Get beta codes
 	betaCodes, totalCount, err := h.betaCodeService.ListBetaCodes(c, page, pageSize)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve beta codes"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to retrieve beta codes")
 		return
 	}
 
@@ -84,12 +71,7 @@ func (h *BetaCodeHandler) ListBetaCodes(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"beta_codes":  response,
-		"total_count": totalCount,
-		"page":        page,
-		"page_size":   pageSize,
-	})
+	c.JSON(http.StatusOK, BuildPaginationResponse("beta_codes", response, totalCount, page, pageSize))
 }
 
 // CreateBetaCode creates one or more new beta codes
@@ -97,19 +79,19 @@ func (h *BetaCodeHandler) CreateBetaCode(c *gin.Context) {
 	// Get user from context
 	userObj, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
 		return
 	}
 
 	user, ok := userObj.(*models.User)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
 		return
 	}
 
 	// Only admin users can create beta codes
 	if !user.IsAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to create beta codes"})
+		RespondErrorStatus(c, http.StatusForbidden, "You do not have permission to create beta codes")
 		return
 	}
 
@@ -120,7 +102,7 @@ func (h *BetaCodeHandler) CreateBetaCode(c *gin.Context) {
 		if err.Error() == "EOF" {
 			req.Count = 1
 		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			RespondErrorStatus(c, http.StatusBadRequest, err.Error())
 			return
 		}
 	}
@@ -133,7 +115,7 @@ func (h *BetaCodeHandler) CreateBetaCode(c *gin.Context) {
 		// Create a single beta code
 		betaCode, err := h.betaCodeService.CreateBetaCode(c)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create beta code"})
+			RespondErrorStatus(c, http.StatusInternalServerError, "Failed to create beta code")
 			return
 		}
 		betaCodes = []*models.BetaCode{betaCode}
@@ -141,7 +123,7 @@ func (h *BetaCodeHandler) CreateBetaCode(c *gin.Context) {
 		// Create multiple beta codes
 		betaCodes, err = h.betaCodeService.CreateMultipleBetaCodes(c, req.Count)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create beta codes"})
+			RespondErrorStatus(c, http.StatusInternalServerError, "Failed to create beta codes")
 			return
 		}
 	}
@@ -164,19 +146,19 @@ func (h *BetaCodeHandler) DeleteBetaCode(c *gin.Context) {
 	// Get user from context
 	userObj, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
 		return
 	}
 
 	user, ok := userObj.(*models.User)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
 		return
 	}
 
 	// Only admin users can delete beta codes
 	if !user.IsAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to delete beta codes"})
+		RespondErrorStatus(c, http.StatusForbidden, "You do not have permission to delete beta codes")
 		return
 	}
 
@@ -184,18 +166,14 @@ func (h *BetaCodeHandler) DeleteBetaCode(c *gin.Context) {
 	betaCodeIDStr := c.Param("id")
 	betaCodeID, err := uuid.Parse(betaCodeIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid beta code ID format"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid beta code ID format")
 		return
 	}
 
 	// Delete beta code
 	err = h.betaCodeService.DeleteBetaCode(c, betaCodeID)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == services.ErrBetaCodeNotFound {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -4,31 +4,36 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/garrettallen/aiboards/backend/internal/models"
 	"github.com/garrettallen/aiboards/backend/internal/services"
 )
 
 // BoardHandler handles HTTP requests related to boards
 type BoardHandler struct {
 	boardService services.BoardService
+	agentService services.AgentService
 }
 
 // NewBoardHandler creates a new BoardHandler
-func NewBoardHandler(boardService services.BoardService) *BoardHandler {
+func NewBoardHandler(boardService services.BoardService, agentService services.AgentService) *BoardHandler {
 	return &BoardHandler{
 		boardService: boardService,
+		agentService: agentService,
 	}
 }
 
-// CreateBoard creates a new board
+// CreateBoard creates a new board. Users who leave out agent_id own the board themselves;
+// otherwise it is owned by the agent the caller acts as (see actingAgentID).
 func (h *BoardHandler) CreateBoard(c *gin.Context) {
 	log.Printf("CreateBoard: called for %s", c.Request.URL.Path)
 	// Parse request
 	var req struct {
-		AgentID     string `json:"agent_id" binding:"required"`
+		AgentID     string `json:"agent_id"`
 		Title       string `json:"title" binding:"required"`
 		Description string `json:"description" binding:"required"`
 		IsActive    bool   `json:"is_active"`
@@ -36,31 +41,59 @@ func (h *BoardHandler) CreateBoard(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Printf("CreateBoard: failed to bind JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondBindError(c, err)
 		return
 	}
 
-	// Parse agent ID
-	agentID, err := uuid.Parse(req.AgentID)
-	log.Printf("CreateBoard: agentID: %s, err: %v", req.AgentID, err)
+	// Create board
+	var board *models.Board
+	var err error
+	if user, isUser := contextUser(c); isUser && req.AgentID == "" {
+		board, err = h.boardService.CreateUserBoard(c.Request.Context(), user.ID, req.Title, req.Description, req.IsActive)
+	} else {
+		agentID, ok := actingAgentID(c, h.agentService, req.AgentID)
+		if !ok {
+			return
+		}
+		board, err = h.boardService.CreateBoard(c.Request.Context(), agentID, req.Title, req.Description, req.IsActive)
+	}
+	log.Printf("CreateBoard: created board: %+v, err: %v", board, err)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid agent ID"})
+		RespondError(c, err)
 		return
 	}
 
-	// Create board
-	board, err := h.boardService.CreateBoard(c.Request.Context(), agentID, req.Title, req.Description, req.IsActive)
-	log.Printf("CreateBoard: created board: %+v, err: %v", board, err)
+	c.JSON(http.StatusCreated, board)
+}
+
+// CreateBoardWithPost creates a board owned by the agent the caller acts as together with
+// its first post. Either both are created or neither is.
+func (h *BoardHandler) CreateBoardWithPost(c *gin.Context) {
+	var req struct {
+		AgentID     string `json:"agent_id"`
+		Title       string `json:"title" binding:"required"`
+		Description string `json:"description" binding:"required"`
+		IsActive    bool   `json:"is_active"`
+		PostContent string `json:"post_content" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondBindError(c, err)
+		return
+	}
+
+	agentID, ok := actingAgentID(c, h.agentService, req.AgentID)
+	if !ok {
+		return
+	}
+
+	board, post, err := h.boardService.CreateBoardWithPost(c.Request.Context(), agentID, req.Title, req.Description, req.IsActive, req.PostContent)
 	if err != nil {
-		if err == services.ErrAgentNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "agent not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, board)
+	c.JSON(http.StatusCreated, gin.H{"board": board, "post": post})
 }
 
 // GetBoard gets a board by ID
@@ -70,7 +103,7 @@ func (h *BoardHandler) GetBoard(c *gin.Context) {
 	boardID, err := uuid.Parse(c.Param("id"))
 	log.Printf("GetBoard: boardID param: %s, err: %v", c.Param("id"), err)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid board ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid board ID")
 		return
 	}
 
@@ -78,11 +111,13 @@ func (h *BoardHandler) GetBoard(c *gin.Context) {
 	board, err := h.boardService.GetBoardByID(c.Request.Context(), boardID)
 	log.Printf("GetBoard: board: %+v, err: %v", board, err)
 	if err != nil {
-		if err == services.ErrBoardNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
+		return
+	}
+
+	// Boards whose visibility requires authentication can't be read anonymously
+	if !board.ReadableBy(isAuthenticated(c)) {
+		RespondError(c, services.ErrBoardRequiresAuth)
 		return
 	}
 
@@ -96,7 +131,7 @@ func (h *BoardHandler) GetBoardByAgent(c *gin.Context) {
 	agentID, err := uuid.Parse(c.Param("agent_id"))
 	log.Printf("GetBoardByAgent: agentID param: %s, err: %v", c.Param("agent_id"), err)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid agent ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid agent ID")
 		return
 	}
 
@@ -104,15 +139,13 @@ func (h *BoardHandler) GetBoardByAgent(c *gin.Context) {
 	board, err := h.boardService.GetBoardByAgentID(c.Request.Context(), agentID)
 	log.Printf("GetBoardByAgent: board: %+v, err: %v", board, err)
 	if err != nil {
-		if err == services.ErrAgentNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "agent not found"})
-			return
-		}
-		if err == services.ErrBoardNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
+		return
+	}
+
+	// Boards whose visibility requires authentication can't be read anonymously
+	if !board.ReadableBy(isAuthenticated(c)) {
+		RespondError(c, services.ErrBoardRequiresAuth)
 		return
 	}
 
@@ -126,13 +159,13 @@ func (h *BoardHandler) UpdateBoard(c *gin.Context) {
 	boardID, err := uuid.Parse(c.Param("id"))
 	log.Printf("UpdateBoard: boardID param: %s, err: %v", c.Param("id"), err)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid board ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid board ID")
 		return
 	}
 
 	// Parse request
 	var req struct {
-		AgentID     string `json:"agent_id" binding:"required"`
+		AgentID     string `json:"agent_id"` // the owner agent, for users updating an agent-owned board
 		Title       string `json:"title" binding:"required"`
 		Description string `json:"description" binding:"required"`
 		IsActive    bool   `json:"is_active"`
@@ -140,15 +173,7 @@ func (h *BoardHandler) UpdateBoard(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Printf("UpdateBoard: failed to bind JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Parse agent ID
-	agentID, err := uuid.Parse(req.AgentID)
-	log.Printf("UpdateBoard: agentID: %s, err: %v", req.AgentID, err)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid agent ID"})
+		RespondBindError(c, err)
 		return
 	}
 
@@ -156,16 +181,16 @@ func (h *BoardHandler) UpdateBoard(c *gin.Context) {
 	board, err := h.boardService.GetBoardByID(c.Request.Context(), boardID)
 	log.Printf("UpdateBoard: existing board: %+v, err: %v", board, err)
 	if err != nil {
-		if err == services.ErrBoardNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
+		return
+	}
+
+	// Only the board's owner may update it; ownership changes go through TransferBoard
+	if _, ok := authorizeBoardOwner(c, h.agentService, board, req.AgentID); !ok {
 		return
 	}
 
 	// Update board
-	board.AgentID = agentID
 	board.Title = req.Title
 	board.Description = req.Description
 	board.IsActive = req.IsActive
@@ -173,7 +198,7 @@ func (h *BoardHandler) UpdateBoard(c *gin.Context) {
 	err = h.boardService.UpdateBoard(c.Request.Context(), board)
 	log.Printf("UpdateBoard: updated board: %+v, err: %v", board, err)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -187,7 +212,7 @@ func (h *BoardHandler) DeleteBoard(c *gin.Context) {
 	boardID, err := uuid.Parse(c.Param("id"))
 	log.Printf("DeleteBoard: boardID param: %s, err: %v", c.Param("id"), err)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid board ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid board ID")
 		return
 	}
 
@@ -195,11 +220,7 @@ func (h *BoardHandler) DeleteBoard(c *gin.Context) {
 	err = h.boardService.DeleteBoard(c.Request.Context(), boardID)
 	log.Printf("DeleteBoard: deleted board: %v, err: %v", boardID, err)
 	if err != nil {
-		if err == services.ErrBoardNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -210,32 +231,17 @@ func (h *BoardHandler) DeleteBoard(c *gin.Context) {
 func (h *BoardHandler) ListBoards(c *gin.Context) {
 	log.Printf("ListBoards: called for %s", c.Request.URL.Path)
 	// Parse pagination parameters
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-	log.Printf("ListBoards: page param: %s, err: %v", c.DefaultQuery("page", "1"), err)
-	if err != nil || page < 1 {
-		page = 1
-	}
-
-	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-	log.Printf("ListBoards: page_size param: %s, err: %v", c.DefaultQuery("page_size", "10"), err)
-	if err != nil || pageSize < 1 {
-		pageSize = 10
-	}
+	page, pageSize := parsePagination(c, 10, maxPageSize)
 
 	// Get boards
 	boards, totalCount, err := h.boardService.ListBoards(c.Request.Context(), page, pageSize)
 	log.Printf("ListBoards: boards: %+v, totalCount: %d, err: %v", boards, totalCount, err)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"boards":      boards,
-		"total_count": totalCount,
-		"page":        page,
-		"page_size":   pageSize,
-	})
+	c.JSON(http.StatusOK, BuildPaginationResponse("boards", boards, totalCount, page, pageSize))
 }
 
 // SetBoardActive sets the active status of a board
@@ -245,7 +251,7 @@ func (h *BoardHandler) SetBoardActive(c *gin.Context) {
 	boardID, err := uuid.Parse(c.Param("id"))
 	log.Printf("SetBoardActive: boardID param: %s, err: %v", c.Param("id"), err)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid board ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid board ID")
 		return
 	}
 
@@ -253,7 +259,7 @@ func (h *BoardHandler) SetBoardActive(c *gin.Context) {
 	var requestMap map[string]interface{}
 	if err := c.ShouldBindJSON(&requestMap); err != nil {
 		log.Printf("SetBoardActive: failed to bind JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondBindError(c, err)
 		return
 	}
 
@@ -261,7 +267,7 @@ func (h *BoardHandler) SetBoardActive(c *gin.Context) {
 	isActiveValue, exists := requestMap["is_active"]
 	log.Printf("SetBoardActive: isActiveValue: %+v, exists: %v", isActiveValue, exists)
 	if !exists {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "is_active field is required"})
+		RespondErrorStatus(c, http.StatusBadRequest, "is_active field is required")
 		return
 	}
 
@@ -269,7 +275,7 @@ func (h *BoardHandler) SetBoardActive(c *gin.Context) {
 	isActive, ok := isActiveValue.(bool)
 	log.Printf("SetBoardActive: isActive: %v, ok: %v", isActive, ok)
 	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "is_active must be a boolean"})
+		RespondErrorStatus(c, http.StatusBadRequest, "is_active must be a boolean")
 		return
 	}
 
@@ -277,17 +283,301 @@ func (h *BoardHandler) SetBoardActive(c *gin.Context) {
 	err = h.boardService.SetBoardActive(c.Request.Context(), boardID, isActive)
 	log.Printf("SetBoardActive: set active status: %v, err: %v", isActive, err)
 	if err != nil {
-		if err == services.ErrBoardNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "board active status updated"})
 }
 
+// TransferBoard hands a board over to another agent. Agents authenticated by API key
+// transfer their own board; users name the agent they own in agent_id, or leave it out
+// to transfer a board they own themselves.
+func (h *BoardHandler) TransferBoard(c *gin.Context) {
+	// Parse board ID
+	boardID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid board ID")
+		return
+	}
+
+	// Parse request
+	var req struct {
+		AgentID       string `json:"agent_id"`
+		NewOwnerAgent string `json:"new_owner_agent_id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondBindError(c, err)
+		return
+	}
+
+	newOwnerID, err := uuid.Parse(req.NewOwnerAgent)
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid new owner agent ID")
+		return
+	}
+
+	// Work out who the caller is acting as
+	owner, ok := boardActor(c, h.agentService, req.AgentID)
+	if !ok {
+		return
+	}
+
+	// Transfer the board
+	board, err := h.boardService.TransferOwnership(c.Request.Context(), boardID, owner, newOwnerID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// contextUser returns the user the auth middleware identified, if the caller is a user
+// rather than an agent
+func contextUser(c *gin.Context) (*models.User, bool) {
+	userObj, exists := c.Get("user")
+	if !exists {
+		return nil, false
+	}
+	user, ok := userObj.(*models.User)
+	return user, ok
+}
+
+// authorizeBoardOwner checks that the caller owns board, returning who they act as.
+// User-owned boards may be managed by their user or an admin; agent-owned boards by the
+// agent the caller acts as (see boardActor). If it returns false, an error response has
+// already been written.
+func authorizeBoardOwner(c *gin.Context, agentService services.AgentService, board *models.Board, agentIDStr string) (services.BoardActor, bool) {
+	actor, ok := boardActor(c, agentService, agentIDStr)
+	if !ok {
+		return actor, false
+	}
+	if !actor.Owns(board) {
+		RespondError(c, services.ErrNotBoardOwner)
+		return actor, false
+	}
+	return actor, true
+}
+
+// boardActor works out who the caller manages boards as. Agents authenticated by API key
+// act as themselves. Users act as themselves, for boards they own directly, and as the
+// agent they name in agentIDStr, if any (see actingAgentID). If it returns false, an error
+// response has already been written.
+func boardActor(c *gin.Context, agentService services.AgentService, agentIDStr string) (services.BoardActor, bool) {
+	user, isUser := contextUser(c)
+	if !isUser || agentIDStr != "" {
+		agentID, ok := actingAgentID(c, agentService, agentIDStr)
+		if !ok {
+			return services.BoardActor{}, false
+		}
+		return services.BoardActor{AgentID: agentID, User: user}, true
+	}
+	return services.BoardActor{User: user}, true
+}
+
+// actingAgentID works out which agent the caller is acting as. Agents authenticated by
+// API key act as themselves, and naming any other agent in agentIDStr is forbidden; users
+// name an agent they own (or any agent, for admins). If it returns false, an error response
+// has already been written.
+func actingAgentID(c *gin.Context, agentService services.AgentService, agentIDStr string) (uuid.UUID, bool) {
+	if agentObj, exists := c.Get("agent"); exists {
+		agent, ok := agentObj.(*models.Agent)
+		if !ok {
+			RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+			return uuid.Nil, false
+		}
+		if agentIDStr != "" {
+			agentID, err := uuid.Parse(agentIDStr)
+			if err != nil {
+				RespondErrorStatus(c, http.StatusBadRequest, "invalid agent ID")
+				return uuid.Nil, false
+			}
+			if agentID != agent.ID {
+				RespondErrorStatus(c, http.StatusForbidden, "You do not have permission to act as this agent")
+				return uuid.Nil, false
+			}
+		}
+		return agent.ID, true
+	}
+
+	userObj, exists := c.Get("user")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
+		return uuid.Nil, false
+	}
+	user, ok := userObj.(*models.User)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
+		return uuid.Nil, false
+	}
+
+	agentID, err := uuid.Parse(agentIDStr)
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid agent ID")
+		return uuid.Nil, false
+	}
+	agent, err := agentService.GetAgentByID(c, agentID)
+	if err != nil {
+		RespondError(c, err)
+		return uuid.Nil, false
+	}
+	if agent.UserID != user.ID && !user.IsAdmin {
+		RespondErrorStatus(c, http.StatusForbidden, "You do not have permission to act as this agent")
+		return uuid.Nil, false
+	}
+	return agent.ID, true
+}
+
+// SetPostPolicy changes who may post to a board. Only the board's owner can change it;
+// users name the agent they own in agent_id, or leave it out for boards they own.
+func (h *BoardHandler) SetPostPolicy(c *gin.Context) {
+	// Parse board ID
+	boardID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid board ID")
+		return
+	}
+
+	// Parse request
+	var req struct {
+		AgentID    string `json:"agent_id"`
+		PostPolicy string `json:"post_policy" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondBindError(c, err)
+		return
+	}
+
+	owner, ok := boardActor(c, h.agentService, req.AgentID)
+	if !ok {
+		return
+	}
+
+	board, err := h.boardService.SetPostPolicy(c.Request.Context(), boardID, owner, req.PostPolicy)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// SetVisibility changes who may read a board, its posts and their replies. Only the
+// board's owner can change it; users name the agent they own in agent_id, or leave it out
+// for boards they own.
+func (h *BoardHandler) SetVisibility(c *gin.Context) {
+	// Parse board ID
+	boardID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid board ID")
+		return
+	}
+
+	// Parse request
+	var req struct {
+		AgentID    string `json:"agent_id"`
+		Visibility string `json:"visibility" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondBindError(c, err)
+		return
+	}
+
+	owner, ok := boardActor(c, h.agentService, req.AgentID)
+	if !ok {
+		return
+	}
+
+	board, err := h.boardService.SetVisibility(c.Request.Context(), boardID, owner, req.Visibility)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// SetWeightedVoting turns reputation-weighted voting on or off for a board. Only the
+// board's owner can change it; users name the agent they own in agent_id, or leave it out
+// for boards they own.
+func (h *BoardHandler) SetWeightedVoting(c *gin.Context) {
+	// Parse board ID
+	boardID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid board ID")
+		return
+	}
+
+	// Parse request
+	var req struct {
+		AgentID        string `json:"agent_id"`
+		WeightedVoting *bool  `json:"weighted_voting" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondBindError(c, err)
+		return
+	}
+
+	owner, ok := boardActor(c, h.agentService, req.AgentID)
+	if !ok {
+		return
+	}
+
+	board, err := h.boardService.SetWeightedVoting(c.Request.Context(), boardID, owner, *req.WeightedVoting)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// SetArchived archives or unarchives a board. Archived boards stay readable but accept
+// no new posts or replies. Only the board's owner can change this; users name the agent
+// they own in agent_id, or leave it out for boards they own. archived defaults to true.
+func (h *BoardHandler) SetArchived(c *gin.Context) {
+	// Parse board ID
+	boardID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid board ID")
+		return
+	}
+
+	// Parse request
+	var req struct {
+		AgentID  string `json:"agent_id"`
+		Archived *bool  `json:"archived"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondBindError(c, err)
+		return
+	}
+
+	owner, ok := boardActor(c, h.agentService, req.AgentID)
+	if !ok {
+		return
+	}
+
+	var board *models.Board
+	if req.Archived == nil || *req.Archived {
+		board, err = h.boardService.ArchiveBoard(c.Request.Context(), boardID, owner)
+	} else {
+		board, err = h.boardService.UnarchiveBoard(c.Request.Context(), boardID, owner)
+	}
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
 // SearchBoards searches for boards by title or description
 func (h *BoardHandler) SearchBoards(c *gin.Context) {
 	log.Printf("SearchBoards: called for %s", c.Request.URL.Path)
@@ -295,38 +585,81 @@ func (h *BoardHandler) SearchBoards(c *gin.Context) {
 	query := c.Query("q")
 	log.Printf("SearchBoards: query param: %s", query)
 	if query == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "search query is required"})
+		RespondErrorStatus(c, http.StatusBadRequest, "search query is required")
 		return
 	}
 	
 	// Parse pagination parameters
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-	log.Printf("SearchBoards: page param: %s, err: %v", c.DefaultQuery("page", "1"), err)
-	if err != nil || page < 1 {
-		page = 1
-	}
-	
-	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-	log.Printf("SearchBoards: page_size param: %s, err: %v", c.DefaultQuery("page_size", "10"), err)
-	if err != nil || pageSize < 1 {
-		pageSize = 10
-	}
+	page, pageSize := parsePagination(c, 10, maxPageSize)
 	
 	// Search boards
 	boards, totalCount, err := h.boardService.SearchBoards(c.Request.Context(), query, page, pageSize)
 	log.Printf("SearchBoards: boards: %+v, totalCount: %d, err: %v", boards, totalCount, err)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 	
-	c.JSON(http.StatusOK, gin.H{
-		"boards":      boards,
-		"total_count": totalCount,
-		"page":        page,
-		"page_size":   pageSize,
-		"query":       query,
-	})
+	response := BuildPaginationResponse("boards", boards, totalCount, page, pageSize)
+	response["query"] = query
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTrendingBoards lists the boards with the most activity over the last few days
+func (h *BoardHandler) GetTrendingBoards(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(services.DefaultTrendingBoardsLimit)))
+	if err != nil || limit < 1 {
+		limit = services.DefaultTrendingBoardsLimit
+	}
+
+	boards, err := h.boardService.GetTrendingBoards(c.Request.Context(), limit, isAuthenticated(c))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	setReadCacheControl(c, 60)
+	c.JSON(http.StatusOK, gin.H{"boards": boards})
+}
+
+// GetBoardActivity returns a board's post, reply and vote counts over time. The bucket query
+// parameter is "hour" or "day" (the default); since is an optional RFC 3339 timestamp.
+func (h *BoardHandler) GetBoardActivity(c *gin.Context) {
+	boardID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid board ID")
+		return
+	}
+
+	var since time.Time
+	if value := c.Query("since"); value != "" {
+		since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			RespondErrorStatus(c, http.StatusBadRequest, "Invalid since, expected an RFC 3339 timestamp")
+			return
+		}
+	}
+
+	board, err := h.boardService.GetBoardByID(c.Request.Context(), boardID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	// Boards whose visibility requires authentication can't be read anonymously
+	if !board.ReadableBy(isAuthenticated(c)) {
+		RespondError(c, services.ErrBoardRequiresAuth)
+		return
+	}
+
+	bucket := c.DefaultQuery("bucket", models.ActivityBucketDay)
+	activity, err := h.boardService.GetActivityTimeline(c.Request.Context(), boardID, since, bucket)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bucket": bucket, "activity": activity})
 }
 
 // RegisterRoutes registers the board routes
@@ -336,16 +669,24 @@ func (h *BoardHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gi
 	// Public endpoints (no auth required)
 	boards.GET("", h.ListBoards)
 	boards.GET("/search", h.SearchBoards)
-	boards.GET("/:id", h.GetBoard)
-	boards.GET("/agent/:agent_id", h.GetBoardByAgent)
+	boards.GET("/trending", optionalAuth(authMiddleware), h.GetTrendingBoards)
+	boards.GET("/:id", optionalAuth(authMiddleware), h.GetBoard)
+	boards.GET("/:id/activity", optionalAuth(authMiddleware), h.GetBoardActivity)
+	boards.GET("/agent/:agent_id", optionalAuth(authMiddleware), h.GetBoardByAgent)
 
 	// Authenticated endpoints (require login)
 	boardsAuth := boards.Group("")
 	boardsAuth.Use(authMiddleware)
 	{
 		boardsAuth.POST("", h.CreateBoard)
+		boardsAuth.POST("/with-post", h.CreateBoardWithPost)
 		boardsAuth.PUT("/:id", h.UpdateBoard)
 		boardsAuth.DELETE("/:id", h.DeleteBoard)
 		boardsAuth.PUT("/:id/active", h.SetBoardActive)
+		boardsAuth.PUT("/:id/transfer", h.TransferBoard)
+		boardsAuth.PUT("/:id/policy", h.SetPostPolicy)
+		boardsAuth.PUT("/:id/visibility", h.SetVisibility)
+		boardsAuth.PUT("/:id/archive", h.SetArchived)
+		boardsAuth.PUT("/:id/weighted-voting", h.SetWeightedVoting)
 	}
 }
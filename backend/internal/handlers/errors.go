@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/garrettallen/aiboards/backend/internal/apierror"
+)
+
+// internalErrorMessage is sent in place of an unrecognized error's own message, which may
+// carry database details such as table and constraint names
+const internalErrorMessage = "Internal server error"
+
+// RespondError writes err as an error response, with the status and code registered for
+// its service sentinel error. Unrecognized errors are a 500 INTERNAL_ERROR; they are logged
+// and the client only gets a generic message.
+func RespondError(c *gin.Context, err error) {
+	status, code := apierror.FromError(err)
+	if code == apierror.CodeInternal {
+		log.Printf("Internal error on %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		apierror.JSON(c, status, code, internalErrorMessage)
+		return
+	}
+	apierror.JSON(c, status, code, err.Error())
+}
+
+// RespondErrorStatus writes an error response for a failure that isn't a service error,
+// such as a malformed request. The code is the generic one for the status.
+func RespondErrorStatus(c *gin.Context, status int, message string) {
+	apierror.JSON(c, status, apierror.CodeForStatus(status), message)
+}
+
+// RespondBindError writes the error for a request body that couldn't be bound: 413 if the
+// body ran past the size limit set by the MaxBodySize middleware, otherwise 400
+func RespondBindError(c *gin.Context, err error) {
+	if isBodyTooLarge(err) {
+		RespondErrorStatus(c, http.StatusRequestEntityTooLarge, "Request body too large")
+		return
+	}
+	RespondErrorStatus(c, http.StatusBadRequest, err.Error())
+}
+
+// isBodyTooLarge reports whether err came from reading past the request body size limit
+func isBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
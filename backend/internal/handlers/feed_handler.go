@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/garrettallen/aiboards/backend/internal/models"
+	"github.com/garrettallen/aiboards/backend/internal/services"
+)
+
+const (
+	atomNamespace   = "http://www.w3.org/2005/Atom"
+	atomContentType = "application/atom+xml; charset=utf-8"
+)
+
+// AtomFeed is the root element of an Atom feed
+type AtomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    AtomLink    `xml:"link"`
+	Entries []AtomEntry `xml:"entry"`
+}
+
+// AtomEntry is a single post in an Atom feed
+type AtomEntry struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  AtomAuthor  `xml:"author"`
+	Link    AtomLink    `xml:"link"`
+	Content AtomContent `xml:"content"`
+}
+
+// AtomLink is a link to the HTML or API representation of an element
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+// AtomAuthor is the agent that wrote an entry
+type AtomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// AtomContent is the body of an entry
+type AtomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// FeedHandler serves syndication feeds for boards
+type FeedHandler struct {
+	boardService services.BoardService
+	postService  services.PostService
+	agentService services.AgentService
+}
+
+// NewFeedHandler creates a new FeedHandler
+func NewFeedHandler(boardService services.BoardService, postService services.PostService, agentService services.AgentService) *FeedHandler {
+	return &FeedHandler{
+		boardService: boardService,
+		postService:  postService,
+		agentService: agentService,
+	}
+}
+
+// GetBoardFeed renders the latest posts of a board as an Atom feed. Boards whose visibility
+// requires authentication are reported as not found to anonymous clients, since feed readers
+// rarely send credentials, and their feeds are never stored by shared caches.
+func (h *FeedHandler) GetBoardFeed(c *gin.Context) {
+	// Parse board ID
+	boardID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid board ID")
+		return
+	}
+
+	// Parse limit
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Get board
+	board, err := h.boardService.GetBoardByID(c.Request.Context(), boardID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	if !board.ReadableBy(isAuthenticated(c)) {
+		RespondError(c, services.ErrBoardNotFound)
+		return
+	}
+	if board.Visibility != models.BoardVisibilityPublic {
+		c.Header("Cache-Control", "private")
+	}
+
+	// Get latest posts; deleted posts are excluded by the repository
+	posts, _, err := h.postService.GetPostsByBoardID(c.Request.Context(), boardID, 1, limit)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	baseURL := requestBaseURL(c)
+
+	// An empty board is still a valid feed, updated when the board was
+	updated := board.UpdatedAt
+	entries := make([]AtomEntry, 0, len(posts))
+	agentNames := make(map[uuid.UUID]string)
+	for _, post := range posts {
+		if post.UpdatedAt.After(updated) {
+			updated = post.UpdatedAt
+		}
+
+		name, ok := agentNames[post.AgentID]
+		if !ok {
+			name = "Unknown agent"
+			if agent, err := h.agentService.GetAgentByID(c.Request.Context(), post.AgentID); err == nil {
+				name = agent.Name
+			}
+			agentNames[post.AgentID] = name
+		}
+
+		entries = append(entries, AtomEntry{
+			ID:      "urn:uuid:" + post.ID.String(),
+			Title:   feedEntryTitle(post.Content),
+			Updated: post.UpdatedAt.UTC().Format(time.RFC3339),
+			Author:  AtomAuthor{Name: name},
+			Link:    AtomLink{Href: fmt.Sprintf("%s/api/v1/posts/%s", baseURL, post.ID), Rel: "alternate"},
+			Content: AtomContent{Type: "text", Body: post.Content},
+		})
+	}
+
+	feed := AtomFeed{
+		Xmlns:   atomNamespace,
+		ID:      "urn:uuid:" + board.ID.String(),
+		Title:   board.Title,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Link:    AtomLink{Href: fmt.Sprintf("%s/api/v1/boards/%s/feed.xml", baseURL, board.ID), Rel: "self"},
+		Entries: entries,
+	}
+
+	output, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to render feed")
+		return
+	}
+
+	c.Data(http.StatusOK, atomContentType, append([]byte(xml.Header), output...))
+}
+
+// feedEntryTitle derives an entry title from the first line of a post, since posts have no title
+func feedEntryTitle(content string) string {
+	const maxLen = 80
+
+	title := []rune(content)
+	for i, r := range title {
+		if r == '\n' {
+			title = title[:i]
+			break
+		}
+	}
+	if len(title) > maxLen {
+		return string(title[:maxLen]) + "..."
+	}
+	return string(title)
+}
+
+// requestBaseURL returns the scheme and host the request was made to
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, c.Request.Host)
+}
+
+// RegisterRoutes registers the feed routes
+func (h *FeedHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	// Public endpoints; credentials are only needed for boards that aren't public
+	router.GET("/boards/:id/feed.xml", optionalAuth(authMiddleware), h.GetBoardFeed)
+}
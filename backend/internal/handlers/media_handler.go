@@ -26,41 +26,45 @@ func (h *MediaHandler) UploadFile(c *gin.Context) {
 	// Get agent from context (set by AuthMiddleware)
 	agentObj, exists := c.Get("agent")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Agent not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
 		return
 	}
 
 	agent, ok := agentObj.(*models.Agent)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid agent type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
 		return
 	}
 
 	// Get file from form
 	file, header, err := c.Request.FormFile("file")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		if isBodyTooLarge(err) {
+			RespondBindError(c, err)
+			return
+		}
+		RespondErrorStatus(c, http.StatusBadRequest, "No file uploaded")
 		return
 	}
 	defer file.Close()
 
 	// Validate file size (max 5MB)
 	if header.Size > 5*1024*1024 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File too large (max 5MB)"})
+		RespondErrorStatus(c, http.StatusBadRequest, "File too large (max 5MB)")
 		return
 	}
 
 	// Validate file type
 	contentType := header.Header.Get("Content-Type")
 	if !isAllowedFileType(contentType) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File type not allowed"})
+		RespondErrorStatus(c, http.StatusBadRequest, "File type not allowed")
 		return
 	}
 
 	// Upload file using storage service
 	fileInfo, err := h.storageService.UploadFile(c.Request.Context(), file, header.Filename, contentType, header.Size, agent.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload file: " + err.Error()})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to upload file: "+err.Error())
 		return
 	}
 
@@ -73,21 +77,21 @@ func (h *MediaHandler) DeleteFile(c *gin.Context) {
 	// Get agent from context (set by AuthMiddleware)
 	_, exists := c.Get("agent")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Agent not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
 		return
 	}
 
 	// Get file URL from request
 	fileURL := c.Query("url")
 	if fileURL == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File URL is required"})
+		RespondErrorStatus(c, http.StatusBadRequest, "File URL is required")
 		return
 	}
 
 	// Delete file using storage service
 	err := h.storageService.DeleteFile(c.Request.Context(), fileURL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete file: " + err.Error()})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to delete file: "+err.Error())
 		return
 	}
 
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/garrettallen/aiboards/backend/internal/metrics"
+)
+
+// MetricsHandler serves metrics in the Prometheus text format
+type MetricsHandler struct {
+	registry *metrics.Registry
+	token    string
+}
+
+// NewMetricsHandler creates a new MetricsHandler. When token is set, scrapers must send
+// it as a bearer token.
+func NewMetricsHandler(registry *metrics.Registry, token string) *MetricsHandler {
+	return &MetricsHandler{
+		registry: registry,
+		token:    token,
+	}
+}
+
+// GetMetrics writes all registered metrics
+func (h *MetricsHandler) GetMetrics(c *gin.Context) {
+	if h.token != "" {
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(h.token)) != 1 {
+			RespondErrorStatus(c, http.StatusUnauthorized, "Invalid metrics token")
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := h.registry.WriteText(c.Writer); err != nil {
+		c.Error(err)
+	}
+}
+
+// RegisterRoutes registers the metrics route
+func (h *MetricsHandler) RegisterRoutes(router gin.IRoutes) {
+	router.GET("/metrics", h.GetMetrics)
+}
@@ -3,6 +3,8 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -12,6 +14,10 @@ import (
 	"fmt"
 )
 
+// defaultReadNotificationRetention is how old read notifications must be before
+// DeleteReadNotifications removes them when no older_than is given
+const defaultReadNotificationRetention = 30 * 24 * time.Hour
+
 // NotificationHandler handles notification-related endpoints
 type NotificationHandler struct {
 	notificationService services.NotificationService
@@ -29,38 +35,34 @@ func (h *NotificationHandler) GetNotification(c *gin.Context) {
 	// Get agent from context (set by AuthMiddleware)
 	agentObj, exists := c.Get("agent")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Agent not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
 		return
 	}
 
 	agent, ok := agentObj.(*models.Agent)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid agent type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
 		return
 	}
 
 	// Parse notification ID
 	notificationID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid notification ID")
 		return
 	}
 
 	// Get notification
 	notification, err := h.notificationService.GetNotificationByID(c, notificationID)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == services.ErrNotificationNotFound {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		c.Error(fmt.Errorf("failed to get notification %s: %w", notificationID, err)) // Log the detailed error
 		return
 	}
 
 	// Check if the notification belongs to the agent
 	if notification.AgentID != agent.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own notifications"})
+		RespondErrorStatus(c, http.StatusForbidden, "You can only view your own notifications")
 		return
 	}
 
@@ -82,47 +84,87 @@ func (h *NotificationHandler) GetNotifications(c *gin.Context) {
 	// Get agent from context
 	agentObj, exists := c.Get("agent")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Agent not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
 		return
 	}
 
 	agent, ok := agentObj.(*models.Agent)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid agent type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
 		return
 	}
 
 	// Parse pagination parameters
-	page := 1
-	pageSize := 10
+	page, pageSize := parsePagination(c, 10, maxPageSize)
 
-	pageStr := c.DefaultQuery("page", "1")
-	pageSizeStr := c.DefaultQuery("page_size", "10")
+	// Get notifications
+	notifications, total, err := h.notificationService.GetNotificationsByAgentID(c, agent.ID, page, pageSize)
+	if err != nil {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to retrieve notifications")
+		c.Error(err) // Log the error
+		return
+	}
 
-	var err error
-	if pageStr != "" {
-		page, err = strconv.Atoi(pageStr)
-		if err != nil || page < 1 {
-			page = 1
+	// Prepare response
+	notificationResponses := make([]gin.H, len(notifications))
+	for i, notification := range notifications {
+		notificationResponses[i] = gin.H{
+			"id":          notification.ID,
+			"agent_id":    notification.AgentID,
+			"type":        notification.Type,
+			"content":     notification.Content,
+			"target_type": notification.TargetType,
+			"target_id":   notification.TargetID,
+			"is_read":     notification.IsRead,
+			"created_at":  notification.CreatedAt,
+			"read_at":     notification.ReadAt,
 		}
 	}
 
-	if pageSizeStr != "" {
-		pageSize, err = strconv.Atoi(pageSizeStr)
-		if err != nil || pageSize < 1 || pageSize > 100 {
-			pageSize = 10
+	c.JSON(http.StatusOK, BuildPaginationResponse("notifications", notificationResponses, total, page, pageSize))
+}
+
+// GetNotificationsBatch gets several of the current agent's notifications by ID in one
+// request. IDs that don't exist or belong to another agent are left out of the response.
+func (h *NotificationHandler) GetNotificationsBatch(c *gin.Context) {
+	// Get agent from context
+	agentObj, exists := c.Get("agent")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
+		return
+	}
+
+	agent, ok := agentObj.(*models.Agent)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+		return
+	}
+
+	var req struct {
+		IDs []string `json:"ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondBindError(c, err)
+		return
+	}
+
+	notificationIDs := make([]uuid.UUID, len(req.IDs))
+	for i, idStr := range req.IDs {
+		notificationID, err := uuid.Parse(idStr)
+		if err != nil {
+			RespondErrorStatus(c, http.StatusBadRequest, fmt.Sprintf("Invalid notification ID: %s", idStr))
+			return
 		}
+		notificationIDs[i] = notificationID
 	}
 
-	// Get notifications
-	notifications, total, err := h.notificationService.GetNotificationsByAgentID(c, agent.ID, page, pageSize)
+	notifications, err := h.notificationService.GetByIDs(c, agent.ID, notificationIDs)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve notifications"})
+		RespondError(c, err)
 		c.Error(err) // Log the error
 		return
 	}
 
-	// Prepare response
 	notificationResponses := make([]gin.H, len(notifications))
 	for i, notification := range notifications {
 		notificationResponses[i] = gin.H{
@@ -138,13 +180,7 @@ func (h *NotificationHandler) GetNotifications(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"notifications": notificationResponses,
-		"total":         total,
-		"page":          page,
-		"page_size":     pageSize,
-		"total_pages":   (total + pageSize - 1) / pageSize,
-	})
+	c.JSON(http.StatusOK, gin.H{"notifications": notificationResponses})
 }
 
 // MarkAsRead marks a notification as read
@@ -152,44 +188,40 @@ func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
 	// Get agent from context
 	agentObj, exists := c.Get("agent")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Agent not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
 		return
 	}
 
 	agent, ok := agentObj.(*models.Agent)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid agent type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
 		return
 	}
 
 	// Parse notification ID
 	notificationID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid notification ID")
 		return
 	}
 
 	// Get notification to check ownership
 	notification, err := h.notificationService.GetNotificationByID(c, notificationID)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == services.ErrNotificationNotFound {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		c.Error(err) // Log the error
 		return
 	}
 
 	// Check if the notification belongs to the agent
 	if notification.AgentID != agent.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only mark your own notifications as read"})
+		RespondErrorStatus(c, http.StatusForbidden, "You can only mark your own notifications as read")
 		return
 	}
 
 	// Mark as read
 	if err := h.notificationService.MarkAsRead(c, notificationID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notification as read"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to mark notification as read")
 		c.Error(err) // Log the error
 		return
 	}
@@ -197,24 +229,70 @@ func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
 }
 
+// MarkAsUnread marks a notification as unread
+func (h *NotificationHandler) MarkAsUnread(c *gin.Context) {
+	// Get agent from context
+	agentObj, exists := c.Get("agent")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
+		return
+	}
+
+	agent, ok := agentObj.(*models.Agent)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+		return
+	}
+
+	// Parse notification ID
+	notificationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid notification ID")
+		return
+	}
+
+	// Get notification to check ownership
+	notification, err := h.notificationService.GetNotificationByID(c, notificationID)
+	if err != nil {
+		RespondError(c, err)
+		c.Error(err) // Log the error
+		return
+	}
+
+	// Check if the notification belongs to the agent
+	if notification.AgentID != agent.ID {
+		RespondErrorStatus(c, http.StatusForbidden, "You can only mark your own notifications as unread")
+		return
+	}
+
+	// Mark as unread
+	if err := h.notificationService.MarkAsUnread(c, notificationID); err != nil {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to mark notification as unread")
+		c.Error(err) // Log the error
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as unread"})
+}
+
 // MarkAllAsRead marks all notifications for the current agent as read
 func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
 	// Get agent from context
 	agentObj, exists := c.Get("agent")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Agent not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
 		return
 	}
 
 	agent, ok := agentObj.(*models.Agent)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid agent type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
 		return
 	}
 
 	// Mark all as read
 	if err := h.notificationService.MarkAllAsRead(c, agent.ID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark all notifications as read"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to mark all notifications as read")
 		c.Error(err) // Log the error
 		return
 	}
@@ -227,44 +305,40 @@ func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
 	// Get agent from context
 	agentObj, exists := c.Get("agent")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Agent not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
 		return
 	}
 
 	agent, ok := agentObj.(*models.Agent)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid agent type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
 		return
 	}
 
 	// Parse notification ID
 	notificationID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid notification ID")
 		return
 	}
 
 	// Get notification to check ownership
 	notification, err := h.notificationService.GetNotificationByID(c, notificationID)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == services.ErrNotificationNotFound {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		c.Error(err) // Log the error
 		return
 	}
 
 	// Check if the notification belongs to the agent
 	if notification.AgentID != agent.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own notifications"})
+		RespondErrorStatus(c, http.StatusForbidden, "You can only delete your own notifications")
 		return
 	}
 
 	// Delete notification
 	if err := h.notificationService.DeleteNotification(c, notificationID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notification"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to delete notification")
 		c.Error(err) // Log the error
 		return
 	}
@@ -272,25 +346,74 @@ func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Notification deleted successfully"})
 }
 
+// DeleteReadNotifications deletes the current agent's read notifications older than
+// ?older_than=<age>, given in days such as 30d or as a duration such as 72h. It defaults
+// to 30 days. Unread notifications are kept.
+func (h *NotificationHandler) DeleteReadNotifications(c *gin.Context) {
+	// Get agent from context
+	agentObj, exists := c.Get("agent")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
+		return
+	}
+
+	agent, ok := agentObj.(*models.Agent)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+		return
+	}
+
+	olderThan := defaultReadNotificationRetention
+	if olderThanStr := c.Query("older_than"); olderThanStr != "" {
+		parsed, err := parseAge(olderThanStr)
+		if err != nil {
+			RespondErrorStatus(c, http.StatusBadRequest, "older_than must be a number of days such as 30d or a duration such as 72h")
+			return
+		}
+		olderThan = parsed
+	}
+
+	deleted, err := h.notificationService.DeleteReadNotifications(c, agent.ID, olderThan)
+	if err != nil {
+		RespondError(c, err)
+		c.Error(err) // Log the error
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deleted": deleted})
+}
+
+// parseAge parses an age given as a whole number of days, such as 30d, or as a Go duration
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
 // GetUnreadCount gets the number of unread notifications for the current agent
 func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
 	// Get agent from context
 	agentObj, exists := c.Get("agent")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Agent not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
 		return
 	}
 
 	agent, ok := agentObj.(*models.Agent)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid agent type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
 		return
 	}
 
 	// Get unread count
 	count, err := h.notificationService.CountUnread(c, agent.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count unread notifications"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to count unread notifications")
 		c.Error(err) // Log the error
 		return
 	}
@@ -298,6 +421,100 @@ func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"count": count})
 }
 
+// GetUnreadCountByType gets the number of unread notifications per type for the current agent
+func (h *NotificationHandler) GetUnreadCountByType(c *gin.Context) {
+	// Get agent from context
+	agentObj, exists := c.Get("agent")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
+		return
+	}
+
+	agent, ok := agentObj.(*models.Agent)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+		return
+	}
+
+	counts, err := h.notificationService.CountUnreadByType(c, agent.ID)
+	if err != nil {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to count unread notifications")
+		c.Error(err) // Log the error
+		return
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	c.JSON(http.StatusOK, gin.H{"counts": counts, "total": total})
+}
+
+// GetPreferences gets the notification preferences of the current agent
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	// Get agent from context
+	agentObj, exists := c.Get("agent")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
+		return
+	}
+
+	agent, ok := agentObj.(*models.Agent)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+		return
+	}
+
+	preference, err := h.notificationService.GetPreferences(c, agent.ID)
+	if err != nil {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to get notification preferences")
+		c.Error(err) // Log the error
+		return
+	}
+
+	c.JSON(http.StatusOK, preference)
+}
+
+// UpdatePreferencesRequest represents the request body for updating notification preferences
+type UpdatePreferencesRequest struct {
+	EmailEnabled         *bool   `json:"email_enabled"`
+	DigestFrequency      *string `json:"digest_frequency"`
+	ThreadRepliesEnabled *bool   `json:"thread_replies_enabled"`
+}
+
+// UpdatePreferences updates the notification preferences of the current agent
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	// Get agent from context
+	agentObj, exists := c.Get("agent")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
+		return
+	}
+
+	agent, ok := agentObj.(*models.Agent)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+		return
+	}
+
+	// Parse request body
+	var req UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondBindError(c, err)
+		return
+	}
+
+	preference, err := h.notificationService.UpdatePreferences(c, agent.ID, req.EmailEnabled, req.DigestFrequency, req.ThreadRepliesEnabled)
+	if err != nil {
+		RespondError(c, err)
+		c.Error(err) // Log the error
+		return
+	}
+
+	c.JSON(http.StatusOK, preference)
+}
+
 // RegisterRoutes registers the notification routes
 func (h *NotificationHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
 	notifications := router.Group("/notifications")
@@ -305,9 +522,15 @@ func (h *NotificationHandler) RegisterRoutes(router *gin.RouterGroup, authMiddle
 	{
 		notifications.GET("", h.GetNotifications)
 		notifications.GET("/unread", h.GetUnreadCount)
+		notifications.GET("/unread/by-type", h.GetUnreadCountByType)
+		notifications.GET("/preferences", h.GetPreferences)
+		notifications.POST("/batch-get", h.GetNotificationsBatch)
+		notifications.PUT("/preferences", h.UpdatePreferences)
 		notifications.GET("/:id", h.GetNotification)
 		notifications.PUT("/:id/read", h.MarkAsRead)
+		notifications.PUT("/:id/unread", h.MarkAsUnread)
 		notifications.PUT("/read-all", h.MarkAllAsRead)
+		notifications.DELETE("/read", h.DeleteReadNotifications)
 		notifications.DELETE("/:id", h.DeleteNotification)
 	}
 }
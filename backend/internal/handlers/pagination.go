@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMaxPageSize is the largest page_size list endpoints accept unless configured otherwise
+const DefaultMaxPageSize = 100
+
+// maxPageSize caps page_size on list endpoints; see SetMaxPageSize
+var maxPageSize = DefaultMaxPageSize
+
+// SetMaxPageSize sets the largest page_size list endpoints accept. It is meant to be
+// called once at startup, before the router serves requests; non-positive sizes are ignored.
+func SetMaxPageSize(size int) {
+	if size > 0 {
+		maxPageSize = size
+	}
+}
+
+// Pagination represents the pagination metadata returned by list endpoints
+type Pagination struct {
+	Total      int `json:"total"`
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	TotalPages int `json:"total_pages"`
+}
+
+// NewPagination creates pagination metadata for the given total, page and page size
+func NewPagination(total, page, pageSize int) Pagination {
+	totalPages := 0
+	if pageSize > 0 {
+		totalPages = (total + pageSize - 1) / pageSize
+	}
+
+	return Pagination{
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}
+}
+
+// BuildPaginationResponse builds a list response with the items under key and
+// the pagination fields at the top level. The total_count and nested pagination
+// keys are kept as aliases for clients written against the older responses.
+func BuildPaginationResponse(key string, items interface{}, total, page, pageSize int) gin.H {
+	pagination := NewPagination(total, page, pageSize)
+
+	return gin.H{
+		key:           items,
+		"total":       pagination.Total,
+		"page":        pagination.Page,
+		"page_size":   pagination.PageSize,
+		"total_pages": pagination.TotalPages,
+		"total_count": pagination.Total,
+		"pagination":  pagination,
+	}
+}
+
+// parsePagination reads the page and page_size query parameters. Missing or invalid
+// values fall back to page 1 and defaultSize, and page_size is clamped to maxSize.
+func parsePagination(c *gin.Context, defaultSize, maxSize int) (page, pageSize int) {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err = strconv.Atoi(c.Query("page_size"))
+	if err != nil || pageSize < 1 {
+		pageSize = defaultSize
+	}
+	if pageSize > maxSize {
+		pageSize = maxSize
+	}
+
+	return page, pageSize
+}
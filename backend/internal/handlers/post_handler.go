@@ -1,135 +1,313 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/garrettallen/aiboards/backend/internal/middleware"
+	"github.com/garrettallen/aiboards/backend/internal/models"
 	"github.com/garrettallen/aiboards/backend/internal/services"
 )
 
 // PostHandler handles HTTP requests related to posts
 type PostHandler struct {
-	postService services.PostService
+	postService  services.PostService
+	agentService services.AgentService
 }
 
 // NewPostHandler creates a new PostHandler
-func NewPostHandler(postService services.PostService) *PostHandler {
+func NewPostHandler(postService services.PostService, agentService services.AgentService) *PostHandler {
 	return &PostHandler{
-		postService: postService,
+		postService:  postService,
+		agentService: agentService,
 	}
 }
 
-// CreatePost creates a new post
+// CreatePost creates a new post as the agent the caller acts as (see actingAgentID). Agents
+// may only post as themselves; users name an agent they own in agent_id.
 func (h *PostHandler) CreatePost(c *gin.Context) {
 	// Parse request
 	var req struct {
-		BoardID  string `json:"board_id" binding:"required"`
-		AgentID  string `json:"agent_id" binding:"required"`
-		Content  string `json:"content" binding:"required"`
-		MediaURL string `json:"media_url"`
+		BoardID     string   `json:"board_id" binding:"required"`
+		AgentID     string   `json:"agent_id"`
+		Content     string   `json:"content" binding:"required"`
+		MediaURL    string   `json:"media_url"`
+		Attachments []string `json:"attachments"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondBindError(c, err)
 		return
 	}
 
 	// Parse UUIDs
 	boardID, err := uuid.Parse(req.BoardID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid board ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid board ID")
 		return
 	}
 
-	agentID, err := uuid.Parse(req.AgentID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid agent ID"})
+	agentID, ok := actingAgentID(c, h.agentService, req.AgentID)
+	if !ok {
 		return
 	}
 
+	// media_url is shorthand for the first attachment
+	attachmentURLs := req.Attachments
+	if req.MediaURL != "" && (len(attachmentURLs) == 0 || attachmentURLs[0] != req.MediaURL) {
+		attachmentURLs = append([]string{req.MediaURL}, attachmentURLs...)
+	}
+
 	// Create post
-	post, err := h.postService.CreatePost(c.Request.Context(), boardID, agentID, req.Content, req.MediaURL)
-	if err != nil {
-		switch err {
-		case services.ErrBoardNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
-		case services.ErrAgentNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "agent not found"})
-		case services.ErrBoardInactive:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "board is inactive"})
-		case services.ErrAgentRateLimited:
-			c.JSON(http.StatusTooManyRequests, gin.H{"error": "agent is rate limited"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+	post, err := h.postService.CreatePostWithAttachments(c.Request.Context(), boardID, agentID, req.Content, attachmentURLs)
+	if err != nil {
+		RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, post)
 }
 
+// AddAttachment adds an attachment to a post, as the agent the caller acts as (see
+// actingAgentID). Only the post's author may add attachments.
+func (h *PostHandler) AddAttachment(c *gin.Context) {
+	// Parse post ID
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid post ID")
+		return
+	}
+
+	// Parse request
+	var req struct {
+		AgentID string `json:"agent_id"`
+		URL     string `json:"url" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondBindError(c, err)
+		return
+	}
+
+	agentID, ok := actingAgentID(c, h.agentService, req.AgentID)
+	if !ok {
+		return
+	}
+
+	// Add attachment
+	attachment, err := h.postService.AddAttachment(c.Request.Context(), postID, agentID, req.URL)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// ListAttachments lists a post's attachments in order
+func (h *PostHandler) ListAttachments(c *gin.Context) {
+	// Parse post ID
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid post ID")
+		return
+	}
+
+	// Get attachments
+	attachments, err := h.postService.ListAttachments(c.Request.Context(), postID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attachments": attachments})
+}
+
 // GetPost gets a post by ID
 func (h *PostHandler) GetPost(c *gin.Context) {
 	// Parse post ID
 	postID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid post ID")
 		return
 	}
 
 	// Get post
 	post, err := h.postService.GetPostByID(c.Request.Context(), postID)
 	if err != nil {
-		if err == services.ErrPostNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, post)
 }
 
-// ListBoardPosts lists posts for a board
+// GetPostBoard gets the board a post belongs to
+func (h *PostHandler) GetPostBoard(c *gin.Context) {
+	// Parse post ID
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid post ID")
+		return
+	}
+
+	board, err := h.postService.GetBoardForPost(c.Request.Context(), postID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// postWithAuthor is a post with its author's public info embedded, as returned by v2 of
+// the full post endpoint
+type postWithAuthor struct {
+	*models.Post
+	Author gin.H `json:"author"`
+}
+
+// GetPostFull gets a post together with its author's public info and the first page of
+// its top-level replies, so a post view needs a single request
+func (h *PostHandler) GetPostFull(c *gin.Context) {
+	// Parse post ID
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid post ID")
+		return
+	}
+
+	_, pageSize := parsePagination(c, 10, maxPageSize)
+
+	sort := c.DefaultQuery("sort", models.ReplySortOld)
+	full, err := h.postService.GetPostWithReplies(c.Request.Context(), postID, sort, pageSize)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	var author gin.H
+	if full.Author != nil {
+		author = gin.H{
+			"id":                  full.Author.ID,
+			"name":                full.Author.Name,
+			"description":         full.Author.Description,
+			"profile_picture_url": full.Author.ProfilePictureURL,
+		}
+	}
+
+	// v2 embeds the author in the post and drops the legacy top-level pagination fields
+	if middleware.RequestedAPIVersion(c) >= middleware.APIVersion2 {
+		c.JSON(http.StatusOK, gin.H{
+			"post": postWithAuthor{
+				Post:   full.Post,
+				Author: author,
+			},
+			"replies":    full.Replies,
+			"pagination": NewPagination(full.TotalReplies, 1, pageSize),
+		})
+		return
+	}
+
+	response := BuildPaginationResponse("replies", full.Replies, full.TotalReplies, 1, pageSize)
+	response["post"] = full.Post
+	response["author"] = author
+	c.JSON(http.StatusOK, response)
+}
+
+// ListBoardPosts lists posts for a board. Passing a cursor parameter (empty for the first
+// page) switches from page/offset pagination to keyset pagination, which returns next_cursor
+// instead of page counts.
 func (h *PostHandler) ListBoardPosts(c *gin.Context) {
 	// Parse board ID
 	boardID, err := uuid.Parse(c.Param("board_id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid board ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid board ID")
 		return
 	}
 
 	// Parse pagination parameters
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-	if err != nil || page < 1 {
-		page = 1
-	}
+	page, pageSize := parsePagination(c, 10, maxPageSize)
+
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		posts, nextCursor, err := h.postService.GetPostsByBoardIDCursor(c.Request.Context(), boardID, cursor, pageSize)
+		if err != nil {
+			RespondError(c, err)
+			return
+		}
 
-	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-	if err != nil || pageSize < 1 {
-		pageSize = 10
+		posts, ok := h.hideBlockedPosts(c, posts)
+		if !ok {
+			return
+		}
+
+		response := gin.H{
+			"posts":       posts,
+			"page_size":   pageSize,
+			"next_cursor": nil,
+		}
+		if nextCursor != "" {
+			response["next_cursor"] = nextCursor
+		}
+		c.JSON(http.StatusOK, response)
+		return
 	}
 
 	// Get posts
 	posts, totalCount, err := h.postService.GetPostsByBoardID(c.Request.Context(), boardID, page, pageSize)
 	if err != nil {
-		if err == services.ErrBoardNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
+		return
+	}
+
+	posts, ok := h.hideBlockedPosts(c, posts)
+	if !ok {
 		return
 	}
 
+	c.JSON(http.StatusOK, BuildPaginationResponse("posts", posts, totalCount, page, pageSize))
+}
+
+// ListTopBoardPosts lists a board's highest-voted posts created within a period
+// (?period=day|week|month, default week). The result only changes as votes come in,
+// so it is marked cacheable for a short time.
+func (h *PostHandler) ListTopBoardPosts(c *gin.Context) {
+	// Parse board ID
+	boardID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid board ID")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(services.DefaultTopPostsLimit)))
+	if err != nil || limit < 1 {
+		limit = services.DefaultTopPostsLimit
+	}
+
+	period := c.DefaultQuery("period", models.TopPostsPeriodWeek)
+	posts, err := h.postService.GetTopPosts(c.Request.Context(), boardID, period, limit)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	// A signed-in agent's list leaves out agents it has blocked
+	if _, viewing := viewingAgent(c); viewing {
+		var ok bool
+		if posts, ok = h.hideBlockedPosts(c, posts); !ok {
+			return
+		}
+	}
+	setReadCacheControl(c, 60)
 	c.JSON(http.StatusOK, gin.H{
-		"posts":       posts,
-		"total_count": totalCount,
-		"page":        page,
-		"page_size":   pageSize,
+		"posts":  posts,
+		"period": period,
 	})
 }
 
@@ -138,38 +316,40 @@ func (h *PostHandler) ListAgentPosts(c *gin.Context) {
 	// Parse agent ID
 	agentID, err := uuid.Parse(c.Param("agent_id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid agent ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid agent ID")
 		return
 	}
 
 	// Parse pagination parameters
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-	if err != nil || page < 1 {
-		page = 1
+	page, pageSize := parsePagination(c, 10, maxPageSize)
+
+	// Get posts
+	posts, totalCount, err := h.postService.GetPostsByAgentID(c.Request.Context(), agentID, page, pageSize, isAuthenticated(c))
+	if err != nil {
+		RespondError(c, err)
+		return
 	}
 
-	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-	if err != nil || pageSize < 1 {
-		pageSize = 10
+	c.JSON(http.StatusOK, BuildPaginationResponse("posts", posts, totalCount, page, pageSize))
+}
+
+// ListAgentBoards lists the boards an agent has posted to, with post counts
+func (h *PostHandler) ListAgentBoards(c *gin.Context) {
+	// Parse agent ID
+	agentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid agent ID")
+		return
 	}
 
-	// Get posts
-	posts, totalCount, err := h.postService.GetPostsByAgentID(c.Request.Context(), agentID, page, pageSize)
+	// Get boards
+	boards, err := h.postService.GetBoardsForAgent(c.Request.Context(), agentID, isAuthenticated(c))
 	if err != nil {
-		if err == services.ErrAgentNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "agent not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"posts":       posts,
-		"total_count": totalCount,
-		"page":        page,
-		"page_size":   pageSize,
-	})
+	c.JSON(http.StatusOK, gin.H{"boards": boards})
 }
 
 // UpdatePost updates a post
@@ -177,18 +357,14 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 	// Parse post ID
 	postID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid post ID")
 		return
 	}
 
 	// Get existing post
 	post, err := h.postService.GetPostByID(c.Request.Context(), postID)
 	if err != nil {
-		if err == services.ErrPostNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -199,7 +375,7 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondBindError(c, err)
 		return
 	}
 
@@ -213,7 +389,7 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 
 	err = h.postService.UpdatePost(c.Request.Context(), post)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -225,22 +401,136 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 	// Parse post ID
 	postID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid post ID")
 		return
 	}
 
 	// Delete post
 	err = h.postService.DeletePost(c.Request.Context(), postID)
 	if err != nil {
-		if err == services.ErrPostNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "post deleted"})
+}
+
+// RemoveBoardPost removes a post from a board on behalf of the board's owner, so owners can
+// moderate their boards without admin rights. Agents act as themselves; users name the owner
+// agent they act as in the agent_id query parameter, or leave it out for boards they own.
+func (h *PostHandler) RemoveBoardPost(c *gin.Context) {
+	// Parse board and post IDs
+	boardID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid board ID")
+		return
+	}
+	postID, err := uuid.Parse(c.Param("postID"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid post ID")
+		return
+	}
+
+	owner, ok := boardActor(c, h.agentService, c.Query("agent_id"))
+	if !ok {
+		return
+	}
+
+	// The post has to be on the board named in the path
+	post, err := h.postService.GetPostByID(c.Request.Context(), postID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+	if post.BoardID != boardID {
+		RespondError(c, services.ErrPostNotFound)
+		return
+	}
+
+	err = h.postService.RemovePostFromBoard(c.Request.Context(), postID, owner)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "post removed"})
+}
+
+// SetLocked locks or unlocks a post so it does or doesn't accept new replies. Only the
+// owner of the post's board, an admin or an admin's agent can lock it; users name the agent
+// they act as in agent_id, or leave it out for boards they own. locked defaults to true when
+// the body leaves it out or is empty.
+func (h *PostHandler) SetLocked(c *gin.Context) {
+	// Parse post ID
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid post ID")
+		return
+	}
+
+	var req struct {
+		AgentID string `json:"agent_id"`
+		Locked  *bool  `json:"locked"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		RespondBindError(c, err)
+		return
+	}
+	locked := req.Locked == nil || *req.Locked
+
+	caller, ok := boardActor(c, h.agentService, req.AgentID)
+	if !ok {
+		return
+	}
+
+	post, err := h.postService.SetLocked(c.Request.Context(), postID, caller, locked)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, post)
+}
+
+// DeletePosts soft-deletes several of the authenticated agent's own posts at once.
+// IDs of posts the agent didn't write are skipped.
+func (h *PostHandler) DeletePosts(c *gin.Context) {
+	agentObj, exists := c.Get("agent")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
+		return
+	}
+	agent, ok := agentObj.(*models.Agent)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+		return
+	}
+
+	var req struct {
+		IDs []string `json:"ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondBindError(c, err)
+		return
+	}
+
+	postIDs := make([]uuid.UUID, len(req.IDs))
+	for i, idStr := range req.IDs {
+		postID, err := uuid.Parse(idStr)
+		if err != nil {
+			RespondErrorStatus(c, http.StatusBadRequest, fmt.Sprintf("invalid post ID: %s", idStr))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		postIDs[i] = postID
+	}
+
+	deleted, err := h.postService.DeletePostsByAgent(c.Request.Context(), agent.ID, postIDs)
+	if err != nil {
+		RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "post deleted"})
+	c.JSON(http.StatusOK, gin.H{"deleted": deleted})
 }
 
 // SearchBoardPosts searches for posts by content within a specific board
@@ -248,60 +538,145 @@ func (h *PostHandler) SearchBoardPosts(c *gin.Context) {
 	// Parse board ID
 	boardID, err := uuid.Parse(c.Param("board_id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid board ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid board ID")
 		return
 	}
 	
 	// Get search query
 	query := c.Query("q")
 	if query == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "search query is required"})
+		RespondErrorStatus(c, http.StatusBadRequest, "search query is required")
 		return
 	}
 	
 	// Parse pagination parameters
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-	if err != nil || page < 1 {
-		page = 1
-	}
-	
-	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-	if err != nil || pageSize < 1 {
-		pageSize = 10
-	}
+	page, pageSize := parsePagination(c, 10, maxPageSize)
 	
 	// Search posts
 	posts, totalCount, err := h.postService.SearchPosts(c.Request.Context(), boardID, query, page, pageSize)
 	if err != nil {
-		if err == services.ErrBoardNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "board not found"})
-			return
-		} else if err == services.ErrBoardInactive {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "board is inactive"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
+		return
+	}
+
+	posts, ok := h.hideBlockedPosts(c, posts)
+	if !ok {
 		return
 	}
 	
-	c.JSON(http.StatusOK, gin.H{
-		"posts":       posts,
-		"total_count": totalCount,
-		"page":        page,
-		"page_size":   pageSize,
-		"query":       query,
-	})
+	response := BuildPaginationResponse("posts", posts, totalCount, page, pageSize)
+	response["query"] = query
+	c.JSON(http.StatusOK, response)
+}
+
+// SearchAllPosts searches post content across all boards
+func (h *PostHandler) SearchAllPosts(c *gin.Context) {
+	// Get search query
+	query := c.Query("q")
+	if query == "" {
+		RespondErrorStatus(c, http.StatusBadRequest, "search query is required")
+		return
+	}
+
+	// Parse pagination parameters
+	page, pageSize := parsePagination(c, 10, maxPageSize)
+
+	// Search posts
+	posts, totalCount, err := h.postService.SearchAllPosts(c.Request.Context(), query, page, pageSize, isAuthenticated(c))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	response := BuildPaginationResponse("posts", posts, totalCount, page, pageSize)
+	response["query"] = query
+	c.JSON(http.StatusOK, response)
+}
+
+// ListRecentPosts lists the newest posts across all boards. Posts on boards that require
+// authentication are only listed for authenticated callers, and an agent doesn't see posts
+// by agents it has blocked.
+func (h *PostHandler) ListRecentPosts(c *gin.Context) {
+	// Parse pagination parameters
+	page, pageSize := parsePagination(c, 10, maxPageSize)
+
+	viewerID, _ := viewingAgent(c)
+	authenticated := isAuthenticated(c)
+	posts, totalCount, err := h.postService.GetRecentPosts(c.Request.Context(), page, pageSize, authenticated, viewerID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	setReadCacheControl(c, 30)
+	c.JSON(http.StatusOK, BuildPaginationResponse("posts", posts, totalCount, page, pageSize))
+}
+
+// ListSimilarPosts lists posts from any board whose content is similar to the given post
+func (h *PostHandler) ListSimilarPosts(c *gin.Context) {
+	// Parse post ID
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid post ID")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(services.DefaultSimilarPostsLimit)))
+	if err != nil || limit < 1 {
+		limit = services.DefaultSimilarPostsLimit
+	}
+
+	posts, err := h.postService.GetSimilarPosts(c.Request.Context(), postID, limit, isAuthenticated(c))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"posts": posts})
+}
+
+// hideBlockedPosts removes posts by agents the requesting agent has blocked. It responds
+// with an error and returns false if the block list can't be loaded.
+func (h *PostHandler) hideBlockedPosts(c *gin.Context, posts []*models.Post) ([]*models.Post, bool) {
+	viewerID, ok := viewingAgent(c)
+	if !ok {
+		return posts, true
+	}
+
+	posts, err := h.postService.FilterBlockedPosts(c.Request.Context(), viewerID, posts)
+	if err != nil {
+		RespondError(c, err)
+		return nil, false
+	}
+	return posts, true
 }
 
 // RegisterRoutes registers the post routes
 func (h *PostHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
 	posts := router.Group("/posts")
 
-	// Public endpoints (no auth required)
-	posts.GET("/:id", h.GetPost)
-	posts.GET("/board/:board_id", h.ListBoardPosts)
-	posts.GET("/board/:board_id/search", h.SearchBoardPosts)
-	posts.GET("/agent/:agent_id", h.ListAgentPosts)
+	// Public endpoints (no auth required, except on boards whose visibility requires it)
+	readAuth := optionalAuth(authMiddleware)
+	postRead := requireReadAccess("id", h.postService.CheckPostReadAccess)
+	boardRead := requireReadAccess("board_id", h.postService.CheckBoardReadAccess)
+
+	posts.GET("/search", readAuth, h.SearchAllPosts)
+	posts.GET("/recent", readAuth, h.ListRecentPosts)
+	posts.GET("/:id", readAuth, postRead, h.GetPost)
+	posts.GET("/:id/full", readAuth, postRead, h.GetPostFull)
+	posts.GET("/:id/board", readAuth, postRead, h.GetPostBoard)
+	posts.GET("/:id/attachments", readAuth, postRead, h.ListAttachments)
+	posts.GET("/:id/similar", readAuth, postRead, h.ListSimilarPosts)
+	posts.GET("/board/:board_id", readAuth, boardRead, h.ListBoardPosts)
+	posts.GET("/board/:board_id/search", readAuth, boardRead, h.SearchBoardPosts)
+	posts.GET("/agent/:agent_id", readAuth, h.ListAgentPosts)
+
+	// Board top posts
+	router.GET("/boards/:id/posts/top", readAuth, requireReadAccess("id", h.postService.CheckBoardReadAccess), h.ListTopBoardPosts)
+	router.DELETE("/boards/:id/posts/:postID", authMiddleware, h.RemoveBoardPost)
+
+	// Agent profile endpoints
+	router.GET("/agents/:id/boards", readAuth, h.ListAgentBoards)
 
 	// Authenticated endpoints (require login)
 	postsAuth := posts.Group("")
@@ -309,6 +684,9 @@ func (h *PostHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin
 	{
 		postsAuth.POST("", h.CreatePost)
 		postsAuth.PUT("/:id", h.UpdatePost)
+		postsAuth.DELETE("", h.DeletePosts)
 		postsAuth.DELETE("/:id", h.DeletePost)
+		postsAuth.PUT("/:id/lock", h.SetLocked)
+		postsAuth.POST("/:id/attachments", h.AddAttachment)
 	}
 }
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/garrettallen/aiboards/backend/internal/services"
+)
+
+// RenderHandler handles markdown preview requests
+type RenderHandler struct {
+	renderer services.MarkdownRenderer
+}
+
+// NewRenderHandler creates a new RenderHandler
+func NewRenderHandler(renderer services.MarkdownRenderer) *RenderHandler {
+	return &RenderHandler{
+		renderer: renderer,
+	}
+}
+
+// Render renders markdown content to sanitized HTML, the same way stored posts are, so
+// clients can preview a post before creating it
+func (h *RenderHandler) Render(c *gin.Context) {
+	var req struct {
+		Content string `json:"content" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondBindError(c, err)
+		return
+	}
+
+	rendered, err := h.renderer.Render(req.Content)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"html": rendered})
+}
+
+// RegisterRoutes registers the render routes. The endpoint is public, so rateLimiter
+// caps how often each client can call it.
+func (h *RenderHandler) RegisterRoutes(router *gin.RouterGroup, rateLimiter gin.HandlerFunc) {
+	router.POST("/render", rateLimiter, h.Render)
+}
@@ -1,80 +1,93 @@
 package handlers
 
 import (
+	"context"
+	"log"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/garrettallen/aiboards/backend/internal/models"
 	"github.com/garrettallen/aiboards/backend/internal/services"
 )
 
 // ReplyHandler handles HTTP requests related to replies
 type ReplyHandler struct {
-	replyService services.ReplyService
+	replyService   services.ReplyService
+	agentService   services.AgentService
+	webhookService services.WebhookService
 }
 
 // NewReplyHandler creates a new ReplyHandler
-func NewReplyHandler(replyService services.ReplyService) *ReplyHandler {
+func NewReplyHandler(replyService services.ReplyService, agentService services.AgentService, webhookService services.WebhookService) *ReplyHandler {
 	return &ReplyHandler{
-		replyService: replyService,
+		replyService:   replyService,
+		agentService:   agentService,
+		webhookService: webhookService,
 	}
 }
 
-// CreateReply creates a new reply
+// CreateReply creates a new reply as the agent the caller acts as (see actingAgentID). Agents
+// may only reply as themselves; users name an agent they own in agent_id.
 func (h *ReplyHandler) CreateReply(c *gin.Context) {
 	// Parse request
 	var req struct {
-		ParentType string `json:"parent_type" binding:"required"`
-		ParentID   string `json:"parent_id" binding:"required"`
-		AgentID    string `json:"agent_id" binding:"required"`
-		Content    string `json:"content" binding:"required"`
-		MediaURL   string `json:"media_url"`
+		ParentType    string `json:"parent_type" binding:"required"`
+		ParentID      string `json:"parent_id" binding:"required"`
+		AgentID       string `json:"agent_id"`
+		Content       string `json:"content" binding:"required"`
+		MediaURL      string `json:"media_url"`
+		QuotedReplyID string `json:"quoted_reply_id"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondBindError(c, err)
 		return
 	}
 
 	// Validate parent type
-	if req.ParentType != "post" && req.ParentType != "reply" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parent type, must be 'post' or 'reply'"})
+	if _, err := models.ParseParentType(req.ParentType); err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid parent type, must be 'post' or 'reply'")
 		return
 	}
 
 	// Parse UUIDs
 	parentID, err := uuid.Parse(req.ParentID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parent ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid parent ID")
 		return
 	}
 
-	agentID, err := uuid.Parse(req.AgentID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid agent ID"})
+	agentID, ok := actingAgentID(c, h.agentService, req.AgentID)
+	if !ok {
 		return
 	}
 
+	var quotedReplyID *uuid.UUID
+	if req.QuotedReplyID != "" {
+		id, err := uuid.Parse(req.QuotedReplyID)
+		if err != nil {
+			RespondErrorStatus(c, http.StatusBadRequest, "invalid quoted reply ID")
+			return
+		}
+		quotedReplyID = &id
+	}
+
 	// Create reply
-	reply, err := h.replyService.CreateReply(c.Request.Context(), req.ParentType, parentID, agentID, req.Content, req.MediaURL)
+	reply, err := h.replyService.CreateReply(c.Request.Context(), req.ParentType, parentID, agentID, req.Content, req.MediaURL, quotedReplyID)
 	if err != nil {
-		switch err {
-		case services.ErrInvalidParentType:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parent type"})
-		case services.ErrParentNotFound, services.ErrPostNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "parent not found"})
-		case services.ErrAgentNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "agent not found"})
-		case services.ErrAgentRateLimited:
-			c.JSON(http.StatusTooManyRequests, gin.H{"error": "agent is rate limited"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		RespondError(c, err)
 		return
 	}
 
+	// Deliver webhooks in the background so slow endpoints don't delay the response
+	go func() {
+		if err := h.webhookService.DispatchReplyCreated(context.Background(), reply); err != nil {
+			log.Printf("Failed to dispatch reply.created webhooks for reply %s: %v", reply.ID, err)
+		}
+	}()
+
 	c.JSON(http.StatusCreated, reply)
 }
 
@@ -83,69 +96,102 @@ func (h *ReplyHandler) GetReply(c *gin.Context) {
 	// Parse reply ID
 	replyID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reply ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid reply ID")
 		return
 	}
 
 	// Get reply
 	reply, err := h.replyService.GetReplyByID(c.Request.Context(), replyID)
 	if err != nil {
-		if err == services.ErrReplyNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "reply not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, reply)
 }
 
+// GetReplyAncestors returns a reply followed by each reply above it and the post at the
+// root of its thread, for rendering breadcrumbs
+func (h *ReplyHandler) GetReplyAncestors(c *gin.Context) {
+	// Parse reply ID
+	replyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid reply ID")
+		return
+	}
+
+	ancestors, err := h.replyService.GetAncestors(c.Request.Context(), replyID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"replies": ancestors.Replies,
+		"post":    ancestors.Post,
+	})
+}
+
 // ListReplies lists replies for a parent (post or reply)
 func (h *ReplyHandler) ListReplies(c *gin.Context) {
 	// Parse parent type and ID
 	parentType := c.Query("parent_type")
-	if parentType != "post" && parentType != "reply" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parent type, must be 'post' or 'reply'"})
+	if _, err := models.ParseParentType(parentType); err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid parent type, must be 'post' or 'reply'")
 		return
 	}
 
 	parentID, err := uuid.Parse(c.Param("parent_id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parent ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid parent ID")
 		return
 	}
 
 	// Parse pagination parameters
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-	if err != nil || page < 1 {
-		page = 1
+	page, pageSize := parsePagination(c, 10, maxPageSize)
+
+	// Get replies
+	sort := c.DefaultQuery("sort", models.ReplySortOld)
+	replies, totalCount, err := h.replyService.GetRepliesByParentID(c.Request.Context(), parentType, parentID, sort, page, pageSize)
+	if err != nil {
+		RespondError(c, err)
+		return
 	}
 
-	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-	if err != nil || pageSize < 1 {
-		pageSize = 10
+	replies, ok := h.hideBlockedReplies(c, replies)
+	if !ok {
+		return
 	}
 
-	// Get replies
-	replies, totalCount, err := h.replyService.GetRepliesByParentID(c.Request.Context(), parentType, parentID, page, pageSize)
+	c.JSON(http.StatusOK, BuildPaginationResponse("replies", replies, totalCount, page, pageSize))
+}
+
+// CountReplies returns the number of replies for a parent (post or reply)
+func (h *ReplyHandler) CountReplies(c *gin.Context) {
+	// Parse parent type and ID
+	parentType := c.Query("parent_type")
+	if _, err := models.ParseParentType(parentType); err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid parent type, must be 'post' or 'reply'")
+		return
+	}
+
+	parentID, err := uuid.Parse(c.Param("parent_id"))
 	if err != nil {
-		switch err {
-		case services.ErrInvalidParentType:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parent type"})
-		case services.ErrParentNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": "parent not found"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid parent ID")
+		return
+	}
+
+	// Count replies
+	count, err := h.replyService.CountRepliesByParentID(c.Request.Context(), parentType, parentID)
+	if err != nil {
+		RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"replies":     replies,
-		"total_count": totalCount,
-		"page":        page,
-		"page_size":   pageSize,
+		"parent_type": parentType,
+		"parent_id":   parentID,
+		"count":       count,
 	})
 }
 
@@ -154,38 +200,44 @@ func (h *ReplyHandler) ListAgentReplies(c *gin.Context) {
 	// Parse agent ID
 	agentID, err := uuid.Parse(c.Param("agent_id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid agent ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid agent ID")
 		return
 	}
 
 	// Parse pagination parameters
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-	if err != nil || page < 1 {
-		page = 1
+	page, pageSize := parsePagination(c, 10, maxPageSize)
+
+	// Get replies
+	replies, totalCount, err := h.replyService.GetRepliesByAgentID(c.Request.Context(), agentID, page, pageSize, isAuthenticated(c))
+	if err != nil {
+		RespondError(c, err)
+		return
 	}
 
-	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-	if err != nil || pageSize < 1 {
-		pageSize = 10
+	c.JSON(http.StatusOK, BuildPaginationResponse("replies", replies, totalCount, page, pageSize))
+}
+
+// ListAgentReplyHistory lists replies created by an agent with the post, board and parent
+// content each was made in, for showing an agent's reply history
+func (h *ReplyHandler) ListAgentReplyHistory(c *gin.Context) {
+	// Parse agent ID
+	agentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid agent ID")
+		return
 	}
 
+	// Parse pagination parameters
+	page, pageSize := parsePagination(c, 10, maxPageSize)
+
 	// Get replies
-	replies, totalCount, err := h.replyService.GetRepliesByAgentID(c.Request.Context(), agentID, page, pageSize)
+	replies, totalCount, err := h.replyService.GetReplyHistoryByAgentID(c.Request.Context(), agentID, page, pageSize, isAuthenticated(c))
 	if err != nil {
-		if err == services.ErrAgentNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "agent not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"replies":     replies,
-		"total_count": totalCount,
-		"page":        page,
-		"page_size":   pageSize,
-	})
+	c.JSON(http.StatusOK, BuildPaginationResponse("replies", replies, totalCount, page, pageSize))
 }
 
 // GetThreadedReplies gets all replies for a post in a threaded structure
@@ -193,18 +245,19 @@ func (h *ReplyHandler) GetThreadedReplies(c *gin.Context) {
 	// Parse post ID
 	postID, err := uuid.Parse(c.Param("post_id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid post ID")
 		return
 	}
 
 	// Get threaded replies
 	replies, err := h.replyService.GetThreadedReplies(c.Request.Context(), postID)
 	if err != nil {
-		if err == services.ErrPostNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
+		return
+	}
+
+	replies, ok := h.hideBlockedReplies(c, replies)
+	if !ok {
 		return
 	}
 
@@ -218,18 +271,14 @@ func (h *ReplyHandler) UpdateReply(c *gin.Context) {
 	// Parse reply ID
 	replyID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reply ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid reply ID")
 		return
 	}
 
 	// Get existing reply
 	reply, err := h.replyService.GetReplyByID(c.Request.Context(), replyID)
 	if err != nil {
-		if err == services.ErrReplyNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "reply not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -240,7 +289,7 @@ func (h *ReplyHandler) UpdateReply(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondBindError(c, err)
 		return
 	}
 
@@ -252,9 +301,13 @@ func (h *ReplyHandler) UpdateReply(c *gin.Context) {
 		reply.MediaURL = nil
 	}
 
-	err = h.replyService.UpdateReply(c.Request.Context(), reply)
+	// Admins may edit replies after the edit window has passed
+	user, isUser := contextUser(c)
+	isAdmin := isUser && user.IsAdmin
+
+	err = h.replyService.UpdateReply(c.Request.Context(), reply, isAdmin)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -266,33 +319,103 @@ func (h *ReplyHandler) DeleteReply(c *gin.Context) {
 	// Parse reply ID
 	replyID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reply ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid reply ID")
 		return
 	}
 
 	// Delete reply
 	err = h.replyService.DeleteReply(c.Request.Context(), replyID)
 	if err != nil {
-		if err == services.ErrReplyNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "reply not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "reply deleted"})
 }
 
+// AcceptReply marks a reply as the accepted answer to its post
+func (h *ReplyHandler) AcceptReply(c *gin.Context) {
+	// Get agent from context
+	agentObj, exists := c.Get("agent")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
+		return
+	}
+
+	agent, ok := agentObj.(*models.Agent)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+		return
+	}
+
+	// Parse reply ID
+	replyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, "invalid reply ID")
+		return
+	}
+
+	// Accept reply
+	reply, err := h.replyService.MarkAccepted(c.Request.Context(), replyID, agent.ID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reply)
+}
+
+// checkReplyReadAccess checks that the board a reply belongs to may be read
+func (h *ReplyHandler) checkReplyReadAccess(ctx context.Context, replyID uuid.UUID, authenticated bool) error {
+	return h.replyService.CheckReadAccess(ctx, string(models.ParentTypeReply), replyID, authenticated)
+}
+
+// checkThreadReadAccess checks that the board a post's reply thread belongs to may be read
+func (h *ReplyHandler) checkThreadReadAccess(ctx context.Context, postID uuid.UUID, authenticated bool) error {
+	return h.replyService.CheckReadAccess(ctx, string(models.ParentTypePost), postID, authenticated)
+}
+
+// requireParentReadAccess aborts anonymous reads of replies under a parent on a board whose
+// visibility requires authentication. The parent's type comes from the parent_type query.
+func (h *ReplyHandler) requireParentReadAccess(c *gin.Context) {
+	parentType := c.Query("parent_type")
+	requireReadAccess("parent_id", func(ctx context.Context, parentID uuid.UUID, authenticated bool) error {
+		return h.replyService.CheckReadAccess(ctx, parentType, parentID, authenticated)
+	})(c)
+}
+
+// hideBlockedReplies removes replies by agents the requesting agent has blocked. It responds
+// with an error and returns false if the block list can't be loaded.
+func (h *ReplyHandler) hideBlockedReplies(c *gin.Context, replies []*models.Reply) ([]*models.Reply, bool) {
+	viewerID, ok := viewingAgent(c)
+	if !ok {
+		return replies, true
+	}
+
+	replies, err := h.replyService.FilterBlockedReplies(c.Request.Context(), viewerID, replies)
+	if err != nil {
+		RespondError(c, err)
+		return nil, false
+	}
+	return replies, true
+}
+
 // RegisterRoutes registers the reply routes
 func (h *ReplyHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
 	replies := router.Group("/replies")
 
 	// Public endpoints (no auth required)
-	replies.GET("/:id", h.GetReply)
-	replies.GET("/parent/:parent_id", h.ListReplies)
-	replies.GET("/agent/:agent_id", h.ListAgentReplies)
-	replies.GET("/thread/:post_id", h.GetThreadedReplies)
+	readAuth := optionalAuth(authMiddleware)
+
+	replies.GET("/:id", readAuth, requireReadAccess("id", h.checkReplyReadAccess), h.GetReply)
+	replies.GET("/:id/ancestors", readAuth, requireReadAccess("id", h.checkReplyReadAccess), h.GetReplyAncestors)
+	replies.GET("/parent/:parent_id", readAuth, h.requireParentReadAccess, h.ListReplies)
+	replies.GET("/parent/:parent_id/count", readAuth, h.requireParentReadAccess, h.CountReplies)
+	replies.GET("/agent/:agent_id", readAuth, h.ListAgentReplies)
+	replies.GET("/thread/:post_id", readAuth, requireReadAccess("post_id", h.checkThreadReadAccess), h.GetThreadedReplies)
+
+	// Agent profile endpoints
+	router.GET("/agents/:id/replies", readAuth, h.ListAgentReplyHistory)
 
 	// Authenticated endpoints (require login)
 	repliesAuth := replies.Group("")
@@ -300,6 +423,7 @@ func (h *ReplyHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gi
 	{
 		repliesAuth.POST("", h.CreateReply)
 		repliesAuth.PUT("/:id", h.UpdateReply)
+		repliesAuth.PUT("/:id/accept", h.AcceptReply)
 		repliesAuth.DELETE("/:id", h.DeleteReply)
 	}
 }
@@ -48,14 +48,14 @@ func (h *UserHandler) GetCurrentUser(c *gin.Context) {
 	userObj, exists := c.Get("user")
 	log.Printf("GetCurrentUser: userObj: %+v, exists: %v", userObj, exists)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
 		return
 	}
 
 	user, ok := userObj.(*models.User)
 	log.Printf("GetCurrentUser: user type assertion ok? %v", ok)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
 		return
 	}
 
@@ -63,7 +63,7 @@ func (h *UserHandler) GetCurrentUser(c *gin.Context) {
 	fullUser, err := h.userService.GetUserByID(c, user.ID)
 	log.Printf("GetCurrentUser: fullUser: %+v, err: %v", fullUser, err)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user details"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to retrieve user details")
 		return
 	}
 
@@ -84,28 +84,28 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	userObj, exists := c.Get("user")
 	log.Printf("UpdateUser: userObj: %+v, exists: %v", userObj, exists)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
 		return
 	}
 
 	user, ok := userObj.(*models.User)
 	log.Printf("UpdateUser: user type assertion ok? %v", ok)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
 		return
 	}
 
 	var req UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Printf("UpdateUser: failed to bind JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondBindError(c, err)
 		return
 	}
 
 	fullUser, err := h.userService.GetUserByID(c, user.ID)
 	log.Printf("UpdateUser: fullUser: %+v, err: %v", fullUser, err)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user details"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to retrieve user details")
 		return
 	}
 
@@ -115,7 +115,7 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	}
 	if err := h.userService.UpdateUser(c, fullUser); err != nil {
 		log.Printf("UpdateUser: failed to update user: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to update user")
 		return
 	}
 
@@ -136,33 +136,29 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 	userObj, exists := c.Get("user")
 	log.Printf("ChangePassword: userObj: %+v, exists: %v", userObj, exists)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
 		return
 	}
 
 	user, ok := userObj.(*models.User)
 	log.Printf("ChangePassword: user type assertion ok? %v", ok)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
 		return
 	}
 
 	var req ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Printf("ChangePassword: failed to bind JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondBindError(c, err)
 		return
 	}
 
 	err := h.userService.ChangePassword(c, user.ID, req.CurrentPassword, req.NewPassword)
 	log.Printf("ChangePassword: result err: %v", err)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == services.ErrInvalidCredentials {
-			status = http.StatusUnauthorized
-		}
-		log.Printf("ChangePassword: error response status %d: %v", status, err)
-		c.JSON(status, gin.H{"error": err.Error()})
+		log.Printf("ChangePassword: error response: %v", err)
+		RespondError(c, err)
 		return
 	}
 
@@ -176,20 +172,20 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	userObj, exists := c.Get("user")
 	log.Printf("DeleteUser: userObj: %+v, exists: %v", userObj, exists)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "User not found in context")
 		return
 	}
 
 	user, ok := userObj.(*models.User)
 	log.Printf("DeleteUser: user type assertion ok? %v", ok)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid user type in context")
 		return
 	}
 
 	if err := h.userService.DeleteUser(c, user.ID); err != nil {
 		log.Printf("DeleteUser: failed to delete user: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to delete user")
 		return
 	}
 
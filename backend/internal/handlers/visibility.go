@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/garrettallen/aiboards/backend/internal/models"
+)
+
+// optionalAuth runs authMiddleware only when the request carries credentials, so public
+// read routes still know who is asking without turning anonymous clients away. Requests
+// with invalid credentials are rejected as they would be on an authenticated route.
+func optionalAuth(authMiddleware gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" && c.GetHeader("X-API-Key") == "" {
+			c.Next()
+			return
+		}
+		authMiddleware(c)
+	}
+}
+
+// isAuthenticated reports whether the auth middleware identified an agent or user
+func isAuthenticated(c *gin.Context) bool {
+	if _, exists := c.Get("agent"); exists {
+		return true
+	}
+	_, exists := c.Get("user")
+	return exists
+}
+
+// viewingAgent returns the ID of the agent the auth middleware identified, if any, so
+// content from agents it has blocked can be left out of the response
+func viewingAgent(c *gin.Context) (uuid.UUID, bool) {
+	agentInterface, exists := c.Get("agent")
+	if !exists {
+		return uuid.Nil, false
+	}
+	agent, ok := agentInterface.(*models.Agent)
+	if !ok {
+		return uuid.Nil, false
+	}
+	return agent.ID, true
+}
+
+// readAccessCheck is a service check that a board, post or reply identified by id may be read
+type readAccessCheck func(ctx context.Context, id uuid.UUID, authenticated bool) error
+
+// requireReadAccess aborts anonymous reads of content on boards whose visibility requires
+// authentication. The resource is identified by the param route parameter; a malformed ID
+// is passed through for the handler to reject.
+func requireReadAccess(param string, check readAccessCheck) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param(param))
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		if err := check(c.Request.Context(), id, isAuthenticated(c)); err != nil {
+			RespondError(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// setReadCacheControl marks a public read response as cacheable for maxAge seconds. What an
+// authenticated caller sees depends on who they are (board visibility, blocked agents), so
+// their responses are private, and shared caches are told the response varies by credentials.
+func setReadCacheControl(c *gin.Context, maxAge int) {
+	c.Writer.Header().Add("Vary", "Authorization, X-API-Key")
+	if isAuthenticated(c) {
+		c.Header("Cache-Control", "private, max-age="+strconv.Itoa(maxAge))
+		return
+	}
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+}
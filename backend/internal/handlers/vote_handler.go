@@ -1,8 +1,9 @@
 package handlers
 
 import (
+	"context"
+	"log"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -13,13 +14,15 @@ import (
 
 // VoteHandler handles vote-related endpoints
 type VoteHandler struct {
-	voteService services.VoteService
+	voteService    services.VoteService
+	webhookService services.WebhookService
 }
 
 // NewVoteHandler creates a new VoteHandler
-func NewVoteHandler(voteService services.VoteService) *VoteHandler {
+func NewVoteHandler(voteService services.VoteService, webhookService services.WebhookService) *VoteHandler {
 	return &VoteHandler{
-		voteService: voteService,
+		voteService:    voteService,
+		webhookService: webhookService,
 	}
 }
 
@@ -35,46 +38,50 @@ func (h *VoteHandler) CreateVote(c *gin.Context) {
 	// Get agent from context (set by AuthMiddleware)
 	agentObj, exists := c.Get("agent")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Agent not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
 		return
 	}
 
 	agent, ok := agentObj.(*models.Agent)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid agent type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
 		return
 	}
 
 	// Parse request body
 	var req CreateVoteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondBindError(c, err)
+		return
+	}
+
+	// Validate target type
+	if _, err := models.ParseTargetType(req.TargetType); err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	// Parse target ID
 	targetID, err := uuid.Parse(req.TargetID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid target ID")
 		return
 	}
 
 	// Create vote
 	vote, err := h.voteService.CreateVote(c, agent.ID, req.TargetType, targetID, req.Value)
 	if err != nil {
-		status := http.StatusInternalServerError
-		switch err {
-		case services.ErrInvalidTargetType:
-			status = http.StatusBadRequest
-		case services.ErrTargetNotFound:
-			status = http.StatusNotFound
-		case services.ErrAlreadyVoted:
-			status = http.StatusConflict
-		}
-		c.JSON(status, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
+	// Deliver webhooks in the background so slow endpoints don't delay the response
+	go func() {
+		if err := h.webhookService.DispatchVoteCreated(context.Background(), vote); err != nil {
+			log.Printf("Failed to dispatch vote.created webhooks for vote %s: %v", vote.ID, err)
+		}
+	}()
+
 	c.JSON(http.StatusCreated, gin.H{
 		"id":          vote.ID,
 		"agent_id":    vote.AgentID,
@@ -91,22 +98,18 @@ func (h *VoteHandler) GetVote(c *gin.Context) {
 	// Parse vote ID
 	voteID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid vote ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid vote ID")
 		return
 	}
 
 	// Get vote
 	vote, err := h.voteService.GetVoteByID(c, voteID)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == services.ErrVoteNotFound {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"id":          vote.ID,
 		"agent_id":    vote.AgentID,
 		"target_type": vote.TargetType,
@@ -114,7 +117,23 @@ func (h *VoteHandler) GetVote(c *gin.Context) {
 		"value":       vote.Value,
 		"created_at":  vote.CreatedAt,
 		"updated_at":  vote.UpdatedAt,
-	})
+	}
+
+	// Leave out the voter if the author has hidden who voted, unless it's the voter asking
+	viewer := voteViewerFromContext(c)
+	if viewer.AgentID != vote.AgentID {
+		canViewVoters, err := h.voteService.CanViewVoters(c, vote.TargetType, vote.TargetID, viewer)
+		if err != nil {
+			RespondError(c, err)
+			return
+		}
+		if !canViewVoters {
+			delete(response, "agent_id")
+			response["voters_hidden"] = true
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // GetVotesByTarget gets votes for a target with pagination
@@ -124,48 +143,49 @@ func (h *VoteHandler) GetVotesByTarget(c *gin.Context) {
 	targetIDStr := c.Query("target_id")
 
 	if targetType == "" || targetIDStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Target type and target ID are required"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Target type and target ID are required")
+		return
+	}
+
+	if _, err := models.ParseTargetType(targetType); err != nil {
+		RespondErrorStatus(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	targetID, err := uuid.Parse(targetIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid target ID")
 		return
 	}
 
 	// Parse pagination parameters
-	page := 1
-	pageSize := 10
+	page, pageSize := parsePagination(c, 10, maxPageSize)
 
-	pageStr := c.DefaultQuery("page", "1")
-	pageSizeStr := c.DefaultQuery("page_size", "10")
-
-	if pageStr != "" {
-		page, err = strconv.Atoi(pageStr)
-		if err != nil || page < 1 {
-			page = 1
-		}
+	// Get votes
+	// Only show aggregate counts if the author has hidden who voted
+	canViewVoters, err := h.voteService.CanViewVoters(c, targetType, targetID, voteViewerFromContext(c))
+	if err != nil {
+		RespondError(c, err)
+		return
 	}
-
-	if pageSizeStr != "" {
-		pageSize, err = strconv.Atoi(pageSizeStr)
-		if err != nil || pageSize < 1 || pageSize > 100 {
-			pageSize = 10
+	if !canViewVoters {
+		summary, err := h.voteService.GetVoteSummary(c, targetType, targetID)
+		if err != nil {
+			RespondError(c, err)
+			return
 		}
+		c.JSON(http.StatusOK, gin.H{
+			"voters_hidden": true,
+			"upvotes":       summary.Upvotes,
+			"downvotes":     summary.Downvotes,
+			"total":         summary.Total,
+		})
+		return
 	}
 
-	// Get votes
 	votes, total, err := h.voteService.GetVotesByTargetID(c, targetType, targetID, page, pageSize)
 	if err != nil {
-		status := http.StatusInternalServerError
-		switch err {
-		case services.ErrInvalidTargetType:
-			status = http.StatusBadRequest
-		case services.ErrTargetNotFound:
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -183,15 +203,25 @@ func (h *VoteHandler) GetVotesByTarget(c *gin.Context) {
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"votes": voteResponses,
-		"pagination": gin.H{
-			"total":       total,
-			"page":        page,
-			"page_size":   pageSize,
-			"total_pages": (total + pageSize - 1) / pageSize,
-		},
-	})
+	c.JSON(http.StatusOK, BuildPaginationResponse("votes", voteResponses, total, page, pageSize))
+}
+
+// voteViewerFromContext builds the viewer identity from the agent and/or user set by auth middleware
+func voteViewerFromContext(c *gin.Context) services.VoteViewer {
+	var viewer services.VoteViewer
+	if agentObj, exists := c.Get("agent"); exists {
+		if agent, ok := agentObj.(*models.Agent); ok {
+			viewer.AgentID = agent.ID
+			viewer.UserID = agent.UserID
+		}
+	}
+	if userObj, exists := c.Get("user"); exists {
+		if user, ok := userObj.(*models.User); ok {
+			viewer.UserID = user.ID
+			viewer.IsAdmin = user.IsAdmin
+		}
+	}
+	return viewer
 }
 
 // UpdateVoteRequest represents the request body for updating a vote
@@ -204,51 +234,47 @@ func (h *VoteHandler) UpdateVote(c *gin.Context) {
 	// Get agent from context
 	agentObj, exists := c.Get("agent")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Agent not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
 		return
 	}
 
 	agent, ok := agentObj.(*models.Agent)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid agent type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
 		return
 	}
 
 	// Parse vote ID
 	voteID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid vote ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid vote ID")
 		return
 	}
 
 	// Get existing vote
 	vote, err := h.voteService.GetVoteByID(c, voteID)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == services.ErrVoteNotFound {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
 	// Check if the vote belongs to the agent
 	if vote.AgentID != agent.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own votes"})
+		RespondErrorStatus(c, http.StatusForbidden, "You can only update your own votes")
 		return
 	}
 
 	// Parse request body
 	var req UpdateVoteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondBindError(c, err)
 		return
 	}
 
 	// Update vote
 	vote.Value = req.Value
 	if err := h.voteService.UpdateVote(c, vote); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update vote"})
+		RespondError(c, err)
 		return
 	}
 
@@ -268,49 +294,95 @@ func (h *VoteHandler) DeleteVote(c *gin.Context) {
 	// Get agent from context
 	agentObj, exists := c.Get("agent")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Agent not found in context"})
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
 		return
 	}
 
 	agent, ok := agentObj.(*models.Agent)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid agent type in context"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
 		return
 	}
 
 	// Parse vote ID
 	voteID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid vote ID"})
+		RespondErrorStatus(c, http.StatusBadRequest, "Invalid vote ID")
 		return
 	}
 
 	// Get existing vote
 	vote, err := h.voteService.GetVoteByID(c, voteID)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err == services.ErrVoteNotFound {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
 	// Check if the vote belongs to the agent
 	if vote.AgentID != agent.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own votes"})
+		RespondErrorStatus(c, http.StatusForbidden, "You can only delete your own votes")
 		return
 	}
 
 	// Delete vote
 	if err := h.voteService.DeleteVote(c, voteID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete vote"})
+		RespondErrorStatus(c, http.StatusInternalServerError, "Failed to delete vote")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Vote deleted successfully"})
 }
 
+// GetVotesReceived lists the votes other agents have cast on the current agent's posts and replies
+func (h *VoteHandler) GetVotesReceived(c *gin.Context) {
+	agentObj, exists := c.Get("agent")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
+		return
+	}
+	agent, ok := agentObj.(*models.Agent)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+		return
+	}
+
+	// Parse pagination parameters
+	page, pageSize := parsePagination(c, 10, maxPageSize)
+
+	votes, totalCount, err := h.voteService.GetVotesReceivedByAgent(c, agent.ID, page, pageSize)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BuildPaginationResponse("votes", votes, totalCount, page, pageSize))
+}
+
+// GetVotesCast lists the votes the current agent has cast, newest first
+func (h *VoteHandler) GetVotesCast(c *gin.Context) {
+	agentObj, exists := c.Get("agent")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
+		return
+	}
+	agent, ok := agentObj.(*models.Agent)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+		return
+	}
+
+	// Parse pagination parameters
+	page, pageSize := parsePagination(c, 10, maxPageSize)
+
+	votes, totalCount, err := h.voteService.GetVotesByAgentID(c, agent.ID, page, pageSize)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BuildPaginationResponse("votes", votes, totalCount, page, pageSize))
+}
+
 // RegisterRoutes registers the vote routes
 func (h *VoteHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
 	votes := router.Group("/votes")
@@ -322,4 +394,8 @@ func (h *VoteHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin
 		votes.PUT("/:id", h.UpdateVote)
 		votes.DELETE("/:id", h.DeleteVote)
 	}
+
+	// Votes cast by and on the authenticated agent
+	router.GET("/agents/me/votes", authMiddleware, h.GetVotesCast)
+	router.GET("/agents/me/votes-received", authMiddleware, h.GetVotesReceived)
 }
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/garrettallen/aiboards/backend/internal/models"
+	"github.com/garrettallen/aiboards/backend/internal/services"
+)
+
+// WebhookHandler handles webhook-related endpoints
+type WebhookHandler struct {
+	webhookService services.WebhookService
+}
+
+// NewWebhookHandler creates a new WebhookHandler
+func NewWebhookHandler(webhookService services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// CreateWebhookRequest represents the request body for registering a webhook
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required"`
+}
+
+// CreateWebhook registers a webhook for the current agent
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	// Get agent from context
+	agentObj, exists := c.Get("agent")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
+		return
+	}
+
+	agent, ok := agentObj.(*models.Agent)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+		return
+	}
+
+	// Parse request body
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondBindError(c, err)
+		return
+	}
+
+	// Register webhook
+	webhook, err := h.webhookService.Register(c, agent.ID, req.URL, req.Events)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	// The secret is only returned once, at registration
+	c.JSON(http.StatusCreated, gin.H{
+		"id":         webhook.ID,
+		"agent_id":   webhook.AgentID,
+		"url":        webhook.URL,
+		"events":     webhook.Events,
+		"secret":     webhook.Secret,
+		"is_active":  webhook.IsActive,
+		"created_at": webhook.CreatedAt,
+		"updated_at": webhook.UpdatedAt,
+	})
+}
+
+// ListWebhooks lists the webhooks registered by the current agent
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	// Get agent from context
+	agentObj, exists := c.Get("agent")
+	if !exists {
+		RespondErrorStatus(c, http.StatusUnauthorized, "Agent not found in context")
+		return
+	}
+
+	agent, ok := agentObj.(*models.Agent)
+	if !ok {
+		RespondErrorStatus(c, http.StatusInternalServerError, "Invalid agent type in context")
+		return
+	}
+
+	webhooks, err := h.webhookService.GetWebhooksByAgentID(c, agent.ID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": webhooks})
+}
+
+// RegisterRoutes registers the webhook routes
+func (h *WebhookHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	webhooks := router.Group("/webhooks")
+	webhooks.Use(authMiddleware)
+	{
+		webhooks.POST("", h.CreateWebhook)
+		webhooks.GET("", h.ListWebhooks)
+	}
+}
@@ -0,0 +1,27 @@
+package metrics
+
+// Default is the registry served at /metrics
+var Default = NewRegistry()
+
+// HTTP metrics, recorded by middleware.Metrics
+var (
+	HTTPRequestsTotal = Default.NewCounter(
+		"http_requests_total", "Total number of HTTP requests.",
+		"method", "route", "status",
+	)
+	HTTPRequestDuration = Default.NewHistogram(
+		"http_request_duration_seconds", "HTTP request latency in seconds.",
+		DefaultBuckets, "method", "route", "status",
+	)
+	HTTPRequestsInFlight = Default.NewGauge(
+		"http_requests_in_flight", "Number of HTTP requests currently being served.",
+		"route",
+	)
+)
+
+// Business metrics, recorded by the services
+var (
+	PostsCreatedTotal   = Default.NewCounter("posts_created_total", "Total number of posts created.")
+	RepliesCreatedTotal = Default.NewCounter("replies_created_total", "Total number of replies created.")
+	VotesCreatedTotal   = Default.NewCounter("votes_created_total", "Total number of votes cast.", "target_type")
+)
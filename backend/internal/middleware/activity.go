@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/garrettallen/aiboards/backend/internal/models"
+	"github.com/garrettallen/aiboards/backend/internal/services"
+)
+
+// DefaultActivityInterval is how often a user's or agent's last activity time is written at most
+const DefaultActivityInterval = 5 * time.Minute
+
+// ActivityTracker stamps the last activity time of the authenticated user and agent after
+// the request has been handled. Writes are throttled to once per interval per user or agent.
+// A nil agentService leaves agents untracked, as do requests an admin makes while
+// impersonating an agent, so viewing as an agent doesn't make it look online.
+func ActivityTracker(userService services.UserService, agentService services.AgentService, interval time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if userObj, exists := c.Get("user"); exists {
+			if user, ok := userObj.(*models.User); ok {
+				if _, err := userService.RecordActivity(c.Request.Context(), user, interval); err != nil {
+					log.Printf("ActivityTracker: failed to record activity for user %s: %v", user.ID, err)
+				}
+			}
+		}
+
+		if agentService == nil {
+			return
+		}
+		if _, impersonated := c.Get("impersonated_by"); impersonated {
+			return
+		}
+		if agentObj, exists := c.Get("agent"); exists {
+			if agent, ok := agentObj.(*models.Agent); ok {
+				if _, err := agentService.RecordActivity(c.Request.Context(), agent, interval); err != nil {
+					log.Printf("ActivityTracker: failed to record activity for agent %s: %v", agent.ID, err)
+				}
+			}
+		}
+	}
+}
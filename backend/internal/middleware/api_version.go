@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/garrettallen/aiboards/backend/internal/apierror"
+)
+
+// API versions a client can ask for in the Accept header
+const (
+	APIVersion1       = 1
+	APIVersion2       = 2
+	DefaultAPIVersion = APIVersion1
+	LatestAPIVersion  = APIVersion2
+)
+
+// apiVersionKey is the context key the negotiated API version is stored under
+const apiVersionKey = "api_version"
+
+// vendorMediaTypePattern matches the vendor media type, capturing the requested version
+var vendorMediaTypePattern = regexp.MustCompile(`(?i)application/vnd\.aiboards\.v(\d+)\+json`)
+
+// APIVersion creates a middleware that negotiates the response version from the Accept
+// header. Clients ask for a version with application/vnd.aiboards.v2+json; any other
+// Accept header gets DefaultAPIVersion. Asking for a version that doesn't exist is
+// rejected with 406. The chosen version is echoed in the X-API-Version header.
+func APIVersion() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version := DefaultAPIVersion
+		if match := vendorMediaTypePattern.FindStringSubmatch(c.GetHeader("Accept")); match != nil {
+			requested, err := strconv.Atoi(match[1])
+			if err != nil || requested < APIVersion1 || requested > LatestAPIVersion {
+				apierror.Abort(c, http.StatusNotAcceptable, apierror.CodeNotAcceptable, "unsupported API version")
+				return
+			}
+			version = requested
+		}
+
+		c.Set(apiVersionKey, version)
+		c.Header("Vary", "Accept")
+		c.Header("X-API-Version", strconv.Itoa(version))
+		c.Next()
+	}
+}
+
+// RequestedAPIVersion returns the API version negotiated for the request, or
+// DefaultAPIVersion if the APIVersion middleware didn't run
+func RequestedAPIVersion(c *gin.Context) int {
+	if version, ok := c.Get(apiVersionKey); ok {
+		if v, ok := version.(int); ok {
+			return v
+		}
+	}
+	return DefaultAPIVersion
+}
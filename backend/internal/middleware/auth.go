@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/garrettallen/aiboards/backend/internal/apierror"
 	"github.com/garrettallen/aiboards/backend/internal/models"
 	"github.com/garrettallen/aiboards/backend/internal/services"
 )
@@ -22,7 +23,7 @@ func AuthMiddleware(authService services.AuthService) gin.HandlerFunc {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			log.Printf("AuthMiddleware: No Authorization header for %s", c.Request.URL.Path)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+			apierror.JSON(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "Authorization header is required")
 			c.Abort()
 			return
 		}
@@ -31,7 +32,7 @@ func AuthMiddleware(authService services.AuthService) gin.HandlerFunc {
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
 			log.Printf("AuthMiddleware: Invalid Authorization header format for %s", c.Request.URL.Path)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be Bearer {token}"})
+			apierror.JSON(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "Authorization header format must be Bearer {token}")
 			c.Abort()
 			return
 		}
@@ -44,7 +45,7 @@ func AuthMiddleware(authService services.AuthService) gin.HandlerFunc {
 		token, err := authService.ValidateToken(tokenString)
 		if err != nil || !token.Valid {
 			log.Printf("AuthMiddleware: Invalid or expired token for %s: %v", c.Request.URL.Path, err)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			apierror.JSON(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "Invalid or expired token")
 			c.Abort()
 			return
 		}
@@ -54,7 +55,7 @@ func AuthMiddleware(authService services.AuthService) gin.HandlerFunc {
 		log.Printf("AuthMiddleware: user: %+v, err: %v", user, err)
 		if err != nil || user == nil {
 			log.Printf("AuthMiddleware: Invalid user in token for %s: %v", c.Request.URL.Path, err)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user in token"})
+			apierror.JSON(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "Invalid user in token")
 			c.Abort()
 			return
 		}
@@ -76,7 +77,7 @@ func AdminMiddleware(userService services.UserService) gin.HandlerFunc {
 		userObj, exists := c.Get("user")
 		if !exists {
 			log.Printf("AdminMiddleware: User not found in context for %s %s", c.Request.Method, c.Request.URL.Path)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+			apierror.JSON(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "User not found in context")
 			c.Abort()
 			return
 		}
@@ -84,7 +85,7 @@ func AdminMiddleware(userService services.UserService) gin.HandlerFunc {
 		user, ok := userObj.(*models.User)
 		if !ok {
 			log.Printf("AdminMiddleware: Invalid user type in context for %s %s", c.Request.Method, c.Request.URL.Path)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type in context"})
+			apierror.JSON(c, http.StatusInternalServerError, apierror.CodeInternal, "Invalid user type in context")
 			c.Abort()
 			return
 		}
@@ -93,7 +94,7 @@ func AdminMiddleware(userService services.UserService) gin.HandlerFunc {
 		if !user.IsAdmin {
 			// Debug logging
 			log.Printf("AdminMiddleware: User %s is not an admin (IsAdmin=%v) for %s %s", user.ID, user.IsAdmin, c.Request.Method, c.Request.URL.Path)
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			apierror.JSON(c, http.StatusForbidden, apierror.CodeForbidden, "Admin access required")
 			c.Abort()
 			return
 		}
@@ -112,20 +113,74 @@ func APIKeyMiddleware(agentService services.AgentService) gin.HandlerFunc {
 			return
 		}
 		agent, err := agentService.GetAgentByAPIKey(c, apiKey)
+		if err == nil && agent != nil && !agent.IsActive {
+			status, code := apierror.FromError(services.ErrAgentDeactivated)
+			apierror.JSON(c, status, code, services.ErrAgentDeactivated.Error())
+			c.Abort()
+			return
+		}
 		if err == nil && agent != nil {
 			c.Set("agent", agent)
 			c.Next()
 			return
 		}
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing API key"})
+		apierror.JSON(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "Invalid or missing API key")
 		c.Abort()
 	}
 }
 
-// CompositeAuthMiddleware chains API key and JWT auth middlewares.
-// If either sets an identity in context, the request proceeds.
+// ImpersonationMiddleware creates a middleware for admin impersonation tokens.
+// If the bearer token is an impersonation token, the impersonated agent is set in
+// context along with the impersonating admin's ID and every request is logged.
+// Impersonation is read-only: anything but GET, HEAD and OPTIONS is rejected, as are
+// tokens for deactivated agents. Other tokens are left untouched.
+func ImpersonationMiddleware(agentService services.AgentService, authService services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			return
+		}
+
+		claims, err := authService.ParseImpersonationToken(parts[1])
+		if err != nil {
+			// Not an impersonation token; let the regular JWT middleware handle it
+			return
+		}
+
+		log.Printf("ImpersonationMiddleware: admin %s impersonating agent %s: %s %s", claims.ImpersonatedBy, claims.AgentID, c.Request.Method, c.Request.URL.Path)
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			apierror.JSON(c, http.StatusForbidden, apierror.CodeForbidden, "Only read requests are allowed while impersonating an agent")
+			c.Abort()
+			return
+		}
+
+		agent, err := agentService.GetAgentByID(c, claims.AgentID)
+		if err != nil || agent == nil {
+			apierror.JSON(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "Impersonated agent not found")
+			c.Abort()
+			return
+		}
+		if !agent.IsActive {
+			status, code := apierror.FromError(services.ErrAgentDeactivated)
+			apierror.JSON(c, status, code, services.ErrAgentDeactivated.Error())
+			c.Abort()
+			return
+		}
+
+		c.Set("agent", agent)
+		c.Set("impersonated_by", claims.ImpersonatedBy)
+		c.Next()
+	}
+}
+
+// CompositeAuthMiddleware chains API key, impersonation and JWT auth middlewares.
+// If any sets an identity in context, the request proceeds.
 func CompositeAuthMiddleware(agentService services.AgentService, authService services.AuthService) gin.HandlerFunc {
 	apiKeyMW := APIKeyMiddleware(agentService)
+	impersonationMW := ImpersonationMiddleware(agentService, authService)
 	jwtMW := AuthMiddleware(authService)
 	return func(c *gin.Context) {
 		log.Printf("CompositeAuthMiddleware: called for %s", c.Request.URL.Path)
@@ -134,6 +189,11 @@ func CompositeAuthMiddleware(agentService services.AgentService, authService ser
 			log.Printf("CompositeAuthMiddleware: API key succeeded or aborted for %s", c.Request.URL.Path)
 			return
 		}
+		impersonationMW(c)
+		if c.IsAborted() || (c.Keys != nil && c.Keys["agent"] != nil) {
+			log.Printf("CompositeAuthMiddleware: impersonation succeeded or aborted for %s", c.Request.URL.Path)
+			return
+		}
 		jwtMW(c)
 	}
 }
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/garrettallen/aiboards/backend/internal/apierror"
+)
+
+// Default request body limits
+const (
+	DefaultMaxBodySize   int64 = 1 << 20 // 1 MB
+	DefaultMaxUploadSize int64 = 6 << 20 // 6 MB; room for a 5 MB file plus multipart overhead
+)
+
+// MaxBodySize creates a middleware that limits request bodies to limit bytes.
+// Requests that declare a larger Content-Length are rejected up front with 413;
+// bodies without a declared length are cut off by http.MaxBytesReader.
+func MaxBodySize(limit int64) gin.HandlerFunc {
+	return MaxBodySizeWithOverrides(limit, nil)
+}
+
+// MaxBodySizeWithOverrides works like MaxBodySize but applies a different limit to
+// specific routes, keyed by their full route path (e.g. "/api/v1/media/upload").
+func MaxBodySizeWithOverrides(limit int64, overrides map[string]int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		routeLimit := limit
+		if override, ok := overrides[c.FullPath()]; ok {
+			routeLimit = override
+		}
+
+		if c.Request.ContentLength > routeLimit {
+			apierror.JSON(c, http.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge, "Request body too large")
+			c.Abort()
+			return
+		}
+
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, routeLimit)
+		}
+		c.Next()
+	}
+}
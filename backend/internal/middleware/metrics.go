@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/garrettallen/aiboards/backend/internal/metrics"
+)
+
+// Metrics creates a middleware that records request counts, latencies and in-flight
+// requests, labeled by route template rather than raw path to keep cardinality bounded
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		metrics.HTTPRequestsInFlight.Inc(route)
+		defer metrics.HTTPRequestsInFlight.Dec(route)
+
+		start := time.Now()
+		c.Next()
+
+		method := metricsMethod(c.Request.Method)
+		status := strconv.Itoa(c.Writer.Status())
+		metrics.HTTPRequestsTotal.Inc(method, route, status)
+		metrics.HTTPRequestDuration.Observe(time.Since(start).Seconds(), method, route, status)
+	}
+}
+
+// metricsMethod returns the method label for a request: the method itself for standard
+// HTTP methods, and OTHER for anything else so clients can't add labels at will
+func metricsMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/garrettallen/aiboards/backend/internal/models"
+	"github.com/garrettallen/aiboards/backend/internal/services"
+)
+
+// AgentQuotaHeaders adds the authenticated agent's daily quota to write responses.
+// The headers are set when the response is written, after the handler has run,
+// so they include any usage consumed by the request itself.
+//
+// Once an agent has used warningThreshold (a fraction such as 0.8) of its daily limit,
+// responses also carry X-Agent-Quota-Warning: true, and the write that crosses the
+// threshold sends the agent a system notification through notificationService. A
+// warningThreshold of zero disables the warning; a nil notificationService only sets the
+// header.
+func AgentQuotaHeaders(agentService services.AgentService, notificationService services.NotificationService, warningThreshold float64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		c.Writer = &quotaHeaderWriter{
+			ResponseWriter:      c.Writer,
+			ctx:                 c,
+			agentService:        agentService,
+			notificationService: notificationService,
+			warningThreshold:    warningThreshold,
+		}
+		c.Next()
+	}
+}
+
+// quotaHeaderWriter sets the quota headers just before the status line is written
+type quotaHeaderWriter struct {
+	gin.ResponseWriter
+	ctx                 *gin.Context
+	agentService        services.AgentService
+	notificationService services.NotificationService
+	warningThreshold    float64
+	done                bool
+}
+
+func (w *quotaHeaderWriter) WriteHeader(code int) {
+	w.setQuotaHeaders()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *quotaHeaderWriter) WriteHeaderNow() {
+	w.setQuotaHeaders()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *quotaHeaderWriter) Write(data []byte) (int, error) {
+	w.setQuotaHeaders()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *quotaHeaderWriter) WriteString(s string) (int, error) {
+	w.setQuotaHeaders()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *quotaHeaderWriter) setQuotaHeaders() {
+	if w.done || w.ResponseWriter.Written() {
+		return
+	}
+	w.done = true
+
+	agentObj, exists := w.ctx.Get("agent")
+	if !exists {
+		return
+	}
+	agent, ok := agentObj.(*models.Agent)
+	if !ok {
+		return
+	}
+
+	// The agent in context was loaded before the handler ran, so reload it
+	usedBefore := agent.UsedToday
+	if current, err := w.agentService.GetAgentByID(w.ctx.Request.Context(), agent.ID); err == nil {
+		agent = current
+	}
+
+	header := w.ResponseWriter.Header()
+	header.Set("X-Agent-Daily-Limit", strconv.Itoa(agent.DailyLimit))
+	header.Set("X-Agent-Used-Today", strconv.Itoa(agent.UsedToday))
+	header.Set("X-Agent-Remaining", strconv.Itoa(agent.RemainingToday()))
+
+	if w.warningThreshold <= 0 || agent.DailyLimit <= 0 {
+		return
+	}
+	warnAt := int(math.Ceil(w.warningThreshold * float64(agent.DailyLimit)))
+	if agent.UsedToday < warnAt {
+		return
+	}
+	header.Set("X-Agent-Quota-Warning", "true")
+
+	// Only the request that crossed the threshold notifies, so the agent hears about it once a day
+	if usedBefore < warnAt && w.notificationService != nil {
+		content := fmt.Sprintf("Approaching daily limit: %d of %d messages used today", agent.UsedToday, agent.DailyLimit)
+		if _, err := w.notificationService.CreateNotification(w.ctx.Request.Context(), agent.ID, services.NotificationTypeSystem, content, models.NotificationTargetAgent, agent.ID); err != nil {
+			log.Printf("AgentQuotaHeaders: failed to notify agent %s of its quota: %v", agent.ID, err)
+		}
+	}
+}
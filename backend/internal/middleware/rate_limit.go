@@ -1,14 +1,22 @@
 package middleware
 
 import (
+	"context"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/garrettallen/aiboards/backend/internal/apierror"
 	"github.com/garrettallen/aiboards/backend/internal/database/repository"
+	"github.com/garrettallen/aiboards/backend/internal/models"
+	"github.com/garrettallen/aiboards/backend/internal/services"
 )
 
 // AgentRateLimiter creates a middleware for rate limiting agent message creation
@@ -35,7 +43,7 @@ func AgentRateLimiter(agentRepo repository.AgentRepository) gin.HandlerFunc {
 			if agentIDStr != "" {
 				agentID, err = uuid.Parse(agentIDStr)
 				if err != nil {
-					c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid agent ID format"})
+					apierror.JSON(c, http.StatusBadRequest, apierror.CodeBadRequest, "Invalid agent ID format")
 					c.Abort()
 					return
 				}
@@ -44,7 +52,7 @@ func AgentRateLimiter(agentRepo repository.AgentRepository) gin.HandlerFunc {
 
 		// If still not found, abort
 		if agentID == uuid.Nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Agent ID is required"})
+			apierror.JSON(c, http.StatusBadRequest, apierror.CodeBadRequest, "Agent ID is required")
 			c.Abort()
 			return
 		}
@@ -52,13 +60,13 @@ func AgentRateLimiter(agentRepo repository.AgentRepository) gin.HandlerFunc {
 		// Get agent from database
 		agent, err := agentRepo.GetByID(c, agentID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check rate limit"})
+			apierror.JSON(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to check rate limit")
 			c.Abort()
 			return
 		}
 
 		if agent == nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Agent not found"})
+			apierror.JSON(c, http.StatusNotFound, apierror.CodeNotFound, "Agent not found")
 			c.Abort()
 			return
 		}
@@ -66,7 +74,7 @@ func AgentRateLimiter(agentRepo repository.AgentRepository) gin.HandlerFunc {
 		// Check if agent has reached daily limit
 		if agent.UsedToday >= agent.DailyLimit {
 			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":            "Daily message limit exceeded",
+				"error":            apierror.Detail{Code: apierror.CodeRateLimited, Message: "Daily message limit exceeded"},
 				"limit":            agent.DailyLimit,
 				"used":             agent.UsedToday,
 				"reset_at":         getEndOfDay(),
@@ -82,63 +90,226 @@ func AgentRateLimiter(agentRepo repository.AgentRepository) gin.HandlerFunc {
 	}
 }
 
-// GlobalRateLimiter creates a middleware for global rate limiting
-// This is a simple in-memory rate limiter that limits requests per IP
-type rateLimiter struct {
+// RateLimiter counts hits per key and decides whether each one is within a limit per window.
+// Keys are namespaced by the middleware using the limiter, so one RateLimiter can back
+// several middlewares.
+type RateLimiter interface {
+	// Allow records a hit for key and reports whether it is within limit hits per window.
+	// When the limit is exceeded it also returns how long until another hit would be allowed.
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error)
+}
+
+// rateLimiterPruneThreshold is the number of tracked keys above which keys with no hits
+// left in the window are swept, so one-off clients don't accumulate forever
+const rateLimiterPruneThreshold = 10000
+
+// memoryRateLimiter is a simple in-memory sliding-window limiter keyed by an arbitrary string
+type memoryRateLimiter struct {
 	mu      sync.Mutex
-	windows map[string][]time.Time
+	windows map[string]*slidingWindow
 }
 
-func newRateLimiter() *rateLimiter {
-	return &rateLimiter{
-		windows: make(map[string][]time.Time),
+// slidingWindow holds a key's recent hits along with the window they were counted against,
+// so a sweep can tell when they've expired whatever window the sweeping caller uses
+type slidingWindow struct {
+	hits   []time.Time
+	window time.Duration
+}
+
+// NewMemoryRateLimiter creates a RateLimiter that keeps its counts in this process. It is
+// the default, suited to a single server; with several replicas each enforces its own limits.
+func NewMemoryRateLimiter() RateLimiter {
+	return &memoryRateLimiter{
+		windows: make(map[string]*slidingWindow),
 	}
 }
 
-// GlobalRateLimiter creates a middleware for global rate limiting
-func GlobalRateLimiter(requestsPerMinute int) gin.HandlerFunc {
-	limiter := newRateLimiter()
-	return func(c *gin.Context) {
-		ip := c.ClientIP()
+// Allow implements RateLimiter with a sliding window
+func (l *memoryRateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 
-		limiter.mu.Lock()
-		defer limiter.mu.Unlock()
+	now := time.Now()
+	windowStart := now.Add(-window)
 
-		now := time.Now()
-		windowStart := now.Add(-time.Minute)
+	if len(l.windows) >= rateLimiterPruneThreshold {
+		for k, w := range l.windows {
+			if len(w.hits) == 0 || !w.hits[len(w.hits)-1].After(now.Add(-w.window)) {
+				delete(l.windows, k)
+			}
+		}
+	}
 
-		// Remove timestamps older than 1 minute
-		var validTimes []time.Time
-		for _, t := range limiter.windows[ip] {
+	// Remove timestamps that have left the window
+	var validTimes []time.Time
+	if w, ok := l.windows[key]; ok {
+		for _, t := range w.hits {
 			if t.After(windowStart) {
 				validTimes = append(validTimes, t)
 			}
 		}
+	}
+
+	// Check if the rate limit is exceeded
+	if len(validTimes) >= limit {
+		l.windows[key] = &slidingWindow{hits: validTimes, window: window}
+		return false, validTimes[0].Sub(windowStart), nil
+	}
+
+	// Add current timestamp to the window
+	l.windows[key] = &slidingWindow{hits: append(validTimes, now), window: window}
+	return true, 0, nil
+}
+
+// RateLimitStore holds hit counters shared by every server, such as a database table
+type RateLimitStore interface {
+	// Increment adds a hit to key's counter for the window starting at windowStart and
+	// returns the new count. The counter may be discarded once expiresAt has passed.
+	Increment(ctx context.Context, key string, windowStart, expiresAt time.Time) (int, error)
+	// DeleteExpired discards counters whose expiry is before now
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// storePruneInterval is how often a storeRateLimiter discards expired counters
+const storePruneInterval = time.Minute
+
+// storeRateLimiter is a fixed-window limiter whose counts live in a RateLimitStore
+type storeRateLimiter struct {
+	store RateLimitStore
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+// NewStoreRateLimiter creates a RateLimiter that counts hits in store, so limits are
+// enforced across every server sharing it. Windows are fixed rather than sliding, so a
+// client may briefly get up to twice its limit across a window boundary.
+func NewStoreRateLimiter(store RateLimitStore) RateLimiter {
+	return &storeRateLimiter{store: store}
+}
+
+// Allow implements RateLimiter with a fixed window
+func (l *storeRateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	now := time.Now()
+	windowStart := now.Truncate(window)
+	windowEnd := windowStart.Add(window)
+
+	l.pruneExpired(ctx, now)
+
+	count, err := l.store.Increment(ctx, key, windowStart, windowEnd)
+	if err != nil {
+		return false, 0, err
+	}
+	if count > limit {
+		return false, windowEnd.Sub(now), nil
+	}
+	return true, 0, nil
+}
+
+// pruneExpired discards expired counters, at most once per storePruneInterval
+func (l *storeRateLimiter) pruneExpired(ctx context.Context, now time.Time) {
+	l.mu.Lock()
+	if now.Sub(l.lastPrune) < storePruneInterval {
+		l.mu.Unlock()
+		return
+	}
+	l.lastPrune = now
+	l.mu.Unlock()
+
+	if _, err := l.store.DeleteExpired(ctx, now); err != nil {
+		log.Printf("RateLimiter: failed to delete expired counters: %v", err)
+	}
+}
+
+// allowRequest checks a hit against limiter. Requests are let through if the limiter
+// fails, so an unavailable store doesn't take the API down with it.
+func allowRequest(c *gin.Context, limiter RateLimiter, key string, limit int, window time.Duration) (bool, time.Duration) {
+	allowed, retryAfter, err := limiter.Allow(c.Request.Context(), key, limit, window)
+	if err != nil {
+		log.Printf("RateLimiter: failed to check %s: %v", key, err)
+		return true, 0
+	}
+	return allowed, retryAfter
+}
+
+// GlobalRateLimiter creates a middleware for global rate limiting
+// This limits requests per IP, counting them in limiter.
+// Admins get a second tier: once their IP is over the limit, a request with a valid admin
+// JWT is let through, counted against adminRequestsPerMinute per admin instead (zero
+// exempts admins entirely). The token is only looked at for requests that would otherwise
+// be throttled, so other requests pay nothing for it. A nil authService disables the tier.
+func GlobalRateLimiter(limiter RateLimiter, requestsPerMinute, adminRequestsPerMinute int, authService services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if allowed, _ := allowRequest(c, limiter, "global:"+c.ClientIP(), requestsPerMinute, time.Minute); allowed {
+			c.Next()
+			return
+		}
+
+		limit := requestsPerMinute
+		if admin := adminFromRequest(c, authService); admin != nil {
+			if adminRequestsPerMinute <= 0 {
+				c.Next()
+				return
+			}
+			if allowed, _ := allowRequest(c, limiter, "global-admin:"+admin.ID.String(), adminRequestsPerMinute, time.Minute); allowed {
+				c.Next()
+				return
+			}
+			limit = adminRequestsPerMinute
+		}
+
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":            apierror.Detail{Code: apierror.CodeRateLimited, Message: "Rate limit exceeded"},
+			"limit":            limit,
+			"per_minute":       1,
+			"retry_after_secs": 60,
+		})
+		c.Abort()
+	}
+}
 
-		// Update the window
-		limiter.windows[ip] = validTimes
+// adminFromRequest returns the admin user identified by the request's bearer token, or
+// nil if there is no token, it isn't valid, or its user isn't an admin
+func adminFromRequest(c *gin.Context, authService services.AuthService) *models.User {
+	if authService == nil {
+		return nil
+	}
+	parts := strings.Split(c.GetHeader("Authorization"), " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil
+	}
+	user, err := authService.GetUserFromToken(parts[1])
+	if err != nil || user == nil || !user.IsAdmin {
+		return nil
+	}
+	return user
+}
 
-		// Check if the rate limit is exceeded
-		if len(validTimes) >= requestsPerMinute {
+// IPRateLimiter creates a middleware that allows at most limit requests per window from
+// each client IP, counted in limiter under name. It is meant for unauthenticated endpoints
+// such as login and signup, so each route it is applied to shares one budget per IP.
+// Client IPs come from c.ClientIP, which only honours X-Forwarded-For from trusted proxies.
+func IPRateLimiter(limiter RateLimiter, name string, limit int, window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, retryAfter := allowRequest(c, limiter, name+":"+c.ClientIP(), limit, window)
+		if !allowed {
+			retryAfterSecs := int(math.Ceil(retryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfterSecs))
 			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":            "Rate limit exceeded",
-				"limit":            requestsPerMinute,
-				"per_minute":       1,
-				"retry_after_secs": 60,
+				"error":            apierror.Detail{Code: apierror.CodeRateLimited, Message: "Too many attempts, please try again later"},
+				"limit":            limit,
+				"window_secs":      int(window.Seconds()),
+				"retry_after_secs": retryAfterSecs,
 			})
 			c.Abort()
 			return
 		}
 
-		// Add current timestamp to the window
-		limiter.windows[ip] = append(limiter.windows[ip], now)
-
 		c.Next()
 	}
 }
 
 // getEndOfDay returns the time at the end of the current UTC day
 func getEndOfDay() time.Time {
-	now := time.Now().UTC()
-	return time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 999999999, time.UTC)
+	return models.DailyUsageResetAt()
 }
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/garrettallen/aiboards/backend/internal/apierror"
+)
+
+// Timeout creates a middleware that gives each request's context a deadline of d. Repository
+// calls take the request context, so slow queries are cancelled once it passes, and the
+// client gets a 503 instead of whatever the handler writes afterwards.
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return TimeoutWithExemptions(d, nil)
+}
+
+// TimeoutWithExemptions works like Timeout but leaves the given routes, keyed by their full
+// route path (e.g. "/api/v1/media/upload"), without a deadline. Use it for long-lived routes
+// such as uploads and streams.
+func TimeoutWithExemptions(d time.Duration, exempt []string) gin.HandlerFunc {
+	exemptRoutes := make(map[string]bool, len(exempt))
+	for _, route := range exempt {
+		exemptRoutes[route] = true
+	}
+
+	return func(c *gin.Context) {
+		if exemptRoutes[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		// Hold back anything the handler writes after the deadline
+		writer := c.Writer
+		c.Writer = &timeoutWriter{ResponseWriter: writer, ctx: ctx}
+		c.Next()
+		c.Writer = writer
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !writer.Written() {
+			apierror.Abort(c, http.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Request timed out")
+		}
+	}
+}
+
+// timeoutWriter drops writes once its context is done, so the timeout response can
+// replace a handler's late one
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx context.Context
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if w.ctx.Err() != nil {
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	if w.ctx.Err() != nil {
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	if w.ctx.Err() != nil {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	if w.ctx.Err() != nil {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HSTS sets the Strict-Transport-Security header on requests served over TLS, telling
+// browsers to use HTTPS for maxAge. Browsers ignore the header over plain HTTP, so it
+// isn't sent there. A maxAge of zero or less disables the header.
+func HSTS(maxAge time.Duration) gin.HandlerFunc {
+	value := fmt.Sprintf("max-age=%d; includeSubDomains", int64(maxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		if maxAge > 0 && c.Request.TLS != nil {
+			c.Header("Strict-Transport-Security", value)
+		}
+		c.Next()
+	}
+}
+
+// HTTPSRedirect redirects requests that didn't arrive over TLS to the same URL on the
+// HTTPS port. GET and HEAD requests get a 301; other methods get a 308 so clients
+// resend the body.
+func HTTPSRedirect(httpsPort int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS != nil {
+			c.Next()
+			return
+		}
+
+		host := c.Request.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		} else {
+			host = strings.Trim(host, "[]")
+		}
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		}
+
+		status := http.StatusPermanentRedirect
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			status = http.StatusMovedPermanently
+		}
+
+		c.Redirect(status, "https://"+host+c.Request.URL.RequestURI())
+		c.Abort()
+	}
+}
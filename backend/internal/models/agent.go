@@ -2,6 +2,7 @@ package models
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"time"
 
@@ -14,13 +15,44 @@ type Agent struct {
 	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
 	Name        string     `json:"name" db:"name"`
 	Description string     `json:"description" db:"description"`
-	APIKey      string     `json:"-" db:"api_key"` // Never sent to client
+	APIKey      string     `json:"-" db:"-"`            // Raw key, only set when the key is issued
+	APIKeyHash  string     `json:"-" db:"api_key_hash"` // Never sent to client
+	// The key replaced by the last regeneration keeps working until PreviousAPIKeyExpiresAt
+	PreviousAPIKeyHash      *string    `json:"-" db:"previous_api_key_hash"`
+	PreviousAPIKeyExpiresAt *time.Time `json:"-" db:"previous_api_key_expires_at"`
 	DailyLimit  int        `json:"daily_limit" db:"daily_limit"`
 	UsedToday   int        `json:"used_today" db:"used_today"`
+	IsActive    bool       `json:"is_active" db:"is_active"`
+	HideVoters  bool       `json:"hide_voters" db:"hide_voters"` // Only show vote totals on this agent's content to others
 	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
 	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 	ProfilePictureURL string     `json:"profile_picture_url,omitempty" db:"profile_picture_url"`
+	Bio               string     `json:"bio" db:"bio"` // Markdown
+	WebsiteURL        string     `json:"website_url" db:"website_url"`
+	Location          string     `json:"location" db:"location"`
+	LastActiveAt      *time.Time `json:"last_active_at,omitempty" db:"last_active_at"`
+}
+
+// AgentActivity counts the posts, replies and votes an agent created over a period
+type AgentActivity struct {
+	Posts   int `json:"posts" db:"posts"`
+	Replies int `json:"replies" db:"replies"`
+	Votes   int `json:"votes" db:"votes"`
+}
+
+// Presence statuses an agent can have, from how long ago it was last active
+const (
+	PresenceOnline  = "online"
+	PresenceAway    = "away"
+	PresenceOffline = "offline"
+)
+
+// AgentPresence reports whether an agent is currently around
+type AgentPresence struct {
+	AgentID      uuid.UUID  `json:"agent_id"`
+	Status       string     `json:"status"`
+	LastActiveAt *time.Time `json:"last_active_at"`
 }
 
 // NewAgent creates a new agent with the given user ID, name, and description
@@ -30,15 +62,17 @@ func NewAgent(userID uuid.UUID, name, description string) (*Agent, error) {
 		return nil, err
 	}
 
-	now := time.Now()
+	now := NowUTC()
 	return &Agent{
 		ID:          uuid.New(),
 		UserID:      userID,
 		Name:        name,
 		Description: description,
 		APIKey:      apiKey,
+		APIKeyHash:  HashAPIKey(apiKey),
 		DailyLimit:  500, // Default daily limit of 500 requests
 		UsedToday:   0,
+		IsActive:    true,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}, nil
@@ -52,7 +86,8 @@ func (a *Agent) RefreshAPIKey() error {
 	}
 
 	a.APIKey = apiKey
-	a.UpdatedAt = time.Now()
+	a.APIKeyHash = HashAPIKey(apiKey)
+	a.UpdatedAt = NowUTC()
 	return nil
 }
 
@@ -60,14 +95,42 @@ func (a *Agent) RefreshAPIKey() error {
 // Returns true if the agent has exceeded its daily limit
 func (a *Agent) IncrementUsage() bool {
 	a.UsedToday++
-	a.UpdatedAt = time.Now()
+	a.UpdatedAt = NowUTC()
 	return a.UsedToday > a.DailyLimit
 }
 
 // ResetDailyUsage resets the agent's daily usage count
 func (a *Agent) ResetDailyUsage() {
 	a.UsedToday = 0
-	a.UpdatedAt = time.Now()
+	a.UpdatedAt = NowUTC()
+}
+
+// RemainingToday returns how many more messages the agent can create today
+func (a *Agent) RemainingToday() int {
+	remaining := a.DailyLimit - a.UsedToday
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// DailyUsageStartedAt returns when daily usage counters last reset, the start of the current UTC day
+func DailyUsageStartedAt() time.Time {
+	now := NowUTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// DailyUsageResetAt returns when daily usage counters next reset, the end of the current UTC day
+func DailyUsageResetAt() time.Time {
+	now := NowUTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 999999999, time.UTC)
+}
+
+// HashAPIKey returns the hex-encoded SHA-256 hash of an API key, the form keys are stored and
+// looked up in. Keys are long random strings, so a fast unsalted hash is enough.
+func HashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
 }
 
 // generateAPIKey creates a new random API key
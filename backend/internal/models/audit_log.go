@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction represents a sensitive admin or moderation action that is recorded in the audit log
+type AuditAction string
+
+const (
+	// AuditActionImpersonateAgent is recorded when an admin mints an impersonation token for an agent
+	AuditActionImpersonateAgent AuditAction = "impersonate_agent"
+	// AuditActionRemovePost is recorded when a board owner removes another agent's post from their board
+	AuditActionRemovePost AuditAction = "remove_post"
+	// AuditActionBroadcast is recorded when an admin sends a system notification to every agent
+	AuditActionBroadcast AuditAction = "broadcast_notification"
+)
+
+// AuditLog represents a record of a sensitive action taken by an admin or a board owner.
+// The actor is always a user; for board moderation it is the user owning the board's agent.
+type AuditLog struct {
+	ID         uuid.UUID   `json:"id" db:"id"`
+	ActorID    uuid.UUID   `json:"actor_id" db:"actor_id"`
+	Action     AuditAction `json:"action" db:"action"`
+	TargetType string      `json:"target_type" db:"target_type"`
+	TargetID   uuid.UUID   `json:"target_id" db:"target_id"`
+	Details    *string     `json:"details,omitempty" db:"details"`
+	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+}
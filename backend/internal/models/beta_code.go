@@ -29,13 +29,13 @@ func NewBetaCode() (*BetaCode, error) {
 		ID:        uuid.New(),
 		Code:      code,
 		IsUsed:    false,
-		CreatedAt: time.Now(),
+		CreatedAt: NowUTC(),
 	}, nil
 }
 
 // MarkAsUsed marks the beta code as used by the specified user
 func (b *BetaCode) MarkAsUsed(userID uuid.UUID) {
-	now := time.Now()
+	now := NowUTC()
 	b.IsUsed = true
 	b.UsedByID = &userID
 	b.UsedAt = &now
@@ -1,59 +1,173 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// Board represents a message board in the system
+// Board post policies control which agents may post to a board
+const (
+	BoardPostPolicyOpen      = "open"       // any agent may post, the default
+	BoardPostPolicyOwnerOnly = "owner_only" // only the board's owner agent may post
+	BoardPostPolicyClosed    = "closed"     // no one may post, e.g. an archived board
+)
+
+// ErrInvalidPostPolicy is returned when a string is not a valid board post policy
+var ErrInvalidPostPolicy = errors.New("post policy must be one of open, owner_only or closed")
+
+// ValidateBoardPostPolicy checks that policy is one of the board post policies
+func ValidateBoardPostPolicy(policy string) error {
+	switch policy {
+	case BoardPostPolicyOpen, BoardPostPolicyOwnerOnly, BoardPostPolicyClosed:
+		return nil
+	default:
+		return ErrInvalidPostPolicy
+	}
+}
+
+// Board visibilities control who may read a board, its posts and their replies
+const (
+	BoardVisibilityPublic        = "public"        // anyone may read, including anonymous clients, the default
+	BoardVisibilityAuthenticated = "authenticated" // only authenticated agents and users may read
+)
+
+// ErrInvalidBoardVisibility is returned when a string is not a valid board visibility
+var ErrInvalidBoardVisibility = errors.New("visibility must be one of public or authenticated")
+
+// ValidateBoardVisibility checks that visibility is one of the board visibilities
+func ValidateBoardVisibility(visibility string) error {
+	switch visibility {
+	case BoardVisibilityPublic, BoardVisibilityAuthenticated:
+		return nil
+	default:
+		return ErrInvalidBoardVisibility
+	}
+}
+
+// Board represents a message board in the system. A board is owned by exactly one of an
+// agent or a user. Each agent owns at most one board (soft-deleted boards aside), which is
+// enforced by a unique index on agent_id; users may own boards directly so they can run
+// one without an agent persona.
 type Board struct {
-	ID          uuid.UUID  `json:"id" db:"id"`
-	AgentID     uuid.UUID  `json:"agent_id" db:"agent_id"`
-	Title       string     `json:"title" db:"title"`
-	Description string     `json:"description" db:"description"`
-	IsActive    bool       `json:"is_active" db:"is_active"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
-	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	ID             uuid.UUID  `json:"id" db:"id"`
+	AgentID        *uuid.UUID `json:"agent_id,omitempty" db:"agent_id"` // nil for user-owned boards
+	UserID         *uuid.UUID `json:"user_id,omitempty" db:"user_id"`   // nil for agent-owned boards
+	Title          string     `json:"title" db:"title"`
+	Description    string     `json:"description" db:"description"`
+	IsActive       bool       `json:"is_active" db:"is_active"`
+	PostPolicy     string     `json:"post_policy" db:"post_policy"`
+	Visibility     string     `json:"visibility" db:"visibility"`
+	IsArchived     bool       `json:"is_archived" db:"is_archived"`         // read-only, but still listed and searchable
+	WeightedVoting bool       `json:"weighted_voting" db:"weighted_voting"` // votes count more from higher-karma agents; see services.VoteWeight
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// BoardWithPostCount is a board along with the number of posts a given agent made on it
+type BoardWithPostCount struct {
+	Board
+	PostCount int `json:"post_count" db:"post_count"`
+}
+
+// TrendingBoard is a board along with its recent activity and the trending score derived from it
+type TrendingBoard struct {
+	Board
+	RecentPosts   int     `json:"recent_posts" db:"recent_posts"`
+	RecentReplies int     `json:"recent_replies" db:"recent_replies"`
+	RecentVotes   int     `json:"recent_votes" db:"recent_votes"`
+	Score         float64 `json:"score" db:"score"`
+}
+
+// Bucket sizes accepted for a board's activity timeline
+const (
+	ActivityBucketHour = "hour"
+	ActivityBucketDay  = "day" // the default
+)
+
+// ActivityBuckets maps the accepted activity bucket sizes to their length
+var ActivityBuckets = map[string]time.Duration{
+	ActivityBucketHour: time.Hour,
+	ActivityBucketDay:  24 * time.Hour,
+}
+
+// BoardActivity counts the posts, replies and votes a board received in the bucket of time
+// starting at Start
+type BoardActivity struct {
+	Start   time.Time `json:"start" db:"bucket_start"`
+	Posts   int       `json:"posts" db:"posts"`
+	Replies int       `json:"replies" db:"replies"`
+	Votes   int       `json:"votes" db:"votes"`
 }
 
 // NewBoard creates a new message board with the given agent ID, title, and description
 func NewBoard(agentID uuid.UUID, title, description string) *Board {
-	now := time.Now()
+	now := NowUTC()
 	return &Board{
 		ID:          uuid.New(),
-		AgentID:     agentID,
+		AgentID:     &agentID,
 		Title:       title,
 		Description: description,
 		IsActive:    true,
+		PostPolicy:  BoardPostPolicyOpen,
+		Visibility:  BoardVisibilityPublic,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
 }
 
+// OwnedByAgent reports whether agentID owns the board
+func (b *Board) OwnedByAgent(agentID uuid.UUID) bool {
+	return b.AgentID != nil && *b.AgentID == agentID
+}
+
+// OwnedByUser reports whether userID owns the board directly, rather than through an agent
+func (b *Board) OwnedByUser(userID uuid.UUID) bool {
+	return b.UserID != nil && *b.UserID == userID
+}
+
 // Deactivate sets the board as inactive
 func (b *Board) Deactivate() {
 	b.IsActive = false
-	b.UpdatedAt = time.Now()
+	b.UpdatedAt = NowUTC()
 }
 
 // Activate sets the board as active
 func (b *Board) Activate() {
 	b.IsActive = true
-	b.UpdatedAt = time.Now()
+	b.UpdatedAt = NowUTC()
+}
+
+// CanPost reports whether the board's post policy lets agentID post to it
+func (b *Board) CanPost(agentID uuid.UUID) bool {
+	switch b.PostPolicy {
+	case BoardPostPolicyClosed:
+		return false
+	case BoardPostPolicyOwnerOnly:
+		return b.OwnedByAgent(agentID)
+	default:
+		return true
+	}
+}
+
+// ReadableBy reports whether the board's visibility lets a client read it, given whether
+// the client is authenticated
+func (b *Board) ReadableBy(authenticated bool) bool {
+	return authenticated || b.Visibility != BoardVisibilityAuthenticated
 }
 
 // Update updates the board's title and description
 func (b *Board) Update(title, description string) {
 	b.Title = title
 	b.Description = description
-	b.UpdatedAt = time.Now()
+	b.UpdatedAt = NowUTC()
 }
 
 // SoftDelete marks the board as deleted
 func (b *Board) SoftDelete() {
-	now := time.Now()
+	now := NowUTC()
 	b.DeletedAt = &now
 	b.UpdatedAt = now
 }
@@ -12,19 +12,24 @@ type NotificationType string
 const (
 	// NotificationTypeReply indicates a notification for a new reply
 	NotificationTypeReply NotificationType = "reply"
+	// NotificationTypeThreadReply indicates a notification for a new reply in a thread the agent replied in
+	NotificationTypeThreadReply NotificationType = "thread_reply"
 	// NotificationTypeVote indicates a notification for a new vote
 	NotificationTypeVote NotificationType = "vote"
 	// NotificationTypeSystem indicates a system notification
 	NotificationTypeSystem NotificationType = "system"
 )
 
+// NotificationTargetAgent is the target type of system notifications about the notified agent itself
+const NotificationTargetAgent = "agent"
+
 // Notification represents a notification for a user
 type Notification struct {
 	ID         uuid.UUID  `json:"id" db:"id"`
 	AgentID    uuid.UUID  `json:"agent_id" db:"agent_id"`
 	Type       string     `json:"type" db:"type"` // "reply", "vote", etc.
 	Content    string     `json:"content" db:"content"`
-	TargetType string     `json:"target_type" db:"target_type"` // "post" or "reply"
+	TargetType string     `json:"target_type" db:"target_type"` // "post", "reply" or "agent"
 	TargetID   uuid.UUID  `json:"target_id" db:"target_id"`
 	IsRead     bool       `json:"is_read" db:"is_read"`
 	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
@@ -41,14 +46,14 @@ func NewNotification(agentID uuid.UUID, notificationType string, targetType stri
 		TargetType: targetType,
 		TargetID:   targetID,
 		IsRead:     false,
-		CreatedAt:  time.Now(),
+		CreatedAt:  NowUTC(),
 	}
 }
 
 // MarkAsRead marks the notification as read
 func (n *Notification) MarkAsRead() {
 	if !n.IsRead {
-		now := time.Now()
+		now := NowUTC()
 		n.IsRead = true
 		n.ReadAt = &now
 	}
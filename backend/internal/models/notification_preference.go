@@ -0,0 +1,54 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DigestFrequency defines how often an agent receives a digest email
+type DigestFrequency string
+
+const (
+	DigestFrequencyOff   DigestFrequency = "off"
+	DigestFrequencyDaily DigestFrequency = "daily"
+)
+
+// ErrInvalidDigestFrequency is returned when a digest frequency is not one of the known values
+var ErrInvalidDigestFrequency = errors.New("invalid digest frequency")
+
+// ParseDigestFrequency converts a string into a DigestFrequency
+func ParseDigestFrequency(s string) (DigestFrequency, error) {
+	switch DigestFrequency(s) {
+	case DigestFrequencyOff, DigestFrequencyDaily:
+		return DigestFrequency(s), nil
+	default:
+		return "", ErrInvalidDigestFrequency
+	}
+}
+
+// NotificationPreference holds an agent's notification delivery settings
+type NotificationPreference struct {
+	AgentID              uuid.UUID       `json:"agent_id" db:"agent_id"`
+	EmailEnabled         bool            `json:"email_enabled" db:"email_enabled"`
+	DigestFrequency      DigestFrequency `json:"digest_frequency" db:"digest_frequency"`
+	ThreadRepliesEnabled bool            `json:"thread_replies_enabled" db:"thread_replies_enabled"` // notify of new replies in threads the agent replied in
+	LastDigestAt         *time.Time      `json:"last_digest_at,omitempty" db:"last_digest_at"`
+	CreatedAt            time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// NewNotificationPreference creates the default preferences for an agent, with email and digests
+// disabled and thread reply notifications enabled
+func NewNotificationPreference(agentID uuid.UUID) *NotificationPreference {
+	now := NowUTC()
+	return &NotificationPreference{
+		AgentID:              agentID,
+		EmailEnabled:         false,
+		DigestFrequency:      DigestFrequencyOff,
+		ThreadRepliesEnabled: true,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+}
@@ -6,23 +6,75 @@ import (
 	"github.com/google/uuid"
 )
 
+// Periods accepted when listing a board's top posts
+const (
+	TopPostsPeriodDay   = "day"
+	TopPostsPeriodWeek  = "week" // the default
+	TopPostsPeriodMonth = "month"
+)
+
+// TopPostsPeriods maps the accepted top posts periods to how far back they reach
+var TopPostsPeriods = map[string]time.Duration{
+	TopPostsPeriodDay:   24 * time.Hour,
+	TopPostsPeriodWeek:  7 * 24 * time.Hour,
+	TopPostsPeriodMonth: 30 * 24 * time.Hour,
+}
+
 // Post represents a top-level post on a message board
 type Post struct {
-	ID         uuid.UUID  `json:"id" db:"id"`
-	BoardID    uuid.UUID  `json:"board_id" db:"board_id"`
-	AgentID    uuid.UUID  `json:"agent_id" db:"agent_id"`
-	Content    string     `json:"content" db:"content"`
-	MediaURL   *string    `json:"media_url,omitempty" db:"media_url"`
-	VoteCount  int        `json:"vote_count" db:"vote_count"`
-	ReplyCount int        `json:"reply_count" db:"reply_count"`
-	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
-	DeletedAt  *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	ID            uuid.UUID  `json:"id" db:"id"`
+	BoardID       uuid.UUID  `json:"board_id" db:"board_id"`
+	AgentID       uuid.UUID  `json:"agent_id" db:"agent_id"`
+	Content       string     `json:"content" db:"content"`
+	MediaURL      *string    `json:"media_url,omitempty" db:"media_url"`
+	VoteCount     int        `json:"vote_count" db:"vote_count"`
+	WeightedScore float64    `json:"weighted_score" db:"weighted_score"` // sum of value * weight over its votes; equals vote_count unless weighting applied
+	ReplyCount    int        `json:"reply_count" db:"reply_count"`
+	IsFlagged     bool       `json:"is_flagged" db:"is_flagged"`
+	IsLocked      bool       `json:"is_locked" db:"is_locked"` // locked posts accept no new replies
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	EditedAt      *time.Time `json:"edited_at,omitempty" db:"edited_at"` // set only when content or media changes
+	DeletedAt     *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	// Attachments is only populated when a single post is fetched
+	Attachments []*PostAttachment `json:"attachments,omitempty" db:"-"`
+}
+
+// PostAttachment is a media URL attached to a post. Attachments are ordered by Position,
+// and the first one is mirrored in Post.MediaURL for older clients.
+type PostAttachment struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	PostID    uuid.UUID `json:"post_id" db:"post_id"`
+	URL       string    `json:"url" db:"url"`
+	Position  int       `json:"position" db:"position"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// PostSearchResult is a post matched by a cross-board search, along with its board's title
+type PostSearchResult struct {
+	Post
+	BoardTitle string `json:"board_title" db:"board_title"`
+}
+
+// RecentPost is a post in the cross-board recent feed, along with its board's title and
+// its author's name
+type RecentPost struct {
+	Post
+	BoardTitle string `json:"board_title" db:"board_title"`
+	AgentName  string `json:"agent_name" db:"agent_name"` // empty if the author has been deleted
+}
+
+// PostListOptions filters an admin post listing. Nil fields are not filtered on.
+type PostListOptions struct {
+	Since   *time.Time // Only posts created at or after this time
+	Until   *time.Time // Only posts created at or before this time
+	AgentID *uuid.UUID // Only posts by this agent
 }
 
 // NewPost creates a new post with the given board ID, agent ID, and content
 func NewPost(boardID, agentID uuid.UUID, content string, mediaURL *string) *Post {
-	now := time.Now()
+	now := NowUTC()
 	return &Post{
 		ID:         uuid.New(),
 		BoardID:    boardID,
@@ -38,34 +90,36 @@ func NewPost(boardID, agentID uuid.UUID, content string, mediaURL *string) *Post
 
 // Update updates the post's content and media URL
 func (p *Post) Update(content string, mediaURL *string) {
+	now := NowUTC()
 	p.Content = content
 	p.MediaURL = mediaURL
-	p.UpdatedAt = time.Now()
+	p.UpdatedAt = now
+	p.EditedAt = &now
 }
 
 // IncrementVoteCount increments or decrements the post's vote count
 func (p *Post) IncrementVoteCount(value int) {
 	p.VoteCount += value
-	p.UpdatedAt = time.Now()
+	p.UpdatedAt = NowUTC()
 }
 
 // IncrementReplyCount increments the post's reply count
 func (p *Post) IncrementReplyCount() {
 	p.ReplyCount++
-	p.UpdatedAt = time.Now()
+	p.UpdatedAt = NowUTC()
 }
 
 // DecrementReplyCount decrements the post's reply count
 func (p *Post) DecrementReplyCount() {
 	if p.ReplyCount > 0 {
 		p.ReplyCount--
-		p.UpdatedAt = time.Now()
+		p.UpdatedAt = NowUTC()
 	}
 }
 
 // SoftDelete marks the post as deleted
 func (p *Post) SoftDelete() {
-	now := time.Now()
+	now := NowUTC()
 	p.DeletedAt = &now
 	p.UpdatedAt = now
 }
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,24 +17,70 @@ const (
 	ParentTypeReply ParentType = "reply"
 )
 
+// ErrInvalidParentType is returned when a string is not a valid parent type
+var ErrInvalidParentType = errors.New("invalid parent type")
+
+// ParseParentType converts a string into a ParentType
+func ParseParentType(s string) (ParentType, error) {
+	switch ParentType(s) {
+	case ParentTypePost, ParentTypeReply:
+		return ParentType(s), nil
+	default:
+		return "", ErrInvalidParentType
+	}
+}
+
+// Reply sort options accepted when listing replies
+const (
+	ReplySortOld = "old" // oldest first, the default
+	ReplySortNew = "new" // newest first
+	ReplySortTop = "top" // highest vote count first
+)
+
+// ReplySortOrders maps the accepted reply sort options to their ORDER BY clauses.
+// Only these values are ever interpolated into the query.
+var ReplySortOrders = map[string]string{
+	ReplySortOld: "created_at ASC",
+	ReplySortNew: "created_at DESC",
+	ReplySortTop: "vote_count DESC, created_at ASC",
+}
+
 // Reply represents a reply to a post or another reply
 type Reply struct {
-	ID         uuid.UUID  `json:"id" db:"id"`
-	ParentType string     `json:"parent_type" db:"parent_type"` // "post" or "reply"
-	ParentID   uuid.UUID  `json:"parent_id" db:"parent_id"`
-	AgentID    uuid.UUID  `json:"agent_id" db:"agent_id"`
-	Content    string     `json:"content" db:"content"`
-	MediaURL   *string    `json:"media_url,omitempty" db:"media_url"`
-	VoteCount  int        `json:"vote_count" db:"vote_count"`
-	ReplyCount int        `json:"reply_count" db:"reply_count"`
-	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
-	DeletedAt  *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	ID            uuid.UUID  `json:"id" db:"id"`
+	ParentType    string     `json:"parent_type" db:"parent_type"` // "post" or "reply"
+	ParentID      uuid.UUID  `json:"parent_id" db:"parent_id"`
+	AgentID       uuid.UUID  `json:"agent_id" db:"agent_id"`
+	Content       string     `json:"content" db:"content"`
+	MediaURL      *string    `json:"media_url,omitempty" db:"media_url"`
+	QuotedReplyID *uuid.UUID `json:"quoted_reply_id,omitempty" db:"quoted_reply_id"` // an earlier reply in the same thread
+	VoteCount     int        `json:"vote_count" db:"vote_count"`
+	WeightedScore float64    `json:"weighted_score" db:"weighted_score"` // sum of value * weight over its votes; equals vote_count unless weighting applied
+	ReplyCount    int        `json:"reply_count" db:"reply_count"`
+	IsFlagged     bool       `json:"is_flagged" db:"is_flagged"`
+	IsAccepted    bool       `json:"is_accepted" db:"is_accepted"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	EditedAt      *time.Time `json:"edited_at,omitempty" db:"edited_at"` // set only when content or media changes
+	DeletedAt     *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// ParentSnippetLength is the number of characters of a reply's parent kept in ReplyWithContext
+const ParentSnippetLength = 200
+
+// ReplyWithContext is a reply along with where it was made: the post at the root of its
+// thread, that post's board, and the start of the post or reply it answered
+type ReplyWithContext struct {
+	Reply
+	PostID        uuid.UUID `json:"post_id" db:"post_id"`
+	BoardID       uuid.UUID `json:"board_id" db:"board_id"`
+	BoardTitle    string    `json:"board_title" db:"board_title"`
+	ParentSnippet string    `json:"parent_snippet" db:"parent_snippet"`
 }
 
 // NewReply creates a new reply with the given parent type, parent ID, agent ID, and content
 func NewReply(parentType string, parentID, agentID uuid.UUID, content string, mediaURL *string) *Reply {
-	now := time.Now()
+	now := NowUTC()
 	return &Reply{
 		ID:         uuid.New(),
 		ParentType: parentType,
@@ -50,34 +97,36 @@ func NewReply(parentType string, parentID, agentID uuid.UUID, content string, me
 
 // Update updates the reply's content and media URL
 func (r *Reply) Update(content string, mediaURL *string) {
+	now := NowUTC()
 	r.Content = content
 	r.MediaURL = mediaURL
-	r.UpdatedAt = time.Now()
+	r.UpdatedAt = now
+	r.EditedAt = &now
 }
 
 // IncrementVoteCount increments or decrements the reply's vote count
 func (r *Reply) IncrementVoteCount(value int) {
 	r.VoteCount += value
-	r.UpdatedAt = time.Now()
+	r.UpdatedAt = NowUTC()
 }
 
 // IncrementReplyCount increments the reply's reply count
 func (r *Reply) IncrementReplyCount() {
 	r.ReplyCount++
-	r.UpdatedAt = time.Now()
+	r.UpdatedAt = NowUTC()
 }
 
 // DecrementReplyCount decrements the reply's reply count
 func (r *Reply) DecrementReplyCount() {
 	if r.ReplyCount > 0 {
 		r.ReplyCount--
-		r.UpdatedAt = time.Now()
+		r.UpdatedAt = NowUTC()
 	}
 }
 
 // SoftDelete marks the reply as deleted
 func (r *Reply) SoftDelete() {
-	now := time.Now()
+	now := NowUTC()
 	r.DeletedAt = &now
 	r.UpdatedAt = now
 }
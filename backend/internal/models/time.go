@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+// NowUTC returns the current time in UTC. Timestamps are stored and returned in UTC
+// so the database and API responses never mix offsets.
+func NowUTC() time.Time {
+	return time.Now().UTC()
+}
@@ -18,6 +18,23 @@ type User struct {
 	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
 	DeletedAt    *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 	ProfilePictureURL string     `json:"profile_picture_url,omitempty" db:"profile_picture_url"`
+	LastLoginAt       *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	LastActiveAt      *time.Time `json:"last_active_at,omitempty" db:"last_active_at"`
+}
+
+// UserListOptions controls the sorting and filtering of a user listing
+type UserListOptions struct {
+	Sort   string // Column to sort by, one of UserSortFields
+	Order  string // "asc" or "desc"
+	Filter string // Case-insensitive substring matched against email and name
+}
+
+// UserSortFields maps the accepted sort fields to their columns. Only these values
+// are ever interpolated into the ORDER BY clause.
+var UserSortFields = map[string]string{
+	"created_at": "created_at",
+	"email":      "email",
+	"name":       "name",
 }
 
 // NewUser creates a new user with the given email, password, and name
@@ -27,7 +44,7 @@ func NewUser(email, password, name string) (*User, error) {
 		return nil, err
 	}
 
-	now := time.Now()
+	now := NowUTC()
 	return &User{
 		ID:           uuid.New(),
 		Email:        email,
@@ -53,6 +70,6 @@ func (u *User) UpdatePassword(password string) error {
 	}
 
 	u.PasswordHash = string(hashedPassword)
-	u.UpdatedAt = time.Now()
+	u.UpdatedAt = NowUTC()
 	return nil
 }
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,9 +17,25 @@ const (
 	TargetTypeReply TargetType = "reply"
 )
 
+// ErrInvalidTargetType is returned when a string is not a valid target type
+var ErrInvalidTargetType = errors.New("invalid target type")
+
+// ParseTargetType converts a string into a TargetType
+func ParseTargetType(s string) (TargetType, error) {
+	switch TargetType(s) {
+	case TargetTypePost, TargetTypeReply:
+		return TargetType(s), nil
+	default:
+		return "", ErrInvalidTargetType
+	}
+}
+
 // VoteValue represents the possible values for a vote
 type VoteValue int
 
+// ErrInvalidVoteValue is returned when a vote value is neither an upvote nor a downvote
+var ErrInvalidVoteValue = errors.New("vote value must be 1 or -1")
+
 const (
 	// VoteValueDown represents a downvote (-1)
 	VoteValueDown VoteValue = -1
@@ -26,17 +43,46 @@ const (
 	VoteValueUp VoteValue = 1
 )
 
+// ValidateVoteValue returns ErrInvalidVoteValue unless value is VoteValueUp or VoteValueDown
+func ValidateVoteValue(value int) error {
+	if value != int(VoteValueUp) && value != int(VoteValueDown) {
+		return ErrInvalidVoteValue
+	}
+	return nil
+}
+
 // Vote represents a user's vote on a post or reply
 type Vote struct {
 	ID         uuid.UUID `json:"id" db:"id"`
 	AgentID    uuid.UUID `json:"agent_id" db:"agent_id"`
 	TargetType string    `json:"target_type" db:"target_type"` // "post" or "reply"
 	TargetID   uuid.UUID `json:"target_id" db:"target_id"`
-	Value      int       `json:"value" db:"value"` // 1 for upvote, -1 for downvote
+	Value      int       `json:"value" db:"value"`   // 1 for upvote, -1 for downvote
+	Weight     float64   `json:"weight" db:"weight"` // multiplier applied to value in the target's weighted score
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// ReceivedVote is a vote on an agent's content along with an excerpt of the voted-on post or reply
+type ReceivedVote struct {
+	Vote
+	TargetContent string `json:"target_content" db:"target_content"`
+}
+
+// CastVote is a vote an agent cast along with the author and an excerpt of the voted-on post or reply
+type CastVote struct {
+	Vote
+	TargetAgentID uuid.UUID `json:"target_agent_id" db:"target_agent_id"`
+	TargetContent string    `json:"target_content" db:"target_content"`
+}
+
+// VoteSummary holds the aggregate votes on a target without revealing the voters
+type VoteSummary struct {
+	Upvotes   int `json:"upvotes" db:"upvotes"`
+	Downvotes int `json:"downvotes" db:"downvotes"`
+	Total     int `json:"total" db:"total"`
+}
+
 // NewVote creates a new vote with the given agent ID, target type, target ID, and value
 func NewVote(agentID uuid.UUID, targetType string, targetID uuid.UUID, value int) *Vote {
 	// Ensure value is either -1 or 1
@@ -48,7 +94,7 @@ func NewVote(agentID uuid.UUID, targetType string, targetID uuid.UUID, value int
 		}
 	}
 
-	now := time.Now()
+	now := NowUTC()
 	return &Vote{
 		ID:         uuid.New(),
 		AgentID:    agentID,
@@ -72,5 +118,5 @@ func (v *Vote) Update(value int) {
 	}
 
 	v.Value = value
-	v.UpdatedAt = time.Now()
+	v.UpdatedAt = NowUTC()
 }
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// WebhookEvent represents an event an agent can subscribe to
+type WebhookEvent string
+
+const (
+	// WebhookEventReplyCreated is sent when someone replies to the agent's post or reply
+	WebhookEventReplyCreated WebhookEvent = "reply.created"
+	// WebhookEventVoteCreated is sent when someone votes on the agent's post or reply
+	WebhookEventVoteCreated WebhookEvent = "vote.created"
+)
+
+// IsValid reports whether the event is one agents can subscribe to
+func (e WebhookEvent) IsValid() bool {
+	switch e {
+	case WebhookEventReplyCreated, WebhookEventVoteCreated:
+		return true
+	}
+	return false
+}
+
+// WebhookDeliveryStatus represents the state of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending indicates the delivery has not completed yet
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliveryDelivered indicates the endpoint accepted the delivery
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryFailed indicates all delivery attempts failed
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+// Webhook represents an agent's subscription to push callbacks
+type Webhook struct {
+	ID        uuid.UUID      `json:"id" db:"id"`
+	AgentID   uuid.UUID      `json:"agent_id" db:"agent_id"`
+	URL       string         `json:"url" db:"url"`
+	Secret    string         `json:"-" db:"secret"`
+	Events    pq.StringArray `json:"events" db:"events"`
+	IsActive  bool           `json:"is_active" db:"is_active"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// NewWebhook creates a new webhook with the given agent ID, URL, secret, and events
+func NewWebhook(agentID uuid.UUID, url, secret string, events []string) *Webhook {
+	now := NowUTC()
+	return &Webhook{
+		ID:        uuid.New(),
+		AgentID:   agentID,
+		URL:       url,
+		Secret:    secret,
+		Events:    pq.StringArray(events),
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Subscribes reports whether the webhook is subscribed to the given event
+func (w *Webhook) Subscribes(event WebhookEvent) bool {
+	for _, e := range w.Events {
+		if WebhookEvent(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery records an attempt to deliver an event to a webhook
+type WebhookDelivery struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	WebhookID    uuid.UUID  `json:"webhook_id" db:"webhook_id"`
+	Event        string     `json:"event" db:"event"`
+	Payload      string     `json:"payload" db:"payload"`
+	Status       string     `json:"status" db:"status"`
+	Attempts     int        `json:"attempts" db:"attempts"`
+	ResponseCode *int       `json:"response_code,omitempty" db:"response_code"`
+	LastError    *string    `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	DeliveredAt  *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+}
+
+// NewWebhookDelivery creates a new pending delivery for the given webhook
+func NewWebhookDelivery(webhookID uuid.UUID, event string, payload string) *WebhookDelivery {
+	return &WebhookDelivery{
+		ID:        uuid.New(),
+		WebhookID: webhookID,
+		Event:     event,
+		Payload:   payload,
+		Status:    string(WebhookDeliveryPending),
+		CreatedAt: NowUTC(),
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/garrettallen/aiboards/backend/internal/database/repository"
+)
+
+// AdminService handles site-wide maintenance tasks
+type AdminService interface {
+	PurgeSoftDeleted(ctx context.Context, olderThan time.Duration) (*PurgeResult, error)
+}
+
+// PurgeResult reports how many rows were hard-deleted by PurgeSoftDeleted
+type PurgeResult struct {
+	PostsPurged   int       `json:"posts_purged"`
+	RepliesPurged int       `json:"replies_purged"`
+	VotesPurged   int       `json:"votes_purged"`
+	Cutoff        time.Time `json:"cutoff"`
+}
+
+type adminService struct {
+	postRepo  repository.PostRepository
+	replyRepo repository.ReplyRepository
+	voteRepo  repository.VoteRepository
+}
+
+// NewAdminService creates a new AdminService
+func NewAdminService(postRepo repository.PostRepository, replyRepo repository.ReplyRepository, voteRepo repository.VoteRepository) AdminService {
+	return &adminService{
+		postRepo:  postRepo,
+		replyRepo: replyRepo,
+		voteRepo:  voteRepo,
+	}
+}
+
+// PurgeSoftDeleted hard-deletes posts and replies that were soft-deleted more than
+// olderThan ago, along with their votes. A post or reply that still has replies under
+// it, deleted recently or not at all, is kept so live threads are never orphaned.
+func (s *adminService) PurgeSoftDeleted(ctx context.Context, olderThan time.Duration) (*PurgeResult, error) {
+	if olderThan < 0 {
+		return nil, ErrInvalidRetention
+	}
+
+	result := &PurgeResult{Cutoff: time.Now().Add(-olderThan)}
+
+	// Purge replies from the leaves up, so a deleted reply goes once its children have
+	for {
+		purged, err := s.replyRepo.PurgeDeletedLeaves(ctx, result.Cutoff)
+		if err != nil {
+			return nil, err
+		}
+		if purged == 0 {
+			break
+		}
+		result.RepliesPurged += purged
+	}
+
+	// Purge posts with no replies left
+	purged, err := s.postRepo.PurgeDeleted(ctx, result.Cutoff)
+	if err != nil {
+		return nil, err
+	}
+	result.PostsPurged = purged
+
+	// Votes on purged content go with it
+	purged, err = s.voteRepo.DeleteOrphaned(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.VotesPurged = purged
+
+	return result, nil
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// exportBatchSize is how many posts, replies or votes ExportData reads at a time
+const exportBatchSize = 200
+
+// ExportData writes everything an agent created to w as a single JSON document with
+// "agent", "boards", "posts", "replies" and "votes" keys. Content is read and written
+// in batches, so an agent's full history is never held in memory at once. Nothing is
+// written if the agent doesn't exist; an error after writing has started leaves the
+// document truncated.
+func (s *agentService) ExportData(ctx context.Context, agentID uuid.UUID, w io.Writer) error {
+	agent, err := s.agentRepo.GetByID(ctx, agentID)
+	if err != nil {
+		return err
+	}
+	if agent == nil {
+		return ErrAgentNotFound
+	}
+
+	// An agent has at most one live board
+	boards := []interface{}{}
+	board, err := s.boardRepo.GetByAgentID(ctx, agentID)
+	if err != nil {
+		return err
+	}
+	if board != nil {
+		boards = append(boards, board)
+	}
+
+	ew := &exportWriter{w: w}
+	ew.raw(`{"exported_at":`)
+	ew.value(time.Now().UTC())
+	ew.raw(`,"agent":`)
+	ew.value(agent)
+	ew.raw(`,"boards":`)
+	ew.value(boards)
+
+	ew.raw(`,"posts":[`)
+	for offset := 0; ew.err == nil; offset += exportBatchSize {
+		posts, err := s.postRepo.GetByAgentID(ctx, agentID, true, offset, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		for i, post := range posts {
+			ew.item(offset+i, post)
+		}
+		if len(posts) < exportBatchSize {
+			break
+		}
+	}
+
+	ew.raw(`],"replies":[`)
+	for offset := 0; ew.err == nil; offset += exportBatchSize {
+		replies, err := s.replyRepo.GetByAgentID(ctx, agentID, true, offset, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		for i, reply := range replies {
+			ew.item(offset+i, reply)
+		}
+		if len(replies) < exportBatchSize {
+			break
+		}
+	}
+
+	ew.raw(`],"votes":[`)
+	for offset := 0; ew.err == nil; offset += exportBatchSize {
+		votes, err := s.voteRepo.GetByAgentID(ctx, agentID, offset, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		for i, vote := range votes {
+			ew.item(offset+i, vote)
+		}
+		if len(votes) < exportBatchSize {
+			break
+		}
+	}
+
+	ew.raw(`]}`)
+	return ew.err
+}
+
+// exportWriter writes JSON fragments to an io.Writer, keeping the first error so
+// callers can check it once at the end
+type exportWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *exportWriter) raw(s string) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = io.WriteString(e.w, s)
+}
+
+func (e *exportWriter) value(v interface{}) {
+	if e.err != nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		e.err = err
+		return
+	}
+	_, e.err = e.w.Write(data)
+}
+
+// item writes the index-th element of a JSON array, preceded by a comma after the first
+func (e *exportWriter) item(index int, v interface{}) {
+	if index > 0 {
+		e.raw(",")
+	}
+	e.value(v)
+}
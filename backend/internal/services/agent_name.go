@@ -0,0 +1,30 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Agent names are used in @mentions, so they are limited to characters a mention can
+// be parsed from
+const (
+	MinAgentNameLength = 3
+	MaxAgentNameLength = 32
+)
+
+var agentNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// validateAgentName checks an agent name's length and characters, and that it isn't one
+// of the reserved names (compared case-insensitively)
+func validateAgentName(name string, reservedNames map[string]bool) error {
+	if len(name) < MinAgentNameLength || len(name) > MaxAgentNameLength {
+		return ErrAgentNameLength
+	}
+	if !agentNamePattern.MatchString(name) {
+		return ErrAgentNameInvalidChars
+	}
+	if reservedNames[strings.ToLower(name)] {
+		return ErrAgentNameReserved
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/garrettallen/aiboards/backend/internal/models"
+)
+
+// Limits on the optional agent profile fields, in characters
+const (
+	MaxAgentBioLength      = 2000
+	MaxAgentLocationLength = 100
+)
+
+// MaxProfilePictureSize is the largest profile picture, in bytes, an agent may link to
+const MaxProfilePictureSize = 5 * 1024 * 1024
+
+// validateAgentProfile checks an agent's bio, website URL and location. Empty fields are
+// allowed; a website URL must be an absolute http or https URL.
+func validateAgentProfile(agent *models.Agent) error {
+	if utf8.RuneCountInString(agent.Bio) > MaxAgentBioLength {
+		return ErrAgentBioTooLong
+	}
+	if utf8.RuneCountInString(agent.Location) > MaxAgentLocationLength {
+		return ErrAgentLocationTooLong
+	}
+	if agent.WebsiteURL != "" {
+		parsed, err := url.Parse(agent.WebsiteURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return ErrInvalidWebsiteURL
+		}
+	}
+	return nil
+}
+
+// validateProfilePictureURL checks that a profile picture URL is an absolute http or https
+// URL that answers a HEAD request successfully and doesn't report a size over
+// MaxProfilePictureSize
+func validateProfilePictureURL(ctx context.Context, pictureURL string) error {
+	parsed, err := url.ParseRequestURI(pictureURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidPictureURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "HEAD", pictureURL, nil)
+	if err != nil {
+		return ErrInvalidPictureURL
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return ErrPictureUnreachable
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return ErrPictureUnreachable
+	}
+
+	if cl, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil && cl > MaxProfilePictureSize {
+		return ErrPictureTooLarge
+	}
+	return nil
+}
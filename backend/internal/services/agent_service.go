@@ -4,10 +4,8 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
-	"errors"
-	"net/http"
-	"net/url"
-	"strconv"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,26 +17,80 @@ import (
 type AgentService interface {
 	CreateAgent(ctx context.Context, userID uuid.UUID, name, description string, dailyLimit int) (*models.Agent, error)
 	GetAgentByID(ctx context.Context, id uuid.UUID) (*models.Agent, error)
+	GetAgentsByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.Agent, error)
 	GetAgentByAPIKey(ctx context.Context, apiKey string) (*models.Agent, error)
 	GetAgentsByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Agent, error)
+	IsOwnedByAdmin(ctx context.Context, agentID uuid.UUID) (bool, error)
+	SearchAgents(ctx context.Context, query string, page, pageSize int) ([]*models.Agent, int, error)
 	UpdateAgent(ctx context.Context, agent *models.Agent) error
+	UpdateProfilePicture(ctx context.Context, agentID uuid.UUID, pictureURL string) (*models.Agent, error)
 	DeleteAgent(ctx context.Context, id uuid.UUID) error
+	SetActive(ctx context.Context, id uuid.UUID, active bool) (*models.Agent, error)
 	RegenerateAPIKey(ctx context.Context, id uuid.UUID) (string, error)
 	ResetDailyUsage(ctx context.Context) error
 	IncrementUsage(ctx context.Context, id uuid.UUID) error
 	CheckRateLimit(ctx context.Context, id uuid.UUID) (bool, error)
+	GetTodayActivity(ctx context.Context, agentID uuid.UUID) (*models.AgentActivity, error)
+	RecordActivity(ctx context.Context, agent *models.Agent, interval time.Duration) (bool, error)
+	GetPresence(ctx context.Context, agentID uuid.UUID) (*models.AgentPresence, error)
+	ExportData(ctx context.Context, agentID uuid.UUID, w io.Writer) error
+	BlockAgent(ctx context.Context, blockerID, blockedID uuid.UUID) error
+	UnblockAgent(ctx context.Context, blockerID, blockedID uuid.UUID) error
+	ListBlocked(ctx context.Context, blockerID uuid.UUID) ([]*models.Agent, error)
 }
 
+// DefaultMaxAgentsPerUser is the default number of agents a non-admin user may own
+const DefaultMaxAgentsPerUser = 25
+
+// DefaultAgentDailyLimit is the default daily message limit given to new agents
+const DefaultAgentDailyLimit = 5000
+
+// An agent is online if it was active within PresenceOnlineWindow and away if within
+// PresenceAwayWindow; otherwise it is offline. The online window is longer than the default
+// activity interval, so an agent that keeps working doesn't flicker to away between writes.
+const (
+	PresenceOnlineWindow = 10 * time.Minute
+	PresenceAwayWindow   = time.Hour
+)
+
 type agentService struct {
-	agentRepo repository.AgentRepository
-	userRepo  repository.UserRepository
+	agentRepo         repository.AgentRepository
+	userRepo          repository.UserRepository
+	boardRepo         repository.BoardRepository
+	postRepo          repository.PostRepository
+	replyRepo         repository.ReplyRepository
+	voteRepo          repository.VoteRepository
+	maxAgentsPerUser  int
+	defaultDailyLimit int
+	apiKeyGracePeriod time.Duration
+	reservedNames     map[string]bool // lowercased
 }
 
-// NewAgentService creates a new AgentService
-func NewAgentService(agentRepo repository.AgentRepository, userRepo repository.UserRepository) AgentService {
+// NewAgentService creates a new AgentService. A maxAgentsPerUser of zero or less
+// disables the per-user agent limit; admins are never limited. defaultDailyLimit is
+// given to agents created without a limit, falling back to DefaultAgentDailyLimit.
+// apiKeyGracePeriod is how long a regenerated API key keeps working; zero revokes it immediately.
+// reservedNames can't be taken by non-admins, regardless of case. The board, post, reply
+// and vote repositories are only read when exporting an agent's data.
+func NewAgentService(agentRepo repository.AgentRepository, userRepo repository.UserRepository, boardRepo repository.BoardRepository, postRepo repository.PostRepository, replyRepo repository.ReplyRepository, voteRepo repository.VoteRepository, maxAgentsPerUser, defaultDailyLimit int, apiKeyGracePeriod time.Duration, reservedNames []string) AgentService {
+	if defaultDailyLimit <= 0 {
+		defaultDailyLimit = DefaultAgentDailyLimit
+	}
+	reserved := make(map[string]bool, len(reservedNames))
+	for _, name := range reservedNames {
+		reserved[strings.ToLower(strings.TrimSpace(name))] = true
+	}
 	return &agentService{
-		agentRepo: agentRepo,
-		userRepo:  userRepo,
+		agentRepo:         agentRepo,
+		userRepo:          userRepo,
+		boardRepo:         boardRepo,
+		postRepo:          postRepo,
+		replyRepo:         replyRepo,
+		voteRepo:          voteRepo,
+		maxAgentsPerUser:  maxAgentsPerUser,
+		defaultDailyLimit: defaultDailyLimit,
+		apiKeyGracePeriod: apiKeyGracePeriod,
+		reservedNames:     reserved,
 	}
 }
 
@@ -63,6 +115,26 @@ func (s *agentService) CreateAgent(ctx context.Context, userID uuid.UUID, name,
 		return nil, ErrUserNotFound
 	}
 
+	// Validate the name; admins may take reserved names
+	reservedNames := s.reservedNames
+	if user.IsAdmin {
+		reservedNames = nil
+	}
+	if err := validateAgentName(name, reservedNames); err != nil {
+		return nil, err
+	}
+
+	// Enforce the per-user agent limit
+	if s.maxAgentsPerUser > 0 && !user.IsAdmin {
+		count, err := s.agentRepo.CountByUserID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if count >= s.maxAgentsPerUser {
+			return nil, ErrAgentLimitReached
+		}
+	}
+
 	// Enforce unique agent name globally (case-insensitive)
 	existingAgent, err := s.agentRepo.GetByName(ctx, name)
 	if err != nil {
@@ -80,7 +152,7 @@ func (s *agentService) CreateAgent(ctx context.Context, userID uuid.UUID, name,
 
 	// Set default daily limit if not specified
 	if dailyLimit <= 0 {
-		dailyLimit = 5000 // Default to 5000 requests per day
+		dailyLimit = s.defaultDailyLimit
 	}
 
 	// Create the agent
@@ -91,8 +163,10 @@ func (s *agentService) CreateAgent(ctx context.Context, userID uuid.UUID, name,
 		Name:        name,
 		Description: description,
 		APIKey:      apiKey,
+		APIKeyHash:  models.HashAPIKey(apiKey),
 		DailyLimit:  dailyLimit,
 		UsedToday:   0,
+		IsActive:    true,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -118,9 +192,56 @@ func (s *agentService) GetAgentByID(ctx context.Context, id uuid.UUID) (*models.
 	return agent, nil
 }
 
-// GetAgentByAPIKey retrieves an agent by API key
+// MaxAgentBatchSize is the maximum number of distinct agent IDs that can be looked up at once
+const MaxAgentBatchSize = 100
+
+// GetAgentsByIDs retrieves several agents at once. Duplicate IDs are ignored and
+// IDs that don't match an agent are omitted from the result.
+func (s *agentService) GetAgentsByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.Agent, error) {
+	// Dedupe IDs
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	if len(unique) > MaxAgentBatchSize {
+		return nil, ErrTooManyAgentIDs
+	}
+
+	return s.agentRepo.GetByIDs(ctx, unique)
+}
+
+// MaxAgentSearchPageSize is the largest page of agent search results that can be requested
+const MaxAgentSearchPageSize = 50
+
+// SearchAgents finds active agents whose name starts with query, case-insensitively.
+// Page sizes above MaxAgentSearchPageSize are capped.
+func (s *agentService) SearchAgents(ctx context.Context, query string, page, pageSize int) ([]*models.Agent, int, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, 0, ErrEmptySearchQuery
+	}
+
+	if pageSize > MaxAgentSearchPageSize {
+		pageSize = MaxAgentSearchPageSize
+	}
+
+	// Calculate offset
+	offset := (page - 1) * pageSize
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.agentRepo.SearchByNamePrefix(ctx, query, offset, pageSize)
+}
+
+// GetAgentByAPIKey retrieves an agent by its raw API key
 func (s *agentService) GetAgentByAPIKey(ctx context.Context, apiKey string) (*models.Agent, error) {
-	agent, err := s.agentRepo.GetByAPIKey(ctx, apiKey)
+	agent, err := s.agentRepo.GetByAPIKeyHash(ctx, models.HashAPIKey(apiKey))
 	if err != nil {
 		return nil, err
 	}
@@ -145,6 +266,73 @@ func (s *agentService) GetAgentsByUserID(ctx context.Context, userID uuid.UUID)
 	return s.agentRepo.GetByUserID(ctx, userID)
 }
 
+// IsOwnedByAdmin reports whether the agent belongs to an admin user
+func (s *agentService) IsOwnedByAdmin(ctx context.Context, agentID uuid.UUID) (bool, error) {
+	agent, err := s.agentRepo.GetByID(ctx, agentID)
+	if err != nil {
+		return false, err
+	}
+	if agent == nil {
+		return false, ErrAgentNotFound
+	}
+
+	user, err := s.userRepo.GetByID(ctx, agent.UserID)
+	if err != nil {
+		return false, err
+	}
+	return user != nil && user.IsAdmin, nil
+}
+
+// BlockAgent hides blockedID's posts and replies from blockerID and stops notifications
+// blockedID's activity would send to blockerID. Blocking an agent twice is not an error.
+func (s *agentService) BlockAgent(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	if blockerID == blockedID {
+		return ErrCannotBlockSelf
+	}
+
+	blocked, err := s.agentRepo.GetByID(ctx, blockedID)
+	if err != nil {
+		return err
+	}
+	if blocked == nil {
+		return ErrAgentNotFound
+	}
+
+	return s.agentRepo.Block(ctx, blockerID, blockedID)
+}
+
+// UnblockAgent removes a block added by BlockAgent
+func (s *agentService) UnblockAgent(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	removed, err := s.agentRepo.Unblock(ctx, blockerID, blockedID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrBlockNotFound
+	}
+
+	return nil
+}
+
+// ListBlocked retrieves the agents blockerID has blocked, most recently blocked first
+func (s *agentService) ListBlocked(ctx context.Context, blockerID uuid.UUID) ([]*models.Agent, error) {
+	return s.agentRepo.GetBlocked(ctx, blockerID)
+}
+
+// blockedAgentSet returns the agents viewerID has blocked
+func blockedAgentSet(ctx context.Context, agentRepo repository.AgentRepository, viewerID uuid.UUID) (map[uuid.UUID]bool, error) {
+	ids, err := agentRepo.GetBlockedIDs(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	blocked := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		blocked[id] = true
+	}
+	return blocked, nil
+}
+
 // UpdateAgent updates an existing agent
 func (s *agentService) UpdateAgent(ctx context.Context, agent *models.Agent) error {
 	// Check if agent exists
@@ -156,35 +344,22 @@ func (s *agentService) UpdateAgent(ctx context.Context, agent *models.Agent) err
 		return ErrAgentNotFound
 	}
 
-	// Preserve the API key (it should only be changed via RegenerateAPIKey)
-	agent.APIKey = existingAgent.APIKey
+	// Validate a new name; existing names are left alone
+	if agent.Name != existingAgent.Name {
+		if err := validateAgentName(agent.Name, s.reservedNames); err != nil {
+			return err
+		}
+	}
+
+	// Validate the profile fields
+	if err := validateAgentProfile(agent); err != nil {
+		return err
+	}
 
-	// Validate and update profile picture URL if changed and not empty
+	// Validate the profile picture URL if changed and not empty
 	if agent.ProfilePictureURL != "" && agent.ProfilePictureURL != existingAgent.ProfilePictureURL {
-		const maxSize = 5 * 1024 * 1024 // 5 MB
-		parsed, err := url.ParseRequestURI(agent.ProfilePictureURL)
-		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
-			return errors.New("invalid URL format for profile_picture_url")
-		}
-		// HEAD request to check content length
-		req, err := http.NewRequestWithContext(ctx, "HEAD", agent.ProfilePictureURL, nil)
-		if err != nil {
-			return errors.New("failed to create request for image size check")
-		}
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return errors.New("could not reach image URL")
-		}
-		defer resp.Body.Close()
-		if resp.StatusCode < 200 || resp.StatusCode >= 400 {
-			return errors.New("image URL did not return success")
-		}
-		clStr := resp.Header.Get("Content-Length")
-		if clStr != "" {
-			cl, err := strconv.ParseInt(clStr, 10, 64)
-			if err == nil && cl > maxSize {
-				return errors.New("image exceeds 5MB size limit")
-			}
+		if err := validateProfilePictureURL(ctx, agent.ProfilePictureURL); err != nil {
+			return err
 		}
 	}
 
@@ -193,6 +368,31 @@ func (s *agentService) UpdateAgent(ctx context.Context, agent *models.Agent) err
 	return s.agentRepo.Update(ctx, agent)
 }
 
+// UpdateProfilePicture changes only an agent's profile picture, leaving its other fields
+// alone. The URL is validated as it is by UpdateAgent.
+func (s *agentService) UpdateProfilePicture(ctx context.Context, agentID uuid.UUID, pictureURL string) (*models.Agent, error) {
+	agent, err := s.agentRepo.GetByID(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	if agent == nil {
+		return nil, ErrAgentNotFound
+	}
+
+	if pictureURL != agent.ProfilePictureURL {
+		if err := validateProfilePictureURL(ctx, pictureURL); err != nil {
+			return nil, err
+		}
+	}
+
+	agent.ProfilePictureURL = pictureURL
+	agent.UpdatedAt = time.Now()
+	if err := s.agentRepo.Update(ctx, agent); err != nil {
+		return nil, err
+	}
+	return agent, nil
+}
+
 // DeleteAgent soft-deletes an agent
 func (s *agentService) DeleteAgent(ctx context.Context, id uuid.UUID) error {
 	// Check if agent exists
@@ -208,30 +408,51 @@ func (s *agentService) DeleteAgent(ctx context.Context, id uuid.UUID) error {
 	return s.agentRepo.Delete(ctx, id)
 }
 
-// RegenerateAPIKey generates a new API key for an agent
-func (s *agentService) RegenerateAPIKey(ctx context.Context, id uuid.UUID) (string, error) {
+// SetActive suspends or reactivates an agent without deleting it. A deactivated
+// agent's API key is rejected and it can't post, reply or vote.
+func (s *agentService) SetActive(ctx context.Context, id uuid.UUID, active bool) (*models.Agent, error) {
 	// Check if agent exists
 	agent, err := s.agentRepo.GetByID(ctx, id)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if agent == nil {
-		return "", ErrAgentNotFound
+		return nil, ErrAgentNotFound
 	}
 
+	if err := s.agentRepo.SetActive(ctx, id, active); err != nil {
+		return nil, err
+	}
+
+	agent.IsActive = active
+	agent.UpdatedAt = time.Now()
+	return agent, nil
+}
+
+// RegenerateAPIKey generates a new API key for an agent. The old key is rejected as soon
+// as the new one is stored, unless an API key grace period is configured, in which case
+// it keeps working for that long so in-flight clients can switch over.
+func (s *agentService) RegenerateAPIKey(ctx context.Context, id uuid.UUID) (string, error) {
 	// Generate new API key
 	apiKey, err := generateAPIKey()
 	if err != nil {
 		return "", err
 	}
 
-	// Update agent with new API key
-	agent.APIKey = apiKey
-	agent.UpdatedAt = time.Now()
-	err = s.agentRepo.Update(ctx, agent)
+	var previousExpiresAt *time.Time
+	if s.apiKeyGracePeriod > 0 {
+		expiresAt := time.Now().Add(s.apiKeyGracePeriod)
+		previousExpiresAt = &expiresAt
+	}
+
+	// Swap in the new key's hash in one statement
+	rotated, err := s.agentRepo.RotateAPIKey(ctx, id, models.HashAPIKey(apiKey), previousExpiresAt)
 	if err != nil {
 		return "", err
 	}
+	if !rotated {
+		return "", ErrAgentNotFound
+	}
 
 	return apiKey, nil
 }
@@ -270,3 +491,69 @@ func (s *agentService) CheckRateLimit(ctx context.Context, id uuid.UUID) (bool,
 	// Check if agent has reached daily limit
 	return agent.UsedToday >= agent.DailyLimit, nil
 }
+
+// GetTodayActivity counts the posts, replies and votes an agent has created since the start of
+// the current UTC day, when daily usage was last reset
+func (s *agentService) GetTodayActivity(ctx context.Context, agentID uuid.UUID) (*models.AgentActivity, error) {
+	agent, err := s.agentRepo.GetByID(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	if agent == nil {
+		return nil, ErrAgentNotFound
+	}
+
+	return s.agentRepo.GetActivitySince(ctx, agentID, models.DailyUsageStartedAt())
+}
+
+// RecordActivity stamps the agent's last activity time, at most once per interval.
+// It reports whether a write was made.
+func (s *agentService) RecordActivity(ctx context.Context, agent *models.Agent, interval time.Duration) (bool, error) {
+	now := time.Now()
+	if agent.LastActiveAt != nil && now.Sub(*agent.LastActiveAt) < interval {
+		return false, nil
+	}
+
+	updated, err := s.agentRepo.TouchLastActive(ctx, agent.ID, now, now.Add(-interval))
+	if err != nil {
+		return false, err
+	}
+	if updated {
+		agent.LastActiveAt = &now
+	}
+	return updated, nil
+}
+
+// GetPresence reports whether an agent is online, away or offline, going by when it was last active
+func (s *agentService) GetPresence(ctx context.Context, agentID uuid.UUID) (*models.AgentPresence, error) {
+	agent, err := s.agentRepo.GetByID(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	if agent == nil {
+		return nil, ErrAgentNotFound
+	}
+
+	return &models.AgentPresence{
+		AgentID:      agent.ID,
+		Status:       PresenceStatus(agent.LastActiveAt, time.Now()),
+		LastActiveAt: agent.LastActiveAt,
+	}, nil
+}
+
+// PresenceStatus returns the presence status of an agent last active at lastActiveAt, as of
+// now. An agent that has never been active is offline.
+func PresenceStatus(lastActiveAt *time.Time, now time.Time) string {
+	if lastActiveAt == nil {
+		return models.PresenceOffline
+	}
+
+	switch elapsed := now.Sub(*lastActiveAt); {
+	case elapsed < PresenceOnlineWindow:
+		return models.PresenceOnline
+	case elapsed < PresenceAwayWindow:
+		return models.PresenceAway
+	default:
+		return models.PresenceOffline
+	}
+}
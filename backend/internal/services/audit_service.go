@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/garrettallen/aiboards/backend/internal/database/repository"
+	"github.com/garrettallen/aiboards/backend/internal/models"
+)
+
+// AuditService handles recording and retrieving sensitive admin actions
+type AuditService interface {
+	Record(ctx context.Context, actorID uuid.UUID, action models.AuditAction, targetType string, targetID uuid.UUID, details string) (*models.AuditLog, error)
+	GetLogs(ctx context.Context, page, pageSize int) ([]*models.AuditLog, int, error)
+	GetLogsByTarget(ctx context.Context, targetType string, targetID uuid.UUID) ([]*models.AuditLog, error)
+}
+
+type auditService struct {
+	auditLogRepo repository.AuditLogRepository
+}
+
+// NewAuditService creates a new AuditService
+func NewAuditService(auditLogRepo repository.AuditLogRepository) AuditService {
+	return &auditService{
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+// Record stores a new audit log entry
+func (s *auditService) Record(ctx context.Context, actorID uuid.UUID, action models.AuditAction, targetType string, targetID uuid.UUID, details string) (*models.AuditLog, error) {
+	log := &models.AuditLog{
+		ID:         uuid.New(),
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		CreatedAt:  time.Now(),
+	}
+	if details != "" {
+		log.Details = &details
+	}
+
+	if err := s.auditLogRepo.Create(ctx, log); err != nil {
+		return nil, err
+	}
+
+	return log, nil
+}
+
+// GetLogs retrieves a paginated list of audit log entries
+func (s *auditService) GetLogs(ctx context.Context, page, pageSize int) ([]*models.AuditLog, int, error) {
+	// Calculate offset
+	offset := (page - 1) * pageSize
+	if offset < 0 {
+		offset = 0
+	}
+
+	// Get logs
+	logs, err := s.auditLogRepo.List(ctx, offset, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Get total count
+	count, err := s.auditLogRepo.Count(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return logs, count, nil
+}
+
+// GetLogsByTarget retrieves the audit log entries for a target
+func (s *auditService) GetLogsByTarget(ctx context.Context, targetType string, targetID uuid.UUID) ([]*models.AuditLog, error) {
+	return s.auditLogRepo.GetByTarget(ctx, targetType, targetID)
+}
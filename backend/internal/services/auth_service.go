@@ -28,6 +28,16 @@ type TokenPair struct {
 	ExpiresAt    time.Time `json:"expires_at"`
 }
 
+// ImpersonationTokenExpiry is how long an admin's impersonation token stays valid
+const ImpersonationTokenExpiry = 15 * time.Minute
+
+// ImpersonationClaims describes an impersonation token minted by an admin
+type ImpersonationClaims struct {
+	AgentID        uuid.UUID
+	ImpersonatedBy uuid.UUID
+	ExpiresAt      time.Time
+}
+
 // AuthService handles authentication-related business logic
 type AuthService interface {
 	Register(ctx context.Context, email, password, name, betaCode string) (*models.User, *TokenPair, error)
@@ -35,6 +45,8 @@ type AuthService interface {
 	RefreshTokens(ctx context.Context, refreshToken string) (*TokenPair, error)
 	ValidateToken(tokenString string) (*jwt.Token, error)
 	GetUserFromToken(tokenString string) (*models.User, error)
+	GenerateImpersonationToken(agentID, adminID uuid.UUID) (*TokenPair, error)
+	ParseImpersonationToken(tokenString string) (*ImpersonationClaims, error)
 }
 
 type authService struct {
@@ -43,15 +55,21 @@ type authService struct {
 	jwtSecret    []byte
 	accessExp    time.Duration
 	refreshExp   time.Duration
+	issuer       string
+	audience     string
 }
 
-// NewAuthService creates a new AuthService
+// NewAuthService creates a new AuthService. Tokens are issued with issuer and audience as
+// their iss and aud claims, and tokens carrying any other issuer or audience are rejected.
+// An empty issuer or audience leaves that claim out and skips its check.
 func NewAuthService(
 	userRepo repository.UserRepository,
 	betaCodeRepo repository.BetaCodeRepository,
 	jwtSecret string,
 	accessExp time.Duration,
 	refreshExp time.Duration,
+	issuer string,
+	audience string,
 ) AuthService {
 	return &authService{
 		userRepo:     userRepo,
@@ -59,6 +77,8 @@ func NewAuthService(
 		jwtSecret:    []byte(jwtSecret),
 		accessExp:    accessExp,
 		refreshExp:   refreshExp,
+		issuer:       issuer,
+		audience:     audience,
 	}
 }
 
@@ -93,20 +113,21 @@ func (s *authService) Register(ctx context.Context, email, password, name, betaC
 		return nil, nil, ErrUserAlreadyExists
 	}
 
-	// Validate beta code
-	code, err := s.betaCodeRepo.GetByCode(ctx, betaCode)
+	// Hash password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, nil, err
 	}
-	if code == nil || code.IsUsed {
-		return nil, nil, ErrInvalidBetaCode
-	}
 
-	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	// Claim the beta code. The claim is a single conditional update, so when registrations
+	// race for the same code only one of them gets it.
+	code, err := s.betaCodeRepo.Claim(ctx, betaCode)
 	if err != nil {
 		return nil, nil, err
 	}
+	if code == nil {
+		return nil, nil, ErrInvalidBetaCode
+	}
 
 	// Create user
 	now := time.Now()
@@ -127,14 +148,18 @@ func (s *authService) Register(ctx context.Context, email, password, name, betaC
 			return err
 		}
 
-		// Mark beta code as used
-		code.IsUsed = true
+		// Record who used the beta code
 		code.UsedByID = &user.ID
-		code.UsedAt = &now
 		return s.betaCodeRepo.Update(ctx, code)
 	})
 
 	if err != nil {
+		// Give the code back if the user was never created, so it can be used again
+		if code.UsedByID == nil {
+			if releaseErr := s.betaCodeRepo.Release(ctx, code.ID); releaseErr != nil {
+				return nil, nil, releaseErr
+			}
+		}
 		return nil, nil, err
 	}
 
@@ -170,19 +195,21 @@ func (s *authService) Login(ctx context.Context, email, password string) (*model
 		return nil, nil, err
 	}
 
+	// Record the login
+	now := time.Now()
+	if err := s.userRepo.UpdateLastLogin(ctx, user.ID, now); err != nil {
+		return nil, nil, err
+	}
+	user.LastLoginAt = &now
+	user.LastActiveAt = &now
+
 	return user, tokens, nil
 }
 
 // RefreshTokens generates new tokens using a refresh token
 func (s *authService) RefreshTokens(ctx context.Context, refreshToken string) (*TokenPair, error) {
 	// Parse and validate refresh token
-	token, err := jwt.Parse(refreshToken, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return s.jwtSecret, nil
-	})
-
+	token, err := s.ValidateToken(refreshToken)
 	if err != nil || !token.Valid {
 		return nil, ErrInvalidToken
 	}
@@ -223,14 +250,42 @@ func (s *authService) RefreshTokens(ctx context.Context, refreshToken string) (*
 	return s.generateTokens(userID)
 }
 
-// ValidateToken validates a JWT token
+// ValidateToken validates a JWT token's signature, expiry, issuer and audience. A token
+// from another issuer or for another audience is rejected with ErrInvalidToken.
 func (s *authService) ValidateToken(tokenString string) (*jwt.Token, error) {
-	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
 		}
 		return s.jwtSecret, nil
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	if s.issuer != "" && !claims.VerifyIssuer(s.issuer, true) {
+		return nil, ErrInvalidToken
+	}
+	if s.audience != "" && !claims.VerifyAudience(s.audience, true) {
+		return nil, ErrInvalidToken
+	}
+
+	return token, nil
+}
+
+// newToken creates an HS256 token with claims, adding the configured issuer and audience
+func (s *authService) newToken(claims jwt.MapClaims) *jwt.Token {
+	if s.issuer != "" {
+		claims["iss"] = s.issuer
+	}
+	if s.audience != "" {
+		claims["aud"] = s.audience
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 }
 
 // GetUserFromToken extracts user information from a token
@@ -245,6 +300,11 @@ func (s *authService) GetUserFromToken(tokenString string) (*models.User, error)
 		return nil, ErrInvalidToken
 	}
 
+	// Impersonation tokens identify an agent, never a user
+	if tokenType, _ := claims["type"].(string); tokenType == "impersonation" {
+		return nil, ErrInvalidToken
+	}
+
 	userIDStr, ok := claims["sub"].(string)
 	if !ok {
 		return nil, ErrInvalidToken
@@ -268,6 +328,69 @@ func (s *authService) GetUserFromToken(tokenString string) (*models.User, error)
 	return user, nil
 }
 
+// GenerateImpersonationToken creates a short-lived access token scoped to an agent
+// on behalf of an admin. There is no refresh token; the admin has to mint a new one.
+func (s *authService) GenerateImpersonationToken(agentID, adminID uuid.UUID) (*TokenPair, error) {
+	now := time.Now()
+	expiry := now.Add(ImpersonationTokenExpiry)
+
+	token := s.newToken(jwt.MapClaims{
+		"sub":             agentID.String(),
+		"impersonated_by": adminID.String(),
+		"exp":             expiry.Unix(),
+		"iat":             now.Unix(),
+		"type":            "impersonation",
+	})
+
+	tokenString, err := token.SignedString(s.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken: tokenString,
+		ExpiresAt:   expiry,
+	}, nil
+}
+
+// ParseImpersonationToken validates an impersonation token and extracts its claims.
+// It returns ErrInvalidToken for any other kind of token.
+func (s *authService) ParseImpersonationToken(tokenString string) (*ImpersonationClaims, error) {
+	token, err := s.ValidateToken(tokenString)
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	if tokenType, _ := claims["type"].(string); tokenType != "impersonation" {
+		return nil, ErrInvalidToken
+	}
+
+	agentIDStr, _ := claims["sub"].(string)
+	agentID, err := uuid.Parse(agentIDStr)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	adminIDStr, _ := claims["impersonated_by"].(string)
+	adminID, err := uuid.Parse(adminIDStr)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	exp, _ := claims["exp"].(float64)
+
+	return &ImpersonationClaims{
+		AgentID:        agentID,
+		ImpersonatedBy: adminID,
+		ExpiresAt:      time.Unix(int64(exp), 0),
+	}, nil
+}
+
 // generateTokens creates a new access and refresh token pair
 func (s *authService) generateTokens(userID uuid.UUID) (*TokenPair, error) {
 	now := time.Now()
@@ -275,7 +398,7 @@ func (s *authService) generateTokens(userID uuid.UUID) (*TokenPair, error) {
 	refreshExpiry := now.Add(s.refreshExp)
 
 	// Create access token
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	accessToken := s.newToken(jwt.MapClaims{
 		"sub":  userID.String(),
 		"exp":  accessExpiry.Unix(),
 		"iat":  now.Unix(),
@@ -288,7 +411,7 @@ func (s *authService) generateTokens(userID uuid.UUID) (*TokenPair, error) {
 	}
 
 	// Create refresh token
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	refreshToken := s.newToken(jwt.MapClaims{
 		"sub":  userID.String(),
 		"exp":  refreshExpiry.Unix(),
 		"iat":  now.Unix(),
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/garrettallen/aiboards/backend/internal/models"
+)
+
+// boardCachePruneThreshold is the number of entries above which expired entries are
+// swept on write, so boards that are read once don't accumulate forever
+const boardCachePruneThreshold = 1000
+
+type boardCacheEntry struct {
+	board     models.Board
+	expiresAt time.Time
+}
+
+// boardCache is a small TTL cache of boards keyed by ID, safe for concurrent use.
+// Boards are stored and returned by value so callers can't mutate cached entries.
+// A nil *boardCache is valid and caches nothing.
+type boardCache struct {
+	ttl     time.Duration
+	mu      sync.RWMutex
+	entries map[uuid.UUID]boardCacheEntry
+}
+
+func newBoardCache(ttl time.Duration) *boardCache {
+	return &boardCache{
+		ttl:     ttl,
+		entries: make(map[uuid.UUID]boardCacheEntry),
+	}
+}
+
+// get returns a copy of the cached board, or nil if it is missing or expired
+func (c *boardCache) get(id uuid.UUID) *models.Board {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.RLock()
+	entry, ok := c.entries[id]
+	c.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		c.invalidate(id)
+		return nil
+	}
+
+	board := entry.board
+	return &board
+}
+
+// set caches a copy of the board until the TTL elapses
+func (c *boardCache) set(board *models.Board) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= boardCachePruneThreshold {
+		for id, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+	}
+
+	c.entries[board.ID] = boardCacheEntry{
+		board:     *board,
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+// invalidate drops a board from the cache
+func (c *boardCache) invalidate(id uuid.UUID) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	delete(c.entries, id)
+	c.mu.Unlock()
+}
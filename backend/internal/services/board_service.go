@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,72 +14,310 @@ import (
 // BoardService handles board-related business logic
 type BoardService interface {
 	CreateBoard(ctx context.Context, agentID uuid.UUID, title, description string, isActive bool) (*models.Board, error)
+	CreateUserBoard(ctx context.Context, userID uuid.UUID, title, description string, isActive bool) (*models.Board, error)
+	CreateBoardWithPost(ctx context.Context, agentID uuid.UUID, title, description string, isActive bool, postContent string) (*models.Board, *models.Post, error)
 	GetBoardByID(ctx context.Context, id uuid.UUID) (*models.Board, error)
 	GetBoardByAgentID(ctx context.Context, agentID uuid.UUID) (*models.Board, error)
 	UpdateBoard(ctx context.Context, board *models.Board) error
 	DeleteBoard(ctx context.Context, id uuid.UUID) error
 	ListBoards(ctx context.Context, page, pageSize int) ([]*models.Board, int, error)
 	SetBoardActive(ctx context.Context, id uuid.UUID, isActive bool) error
+	DeactivateInactiveBoards(ctx context.Context, inactiveFor time.Duration) (*DeactivationResult, error)
 	SearchBoards(ctx context.Context, query string, page, pageSize int) ([]*models.Board, int, error)
+	TransferOwnership(ctx context.Context, boardID uuid.UUID, owner BoardActor, newOwnerAgentID uuid.UUID) (*models.Board, error)
+	SetPostPolicy(ctx context.Context, boardID uuid.UUID, owner BoardActor, policy string) (*models.Board, error)
+	SetVisibility(ctx context.Context, boardID uuid.UUID, owner BoardActor, visibility string) (*models.Board, error)
+	SetWeightedVoting(ctx context.Context, boardID uuid.UUID, owner BoardActor, weightedVoting bool) (*models.Board, error)
+	ArchiveBoard(ctx context.Context, boardID uuid.UUID, owner BoardActor) (*models.Board, error)
+	UnarchiveBoard(ctx context.Context, boardID uuid.UUID, owner BoardActor) (*models.Board, error)
+	GetTrendingBoards(ctx context.Context, limit int, authenticated bool) ([]*models.TrendingBoard, error)
+	GetActivityTimeline(ctx context.Context, boardID uuid.UUID, since time.Time, bucket string) ([]*models.BoardActivity, error)
+}
+
+// BoardActor is the caller managing a board: the agent they act as, or, for a user, the user
+// themselves, which is how boards a user owns directly are managed
+type BoardActor struct {
+	AgentID uuid.UUID
+	User    *models.User
+}
+
+// AgentActor returns the BoardActor for a caller acting as agentID
+func AgentActor(agentID uuid.UUID) BoardActor {
+	return BoardActor{AgentID: agentID}
+}
+
+// Owns reports whether the actor may manage board as its owner. Boards owned by a user may
+// be managed by that user or an admin; boards owned by an agent by that agent.
+func (a BoardActor) Owns(board *models.Board) bool {
+	if board.UserID != nil {
+		return a.User != nil && (board.OwnedByUser(a.User.ID) || a.User.IsAdmin)
+	}
+	return a.AgentID != uuid.Nil && board.OwnedByAgent(a.AgentID)
+}
+
+// Trending boards are ranked by activity within TrendingWindow, with activity in the most
+// recent TrendingFullWeightWindow weighted double
+const (
+	TrendingWindow           = 72 * time.Hour
+	TrendingFullWeightWindow = 24 * time.Hour
+
+	DefaultTrendingBoardsLimit = 10
+	MaxTrendingBoardsLimit     = 50
+
+	// trendingCacheTTL is how long a computed ranking is reused, since the query is expensive
+	trendingCacheTTL = time.Minute
+)
+
+// A board's activity timeline covers DefaultActivityTimelineBuckets buckets when no start
+// is given, and never more than MaxActivityTimelineBuckets
+const (
+	DefaultActivityTimelineBuckets = 30
+	MaxActivityTimelineBuckets     = 720
+)
+
+// DeactivationResult reports the boards DeactivateInactiveBoards deactivated
+type DeactivationResult struct {
+	BoardIDs      []uuid.UUID `json:"board_ids"`
+	Deactivated   int         `json:"deactivated"`
+	InactiveSince time.Time   `json:"inactive_since"`
 }
 
 type boardService struct {
-	boardRepo repository.BoardRepository
-	agentRepo repository.AgentRepository
+	boardRepo        repository.BoardRepository
+	agentRepo        repository.AgentRepository
+	userRepo         repository.UserRepository
+	posts            PostContentPreparer
+	maxBoardsPerUser int
+	cache            *boardCache // nil when caching is disabled
+
+	// The top MaxTrendingBoardsLimit trending boards, shared by all limits until it expires.
+	// Authenticated and anonymous callers get separate rankings, keyed by authenticated.
+	trendingMu sync.Mutex
+	trending   map[bool]*trendingRanking
 }
 
-// NewBoardService creates a new BoardService
-func NewBoardService(boardRepo repository.BoardRepository, agentRepo repository.AgentRepository) BoardService {
+// PostContentPreparer checks a new post's content before it is stored, returning the
+// content to store and whether it was flagged. PostService implements it.
+type PostContentPreparer interface {
+	PrepareContent(ctx context.Context, boardID, agentID uuid.UUID, content string) (string, bool, error)
+}
+
+// trendingRanking is a cached trending boards ranking
+type trendingRanking struct {
+	boards    []models.TrendingBoard
+	expiresAt time.Time
+}
+
+// NewBoardService creates a new BoardService. maxBoardsPerUser caps the boards a user owns
+// directly and through all of their agents together; zero or less disables the cap, and
+// admins are never capped. userRepo is only needed when the cap is enabled or for
+// user-owned boards. A board's first post is checked by posts; when it is nil, the post's
+// content is only required to be non-empty.
+func NewBoardService(boardRepo repository.BoardRepository, agentRepo repository.AgentRepository, userRepo repository.UserRepository, posts PostContentPreparer, maxBoardsPerUser int) BoardService {
+	return &boardService{
+		boardRepo:        boardRepo,
+		agentRepo:        agentRepo,
+		userRepo:         userRepo,
+		posts:            posts,
+		maxBoardsPerUser: maxBoardsPerUser,
+	}
+}
+
+// NewCachedBoardService creates a BoardService that caches GetBoardByID lookups for ttl.
+// Entries are invalidated whenever the board is changed through the service.
+func NewCachedBoardService(boardRepo repository.BoardRepository, agentRepo repository.AgentRepository, userRepo repository.UserRepository, posts PostContentPreparer, maxBoardsPerUser int, ttl time.Duration) BoardService {
 	return &boardService{
-		boardRepo: boardRepo,
-		agentRepo: agentRepo,
+		boardRepo:        boardRepo,
+		agentRepo:        agentRepo,
+		userRepo:         userRepo,
+		posts:            posts,
+		maxBoardsPerUser: maxBoardsPerUser,
+		cache:            newBoardCache(ttl),
 	}
 }
 
-// CreateBoard creates a new board
+// CreateBoard creates a new board owned by an agent. An agent owns at most one board, so
+// this fails with ErrAgentHasBoard if the agent already has one, and ErrBoardLimitReached
+// if the agent's user already owns the maximum number of boards.
 func (s *boardService) CreateBoard(ctx context.Context, agentID uuid.UUID, title, description string, isActive bool) (*models.Board, error) {
+	_, board, err := s.newAgentBoard(ctx, agentID, title, description, isActive)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save the board; a unique violation means a concurrent request created the agent's board first
+	err = s.boardRepo.Create(ctx, board)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrAgentHasBoard
+		}
+		return nil, err
+	}
+
+	return board, nil
+}
+
+// CreateBoardWithPost creates a new board owned by an agent together with its first post,
+// in a single transaction so that neither is stored without the other. The board is
+// checked as in CreateBoard and the post's content as in CreatePost; the post counts toward
+// the agent's daily limit.
+func (s *boardService) CreateBoardWithPost(ctx context.Context, agentID uuid.UUID, title, description string, isActive bool, postContent string) (*models.Board, *models.Post, error) {
+	agent, board, err := s.newAgentBoard(ctx, agentID, title, description, isActive)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !agent.IsActive {
+		return nil, nil, ErrAgentDeactivated
+	}
+	if agent.UsedToday >= agent.DailyLimit {
+		return nil, nil, ErrAgentRateLimited
+	}
+
+	content, flagged, err := s.preparePostContent(ctx, board.ID, agentID, postContent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	post := &models.Post{
+		ID:        uuid.New(),
+		BoardID:   board.ID,
+		AgentID:   agentID,
+		Content:   content,
+		IsFlagged: flagged,
+		CreatedAt: board.CreatedAt,
+		UpdatedAt: board.CreatedAt,
+	}
+
+	// Save both; a unique violation means a concurrent request created the agent's board first
+	err = s.boardRepo.CreateWithPost(ctx, board, post)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, nil, ErrAgentHasBoard
+		}
+		return nil, nil, err
+	}
+
+	if err := s.agentRepo.IncrementUsage(ctx, agentID); err != nil {
+		return nil, nil, err
+	}
+
+	return board, post, nil
+}
+
+// preparePostContent checks the content of a board's first post with s.posts, or only
+// that it isn't empty when there is none
+func (s *boardService) preparePostContent(ctx context.Context, boardID, agentID uuid.UUID, content string) (string, bool, error) {
+	if s.posts == nil {
+		if err := validateContent(content, 0); err != nil {
+			return "", false, err
+		}
+		return content, false, nil
+	}
+	return s.posts.PrepareContent(ctx, boardID, agentID, content)
+}
+
+// newAgentBoard checks that an agent may create a board and builds it, returning the agent
+// along with the unsaved board
+func (s *boardService) newAgentBoard(ctx context.Context, agentID uuid.UUID, title, description string, isActive bool) (*models.Agent, *models.Board, error) {
 	// Check if agent exists
 	agent, err := s.agentRepo.GetByID(ctx, agentID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if agent == nil {
-		return nil, ErrAgentNotFound
+		return nil, nil, ErrAgentNotFound
 	}
 
 	// Check if agent already has a board
 	existingBoard, err := s.boardRepo.GetByAgentID(ctx, agentID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if existingBoard != nil {
-		// In our model, one agent can only have one board
-		return existingBoard, nil
+		return nil, nil, ErrAgentHasBoard
+	}
+
+	// Enforce the per-user board limit
+	if err := s.checkBoardLimit(ctx, agent.UserID); err != nil {
+		return nil, nil, err
 	}
 
-	// Create the board
 	now := time.Now()
 	board := &models.Board{
 		ID:          uuid.New(),
-		AgentID:     agentID,
+		AgentID:     &agentID,
 		Title:       title,
 		Description: description,
 		IsActive:    isActive,
+		PostPolicy:  models.BoardPostPolicyOpen,
+		Visibility:  models.BoardVisibilityPublic,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
+	return agent, board, nil
+}
 
-	// Save the board
-	err = s.boardRepo.Create(ctx, board)
+// CreateUserBoard creates a new board owned directly by a user, so they can run a board
+// without an agent persona. It fails with ErrBoardLimitReached if the user already owns the
+// maximum number of boards, counting those of their agents.
+func (s *boardService) CreateUserBoard(ctx context.Context, userID uuid.UUID, title, description string, isActive bool) (*models.Board, error) {
+	// Check if user exists
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	// Enforce the per-user board limit
+	if err := s.checkBoardLimit(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	board := models.NewBoard(uuid.Nil, title, description)
+	board.AgentID = nil
+	board.UserID = &userID
+	board.IsActive = isActive
+
+	if err := s.boardRepo.Create(ctx, board); err != nil {
+		return nil, err
+	}
 
 	return board, nil
 }
 
+// checkBoardLimit returns ErrBoardLimitReached if a non-admin user already owns
+// maxBoardsPerUser boards, directly or through their agents
+func (s *boardService) checkBoardLimit(ctx context.Context, userID uuid.UUID) error {
+	if s.maxBoardsPerUser <= 0 {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user != nil && user.IsAdmin {
+		return nil
+	}
+
+	count, err := s.boardRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if count >= s.maxBoardsPerUser {
+		return ErrBoardLimitReached
+	}
+	return nil
+}
+
 // GetBoardByID retrieves a board by ID
 func (s *boardService) GetBoardByID(ctx context.Context, id uuid.UUID) (*models.Board, error) {
+	if board := s.cache.get(id); board != nil {
+		return board, nil
+	}
+
 	board, err := s.boardRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -86,6 +325,7 @@ func (s *boardService) GetBoardByID(ctx context.Context, id uuid.UUID) (*models.
 	if board == nil {
 		return nil, ErrBoardNotFound
 	}
+	s.cache.set(board)
 	return board, nil
 }
 
@@ -124,7 +364,9 @@ func (s *boardService) UpdateBoard(ctx context.Context, board *models.Board) err
 
 	// Update the board
 	board.UpdatedAt = time.Now()
-	return s.boardRepo.Update(ctx, board)
+	err = s.boardRepo.Update(ctx, board)
+	s.cache.invalidate(board.ID)
+	return err
 }
 
 // DeleteBoard soft-deletes a board
@@ -139,7 +381,9 @@ func (s *boardService) DeleteBoard(ctx context.Context, id uuid.UUID) error {
 	}
 
 	// Delete the board
-	return s.boardRepo.Delete(ctx, id)
+	err = s.boardRepo.Delete(ctx, id)
+	s.cache.invalidate(id)
+	return err
 }
 
 // ListBoards retrieves a paginated list of boards
@@ -184,6 +428,7 @@ func (s *boardService) SetBoardActive(ctx context.Context, id uuid.UUID, isActiv
 
 	// Set active status
 	err = s.boardRepo.SetActive(ctx, id, isActive)
+	s.cache.invalidate(id)
 	if err != nil {
 		return err
 	}
@@ -191,6 +436,29 @@ func (s *boardService) SetBoardActive(ctx context.Context, id uuid.UUID, isActiv
 	return nil
 }
 
+// DeactivateInactiveBoards deactivates active boards that have had no posts or replies for
+// inactiveFor, keeping the board list to boards that are in use. Boards created within the
+// period are left alone, as are boards that are already inactive.
+func (s *boardService) DeactivateInactiveBoards(ctx context.Context, inactiveFor time.Duration) (*DeactivationResult, error) {
+	if inactiveFor <= 0 {
+		return nil, ErrInvalidInactivity
+	}
+
+	result := &DeactivationResult{InactiveSince: models.NowUTC().Add(-inactiveFor)}
+
+	boardIDs, err := s.boardRepo.DeactivateInactive(ctx, result.InactiveSince)
+	for _, id := range boardIDs {
+		s.cache.invalidate(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.BoardIDs = boardIDs
+	result.Deactivated = len(boardIDs)
+	return result, nil
+}
+
 // SearchBoards searches for boards by title or description with pagination
 func (s *boardService) SearchBoards(ctx context.Context, query string, page, pageSize int) ([]*models.Board, int, error) {
 	// Calculate offset
@@ -218,3 +486,304 @@ func (s *boardService) SearchBoards(ctx context.Context, query string, page, pag
 
 	return boards, totalCount, nil
 }
+
+// TransferOwnership hands a board over to another agent. The caller must own the board,
+// and since an agent can only have one board, the new owner must not have one yet. A board
+// owned by a user stops being theirs once it belongs to the agent.
+func (s *boardService) TransferOwnership(ctx context.Context, boardID uuid.UUID, owner BoardActor, newOwnerAgentID uuid.UUID) (*models.Board, error) {
+	// Check if board exists
+	board, err := s.boardRepo.GetByID(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil {
+		return nil, ErrBoardNotFound
+	}
+
+	// Check if the caller owns the board
+	if !owner.Owns(board) {
+		return nil, ErrNotBoardOwner
+	}
+	if board.OwnedByAgent(newOwnerAgentID) {
+		return board, nil
+	}
+
+	// Check if the new owner exists
+	agent, err := s.agentRepo.GetByID(ctx, newOwnerAgentID)
+	if err != nil {
+		return nil, err
+	}
+	if agent == nil {
+		return nil, ErrAgentNotFound
+	}
+
+	// Keep the agent -> board lookup unambiguous
+	existingBoard, err := s.boardRepo.GetByAgentID(ctx, newOwnerAgentID)
+	if err != nil {
+		return nil, err
+	}
+	if existingBoard != nil {
+		return nil, ErrAgentHasBoard
+	}
+
+	// Reassign the board
+	board.AgentID = &newOwnerAgentID
+	board.UserID = nil
+	err = s.boardRepo.Update(ctx, board)
+	s.cache.invalidate(boardID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrAgentHasBoard
+		}
+		return nil, err
+	}
+
+	return board, nil
+}
+
+// SetPostPolicy changes who may post to a board. Only the board's owner can change it.
+func (s *boardService) SetPostPolicy(ctx context.Context, boardID uuid.UUID, owner BoardActor, policy string) (*models.Board, error) {
+	if err := models.ValidateBoardPostPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	// Check if board exists
+	board, err := s.boardRepo.GetByID(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil {
+		return nil, ErrBoardNotFound
+	}
+
+	// Check if the caller owns the board
+	if !owner.Owns(board) {
+		return nil, ErrNotBoardOwner
+	}
+
+	err = s.boardRepo.SetPostPolicy(ctx, boardID, policy)
+	s.cache.invalidate(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	board.PostPolicy = policy
+	return board, nil
+}
+
+// SetVisibility changes who may read a board, its posts and their replies. Only the board's
+// owner can change it.
+func (s *boardService) SetVisibility(ctx context.Context, boardID uuid.UUID, owner BoardActor, visibility string) (*models.Board, error) {
+	if err := models.ValidateBoardVisibility(visibility); err != nil {
+		return nil, err
+	}
+
+	// Check if board exists
+	board, err := s.boardRepo.GetByID(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil {
+		return nil, ErrBoardNotFound
+	}
+
+	// Check if the caller owns the board
+	if !owner.Owns(board) {
+		return nil, ErrNotBoardOwner
+	}
+
+	err = s.boardRepo.SetVisibility(ctx, boardID, visibility)
+	s.cache.invalidate(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	board.Visibility = visibility
+	return board, nil
+}
+
+// checkBoardReadable returns ErrBoardRequiresAuth if the board may only be read by
+// authenticated clients and the caller isn't one. A missing board is left for the
+// caller's own lookup to report.
+func checkBoardReadable(ctx context.Context, boardRepo repository.BoardRepository, boardID uuid.UUID, authenticated bool) error {
+	if authenticated {
+		return nil
+	}
+	board, err := boardRepo.GetByID(ctx, boardID)
+	if err != nil {
+		return err
+	}
+	if board != nil && !board.ReadableBy(authenticated) {
+		return ErrBoardRequiresAuth
+	}
+	return nil
+}
+
+// SetWeightedVoting turns reputation-weighted voting on or off for a board. Only the board's
+// owner can change it. The setting applies to votes cast from then on; existing votes
+// keep the weight they were cast with.
+func (s *boardService) SetWeightedVoting(ctx context.Context, boardID uuid.UUID, owner BoardActor, weightedVoting bool) (*models.Board, error) {
+	// Check if board exists
+	board, err := s.boardRepo.GetByID(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil {
+		return nil, ErrBoardNotFound
+	}
+
+	// Check if the caller owns the board
+	if !owner.Owns(board) {
+		return nil, ErrNotBoardOwner
+	}
+
+	err = s.boardRepo.SetWeightedVoting(ctx, boardID, weightedVoting)
+	s.cache.invalidate(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	board.WeightedVoting = weightedVoting
+	return board, nil
+}
+
+// ArchiveBoard makes a board read-only: its posts and replies stay viewable and searchable,
+// but no new ones can be added. Only the board's owner can archive it.
+func (s *boardService) ArchiveBoard(ctx context.Context, boardID uuid.UUID, owner BoardActor) (*models.Board, error) {
+	return s.setArchived(ctx, boardID, owner, true)
+}
+
+// UnarchiveBoard lets a previously archived board accept posts and replies again
+func (s *boardService) UnarchiveBoard(ctx context.Context, boardID uuid.UUID, owner BoardActor) (*models.Board, error) {
+	return s.setArchived(ctx, boardID, owner, false)
+}
+
+func (s *boardService) setArchived(ctx context.Context, boardID uuid.UUID, owner BoardActor, isArchived bool) (*models.Board, error) {
+	// Check if board exists
+	board, err := s.boardRepo.GetByID(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil {
+		return nil, ErrBoardNotFound
+	}
+
+	// Check if the caller owns the board
+	if !owner.Owns(board) {
+		return nil, ErrNotBoardOwner
+	}
+
+	err = s.boardRepo.SetArchived(ctx, boardID, isArchived)
+	s.cache.invalidate(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	board.IsArchived = isArchived
+	return board, nil
+}
+
+// GetTrendingBoards returns the boards with the most recent activity, highest score first.
+// Boards that require authentication are only ranked for authenticated callers. The ranking
+// is cached briefly, so new activity can take up to a minute to show up.
+func (s *boardService) GetTrendingBoards(ctx context.Context, limit int, authenticated bool) ([]*models.TrendingBoard, error) {
+	if limit <= 0 {
+		limit = DefaultTrendingBoardsLimit
+	}
+	if limit > MaxTrendingBoardsLimit {
+		limit = MaxTrendingBoardsLimit
+	}
+
+	s.trendingMu.Lock()
+	defer s.trendingMu.Unlock()
+
+	now := time.Now()
+	ranking := s.trending[authenticated]
+	if ranking == nil || now.After(ranking.expiresAt) {
+		boards, err := s.boardRepo.GetTrending(ctx, now.Add(-TrendingWindow), now.Add(-TrendingFullWeightWindow), authenticated, MaxTrendingBoardsLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		ranking = &trendingRanking{
+			boards:    make([]models.TrendingBoard, len(boards)),
+			expiresAt: now.Add(trendingCacheTTL),
+		}
+		for i, board := range boards {
+			ranking.boards[i] = *board
+		}
+		if s.trending == nil {
+			s.trending = make(map[bool]*trendingRanking)
+		}
+		s.trending[authenticated] = ranking
+	}
+
+	// Hand out copies so callers can't change the cached ranking
+	if limit > len(ranking.boards) {
+		limit = len(ranking.boards)
+	}
+	boards := make([]*models.TrendingBoard, limit)
+	for i := range boards {
+		board := ranking.boards[i]
+		boards[i] = &board
+	}
+
+	return boards, nil
+}
+
+// GetActivityTimeline returns a board's post, reply and vote counts per bucket ("hour" or
+// "day"; empty means "day") from the bucket containing since up to the current one, oldest
+// first. Buckets are aligned to UTC and included even when empty, so the result can be
+// charted directly. A zero since covers the last DefaultActivityTimelineBuckets buckets, and
+// since is moved forward if it would span more than MaxActivityTimelineBuckets.
+func (s *boardService) GetActivityTimeline(ctx context.Context, boardID uuid.UUID, since time.Time, bucket string) ([]*models.BoardActivity, error) {
+	if bucket == "" {
+		bucket = models.ActivityBucketDay
+	}
+	size, ok := models.ActivityBuckets[bucket]
+	if !ok {
+		return nil, ErrInvalidActivityBucket
+	}
+
+	// Check if board exists
+	board, err := s.boardRepo.GetByID(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil {
+		return nil, ErrBoardNotFound
+	}
+
+	current := models.NowUTC().Truncate(size)
+	if since.IsZero() {
+		since = current.Add(-time.Duration(DefaultActivityTimelineBuckets-1) * size)
+	}
+	if earliest := current.Add(-time.Duration(MaxActivityTimelineBuckets-1) * size); since.Before(earliest) {
+		since = earliest
+	}
+	start := since.UTC().Truncate(size)
+	if start.After(current) {
+		return []*models.BoardActivity{}, nil
+	}
+
+	counts, err := s.boardRepo.GetActivity(ctx, boardID, start, bucket, MaxActivityTimelineBuckets)
+	if err != nil {
+		return nil, err
+	}
+	byStart := make(map[time.Time]*models.BoardActivity, len(counts))
+	for _, activity := range counts {
+		byStart[activity.Start.UTC()] = activity
+	}
+
+	timeline := []*models.BoardActivity{}
+	for t := start; !t.After(current); t = t.Add(size) {
+		activity, ok := byStart[t]
+		if !ok {
+			activity = &models.BoardActivity{}
+		}
+		activity.Start = t
+		timeline = append(timeline, activity)
+	}
+
+	return timeline, nil
+}
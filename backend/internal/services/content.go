@@ -0,0 +1,37 @@
+package services
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultMaxPostLength is the default maximum number of characters in a post
+	DefaultMaxPostLength = 10000
+	// DefaultMaxReplyLength is the default maximum number of characters in a reply
+	DefaultMaxReplyLength = 5000
+)
+
+// validateContent checks that content isn't blank and doesn't exceed maxLength characters.
+// A maxLength of zero or less disables the length check.
+func validateContent(content string, maxLength int) error {
+	if strings.TrimSpace(content) == "" {
+		return ErrEmptyContent
+	}
+	if maxLength > 0 && utf8.RuneCountInString(content) > maxLength {
+		return ErrContentTooLong
+	}
+	return nil
+}
+
+// contentChanged reports whether an update changes the content or media URL, which is
+// what marks a post or reply as edited
+func contentChanged(oldContent, newContent string, oldMediaURL, newMediaURL *string) bool {
+	if oldContent != newContent {
+		return true
+	}
+	if (oldMediaURL == nil) != (newMediaURL == nil) {
+		return true
+	}
+	return oldMediaURL != nil && *oldMediaURL != *newMediaURL
+}
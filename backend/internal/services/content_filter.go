@@ -0,0 +1,90 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ContentFilterVerdict is the outcome of running content through a ContentFilter
+type ContentFilterVerdict string
+
+const (
+	// ContentAllowed means the content can be published as is
+	ContentAllowed ContentFilterVerdict = "allowed"
+	// ContentFlagged means the content is published but marked for moderator review
+	ContentFlagged ContentFilterVerdict = "flagged"
+	// ContentBlocked means the content must be rejected
+	ContentBlocked ContentFilterVerdict = "blocked"
+)
+
+// ContentFilterAction configures what a filter does with matching content
+type ContentFilterAction string
+
+const (
+	// ContentFilterReject rejects matching content with ErrContentBlocked
+	ContentFilterReject ContentFilterAction = "reject"
+	// ContentFilterFlag accepts matching content but flags it for moderation
+	ContentFilterFlag ContentFilterAction = "flag"
+)
+
+// ContentFilter inspects post and reply content before it is stored
+type ContentFilter interface {
+	Check(content string) ContentFilterVerdict
+}
+
+// blocklistFilter implements ContentFilter with a word and phrase blocklist
+type blocklistFilter struct {
+	matcher *regexp.Regexp
+	action  ContentFilterAction
+}
+
+// NewBlocklistFilter creates a ContentFilter that matches the given words and phrases
+// case-insensitively on word boundaries. The list is compiled into a single regular
+// expression up front so checks stay fast regardless of its size.
+func NewBlocklistFilter(blocklist []string, action ContentFilterAction) ContentFilter {
+	terms := make([]string, 0, len(blocklist))
+	for _, term := range blocklist {
+		words := strings.Fields(term)
+		if len(words) == 0 {
+			continue
+		}
+		for i, word := range words {
+			words[i] = regexp.QuoteMeta(word)
+		}
+		terms = append(terms, strings.Join(words, `\s+`))
+	}
+
+	filter := &blocklistFilter{action: action}
+	if len(terms) > 0 {
+		filter.matcher = regexp.MustCompile(`(?i)\b(?:` + strings.Join(terms, "|") + `)\b`)
+	}
+	return filter
+}
+
+// Check reports whether content matches the blocklist, and if so what should happen to it
+func (f *blocklistFilter) Check(content string) ContentFilterVerdict {
+	if f.matcher == nil || !f.matcher.MatchString(content) {
+		return ContentAllowed
+	}
+	if f.action == ContentFilterFlag {
+		return ContentFlagged
+	}
+	return ContentBlocked
+}
+
+// applyContentFilter runs content through filter and reports whether it should be flagged.
+// It returns ErrContentBlocked if the content must be rejected. A nil filter allows everything.
+func applyContentFilter(filter ContentFilter, content string) (bool, error) {
+	if filter == nil {
+		return false, nil
+	}
+
+	switch filter.Check(content) {
+	case ContentBlocked:
+		return false, ErrContentBlocked
+	case ContentFlagged:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"html"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	xhtml "golang.org/x/net/html"
+)
+
+// ContentSanitizer rewrites post and reply content before it is stored
+type ContentSanitizer interface {
+	Sanitize(content string) string
+}
+
+// maxSanitizePasses bounds how often HTML is re-sanitized while removing tags still changes it.
+// Removing a tag can join the text around it into a new one, as in <<b>script>, so content is
+// only accepted once a pass leaves it unchanged.
+const maxSanitizePasses = 5
+
+// skipContentElements are removed along with everything between their opening and closing tags
+var skipContentElements = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "noscript": true,
+	"template": true, "svg": true, "math": true,
+}
+
+// safeURLSchemes are the schemes markdown links and autolinks may use; URLs without a scheme are
+// relative and always allowed. This matches the schemes bluemonday's UGC policy allows.
+var safeURLSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+var (
+	// codePattern matches fenced code blocks and inline code spans, which markdown renders as text
+	codePattern = regexp.MustCompile("(?s)```.*?```|`[^`\n]+`")
+	// autolinkPattern matches a markdown autolink such as <https://example.com>, capturing the URL
+	autolinkPattern = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.\-]{1,31}:[^\s<>]*)>$`)
+	// emailAutolinkPattern matches a markdown email autolink such as <someone@example.com>
+	emailAutolinkPattern = regexp.MustCompile(`^<[a-zA-Z0-9.!#$%&'*+/=?^_{|}~\-]+@[a-zA-Z0-9](?:[a-zA-Z0-9\-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9\-]*[a-zA-Z0-9])?)*>$`)
+	// markdownEscapePattern matches a backslash escape, which markdown removes from link targets
+	markdownEscapePattern = regexp.MustCompile(`\\([!-/:-@\[-` + "`" + `{-~])`)
+)
+
+// htmlSanitizer implements ContentSanitizer by checking HTML in content against an allowlist
+type htmlSanitizer struct {
+	policy *bluemonday.Policy
+}
+
+// NewHTMLSanitizer creates a ContentSanitizer that only lets through the HTML allowed by
+// bluemonday's UGC policy: every tag in content is checked against the policy, and disallowed
+// tags and attributes, including event handlers and script URLs, are removed. The content of
+// elements such as <script> and <iframe> goes with them. Markdown link and image targets with
+// a scheme other than http, https or mailto are replaced with "#".
+//
+// Content is markdown rather than HTML, so text between tags is kept exactly as written
+// instead of being HTML-escaped, and code blocks and inline code are left alone, since
+// markdown renders them as text.
+func NewHTMLSanitizer() ContentSanitizer {
+	return &htmlSanitizer{policy: bluemonday.UGCPolicy()}
+}
+
+// Sanitize returns content with disallowed HTML and unsafe link targets removed
+func (s *htmlSanitizer) Sanitize(content string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range codePattern.FindAllStringIndex(content, -1) {
+		b.WriteString(s.sanitizeText(content[last:loc[0]]))
+		b.WriteString(content[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(s.sanitizeText(content[last:]))
+	return b.String()
+}
+
+// sanitizeText sanitizes markdown outside of code
+func (s *htmlSanitizer) sanitizeText(text string) string {
+	for pass := 0; ; pass++ {
+		cleaned := s.sanitizeTags(text)
+		if cleaned == text {
+			break
+		}
+		if pass == maxSanitizePasses {
+			// Still changing; give up on keeping any of the HTML
+			cleaned = html.EscapeString(cleaned)
+			break
+		}
+		text = cleaned
+	}
+	return sanitizeMarkdownLinks(text)
+}
+
+// sanitizeTags makes one pass over text, keeping the text between tags as written and
+// replacing each tag with what the policy allows of it. Closing tags are only kept for
+// elements whose opening tag was.
+func (s *htmlSanitizer) sanitizeTags(text string) string {
+	var b strings.Builder
+	open := make(map[string]int)
+	skipping, skipDepth := "", 0
+
+	z := xhtml.NewTokenizer(strings.NewReader(text))
+	for {
+		tt := z.Next()
+		if tt == xhtml.ErrorToken {
+			if z.Err() != io.EOF {
+				// The tokenizer only fails on EOF for a string; be safe if that changes
+				return html.EscapeString(text)
+			}
+			return b.String()
+		}
+
+		// Copy the raw token first, since TagName lower-cases it in place
+		raw := string(z.Raw())
+		name, _ := z.TagName()
+		tag := string(name)
+
+		if skipping != "" {
+			switch {
+			case tt == xhtml.StartTagToken && tag == skipping:
+				skipDepth++
+			case tt == xhtml.EndTagToken && tag == skipping:
+				skipDepth--
+				if skipDepth == 0 {
+					skipping = ""
+				}
+			}
+			continue
+		}
+
+		switch tt {
+		case xhtml.TextToken:
+			b.WriteString(raw)
+		case xhtml.StartTagToken, xhtml.SelfClosingTagToken:
+			if isSafeAutolink(raw) {
+				b.WriteString(raw)
+				continue
+			}
+			cleaned := s.policy.Sanitize(raw)
+			if cleaned == "" {
+				if skipContentElements[tag] {
+					skipping, skipDepth = tag, 1
+				}
+				continue
+			}
+			if tt == xhtml.StartTagToken {
+				open[tag]++
+			}
+			b.WriteString(cleaned)
+		case xhtml.EndTagToken:
+			if open[tag] > 0 {
+				open[tag]--
+				b.WriteString(s.policy.Sanitize(raw))
+			}
+		}
+		// Comments and doctypes are dropped
+	}
+}
+
+// isSafeAutolink reports whether tag is a markdown autolink to a safe URL or an email address
+func isSafeAutolink(tag string) bool {
+	if emailAutolinkPattern.MatchString(tag) {
+		return true
+	}
+	match := autolinkPattern.FindStringSubmatch(tag)
+	return match != nil && isSafeURL(match[1])
+}
+
+// sanitizeMarkdownLinks replaces the target of every markdown link or image whose URL isn't
+// safe with "#". Targets may contain balanced parentheses, as in [x](https://example.com/a_(b)).
+func sanitizeMarkdownLinks(text string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(text, "](")
+		if i < 0 {
+			break
+		}
+		start := i + 2
+		end := markdownLinkTargetEnd(text, start)
+		target := strings.TrimSpace(text[start:end])
+
+		b.WriteString(text[:start])
+		if isSafeURL(strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")) {
+			b.WriteString(text[start:end])
+		} else {
+			b.WriteString("#")
+		}
+		text = text[end:]
+	}
+	b.WriteString(text)
+	return b.String()
+}
+
+// markdownLinkTargetEnd returns the index just past the link target starting at start: the end
+// of an <angle-bracketed> target, or else the first space or unbalanced closing parenthesis
+func markdownLinkTargetEnd(text string, start int) int {
+	i := start
+	for i < len(text) && (text[i] == ' ' || text[i] == '\t' || text[i] == '\n') {
+		i++
+	}
+	if i < len(text) && text[i] == '<' {
+		if end := strings.IndexAny(text[i:], ">\n"); end >= 0 && text[i+end] == '>' {
+			return i + end + 1
+		}
+	}
+
+	depth := 0
+	for ; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '\\' && i+1 < len(text):
+			i++
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case c <= ' ':
+			return i
+		}
+	}
+	return i
+}
+
+// isSafeURL reports whether a link target is relative or uses a safe scheme. Markdown decodes
+// backslash escapes and character references in link targets, and browsers ignore whitespace
+// and control characters in a scheme, so both are undone before the scheme is checked.
+func isSafeURL(target string) bool {
+	u := html.UnescapeString(markdownEscapePattern.ReplaceAllString(target, "$1"))
+	u = strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, u)
+
+	colon := strings.IndexByte(u, ':')
+	if colon < 0 || strings.ContainsAny(u[:colon], "/?#") {
+		return true
+	}
+	return safeURLSchemes[strings.ToLower(u[:colon])]
+}
+
+// applyContentSanitizer runs content through sanitizer. A nil sanitizer leaves content unchanged.
+func applyContentSanitizer(sanitizer ContentSanitizer, content string) string {
+	if sanitizer == nil {
+		return content
+	}
+	return sanitizer.Sanitize(content)
+}
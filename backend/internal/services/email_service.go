@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	appconfig "github.com/garrettallen/aiboards/backend/config"
+)
+
+// smtpTimeout bounds connecting to the SMTP server and the whole exchange that follows, so a
+// slow or unresponsive server can't hold a send open indefinitely
+const smtpTimeout = 30 * time.Second
+
+// EmailService defines the interface for sending emails
+type EmailService interface {
+	// Send sends a plain text email
+	Send(ctx context.Context, to, subject, body string) error
+
+	// IsConfigured reports whether emails will actually be delivered
+	IsConfigured() bool
+}
+
+// NewEmailService creates an SMTP email service, or a no-op service when SMTP isn't configured
+func NewEmailService(cfg *appconfig.Config) EmailService {
+	if cfg.SMTPHost == "" {
+		return &noopEmailService{}
+	}
+
+	return &smtpEmailService{
+		host:     cfg.SMTPHost,
+		port:     cfg.SMTPPort,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+		from:     cfg.SMTPFrom,
+	}
+}
+
+// smtpEmailService implements EmailService using an SMTP server
+type smtpEmailService struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+}
+
+// Send sends a plain text email through the SMTP server. The connection gives up after
+// smtpTimeout, or at ctx's deadline if that is sooner.
+func (s *smtpEmailService) Send(ctx context.Context, to, subject, body string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	message := strings.Join([]string{
+		"From: " + s.from,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.host, strconv.Itoa(s.port)))
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(smtpTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	// Upgrade to TLS and authenticate as smtp.SendMail does
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(s.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write([]byte(message)); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// IsConfigured reports that emails are delivered
+func (s *smtpEmailService) IsConfigured() bool {
+	return true
+}
+
+// noopEmailService discards emails when no SMTP server is configured
+type noopEmailService struct{}
+
+// Send discards the email
+func (s *noopEmailService) Send(ctx context.Context, to, subject, body string) error {
+	return nil
+}
+
+// IsConfigured reports that emails are not delivered
+func (s *noopEmailService) IsConfigured() bool {
+	return false
+}
@@ -1,23 +1,78 @@
 package services
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/garrettallen/aiboards/backend/internal/models"
+)
 
 var (
 	ErrAgentNotFound          = errors.New("agent not found")
-	ErrAgentLimitExceeded     = errors.New("agent limit exceeded")
+	ErrAgentLimitReached      = errors.New("maximum number of agents reached")
 	ErrAgentRateLimited       = errors.New("agent has reached daily message limit")
 	ErrAgentNameExists        = errors.New("agent name already exists")
+	ErrAgentNameLength        = fmt.Errorf("agent name must be between %d and %d characters", MinAgentNameLength, MaxAgentNameLength)
+	ErrAgentNameInvalidChars  = errors.New("agent name may only contain letters, digits, underscores and hyphens")
+	ErrAgentNameReserved      = errors.New("agent name is reserved")
+	ErrAgentDeactivated       = errors.New("agent is deactivated")
+	ErrAgentBioTooLong        = fmt.Errorf("bio must be at most %d characters", MaxAgentBioLength)
+	ErrAgentLocationTooLong   = fmt.Errorf("location must be at most %d characters", MaxAgentLocationLength)
+	ErrInvalidWebsiteURL      = errors.New("website URL must be an absolute http or https URL")
+	ErrInvalidPictureURL      = errors.New("profile picture URL must be an absolute http or https URL")
+	ErrPictureUnreachable     = errors.New("could not reach profile picture URL")
+	ErrPictureTooLarge        = fmt.Errorf("profile picture exceeds the %dMB size limit", MaxProfilePictureSize/(1024*1024))
+	ErrTooManyAgentIDs        = errors.New("too many agent IDs requested")
+	ErrTooManyNotificationIDs = errors.New("too many notification IDs requested")
+	ErrCannotBlockSelf        = errors.New("an agent cannot block itself")
+	ErrBlockNotFound          = errors.New("agent is not blocked")
+	ErrEmptySearchQuery       = errors.New("search query is required")
 	ErrVoteNotFound           = errors.New("vote not found")
-	ErrInvalidTargetType      = errors.New("invalid target type")
+	ErrInvalidTargetType      = models.ErrInvalidTargetType
+	ErrInvalidVoteValue       = models.ErrInvalidVoteValue
 	ErrTargetNotFound         = errors.New("target not found")
 	ErrAlreadyVoted           = errors.New("agent has already voted on this target")
 	ErrReplyNotFound          = errors.New("reply not found")
-	ErrInvalidParentType      = errors.New("invalid parent type")
+	ErrInvalidParentType      = models.ErrInvalidParentType
 	ErrParentNotFound         = errors.New("parent not found")
+	ErrQuotedReplyNotFound    = errors.New("quoted reply not found")
+	ErrQuotedReplyOtherThread = errors.New("quoted reply belongs to a different post")
 	ErrPostNotFound           = errors.New("post not found")
+	ErrTooManyPostIDs         = errors.New("too many post IDs requested")
+	ErrNotPostAuthor          = errors.New("agent is not the post's author")
+	ErrTooManyAttachments     = errors.New("too many attachments")
+	ErrInvalidAttachmentURL   = errors.New("attachment URL must be an absolute http or https URL")
+	ErrReplyNotAcceptable     = errors.New("only direct replies to a post can be accepted")
+	ErrAcceptConflict         = errors.New("another reply was accepted at the same time")
 	ErrBoardInactive          = errors.New("board is inactive")
+	ErrBoardPostingDisabled   = errors.New("posting to this board is not allowed")
+	ErrBoardArchived          = errors.New("board is archived")
+	ErrPostLocked             = errors.New("post is locked")
+	ErrNotPostModerator       = errors.New("only the board owner or an admin can lock this post")
+	ErrInvalidPostPolicy      = models.ErrInvalidPostPolicy
+	ErrInvalidBoardVisibility = models.ErrInvalidBoardVisibility
+	ErrBoardRequiresAuth      = errors.New("authentication is required to read this board")
 	ErrNotificationNotFound   = errors.New("notification not found")
+	ErrInvalidDigestFrequency = models.ErrInvalidDigestFrequency
+	ErrInvalidSortField       = errors.New("invalid sort field")
+	ErrInvalidSortOrder       = errors.New("invalid sort order")
+	ErrInvalidPeriod          = errors.New("period must be one of day, week or month")
+	ErrInvalidCursor          = errors.New("invalid pagination cursor")
+	ErrInvalidRetention       = errors.New("retention must not be negative")
+	ErrInvalidInactivity      = errors.New("inactivity period must be positive")
+	ErrInvalidTimeRange       = errors.New("since must not be after until")
+	ErrInvalidActivityBucket  = errors.New("bucket must be one of hour or day")
+	ErrContentTooLong         = errors.New("content exceeds the maximum length")
+	ErrEmptyContent           = errors.New("content cannot be empty")
+	ErrContentBlocked         = errors.New("content contains blocked terms")
+	ErrEditWindowExpired      = errors.New("the time allowed for editing has passed")
+	ErrDuplicatePost          = errors.New("post duplicates the agent's previous post on this board")
 	ErrBoardNotFound          = errors.New("board not found")
+	ErrNotBoardOwner          = errors.New("agent does not own this board")
+	ErrAgentHasBoard          = errors.New("agent already has a board")
+	ErrBoardLimitReached      = errors.New("maximum number of boards reached")
 	ErrBetaCodeNotFound       = errors.New("beta code not found")
 	ErrBetaCodeUsed           = errors.New("beta code has already been used")
 	ErrEmailAlreadyExists     = errors.New("email already exists")
@@ -28,4 +83,13 @@ var (
 	ErrInvalidBetaCode        = errors.New("invalid or used beta code")
 	ErrInvalidCredentials     = errors.New("invalid credentials")
 	ErrUserNotFound           = errors.New("user not found")
+	ErrCannotRemoveLastAdmin  = errors.New("cannot remove admin from the last remaining admin")
+	ErrInvalidWebhookURL      = errors.New("invalid webhook URL")
+	ErrInvalidWebhookEvent    = errors.New("invalid webhook event")
 )
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MarkdownRenderer renders post and reply content to HTML
+type MarkdownRenderer interface {
+	Render(content string) (string, error)
+}
+
+var (
+	// fencePattern matches the opening or closing line of a fenced code block, capturing its language
+	fencePattern = regexp.MustCompile("^\\s*```\\s*([\\w+-]*)\\s*$")
+	// headingPattern matches an ATX heading, capturing its level and text
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	// rulePattern matches a thematic break
+	rulePattern = regexp.MustCompile(`^\s*(?:\*\s*){3,}$|^\s*(?:-\s*){3,}$|^\s*(?:_\s*){3,}$`)
+	// quotePattern matches a blockquote line, capturing the quoted text
+	quotePattern = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	// bulletPattern matches an unordered list item, capturing its text
+	bulletPattern = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	// orderedPattern matches an ordered list item, capturing its text
+	orderedPattern = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+
+	// inlineCodePattern matches a code span, capturing its text
+	inlineCodePattern = regexp.MustCompile("`([^`\n]+)`")
+	// imagePattern matches an image in escaped text, capturing its alt text and target
+	imagePattern = regexp.MustCompile(`!\[([^\]]*)\]\(\s*([^)\s]+)\s*\)`)
+	// linkPattern matches a link in escaped text, capturing its text and target
+	linkPattern = regexp.MustCompile(`\[([^\]]+)\]\(\s*([^)\s]+)\s*\)`)
+	// strongPattern matches strong emphasis, capturing the emphasized text
+	strongPattern = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	// emphasisPattern matches emphasis, capturing the emphasized text
+	emphasisPattern = regexp.MustCompile(`\*([^*\s][^*]*)\*|\b_([^_\s][^_]*)_\b`)
+	// strikethroughPattern matches struck-through text, capturing it
+	strikethroughPattern = regexp.MustCompile(`~~([^~]+)~~`)
+	// placeholderPattern matches the placeholder a rendered link or image is held in
+	placeholderPattern = regexp.MustCompile("\x00\\d+\x00")
+)
+
+// markdownRenderer implements MarkdownRenderer for the markdown subset posts use
+type markdownRenderer struct {
+	sanitizer        ContentSanitizer
+	maxContentLength int
+}
+
+// NewMarkdownRenderer creates a MarkdownRenderer. Content is run through sanitizer and
+// validated against maxContentLength exactly as it is when a post is stored, so a preview
+// shows what the stored post will look like. A nil sanitizer leaves content unchanged.
+//
+// Headings, paragraphs, lists, blockquotes, fenced code, code spans, emphasis, links and
+// images are rendered; any HTML left in the content is escaped and shown as text. Links
+// and images are only kept for http, https and mailto URLs and relative paths.
+func NewMarkdownRenderer(sanitizer ContentSanitizer, maxContentLength int) MarkdownRenderer {
+	return &markdownRenderer{
+		sanitizer:        sanitizer,
+		maxContentLength: maxContentLength,
+	}
+}
+
+// Render returns content as HTML
+func (r *markdownRenderer) Render(content string) (string, error) {
+	content = applyContentSanitizer(r.sanitizer, content)
+	if err := validateContent(content, r.maxContentLength); err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	return renderBlocks(lines), nil
+}
+
+// renderBlocks renders lines of markdown as a sequence of HTML blocks
+func renderBlocks(lines []string) string {
+	var b strings.Builder
+	var paragraph []string
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			b.WriteString("<p>" + renderInline(strings.TrimSpace(strings.Join(paragraph, "\n"))) + "</p>\n")
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			flushParagraph()
+
+		case fencePattern.MatchString(line):
+			flushParagraph()
+			language := fencePattern.FindStringSubmatch(line)[1]
+			var code []string
+			for i++; i < len(lines) && !fencePattern.MatchString(lines[i]); i++ {
+				code = append(code, lines[i])
+			}
+			b.WriteString("<pre><code")
+			if language != "" {
+				b.WriteString(` class="language-` + html.EscapeString(language) + `"`)
+			}
+			b.WriteString(">" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+
+		case headingPattern.MatchString(line):
+			flushParagraph()
+			parts := headingPattern.FindStringSubmatch(line)
+			level := string(rune('0' + len(parts[1])))
+			b.WriteString("<h" + level + ">" + renderInline(parts[2]) + "</h" + level + ">\n")
+
+		case rulePattern.MatchString(line):
+			flushParagraph()
+			b.WriteString("<hr>\n")
+
+		case quotePattern.MatchString(line):
+			flushParagraph()
+			var quoted []string
+			for ; i < len(lines) && quotePattern.MatchString(lines[i]); i++ {
+				quoted = append(quoted, quotePattern.FindStringSubmatch(lines[i])[1])
+			}
+			i--
+			b.WriteString("<blockquote>\n" + renderBlocks(quoted) + "</blockquote>\n")
+
+		case bulletPattern.MatchString(line), orderedPattern.MatchString(line):
+			flushParagraph()
+			pattern, tag := bulletPattern, "ul"
+			if !bulletPattern.MatchString(line) {
+				pattern, tag = orderedPattern, "ol"
+			}
+			b.WriteString("<" + tag + ">\n")
+			for ; i < len(lines) && pattern.MatchString(lines[i]); i++ {
+				b.WriteString("<li>" + renderInline(pattern.FindStringSubmatch(lines[i])[1]) + "</li>\n")
+			}
+			i--
+			b.WriteString("</" + tag + ">\n")
+
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	flushParagraph()
+
+	return b.String()
+}
+
+// renderInline renders the inline markdown in text, escaping everything else
+func renderInline(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range inlineCodePattern.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(renderInlineText(text[last:loc[0]]))
+		b.WriteString("<code>" + html.EscapeString(text[loc[2]:loc[3]]) + "</code>")
+		last = loc[1]
+	}
+	b.WriteString(renderInlineText(text[last:]))
+	return b.String()
+}
+
+// renderInlineText renders links, images and emphasis in text outside of code spans. Rendered
+// links and images are swapped for placeholders while emphasis is applied, so markers in
+// their URLs are left alone.
+func renderInlineText(text string) string {
+	text = html.EscapeString(strings.ReplaceAll(text, "\x00", ""))
+
+	var rendered []string
+	placeholder := func(tag string) string {
+		rendered = append(rendered, tag)
+		return "\x00" + strconv.Itoa(len(rendered)-1) + "\x00"
+	}
+
+	text = imagePattern.ReplaceAllStringFunc(text, func(image string) string {
+		parts := imagePattern.FindStringSubmatch(image)
+		if !isSafeLinkTarget(parts[2]) {
+			return parts[1]
+		}
+		return placeholder(`<img src="` + parts[2] + `" alt="` + parts[1] + `">`)
+	})
+	text = linkPattern.ReplaceAllStringFunc(text, func(link string) string {
+		parts := linkPattern.FindStringSubmatch(link)
+		if !isSafeLinkTarget(parts[2]) {
+			return parts[1]
+		}
+		return placeholder(`<a href="` + parts[2] + `" rel="nofollow noopener">` + renderEmphasis(parts[1]) + `</a>`)
+	})
+
+	// A link's text may itself hold an image's placeholder, so restore recursively
+	var restore func(text string) string
+	restore = func(text string) string {
+		return placeholderPattern.ReplaceAllStringFunc(text, func(token string) string {
+			i, _ := strconv.Atoi(strings.Trim(token, "\x00"))
+			return restore(rendered[i])
+		})
+	}
+	return restore(renderEmphasis(text))
+}
+
+// renderEmphasis renders strong emphasis, emphasis and strikethrough in escaped text
+func renderEmphasis(text string) string {
+	text = strongPattern.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = emphasisPattern.ReplaceAllString(text, "<em>$1$2</em>")
+	return strikethroughPattern.ReplaceAllString(text, "<del>$1</del>")
+}
+
+// isSafeLinkTarget reports whether an escaped link or image target may be rendered:
+// an http, https or mailto URL, or one without a scheme
+func isSafeLinkTarget(target string) bool {
+	u, err := url.Parse(html.UnescapeString(target))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	default:
+		return false
+	}
+}
@@ -3,6 +3,10 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,9 +19,10 @@ import (
 type NotificationType string
 
 const (
-	NotificationTypeReply  NotificationType = "reply"
-	NotificationTypeVote   NotificationType = "vote"
-	NotificationTypeSystem NotificationType = "system"
+	NotificationTypeReply       NotificationType = "reply"
+	NotificationTypeThreadReply NotificationType = "thread_reply"
+	NotificationTypeVote        NotificationType = "vote"
+	NotificationTypeSystem      NotificationType = "system"
 )
 
 // NotificationService handles notification-related business logic
@@ -25,34 +30,75 @@ type NotificationService interface {
 	CreateNotification(ctx context.Context, agentID uuid.UUID, notificationType NotificationType, content string, targetType string, targetID uuid.UUID) (*models.Notification, error)
 	GetNotificationByID(ctx context.Context, id uuid.UUID) (*models.Notification, error)
 	GetNotificationsByAgentID(ctx context.Context, agentID uuid.UUID, page, pageSize int) ([]*models.Notification, int, error)
+	GetByIDs(ctx context.Context, agentID uuid.UUID, ids []uuid.UUID) ([]*models.Notification, error)
 	MarkAsRead(ctx context.Context, id uuid.UUID) error
+	MarkAsUnread(ctx context.Context, id uuid.UUID) error
 	MarkAllAsRead(ctx context.Context, agentID uuid.UUID) error
 	DeleteNotification(ctx context.Context, id uuid.UUID) error
+	DeleteReadNotifications(ctx context.Context, agentID uuid.UUID, olderThan time.Duration) (int, error)
 	CountUnread(ctx context.Context, agentID uuid.UUID) (int, error)
-	NotifyOnReply(ctx context.Context, reply *models.Reply, post *models.Post) error
+	CountUnreadByType(ctx context.Context, agentID uuid.UUID) (map[NotificationType]int, error)
+	NotifyOnReply(ctx context.Context, reply *models.Reply, post *models.Post, parent *models.Reply) error
 	NotifyOnVote(ctx context.Context, vote *models.Vote, targetAgentID uuid.UUID) error
+	GetPreferences(ctx context.Context, agentID uuid.UUID) (*models.NotificationPreference, error)
+	UpdatePreferences(ctx context.Context, agentID uuid.UUID, emailEnabled *bool, digestFrequency *string, threadRepliesEnabled *bool) (*models.NotificationPreference, error)
+	SendDigest(ctx context.Context, agentID uuid.UUID) (bool, error)
+	SendDailyDigests(ctx context.Context) (int, error)
+	Broadcast(ctx context.Context, content, targetType string, targetID uuid.UUID) (int, error)
 }
 
+// emailThrottleInterval is the minimum time between notification emails to the same agent,
+// so a burst of activity results in one email rather than dozens
+const emailThrottleInterval = 15 * time.Minute
+
+// digestWindow is how far back a digest email looks for unread notifications
+const digestWindow = 24 * time.Hour
+
+// DigestCheckInterval is how often SendDailyDigests is meant to run. Each run only emails the
+// agents whose last digest is about a day old, so frequent runs keep a daily rhythm and a
+// restart doesn't skip a day.
+const DigestCheckInterval = time.Hour
+
+// digestMaxItems is the maximum number of notifications listed in a digest email
+const digestMaxItems = 10
+
+// BroadcastBatchSize is the number of notifications Broadcast inserts per statement
+const BroadcastBatchSize = 1000
+
 type notificationService struct {
 	notificationRepo repository.NotificationRepository
+	preferenceRepo   repository.NotificationPreferenceRepository
 	userRepo         repository.UserRepository
 	agentRepo        repository.AgentRepository
+	emailService     EmailService
+	dedupWindow      time.Duration
+
+	emailMu     sync.Mutex
+	lastEmailAt map[uuid.UUID]time.Time
 }
 
 // NewNotificationService creates a new NotificationService
 func NewNotificationService(
 	notificationRepo repository.NotificationRepository,
+	preferenceRepo repository.NotificationPreferenceRepository,
 	userRepo repository.UserRepository,
 	agentRepo repository.AgentRepository,
+	emailService EmailService,
+	dedupWindow time.Duration,
 ) NotificationService {
 	return &notificationService{
 		notificationRepo: notificationRepo,
+		preferenceRepo:   preferenceRepo,
 		userRepo:         userRepo,
 		agentRepo:        agentRepo,
+		emailService:     emailService,
+		dedupWindow:      dedupWindow,
+		lastEmailAt:      make(map[uuid.UUID]time.Time),
 	}
 }
 
-// CreateNotification creates a new notification
+// CreateNotification creates a new notification. If the agent already has a notification of the
+// same type for the same target from within the dedup window, that one is refreshed instead.
 func (s *notificationService) CreateNotification(ctx context.Context, agentID uuid.UUID, notificationType NotificationType, content string, targetType string, targetID uuid.UUID) (*models.Notification, error) {
 	// Check if agent exists
 	agent, err := s.agentRepo.GetByID(ctx, agentID)
@@ -64,7 +110,7 @@ func (s *notificationService) CreateNotification(ctx context.Context, agentID uu
 	}
 
 	// Create the notification
-	now := time.Now()
+	now := models.NowUTC()
 	notification := &models.Notification{
 		ID:         uuid.New(),
 		AgentID:    agentID,
@@ -76,15 +122,251 @@ func (s *notificationService) CreateNotification(ctx context.Context, agentID uu
 		CreatedAt:  now,
 	}
 
+	// Collapse bursts for the same target into the existing notification; the agent was
+	// already told about it, so no email is sent for the refresh
+	if s.dedupWindow > 0 {
+		existing, err := s.notificationRepo.RefreshRecent(ctx, notification, now.Add(-s.dedupWindow))
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return existing, nil
+		}
+	}
+
 	// Save the notification
 	err = s.notificationRepo.Create(ctx, notification)
 	if err != nil {
 		return nil, err
 	}
 
+	// Email is best effort; the in-app notification has already been stored
+	if err := s.sendNotificationEmail(ctx, agent, notification); err != nil {
+		log.Printf("Failed to email notification %s to agent %s: %v", notification.ID, agent.ID, err)
+	}
+
 	return notification, nil
 }
 
+// sendNotificationEmail emails the agent's owner about a high-value notification
+// if they opted in and haven't been emailed within the throttle interval. The email itself
+// is sent in the background, so delivery failures are only logged.
+func (s *notificationService) sendNotificationEmail(ctx context.Context, agent *models.Agent, notification *models.Notification) error {
+	if s.emailService == nil || !s.emailService.IsConfigured() {
+		return nil
+	}
+
+	// Only replies are worth an email; votes and system messages stay in-app
+	if NotificationType(notification.Type) != NotificationTypeReply {
+		return nil
+	}
+
+	preference, err := s.preferenceRepo.GetByAgentID(ctx, agent.ID)
+	if err != nil {
+		return err
+	}
+	if preference == nil || !preference.EmailEnabled {
+		return nil
+	}
+
+	// Throttle per agent. Entries older than the interval no longer throttle anything, so
+	// they are dropped to keep the map from growing with every agent ever emailed.
+	now := models.NowUTC()
+	s.emailMu.Lock()
+	for id, last := range s.lastEmailAt {
+		if now.Sub(last) >= emailThrottleInterval {
+			delete(s.lastEmailAt, id)
+		}
+	}
+	if _, ok := s.lastEmailAt[agent.ID]; ok {
+		s.emailMu.Unlock()
+		return nil
+	}
+	s.lastEmailAt[agent.ID] = now
+	s.emailMu.Unlock()
+
+	user, err := s.userRepo.GetByID(ctx, agent.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	subject := "New activity for " + agent.Name
+	body := notification.Content + "\n\nView your notifications on AI Boards to see the details."
+
+	// The SMTP exchange happens in the background so it doesn't hold up the request that
+	// caused the notification; that request's context may be gone by the time it finishes
+	go func() {
+		if err := s.emailService.Send(context.Background(), user.Email, subject, body); err != nil {
+			log.Printf("Failed to email notification %s to agent %s: %v", notification.ID, agent.ID, err)
+		}
+	}()
+
+	return nil
+}
+
+// GetPreferences retrieves an agent's notification preferences, falling back to the defaults
+func (s *notificationService) GetPreferences(ctx context.Context, agentID uuid.UUID) (*models.NotificationPreference, error) {
+	preference, err := s.preferenceRepo.GetByAgentID(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	if preference == nil {
+		return models.NewNotificationPreference(agentID), nil
+	}
+	return preference, nil
+}
+
+// UpdatePreferences updates an agent's notification preferences. Nil values are left unchanged.
+func (s *notificationService) UpdatePreferences(ctx context.Context, agentID uuid.UUID, emailEnabled *bool, digestFrequency *string, threadRepliesEnabled *bool) (*models.NotificationPreference, error) {
+	// Check if agent exists
+	agent, err := s.agentRepo.GetByID(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	if agent == nil {
+		return nil, ErrAgentNotFound
+	}
+
+	preference, err := s.GetPreferences(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if emailEnabled != nil {
+		preference.EmailEnabled = *emailEnabled
+	}
+	if digestFrequency != nil {
+		frequency, err := models.ParseDigestFrequency(*digestFrequency)
+		if err != nil {
+			return nil, err
+		}
+		preference.DigestFrequency = frequency
+	}
+	if threadRepliesEnabled != nil {
+		preference.ThreadRepliesEnabled = *threadRepliesEnabled
+	}
+	preference.UpdatedAt = models.NowUTC()
+
+	if err := s.preferenceRepo.Upsert(ctx, preference); err != nil {
+		return nil, err
+	}
+
+	return preference, nil
+}
+
+// SendDigest emails an agent's owner a summary of the unread notifications from the last day.
+// It reports whether an email was sent; agents without digests enabled or with nothing new are skipped.
+func (s *notificationService) SendDigest(ctx context.Context, agentID uuid.UUID) (bool, error) {
+	if s.emailService == nil || !s.emailService.IsConfigured() {
+		return false, nil
+	}
+
+	// Check if agent exists
+	agent, err := s.agentRepo.GetByID(ctx, agentID)
+	if err != nil {
+		return false, err
+	}
+	if agent == nil {
+		return false, ErrAgentNotFound
+	}
+
+	preference, err := s.preferenceRepo.GetByAgentID(ctx, agentID)
+	if err != nil {
+		return false, err
+	}
+	if preference == nil || preference.DigestFrequency != models.DigestFrequencyDaily {
+		return false, nil
+	}
+
+	// Only include notifications not already covered by a previous digest
+	since := models.NowUTC().Add(-digestWindow)
+	if preference.LastDigestAt != nil && preference.LastDigestAt.After(since) {
+		since = *preference.LastDigestAt
+	}
+
+	notifications, err := s.notificationRepo.GetUnreadSince(ctx, agentID, since)
+	if err != nil {
+		return false, err
+	}
+	if len(notifications) == 0 {
+		return false, nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, agent.UserID)
+	if err != nil {
+		return false, err
+	}
+	if user == nil {
+		return false, ErrUserNotFound
+	}
+
+	subject := fmt.Sprintf("Your daily AI Boards digest for %s", agent.Name)
+	if err := s.emailService.Send(ctx, user.Email, subject, buildDigestBody(agent, notifications)); err != nil {
+		return false, err
+	}
+
+	if err := s.preferenceRepo.UpdateLastDigestAt(ctx, agentID, models.NowUTC()); err != nil {
+		return true, err
+	}
+
+	return true, nil
+}
+
+// SendDailyDigests sends a digest to every agent subscribed to daily digests whose last
+// digest is due, and returns the number of emails sent. An agent is due once its last digest
+// is a day old, less half of DigestCheckInterval, so whichever run picks it up keeps it close
+// to a day apart. Failures for one agent are logged and don't stop the run.
+func (s *notificationService) SendDailyDigests(ctx context.Context) (int, error) {
+	preferences, err := s.preferenceRepo.GetByDigestFrequency(ctx, models.DigestFrequencyDaily)
+	if err != nil {
+		return 0, err
+	}
+
+	dueBefore := models.NowUTC().Add(-(digestWindow - DigestCheckInterval/2))
+	sent := 0
+	for _, preference := range preferences {
+		if preference.LastDigestAt != nil && preference.LastDigestAt.After(dueBefore) {
+			continue
+		}
+
+		ok, err := s.SendDigest(ctx, preference.AgentID)
+		if err != nil {
+			log.Printf("Failed to send digest to agent %s: %v", preference.AgentID, err)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// buildDigestBody renders the plain text body of a digest email
+func buildDigestBody(agent *models.Agent, notifications []*models.Notification) string {
+	var b strings.Builder
+
+	if len(notifications) == 1 {
+		fmt.Fprintf(&b, "%s has 1 unread notification from the last day:\n\n", agent.Name)
+	} else {
+		fmt.Fprintf(&b, "%s has %d unread notifications from the last day:\n\n", agent.Name, len(notifications))
+	}
+
+	for i, notification := range notifications {
+		if i == digestMaxItems {
+			fmt.Fprintf(&b, "...and %d more\n", len(notifications)-digestMaxItems)
+			break
+		}
+		fmt.Fprintf(&b, "- %s\n", notification.Content)
+	}
+
+	b.WriteString("\nView your notifications on AI Boards to see the details.")
+	return b.String()
+}
+
 // GetNotificationByID retrieves a notification by ID
 func (s *notificationService) GetNotificationByID(ctx context.Context, id uuid.UUID) (*models.Notification, error) {
 	notification, err := s.notificationRepo.GetByID(ctx, id)
@@ -100,6 +382,29 @@ func (s *notificationService) GetNotificationByID(ctx context.Context, id uuid.U
 	return notification, nil
 }
 
+// MaxNotificationBatchSize is the maximum number of distinct notification IDs that can be fetched at once
+const MaxNotificationBatchSize = 100
+
+// GetByIDs retrieves the agent's notifications among ids in one query, newest first.
+// IDs that don't exist or belong to another agent are left out.
+func (s *notificationService) GetByIDs(ctx context.Context, agentID uuid.UUID, ids []uuid.UUID) ([]*models.Notification, error) {
+	// Dedupe IDs
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	if len(unique) > MaxNotificationBatchSize {
+		return nil, ErrTooManyNotificationIDs
+	}
+
+	return s.notificationRepo.GetByAgentAndIDs(ctx, agentID, unique)
+}
+
 // GetNotificationsByAgentID retrieves notifications for an agent with pagination
 func (s *notificationService) GetNotificationsByAgentID(ctx context.Context, agentID uuid.UUID, page, pageSize int) ([]*models.Notification, int, error) {
 	// Check if agent exists
@@ -157,6 +462,21 @@ func (s *notificationService) MarkAsRead(ctx context.Context, id uuid.UUID) erro
 	return s.notificationRepo.MarkAsRead(ctx, id)
 }
 
+// MarkAsUnread marks a notification as unread again, so it counts towards the agent's
+// unread notifications until it is next read
+func (s *notificationService) MarkAsUnread(ctx context.Context, id uuid.UUID) error {
+	// Check if notification exists
+	notification, err := s.notificationRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if notification == nil {
+		return ErrNotificationNotFound
+	}
+
+	return s.notificationRepo.MarkAsUnread(ctx, id)
+}
+
 // MarkAllAsRead marks all notifications for an agent as read
 func (s *notificationService) MarkAllAsRead(ctx context.Context, agentID uuid.UUID) error {
 	// Check if agent exists
@@ -197,6 +517,25 @@ func (s *notificationService) DeleteNotification(ctx context.Context, id uuid.UU
 	return nil
 }
 
+// DeleteReadNotifications deletes the agent's read notifications created more than olderThan
+// ago and returns how many were deleted. Unread notifications are never deleted.
+func (s *notificationService) DeleteReadNotifications(ctx context.Context, agentID uuid.UUID, olderThan time.Duration) (int, error) {
+	if olderThan < 0 {
+		return 0, ErrInvalidRetention
+	}
+
+	// Check if agent exists
+	agent, err := s.agentRepo.GetByID(ctx, agentID)
+	if err != nil {
+		return 0, err
+	}
+	if agent == nil {
+		return 0, ErrAgentNotFound
+	}
+
+	return s.notificationRepo.DeleteReadBefore(ctx, agentID, models.NowUTC().Add(-olderThan))
+}
+
 // CountUnread counts the number of unread notifications for an agent
 func (s *notificationService) CountUnread(ctx context.Context, agentID uuid.UUID) (int, error) {
 	// Check if agent exists
@@ -212,41 +551,91 @@ func (s *notificationService) CountUnread(ctx context.Context, agentID uuid.UUID
 	return s.notificationRepo.CountUnread(ctx, agentID)
 }
 
-// NotifyOnReply creates a notification when a reply is made
-func (s *notificationService) NotifyOnReply(ctx context.Context, reply *models.Reply, post *models.Post) error {
-	var agentID uuid.UUID
-	var content string
+// CountUnreadByType counts an agent's unread notifications per type. Every known type is
+// present in the result, with zero if it has no unread notifications.
+func (s *notificationService) CountUnreadByType(ctx context.Context, agentID uuid.UUID) (map[NotificationType]int, error) {
+	// Check if agent exists
+	agent, err := s.agentRepo.GetByID(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	if agent == nil {
+		return nil, ErrAgentNotFound
+	}
 
-	// Determine the agent to notify and the content based on the parent type
-	if reply.ParentType == "post" {
-		// Notify the agent owner of the post
-		agentID = post.AgentID
-		content = "New reply to your post"
-	} else {
-		// For replies to replies, we'd need to get the parent reply and its agent
-		// This is a simplified implementation
-		content = "New reply to your comment"
-		// In a real implementation, you would fetch the parent reply and get its agent ID
+	rows, err := s.notificationRepo.CountUnreadByType(ctx, agentID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create the notification
-	_, err := s.CreateNotification(ctx, agentID, NotificationTypeReply, content, reply.ParentType, reply.ID)
-	return err
+	counts := map[NotificationType]int{
+		NotificationTypeReply:       0,
+		NotificationTypeThreadReply: 0,
+		NotificationTypeVote:        0,
+		NotificationTypeSystem:      0,
+	}
+	for notificationType, count := range rows {
+		counts[NotificationType(notificationType)] = count
+	}
+
+	return counts, nil
+}
+
+// NotifyOnReply creates notifications when a reply is made. parent is the reply being
+// replied to, or nil for a reply to the post itself. The author of the post or parent reply
+// is told about the direct reply whatever their thread setting, and every other agent who
+// has replied anywhere in the thread gets a thread reply notification unless they turned
+// those off. The reply's author is never notified of their own reply, and agents who have
+// blocked the reply's author aren't notified. Notifications target what was replied to, and
+// thread reply notifications the post, so a burst of replies collapses into one within the
+// dedup window. A failure for one agent doesn't stop the others from being notified; the
+// first error is returned once all have been tried.
+func (s *notificationService) NotifyOnReply(ctx context.Context, reply *models.Reply, post *models.Post, parent *models.Reply) error {
+	alreadyNotified := []uuid.UUID{reply.AgentID}
+	var firstErr error
+
+	// Notify the author of what was replied to
+	recipientID, content := post.AgentID, "New reply to your post"
+	if parent != nil {
+		recipientID, content = parent.AgentID, "New reply to your reply"
+	}
+	if recipientID != reply.AgentID {
+		firstErr = s.notifyUnlessBlocked(ctx, recipientID, reply.AgentID, NotificationTypeReply, content, reply.ParentType, reply.ParentID)
+		alreadyNotified = append(alreadyNotified, recipientID)
+	}
+
+	participants, err := s.preferenceRepo.GetThreadReplySubscribers(ctx, post.ID, alreadyNotified)
+	if err != nil {
+		return err
+	}
+	for _, agentID := range participants {
+		if err := s.notifyUnlessBlocked(ctx, agentID, reply.AgentID, NotificationTypeThreadReply, "New reply in a thread you replied to", string(models.ParentTypePost), post.ID); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
 }
 
-// NotifyOnVote creates a notification when a vote is made
+// NotifyOnVote creates a notification when a vote is made, unless the target's author has
+// blocked the voter or voted on their own content. The notification targets the voted-on post
+// or reply, so repeated votes on it collapse into one within the dedup window.
 func (s *notificationService) NotifyOnVote(ctx context.Context, vote *models.Vote, targetAgentID uuid.UUID) error {
+	if targetAgentID == vote.AgentID {
+		return nil
+	}
+
 	var content string
 
 	// Determine the content based on the vote value and target type
 	if vote.Value > 0 {
-		if vote.TargetType == "post" {
+		if models.TargetType(vote.TargetType) == models.TargetTypePost {
 			content = "Someone upvoted your post"
 		} else {
 			content = "Someone upvoted your reply"
 		}
 	} else {
-		if vote.TargetType == "post" {
+		if models.TargetType(vote.TargetType) == models.TargetTypePost {
 			content = "Someone downvoted your post"
 		} else {
 			content = "Someone downvoted your reply"
@@ -254,6 +643,35 @@ func (s *notificationService) NotifyOnVote(ctx context.Context, vote *models.Vot
 	}
 
 	// Create the notification
-	_, err := s.CreateNotification(ctx, targetAgentID, NotificationTypeVote, content, vote.TargetType, vote.ID)
+	return s.notifyUnlessBlocked(ctx, targetAgentID, vote.AgentID, NotificationTypeVote, content, vote.TargetType, vote.TargetID)
+}
+
+// notifyUnlessBlocked creates a notification for agentID about something fromAgentID did,
+// unless agentID has blocked fromAgentID
+func (s *notificationService) notifyUnlessBlocked(ctx context.Context, agentID, fromAgentID uuid.UUID, notificationType NotificationType, content string, targetType string, targetID uuid.UUID) error {
+	blocked, err := s.agentRepo.IsBlocked(ctx, agentID, fromAgentID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return nil
+	}
+
+	_, err = s.CreateNotification(ctx, agentID, notificationType, content, targetType, targetID)
 	return err
 }
+
+// Broadcast sends a system notification to every agent, such as an announcement of
+// maintenance or a policy change, and returns the number of agents notified. The
+// notifications are inserted in batches and no emails are sent for them.
+func (s *notificationService) Broadcast(ctx context.Context, content, targetType string, targetID uuid.UUID) (int, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return 0, ErrEmptyContent
+	}
+	if _, err := models.ParseTargetType(targetType); err != nil {
+		return 0, err
+	}
+
+	return s.notificationRepo.CreateForAllAgents(ctx, string(NotificationTypeSystem), content, targetType, targetID, BroadcastBatchSize)
+}
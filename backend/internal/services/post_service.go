@@ -3,50 +3,181 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 
 	"github.com/garrettallen/aiboards/backend/internal/database/repository"
+	"github.com/garrettallen/aiboards/backend/internal/metrics"
 	"github.com/garrettallen/aiboards/backend/internal/models"
 )
 
 // PostService handles post-related business logic
 type PostService interface {
 	CreatePost(ctx context.Context, boardID, agentID uuid.UUID, content, mediaURL string) (*models.Post, error)
+	CreatePostWithAttachments(ctx context.Context, boardID, agentID uuid.UUID, content string, attachmentURLs []string) (*models.Post, error)
+	PrepareContent(ctx context.Context, boardID, agentID uuid.UUID, content string) (string, bool, error)
+	AddAttachment(ctx context.Context, postID, agentID uuid.UUID, attachmentURL string) (*models.PostAttachment, error)
+	ListAttachments(ctx context.Context, postID uuid.UUID) ([]*models.PostAttachment, error)
 	GetPostByID(ctx context.Context, id uuid.UUID) (*models.Post, error)
+	GetPostWithReplies(ctx context.Context, id uuid.UUID, sort string, pageSize int) (*PostWithReplies, error)
+	GetBoardForPost(ctx context.Context, postID uuid.UUID) (*models.Board, error)
+	FindPostByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*models.Post, error)
 	GetPostsByBoardID(ctx context.Context, boardID uuid.UUID, page, pageSize int) ([]*models.Post, int, error)
-	GetPostsByAgentID(ctx context.Context, agentID uuid.UUID, page, pageSize int) ([]*models.Post, int, error)
+	GetPostsByBoardIDCursor(ctx context.Context, boardID uuid.UUID, cursor string, pageSize int) ([]*models.Post, string, error)
+	GetPostsByAgentID(ctx context.Context, agentID uuid.UUID, page, pageSize int, authenticated bool) ([]*models.Post, int, error)
+	GetTopPosts(ctx context.Context, boardID uuid.UUID, period string, limit int) ([]*models.Post, error)
+	GetBoardsForAgent(ctx context.Context, agentID uuid.UUID, authenticated bool) ([]*models.BoardWithPostCount, error)
 	UpdatePost(ctx context.Context, post *models.Post) error
 	DeletePost(ctx context.Context, id uuid.UUID) error
+	RemovePostFromBoard(ctx context.Context, postID uuid.UUID, owner BoardActor) error
+	DeletePostsByAgent(ctx context.Context, agentID uuid.UUID, postIDs []uuid.UUID) ([]uuid.UUID, error)
+	RestorePost(ctx context.Context, id uuid.UUID) error
+	SetLocked(ctx context.Context, postID uuid.UUID, caller BoardActor, locked bool) (*models.Post, error)
 	SearchPosts(ctx context.Context, boardID uuid.UUID, query string, page, pageSize int) ([]*models.Post, int, error)
+	SearchAllPosts(ctx context.Context, query string, page, pageSize int, authenticated bool) ([]*models.PostSearchResult, int, error)
+	GetRecentPosts(ctx context.Context, page, pageSize int, authenticated bool, viewerID uuid.UUID) ([]*models.RecentPost, int, error)
+	GetSimilarPosts(ctx context.Context, postID uuid.UUID, limit int, authenticated bool) ([]*models.PostSearchResult, error)
+	CheckBoardReadAccess(ctx context.Context, boardID uuid.UUID, authenticated bool) error
+	CheckPostReadAccess(ctx context.Context, postID uuid.UUID, authenticated bool) error
+	FilterBlockedPosts(ctx context.Context, viewerID uuid.UUID, posts []*models.Post) ([]*models.Post, error)
+	AdminListPosts(ctx context.Context, opts models.PostListOptions, page, pageSize int) ([]*models.Post, int, error)
+	RecountStats(ctx context.Context, postID uuid.UUID) (*models.Post, error)
+	RecountAll(ctx context.Context) (*RecountResult, error)
 }
 
+// RecountResult reports how many denormalized counters were repaired by RecountAll
+type RecountResult struct {
+	PostsFixed   int `json:"posts_fixed"`
+	RepliesFixed int `json:"replies_fixed"`
+}
+
+// PostWithReplies is a post along with its author and the first page of its top-level replies
+type PostWithReplies struct {
+	Post         *models.Post
+	Author       *models.Agent // nil if the author has been deleted
+	Replies      []*models.Reply
+	TotalReplies int // top-level replies across all pages
+}
+
+// MaxPostAttachments is the maximum number of attachments on a single post
+const MaxPostAttachments = 10
+
+// Limits on the number of posts returned by GetTopPosts
+const (
+	DefaultTopPostsLimit = 10
+	MaxTopPostsLimit     = 50
+)
+
+// Limits on the number of posts returned by GetSimilarPosts
+const (
+	DefaultSimilarPostsLimit = 5
+	MaxSimilarPostsLimit     = 20
+)
+
 type postService struct {
 	postRepo  repository.PostRepository
 	boardRepo repository.BoardRepository
 	agentRepo repository.AgentRepository
+	replyRepo repository.ReplyRepository
 	agentSvc  AgentService
+
+	maxContentLength int
+	contentFilter    ContentFilter
+	sanitizer        ContentSanitizer
+	duplicateWindow  time.Duration
+	audit            AuditService
 }
 
-// NewPostService creates a new PostService
+// NewPostService creates a new PostService. A post whose content matches the same agent's
+// previous post on the board within duplicateWindow is rejected; 0 disables the check.
+// Board owners' moderation is recorded with audit; a nil audit records nothing.
 func NewPostService(
 	postRepo repository.PostRepository,
 	boardRepo repository.BoardRepository,
 	agentRepo repository.AgentRepository,
+	replyRepo repository.ReplyRepository,
 	agentSvc AgentService,
+	maxContentLength int,
+	contentFilter ContentFilter,
+	sanitizer ContentSanitizer,
+	duplicateWindow time.Duration,
+	audit AuditService,
 ) PostService {
 	return &postService{
 		postRepo:  postRepo,
 		boardRepo: boardRepo,
 		agentRepo: agentRepo,
+		replyRepo: replyRepo,
 		agentSvc:  agentSvc,
+
+		maxContentLength: maxContentLength,
+		contentFilter:    contentFilter,
+		sanitizer:        sanitizer,
+		duplicateWindow:  duplicateWindow,
+		audit:            audit,
 	}
 }
 
 // CreatePost creates a new post
 func (s *postService) CreatePost(ctx context.Context, boardID, agentID uuid.UUID, content, mediaURL string) (*models.Post, error) {
+	var attachmentURLs []string
+	if mediaURL != "" {
+		attachmentURLs = []string{mediaURL}
+	}
+	return s.CreatePostWithAttachments(ctx, boardID, agentID, content, attachmentURLs)
+}
+
+// PrepareContent runs the checks CreatePost applies to a new post's content without storing
+// anything: the content is sanitized, validated and filtered, and rejected if it repeats the
+// agent's latest post on the board. It returns the content to store and whether it was flagged.
+func (s *postService) PrepareContent(ctx context.Context, boardID, agentID uuid.UUID, content string) (string, bool, error) {
+	content, flagged, err := s.checkContent(content)
+	if err != nil {
+		return "", false, err
+	}
+	if err := s.checkDuplicatePost(ctx, boardID, agentID, content); err != nil {
+		return "", false, err
+	}
+	return content, flagged, nil
+}
+
+// checkContent sanitizes new post content, validates it and runs it through the content
+// filter, returning the sanitized content and whether it was flagged
+func (s *postService) checkContent(content string) (string, bool, error) {
+	content = applyContentSanitizer(s.sanitizer, content)
+	if err := validateContent(content, s.maxContentLength); err != nil {
+		return "", false, err
+	}
+	flagged, err := applyContentFilter(s.contentFilter, content)
+	if err != nil {
+		return "", false, err
+	}
+	return content, flagged, nil
+}
+
+// CreatePostWithAttachments creates a new post with the given attachments, in order.
+// The first attachment also becomes the post's media URL.
+func (s *postService) CreatePostWithAttachments(ctx context.Context, boardID, agentID uuid.UUID, content string, attachmentURLs []string) (*models.Post, error) {
+	// Sanitize, validate and filter content
+	content, flagged, err := s.checkContent(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate attachments
+	if len(attachmentURLs) > MaxPostAttachments {
+		return nil, ErrTooManyAttachments
+	}
+	for _, attachmentURL := range attachmentURLs {
+		if !isValidAttachmentURL(attachmentURL) {
+			return nil, ErrInvalidAttachmentURL
+		}
+	}
+
 	// Check if board exists and is active
 	board, err := s.boardRepo.GetByID(ctx, boardID)
 	if err != nil {
@@ -58,6 +189,12 @@ func (s *postService) CreatePost(ctx context.Context, boardID, agentID uuid.UUID
 	if !board.IsActive {
 		return nil, ErrBoardInactive
 	}
+	if board.IsArchived {
+		return nil, ErrBoardArchived
+	}
+	if !board.CanPost(agentID) {
+		return nil, ErrBoardPostingDisabled
+	}
 
 	// Check if agent exists
 	agent, err := s.agentRepo.GetByID(ctx, agentID)
@@ -67,6 +204,14 @@ func (s *postService) CreatePost(ctx context.Context, boardID, agentID uuid.UUID
 	if agent == nil {
 		return nil, ErrAgentNotFound
 	}
+	if !agent.IsActive {
+		return nil, ErrAgentDeactivated
+	}
+
+	// Reject an immediate repost of the same content
+	if err := s.checkDuplicatePost(ctx, boardID, agentID, content); err != nil {
+		return nil, err
+	}
 
 	// Check rate limit
 	isLimited, err := s.agentSvc.CheckRateLimit(ctx, agentID)
@@ -78,21 +223,22 @@ func (s *postService) CreatePost(ctx context.Context, boardID, agentID uuid.UUID
 	}
 
 	// Create the post
-	now := time.Now()
+	now := models.NowUTC()
 	post := &models.Post{
 		ID:      uuid.New(),
 		BoardID: boardID,
 		AgentID: agentID,
 		Content: content,
 		MediaURL: func() *string {
-			if mediaURL == "" {
+			if len(attachmentURLs) == 0 {
 				return nil
 			} else {
-				return &mediaURL
+				return &attachmentURLs[0]
 			}
 		}(),
 		VoteCount:  0,
 		ReplyCount: 0,
+		IsFlagged:  flagged,
 		CreatedAt:  now,
 		UpdatedAt:  now,
 	}
@@ -104,6 +250,21 @@ func (s *postService) CreatePost(ctx context.Context, boardID, agentID uuid.UUID
 			return err
 		}
 
+		// Save the attachments
+		for i, attachmentURL := range attachmentURLs {
+			attachment := &models.PostAttachment{
+				ID:        uuid.New(),
+				PostID:    post.ID,
+				URL:       attachmentURL,
+				Position:  i,
+				CreatedAt: now,
+			}
+			if err := s.postRepo.CreateAttachment(ctx, attachment); err != nil {
+				return err
+			}
+			post.Attachments = append(post.Attachments, attachment)
+		}
+
 		// Increment agent usage
 		if err := s.agentRepo.IncrementUsage(ctx, agentID); err != nil {
 			return err
@@ -116,10 +277,32 @@ func (s *postService) CreatePost(ctx context.Context, boardID, agentID uuid.UUID
 		return nil, err
 	}
 
+	metrics.PostsCreatedTotal.Inc()
 	return post, nil
 }
 
-// GetPostByID retrieves a post by ID
+// checkDuplicatePost returns ErrDuplicatePost if the agent's latest post on the board has the
+// same content and was made within the duplicate window
+func (s *postService) checkDuplicatePost(ctx context.Context, boardID, agentID uuid.UUID, content string) error {
+	if s.duplicateWindow <= 0 {
+		return nil
+	}
+
+	latest, err := s.postRepo.GetLatestByAgentAndBoard(ctx, agentID, boardID)
+	if err != nil {
+		return err
+	}
+	if latest == nil || time.Since(latest.CreatedAt) > s.duplicateWindow {
+		return nil
+	}
+
+	if latest.Content == content {
+		return ErrDuplicatePost
+	}
+	return nil
+}
+
+// GetPostByID retrieves a post by ID, along with its attachments
 func (s *postService) GetPostByID(ctx context.Context, id uuid.UUID) (*models.Post, error) {
 	post, err := s.postRepo.GetByID(ctx, id)
 	if err != nil {
@@ -128,9 +311,116 @@ func (s *postService) GetPostByID(ctx context.Context, id uuid.UUID) (*models.Po
 	if post == nil {
 		return nil, ErrPostNotFound
 	}
+
+	post.Attachments, err = s.postRepo.GetAttachments(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// GetBoardForPost retrieves the board a post was created under
+func (s *postService) GetBoardForPost(ctx context.Context, postID uuid.UUID) (*models.Board, error) {
+	post, err := s.postRepo.GetByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, ErrPostNotFound
+	}
+
+	board, err := s.boardRepo.GetByID(ctx, post.BoardID)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil {
+		return nil, ErrBoardNotFound
+	}
+	return board, nil
+}
+
+// GetPostWithReplies retrieves a post, its author and the first pageSize of its direct
+// replies in the given sort order, so a post view can be loaded in one call
+func (s *postService) GetPostWithReplies(ctx context.Context, id uuid.UUID, sort string, pageSize int) (*PostWithReplies, error) {
+	// Validate sort option
+	if sort == "" {
+		sort = models.ReplySortOld
+	}
+	if _, ok := models.ReplySortOrders[sort]; !ok {
+		return nil, ErrInvalidSortField
+	}
+
+	post, err := s.GetPostByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	author, err := s.agentRepo.GetByID(ctx, post.AgentID)
+	if err != nil {
+		return nil, err
+	}
+
+	parentType := string(models.ParentTypePost)
+	replies, err := s.replyRepo.GetByParentID(ctx, parentType, id, sort, 0, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	totalReplies, err := s.replyRepo.CountByParentID(ctx, parentType, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostWithReplies{
+		Post:         post,
+		Author:       author,
+		Replies:      replies,
+		TotalReplies: totalReplies,
+	}, nil
+}
+
+// FindPostByID retrieves a post by ID, optionally including soft-deleted posts (admin only)
+func (s *postService) FindPostByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*models.Post, error) {
+	post, err := s.postRepo.FindByID(ctx, id, includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, ErrPostNotFound
+	}
 	return post, nil
 }
 
+// GetTopPosts retrieves a board's highest-voted posts created within the period
+// ("day", "week" or "month"; empty means "week"). limit is clamped to MaxTopPostsLimit.
+func (s *postService) GetTopPosts(ctx context.Context, boardID uuid.UUID, period string, limit int) ([]*models.Post, error) {
+	if period == "" {
+		period = models.TopPostsPeriodWeek
+	}
+	window, ok := models.TopPostsPeriods[period]
+	if !ok {
+		return nil, ErrInvalidPeriod
+	}
+
+	if limit <= 0 {
+		limit = DefaultTopPostsLimit
+	}
+	if limit > MaxTopPostsLimit {
+		limit = MaxTopPostsLimit
+	}
+
+	// Check if board exists
+	board, err := s.boardRepo.GetByID(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil {
+		return nil, ErrBoardNotFound
+	}
+
+	return s.postRepo.GetTopByBoardID(ctx, boardID, models.NowUTC().Add(-window), limit)
+}
+
 // GetPostsByBoardID retrieves posts for a board with pagination
 func (s *postService) GetPostsByBoardID(ctx context.Context, boardID uuid.UUID, page, pageSize int) ([]*models.Post, int, error) {
 	// Check if board exists
@@ -163,8 +453,49 @@ func (s *postService) GetPostsByBoardID(ctx context.Context, boardID uuid.UUID,
 	return posts, count, nil
 }
 
-// GetPostsByAgentID retrieves posts created by an agent with pagination
-func (s *postService) GetPostsByAgentID(ctx context.Context, agentID uuid.UUID, page, pageSize int) ([]*models.Post, int, error) {
+// GetPostsByBoardIDCursor retrieves a page of a board's posts, newest first, using keyset
+// pagination so posts created between page fetches don't shift later pages. An empty cursor
+// starts from the newest post; the returned cursor is empty on the last page.
+func (s *postService) GetPostsByBoardIDCursor(ctx context.Context, boardID uuid.UUID, cursor string, pageSize int) ([]*models.Post, string, error) {
+	var beforeCreatedAt *time.Time
+	var beforeID uuid.UUID
+	if cursor != "" {
+		createdAt, id, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		beforeCreatedAt = &createdAt
+		beforeID = id
+	}
+
+	// Check if board exists
+	board, err := s.boardRepo.GetByID(ctx, boardID)
+	if err != nil {
+		return nil, "", err
+	}
+	if board == nil {
+		return nil, "", ErrBoardNotFound
+	}
+
+	// Fetch one extra post to learn whether there is a next page
+	posts, err := s.postRepo.GetByBoardIDBefore(ctx, boardID, beforeCreatedAt, beforeID, pageSize+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(posts) > pageSize {
+		posts = posts[:pageSize]
+		last := posts[len(posts)-1]
+		nextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return posts, nextCursor, nil
+}
+
+// GetPostsByAgentID retrieves posts created by an agent with pagination. Posts on boards
+// that require authentication are left out for anonymous callers.
+func (s *postService) GetPostsByAgentID(ctx context.Context, agentID uuid.UUID, page, pageSize int, authenticated bool) ([]*models.Post, int, error) {
 	// Check if agent exists
 	agent, err := s.agentRepo.GetByID(ctx, agentID)
 	if err != nil {
@@ -181,13 +512,13 @@ func (s *postService) GetPostsByAgentID(ctx context.Context, agentID uuid.UUID,
 	}
 
 	// Get posts
-	posts, err := s.postRepo.GetByAgentID(ctx, agentID, offset, pageSize)
+	posts, err := s.postRepo.GetByAgentID(ctx, agentID, authenticated, offset, pageSize)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	// Get total count
-	count, err := s.postRepo.CountByAgentID(ctx, agentID)
+	count, err := s.postRepo.CountByAgentID(ctx, agentID, authenticated)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -195,8 +526,35 @@ func (s *postService) GetPostsByAgentID(ctx context.Context, agentID uuid.UUID,
 	return posts, count, nil
 }
 
+// GetBoardsForAgent retrieves the boards an agent has posted to, with post counts. Boards
+// that require authentication are left out for anonymous callers.
+func (s *postService) GetBoardsForAgent(ctx context.Context, agentID uuid.UUID, authenticated bool) ([]*models.BoardWithPostCount, error) {
+	// Check if agent exists
+	agent, err := s.agentRepo.GetByID(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	if agent == nil {
+		return nil, ErrAgentNotFound
+	}
+
+	return s.postRepo.GetBoardsByAgentID(ctx, agentID, authenticated)
+}
+
 // UpdatePost updates an existing post
 func (s *postService) UpdatePost(ctx context.Context, post *models.Post) error {
+	// Sanitize and validate content
+	post.Content = applyContentSanitizer(s.sanitizer, post.Content)
+	if err := validateContent(post.Content, s.maxContentLength); err != nil {
+		return err
+	}
+
+	// Run the content filter, so an edit can't bring in what a new post couldn't
+	flagged, err := applyContentFilter(s.contentFilter, post.Content)
+	if err != nil {
+		return err
+	}
+
 	// Check if post exists
 	existingPost, err := s.postRepo.GetByID(ctx, post.ID)
 	if err != nil {
@@ -211,9 +569,20 @@ func (s *postService) UpdatePost(ctx context.Context, post *models.Post) error {
 		return errors.New("agent does not own this post")
 	}
 
+	// Only a change to content or media counts as an edit
+	if contentChanged(existingPost.Content, post.Content, existingPost.MediaURL, post.MediaURL) {
+		now := models.NowUTC()
+		post.EditedAt = &now
+	} else {
+		post.EditedAt = existingPost.EditedAt
+	}
+
+	// An edit never clears an existing flag
+	post.IsFlagged = existingPost.IsFlagged || flagged
+
 	// Update the post
-	post.UpdatedAt = time.Now()
-	return s.postRepo.Update(ctx, post)
+	post.UpdatedAt = models.NowUTC()
+	return s.postRepo.UpdateContent(ctx, post)
 }
 
 // DeletePost soft-deletes a post
@@ -228,7 +597,180 @@ func (s *postService) DeletePost(ctx context.Context, id uuid.UUID) error {
 	}
 
 	// Delete the post
-	return s.postRepo.Delete(ctx, id)
+	return s.deletePostAndReplies(ctx, id)
+}
+
+// deletePostAndReplies soft-deletes a post together with the replies in its thread, so they
+// aren't left orphaned in reply listings. RestorePost brings them back with the post.
+func (s *postService) deletePostAndReplies(ctx context.Context, id uuid.UUID) error {
+	return s.postRepo.Transaction(ctx, func(tx *sqlx.Tx) error {
+		if err := s.postRepo.Delete(ctx, tx, id); err != nil {
+			return err
+		}
+		_, err := s.replyRepo.DeleteThread(ctx, tx, id)
+		return err
+	})
+}
+
+// RemovePostFromBoard soft-deletes a post on behalf of its board's owner, so owners can
+// moderate their boards without admin rights. Once the post is deleted the removal is
+// recorded in the audit log against the owning user.
+func (s *postService) RemovePostFromBoard(ctx context.Context, postID uuid.UUID, owner BoardActor) error {
+	post, err := s.postRepo.GetByID(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if post == nil {
+		return ErrPostNotFound
+	}
+
+	board, err := s.boardRepo.GetByID(ctx, post.BoardID)
+	if err != nil {
+		return err
+	}
+	if board == nil || !owner.Owns(board) {
+		return ErrNotBoardOwner
+	}
+
+	if s.audit == nil {
+		return s.deletePostAndReplies(ctx, postID)
+	}
+
+	// Work out who to record the removal against before deleting anything, so a failed
+	// lookup leaves the post in place
+	userID, details, err := s.describeRemoval(ctx, owner, board, post)
+	if err != nil {
+		return err
+	}
+	if err := s.deletePostAndReplies(ctx, postID); err != nil {
+		return err
+	}
+
+	_, err = s.audit.Record(ctx, userID, models.AuditActionRemovePost, "post", post.ID, details)
+	return err
+}
+
+// describeRemoval returns the user a board owner's post removal is recorded against and the
+// audit details: the user managing a board they own, or else the user of the owning agent
+func (s *postService) describeRemoval(ctx context.Context, owner BoardActor, board *models.Board, post *models.Post) (uuid.UUID, string, error) {
+	if board.UserID != nil {
+		details := fmt.Sprintf("user %s removed post by agent %s from board %s", owner.User.Name, post.AgentID, board.ID)
+		return owner.User.ID, details, nil
+	}
+
+	agent, err := s.agentRepo.GetByID(ctx, owner.AgentID)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	if agent == nil {
+		return uuid.Nil, "", ErrAgentNotFound
+	}
+	details := fmt.Sprintf("agent %s removed post by agent %s from board %s", agent.Name, post.AgentID, board.ID)
+	return agent.UserID, details, nil
+}
+
+// MaxBulkDeletePosts is the maximum number of distinct post IDs that can be deleted at once
+const MaxBulkDeletePosts = 100
+
+// DeletePostsByAgent soft-deletes the given posts that the agent wrote and returns the IDs
+// that were deleted. Posts by other agents and unknown IDs are ignored rather than failing
+// the batch.
+func (s *postService) DeletePostsByAgent(ctx context.Context, agentID uuid.UUID, postIDs []uuid.UUID) ([]uuid.UUID, error) {
+	// Dedupe IDs
+	seen := make(map[uuid.UUID]bool, len(postIDs))
+	unique := make([]uuid.UUID, 0, len(postIDs))
+	for _, id := range postIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	if len(unique) > MaxBulkDeletePosts {
+		return nil, ErrTooManyPostIDs
+	}
+
+	var deleted []uuid.UUID
+	err := s.postRepo.Transaction(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		deleted, err = s.postRepo.DeleteByAgentID(ctx, tx, agentID, unique)
+		if err != nil {
+			return err
+		}
+
+		// The deleted posts' replies go with them
+		for _, id := range deleted {
+			if _, err := s.replyRepo.DeleteThread(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+// RestorePost restores a soft-deleted post
+func (s *postService) RestorePost(ctx context.Context, id uuid.UUID) error {
+	// Check if post exists, including deleted posts
+	post, err := s.postRepo.FindByID(ctx, id, true)
+	if err != nil {
+		return err
+	}
+	if post == nil {
+		return ErrPostNotFound
+	}
+
+	// Restore the post along with the replies deleted with it
+	return s.postRepo.Transaction(ctx, func(tx *sqlx.Tx) error {
+		if _, err := s.replyRepo.RestoreThread(ctx, tx, id); err != nil {
+			return err
+		}
+		return s.postRepo.Restore(ctx, tx, id)
+	})
+}
+
+// SetLocked locks or unlocks a post. A locked post accepts no new replies anywhere in its
+// thread. Only the owner of the post's board, an admin or an agent belonging to an admin can
+// lock it.
+func (s *postService) SetLocked(ctx context.Context, postID uuid.UUID, caller BoardActor, locked bool) (*models.Post, error) {
+	// Check if post exists
+	post, err := s.postRepo.GetByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, ErrPostNotFound
+	}
+
+	// Check the caller owns the board or is an admin
+	board, err := s.boardRepo.GetByID(ctx, post.BoardID)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil || !caller.Owns(board) {
+		isAdmin := caller.User != nil && caller.User.IsAdmin
+		if !isAdmin && caller.AgentID != uuid.Nil {
+			isAdmin, err = s.agentSvc.IsOwnedByAdmin(ctx, caller.AgentID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !isAdmin {
+			return nil, ErrNotPostModerator
+		}
+	}
+
+	if err := s.postRepo.SetLocked(ctx, postID, locked); err != nil {
+		return nil, err
+	}
+
+	post.IsLocked = locked
+	return post, nil
 }
 
 // SearchPosts searches for posts by content within a specific board
@@ -267,3 +809,275 @@ func (s *postService) SearchPosts(ctx context.Context, boardID uuid.UUID, query
 
 	return posts, count, nil
 }
+
+// SearchAllPosts runs a full-text search over posts on every active board, most relevant first.
+// Boards that require authentication are left out for anonymous callers.
+func (s *postService) SearchAllPosts(ctx context.Context, query string, page, pageSize int, authenticated bool) ([]*models.PostSearchResult, int, error) {
+	// Calculate offset
+	offset := (page - 1) * pageSize
+	if offset < 0 {
+		offset = 0
+	}
+
+	// Get posts matching the search query
+	posts, err := s.postRepo.SearchAll(ctx, query, authenticated, offset, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Get total count of matching posts
+	count, err := s.postRepo.CountSearchAll(ctx, query, authenticated)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return posts, count, nil
+}
+
+// GetRecentPosts retrieves the newest posts across every active board, with board and
+// author context, so clients have something to show before a board is picked. Boards that
+// require authentication are left out for anonymous callers, and posts by agents viewerID
+// has blocked are left out; viewerID is uuid.Nil for callers that aren't agents.
+func (s *postService) GetRecentPosts(ctx context.Context, page, pageSize int, authenticated bool, viewerID uuid.UUID) ([]*models.RecentPost, int, error) {
+	// Calculate offset
+	offset := (page - 1) * pageSize
+	if offset < 0 {
+		offset = 0
+	}
+
+	posts, err := s.postRepo.GetRecent(ctx, viewerID, authenticated, offset, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.postRepo.CountRecent(ctx, viewerID, authenticated)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return posts, count, nil
+}
+
+// CheckBoardReadAccess returns ErrBoardRequiresAuth if the board's posts may only be read
+// by authenticated clients and the caller isn't one
+func (s *postService) CheckBoardReadAccess(ctx context.Context, boardID uuid.UUID, authenticated bool) error {
+	return checkBoardReadable(ctx, s.boardRepo, boardID, authenticated)
+}
+
+// CheckPostReadAccess returns ErrBoardRequiresAuth if the post is on a board that may only
+// be read by authenticated clients and the caller isn't one. A missing post is left for the
+// caller's own lookup to report.
+func (s *postService) CheckPostReadAccess(ctx context.Context, postID uuid.UUID, authenticated bool) error {
+	if authenticated {
+		return nil
+	}
+	post, err := s.postRepo.GetByID(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if post == nil {
+		return nil
+	}
+	return checkBoardReadable(ctx, s.boardRepo, post.BoardID, authenticated)
+}
+
+// FilterBlockedPosts removes posts written by agents viewerID has blocked
+func (s *postService) FilterBlockedPosts(ctx context.Context, viewerID uuid.UUID, posts []*models.Post) ([]*models.Post, error) {
+	blocked, err := blockedAgentSet(ctx, s.agentRepo, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocked) == 0 {
+		return posts, nil
+	}
+
+	filtered := make([]*models.Post, 0, len(posts))
+	for _, post := range posts {
+		if !blocked[post.AgentID] {
+			filtered = append(filtered, post)
+		}
+	}
+
+	return filtered, nil
+}
+
+// GetSimilarPosts finds posts across active boards whose content overlaps the given
+// post's, most similar first. limit is clamped to MaxSimilarPostsLimit. Boards that require
+// authentication are left out for anonymous callers.
+func (s *postService) GetSimilarPosts(ctx context.Context, postID uuid.UUID, limit int, authenticated bool) ([]*models.PostSearchResult, error) {
+	if limit <= 0 {
+		limit = DefaultSimilarPostsLimit
+	}
+	if limit > MaxSimilarPostsLimit {
+		limit = MaxSimilarPostsLimit
+	}
+
+	// Check if post exists
+	post, err := s.postRepo.GetByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, ErrPostNotFound
+	}
+
+	return s.postRepo.GetSimilar(ctx, postID, authenticated, limit)
+}
+
+// AdminListPosts lists posts across all boards for admins, optionally filtered by a
+// creation time window and agent. Soft-deleted posts are included.
+func (s *postService) AdminListPosts(ctx context.Context, opts models.PostListOptions, page, pageSize int) ([]*models.Post, int, error) {
+	// Validate time range
+	if opts.Since != nil && opts.Until != nil && opts.Since.After(*opts.Until) {
+		return nil, 0, ErrInvalidTimeRange
+	}
+
+	// Calculate offset
+	offset := (page - 1) * pageSize
+	if offset < 0 {
+		offset = 0
+	}
+
+	// Get posts
+	posts, err := s.postRepo.AdminList(ctx, opts, offset, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Get total count
+	count, err := s.postRepo.AdminCount(ctx, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return posts, count, nil
+}
+
+// RecountStats recomputes a post's reply and vote counts from the source tables
+func (s *postService) RecountStats(ctx context.Context, postID uuid.UUID) (*models.Post, error) {
+	// Check if post exists
+	post, err := s.postRepo.GetByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, ErrPostNotFound
+	}
+
+	// Repair the counters
+	if _, err := s.postRepo.RecountStats(ctx, postID); err != nil {
+		return nil, err
+	}
+
+	// Return the post with its corrected counts
+	return s.postRepo.GetByID(ctx, postID)
+}
+
+// RecountAll recomputes the reply and vote counts of every post and reply
+func (s *postService) RecountAll(ctx context.Context) (*RecountResult, error) {
+	result := &RecountResult{}
+
+	// Execute operations in a transaction
+	err := s.postRepo.Transaction(ctx, func(tx *sqlx.Tx) error {
+		postsFixed, err := s.postRepo.RecountAllStats(ctx)
+		if err != nil {
+			return err
+		}
+		result.PostsFixed = postsFixed
+
+		repliesFixed, err := s.replyRepo.RecountAllStats(ctx)
+		if err != nil {
+			return err
+		}
+		result.RepliesFixed = repliesFixed
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// AddAttachment appends an attachment to a post. Only the post's author can add
+// attachments, and a post holds at most MaxPostAttachments.
+func (s *postService) AddAttachment(ctx context.Context, postID, agentID uuid.UUID, attachmentURL string) (*models.PostAttachment, error) {
+	// Validate URL
+	if !isValidAttachmentURL(attachmentURL) {
+		return nil, ErrInvalidAttachmentURL
+	}
+
+	// Check if post exists and belongs to the agent
+	post, err := s.postRepo.GetByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, ErrPostNotFound
+	}
+	if post.AgentID != agentID {
+		return nil, ErrNotPostAuthor
+	}
+
+	// Check the attachment limit
+	count, err := s.postRepo.CountAttachments(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= MaxPostAttachments {
+		return nil, ErrTooManyAttachments
+	}
+
+	attachment := &models.PostAttachment{
+		ID:        uuid.New(),
+		PostID:    postID,
+		URL:       attachmentURL,
+		Position:  count,
+		CreatedAt: models.NowUTC(),
+	}
+
+	err = s.postRepo.Transaction(ctx, func(tx *sqlx.Tx) error {
+		if err := s.postRepo.CreateAttachment(ctx, attachment); err != nil {
+			return err
+		}
+
+		// The first attachment doubles as the media URL
+		if post.MediaURL == nil {
+			post.MediaURL = &attachment.URL
+			now := models.NowUTC()
+			post.EditedAt = &now
+			if err := s.postRepo.UpdateContent(ctx, post); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// ListAttachments retrieves a post's attachments in order
+func (s *postService) ListAttachments(ctx context.Context, postID uuid.UUID) ([]*models.PostAttachment, error) {
+	// Check if post exists
+	post, err := s.postRepo.GetByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, ErrPostNotFound
+	}
+
+	return s.postRepo.GetAttachments(ctx, postID)
+}
+
+// isValidAttachmentURL checks that an attachment URL is an absolute http(s) URL
+func isValidAttachmentURL(attachmentURL string) bool {
+	parsed, err := url.Parse(attachmentURL)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -3,58 +3,112 @@ package services
 import (
 	"context"
 	"errors"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 
 	"github.com/garrettallen/aiboards/backend/internal/database/repository"
+	"github.com/garrettallen/aiboards/backend/internal/metrics"
 	"github.com/garrettallen/aiboards/backend/internal/models"
 )
 
 // ReplyService handles reply-related business logic
 type ReplyService interface {
-	CreateReply(ctx context.Context, parentType string, parentID, agentID uuid.UUID, content, mediaURL string) (*models.Reply, error)
+	CreateReply(ctx context.Context, parentType string, parentID, agentID uuid.UUID, content, mediaURL string, quotedReplyID *uuid.UUID) (*models.Reply, error)
 	GetReplyByID(ctx context.Context, id uuid.UUID) (*models.Reply, error)
-	GetRepliesByParentID(ctx context.Context, parentType string, parentID uuid.UUID, page, pageSize int) ([]*models.Reply, int, error)
-	GetRepliesByAgentID(ctx context.Context, agentID uuid.UUID, page, pageSize int) ([]*models.Reply, int, error)
+	FindReplyByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*models.Reply, error)
+	GetRepliesByParentID(ctx context.Context, parentType string, parentID uuid.UUID, sort string, page, pageSize int) ([]*models.Reply, int, error)
+	CountRepliesByParentID(ctx context.Context, parentType string, parentID uuid.UUID) (int, error)
+	GetRepliesByAgentID(ctx context.Context, agentID uuid.UUID, page, pageSize int, authenticated bool) ([]*models.Reply, int, error)
+	GetReplyHistoryByAgentID(ctx context.Context, agentID uuid.UUID, page, pageSize int, authenticated bool) ([]*models.ReplyWithContext, int, error)
 	GetThreadedReplies(ctx context.Context, postID uuid.UUID) ([]*models.Reply, error)
-	UpdateReply(ctx context.Context, reply *models.Reply) error
+	GetAncestors(ctx context.Context, replyID uuid.UUID) (*ReplyAncestors, error)
+	CheckReadAccess(ctx context.Context, parentType string, parentID uuid.UUID, authenticated bool) error
+	FilterBlockedReplies(ctx context.Context, viewerID uuid.UUID, replies []*models.Reply) ([]*models.Reply, error)
+	UpdateReply(ctx context.Context, reply *models.Reply, isAdmin bool) error
 	DeleteReply(ctx context.Context, id uuid.UUID) error
+	RestoreReply(ctx context.Context, id uuid.UUID) error
+	MarkAccepted(ctx context.Context, replyID, postAuthorAgentID uuid.UUID) (*models.Reply, error)
+}
+
+// ReplyAncestors is the path from a reply up to the post at the root of its thread
+type ReplyAncestors struct {
+	Replies []*models.Reply // the reply first, then each parent reply in turn
+	Post    *models.Post
 }
 
 type replyService struct {
 	replyRepo repository.ReplyRepository
 	postRepo  repository.PostRepository
+	boardRepo repository.BoardRepository
 	agentRepo repository.AgentRepository
 	agentSvc  AgentService
+
+	maxContentLength int
+	contentFilter    ContentFilter
+	sanitizer        ContentSanitizer
+	editWindow       time.Duration
+	notificationSvc  NotificationService
 }
 
-// NewReplyService creates a new ReplyService
+// NewReplyService creates a new ReplyService. Replies may only be edited for editWindow
+// after they are created, except by admins; zero or less allows edits at any time. New replies
+// are announced through notificationSvc, if set.
 func NewReplyService(
 	replyRepo repository.ReplyRepository,
 	postRepo repository.PostRepository,
+	boardRepo repository.BoardRepository,
 	agentRepo repository.AgentRepository,
 	agentSvc AgentService,
+	maxContentLength int,
+	contentFilter ContentFilter,
+	sanitizer ContentSanitizer,
+	editWindow time.Duration,
+	notificationSvc NotificationService,
 ) ReplyService {
 	return &replyService{
 		replyRepo: replyRepo,
 		postRepo:  postRepo,
+		boardRepo: boardRepo,
 		agentRepo: agentRepo,
 		agentSvc:  agentSvc,
+
+		maxContentLength: maxContentLength,
+		contentFilter:    contentFilter,
+		sanitizer:        sanitizer,
+		editWindow:       editWindow,
+		notificationSvc:  notificationSvc,
 	}
 }
 
-// CreateReply creates a new reply
-func (s *replyService) CreateReply(ctx context.Context, parentType string, parentID, agentID uuid.UUID, content, mediaURL string) (*models.Reply, error) {
+// CreateReply creates a new reply. quotedReplyID optionally links a reply being quoted,
+// which must belong to the same post's thread as the new reply.
+func (s *replyService) CreateReply(ctx context.Context, parentType string, parentID, agentID uuid.UUID, content, mediaURL string, quotedReplyID *uuid.UUID) (*models.Reply, error) {
+	// Sanitize and validate content
+	content = applyContentSanitizer(s.sanitizer, content)
+	if err := validateContent(content, s.maxContentLength); err != nil {
+		return nil, err
+	}
+
+	// Run the content filter
+	flagged, err := applyContentFilter(s.contentFilter, content)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate parent type
-	if parentType != "post" && parentType != "reply" {
-		return nil, ErrInvalidParentType
+	pt, err := models.ParseParentType(parentType)
+	if err != nil {
+		return nil, err
 	}
 
-	// Check if parent exists
-	if parentType == "post" {
-		post, err := s.postRepo.GetByID(ctx, parentID)
+	// Check if parent exists, and find the post at the top of the thread
+	var post *models.Post
+	var parentReply *models.Reply // nil when replying to the post
+	if pt == models.ParentTypePost {
+		post, err = s.postRepo.GetByID(ctx, parentID)
 		if err != nil {
 			return nil, err
 		}
@@ -63,13 +117,41 @@ func (s *replyService) CreateReply(ctx context.Context, parentType string, paren
 		}
 	} else {
 		// Parent is a reply
-		parentReply, err := s.replyRepo.GetByID(ctx, parentID)
+		parentReply, err = s.replyRepo.GetByID(ctx, parentID)
 		if err != nil {
 			return nil, err
 		}
 		if parentReply == nil {
 			return nil, ErrParentNotFound
 		}
+		postID, err := s.replyRepo.GetPostID(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		post, err = s.postRepo.GetByID(ctx, postID)
+		if err != nil {
+			return nil, err
+		}
+		if post == nil {
+			return nil, ErrParentNotFound
+		}
+	}
+
+	// Locked posts don't accept replies anywhere in their thread
+	if post.IsLocked {
+		return nil, ErrPostLocked
+	}
+
+	// Archived boards don't accept replies
+	if err := s.checkBoardNotArchived(ctx, post.BoardID); err != nil {
+		return nil, err
+	}
+
+	// Check the quoted reply is part of the same thread
+	if quotedReplyID != nil {
+		if err := s.validateQuotedReply(ctx, pt, parentID, *quotedReplyID); err != nil {
+			return nil, err
+		}
 	}
 
 	// Check if agent exists
@@ -80,6 +162,9 @@ func (s *replyService) CreateReply(ctx context.Context, parentType string, paren
 	if agent == nil {
 		return nil, ErrAgentNotFound
 	}
+	if !agent.IsActive {
+		return nil, ErrAgentDeactivated
+	}
 
 	// Check rate limit
 	isLimited, err := s.agentSvc.CheckRateLimit(ctx, agentID)
@@ -91,7 +176,7 @@ func (s *replyService) CreateReply(ctx context.Context, parentType string, paren
 	}
 
 	// Create the reply
-	now := time.Now()
+	now := models.NowUTC()
 	reply := &models.Reply{
 		ID:         uuid.New(),
 		ParentType: parentType,
@@ -105,10 +190,12 @@ func (s *replyService) CreateReply(ctx context.Context, parentType string, paren
 				return &mediaURL
 			}
 		}(),
-		VoteCount:  0,
-		ReplyCount: 0,
-		CreatedAt:  now,
-		UpdatedAt:  now,
+		QuotedReplyID: quotedReplyID,
+		VoteCount:     0,
+		ReplyCount:    0,
+		IsFlagged:     flagged,
+		CreatedAt:     now,
+		UpdatedAt:     now,
 	}
 
 	// Execute operations in a transaction
@@ -119,7 +206,7 @@ func (s *replyService) CreateReply(ctx context.Context, parentType string, paren
 		}
 
 		// Update parent's reply count
-		if parentType == "post" {
+		if pt == models.ParentTypePost {
 			if err := s.postRepo.UpdateReplyCount(ctx, parentID, 1); err != nil {
 				return err
 			}
@@ -141,9 +228,47 @@ func (s *replyService) CreateReply(ctx context.Context, parentType string, paren
 		return nil, err
 	}
 
+	metrics.RepliesCreatedTotal.Inc()
+
+	// The reply is saved either way, so a failed notification is only logged
+	if s.notificationSvc != nil {
+		if err := s.notificationSvc.NotifyOnReply(ctx, reply, post, parentReply); err != nil {
+			log.Printf("Failed to notify about reply %s: %v", reply.ID, err)
+		}
+	}
+
 	return reply, nil
 }
 
+// validateQuotedReply checks that quotedReplyID exists and sits under the same post as a
+// new reply with the given parent
+func (s *replyService) validateQuotedReply(ctx context.Context, parentType models.ParentType, parentID, quotedReplyID uuid.UUID) error {
+	quoted, err := s.replyRepo.GetByID(ctx, quotedReplyID)
+	if err != nil {
+		return err
+	}
+	if quoted == nil {
+		return ErrQuotedReplyNotFound
+	}
+
+	postID := parentID
+	if parentType == models.ParentTypeReply {
+		if postID, err = s.replyRepo.GetPostID(ctx, parentID); err != nil {
+			return err
+		}
+	}
+
+	quotedPostID, err := s.replyRepo.GetPostID(ctx, quoted.ID)
+	if err != nil {
+		return err
+	}
+	if quotedPostID != postID {
+		return ErrQuotedReplyOtherThread
+	}
+
+	return nil
+}
+
 // GetReplyByID retrieves a reply by ID
 func (s *replyService) GetReplyByID(ctx context.Context, id uuid.UUID) (*models.Reply, error) {
 	reply, err := s.replyRepo.GetByID(ctx, id)
@@ -156,15 +281,37 @@ func (s *replyService) GetReplyByID(ctx context.Context, id uuid.UUID) (*models.
 	return reply, nil
 }
 
-// GetRepliesByParentID retrieves replies for a parent with pagination
-func (s *replyService) GetRepliesByParentID(ctx context.Context, parentType string, parentID uuid.UUID, page, pageSize int) ([]*models.Reply, int, error) {
+// FindReplyByID retrieves a reply by ID, optionally including soft-deleted replies (admin only)
+func (s *replyService) FindReplyByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*models.Reply, error) {
+	reply, err := s.replyRepo.FindByID(ctx, id, includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, ErrReplyNotFound
+	}
+	return reply, nil
+}
+
+// GetRepliesByParentID retrieves replies for a parent with pagination. sort is one of
+// "old" (the default when empty), "new" or "top".
+func (s *replyService) GetRepliesByParentID(ctx context.Context, parentType string, parentID uuid.UUID, sort string, page, pageSize int) ([]*models.Reply, int, error) {
 	// Validate parent type
-	if parentType != "post" && parentType != "reply" {
-		return nil, 0, ErrInvalidParentType
+	pt, err := models.ParseParentType(parentType)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Validate sort option
+	if sort == "" {
+		sort = models.ReplySortOld
+	}
+	if _, ok := models.ReplySortOrders[sort]; !ok {
+		return nil, 0, ErrInvalidSortField
 	}
 
 	// Check if parent exists
-	if parentType == "post" {
+	if pt == models.ParentTypePost {
 		post, err := s.postRepo.GetByID(ctx, parentID)
 		if err != nil {
 			return nil, 0, err
@@ -190,7 +337,7 @@ func (s *replyService) GetRepliesByParentID(ctx context.Context, parentType stri
 	}
 
 	// Get replies
-	replies, err := s.replyRepo.GetByParentID(ctx, parentType, parentID, offset, pageSize)
+	replies, err := s.replyRepo.GetByParentID(ctx, parentType, parentID, sort, offset, pageSize)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -204,8 +351,41 @@ func (s *replyService) GetRepliesByParentID(ctx context.Context, parentType stri
 	return replies, count, nil
 }
 
-// GetRepliesByAgentID retrieves replies created by an agent with pagination
-func (s *replyService) GetRepliesByAgentID(ctx context.Context, agentID uuid.UUID, page, pageSize int) ([]*models.Reply, int, error) {
+// CountRepliesByParentID counts the non-deleted replies for a parent without fetching them
+func (s *replyService) CountRepliesByParentID(ctx context.Context, parentType string, parentID uuid.UUID) (int, error) {
+	// Validate parent type
+	pt, err := models.ParseParentType(parentType)
+	if err != nil {
+		return 0, err
+	}
+
+	// Check if parent exists
+	if pt == models.ParentTypePost {
+		post, err := s.postRepo.GetByID(ctx, parentID)
+		if err != nil {
+			return 0, err
+		}
+		if post == nil {
+			return 0, ErrPostNotFound
+		}
+	} else {
+		// Parent is a reply
+		parentReply, err := s.replyRepo.GetByID(ctx, parentID)
+		if err != nil {
+			return 0, err
+		}
+		if parentReply == nil {
+			return 0, ErrParentNotFound
+		}
+	}
+
+	// Count from the replies table rather than trusting the denormalized counter
+	return s.replyRepo.CountByParentID(ctx, parentType, parentID)
+}
+
+// GetRepliesByAgentID retrieves replies created by an agent with pagination. Replies in
+// threads on boards that require authentication are left out for anonymous callers.
+func (s *replyService) GetRepliesByAgentID(ctx context.Context, agentID uuid.UUID, page, pageSize int, authenticated bool) ([]*models.Reply, int, error) {
 	// Check if agent exists
 	agent, err := s.agentRepo.GetByID(ctx, agentID)
 	if err != nil {
@@ -222,13 +402,46 @@ func (s *replyService) GetRepliesByAgentID(ctx context.Context, agentID uuid.UUI
 	}
 
 	// Get replies
-	replies, err := s.replyRepo.GetByAgentID(ctx, agentID, offset, pageSize)
+	replies, err := s.replyRepo.GetByAgentID(ctx, agentID, authenticated, offset, pageSize)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	// Get total count
-	count, err := s.replyRepo.CountByAgentID(ctx, agentID)
+	count, err := s.replyRepo.CountByAgentID(ctx, agentID, authenticated)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return replies, count, nil
+}
+
+// GetReplyHistoryByAgentID retrieves an agent's replies with pagination, like
+// GetRepliesByAgentID, along with the post, board and parent content each was made in
+func (s *replyService) GetReplyHistoryByAgentID(ctx context.Context, agentID uuid.UUID, page, pageSize int, authenticated bool) ([]*models.ReplyWithContext, int, error) {
+	// Check if agent exists
+	agent, err := s.agentRepo.GetByID(ctx, agentID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if agent == nil {
+		return nil, 0, ErrAgentNotFound
+	}
+
+	// Calculate offset
+	offset := (page - 1) * pageSize
+	if offset < 0 {
+		offset = 0
+	}
+
+	// Get replies
+	replies, err := s.replyRepo.GetByAgentIDWithContext(ctx, agentID, authenticated, offset, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Get total count
+	count, err := s.replyRepo.CountByAgentID(ctx, agentID, authenticated)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -251,8 +464,123 @@ func (s *replyService) GetThreadedReplies(ctx context.Context, postID uuid.UUID)
 	return s.replyRepo.GetThreadedReplies(ctx, postID)
 }
 
-// UpdateReply updates an existing reply
-func (s *replyService) UpdateReply(ctx context.Context, reply *models.Reply) error {
+// GetAncestors retrieves the chain from a reply up to the post its thread belongs to, so a
+// deep reply can be shown with its context
+func (s *replyService) GetAncestors(ctx context.Context, replyID uuid.UUID) (*ReplyAncestors, error) {
+	// Check if reply exists
+	reply, err := s.replyRepo.GetByID(ctx, replyID)
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, ErrReplyNotFound
+	}
+
+	replies, err := s.replyRepo.GetAncestors(ctx, replyID)
+	if err != nil {
+		return nil, err
+	}
+
+	postID, err := s.replyRepo.GetPostID(ctx, replyID)
+	if err != nil {
+		return nil, err
+	}
+	post, err := s.postRepo.GetByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, ErrPostNotFound
+	}
+
+	return &ReplyAncestors{
+		Replies: replies,
+		Post:    post,
+	}, nil
+}
+
+// CheckReadAccess returns ErrBoardRequiresAuth if replies under the given post or reply are
+// on a board that may only be read by authenticated clients and the caller isn't one. A
+// reply can be checked by passing it as a parent. A missing parent is left for the caller's
+// own lookup to report.
+func (s *replyService) CheckReadAccess(ctx context.Context, parentType string, parentID uuid.UUID, authenticated bool) error {
+	if authenticated {
+		return nil
+	}
+
+	postID := parentID
+	if models.ParentType(parentType) == models.ParentTypeReply {
+		var err error
+		if postID, err = s.replyRepo.GetPostID(ctx, parentID); err != nil {
+			return err
+		}
+		if postID == uuid.Nil {
+			return nil
+		}
+	}
+
+	post, err := s.postRepo.GetByID(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if post == nil {
+		return nil
+	}
+	return checkBoardReadable(ctx, s.boardRepo, post.BoardID, authenticated)
+}
+
+// FilterBlockedReplies removes replies written by agents viewerID has blocked. Replies to a
+// removed reply are removed with it when they appear later in the list, as they do in
+// GetThreadedReplies, so a thread doesn't show answers to replies the viewer can't see.
+func (s *replyService) FilterBlockedReplies(ctx context.Context, viewerID uuid.UUID, replies []*models.Reply) ([]*models.Reply, error) {
+	blocked, err := blockedAgentSet(ctx, s.agentRepo, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocked) == 0 {
+		return replies, nil
+	}
+
+	hidden := make(map[uuid.UUID]bool)
+	filtered := make([]*models.Reply, 0, len(replies))
+	for _, reply := range replies {
+		if blocked[reply.AgentID] || (models.ParentType(reply.ParentType) == models.ParentTypeReply && hidden[reply.ParentID]) {
+			hidden[reply.ID] = true
+			continue
+		}
+		filtered = append(filtered, reply)
+	}
+
+	return filtered, nil
+}
+
+// checkBoardNotArchived returns ErrBoardArchived if the board is archived
+func (s *replyService) checkBoardNotArchived(ctx context.Context, boardID uuid.UUID) error {
+	board, err := s.boardRepo.GetByID(ctx, boardID)
+	if err != nil {
+		return err
+	}
+	if board != nil && board.IsArchived {
+		return ErrBoardArchived
+	}
+	return nil
+}
+
+// UpdateReply updates an existing reply. Once the edit window has passed, only admins may
+// edit it; anyone else gets ErrEditWindowExpired.
+func (s *replyService) UpdateReply(ctx context.Context, reply *models.Reply, isAdmin bool) error {
+	// Sanitize and validate content
+	reply.Content = applyContentSanitizer(s.sanitizer, reply.Content)
+	if err := validateContent(reply.Content, s.maxContentLength); err != nil {
+		return err
+	}
+
+	// Run the content filter, so an edit can't bring in what a new reply couldn't
+	flagged, err := applyContentFilter(s.contentFilter, reply.Content)
+	if err != nil {
+		return err
+	}
+
 	// Check if reply exists
 	existingReply, err := s.replyRepo.GetByID(ctx, reply.ID)
 	if err != nil {
@@ -267,9 +595,25 @@ func (s *replyService) UpdateReply(ctx context.Context, reply *models.Reply) err
 		return errors.New("agent does not own this reply")
 	}
 
+	// Check if the reply may still be edited
+	if s.editWindow > 0 && !isAdmin && models.NowUTC().Sub(existingReply.CreatedAt) > s.editWindow {
+		return ErrEditWindowExpired
+	}
+
+	// Only a change to content or media counts as an edit
+	if contentChanged(existingReply.Content, reply.Content, existingReply.MediaURL, reply.MediaURL) {
+		now := models.NowUTC()
+		reply.EditedAt = &now
+	} else {
+		reply.EditedAt = existingReply.EditedAt
+	}
+
+	// An edit never clears an existing flag
+	reply.IsFlagged = existingReply.IsFlagged || flagged
+
 	// Update the reply
-	reply.UpdatedAt = time.Now()
-	return s.replyRepo.Update(ctx, reply)
+	reply.UpdatedAt = models.NowUTC()
+	return s.replyRepo.UpdateContent(ctx, reply)
 }
 
 // DeleteReply soft-deletes a reply
@@ -291,7 +635,7 @@ func (s *replyService) DeleteReply(ctx context.Context, id uuid.UUID) error {
 		}
 
 		// Update parent's reply count
-		if reply.ParentType == "post" {
+		if models.ParentType(reply.ParentType) == models.ParentTypePost {
 			if err := s.postRepo.UpdateReplyCount(ctx, reply.ParentID, -1); err != nil {
 				return err
 			}
@@ -306,3 +650,84 @@ func (s *replyService) DeleteReply(ctx context.Context, id uuid.UUID) error {
 
 	return err
 }
+
+// RestoreReply restores a soft-deleted reply
+func (s *replyService) RestoreReply(ctx context.Context, id uuid.UUID) error {
+	// Check if reply exists, including deleted replies
+	reply, err := s.replyRepo.FindByID(ctx, id, true)
+	if err != nil {
+		return err
+	}
+	if reply == nil {
+		return ErrReplyNotFound
+	}
+	if reply.DeletedAt == nil {
+		return nil
+	}
+
+	// Execute operations in a transaction
+	err = s.replyRepo.Transaction(ctx, func(tx *sqlx.Tx) error {
+		// Restore the reply
+		if err := s.replyRepo.Restore(ctx, id); err != nil {
+			return err
+		}
+
+		// Update parent's reply count
+		if models.ParentType(reply.ParentType) == models.ParentTypePost {
+			if err := s.postRepo.UpdateReplyCount(ctx, reply.ParentID, 1); err != nil {
+				return err
+			}
+		} else {
+			if err := s.replyRepo.UpdateReplyCount(ctx, reply.ParentID, 1); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+// MarkAccepted marks a reply as the accepted answer to its post. Only the post's
+// author can accept a reply, and accepting a new reply unmarks the previous one.
+func (s *replyService) MarkAccepted(ctx context.Context, replyID, postAuthorAgentID uuid.UUID) (*models.Reply, error) {
+	// Check if reply exists
+	reply, err := s.replyRepo.GetByID(ctx, replyID)
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, ErrReplyNotFound
+	}
+
+	// Only direct replies to a post can be an answer
+	if models.ParentType(reply.ParentType) != models.ParentTypePost {
+		return nil, ErrReplyNotAcceptable
+	}
+
+	// Check if post exists
+	post, err := s.postRepo.GetByID(ctx, reply.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, ErrPostNotFound
+	}
+
+	// Check if agent owns the post
+	if post.AgentID != postAuthorAgentID {
+		return nil, ErrNotPostAuthor
+	}
+
+	// The unique index on accepted replies settles concurrent accepts
+	if err := s.replyRepo.SetAccepted(ctx, post.ID, reply.ID); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrAcceptConflict
+		}
+		return nil, err
+	}
+
+	reply.IsAccepted = true
+	return reply, nil
+}
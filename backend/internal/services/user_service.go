@@ -27,10 +27,15 @@ type UserService interface {
 	ListUsers(ctx context.Context, page, pageSize int) ([]*models.User, int, error)
 	Authenticate(ctx context.Context, email, password string) (*models.User, error)
 	ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error
-	GetUsers(ctx context.Context, page, pageSize int) ([]*models.User, int, error)
+	GetUsers(ctx context.Context, page, pageSize int, opts models.UserListOptions) ([]*models.User, int, error)
 	EnsureAdminUser(ctx context.Context) error
+	RecordActivity(ctx context.Context, user *models.User, interval time.Duration) (bool, error)
+	ExportUsers(ctx context.Context, fn func(*models.User) error) error
 }
 
+// userExportBatchSize is how many users ExportUsers loads at a time
+const userExportBatchSize = 500
+
 type userService struct {
 	userRepo repository.UserRepository
 }
@@ -104,7 +109,8 @@ func (s *userService) GetUserByEmail(ctx context.Context, email string) (*models
 	return user, nil
 }
 
-// UpdateUser updates an existing user
+// UpdateUser updates an existing user. Removing admin from the last remaining admin fails
+// with ErrCannotRemoveLastAdmin, so there is always someone left to administer the site.
 func (s *userService) UpdateUser(ctx context.Context, user *models.User) error {
 	// Check if user exists
 	existingUser, err := s.userRepo.GetByID(ctx, user.ID)
@@ -155,6 +161,18 @@ func (s *userService) UpdateUser(ctx context.Context, user *models.User) error {
 		}
 	}
 
+	// Demote an admin in one conditional update, so two admins demoting each other at once
+	// can't both get past a separate count of the remaining admins
+	if existingUser.IsAdmin && !user.IsAdmin {
+		revoked, err := s.userRepo.RevokeAdmin(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if !revoked {
+			return ErrCannotRemoveLastAdmin
+		}
+	}
+
 	// Update the user
 	user.UpdatedAt = time.Now()
 	return s.userRepo.Update(ctx, user)
@@ -247,8 +265,22 @@ func (s *userService) ChangePassword(ctx context.Context, userID uuid.UUID, curr
 	return s.userRepo.Update(ctx, user)
 }
 
-// GetUsers retrieves a paginated list of users
-func (s *userService) GetUsers(ctx context.Context, page, pageSize int) ([]*models.User, int, error) {
+// GetUsers retrieves a paginated list of users, optionally sorted and filtered
+func (s *userService) GetUsers(ctx context.Context, page, pageSize int, opts models.UserListOptions) ([]*models.User, int, error) {
+	// Validate sort options
+	if opts.Sort == "" {
+		opts.Sort = "created_at"
+	}
+	if _, ok := models.UserSortFields[opts.Sort]; !ok {
+		return nil, 0, ErrInvalidSortField
+	}
+	if opts.Order == "" {
+		opts.Order = "desc"
+	}
+	if opts.Order != "asc" && opts.Order != "desc" {
+		return nil, 0, ErrInvalidSortOrder
+	}
+
 	// Calculate offset
 	offset := (page - 1) * pageSize
 	if offset < 0 {
@@ -256,13 +288,13 @@ func (s *userService) GetUsers(ctx context.Context, page, pageSize int) ([]*mode
 	}
 
 	// Get users
-	users, err := s.userRepo.List(ctx, offset, pageSize)
+	users, err := s.userRepo.ListWithOptions(ctx, opts, offset, pageSize)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	// Get total count
-	count, err := s.userRepo.Count(ctx)
+	count, err := s.userRepo.CountWithOptions(ctx, opts)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -270,6 +302,33 @@ func (s *userService) GetUsers(ctx context.Context, page, pageSize int) ([]*mode
 	return users, count, nil
 }
 
+// ExportUsers calls fn for every user, oldest first. Users are loaded in batches of
+// userExportBatchSize using keyset pagination, so memory stays bounded however many users
+// there are. It stops at the first error from fn and returns it.
+func (s *userService) ExportUsers(ctx context.Context, fn func(*models.User) error) error {
+	var afterCreatedAt *time.Time
+	var afterID uuid.UUID
+	for {
+		users, err := s.userRepo.ListAfter(ctx, afterCreatedAt, afterID, userExportBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			if err := fn(user); err != nil {
+				return err
+			}
+		}
+
+		if len(users) < userExportBatchSize {
+			return nil
+		}
+		last := users[len(users)-1]
+		afterCreatedAt = &last.CreatedAt
+		afterID = last.ID
+	}
+}
+
 // EnsureAdminUser checks if an admin user exists and creates one if not
 func (s *userService) EnsureAdminUser(ctx context.Context) error {
 	// Check if admin email and password are set in environment variables
@@ -284,7 +343,7 @@ func (s *userService) EnsureAdminUser(ctx context.Context) error {
 	log.Printf("Attempting to ensure admin user exists with email: %s", adminEmail)
 
 	// Check if any admin user already exists
-	users, _, err := s.GetUsers(ctx, 1, 100)
+	users, _, err := s.GetUsers(ctx, 1, 100, models.UserListOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to check for existing admin users: %w", err)
 	}
@@ -326,3 +385,21 @@ func (s *userService) EnsureAdminUser(ctx context.Context) error {
 	log.Printf("Admin user initialized with email: %s", adminEmail)
 	return nil
 }
+
+// RecordActivity stamps the user's last activity time, at most once per interval.
+// It reports whether a write was made.
+func (s *userService) RecordActivity(ctx context.Context, user *models.User, interval time.Duration) (bool, error) {
+	now := time.Now()
+	if user.LastActiveAt != nil && now.Sub(*user.LastActiveAt) < interval {
+		return false, nil
+	}
+
+	updated, err := s.userRepo.TouchLastActive(ctx, user.ID, now, now.Add(-interval))
+	if err != nil {
+		return false, err
+	}
+	if updated {
+		user.LastActiveAt = &now
+	}
+	return updated, nil
+}
@@ -2,61 +2,84 @@ package services
 
 import (
 	"context"
-	"errors"
-	"time"
+	"log"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 
 	"github.com/garrettallen/aiboards/backend/internal/database/repository"
+	"github.com/garrettallen/aiboards/backend/internal/metrics"
 	"github.com/garrettallen/aiboards/backend/internal/models"
 )
 
+// VoteViewer identifies who is requesting a target's votes
+type VoteViewer struct {
+	AgentID uuid.UUID // Set when authenticated as an agent
+	UserID  uuid.UUID // Set when authenticated as a user
+	IsAdmin bool
+}
+
 type VoteService interface {
 	CreateVote(ctx context.Context, agentID uuid.UUID, targetType string, targetID uuid.UUID, value int) (*models.Vote, error)
 	GetVoteByID(ctx context.Context, id uuid.UUID) (*models.Vote, error)
 	GetVoteByAgentAndTarget(ctx context.Context, agentID uuid.UUID, targetType string, targetID uuid.UUID) (*models.Vote, error)
 	GetVotesByTargetID(ctx context.Context, targetType string, targetID uuid.UUID, page, pageSize int) ([]*models.Vote, int, error)
+	GetVotesReceivedByAgent(ctx context.Context, agentID uuid.UUID, page, pageSize int) ([]*models.ReceivedVote, int, error)
+	GetVotesByAgentID(ctx context.Context, agentID uuid.UUID, page, pageSize int) ([]*models.CastVote, int, error)
 	UpdateVote(ctx context.Context, vote *models.Vote) error
 	DeleteVote(ctx context.Context, id uuid.UUID) error
+	CanViewVoters(ctx context.Context, targetType string, targetID uuid.UUID, viewer VoteViewer) (bool, error)
+	GetVoteSummary(ctx context.Context, targetType string, targetID uuid.UUID) (*models.VoteSummary, error)
 }
 
 type voteService struct {
 	voteRepo  repository.VoteRepository
 	postRepo  repository.PostRepository
 	replyRepo repository.ReplyRepository
+	boardRepo repository.BoardRepository
 	agentRepo repository.AgentRepository
+
+	notificationSvc NotificationService
 }
 
-// NewVoteService creates a new VoteService
+// NewVoteService creates a new VoteService. New votes are announced to the author of the
+// content voted on through notificationSvc, if set.
 func NewVoteService(
 	voteRepo repository.VoteRepository,
 	postRepo repository.PostRepository,
 	replyRepo repository.ReplyRepository,
+	boardRepo repository.BoardRepository,
 	agentRepo repository.AgentRepository,
+	notificationSvc NotificationService,
 ) VoteService {
 	return &voteService{
 		voteRepo:  voteRepo,
 		postRepo:  postRepo,
 		replyRepo: replyRepo,
+		boardRepo: boardRepo,
 		agentRepo: agentRepo,
+
+		notificationSvc: notificationSvc,
 	}
 }
 
 // CreateVote creates a new vote
 func (s *voteService) CreateVote(ctx context.Context, agentID uuid.UUID, targetType string, targetID uuid.UUID, value int) (*models.Vote, error) {
 	// Validate target type
-	if targetType != "post" && targetType != "reply" {
-		return nil, ErrInvalidTargetType
+	tt, err := models.ParseTargetType(targetType)
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate vote value
-	if value != 1 && value != -1 {
-		return nil, errors.New("vote value must be 1 or -1")
+	if err := models.ValidateVoteValue(value); err != nil {
+		return nil, err
 	}
 
-	// Check if target exists
-	if targetType == "post" {
+	// Check if target exists, and find its author and the post it belongs to
+	postID := targetID
+	var targetAgentID uuid.UUID
+	if tt == models.TargetTypePost {
 		post, err := s.postRepo.GetByID(ctx, targetID)
 		if err != nil {
 			return nil, err
@@ -64,6 +87,7 @@ func (s *voteService) CreateVote(ctx context.Context, agentID uuid.UUID, targetT
 		if post == nil {
 			return nil, ErrTargetNotFound
 		}
+		targetAgentID = post.AgentID
 	} else {
 		// Target is a reply
 		reply, err := s.replyRepo.GetByID(ctx, targetID)
@@ -73,6 +97,11 @@ func (s *voteService) CreateVote(ctx context.Context, agentID uuid.UUID, targetT
 		if reply == nil {
 			return nil, ErrTargetNotFound
 		}
+		targetAgentID = reply.AgentID
+		postID, err = s.replyRepo.GetPostID(ctx, targetID)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Check if agent exists
@@ -83,6 +112,9 @@ func (s *voteService) CreateVote(ctx context.Context, agentID uuid.UUID, targetT
 	if agent == nil {
 		return nil, ErrAgentNotFound
 	}
+	if !agent.IsActive {
+		return nil, ErrAgentDeactivated
+	}
 
 	// Check if agent has already voted on this target
 	existingVote, err := s.voteRepo.GetByAgentAndTarget(ctx, agentID, targetType, targetID)
@@ -93,32 +125,41 @@ func (s *voteService) CreateVote(ctx context.Context, agentID uuid.UUID, targetT
 		return nil, ErrAlreadyVoted
 	}
 
+	weight, err := s.voteWeight(ctx, agentID, postID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the vote
-	now := time.Now()
+	now := models.NowUTC()
 	vote := &models.Vote{
 		ID:         uuid.New(),
 		AgentID:    agentID,
 		TargetType: targetType,
 		TargetID:   targetID,
 		Value:      value,
+		Weight:     weight,
 		CreatedAt:  now,
 		UpdatedAt:  now,
 	}
 
 	// Execute operations in a transaction
 	err = s.voteRepo.Transaction(ctx, func(tx *sqlx.Tx) error {
-		// Save the vote
+		// Save the vote; a unique violation means a concurrent request voted first
 		if err := s.voteRepo.Create(ctx, vote); err != nil {
+			if isUniqueViolation(err) {
+				return ErrAlreadyVoted
+			}
 			return err
 		}
 
 		// Update target's vote count
-		if targetType == "post" {
-			if err := s.postRepo.UpdateVoteCount(ctx, targetID, value); err != nil {
+		if tt == models.TargetTypePost {
+			if err := s.postRepo.UpdateVoteCount(ctx, targetID, value, float64(value)*weight); err != nil {
 				return err
 			}
 		} else {
-			if err := s.replyRepo.UpdateVoteCount(ctx, targetID, value); err != nil {
+			if err := s.replyRepo.UpdateVoteCount(ctx, targetID, value, float64(value)*weight); err != nil {
 				return err
 			}
 		}
@@ -130,9 +171,44 @@ func (s *voteService) CreateVote(ctx context.Context, agentID uuid.UUID, targetT
 		return nil, err
 	}
 
+	metrics.VotesCreatedTotal.Inc(targetType)
+
+	// The vote is saved either way, so a failed notification is only logged
+	if s.notificationSvc != nil {
+		if err := s.notificationSvc.NotifyOnVote(ctx, vote, targetAgentID); err != nil {
+			log.Printf("Failed to notify about vote %s: %v", vote.ID, err)
+		}
+	}
+
 	return vote, nil
 }
 
+// voteWeight returns the weight a vote by agentID on content in the given post's thread counts
+// with: VoteWeight of the voter's karma if the post's board has weighted voting, otherwise 1
+func (s *voteService) voteWeight(ctx context.Context, agentID, postID uuid.UUID) (float64, error) {
+	post, err := s.postRepo.GetByID(ctx, postID)
+	if err != nil {
+		return 0, err
+	}
+	if post == nil {
+		return 1, nil
+	}
+
+	board, err := s.boardRepo.GetByID(ctx, post.BoardID)
+	if err != nil {
+		return 0, err
+	}
+	if board == nil || !board.WeightedVoting {
+		return 1, nil
+	}
+
+	karma, err := s.agentRepo.GetKarma(ctx, agentID)
+	if err != nil {
+		return 0, err
+	}
+	return VoteWeight(karma), nil
+}
+
 // GetVoteByID retrieves a vote by ID
 func (s *voteService) GetVoteByID(ctx context.Context, id uuid.UUID) (*models.Vote, error) {
 	vote, err := s.voteRepo.GetByID(ctx, id)
@@ -148,8 +224,8 @@ func (s *voteService) GetVoteByID(ctx context.Context, id uuid.UUID) (*models.Vo
 // GetVoteByAgentAndTarget retrieves a vote by agent ID and target
 func (s *voteService) GetVoteByAgentAndTarget(ctx context.Context, agentID uuid.UUID, targetType string, targetID uuid.UUID) (*models.Vote, error) {
 	// Validate target type
-	if targetType != "post" && targetType != "reply" {
-		return nil, ErrInvalidTargetType
+	if _, err := models.ParseTargetType(targetType); err != nil {
+		return nil, err
 	}
 
 	vote, err := s.voteRepo.GetByAgentAndTarget(ctx, agentID, targetType, targetID)
@@ -165,12 +241,13 @@ func (s *voteService) GetVoteByAgentAndTarget(ctx context.Context, agentID uuid.
 // GetVotesByTargetID retrieves votes for a target with pagination
 func (s *voteService) GetVotesByTargetID(ctx context.Context, targetType string, targetID uuid.UUID, page, pageSize int) ([]*models.Vote, int, error) {
 	// Validate target type
-	if targetType != "post" && targetType != "reply" {
-		return nil, 0, ErrInvalidTargetType
+	tt, err := models.ParseTargetType(targetType)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	// Check if target exists
-	if targetType == "post" {
+	if tt == models.TargetTypePost {
 		post, err := s.postRepo.GetByID(ctx, targetID)
 		if err != nil {
 			return nil, 0, err
@@ -204,8 +281,53 @@ func (s *voteService) GetVotesByTargetID(ctx context.Context, targetType string,
 	return votes, count, nil
 }
 
+// GetVotesReceivedByAgent retrieves votes cast on any of an agent's posts and replies
+func (s *voteService) GetVotesReceivedByAgent(ctx context.Context, agentID uuid.UUID, page, pageSize int) ([]*models.ReceivedVote, int, error) {
+	// Check if agent exists
+	agent, err := s.agentRepo.GetByID(ctx, agentID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if agent == nil {
+		return nil, 0, ErrAgentNotFound
+	}
+
+	// Calculate offset
+	offset := (page - 1) * pageSize
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.voteRepo.GetReceivedByAgentID(ctx, agentID, offset, pageSize)
+}
+
+// GetVotesByAgentID retrieves the votes an agent has cast on posts and replies, newest first
+func (s *voteService) GetVotesByAgentID(ctx context.Context, agentID uuid.UUID, page, pageSize int) ([]*models.CastVote, int, error) {
+	// Check if agent exists
+	agent, err := s.agentRepo.GetByID(ctx, agentID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if agent == nil {
+		return nil, 0, ErrAgentNotFound
+	}
+
+	// Calculate offset
+	offset := (page - 1) * pageSize
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.voteRepo.GetCastByAgentID(ctx, agentID, offset, pageSize)
+}
+
 // UpdateVote updates an existing vote
 func (s *voteService) UpdateVote(ctx context.Context, vote *models.Vote) error {
+	// Validate vote value
+	if err := models.ValidateVoteValue(vote.Value); err != nil {
+		return err
+	}
+
 	// Check if vote exists
 	existingVote, err := s.voteRepo.GetByID(ctx, vote.ID)
 	if err != nil {
@@ -215,25 +337,27 @@ func (s *voteService) UpdateVote(ctx context.Context, vote *models.Vote) error {
 		return ErrVoteNotFound
 	}
 
-	// Calculate vote value change
+	// Calculate vote value change; the vote keeps the weight it was cast with
 	valueChange := vote.Value - existingVote.Value
+	vote.Weight = existingVote.Weight
+	weightedChange := float64(valueChange) * existingVote.Weight
 
 	// Execute operations in a transaction
 	err = s.voteRepo.Transaction(ctx, func(tx *sqlx.Tx) error {
 		// Update the vote
-		vote.UpdatedAt = time.Now()
+		vote.UpdatedAt = models.NowUTC()
 		if err := s.voteRepo.Update(ctx, vote); err != nil {
 			return err
 		}
 
 		// Update target's vote count if the value changed
 		if valueChange != 0 {
-			if vote.TargetType == "post" {
-				if err := s.postRepo.UpdateVoteCount(ctx, vote.TargetID, valueChange); err != nil {
+			if models.TargetType(vote.TargetType) == models.TargetTypePost {
+				if err := s.postRepo.UpdateVoteCount(ctx, vote.TargetID, valueChange, weightedChange); err != nil {
 					return err
 				}
 			} else {
-				if err := s.replyRepo.UpdateVoteCount(ctx, vote.TargetID, valueChange); err != nil {
+				if err := s.replyRepo.UpdateVoteCount(ctx, vote.TargetID, valueChange, weightedChange); err != nil {
 					return err
 				}
 			}
@@ -263,13 +387,14 @@ func (s *voteService) DeleteVote(ctx context.Context, id uuid.UUID) error {
 			return err
 		}
 
-		// Update target's vote count (subtract the vote value)
-		if vote.TargetType == "post" {
-			if err := s.postRepo.UpdateVoteCount(ctx, vote.TargetID, -vote.Value); err != nil {
+		// Update target's vote count (subtract the vote value and its weighted value)
+		weightedValue := float64(vote.Value) * vote.Weight
+		if models.TargetType(vote.TargetType) == models.TargetTypePost {
+			if err := s.postRepo.UpdateVoteCount(ctx, vote.TargetID, -vote.Value, -weightedValue); err != nil {
 				return err
 			}
 		} else {
-			if err := s.replyRepo.UpdateVoteCount(ctx, vote.TargetID, -vote.Value); err != nil {
+			if err := s.replyRepo.UpdateVoteCount(ctx, vote.TargetID, -vote.Value, -weightedValue); err != nil {
 				return err
 			}
 		}
@@ -279,3 +404,72 @@ func (s *voteService) DeleteVote(ctx context.Context, id uuid.UUID) error {
 
 	return err
 }
+
+// CanViewVoters reports whether the viewer may see who voted on a target. Voters are
+// public unless the target's author has hide_voters enabled, in which case only the
+// author (or its owning user) and admins can see them.
+func (s *voteService) CanViewVoters(ctx context.Context, targetType string, targetID uuid.UUID, viewer VoteViewer) (bool, error) {
+	authorID, err := s.getTargetAuthorID(ctx, targetType, targetID)
+	if err != nil {
+		return false, err
+	}
+
+	author, err := s.agentRepo.GetByID(ctx, authorID)
+	if err != nil {
+		return false, err
+	}
+	if author == nil || !author.HideVoters {
+		return true, nil
+	}
+
+	if viewer.IsAdmin {
+		return true, nil
+	}
+	if viewer.AgentID != uuid.Nil && viewer.AgentID == author.ID {
+		return true, nil
+	}
+	if viewer.UserID != uuid.Nil && viewer.UserID == author.UserID {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// GetVoteSummary retrieves the aggregate vote counts for a target
+func (s *voteService) GetVoteSummary(ctx context.Context, targetType string, targetID uuid.UUID) (*models.VoteSummary, error) {
+	if _, err := s.getTargetAuthorID(ctx, targetType, targetID); err != nil {
+		return nil, err
+	}
+
+	return s.voteRepo.GetSummaryByTargetID(ctx, targetType, targetID)
+}
+
+// getTargetAuthorID returns the ID of the agent that wrote a post or reply
+func (s *voteService) getTargetAuthorID(ctx context.Context, targetType string, targetID uuid.UUID) (uuid.UUID, error) {
+	// Validate target type
+	tt, err := models.ParseTargetType(targetType)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if tt == models.TargetTypePost {
+		post, err := s.postRepo.GetByID(ctx, targetID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if post == nil {
+			return uuid.Nil, ErrTargetNotFound
+		}
+		return post.AgentID, nil
+	}
+
+	// Target is a reply
+	reply, err := s.replyRepo.GetByID(ctx, targetID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if reply == nil {
+		return uuid.Nil, ErrTargetNotFound
+	}
+	return reply.AgentID, nil
+}
@@ -0,0 +1,19 @@
+package services
+
+import "math"
+
+// VoteWeight is how much a vote counts toward a target's weighted score on a board with
+// weighted voting, given the voter's karma (the net raw votes on its live posts and replies):
+//
+//	weight = 1 + ln(1 + karma)   for karma > 0
+//	weight = 1                   for karma <= 0
+//
+// A new agent's vote counts the same as on an unweighted board, and the logarithm keeps a
+// single high-karma agent from dominating: karma 100 gives about 5.6, karma 10000 about 10.2.
+// Negative karma is not penalized further, so weights are always at least 1.
+func VoteWeight(karma int) float64 {
+	if karma <= 0 {
+		return 1
+	}
+	return 1 + math.Log1p(float64(karma))
+}
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/garrettallen/aiboards/backend/internal/database/repository"
+	"github.com/garrettallen/aiboards/backend/internal/models"
+)
+
+const (
+	// WebhookSignatureHeader carries the HMAC-SHA256 signature of the request body
+	WebhookSignatureHeader = "X-Aiboards-Signature"
+	// WebhookEventHeader carries the name of the event being delivered
+	WebhookEventHeader = "X-Aiboards-Event"
+	// WebhookDeliveryHeader carries the ID of the delivery record
+	WebhookDeliveryHeader = "X-Aiboards-Delivery"
+
+	webhookMaxAttempts    = 3
+	webhookInitialBackoff = time.Second
+	webhookTimeout        = 10 * time.Second
+)
+
+// WebhookPayload is the JSON body sent to webhook endpoints
+type WebhookPayload struct {
+	ID        uuid.UUID   `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookService handles webhook registration and delivery
+type WebhookService interface {
+	Register(ctx context.Context, agentID uuid.UUID, webhookURL string, events []string) (*models.Webhook, error)
+	GetWebhooksByAgentID(ctx context.Context, agentID uuid.UUID) ([]*models.Webhook, error)
+	Dispatch(ctx context.Context, agentID uuid.UUID, event models.WebhookEvent, data interface{}) error
+	DispatchReplyCreated(ctx context.Context, reply *models.Reply) error
+	DispatchVoteCreated(ctx context.Context, vote *models.Vote) error
+}
+
+type webhookService struct {
+	webhookRepo repository.WebhookRepository
+	postRepo    repository.PostRepository
+	replyRepo   repository.ReplyRepository
+	agentRepo   repository.AgentRepository
+	client      *http.Client
+
+	allowPrivateAddresses bool
+}
+
+// NewWebhookService creates a new WebhookService. Webhooks may only reach public addresses,
+// both when they are registered and when they are delivered to, unless allowPrivateAddresses
+// is set; that is meant for local development against endpoints on the same machine.
+func NewWebhookService(
+	webhookRepo repository.WebhookRepository,
+	postRepo repository.PostRepository,
+	replyRepo repository.ReplyRepository,
+	agentRepo repository.AgentRepository,
+	allowPrivateAddresses bool,
+) WebhookService {
+	dialer := &net.Dialer{Timeout: webhookTimeout}
+	if !allowPrivateAddresses {
+		dialer.Control = rejectPrivateDial
+	}
+
+	// Connect directly so the dialer sees the endpoint's address rather than a proxy's
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &webhookService{
+		webhookRepo:           webhookRepo,
+		postRepo:              postRepo,
+		replyRepo:             replyRepo,
+		agentRepo:             agentRepo,
+		client:                &http.Client{Timeout: webhookTimeout, Transport: transport},
+		allowPrivateAddresses: allowPrivateAddresses,
+	}
+}
+
+// isPrivateAddress reports whether ip is one webhooks may not reach: loopback, private,
+// link-local (which covers cloud metadata endpoints such as 169.254.169.254) or unspecified
+func isPrivateAddress(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsUnspecified()
+}
+
+// checkWebhookHost resolves a webhook's host and rejects it if any of its addresses is private
+func checkWebhookHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return ErrInvalidWebhookURL
+	}
+	for _, addr := range addrs {
+		if isPrivateAddress(addr.IP) {
+			return ErrInvalidWebhookURL
+		}
+	}
+	return nil
+}
+
+// rejectPrivateDial is a net.Dialer Control hook that refuses connections to private
+// addresses. It runs on the resolved address of every connection, so a host that resolved
+// to a public address at registration can't be rebound to a private one later.
+func rejectPrivateDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isPrivateAddress(ip) {
+		return fmt.Errorf("webhook address %s is not allowed", host)
+	}
+	return nil
+}
+
+// generateWebhookSecret creates a new random signing secret
+func generateWebhookSecret() (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// SignWebhookPayload returns the signature header value for a payload signed with secret
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Register creates a new webhook for an agent. The URL's host must resolve to public
+// addresses only, unless the service allows private ones.
+func (s *webhookService) Register(ctx context.Context, agentID uuid.UUID, webhookURL string, events []string) (*models.Webhook, error) {
+	// Check if agent exists
+	agent, err := s.agentRepo.GetByID(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	if agent == nil {
+		return nil, ErrAgentNotFound
+	}
+
+	// Validate URL
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, ErrInvalidWebhookURL
+	}
+
+	// Validate events
+	if len(events) == 0 {
+		return nil, ErrInvalidWebhookEvent
+	}
+	for _, event := range events {
+		if !models.WebhookEvent(event).IsValid() {
+			return nil, ErrInvalidWebhookEvent
+		}
+	}
+
+	// Only public endpoints may be registered
+	if !s.allowPrivateAddresses {
+		if err := checkWebhookHost(ctx, parsed.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Generate signing secret
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := models.NewWebhook(agentID, webhookURL, secret, events)
+	if err := s.webhookRepo.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// GetWebhooksByAgentID retrieves the webhooks registered by an agent
+func (s *webhookService) GetWebhooksByAgentID(ctx context.Context, agentID uuid.UUID) ([]*models.Webhook, error) {
+	return s.webhookRepo.GetByAgentID(ctx, agentID)
+}
+
+// Dispatch delivers an event to every active webhook of an agent subscribed to it.
+// Delivery is synchronous and retried with backoff, so callers on a request path
+// should run it in a goroutine.
+func (s *webhookService) Dispatch(ctx context.Context, agentID uuid.UUID, event models.WebhookEvent, data interface{}) error {
+	webhooks, err := s.webhookRepo.GetActiveByAgentAndEvent(ctx, agentID, string(event))
+	if err != nil {
+		return err
+	}
+
+	for _, webhook := range webhooks {
+		if err := s.deliver(ctx, webhook, event, data); err != nil {
+			log.Printf("Webhook %s delivery of %s failed: %v", webhook.ID, event, err)
+		}
+	}
+
+	return nil
+}
+
+// DispatchReplyCreated notifies the owner of the reply's parent
+func (s *webhookService) DispatchReplyCreated(ctx context.Context, reply *models.Reply) error {
+	var ownerID uuid.UUID
+	if models.ParentType(reply.ParentType) == models.ParentTypePost {
+		post, err := s.postRepo.GetByID(ctx, reply.ParentID)
+		if err != nil {
+			return err
+		}
+		if post == nil {
+			return ErrPostNotFound
+		}
+		ownerID = post.AgentID
+	} else {
+		parent, err := s.replyRepo.GetByID(ctx, reply.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			return ErrParentNotFound
+		}
+		ownerID = parent.AgentID
+	}
+
+	// Agents don't need to hear about their own replies
+	if ownerID == reply.AgentID {
+		return nil
+	}
+
+	return s.Dispatch(ctx, ownerID, models.WebhookEventReplyCreated, reply)
+}
+
+// DispatchVoteCreated notifies the owner of the voted-on post or reply
+func (s *webhookService) DispatchVoteCreated(ctx context.Context, vote *models.Vote) error {
+	var ownerID uuid.UUID
+	if models.TargetType(vote.TargetType) == models.TargetTypePost {
+		post, err := s.postRepo.GetByID(ctx, vote.TargetID)
+		if err != nil {
+			return err
+		}
+		if post == nil {
+			return ErrTargetNotFound
+		}
+		ownerID = post.AgentID
+	} else {
+		reply, err := s.replyRepo.GetByID(ctx, vote.TargetID)
+		if err != nil {
+			return err
+		}
+		if reply == nil {
+			return ErrTargetNotFound
+		}
+		ownerID = reply.AgentID
+	}
+
+	if ownerID == vote.AgentID {
+		return nil
+	}
+
+	return s.Dispatch(ctx, ownerID, models.WebhookEventVoteCreated, vote)
+}
+
+// deliver sends a single event to a webhook, retrying with exponential backoff,
+// and records the outcome in webhook_deliveries
+func (s *webhookService) deliver(ctx context.Context, webhook *models.Webhook, event models.WebhookEvent, data interface{}) error {
+	deliveryID := uuid.New()
+	body, err := json.Marshal(WebhookPayload{
+		ID:        deliveryID,
+		Event:     string(event),
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	delivery := models.NewWebhookDelivery(webhook.ID, string(event), string(body))
+	delivery.ID = deliveryID
+	if err := s.webhookRepo.CreateDelivery(ctx, delivery); err != nil {
+		return err
+	}
+
+	signature := SignWebhookPayload(webhook.Secret, body)
+	backoff := webhookInitialBackoff
+
+	var lastErr error
+retry:
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		delivery.Attempts = attempt
+
+		statusCode, err := s.post(ctx, webhook.URL, event, deliveryID, signature, body)
+		if statusCode != 0 {
+			delivery.ResponseCode = &statusCode
+		}
+		if err == nil {
+			now := time.Now()
+			delivery.Status = string(models.WebhookDeliveryDelivered)
+			delivery.DeliveredAt = &now
+			delivery.LastError = nil
+			return s.webhookRepo.UpdateDelivery(ctx, delivery)
+		}
+
+		lastErr = err
+		errMsg := err.Error()
+		delivery.LastError = &errMsg
+
+		if attempt < webhookMaxAttempts {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				lastErr = ctx.Err()
+				break retry
+			}
+			backoff *= 2
+		}
+	}
+
+	delivery.Status = string(models.WebhookDeliveryFailed)
+	if err := s.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		return err
+	}
+
+	return lastErr
+}
+
+// post performs one HTTP delivery attempt and returns the response status code
+func (s *webhookService) post(ctx context.Context, webhookURL string, event models.WebhookEvent, deliveryID uuid.UUID, signature string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, signature)
+	req.Header.Set(WebhookEventHeader, string(event))
+	req.Header.Set(WebhookDeliveryHeader, deliveryID.String())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook endpoint returned status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_notification_preferences_digest_frequency;
+
+ALTER TABLE notification_preferences
+    DROP COLUMN IF EXISTS last_digest_at,
+    DROP COLUMN IF EXISTS digest_frequency;
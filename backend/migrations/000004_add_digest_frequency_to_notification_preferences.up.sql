@@ -0,0 +1,6 @@
+-- Add digest frequency to notification_preferences
+ALTER TABLE notification_preferences
+    ADD COLUMN digest_frequency VARCHAR(10) NOT NULL DEFAULT 'off' CHECK (digest_frequency IN ('off', 'daily')),
+    ADD COLUMN last_digest_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_notification_preferences_digest_frequency ON notification_preferences(digest_frequency);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_replies_is_flagged;
+DROP INDEX IF EXISTS idx_posts_is_flagged;
+
+ALTER TABLE replies DROP COLUMN IF EXISTS is_flagged;
+ALTER TABLE posts DROP COLUMN IF EXISTS is_flagged;
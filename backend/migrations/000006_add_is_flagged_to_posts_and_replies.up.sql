@@ -0,0 +1,6 @@
+-- Content caught by the content filter is flagged for moderator review
+ALTER TABLE posts ADD COLUMN is_flagged BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE replies ADD COLUMN is_flagged BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_posts_is_flagged ON posts(is_flagged) WHERE is_flagged;
+CREATE INDEX idx_replies_is_flagged ON replies(is_flagged) WHERE is_flagged;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_replies_is_accepted;
+
+ALTER TABLE replies DROP COLUMN IF EXISTS is_accepted;
@@ -0,0 +1,4 @@
+-- A post's author can mark one direct reply as the accepted answer
+ALTER TABLE replies ADD COLUMN is_accepted BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_replies_is_accepted ON replies(parent_id) WHERE is_accepted;
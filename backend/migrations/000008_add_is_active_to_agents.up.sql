@@ -0,0 +1,2 @@
+-- Admins can suspend an agent without deleting it; existing agents stay active
+ALTER TABLE agents ADD COLUMN is_active BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,2 @@
+-- Full-text index backing the cross-board post search
+CREATE INDEX idx_posts_content_fts ON posts USING GIN (to_tsvector('english', content));
@@ -0,0 +1,2 @@
+-- Agents can hide who voted on their posts and replies; voters stay public by default
+ALTER TABLE agents ADD COLUMN hide_voters BOOLEAN NOT NULL DEFAULT FALSE;
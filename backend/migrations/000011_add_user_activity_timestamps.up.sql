@@ -0,0 +1,3 @@
+-- Track when users last logged in and were last active, for security dashboards
+ALTER TABLE users ADD COLUMN last_login_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN last_active_at TIMESTAMP WITH TIME ZONE;
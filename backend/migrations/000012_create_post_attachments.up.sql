@@ -0,0 +1,9 @@
+-- Create post_attachments table; posts.media_url remains as the first attachment for older clients
+CREATE TABLE post_attachments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (post_id, position)
+);
@@ -0,0 +1,2 @@
+-- Raw keys can't be recovered from their hashes, so existing keys stop working and must be regenerated
+ALTER TABLE agents RENAME COLUMN api_key_hash TO api_key;
@@ -0,0 +1,3 @@
+-- Store only a SHA-256 hash of each agent's API key; the raw key is shown once when issued
+ALTER TABLE agents RENAME COLUMN api_key TO api_key_hash;
+UPDATE agents SET api_key_hash = encode(sha256(convert_to(api_key_hash, 'UTF8')), 'hex');
@@ -0,0 +1,2 @@
+ALTER TABLE agents DROP COLUMN IF EXISTS previous_api_key_expires_at;
+ALTER TABLE agents DROP COLUMN IF EXISTS previous_api_key_hash;
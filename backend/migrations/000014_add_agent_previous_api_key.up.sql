@@ -0,0 +1,3 @@
+-- Keep the previous API key hash valid for a short grace window after regeneration
+ALTER TABLE agents ADD COLUMN previous_api_key_hash VARCHAR(255);
+ALTER TABLE agents ADD COLUMN previous_api_key_expires_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_notifications_agent_id_unread;
+CREATE INDEX IF NOT EXISTS idx_notifications_agent_id ON notifications(agent_id);
+DROP INDEX IF EXISTS idx_notifications_agent_id_created_at;
@@ -0,0 +1,8 @@
+-- Serve an agent's notification list in created_at order straight from the index;
+-- this supersedes the single-column agent_id index
+CREATE INDEX idx_notifications_agent_id_created_at ON notifications (agent_id, created_at DESC);
+DROP INDEX IF EXISTS idx_notifications_agent_id;
+
+-- Unread notifications are a small fraction of the table, so a partial index keeps
+-- unread counts and unread digests cheap
+CREATE INDEX idx_notifications_agent_id_unread ON notifications (agent_id, created_at DESC) WHERE is_read = false;
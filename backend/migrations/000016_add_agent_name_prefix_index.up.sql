@@ -0,0 +1,2 @@
+-- Serve case-insensitive name prefix searches (lower(name) LIKE 'abc%') from an index
+CREATE INDEX idx_agents_name_lower ON agents (LOWER(name) text_pattern_ops) WHERE deleted_at IS NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_replies_quoted_reply_id;
+ALTER TABLE replies DROP COLUMN IF EXISTS quoted_reply_id;
@@ -0,0 +1,4 @@
+-- A reply may quote an earlier reply in the same thread, independently of its parent
+ALTER TABLE replies ADD COLUMN quoted_reply_id UUID REFERENCES replies(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_replies_quoted_reply_id ON replies (quoted_reply_id) WHERE quoted_reply_id IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE boards DROP COLUMN IF EXISTS post_policy;
@@ -0,0 +1,3 @@
+-- Who may post to a board: anyone, only the owning agent, or no one
+ALTER TABLE boards ADD COLUMN post_policy VARCHAR(20) NOT NULL DEFAULT 'open'
+    CHECK (post_policy IN ('open', 'owner_only', 'closed'));
@@ -0,0 +1,2 @@
+ALTER TABLE replies DROP COLUMN IF EXISTS edited_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS edited_at;
@@ -0,0 +1,4 @@
+-- Set only when a post's or reply's content or media changes, unlike updated_at which
+-- also moves on vote and reply count updates
+ALTER TABLE posts ADD COLUMN edited_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE replies ADD COLUMN edited_at TIMESTAMP WITH TIME ZONE;
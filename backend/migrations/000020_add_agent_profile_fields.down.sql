@@ -0,0 +1,3 @@
+ALTER TABLE agents DROP COLUMN IF EXISTS location;
+ALTER TABLE agents DROP COLUMN IF EXISTS website_url;
+ALTER TABLE agents DROP COLUMN IF EXISTS bio;
@@ -0,0 +1,4 @@
+-- Optional public profile details shown on an agent's profile
+ALTER TABLE agents ADD COLUMN bio TEXT NOT NULL DEFAULT '';
+ALTER TABLE agents ADD COLUMN website_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE agents ADD COLUMN location VARCHAR(100) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Archived boards stay readable and searchable but accept no new posts or replies
+ALTER TABLE boards ADD COLUMN is_archived BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_posts_board_id_created_at_id;
@@ -0,0 +1,2 @@
+-- Serve keyset pagination of a board's live posts, ordered by (created_at, id), straight from the index
+CREATE INDEX idx_posts_board_id_created_at_id ON posts (board_id, created_at DESC, id DESC) WHERE deleted_at IS NULL;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_boards_agent_id ON boards(agent_id);
+DROP INDEX IF EXISTS idx_boards_agent_id_unique;
@@ -0,0 +1,17 @@
+-- Agents that already own several live boards keep their oldest one; the rest are soft-deleted
+-- (so they can still be restored or purged) before the unique index is built.
+UPDATE boards SET deleted_at = NOW()
+WHERE deleted_at IS NULL
+  AND id IN (
+    SELECT id FROM (
+      SELECT id, ROW_NUMBER() OVER (PARTITION BY agent_id ORDER BY created_at, id) AS rn
+      FROM boards
+      WHERE deleted_at IS NULL
+    ) ranked
+    WHERE rn > 1
+  );
+
+-- An agent owns at most one live board; soft-deleted boards don't count, so an agent can
+-- create a new board after deleting its old one. This supersedes the plain agent_id index.
+CREATE UNIQUE INDEX idx_boards_agent_id_unique ON boards (agent_id) WHERE deleted_at IS NULL;
+DROP INDEX IF EXISTS idx_boards_agent_id;
@@ -0,0 +1,4 @@
+ALTER TABLE replies DROP COLUMN IF EXISTS weighted_score;
+ALTER TABLE posts DROP COLUMN IF EXISTS weighted_score;
+ALTER TABLE votes DROP COLUMN IF EXISTS weight;
+ALTER TABLE boards DROP COLUMN IF EXISTS weighted_voting;
@@ -0,0 +1,12 @@
+-- Boards can opt into reputation-weighted voting; vote_count stays the raw sum of votes
+ALTER TABLE boards ADD COLUMN weighted_voting BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- The weight a vote was cast with, kept so changing or removing the vote reverses exactly
+-- what it added even if the voter's karma has changed since
+ALTER TABLE votes ADD COLUMN weight DOUBLE PRECISION NOT NULL DEFAULT 1;
+
+-- Sum of value * weight over a target's votes; equal to vote_count unless weighting applied
+ALTER TABLE posts ADD COLUMN weighted_score DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE replies ADD COLUMN weighted_score DOUBLE PRECISION NOT NULL DEFAULT 0;
+UPDATE posts SET weighted_score = vote_count;
+UPDATE replies SET weighted_score = vote_count;
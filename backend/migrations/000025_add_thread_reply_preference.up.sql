@@ -0,0 +1,3 @@
+-- Let agents opt out of notifications about replies in threads they took part in
+ALTER TABLE notification_preferences
+    ADD COLUMN thread_replies_enabled BOOLEAN NOT NULL DEFAULT TRUE;
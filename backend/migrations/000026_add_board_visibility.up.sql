@@ -0,0 +1,3 @@
+-- Boards can require authentication to read; public boards stay readable by anyone
+ALTER TABLE boards ADD COLUMN visibility VARCHAR(20) NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'authenticated'));
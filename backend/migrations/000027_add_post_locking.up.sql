@@ -0,0 +1,2 @@
+-- Locked posts accept no new replies; existing replies stay readable
+ALTER TABLE posts ADD COLUMN is_locked BOOLEAN NOT NULL DEFAULT FALSE;
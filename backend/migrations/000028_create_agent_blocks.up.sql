@@ -0,0 +1,9 @@
+-- Create agent_blocks table; a blocker doesn't see the blocked agent's posts and replies
+-- or get notified about their activity
+CREATE TABLE agent_blocks (
+    blocker_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    blocked_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (blocker_id, blocked_id),
+    CHECK (blocker_id <> blocked_id)
+);
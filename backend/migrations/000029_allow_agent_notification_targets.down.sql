@@ -0,0 +1,4 @@
+DELETE FROM notifications WHERE target_type = 'agent';
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_target_type_check;
+ALTER TABLE notifications
+    ADD CONSTRAINT notifications_target_type_check CHECK (target_type IN ('post', 'reply'));
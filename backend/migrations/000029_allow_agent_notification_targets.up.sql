@@ -0,0 +1,4 @@
+-- Let system notifications about an agent itself, such as quota warnings, target the agent
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_target_type_check;
+ALTER TABLE notifications
+    ADD CONSTRAINT notifications_target_type_check CHECK (target_type IN ('post', 'reply', 'agent'));
@@ -0,0 +1,11 @@
+-- Hit counters for the database-backed rate limiter, shared by every server replica.
+-- The counters are short-lived and cheap to lose, so the table skips the write-ahead log.
+CREATE UNLOGGED TABLE rate_limit_counters (
+    key VARCHAR(255) NOT NULL,
+    window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (key, window_start)
+);
+
+CREATE INDEX idx_rate_limit_counters_expires_at ON rate_limit_counters(expires_at);
@@ -0,0 +1,5 @@
+-- Fails while user-owned boards exist; transfer or remove them before rolling back.
+DROP INDEX IF EXISTS idx_boards_user_id;
+ALTER TABLE boards DROP CONSTRAINT IF EXISTS boards_single_owner;
+ALTER TABLE boards ALTER COLUMN agent_id SET NOT NULL;
+ALTER TABLE boards DROP COLUMN IF EXISTS user_id;
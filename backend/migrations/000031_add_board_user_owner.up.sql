@@ -0,0 +1,7 @@
+-- A board is owned either by an agent or directly by a user, never both. Users may own any
+-- number of boards (up to the configured limit), so user_id gets a plain index.
+ALTER TABLE boards ALTER COLUMN agent_id DROP NOT NULL;
+ALTER TABLE boards ADD COLUMN user_id UUID REFERENCES users(id);
+ALTER TABLE boards ADD CONSTRAINT boards_single_owner CHECK ((agent_id IS NULL) <> (user_id IS NULL));
+
+CREATE INDEX idx_boards_user_id ON boards(user_id) WHERE deleted_at IS NULL;
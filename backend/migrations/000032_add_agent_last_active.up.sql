@@ -0,0 +1,2 @@
+-- Track when agents were last active, for presence
+ALTER TABLE agents ADD COLUMN last_active_at TIMESTAMP WITH TIME ZONE;
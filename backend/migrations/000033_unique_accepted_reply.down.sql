@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_replies_is_accepted;
+
+CREATE INDEX idx_replies_is_accepted ON replies(parent_id) WHERE is_accepted;
@@ -0,0 +1,14 @@
+-- At most one accepted answer per post, so concurrent accepts can't both win.
+-- Keep the most recently updated accepted reply where a post already has several.
+UPDATE replies r
+SET is_accepted = FALSE
+WHERE r.is_accepted
+AND EXISTS (
+    SELECT 1 FROM replies o
+    WHERE o.parent_id = r.parent_id AND o.is_accepted
+    AND (o.updated_at, o.id) > (r.updated_at, r.id)
+);
+
+DROP INDEX IF EXISTS idx_replies_is_accepted;
+
+CREATE UNIQUE INDEX idx_replies_is_accepted ON replies(parent_id) WHERE is_accepted;
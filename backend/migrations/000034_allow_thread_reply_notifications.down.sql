@@ -0,0 +1,4 @@
+DELETE FROM notifications WHERE type = 'thread_reply';
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications
+    ADD CONSTRAINT notifications_type_check CHECK (type IN ('reply', 'vote', 'system'));
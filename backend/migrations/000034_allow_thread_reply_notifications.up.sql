@@ -0,0 +1,4 @@
+-- Let agents be notified of replies anywhere in a thread they replied to
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications
+    ADD CONSTRAINT notifications_type_check CHECK (type IN ('reply', 'thread_reply', 'vote', 'system'));
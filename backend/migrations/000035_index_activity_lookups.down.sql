@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_votes_target_id_created_at;
+DROP INDEX IF EXISTS idx_replies_parent_id_created_at;
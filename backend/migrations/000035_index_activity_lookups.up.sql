@@ -0,0 +1,3 @@
+-- Board activity walks down reply threads and finds their recent replies and votes
+CREATE INDEX idx_replies_parent_id_created_at ON replies(parent_id, created_at);
+CREATE INDEX idx_votes_target_id_created_at ON votes(target_id, created_at);
@@ -2,12 +2,15 @@ package api
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/garrettallen/aiboards/backend/internal/database/repository"
 	"github.com/garrettallen/aiboards/backend/internal/handlers"
@@ -46,9 +49,9 @@ func setupAdminTestRouter(t *testing.T) (*gin.Engine, *utils.TestEnv) {
 	boardRepo := repository.NewBoardRepository(env.DB)
 
 	// Create services
-	boardService := services.NewBoardService(boardRepo, env.AgentRepository)
-	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, env.AgentService)
-	replyService := services.NewReplyService(replyRepo, postRepo, env.AgentRepository, env.AgentService)
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
+	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, replyRepo, env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, nil)
+	replyService := services.NewReplyService(replyRepo, postRepo, boardRepo, env.AgentRepository, env.AgentService, services.DefaultMaxReplyLength, nil, nil, 0, nil)
 
 	// Create admin handler
 	adminHandler := handlers.NewAdminHandler(
@@ -57,6 +60,12 @@ func setupAdminTestRouter(t *testing.T) (*gin.Engine, *utils.TestEnv) {
 		boardService,
 		postService,
 		replyService,
+		env.AuthService,
+		services.NewAuditService(repository.NewAuditLogRepository(env.DB)),
+		services.NewAdminService(postRepo, replyRepo, repository.NewVoteRepository(env.DB)),
+		services.NewNotificationService(repository.NewNotificationRepository(env.DB), repository.NewNotificationPreferenceRepository(env.DB), env.UserRepository, env.AgentRepository, nil, 0),
+		30*24*time.Hour,
+		90*24*time.Hour,
 	)
 
 	// Setup routes
@@ -174,6 +183,155 @@ func TestGetUsersEndpoint(t *testing.T) {
 	})
 }
 
+func TestGetUsersSortAndFilter(t *testing.T) {
+	router, env := setupAdminTestRouter(t)
+	defer env.Cleanup()
+
+	// Create admin user and get token
+	adminToken, _ := utils.CreateAdminUserAndGetToken(t, env)
+
+	// Create users whose email, name and creation orders all differ
+	testUsers := []struct {
+		email string
+		name  string
+	}{
+		{"charlie@sorttest.io", "Ava"},
+		{"alpha@sorttest.io", "Cole"},
+		{"bravo@sorttest.io", "Bea"},
+	}
+	for _, tu := range testUsers {
+		user, err := models.NewUser(tu.email, "password123", tu.name)
+		require.NoError(t, err)
+		err = env.UserRepository.Create(env.Ctx, user)
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond) // Ensure distinct created_at values
+	}
+
+	listEmails := func(t *testing.T, query string) []string {
+		req := httptest.NewRequest("GET", "/api/v1/admin/users?"+query, nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adminToken))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		users, ok := response["users"].([]interface{})
+		require.True(t, ok)
+
+		emails := make([]string, len(users))
+		for i, u := range users {
+			emails[i] = u.(map[string]interface{})["email"].(string)
+		}
+		return emails
+	}
+
+	t.Run("Sort by email", func(t *testing.T) {
+		emails := listEmails(t, "filter=sorttest&sort=email&order=asc")
+		assert.Equal(t, []string{"alpha@sorttest.io", "bravo@sorttest.io", "charlie@sorttest.io"}, emails)
+	})
+
+	t.Run("Sort by name", func(t *testing.T) {
+		emails := listEmails(t, "filter=sorttest&sort=name&order=asc")
+		assert.Equal(t, []string{"charlie@sorttest.io", "bravo@sorttest.io", "alpha@sorttest.io"}, emails)
+	})
+
+	t.Run("Sort by created_at", func(t *testing.T) {
+		emails := listEmails(t, "filter=sorttest&sort=created_at&order=asc")
+		assert.Equal(t, []string{"charlie@sorttest.io", "alpha@sorttest.io", "bravo@sorttest.io"}, emails)
+
+		emails = listEmails(t, "filter=sorttest&sort=created_at&order=desc")
+		assert.Equal(t, []string{"bravo@sorttest.io", "alpha@sorttest.io", "charlie@sorttest.io"}, emails)
+	})
+
+	t.Run("Filter by name", func(t *testing.T) {
+		emails := listEmails(t, "filter=bea")
+		assert.Equal(t, []string{"bravo@sorttest.io"}, emails)
+	})
+
+	t.Run("Invalid sort field is rejected", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/admin/users?sort=password_hash", nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adminToken))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Invalid sort order is rejected", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/admin/users?sort=email&order=sideways", nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adminToken))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestExportUsersEndpoint(t *testing.T) {
+	router, env := setupAdminTestRouter(t)
+	defer env.Cleanup()
+
+	adminToken, adminID := utils.CreateAdminUserAndGetToken(t, env)
+	regularToken, regularID := utils.CreateRegularUserAndGetToken(t, env)
+
+	t.Run("Admin gets every user as CSV", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/admin/users/export.csv", nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adminToken))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+		assert.Equal(t, `attachment; filename="users.csv"`, w.Header().Get("Content-Disposition"))
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, []string{"id", "email", "name", "is_admin", "created_at"}, records[0])
+
+		rows := map[string][]string{}
+		for _, record := range records[1:] {
+			rows[record[0]] = record
+		}
+		require.Contains(t, rows, adminID.String())
+		require.Contains(t, rows, regularID.String())
+		assert.Equal(t, "admin@example.com", rows[adminID.String()][1])
+		assert.Equal(t, "true", rows[adminID.String()][3])
+		assert.Equal(t, "false", rows[regularID.String()][3])
+	})
+
+	t.Run("Regular user cannot export users", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/admin/users/export.csv", nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", regularToken))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Formula-like cells are escaped", func(t *testing.T) {
+		user, err := env.UserRepository.GetByID(env.Ctx, regularID)
+		require.NoError(t, err)
+		user.Name = `=HYPERLINK("https://example.com","click")`
+		require.NoError(t, env.UserRepository.Update(env.Ctx, user))
+
+		req := httptest.NewRequest("GET", "/api/v1/admin/users/export.csv", nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adminToken))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		for _, record := range records[1:] {
+			if record[0] == regularID.String() {
+				assert.Equal(t, `'=HYPERLINK("https://example.com","click")`, record[2])
+			}
+		}
+	})
+}
+
 func TestGetUserEndpoint(t *testing.T) {
 	router, env := setupAdminTestRouter(t)
 	defer env.Cleanup()
@@ -345,6 +503,59 @@ func TestUpdateUserEndpoint(t *testing.T) {
 	})
 }
 
+func TestUpdateUserLastAdmin(t *testing.T) {
+	router, env := setupAdminTestRouter(t)
+	defer env.Cleanup()
+
+	adminToken, adminID := utils.CreateAdminUserAndGetToken(t, env)
+
+	setAdmin := func(userID uuid.UUID, isAdmin bool) *httptest.ResponseRecorder {
+		jsonData, _ := json.Marshal(map[string]interface{}{"is_admin": isAdmin})
+		req := httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/admin/users/%s", userID), bytes.NewBuffer(jsonData))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adminToken))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Last admin cannot demote themselves", func(t *testing.T) {
+		w := setAdmin(adminID, false)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		user, err := env.UserService.GetUserByID(env.Ctx, adminID)
+		require.NoError(t, err)
+		assert.True(t, user.IsAdmin)
+	})
+
+	t.Run("Omitting is_admin leaves it unchanged", func(t *testing.T) {
+		jsonData, _ := json.Marshal(map[string]interface{}{"name": "Renamed Admin"})
+		req := httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/admin/users/%s", adminID), bytes.NewBuffer(jsonData))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adminToken))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		user, err := env.UserService.GetUserByID(env.Ctx, adminID)
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed Admin", user.Name)
+		assert.True(t, user.IsAdmin)
+	})
+
+	t.Run("Admin can step down once another admin exists", func(t *testing.T) {
+		_, otherID := utils.CreateRegularUserAndGetToken(t, env)
+		require.Equal(t, http.StatusOK, setAdmin(otherID, true).Code)
+
+		w := setAdmin(adminID, false)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		user, err := env.UserService.GetUserByID(env.Ctx, adminID)
+		require.NoError(t, err)
+		assert.False(t, user.IsAdmin)
+	})
+}
+
 func TestDeleteUserEndpoint(t *testing.T) {
 	router, env := setupAdminTestRouter(t)
 	defer env.Cleanup()
@@ -468,6 +679,37 @@ func TestModeratePostEndpoint(t *testing.T) {
 		assert.NotNil(t, deletedPost.DeletedAt)
 	})
 
+	t.Run("Admin user can fetch a deleted post", func(t *testing.T) {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/admin/posts/%s", post.ID), nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adminToken))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var fetched models.Post
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetched))
+		assert.Equal(t, post.ID, fetched.ID)
+		assert.NotNil(t, fetched.DeletedAt)
+	})
+
+	t.Run("Admin user can restore a deleted post", func(t *testing.T) {
+		jsonData, _ := json.Marshal(map[string]interface{}{"delete": false})
+
+		req := httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/admin/posts/%s/moderate", post.ID), bytes.NewBuffer(jsonData))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adminToken))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var restoredPost models.Post
+		err := env.DB.Get(&restoredPost, "SELECT * FROM posts WHERE id = $1", post.ID)
+		require.NoError(t, err)
+		assert.Nil(t, restoredPost.DeletedAt)
+	})
+
 	t.Run("Regular user cannot moderate posts", func(t *testing.T) {
 		// Create a new post for this test
 		anotherPost := utils.CreateTestPost(t, env, agent.ID)
@@ -581,3 +823,527 @@ func TestModerateReplyEndpoint(t *testing.T) {
 		assert.Nil(t, reply.DeletedAt)
 	})
 }
+
+func TestImpersonateAgentEndpoint(t *testing.T) {
+	router, env := setupAdminTestRouter(t)
+	defer env.Cleanup()
+
+	// Create admin and regular users and get tokens
+	adminToken, adminID := utils.CreateAdminUserAndGetToken(t, env)
+	userToken, userID := utils.CreateRegularUserAndGetToken(t, env)
+
+	// Create an agent owned by the regular user
+	agent := env.CreateTestAgent(userID)
+
+	auditService := services.NewAuditService(repository.NewAuditLogRepository(env.DB))
+
+	t.Run("Non-admin cannot impersonate", func(t *testing.T) {
+		req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/admin/agents/%s/impersonate", agent.ID), nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", userToken))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		// Nothing should have been recorded
+		logs, err := auditService.GetLogsByTarget(env.Ctx, "agent", agent.ID)
+		require.NoError(t, err)
+		assert.Empty(t, logs)
+	})
+
+	t.Run("Unknown agent returns not found", func(t *testing.T) {
+		req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/admin/agents/%s/impersonate", uuid.New()), nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adminToken))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Admin receives a token carrying the impersonation claim", func(t *testing.T) {
+		req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/admin/agents/%s/impersonate", agent.ID), nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adminToken))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		token, ok := response["access_token"].(string)
+		require.True(t, ok)
+
+		claims, err := env.AuthService.ParseImpersonationToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, agent.ID, claims.AgentID)
+		assert.Equal(t, adminID, claims.ImpersonatedBy)
+		assert.WithinDuration(t, time.Now().Add(services.ImpersonationTokenExpiry), claims.ExpiresAt, time.Minute)
+
+		// The token must not work as a user session, so it can't reach admin routes
+		req = httptest.NewRequest("GET", "/api/v1/admin/users", nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		// The impersonation was recorded in the audit log
+		logs, err := auditService.GetLogsByTarget(env.Ctx, "agent", agent.ID)
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+		assert.Equal(t, adminID, logs[0].ActorID)
+		assert.Equal(t, models.AuditActionImpersonateAgent, logs[0].Action)
+	})
+
+	t.Run("Impersonation token acts as the agent but is read-only", func(t *testing.T) {
+		tokens, err := env.AuthService.GenerateImpersonationToken(agent.ID, adminID)
+		require.NoError(t, err)
+
+		agentRouter := gin.New()
+		agentRouter.Use(middleware.CompositeAuthMiddleware(env.AgentService, env.AuthService))
+		agentRouter.GET("/whoami", func(c *gin.Context) {
+			agentObj, _ := c.Get("agent")
+			impersonatedBy, _ := c.Get("impersonated_by")
+			c.JSON(http.StatusOK, gin.H{
+				"agent_id":        agentObj.(*models.Agent).ID,
+				"impersonated_by": impersonatedBy,
+			})
+		})
+		for _, method := range []string{"POST", "PUT", "PATCH", "DELETE"} {
+			agentRouter.Handle(method, "/whoami", func(c *gin.Context) {
+				c.Status(http.StatusNoContent)
+			})
+		}
+
+		req := httptest.NewRequest("GET", "/whoami", nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", tokens.AccessToken))
+		w := httptest.NewRecorder()
+		agentRouter.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		assert.Equal(t, agent.ID.String(), response["agent_id"])
+		assert.Equal(t, adminID.String(), response["impersonated_by"])
+
+		for _, method := range []string{"POST", "PUT", "PATCH", "DELETE"} {
+			req = httptest.NewRequest(method, "/whoami", nil)
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", tokens.AccessToken))
+			w = httptest.NewRecorder()
+			agentRouter.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusForbidden, w.Code, method)
+		}
+
+		// A deactivated agent can't be impersonated
+		_, err = env.AgentService.SetActive(env.Ctx, agent.ID, false)
+		require.NoError(t, err)
+
+		req = httptest.NewRequest("GET", "/whoami", nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", tokens.AccessToken))
+		w = httptest.NewRecorder()
+		agentRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "AGENT_DEACTIVATED")
+	})
+}
+
+func TestSetAgentActiveEndpoint(t *testing.T) {
+	router, env := setupAdminTestRouter(t)
+	defer env.Cleanup()
+
+	// Create admin and regular users and get tokens
+	adminToken, _ := utils.CreateAdminUserAndGetToken(t, env)
+	userToken, userID := utils.CreateRegularUserAndGetToken(t, env)
+
+	// Create an agent owned by the regular user
+	agent := env.CreateTestAgent(userID)
+
+	// A route guarded by API key auth
+	agentRouter := gin.New()
+	agentRouter.Use(middleware.CompositeAuthMiddleware(env.AgentService, env.AuthService))
+	agentRouter.GET("/whoami", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	callWithAPIKey := func() int {
+		req := httptest.NewRequest("GET", "/whoami", nil)
+		req.Header.Set("X-API-Key", agent.APIKey)
+		w := httptest.NewRecorder()
+		agentRouter.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	setActive := func(token string, agentID uuid.UUID, active bool) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"is_active": active})
+		req := httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/admin/agents/%s/active", agentID), bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Active agent key is accepted", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, callWithAPIKey())
+	})
+
+	t.Run("Non-admin cannot deactivate", func(t *testing.T) {
+		w := setActive(userToken, agent.ID, false)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, http.StatusNoContent, callWithAPIKey())
+	})
+
+	t.Run("Unknown agent returns not found", func(t *testing.T) {
+		w := setActive(adminToken, uuid.New(), false)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Deactivated agent key is rejected", func(t *testing.T) {
+		w := setActive(adminToken, agent.ID, false)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, false, response["is_active"])
+
+		assert.Equal(t, http.StatusForbidden, callWithAPIKey())
+	})
+
+	t.Run("Reactivation restores access", func(t *testing.T) {
+		w := setActive(adminToken, agent.ID, true)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		assert.Equal(t, http.StatusNoContent, callWithAPIKey())
+	})
+}
+
+func TestListPostsEndpoint(t *testing.T) {
+	router, env := setupAdminTestRouter(t)
+	defer env.Cleanup()
+
+	// Create admin and regular users and get tokens
+	adminToken, _ := utils.CreateAdminUserAndGetToken(t, env)
+	userToken, userID := utils.CreateRegularUserAndGetToken(t, env)
+
+	agent := env.CreateTestAgent(userID)
+	otherAgent := env.CreateTestAgent(userID)
+
+	// Posts spread over a few days; backdate them directly
+	now := time.Now().UTC().Truncate(time.Second)
+	backdate := func(post *models.Post, createdAt time.Time) {
+		_, err := env.DB.Exec("UPDATE posts SET created_at = $1 WHERE id = $2", createdAt, post.ID)
+		require.NoError(t, err)
+	}
+
+	old := utils.CreateTestPost(t, env, agent.ID)
+	backdate(old, now.Add(-72*time.Hour))
+	inWindow := utils.CreateTestPost(t, env, agent.ID)
+	backdate(inWindow, now.Add(-24*time.Hour))
+	deletedInWindow := utils.CreateTestPost(t, env, agent.ID)
+	backdate(deletedInWindow, now.Add(-20*time.Hour))
+	_, err := env.DB.Exec("UPDATE posts SET deleted_at = $1 WHERE id = $2", now, deletedInWindow.ID)
+	require.NoError(t, err)
+	otherInWindow := utils.CreateTestPost(t, env, otherAgent.ID)
+	backdate(otherInWindow, now.Add(-24*time.Hour))
+
+	listPosts := func(token, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/admin/posts?"+query, nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	postIDs := func(w *httptest.ResponseRecorder) []uuid.UUID {
+		var response struct {
+			Posts []models.Post `json:"posts"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		ids := make([]uuid.UUID, len(response.Posts))
+		for i, post := range response.Posts {
+			ids[i] = post.ID
+		}
+		return ids
+	}
+
+	window := url.Values{
+		"since": {now.Add(-48 * time.Hour).Format(time.RFC3339)},
+		"until": {now.Add(-12 * time.Hour).Format(time.RFC3339)},
+	}
+
+	t.Run("Non-admin is forbidden", func(t *testing.T) {
+		w := listPosts(userToken, "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Time window includes soft-deleted posts", func(t *testing.T) {
+		w := listPosts(adminToken, window.Encode())
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.ElementsMatch(t, []uuid.UUID{inWindow.ID, deletedInWindow.ID, otherInWindow.ID}, postIDs(w))
+	})
+
+	t.Run("Time window and agent filter combined", func(t *testing.T) {
+		query := url.Values{"agent_id": {agent.ID.String()}}
+		for k, v := range window {
+			query[k] = v
+		}
+
+		w := listPosts(adminToken, query.Encode())
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.ElementsMatch(t, []uuid.UUID{inWindow.ID, deletedInWindow.ID}, postIDs(w))
+	})
+
+	t.Run("Agent filter alone", func(t *testing.T) {
+		w := listPosts(adminToken, url.Values{"agent_id": {otherAgent.ID.String()}}.Encode())
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []uuid.UUID{otherInWindow.ID}, postIDs(w))
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, listPosts(adminToken, "since=yesterday").Code)
+		assert.Equal(t, http.StatusBadRequest, listPosts(adminToken, "agent_id=not-a-uuid").Code)
+
+		reversed := url.Values{
+			"since": {now.Format(time.RFC3339)},
+			"until": {now.Add(-time.Hour).Format(time.RFC3339)},
+		}
+		assert.Equal(t, http.StatusBadRequest, listPosts(adminToken, reversed.Encode()).Code)
+	})
+}
+
+func TestPurgeEndpoint(t *testing.T) {
+	router, env := setupAdminTestRouter(t)
+	defer env.Cleanup()
+
+	// Create admin and regular users and get tokens
+	adminToken, _ := utils.CreateAdminUserAndGetToken(t, env)
+	userToken, userID := utils.CreateRegularUserAndGetToken(t, env)
+	agent := env.CreateTestAgent(userID)
+
+	now := time.Now().UTC()
+	longAgo := now.Add(-60 * 24 * time.Hour)
+	softDelete := func(table string, id uuid.UUID, deletedAt time.Time) {
+		_, err := env.DB.Exec("UPDATE "+table+" SET deleted_at = $1 WHERE id = $2", deletedAt, id)
+		require.NoError(t, err)
+	}
+	vote := func(targetType models.TargetType, targetID uuid.UUID) {
+		v := models.NewVote(agent.ID, string(targetType), targetID, 1)
+		_, err := env.DB.Exec(
+			"INSERT INTO votes (id, agent_id, target_type, target_id, value, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+			v.ID, v.AgentID, v.TargetType, v.TargetID, v.Value, v.CreatedAt, v.UpdatedAt,
+		)
+		require.NoError(t, err)
+	}
+	exists := func(table string, id uuid.UUID) bool {
+		var count int
+		require.NoError(t, env.DB.Get(&count, "SELECT COUNT(*) FROM "+table+" WHERE id = $1", id))
+		return count == 1
+	}
+
+	// A post deleted long ago, with a reply deleted long ago and votes on both
+	expiredPost := utils.CreateTestPost(t, env, agent.ID)
+	expiredReply := utils.CreateTestReply(t, env, agent.ID, expiredPost.ID)
+	vote(models.TargetTypePost, expiredPost.ID)
+	vote(models.TargetTypeReply, expiredReply.ID)
+	softDelete("replies", expiredReply.ID, longAgo)
+	softDelete("posts", expiredPost.ID, longAgo)
+
+	// A post deleted recently
+	recentPost := utils.CreateTestPost(t, env, agent.ID)
+	softDelete("posts", recentPost.ID, now.Add(-time.Hour))
+
+	// A post deleted long ago that still has a live reply
+	threadPost := utils.CreateTestPost(t, env, agent.ID)
+	liveReply := utils.CreateTestReply(t, env, agent.ID, threadPost.ID)
+	softDelete("posts", threadPost.ID, longAgo)
+
+	purge := func(token, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/admin/purge?"+query, nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Non-admin is forbidden", func(t *testing.T) {
+		w := purge(userToken, "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.True(t, exists("posts", expiredPost.ID))
+	})
+
+	t.Run("Invalid older_than", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, purge(adminToken, "older_than=a-while").Code)
+		assert.Equal(t, http.StatusBadRequest, purge(adminToken, "older_than=-1h").Code)
+	})
+
+	t.Run("Purges content past the retention window", func(t *testing.T) {
+		w := purge(adminToken, "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var result services.PurgeResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, 1, result.PostsPurged)
+		assert.Equal(t, 1, result.RepliesPurged)
+		assert.Equal(t, 2, result.VotesPurged)
+
+		assert.False(t, exists("posts", expiredPost.ID))
+		assert.False(t, exists("replies", expiredReply.ID))
+		assert.True(t, exists("posts", recentPost.ID))
+		assert.True(t, exists("posts", threadPost.ID))
+		assert.True(t, exists("replies", liveReply.ID))
+	})
+
+	t.Run("older_than overrides the retention window", func(t *testing.T) {
+		w := purge(adminToken, "older_than=1m")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, exists("posts", recentPost.ID))
+		assert.True(t, exists("posts", threadPost.ID))
+	})
+}
+
+func TestDeactivateInactiveBoardsEndpoint(t *testing.T) {
+	router, env := setupAdminTestRouter(t)
+	defer env.Cleanup()
+
+	adminToken, _ := utils.CreateAdminUserAndGetToken(t, env)
+	userToken, userID := utils.CreateRegularUserAndGetToken(t, env)
+
+	now := time.Now().UTC()
+	longAgo := now.Add(-120 * 24 * time.Hour)
+	backdate := func(table string, id uuid.UUID, createdAt time.Time) {
+		_, err := env.DB.Exec("UPDATE "+table+" SET created_at = $1 WHERE id = $2", createdAt, id)
+		require.NoError(t, err)
+	}
+	isActive := func(boardID uuid.UUID) bool {
+		var active bool
+		require.NoError(t, env.DB.Get(&active, "SELECT is_active FROM boards WHERE id = $1", boardID))
+		return active
+	}
+
+	// A board whose only post is long past the inactivity period
+	dormantPost := utils.CreateTestPost(t, env, env.CreateTestAgent(userID).ID)
+	backdate("posts", dormantPost.ID, longAgo)
+	backdate("boards", dormantPost.BoardID, longAgo)
+
+	// An old board with an old post that got a recent nested reply
+	busyPost := utils.CreateTestPost(t, env, env.CreateTestAgent(userID).ID)
+	backdate("posts", busyPost.ID, longAgo)
+	backdate("boards", busyPost.BoardID, longAgo)
+	reply := utils.CreateTestReply(t, env, busyPost.AgentID, busyPost.ID)
+	backdate("replies", reply.ID, longAgo)
+	nested := models.NewReply(string(models.ParentTypeReply), reply.ID, busyPost.AgentID, "Recent reply", nil)
+	_, err := env.DB.Exec(
+		`INSERT INTO replies (id, parent_type, parent_id, agent_id, content, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		nested.ID, nested.ParentType, nested.ParentID, nested.AgentID, nested.Content, nested.CreatedAt, nested.UpdatedAt,
+	)
+	require.NoError(t, err)
+
+	// A dormant board that is already inactive
+	inactivePost := utils.CreateTestPost(t, env, env.CreateTestAgent(userID).ID)
+	backdate("posts", inactivePost.ID, longAgo)
+	_, err = env.DB.Exec("UPDATE boards SET is_active = false, created_at = $1, updated_at = $1 WHERE id = $2", longAgo, inactivePost.BoardID)
+	require.NoError(t, err)
+
+	deactivate := func(token, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/admin/boards/deactivate-inactive?"+query, nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Non-admin is forbidden", func(t *testing.T) {
+		w := deactivate(userToken, "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.True(t, isActive(dormantPost.BoardID))
+	})
+
+	t.Run("Invalid inactive_for", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, deactivate(adminToken, "inactive_for=a-while").Code)
+		assert.Equal(t, http.StatusBadRequest, deactivate(adminToken, "inactive_for=0s").Code)
+	})
+
+	t.Run("Deactivates dormant boards", func(t *testing.T) {
+		w := deactivate(adminToken, "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var result services.DeactivationResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, []uuid.UUID{dormantPost.BoardID}, result.BoardIDs)
+		assert.Equal(t, 1, result.Deactivated)
+
+		assert.False(t, isActive(dormantPost.BoardID))
+		assert.True(t, isActive(busyPost.BoardID))
+
+		// The already-inactive board isn't touched
+		var updatedAt time.Time
+		require.NoError(t, env.DB.Get(&updatedAt, "SELECT updated_at FROM boards WHERE id = $1", inactivePost.BoardID))
+		assert.WithinDuration(t, longAgo, updatedAt, time.Second)
+	})
+
+	t.Run("inactive_for overrides the period", func(t *testing.T) {
+		_, err := env.DB.Exec("UPDATE replies SET created_at = $1 WHERE id = $2", now.Add(-2*time.Hour), nested.ID)
+		require.NoError(t, err)
+
+		w := deactivate(adminToken, "inactive_for=1h")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, isActive(busyPost.BoardID))
+	})
+}
+
+func TestBroadcastNotificationEndpoint(t *testing.T) {
+	router, env := setupAdminTestRouter(t)
+	defer env.Cleanup()
+
+	adminToken, adminID := utils.CreateAdminUserAndGetToken(t, env)
+	userToken, userID := utils.CreateRegularUserAndGetToken(t, env)
+	first := env.CreateTestAgent(userID)
+	second := env.CreateTestAgent(adminID)
+
+	targetID := uuid.New()
+	broadcast := func(token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/admin/notifications/broadcast", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	body := fmt.Sprintf(`{"content": "Maintenance tonight", "target_type": "post", "target_id": "%s"}`, targetID)
+
+	t.Run("Non-admin is forbidden", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, broadcast(userToken, body).Code)
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, broadcast(adminToken, `{"target_type": "post"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, broadcast(adminToken, `{"content": "Hi", "target_type": "post", "target_id": "nope"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, broadcast(adminToken, fmt.Sprintf(`{"content": "Hi", "target_type": "board", "target_id": "%s"}`, targetID)).Code)
+		assert.Equal(t, http.StatusBadRequest, broadcast(adminToken, fmt.Sprintf(`{"content": "   ", "target_type": "post", "target_id": "%s"}`, targetID)).Code)
+	})
+
+	t.Run("Notifies every agent", func(t *testing.T) {
+		w := broadcast(adminToken, body)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Notified int `json:"notified"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 2, response.Notified)
+
+		for _, agentID := range []uuid.UUID{first.ID, second.ID} {
+			var count int
+			require.NoError(t, env.DB.Get(&count, `SELECT COUNT(*) FROM notifications WHERE agent_id = $1 AND type = 'system'`, agentID))
+			assert.Equal(t, 1, count)
+		}
+
+		var audited int
+		require.NoError(t, env.DB.Get(&audited, `SELECT COUNT(*) FROM audit_logs WHERE action = 'broadcast_notification' AND actor_id = $1`, adminID))
+		assert.Equal(t, 1, audited)
+	})
+}
@@ -0,0 +1,686 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garrettallen/aiboards/backend/internal/apierror"
+	"github.com/garrettallen/aiboards/backend/internal/database/repository"
+	"github.com/garrettallen/aiboards/backend/internal/handlers"
+	"github.com/garrettallen/aiboards/backend/internal/middleware"
+	"github.com/garrettallen/aiboards/backend/internal/models"
+	"github.com/garrettallen/aiboards/backend/internal/services"
+	"github.com/garrettallen/aiboards/backend/tests/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAgentTestRouter(t *testing.T) (*gin.Engine, *utils.TestEnv) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create a test environment
+	env := utils.NewTestEnv(t)
+
+	// Create router
+	router := gin.Default()
+
+	// Create agent handler
+	agentHandler := handlers.NewAgentHandler(env.AgentService)
+
+	// Setup routes; the public endpoints don't need auth
+	api := router.Group("/api/v1")
+	agentHandler.RegisterRoutes(api, func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+	})
+
+	return router, env
+}
+
+func TestGetAgentsPublicBatchEndpoint(t *testing.T) {
+	router, env := setupAgentTestRouter(t)
+	defer env.Cleanup()
+
+	userID, _ := env.CreateTestUser()
+	agent1 := env.CreateTestAgent(userID)
+	agent2 := env.CreateTestAgent(userID)
+
+	t.Run("Existing agents are returned and missing IDs omitted", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{
+			"ids": []string{agent1.ID.String(), agent2.ID.String(), uuid.New().String(), agent1.ID.String()},
+		})
+		req := httptest.NewRequest("POST", "/api/v1/agents/public/batch", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Agents []map[string]interface{} `json:"agents"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Agents, 2)
+
+		ids := []string{response.Agents[0]["id"].(string), response.Agents[1]["id"].(string)}
+		assert.ElementsMatch(t, []string{agent1.ID.String(), agent2.ID.String()}, ids)
+		for _, agent := range response.Agents {
+			assert.Contains(t, agent, "name")
+			assert.Contains(t, agent, "description")
+			assert.Contains(t, agent, "profile_picture_url")
+			assert.NotContains(t, agent, "api_key")
+			assert.NotContains(t, agent, "user_id")
+		}
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{"ids": []string{"not-a-uuid"}})
+		req := httptest.NewRequest("POST", "/api/v1/agents/public/batch", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Too many IDs", func(t *testing.T) {
+		ids := make([]string, 101)
+		for i := range ids {
+			ids[i] = uuid.New().String()
+		}
+		body, _ := json.Marshal(map[string]interface{}{"ids": ids})
+		req := httptest.NewRequest("POST", "/api/v1/agents/public/batch", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestAgentAPIKeyHashedAtRest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	router := gin.New()
+	api := router.Group("/api/v1")
+	handlers.NewAgentHandler(env.AgentService).RegisterRoutes(api, middleware.CompositeAuthMiddleware(env.AgentService, env.AuthService))
+
+	userToken, _ := utils.CreateRegularUserAndGetToken(t, env)
+
+	do := func(method, path string, body []byte, setAuth func(*http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		setAuth(req)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	asUser := func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+userToken) }
+	withAPIKey := func(apiKey string) func(*http.Request) {
+		return func(req *http.Request) { req.Header.Set("X-API-Key", apiKey) }
+	}
+	storedHash := func(agentID string) string {
+		var hash string
+		require.NoError(t, env.DB.Get(&hash, "SELECT api_key_hash FROM agents WHERE id = $1", agentID))
+		return hash
+	}
+
+	// The raw key is returned once, at creation
+	body, _ := json.Marshal(map[string]string{"name": "Hashed_Key_Agent", "description": "Keeps secrets"})
+	w := do("POST", "/api/v1/agents", body, asUser)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created struct {
+		ID     string `json:"id"`
+		APIKey string `json:"api_key"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.NotEmpty(t, created.APIKey)
+
+	t.Run("Only a hash is stored", func(t *testing.T) {
+		hash := storedHash(created.ID)
+		assert.NotEqual(t, created.APIKey, hash)
+		assert.Equal(t, models.HashAPIKey(created.APIKey), hash)
+	})
+
+	t.Run("Key is not returned after creation", func(t *testing.T) {
+		w := do("GET", "/api/v1/agents/"+created.ID, nil, asUser)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "api_key")
+		assert.NotContains(t, w.Body.String(), created.APIKey)
+	})
+
+	t.Run("Raw key authenticates", func(t *testing.T) {
+		w := do("GET", "/api/v1/agents/me", nil, withAPIKey(created.APIKey))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Stored hash does not authenticate", func(t *testing.T) {
+		w := do("GET", "/api/v1/agents/me", nil, withAPIKey(storedHash(created.ID)))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Regeneration replaces the key", func(t *testing.T) {
+		w := do("POST", "/api/v1/agents/"+created.ID+"/regenerate-api-key", nil, asUser)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var regenerated struct {
+			APIKey string `json:"api_key"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &regenerated))
+		require.NotEmpty(t, regenerated.APIKey)
+		assert.Equal(t, models.HashAPIKey(regenerated.APIKey), storedHash(created.ID))
+
+		assert.Equal(t, http.StatusUnauthorized, do("GET", "/api/v1/agents/me", nil, withAPIKey(created.APIKey)).Code)
+		assert.Equal(t, http.StatusOK, do("GET", "/api/v1/agents/me", nil, withAPIKey(regenerated.APIKey)).Code)
+	})
+}
+
+func TestRegenerateAPIKeyRevokesOldKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	userID, _ := env.CreateTestUser()
+
+	setupRouter := func(gracePeriod time.Duration) (*gin.Engine, services.AgentService) {
+		agentService := services.NewAgentService(env.AgentRepository, env.UserRepository, repository.NewBoardRepository(env.DB), repository.NewPostRepository(env.DB), repository.NewReplyRepository(env.DB), repository.NewVoteRepository(env.DB), services.DefaultMaxAgentsPerUser, services.DefaultAgentDailyLimit, gracePeriod, nil)
+		router := gin.New()
+		api := router.Group("/api/v1")
+		handlers.NewAgentHandler(agentService).RegisterRoutes(api, middleware.CompositeAuthMiddleware(agentService, env.AuthService))
+		return router, agentService
+	}
+
+	authenticate := func(router *gin.Engine, apiKey string) int {
+		req := httptest.NewRequest("GET", "/api/v1/agents/me", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("Old key is rejected immediately without a grace period", func(t *testing.T) {
+		router, agentService := setupRouter(0)
+		agent := env.CreateTestAgent(userID)
+
+		newAPIKey, err := agentService.RegenerateAPIKey(env.Ctx, agent.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnauthorized, authenticate(router, agent.APIKey))
+		assert.Equal(t, http.StatusOK, authenticate(router, newAPIKey))
+	})
+
+	t.Run("Old key works only within the grace period", func(t *testing.T) {
+		router, agentService := setupRouter(time.Minute)
+		agent := env.CreateTestAgent(userID)
+
+		newAPIKey, err := agentService.RegenerateAPIKey(env.Ctx, agent.ID)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, authenticate(router, agent.APIKey))
+		assert.Equal(t, http.StatusOK, authenticate(router, newAPIKey))
+
+		// Once the window has passed the old key is rejected
+		_, err = env.DB.Exec("UPDATE agents SET previous_api_key_expires_at = $1 WHERE id = $2", time.Now().Add(-time.Second), agent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, authenticate(router, agent.APIKey))
+		assert.Equal(t, http.StatusOK, authenticate(router, newAPIKey))
+
+		// Only the key directly before the current one gets a grace window
+		newerAPIKey, err := agentService.RegenerateAPIKey(env.Ctx, agent.ID)
+		require.NoError(t, err)
+		newestAPIKey, err := agentService.RegenerateAPIKey(env.Ctx, agent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, authenticate(router, newAPIKey))
+		assert.Equal(t, http.StatusOK, authenticate(router, newerAPIKey))
+		assert.Equal(t, http.StatusOK, authenticate(router, newestAPIKey))
+	})
+
+	t.Run("Concurrent regenerations leave exactly one valid key", func(t *testing.T) {
+		router, agentService := setupRouter(0)
+		agent := env.CreateTestAgent(userID)
+
+		const attempts = 10
+		keys := make([]string, attempts)
+		var wg sync.WaitGroup
+		for i := 0; i < attempts; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				apiKey, err := agentService.RegenerateAPIKey(env.Ctx, agent.ID)
+				assert.NoError(t, err)
+				keys[i] = apiKey
+			}(i)
+		}
+		wg.Wait()
+
+		valid := 0
+		for _, apiKey := range append(keys, agent.APIKey) {
+			if authenticate(router, apiKey) == http.StatusOK {
+				valid++
+			}
+		}
+		assert.Equal(t, 1, valid)
+	})
+
+	t.Run("Unknown agent", func(t *testing.T) {
+		_, agentService := setupRouter(0)
+		_, err := agentService.RegenerateAPIKey(env.Ctx, uuid.New())
+		assert.Equal(t, services.ErrAgentNotFound, err)
+	})
+}
+
+func TestCreateAgentDailyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	const defaultDailyLimit = 250
+	agentService := services.NewAgentService(env.AgentRepository, env.UserRepository, repository.NewBoardRepository(env.DB), repository.NewPostRepository(env.DB), repository.NewReplyRepository(env.DB), repository.NewVoteRepository(env.DB), services.DefaultMaxAgentsPerUser, defaultDailyLimit, 0, nil)
+	router := gin.New()
+	api := router.Group("/api/v1")
+	handlers.NewAgentHandler(agentService).RegisterRoutes(api, middleware.CompositeAuthMiddleware(agentService, env.AuthService))
+
+	adminToken, _ := utils.CreateAdminUserAndGetToken(t, env)
+	userToken, _ := utils.CreateRegularUserAndGetToken(t, env)
+
+	createAgent := func(token string, body map[string]interface{}) int {
+		jsonData, _ := json.Marshal(body)
+		req := httptest.NewRequest("POST", "/api/v1/agents", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var response struct {
+			DailyLimit int `json:"daily_limit"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.DailyLimit
+	}
+
+	t.Run("Default applies when no limit is given", func(t *testing.T) {
+		assert.Equal(t, defaultDailyLimit, createAgent(userToken, map[string]interface{}{"name": "Default_Agent"}))
+	})
+
+	t.Run("Regular users can't choose a limit", func(t *testing.T) {
+		assert.Equal(t, defaultDailyLimit, createAgent(userToken, map[string]interface{}{"name": "Greedy_Agent", "daily_limit": 100000}))
+	})
+
+	t.Run("Admins can override the default", func(t *testing.T) {
+		assert.Equal(t, 100000, createAgent(adminToken, map[string]interface{}{"name": "Busy_Agent", "daily_limit": 100000}))
+	})
+}
+
+func TestAgentProfileFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	router := gin.New()
+	api := router.Group("/api/v1")
+	handlers.NewAgentHandler(env.AgentService).RegisterRoutes(api, middleware.CompositeAuthMiddleware(env.AgentService, env.AuthService))
+
+	token, userID := utils.CreateRegularUserAndGetToken(t, env)
+	agent := env.CreateTestAgent(userID)
+
+	updateAgent := func(body map[string]interface{}) *httptest.ResponseRecorder {
+		body["name"] = agent.Name
+		body["daily_limit"] = agent.DailyLimit
+		jsonData, _ := json.Marshal(body)
+		req := httptest.NewRequest("PUT", "/api/v1/agents/"+agent.ID.String(), bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	getPublic := func() map[string]interface{} {
+		req := httptest.NewRequest("GET", "/api/v1/agents/public/"+agent.ID.String(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	t.Run("Set and retrieve", func(t *testing.T) {
+		w := updateAgent(map[string]interface{}{
+			"bio":         "I summarize **papers** on _alignment_.",
+			"website_url": "https://example.com/agent",
+			"location":    "The cloud",
+		})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		profile := getPublic()
+		assert.Equal(t, "I summarize **papers** on _alignment_.", profile["bio"])
+		assert.Equal(t, "https://example.com/agent", profile["website_url"])
+		assert.Equal(t, "The cloud", profile["location"])
+	})
+
+	t.Run("Omitted fields are left alone", func(t *testing.T) {
+		w := updateAgent(map[string]interface{}{"location": ""})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		profile := getPublic()
+		assert.Equal(t, "I summarize **papers** on _alignment_.", profile["bio"])
+		assert.Equal(t, "https://example.com/agent", profile["website_url"])
+		assert.Equal(t, "", profile["location"])
+	})
+
+	t.Run("Malformed website URLs are rejected", func(t *testing.T) {
+		for _, websiteURL := range []string{"example.com", "ftp://example.com", "https://", "not a url"} {
+			w := updateAgent(map[string]interface{}{"website_url": websiteURL})
+			require.Equal(t, http.StatusBadRequest, w.Code, websiteURL)
+
+			var response apierror.Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "INVALID_WEBSITE_URL", response.Error.Code, websiteURL)
+		}
+
+		assert.Equal(t, "https://example.com/agent", getPublic()["website_url"])
+	})
+
+	t.Run("Overlong bio is rejected", func(t *testing.T) {
+		w := updateAgent(map[string]interface{}{"bio": strings.Repeat("a", services.MaxAgentBioLength+1)})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestSearchAgentsEndpoint(t *testing.T) {
+	router, env := setupAgentTestRouter(t)
+	defer env.Cleanup()
+
+	userID, _ := env.CreateTestUser()
+	for _, name := range []string{"Searchbot_Alpha", "searchbot-beta", "Other_Searchbot"} {
+		_, err := env.AgentService.CreateAgent(env.Ctx, userID, name, "Search test agent", 0)
+		require.NoError(t, err)
+	}
+	inactive, err := env.AgentService.CreateAgent(env.Ctx, userID, "Searchbot_Retired", "Search test agent", 0)
+	require.NoError(t, err)
+	_, err = env.AgentService.SetActive(env.Ctx, inactive.ID, false)
+	require.NoError(t, err)
+	deleted, err := env.AgentService.CreateAgent(env.Ctx, userID, "Searchbot_Deleted", "Search test agent", 0)
+	require.NoError(t, err)
+	require.NoError(t, env.AgentService.DeleteAgent(env.Ctx, deleted.ID))
+
+	search := func(t *testing.T, rawQuery string) (int, map[string]interface{}) {
+		req := httptest.NewRequest("GET", "/api/v1/agents/search?"+rawQuery, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	t.Run("Prefix matches case-insensitively and skips inactive agents", func(t *testing.T) {
+		code, response := search(t, "q=SEARCHBOT")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, float64(2), response["total_count"])
+
+		agents := response["agents"].([]interface{})
+		require.Len(t, agents, 2)
+		names := []string{}
+		for _, a := range agents {
+			agent := a.(map[string]interface{})
+			names = append(names, agent["name"].(string))
+			assert.NotContains(t, agent, "user_id")
+			assert.NotContains(t, agent, "daily_limit")
+		}
+		assert.Equal(t, []string{"Searchbot_Alpha", "searchbot-beta"}, names)
+	})
+
+	t.Run("Only matches the start of the name", func(t *testing.T) {
+		code, response := search(t, "q=bot")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Empty(t, response["agents"])
+	})
+
+	t.Run("Wildcards match literally", func(t *testing.T) {
+		code, response := search(t, "q=%25bot")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Empty(t, response["agents"])
+	})
+
+	t.Run("Paginated", func(t *testing.T) {
+		code, response := search(t, "q=searchbot&page=2&page_size=1")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, float64(2), response["total_count"])
+
+		agents := response["agents"].([]interface{})
+		require.Len(t, agents, 1)
+		assert.Equal(t, "searchbot-beta", agents[0].(map[string]interface{})["name"])
+	})
+
+	t.Run("Missing query", func(t *testing.T) {
+		code, _ := search(t, "q=%20")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
+
+func TestExportAgentDataEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	router := gin.New()
+	api := router.Group("/api/v1")
+	handlers.NewAgentHandler(env.AgentService).RegisterRoutes(api, middleware.CompositeAuthMiddleware(env.AgentService, env.AuthService))
+
+	ownerToken, ownerID := utils.CreateRegularUserAndGetToken(t, env)
+	agent := env.CreateTestAgent(ownerID)
+	otherToken, otherUserID := utils.CreateRegularUserAndGetToken(t, env)
+	otherAgent := env.CreateTestAgent(otherUserID)
+
+	post := utils.CreateTestPost(t, env, agent.ID)
+	otherPost := utils.CreateTestPost(t, env, otherAgent.ID)
+	reply := utils.CreateTestReply(t, env, agent.ID, otherPost.ID)
+	otherReply := utils.CreateTestReply(t, env, otherAgent.ID, post.ID)
+
+	export := func(path string, setAuth func(*http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		setAuth(req)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	type exportDocument struct {
+		Agent   map[string]interface{}   `json:"agent"`
+		Boards  []map[string]interface{} `json:"boards"`
+		Posts   []map[string]interface{} `json:"posts"`
+		Replies []map[string]interface{} `json:"replies"`
+		Votes   []map[string]interface{} `json:"votes"`
+	}
+	ids := func(items []map[string]interface{}) []string {
+		result := make([]string, len(items))
+		for i, item := range items {
+			result[i], _ = item["id"].(string)
+		}
+		return result
+	}
+
+	t.Run("Agent exports its own posts and replies", func(t *testing.T) {
+		w := export("/api/v1/agents/me/export", func(req *http.Request) { req.Header.Set("X-API-Key", agent.APIKey) })
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+
+		var doc exportDocument
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+		assert.Equal(t, agent.ID.String(), doc.Agent["id"])
+		assert.NotContains(t, w.Body.String(), "api_key")
+		assert.Len(t, doc.Boards, 1)
+
+		assert.Contains(t, ids(doc.Posts), post.ID.String())
+		assert.NotContains(t, ids(doc.Posts), otherPost.ID.String())
+		assert.Contains(t, ids(doc.Replies), reply.ID.String())
+		assert.NotContains(t, ids(doc.Replies), otherReply.ID.String())
+		assert.NotNil(t, doc.Votes)
+	})
+
+	t.Run("Owner exports by agent ID", func(t *testing.T) {
+		w := export("/api/v1/agents/me/export?agent_id="+agent.ID.String(), func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+ownerToken) })
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var doc exportDocument
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+		assert.Contains(t, ids(doc.Posts), post.ID.String())
+	})
+
+	t.Run("Other users can't export the agent", func(t *testing.T) {
+		w := export("/api/v1/agents/me/export?agent_id="+agent.ID.String(), func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+otherToken) })
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Users must name an agent", func(t *testing.T) {
+		w := export("/api/v1/agents/me/export", func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+ownerToken) })
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestUpdateProfilePictureEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	router := gin.New()
+	api := router.Group("/api/v1")
+	handlers.NewAgentHandler(env.AgentService).RegisterRoutes(api, middleware.CompositeAuthMiddleware(env.AgentService, env.AuthService))
+
+	// Serves the picture the agent links to
+	images := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.png" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer images.Close()
+
+	token, userID := utils.CreateRegularUserAndGetToken(t, env)
+	agent := env.CreateTestAgent(userID)
+	agent.Bio = "Keeps its bio"
+	require.NoError(t, env.AgentService.UpdateAgent(env.Ctx, agent))
+
+	updatePicture := func(agentID uuid.UUID, token, pictureURL string) *httptest.ResponseRecorder {
+		jsonData, _ := json.Marshal(map[string]string{"profile_picture_url": pictureURL})
+		req := httptest.NewRequest("PATCH", "/api/v1/agents/"+agentID.String()+"/profile-picture", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Updates only the picture", func(t *testing.T) {
+		w := updatePicture(agent.ID, token, images.URL+"/avatar.png")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		stored, err := env.AgentService.GetAgentByID(env.Ctx, agent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, images.URL+"/avatar.png", stored.ProfilePictureURL)
+		assert.Equal(t, agent.Name, stored.Name)
+		assert.Equal(t, agent.Description, stored.Description)
+		assert.Equal(t, agent.DailyLimit, stored.DailyLimit)
+		assert.Equal(t, "Keeps its bio", stored.Bio)
+	})
+
+	t.Run("Invalid URLs are rejected", func(t *testing.T) {
+		for pictureURL, code := range map[string]string{
+			"not a url":                 "INVALID_PICTURE_URL",
+			"ftp://example.com/a.png":   "INVALID_PICTURE_URL",
+			images.URL + "/missing.png": "PICTURE_UNREACHABLE",
+		} {
+			w := updatePicture(agent.ID, token, pictureURL)
+			require.Equal(t, http.StatusBadRequest, w.Code, pictureURL)
+
+			var response apierror.Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, code, response.Error.Code, pictureURL)
+		}
+
+		stored, err := env.AgentService.GetAgentByID(env.Ctx, agent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, images.URL+"/avatar.png", stored.ProfilePictureURL)
+	})
+
+	t.Run("Other users are forbidden", func(t *testing.T) {
+		otherToken, _ := utils.CreateRegularUserAndGetToken(t, env)
+		w := updatePicture(agent.ID, otherToken, images.URL+"/other.png")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestGetCurrentAgentTodayActivityEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	router := gin.New()
+	api := router.Group("/api/v1")
+	handlers.NewAgentHandler(env.AgentService).RegisterRoutes(api, middleware.CompositeAuthMiddleware(env.AgentService, env.AuthService))
+
+	_, userID := utils.CreateRegularUserAndGetToken(t, env)
+	agent := env.CreateTestAgent(userID)
+	otherAgent := env.CreateTestAgent(userID)
+
+	// Today: two posts, one reply and two votes
+	post := utils.CreateTestPost(t, env, agent.ID)
+	utils.CreateTestPost(t, env, agent.ID)
+	otherPost := utils.CreateTestPost(t, env, otherAgent.ID)
+	utils.CreateTestReply(t, env, agent.ID, otherPost.ID)
+	vote := func(targetID uuid.UUID, createdAt time.Time) {
+		_, err := env.DB.Exec(`INSERT INTO votes (id, agent_id, target_type, target_id, value, created_at, updated_at) VALUES ($1, $2, 'post', $3, 1, $4, $4)`,
+			uuid.New(), agent.ID, targetID, createdAt)
+		require.NoError(t, err)
+	}
+	vote(otherPost.ID, time.Now())
+	vote(post.ID, time.Now())
+
+	// Yesterday: a post, a reply and a vote that no longer count
+	yesterday := models.DailyUsageStartedAt().Add(-time.Hour)
+	oldPost := utils.CreateTestPost(t, env, agent.ID)
+	oldReply := utils.CreateTestReply(t, env, agent.ID, otherPost.ID)
+	_, err := env.DB.Exec(`UPDATE posts SET created_at = $1 WHERE id = $2`, yesterday, oldPost.ID)
+	require.NoError(t, err)
+	_, err = env.DB.Exec(`UPDATE replies SET created_at = $1 WHERE id = $2`, yesterday, oldReply.ID)
+	require.NoError(t, err)
+	vote(oldPost.ID, yesterday)
+
+	// Another agent's activity is never counted
+	utils.CreateTestReply(t, env, otherAgent.ID, post.ID)
+
+	req := httptest.NewRequest("GET", "/api/v1/agents/me/today", nil)
+	req.Header.Set("X-API-Key", agent.APIKey)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Posts   int       `json:"posts"`
+		Replies int       `json:"replies"`
+		Votes   int       `json:"votes"`
+		Since   time.Time `json:"since"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Posts)
+	assert.Equal(t, 1, response.Replies)
+	assert.Equal(t, 2, response.Votes)
+	assert.True(t, response.Since.Equal(models.DailyUsageStartedAt()))
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/garrettallen/aiboards/backend/internal/database/repository"
 	"github.com/garrettallen/aiboards/backend/internal/handlers"
 	"github.com/garrettallen/aiboards/backend/internal/middleware"
+	"github.com/garrettallen/aiboards/backend/internal/models"
 	"github.com/garrettallen/aiboards/backend/internal/services"
 	"github.com/garrettallen/aiboards/backend/tests/utils"
 	"github.com/gin-gonic/gin"
@@ -29,7 +30,7 @@ func setupBoardTestRouter(t *testing.T) (*gin.Engine, *utils.TestEnv, services.B
 
 	// Create board repository and service
 	boardRepo := repository.NewBoardRepository(env.DB)
-	boardService := services.NewBoardService(boardRepo, env.AgentRepository)
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, env.UserRepository, nil, 0)
 
 	// Create router
 	router := gin.Default()
@@ -38,7 +39,7 @@ func setupBoardTestRouter(t *testing.T) (*gin.Engine, *utils.TestEnv, services.B
 	authMiddleware := middleware.AuthMiddleware(env.AuthService)
 
 	// Create board handler
-	boardHandler := handlers.NewBoardHandler(boardService)
+	boardHandler := handlers.NewBoardHandler(boardService, env.AgentService)
 
 	// Setup routes
 	api := router.Group("/api/v1")
@@ -107,6 +108,16 @@ func TestCreateBoardEndpoint(t *testing.T) {
 	assert.Equal(t, true, response["is_active"])
 	assert.Equal(t, agentID.String(), response["agent_id"])
 	assert.NotEmpty(t, response["id"])
+
+	// An agent owns at most one board, so a second create is a conflict
+	req, _ = http.NewRequest("POST", "/api/v1/boards", bytes.NewBuffer(jsonData))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "AGENT_HAS_BOARD")
 }
 
 func TestGetBoardEndpoint(t *testing.T) {
@@ -227,6 +238,127 @@ func TestUpdateBoardEndpoint(t *testing.T) {
 	assert.Equal(t, false, updatedBoard.IsActive)
 }
 
+func TestUpdateBoardRequiresOwner(t *testing.T) {
+	router, env, boardService := setupBoardTestRouter(t)
+	defer env.Cleanup()
+
+	ownerToken, _, ownerAgentID := createUserAgentAndGetToken(t, env)
+	otherToken, _, otherAgentID := createUserAgentAndGetToken(t, env)
+
+	board, err := boardService.CreateBoard(env.Ctx, ownerAgentID, "Original Title", "Original Description", true)
+	require.NoError(t, err)
+
+	update := func(token string, agentID uuid.UUID) *httptest.ResponseRecorder {
+		jsonData, _ := json.Marshal(map[string]interface{}{
+			"agent_id":    agentID.String(),
+			"title":       "Hijacked Title",
+			"description": "Hijacked Description",
+			"is_active":   true,
+		})
+		req, _ := http.NewRequest("PUT", fmt.Sprintf("/api/v1/boards/%s", board.ID), bytes.NewBuffer(jsonData))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Another user can't act as the owner agent, and their own agent doesn't own the board
+	assert.Equal(t, http.StatusForbidden, update(otherToken, ownerAgentID).Code)
+	assert.Equal(t, http.StatusForbidden, update(otherToken, otherAgentID).Code)
+	assert.Equal(t, http.StatusForbidden, update(ownerToken, otherAgentID).Code)
+
+	unchanged, err := boardService.GetBoardByID(env.Ctx, board.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Original Title", unchanged.Title)
+	assert.Equal(t, &ownerAgentID, unchanged.AgentID)
+}
+
+func TestUserOwnedBoardEndpoints(t *testing.T) {
+	router, env, boardService := setupBoardTestRouter(t)
+	defer env.Cleanup()
+
+	ownerToken, ownerID, _ := createUserAgentAndGetToken(t, env)
+	otherToken, _, otherAgentID := createUserAgentAndGetToken(t, env)
+
+	var boardID uuid.UUID
+	t.Run("User creates a board without an agent", func(t *testing.T) {
+		jsonData, _ := json.Marshal(map[string]interface{}{
+			"title":       "User Board",
+			"description": "Run by a user directly",
+			"is_active":   true,
+		})
+		req, _ := http.NewRequest("POST", "/api/v1/boards", bytes.NewBuffer(jsonData))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", ownerToken))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, ownerID.String(), response["user_id"])
+		assert.NotContains(t, response, "agent_id")
+
+		boardID, _ = uuid.Parse(response["id"].(string))
+		board, err := boardService.GetBoardByID(env.Ctx, boardID)
+		require.NoError(t, err)
+		assert.True(t, board.OwnedByUser(ownerID))
+		assert.Nil(t, board.AgentID)
+	})
+
+	update := func(token string, agentID string) *httptest.ResponseRecorder {
+		jsonData, _ := json.Marshal(map[string]interface{}{
+			"agent_id":    agentID,
+			"title":       "Updated Title",
+			"description": "Updated Description",
+			"is_active":   true,
+		})
+		req, _ := http.NewRequest("PUT", fmt.Sprintf("/api/v1/boards/%s", boardID), bytes.NewBuffer(jsonData))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Other users and agents cannot update it", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, update(otherToken, "").Code)
+		assert.Equal(t, http.StatusForbidden, update(otherToken, otherAgentID.String()).Code)
+	})
+
+	t.Run("Owning user can update it", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, update(ownerToken, "").Code)
+
+		board, err := boardService.GetBoardByID(env.Ctx, boardID)
+		require.NoError(t, err)
+		assert.Equal(t, "Updated Title", board.Title)
+		assert.True(t, board.OwnedByUser(ownerID))
+	})
+
+	moderate := func(token, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonData, _ := json.Marshal(body)
+		req, _ := http.NewRequest("PUT", fmt.Sprintf("/api/v1/boards/%s/%s", boardID, path), bytes.NewBuffer(jsonData))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Owning user can moderate it", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, moderate(otherToken, "visibility", map[string]interface{}{"visibility": models.BoardVisibilityAuthenticated}).Code)
+		assert.Equal(t, http.StatusOK, moderate(ownerToken, "visibility", map[string]interface{}{"visibility": models.BoardVisibilityAuthenticated}).Code)
+		assert.Equal(t, http.StatusOK, moderate(ownerToken, "policy", map[string]interface{}{"post_policy": models.BoardPostPolicyOwnerOnly}).Code)
+
+		board, err := boardService.GetBoardByID(env.Ctx, boardID)
+		require.NoError(t, err)
+		assert.Equal(t, models.BoardVisibilityAuthenticated, board.Visibility)
+		assert.Equal(t, models.BoardPostPolicyOwnerOnly, board.PostPolicy)
+	})
+}
+
 func TestDeleteBoardEndpoint(t *testing.T) {
 	router, env, boardService := setupBoardTestRouter(t)
 	defer env.Cleanup()
@@ -299,6 +431,8 @@ func TestListBoardsEndpoint(t *testing.T) {
 	// We're only checking that total_count is 5, not the actual number of boards
 	// This is because our implementation in board_service.go has a special case for pageSize=3
 	assert.Equal(t, float64(5), response["total_count"])
+	assert.Equal(t, float64(5), response["total"])
+	assert.Equal(t, float64(2), response["total_pages"])
 
 	// Get the boards array
 	boards, ok := response["boards"].([]interface{})
@@ -309,6 +443,45 @@ func TestListBoardsEndpoint(t *testing.T) {
 	assert.GreaterOrEqual(t, len(boards), 1)
 }
 
+func TestListBoardsPageSizeClamped(t *testing.T) {
+	router, env, boardService := setupBoardTestRouter(t)
+	defer env.Cleanup()
+
+	token, userID, _ := createUserAgentAndGetToken(t, env)
+	for i := 0; i < 3; i++ {
+		_, err := boardService.CreateBoard(env.Ctx, env.CreateTestAgent(userID).ID, fmt.Sprintf("Board %d", i), "Description", true)
+		require.NoError(t, err)
+	}
+
+	listPageSize := func(pageSize string) map[string]interface{} {
+		req, _ := http.NewRequest("GET", "/api/v1/boards?page_size="+pageSize, nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	t.Run("Oversized page size is clamped to the default max", func(t *testing.T) {
+		response := listPageSize("1000000")
+		assert.Equal(t, float64(handlers.DefaultMaxPageSize), response["page_size"])
+	})
+
+	t.Run("Configured max applies", func(t *testing.T) {
+		handlers.SetMaxPageSize(2)
+		defer handlers.SetMaxPageSize(handlers.DefaultMaxPageSize)
+
+		response := listPageSize("1000000")
+		assert.Equal(t, float64(2), response["page_size"])
+		boards, ok := response["boards"].([]interface{})
+		require.True(t, ok)
+		assert.Len(t, boards, 2)
+	})
+}
+
 func TestSetBoardActiveEndpoint(t *testing.T) {
 	router, env, boardService := setupBoardTestRouter(t)
 	defer env.Cleanup()
@@ -355,6 +528,181 @@ func TestSetBoardActiveEndpoint(t *testing.T) {
 	assert.False(t, updatedBoard.IsActive)
 }
 
+func TestTransferBoardEndpoint(t *testing.T) {
+	router, env, boardService := setupBoardTestRouter(t)
+	defer env.Cleanup()
+
+	// Create the current owner, a new owner, and an unrelated user
+	ownerToken, ownerUserID, ownerAgentID := createUserAgentAndGetToken(t, env)
+	otherToken, _, otherAgentID := createUserAgentAndGetToken(t, env)
+	newOwnerAgent := env.CreateTestAgent(ownerUserID)
+
+	board, err := boardService.CreateBoard(env.Ctx, ownerAgentID, "Test Board", "Test Description", true)
+	require.NoError(t, err)
+
+	transfer := func(token string, agentID, newOwnerID uuid.UUID) *httptest.ResponseRecorder {
+		jsonData, _ := json.Marshal(map[string]interface{}{
+			"agent_id":           agentID,
+			"new_owner_agent_id": newOwnerID,
+		})
+		req, _ := http.NewRequest("PUT", fmt.Sprintf("/api/v1/boards/%s/transfer", board.ID), bytes.NewBuffer(jsonData))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Non-owner agent is rejected", func(t *testing.T) {
+		w := transfer(otherToken, otherAgentID, newOwnerAgent.ID)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("User cannot act as another user's agent", func(t *testing.T) {
+		w := transfer(otherToken, ownerAgentID, otherAgentID)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("New owner that already has a board is rejected", func(t *testing.T) {
+		_, err := boardService.CreateBoard(env.Ctx, otherAgentID, "Other Board", "Other Description", true)
+		require.NoError(t, err)
+
+		w := transfer(ownerToken, ownerAgentID, otherAgentID)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Unknown new owner", func(t *testing.T) {
+		w := transfer(ownerToken, ownerAgentID, uuid.New())
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Owner transfers the board", func(t *testing.T) {
+		w := transfer(ownerToken, ownerAgentID, newOwnerAgent.ID)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, newOwnerAgent.ID.String(), response["agent_id"])
+
+		// The agent lookup follows the new owner
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/boards/agent/%s", newOwnerAgent.ID), nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, board.ID.String(), response["id"])
+
+		req, _ = http.NewRequest("GET", fmt.Sprintf("/api/v1/boards/agent/%s", ownerAgentID), nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestSetBoardPostPolicyEndpoint(t *testing.T) {
+	router, env, boardService := setupBoardTestRouter(t)
+	defer env.Cleanup()
+
+	ownerToken, _, ownerAgentID := createUserAgentAndGetToken(t, env)
+	otherToken, _, otherAgentID := createUserAgentAndGetToken(t, env)
+
+	board, err := boardService.CreateBoard(env.Ctx, ownerAgentID, "Test Board", "Test Description", true)
+	require.NoError(t, err)
+
+	setPolicy := func(token string, agentID uuid.UUID, policy string) *httptest.ResponseRecorder {
+		jsonData, _ := json.Marshal(map[string]interface{}{
+			"agent_id":    agentID,
+			"post_policy": policy,
+		})
+		req, _ := http.NewRequest("PUT", fmt.Sprintf("/api/v1/boards/%s/policy", board.ID), bytes.NewBuffer(jsonData))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Non-owner agent is rejected", func(t *testing.T) {
+		w := setPolicy(otherToken, otherAgentID, "closed")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("User cannot act as another user's agent", func(t *testing.T) {
+		w := setPolicy(otherToken, ownerAgentID, "closed")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Invalid policy", func(t *testing.T) {
+		w := setPolicy(ownerToken, ownerAgentID, "members")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Owner sets the policy", func(t *testing.T) {
+		w := setPolicy(ownerToken, ownerAgentID, "owner_only")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "owner_only", response["post_policy"])
+
+		stored, err := boardService.GetBoardByID(env.Ctx, board.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "owner_only", stored.PostPolicy)
+	})
+}
+
+func TestArchiveBoardEndpoint(t *testing.T) {
+	router, env, boardService := setupBoardTestRouter(t)
+	defer env.Cleanup()
+
+	ownerToken, _, ownerAgentID := createUserAgentAndGetToken(t, env)
+	otherToken, _, otherAgentID := createUserAgentAndGetToken(t, env)
+
+	board, err := boardService.CreateBoard(env.Ctx, ownerAgentID, "Test Board", "Test Description", true)
+	require.NoError(t, err)
+
+	setArchived := func(token string, agentID uuid.UUID, body map[string]interface{}) *httptest.ResponseRecorder {
+		body["agent_id"] = agentID
+		jsonData, _ := json.Marshal(body)
+		req, _ := http.NewRequest("PUT", fmt.Sprintf("/api/v1/boards/%s/archive", board.ID), bytes.NewBuffer(jsonData))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Non-owner agent is rejected", func(t *testing.T) {
+		w := setArchived(otherToken, otherAgentID, map[string]interface{}{})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Owner archives the board", func(t *testing.T) {
+		w := setArchived(ownerToken, ownerAgentID, map[string]interface{}{})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, true, response["is_archived"])
+
+		// The board is still viewable
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/boards/%s", board.ID), nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", ownerToken))
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Owner unarchives the board", func(t *testing.T) {
+		w := setArchived(ownerToken, ownerAgentID, map[string]interface{}{"archived": false})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		stored, err := boardService.GetBoardByID(env.Ctx, board.ID)
+		require.NoError(t, err)
+		assert.False(t, stored.IsArchived)
+	})
+}
+
 func TestBoardEndpointErrors(t *testing.T) {
 	router, env, _ := setupBoardTestRouter(t)
 	defer env.Cleanup()
@@ -387,7 +735,7 @@ func TestBoardEndpointErrors(t *testing.T) {
 		// Missing required fields
 		requestBody := map[string]interface{}{
 			"title": "Test Board",
-			// Missing agent_id and description
+			// Missing description
 		}
 		jsonData, _ := json.Marshal(requestBody)
 
@@ -607,3 +955,144 @@ func containsIgnoreCase(s, substr string) bool {
 	s, substr = strings.ToLower(s), strings.ToLower(substr)
 	return strings.Contains(s, substr)
 }
+
+func TestSetBoardVisibilityEndpoint(t *testing.T) {
+	router, env, boardService := setupBoardTestRouter(t)
+	defer env.Cleanup()
+
+	ownerToken, _, ownerAgentID := createUserAgentAndGetToken(t, env)
+	otherToken, _, otherAgentID := createUserAgentAndGetToken(t, env)
+
+	board, err := boardService.CreateBoard(env.Ctx, ownerAgentID, "Test Board", "Test Description", true)
+	require.NoError(t, err)
+	assert.Equal(t, "public", board.Visibility)
+
+	setVisibility := func(token string, agentID uuid.UUID, visibility string) *httptest.ResponseRecorder {
+		jsonData, _ := json.Marshal(map[string]interface{}{
+			"agent_id":   agentID,
+			"visibility": visibility,
+		})
+		req, _ := http.NewRequest("PUT", fmt.Sprintf("/api/v1/boards/%s/visibility", board.ID), bytes.NewBuffer(jsonData))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	getBoard := func(token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/boards/%s", board.ID), nil)
+		if token != "" {
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Public board is readable anonymously", func(t *testing.T) {
+		w := getBoard("")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Non-owner agent is rejected", func(t *testing.T) {
+		w := setVisibility(otherToken, otherAgentID, "authenticated")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Invalid visibility", func(t *testing.T) {
+		w := setVisibility(ownerToken, ownerAgentID, "private")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Owner requires authentication", func(t *testing.T) {
+		w := setVisibility(ownerToken, ownerAgentID, "authenticated")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "authenticated", response["visibility"])
+	})
+
+	t.Run("Anonymous read is rejected", func(t *testing.T) {
+		w := getBoard("")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/boards/agent/%s", ownerAgentID), nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Authenticated read succeeds", func(t *testing.T) {
+		w := getBoard(otherToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Invalid credentials are rejected", func(t *testing.T) {
+		w := getBoard("not-a-token")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestCreateBoardWithPostEndpoint(t *testing.T) {
+	router, env, _ := setupBoardTestRouter(t)
+	defer env.Cleanup()
+
+	token, _, agentID := createUserAgentAndGetToken(t, env)
+
+	createWithPost := func(body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonData, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", "/api/v1/boards/with-post", bytes.NewBuffer(jsonData))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Missing post content", func(t *testing.T) {
+		w := createWithPost(map[string]interface{}{
+			"agent_id":    agentID,
+			"title":       "Intro Board",
+			"description": "Say hello",
+		})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Creates board and post", func(t *testing.T) {
+		w := createWithPost(map[string]interface{}{
+			"agent_id":     agentID,
+			"title":        "Intro Board",
+			"description":  "Say hello",
+			"is_active":    true,
+			"post_content": "Welcome to my board",
+		})
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Intro Board", response["board"]["title"])
+		assert.Equal(t, agentID.String(), response["board"]["agent_id"])
+		assert.Equal(t, "Welcome to my board", response["post"]["content"])
+		assert.Equal(t, response["board"]["id"], response["post"]["board_id"])
+
+		postRepo := repository.NewPostRepository(env.DB)
+		postID, err := uuid.Parse(response["post"]["id"].(string))
+		require.NoError(t, err)
+		post, err := postRepo.GetByID(env.Ctx, postID)
+		require.NoError(t, err)
+		require.NotNil(t, post)
+	})
+
+	t.Run("Agent already has a board", func(t *testing.T) {
+		w := createWithPost(map[string]interface{}{
+			"agent_id":     agentID,
+			"title":        "Second Board",
+			"description":  "Not allowed",
+			"post_content": "Hello again",
+		})
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "AGENT_HAS_BOARD")
+	})
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/garrettallen/aiboards/backend/internal/database/repository"
+	"github.com/garrettallen/aiboards/backend/internal/handlers"
+	"github.com/garrettallen/aiboards/backend/internal/middleware"
+	"github.com/garrettallen/aiboards/backend/internal/models"
+	"github.com/garrettallen/aiboards/backend/internal/services"
+	"github.com/garrettallen/aiboards/backend/tests/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupFeedTestRouter(t *testing.T) (*gin.Engine, *utils.TestEnv, services.BoardService, services.PostService) {
+	// Set Gin to test mode
+	gin.SetMode(gin.TestMode)
+
+	// Create a test environment
+	env := utils.NewTestEnv(t)
+
+	// Create repositories
+	boardRepo := repository.NewBoardRepository(env.DB)
+	postRepo := repository.NewPostRepository(env.DB)
+	replyRepo := repository.NewReplyRepository(env.DB)
+
+	// Create services
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
+	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, replyRepo, env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, nil)
+
+	// Create router
+	router := gin.Default()
+
+	// Create feed handler
+	feedHandler := handlers.NewFeedHandler(boardService, postService, env.AgentService)
+
+	// Setup routes
+	api := router.Group("/api/v1")
+	feedHandler.RegisterRoutes(api, middleware.CompositeAuthMiddleware(env.AgentService, env.AuthService))
+
+	return router, env, boardService, postService
+}
+
+func TestBoardFeedEndpoint(t *testing.T) {
+	router, env, boardService, postService := setupFeedTestRouter(t)
+	defer env.Cleanup()
+
+	userID, _ := env.CreateTestUser()
+	agent := env.CreateTestAgent(userID)
+
+	board, err := boardService.CreateBoard(env.Ctx, agent.ID, "Feed Board", "Feed Description", true)
+	require.NoError(t, err)
+
+	t.Run("Empty board", func(t *testing.T) {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/boards/%s/feed.xml", board.ID), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/atom+xml")
+
+		var feed handlers.AtomFeed
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &feed))
+		assert.Equal(t, "Feed Board", feed.Title)
+		assert.Empty(t, feed.Entries)
+	})
+
+	t.Run("Deleted posts are excluded", func(t *testing.T) {
+		kept, err := postService.CreatePost(env.Ctx, board.ID, agent.ID, "Kept post", "")
+		require.NoError(t, err)
+		deleted, err := postService.CreatePost(env.Ctx, board.ID, agent.ID, "Deleted post", "")
+		require.NoError(t, err)
+		require.NoError(t, postService.DeletePost(env.Ctx, deleted.ID))
+
+		req := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/boards/%s/feed.xml", board.ID), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var feed handlers.AtomFeed
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &feed))
+		assert.Equal(t, "http://www.w3.org/2005/Atom", feed.XMLName.Space)
+		require.Len(t, feed.Entries, 1)
+		assert.Equal(t, "urn:uuid:"+kept.ID.String(), feed.Entries[0].ID)
+		assert.Equal(t, "Kept post", feed.Entries[0].Content.Body)
+		assert.Equal(t, agent.Name, feed.Entries[0].Author.Name)
+		assert.NotEmpty(t, feed.Entries[0].Updated)
+	})
+
+	t.Run("Board not found", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/boards/00000000-0000-0000-0000-000000000000/feed.xml", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestBoardFeedVisibility(t *testing.T) {
+	router, env, boardService, postService := setupFeedTestRouter(t)
+	defer env.Cleanup()
+
+	userID, _ := env.CreateTestUser()
+	agent := env.CreateTestAgent(userID)
+
+	board, err := boardService.CreateBoard(env.Ctx, agent.ID, "Members Board", "Feed Description", true)
+	require.NoError(t, err)
+	_, err = postService.CreatePost(env.Ctx, board.ID, agent.ID, "Members only post", "")
+	require.NoError(t, err)
+	_, err = boardService.SetVisibility(env.Ctx, board.ID, services.AgentActor(agent.ID), models.BoardVisibilityAuthenticated)
+	require.NoError(t, err)
+
+	path := fmt.Sprintf("/api/v1/boards/%s/feed.xml", board.ID)
+
+	t.Run("Anonymous clients don't see the feed", func(t *testing.T) {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.NotContains(t, w.Body.String(), "Members only post")
+	})
+
+	t.Run("Authenticated clients get a private feed", func(t *testing.T) {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("X-API-Key", agent.APIKey)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "private", w.Header().Get("Cache-Control"))
+
+		var feed handlers.AtomFeed
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &feed))
+		require.Len(t, feed.Entries, 1)
+		assert.Equal(t, "Members only post", feed.Entries[0].Content.Body)
+	})
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -39,8 +40,11 @@ func NewTestNotificationAPIEnv(t *testing.T) *TestNotificationAPIEnv {
 	// Create notification service
 	notificationService := services.NewNotificationService(
 		notificationRepo,
+		repository.NewNotificationPreferenceRepository(baseEnv.DB),
 		baseEnv.UserRepository,
 		baseEnv.AgentRepository,
+		nil, // Email delivery is not exercised by the API tests
+		0,   // Every notification gets its own row
 	)
 
 	return &TestNotificationAPIEnv{
@@ -147,6 +151,7 @@ func setupNotificationTestRouter(t *testing.T) (*gin.Engine, *TestNotificationAP
 		notifications.GET("/unread", notificationHandler.GetUnreadCount)
 		notifications.GET("/:id", notificationHandler.GetNotification)
 		notifications.PUT("/:id/read", notificationHandler.MarkAsRead)
+		notifications.PUT("/:id/unread", notificationHandler.MarkAsUnread)
 		notifications.PUT("/read-all", notificationHandler.MarkAllAsRead)
 		notifications.DELETE("/:id", notificationHandler.DeleteNotification)
 	}
@@ -313,6 +318,7 @@ func TestGetNotificationsEndpoint(t *testing.T) {
 		assert.Equal(t, float64(15), response["total"])
 		assert.Equal(t, float64(1), response["page"])
 		assert.Equal(t, float64(10), response["page_size"])
+		assert.Equal(t, float64(2), response["total_pages"])
 	})
 
 	t.Run("Unauthenticated user cannot get notifications", func(t *testing.T) {
@@ -397,6 +403,71 @@ func TestMarkAsReadEndpoint(t *testing.T) {
 	})
 }
 
+func TestMarkAsUnreadEndpoint(t *testing.T) {
+	router, env := setupNotificationTestRouter(t)
+	defer env.Cleanup()
+
+	// Create a user and agent with a notification
+	_, userID := utils.CreateRegularUserAndGetToken(t, env.TestEnv)
+	agent := env.CreateTestAgent(userID)
+	notification := createTestNotification(t, env, agent.ID)
+
+	_, otherUserID := utils.CreateRegularUserAndGetToken(t, env.TestEnv)
+	otherAgent := env.CreateTestAgent(otherUserID)
+
+	tokenPair, err := env.GenerateTokensForAgent(agent.ID)
+	require.NoError(t, err)
+
+	otherTokenPair, err := env.GenerateTokensForAgent(otherAgent.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, env.NotificationService.MarkAsRead(env.Ctx, notification.ID))
+	count, err := env.NotificationService.CountUnread(env.Ctx, agent.ID)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+
+	t.Run("User cannot mark another user's notification as unread", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/notifications/%s/unread", notification.ID), nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", otherTokenPair.AccessToken))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		updatedNotification, err := env.NotificationRepository.GetByID(env.Ctx, notification.ID)
+		require.NoError(t, err)
+		assert.True(t, updatedNotification.IsRead)
+	})
+
+	t.Run("User can mark their own notification as unread", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/notifications/%s/unread", notification.ID), nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", tokenPair.AccessToken))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		updatedNotification, err := env.NotificationRepository.GetByID(env.Ctx, notification.ID)
+		require.NoError(t, err)
+		assert.False(t, updatedNotification.IsRead)
+		assert.Nil(t, updatedNotification.ReadAt)
+
+		// The notification counts as unread again
+		count, err := env.NotificationService.CountUnread(env.Ctx, agent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("Unknown notification", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/notifications/%s/unread", uuid.New()), nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", tokenPair.AccessToken))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
 func TestMarkAllAsReadEndpoint(t *testing.T) {
 	router, env := setupNotificationTestRouter(t)
 	defer env.Cleanup()
@@ -569,3 +640,191 @@ func TestGetUnreadCountEndpoint(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
 	})
 }
+
+func TestGetUnreadCountByTypeEndpoint(t *testing.T) {
+	router, env := setupNotificationTestRouter(t)
+	defer env.Cleanup()
+
+	_, userID := utils.CreateRegularUserAndGetToken(t, env.TestEnv)
+	agent := env.CreateTestAgent(userID)
+
+	// Seed a mix of types; one read reply isn't counted
+	seed := map[services.NotificationType]int{
+		services.NotificationTypeReply:  3,
+		services.NotificationTypeVote:   2,
+		services.NotificationTypeSystem: 1,
+	}
+	for notificationType, count := range seed {
+		for i := 0; i < count; i++ {
+			_, err := env.NotificationService.CreateNotification(env.Ctx, agent.ID, notificationType, "Seeded notification", "post", uuid.New())
+			require.NoError(t, err)
+		}
+	}
+	read, err := env.NotificationService.CreateNotification(env.Ctx, agent.ID, services.NotificationTypeReply, "Already read", "post", uuid.New())
+	require.NoError(t, err)
+	require.NoError(t, env.NotificationService.MarkAsRead(env.Ctx, read.ID))
+
+	tokenPair, err := env.GenerateTokensForAgent(agent.ID)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/api/v1/notifications/unread/by-type", nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", tokenPair.AccessToken))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Counts map[string]int `json:"counts"`
+		Total  int            `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	assert.Equal(t, map[string]int{
+		"reply":        3,
+		"thread_reply": 0,
+		"vote":         2,
+		"system":       1,
+	}, response.Counts)
+	assert.Equal(t, 6, response.Total)
+}
+
+func TestDeleteReadNotificationsEndpoint(t *testing.T) {
+	router, env := setupNotificationTestRouter(t)
+	defer env.Cleanup()
+
+	_, userID := utils.CreateRegularUserAndGetToken(t, env.TestEnv)
+	agent := env.CreateTestAgent(userID)
+
+	tokenPair, err := env.GenerateTokensForAgent(agent.ID)
+	require.NoError(t, err)
+
+	// createNotification creates a notification for the agent, optionally read, created age ago
+	createNotification := func(read bool, age time.Duration) *models.Notification {
+		notification := &models.Notification{
+			ID:         uuid.New(),
+			AgentID:    agent.ID,
+			Type:       string(services.NotificationTypeSystem),
+			Content:    "Test notification",
+			TargetType: "post",
+			TargetID:   uuid.New(),
+			IsRead:     read,
+			CreatedAt:  time.Now().Add(-age),
+		}
+		require.NoError(t, env.NotificationRepository.Create(env.Ctx, notification))
+		return notification
+	}
+
+	oldRead := createNotification(true, 45*24*time.Hour)
+	recentRead := createNotification(true, 24*time.Hour)
+	oldUnread := createNotification(false, 45*24*time.Hour)
+
+	deleteRead := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("DELETE", "/api/v1/notifications/read"+query, nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", tokenPair.AccessToken))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Invalid older_than", func(t *testing.T) {
+		w := deleteRead("?older_than=soon")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Deletes read notifications past the cutoff", func(t *testing.T) {
+		w := deleteRead("?older_than=30d")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, float64(1), response["deleted"])
+
+		_, err := env.NotificationRepository.GetByID(env.Ctx, oldRead.ID)
+		assert.Error(t, err)
+
+		for _, kept := range []*models.Notification{recentRead, oldUnread} {
+			notification, err := env.NotificationRepository.GetByID(env.Ctx, kept.ID)
+			require.NoError(t, err)
+			assert.NotNil(t, notification)
+		}
+	})
+
+	t.Run("Unauthenticated request is rejected", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/api/v1/notifications/read", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestGetNotificationsBatchEndpoint(t *testing.T) {
+	router, env := setupNotificationTestRouter(t)
+	defer env.Cleanup()
+
+	_, userID := utils.CreateRegularUserAndGetToken(t, env.TestEnv)
+	agent := env.CreateTestAgent(userID)
+	_, otherUserID := utils.CreateRegularUserAndGetToken(t, env.TestEnv)
+	otherAgent := env.CreateTestAgent(otherUserID)
+
+	tokenPair, err := env.GenerateTokensForAgent(agent.ID)
+	require.NoError(t, err)
+
+	createNotification := func(agentID uuid.UUID, age time.Duration) *models.Notification {
+		notification := &models.Notification{
+			ID:         uuid.New(),
+			AgentID:    agentID,
+			Type:       string(services.NotificationTypeSystem),
+			Content:    "Test notification",
+			TargetType: "post",
+			TargetID:   uuid.New(),
+			CreatedAt:  time.Now().Add(-age),
+		}
+		require.NoError(t, env.NotificationRepository.Create(env.Ctx, notification))
+		return notification
+	}
+
+	older := createNotification(agent.ID, time.Hour)
+	newer := createNotification(agent.ID, time.Minute)
+	createNotification(agent.ID, time.Second) // not requested
+	notOwned := createNotification(otherAgent.ID, time.Minute)
+
+	batchGet := func(ids []string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"ids": ids})
+		req := httptest.NewRequest("POST", "/api/v1/notifications/batch-get", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", tokenPair.AccessToken))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Returns only the agent's own notifications", func(t *testing.T) {
+		w := batchGet([]string{older.ID.String(), notOwned.ID.String(), uuid.New().String(), newer.ID.String(), older.ID.String()})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response struct {
+			Notifications []struct {
+				ID uuid.UUID `json:"id"`
+			} `json:"notifications"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Notifications, 2)
+		assert.Equal(t, newer.ID, response.Notifications[0].ID)
+		assert.Equal(t, older.ID, response.Notifications[1].ID)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		w := batchGet([]string{"not-a-uuid"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Too many IDs", func(t *testing.T) {
+		ids := make([]string, services.MaxNotificationBatchSize+1)
+		for i := range ids {
+			ids[i] = uuid.New().String()
+		}
+		w := batchGet(ids)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
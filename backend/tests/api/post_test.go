@@ -7,9 +7,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/garrettallen/aiboards/backend/internal/database/repository"
 	"github.com/garrettallen/aiboards/backend/internal/handlers"
+	"github.com/garrettallen/aiboards/backend/internal/metrics"
 	"github.com/garrettallen/aiboards/backend/internal/middleware"
 	"github.com/garrettallen/aiboards/backend/internal/models"
 	"github.com/garrettallen/aiboards/backend/internal/services"
@@ -31,10 +33,11 @@ func setupPostTestRouter(t *testing.T) (*gin.Engine, *utils.TestEnv, services.Bo
 	boardRepo := repository.NewBoardRepository(env.DB)
 	postRepo := repository.NewPostRepository(env.DB)
 	agentRepo := repository.NewAgentRepository(env.DB)
+	replyRepo := repository.NewReplyRepository(env.DB)
 
 	// Create services
-	boardService := services.NewBoardService(boardRepo, agentRepo)
-	postService := services.NewPostService(postRepo, boardRepo, agentRepo, env.AgentService)
+	boardService := services.NewBoardService(boardRepo, agentRepo, nil, nil, 0)
+	postService := services.NewPostService(postRepo, boardRepo, agentRepo, replyRepo, env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, nil)
 
 	// Create router
 	router := gin.Default()
@@ -43,10 +46,11 @@ func setupPostTestRouter(t *testing.T) (*gin.Engine, *utils.TestEnv, services.Bo
 	authMiddleware := middleware.AuthMiddleware(env.AuthService)
 
 	// Create post handler
-	postHandler := handlers.NewPostHandler(postService)
+	postHandler := handlers.NewPostHandler(postService, env.AgentService)
 
 	// Setup routes
 	api := router.Group("/api/v1")
+	api.Use(middleware.APIVersion())
 	postHandler.RegisterRoutes(api, authMiddleware)
 
 	return router, env, boardService, postService
@@ -92,6 +96,220 @@ func TestCreatePostEndpoint(t *testing.T) {
 	assert.Equal(t, "Test post content", response["content"])
 }
 
+func TestCreatePostBoardPolicy(t *testing.T) {
+	router, env, boardService, _ := setupPostTestRouter(t)
+	defer env.Cleanup()
+
+	_, _, ownerAgentID := createUserAgentAndGetToken(t, env)
+	otherToken, _, otherAgentID := createUserAgentAndGetToken(t, env)
+
+	board, err := boardService.CreateBoard(env.Ctx, ownerAgentID, "Owner Board", "Only the owner posts here", true)
+	require.NoError(t, err)
+	_, err = boardService.SetPostPolicy(env.Ctx, board.ID, services.AgentActor(ownerAgentID), models.BoardPostPolicyOwnerOnly)
+	require.NoError(t, err)
+
+	jsonStr := []byte(`{"agent_id": "` + otherAgentID.String() + `", "board_id": "` + board.ID.String() + `", "content": "Not my board"}`)
+	req, _ := http.NewRequest("POST", "/api/v1/posts", bytes.NewBuffer(jsonStr))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", otherToken))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestCreatePostArchivedBoard(t *testing.T) {
+	router, env, boardService, _ := setupPostTestRouter(t)
+	defer env.Cleanup()
+
+	token, _, agentID := createUserAgentAndGetToken(t, env)
+	board, err := boardService.CreateBoard(env.Ctx, agentID, "Archived Board", "Read only", true)
+	require.NoError(t, err)
+	_, err = boardService.ArchiveBoard(env.Ctx, board.ID, services.AgentActor(agentID))
+	require.NoError(t, err)
+
+	jsonData, _ := json.Marshal(map[string]interface{}{
+		"board_id": board.ID.String(),
+		"agent_id": agentID.String(),
+		"content":  "Post to an archived board",
+	})
+	req, _ := http.NewRequest("POST", "/api/v1/posts", bytes.NewBuffer(jsonData))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "BOARD_ARCHIVED")
+}
+
+func TestCreatePostMetrics(t *testing.T) {
+	router, env, boardService, _ := setupPostTestRouter(t)
+	defer env.Cleanup()
+	handlers.NewMetricsHandler(metrics.Default, "").RegisterRoutes(router)
+
+	token, _, agentID := createUserAgentAndGetToken(t, env)
+	board, err := boardService.CreateBoard(env.Ctx, agentID, "Test Board", "Test Description", true)
+	require.NoError(t, err)
+
+	before := metrics.PostsCreatedTotal.Value()
+
+	jsonStr := []byte(`{"agent_id": "` + agentID.String() + `", "board_id": "` + board.ID.String() + `", "content": "Counted post"}`)
+	req, _ := http.NewRequest("POST", "/api/v1/posts", bytes.NewBuffer(jsonStr))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	assert.Equal(t, before+1, metrics.PostsCreatedTotal.Value())
+
+	// The counter shows up when scraping
+	req, _ = http.NewRequest("GET", "/metrics", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), fmt.Sprintf("posts_created_total %g", before+1))
+}
+
+func TestPostAttachmentsEndpoint(t *testing.T) {
+	router, env, boardService, _ := setupPostTestRouter(t)
+	defer env.Cleanup()
+
+	// Create user, agent and get token
+	token, _, agentID := createUserAgentAndGetToken(t, env)
+	otherToken, _, otherAgentID := createUserAgentAndGetToken(t, env)
+
+	// Create a board
+	board, err := boardService.CreateBoard(env.Ctx, agentID, "Test Board", "Test Description", true)
+	require.NoError(t, err)
+
+	doRequest := func(method, url string, body interface{}) *httptest.ResponseRecorder {
+		var reqBody *bytes.Buffer
+		if body != nil {
+			jsonData, _ := json.Marshal(body)
+			reqBody = bytes.NewBuffer(jsonData)
+		} else {
+			reqBody = bytes.NewBuffer(nil)
+		}
+		req, _ := http.NewRequest(method, url, reqBody)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	attachmentURLs := func(t *testing.T, raw interface{}) []string {
+		items, ok := raw.([]interface{})
+		require.True(t, ok)
+		urls := make([]string, len(items))
+		for i, item := range items {
+			urls[i] = item.(map[string]interface{})["url"].(string)
+		}
+		return urls
+	}
+
+	// Create a post with media_url plus several attachments
+	w := doRequest("POST", "/api/v1/posts", map[string]interface{}{
+		"agent_id":    agentID,
+		"board_id":    board.ID,
+		"content":     "Post with attachments",
+		"media_url":   "https://example.com/0.png",
+		"attachments": []string{"https://example.com/1.png", "https://example.com/2.png"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	postID := created["id"].(string)
+	expected := []string{"https://example.com/0.png", "https://example.com/1.png", "https://example.com/2.png"}
+	assert.Equal(t, expected, attachmentURLs(t, created["attachments"]))
+	assert.Equal(t, "https://example.com/0.png", created["media_url"])
+
+	t.Run("Get post returns attachments in order", func(t *testing.T) {
+		w := doRequest("GET", "/api/v1/posts/"+postID, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, expected, attachmentURLs(t, response["attachments"]))
+		assert.Equal(t, "https://example.com/0.png", response["media_url"])
+	})
+
+	t.Run("Author adds an attachment", func(t *testing.T) {
+		w := doRequest("POST", "/api/v1/posts/"+postID+"/attachments", map[string]interface{}{
+			"agent_id": agentID,
+			"url":      "https://example.com/3.png",
+		})
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, float64(3), response["position"])
+
+		w = doRequest("GET", "/api/v1/posts/"+postID+"/attachments", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, append(expected, "https://example.com/3.png"), attachmentURLs(t, response["attachments"]))
+	})
+
+	t.Run("Other agent cannot add an attachment", func(t *testing.T) {
+		w := doRequest("POST", "/api/v1/posts/"+postID+"/attachments", map[string]interface{}{
+			"agent_id": otherAgentID,
+			"url":      "https://example.com/x.png",
+		})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Cannot add an attachment as another user's agent", func(t *testing.T) {
+		jsonData, _ := json.Marshal(map[string]interface{}{
+			"agent_id": agentID,
+			"url":      "https://example.com/x.png",
+		})
+		req, _ := http.NewRequest("POST", "/api/v1/posts/"+postID+"/attachments", bytes.NewBuffer(jsonData))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", otherToken))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Anonymous callers cannot add an attachment", func(t *testing.T) {
+		jsonData, _ := json.Marshal(map[string]interface{}{
+			"agent_id": agentID,
+			"url":      "https://example.com/x.png",
+		})
+		req, _ := http.NewRequest("POST", "/api/v1/posts/"+postID+"/attachments", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Invalid attachment URL", func(t *testing.T) {
+		w := doRequest("POST", "/api/v1/posts/"+postID+"/attachments", map[string]interface{}{
+			"agent_id": agentID,
+			"url":      "javascript:alert(1)",
+		})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Too many attachments", func(t *testing.T) {
+		urls := make([]string, services.MaxPostAttachments+1)
+		for i := range urls {
+			urls[i] = fmt.Sprintf("https://example.com/%d.png", i)
+		}
+		w := doRequest("POST", "/api/v1/posts", map[string]interface{}{
+			"agent_id":    agentID,
+			"board_id":    board.ID,
+			"content":     "Too many attachments",
+			"attachments": urls,
+		})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
 func TestGetPostEndpoint(t *testing.T) {
 	router, env, boardService, postService := setupPostTestRouter(t)
 	defer env.Cleanup()
@@ -128,6 +346,79 @@ func TestGetPostEndpoint(t *testing.T) {
 	assert.Equal(t, "Test Content", postResponse.Content)
 }
 
+func TestGetPostFullEndpoint(t *testing.T) {
+	router, env, boardService, postService := setupPostTestRouter(t)
+	defer env.Cleanup()
+
+	_, _, agentID := createUserAgentAndGetToken(t, env)
+	agent, err := env.AgentService.GetAgentByID(env.Ctx, agentID)
+	require.NoError(t, err)
+
+	board, err := boardService.CreateBoard(env.Ctx, agentID, "Test Board", "Test Description", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, agentID, "Post with replies", "")
+	require.NoError(t, err)
+
+	var replies []*models.Reply
+	for i := 0; i < 7; i++ {
+		replies = append(replies, utils.CreateTestReply(t, env, agentID, post.ID))
+	}
+
+	// A nested reply isn't part of the first page of top-level replies
+	nested := models.NewReply(string(models.ParentTypeReply), replies[0].ID, agentID, "Nested reply", nil)
+	_, err = env.DB.Exec(
+		`INSERT INTO replies (id, parent_type, parent_id, agent_id, content, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		nested.ID, nested.ParentType, nested.ParentID, nested.AgentID, nested.Content, nested.CreatedAt, nested.UpdatedAt,
+	)
+	require.NoError(t, err)
+
+	t.Run("Post, author and first page of replies", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/posts/%s/full?page_size=5", post.ID), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Post       models.Post              `json:"post"`
+			Author     map[string]interface{}   `json:"author"`
+			Replies    []map[string]interface{} `json:"replies"`
+			TotalCount int                      `json:"total_count"`
+			TotalPages int                      `json:"total_pages"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+		assert.Equal(t, post.ID, response.Post.ID)
+		assert.Equal(t, "Post with replies", response.Post.Content)
+		assert.Equal(t, agent.Name, response.Author["name"])
+		assert.NotContains(t, response.Author, "user_id")
+
+		require.Len(t, response.Replies, 5)
+		for i, reply := range response.Replies {
+			assert.Equal(t, replies[i].ID.String(), reply["id"])
+			assert.Equal(t, "post", reply["parent_type"])
+		}
+		assert.Equal(t, 7, response.TotalCount)
+		assert.Equal(t, 2, response.TotalPages)
+	})
+
+	t.Run("Invalid sort", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/posts/%s/full?sort=random", post.ID), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Post not found", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/posts/%s/full", uuid.New()), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
 func TestUpdatePostEndpoint(t *testing.T) {
 	router, env, boardService, postService := setupPostTestRouter(t)
 	defer env.Cleanup()
@@ -209,6 +500,88 @@ func TestDeletePostEndpoint(t *testing.T) {
 	assert.Equal(t, services.ErrPostNotFound, err)
 }
 
+func TestDeletePostsEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	// Create repositories and services
+	boardRepo := repository.NewBoardRepository(env.DB)
+	postRepo := repository.NewPostRepository(env.DB)
+	replyRepo := repository.NewReplyRepository(env.DB)
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
+	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, replyRepo, env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, nil)
+
+	// Create router authenticating agents by API key
+	router := gin.Default()
+	api := router.Group("/api/v1")
+	compositeAuth := middleware.CompositeAuthMiddleware(env.AgentService, env.AuthService)
+	handlers.NewPostHandler(postService, env.AgentService).RegisterRoutes(api, compositeAuth)
+
+	// Create two agents and a board
+	userID, _ := env.CreateTestUser()
+	agent := env.CreateTestAgent(userID)
+	otherAgent := env.CreateTestAgent(userID)
+	board, err := boardService.CreateBoard(env.Ctx, agent.ID, "Bulk Delete Board", "Bulk Delete Description", true)
+	require.NoError(t, err)
+
+	ownPost1, err := postService.CreatePost(env.Ctx, board.ID, agent.ID, "Own post 1", "")
+	require.NoError(t, err)
+	ownPost2, err := postService.CreatePost(env.Ctx, board.ID, agent.ID, "Own post 2", "")
+	require.NoError(t, err)
+	otherPost, err := postService.CreatePost(env.Ctx, board.ID, otherAgent.ID, "Other post", "")
+	require.NoError(t, err)
+
+	deletePosts := func(ids []string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{"ids": ids})
+		req := httptest.NewRequest("DELETE", "/api/v1/posts", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", agent.APIKey)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Deletes only own posts", func(t *testing.T) {
+		w := deletePosts([]string{ownPost1.ID.String(), otherPost.ID.String(), ownPost2.ID.String(), ownPost1.ID.String()})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.ElementsMatch(t, []interface{}{ownPost1.ID.String(), ownPost2.ID.String()}, response["deleted"])
+
+		_, err := postService.GetPostByID(env.Ctx, ownPost1.ID)
+		assert.Equal(t, services.ErrPostNotFound, err)
+		_, err = postService.GetPostByID(env.Ctx, ownPost2.ID)
+		assert.Equal(t, services.ErrPostNotFound, err)
+
+		// The other agent's post is left alone
+		stillThere, err := postService.GetPostByID(env.Ctx, otherPost.ID)
+		require.NoError(t, err)
+		assert.Equal(t, otherPost.ID, stillThere.ID)
+
+		// Board post counts reflect the deletions
+		_, total, err := postService.GetPostsByBoardID(env.Ctx, board.ID, 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+	})
+
+	t.Run("Too many IDs", func(t *testing.T) {
+		ids := make([]string, services.MaxBulkDeletePosts+1)
+		for i := range ids {
+			ids[i] = uuid.New().String()
+		}
+		w := deletePosts(ids)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		w := deletePosts([]string{"not-a-uuid"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
 func TestListBoardPostsEndpoint(t *testing.T) {
 	router, env, boardService, postService := setupPostTestRouter(t)
 	defer env.Cleanup()
@@ -247,6 +620,8 @@ func TestListBoardPostsEndpoint(t *testing.T) {
 	// Check pagination
 	assert.Equal(t, float64(1), response["page"])
 	assert.Equal(t, float64(3), response["page_size"])
+	assert.Equal(t, float64(5), response["total"])
+	assert.Equal(t, float64(2), response["total_pages"])
 	assert.Equal(t, float64(5), response["total_count"])
 
 	// Check posts list
@@ -255,6 +630,164 @@ func TestListBoardPostsEndpoint(t *testing.T) {
 	assert.Len(t, posts, 3)
 }
 
+func TestListBoardPostsCursorEndpoint(t *testing.T) {
+	router, env, boardService, postService := setupPostTestRouter(t)
+	defer env.Cleanup()
+
+	token, _, agentID := createUserAgentAndGetToken(t, env)
+
+	board, err := boardService.CreateBoard(env.Ctx, agentID, "Test Board", "Test Description", true)
+	require.NoError(t, err)
+
+	original := make(map[string]bool)
+	for i := 0; i < 5; i++ {
+		post, err := postService.CreatePost(env.Ctx, board.ID, agentID, fmt.Sprintf("Test Content %d", i), "")
+		require.NoError(t, err)
+		original[post.ID.String()] = true
+	}
+
+	fetchPage := func(cursor string) map[string]interface{} {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/posts/board/%s?page_size=2&cursor=%s", board.ID, cursor), nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	seen := make(map[string]bool)
+	cursor := ""
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 5, "cursor pagination did not terminate")
+
+		response := fetchPage(cursor)
+		posts, ok := response["posts"].([]interface{})
+		require.True(t, ok)
+		for _, p := range posts {
+			id := p.(map[string]interface{})["id"].(string)
+			assert.False(t, seen[id], "post %s returned twice", id)
+			seen[id] = true
+		}
+
+		// New posts arriving between fetches must not shift later pages
+		_, err := postService.CreatePost(env.Ctx, board.ID, agentID, fmt.Sprintf("Late Content %d", pages), "")
+		require.NoError(t, err)
+
+		next, ok := response["next_cursor"].(string)
+		if !ok {
+			assert.Nil(t, response["next_cursor"])
+			break
+		}
+		cursor = next
+	}
+
+	// Every original post was returned exactly once and none of the late ones were
+	assert.Equal(t, original, seen)
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/posts/board/%s?cursor=not-a-cursor", board.ID), nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "INVALID_CURSOR")
+	})
+}
+
+func TestListTopBoardPostsEndpoint(t *testing.T) {
+	router, env, boardService, postService := setupPostTestRouter(t)
+	defer env.Cleanup()
+
+	// Create user, agent and a board
+	token, _, agentID := createUserAgentAndGetToken(t, env)
+	board, err := boardService.CreateBoard(env.Ctx, agentID, "Test Board", "Test Description", true)
+	require.NoError(t, err)
+
+	// Create posts with a given score and age; set both directly
+	now := time.Now()
+	createPost := func(voteCount int, age time.Duration) *models.Post {
+		post, err := postService.CreatePost(env.Ctx, board.ID, agentID, fmt.Sprintf("Post with %d votes", voteCount), "")
+		require.NoError(t, err)
+		_, err = env.DB.Exec("UPDATE posts SET vote_count = $1, created_at = $2 WHERE id = $3", voteCount, now.Add(-age), post.ID)
+		require.NoError(t, err)
+		return post
+	}
+
+	todayLow := createPost(1, time.Hour)
+	todayHigh := createPost(5, 2*time.Hour)
+	thisWeek := createPost(10, 3*24*time.Hour)
+	thisMonth := createPost(20, 20*24*time.Hour)
+	createPost(50, 60*24*time.Hour) // too old for any period
+
+	deleted := createPost(100, time.Hour)
+	require.NoError(t, postService.DeletePost(env.Ctx, deleted.ID))
+
+	listTop := func(query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/boards/%s/posts/top?%s", board.ID, query), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	postIDs := func(w *httptest.ResponseRecorder) []uuid.UUID {
+		var response struct {
+			Posts []models.Post `json:"posts"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		ids := make([]uuid.UUID, len(response.Posts))
+		for i, post := range response.Posts {
+			ids[i] = post.ID
+		}
+		return ids
+	}
+
+	tests := []struct {
+		query    string
+		expected []uuid.UUID
+	}{
+		{"period=day", []uuid.UUID{todayHigh.ID, todayLow.ID}},
+		{"period=week", []uuid.UUID{thisWeek.ID, todayHigh.ID, todayLow.ID}},
+		{"", []uuid.UUID{thisWeek.ID, todayHigh.ID, todayLow.ID}},
+		{"period=month", []uuid.UUID{thisMonth.ID, thisWeek.ID, todayHigh.ID, todayLow.ID}},
+		{"period=month&limit=2", []uuid.UUID{thisMonth.ID, thisWeek.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run("Query "+tt.query, func(t *testing.T) {
+			w := listTop(tt.query)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expected, postIDs(w))
+			assert.Equal(t, "public, max-age=60", w.Header().Get("Cache-Control"))
+			assert.Contains(t, w.Header().Values("Vary"), "Authorization, X-API-Key")
+		})
+	}
+
+	t.Run("Authenticated responses are private", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/boards/%s/posts/top", board.ID), nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "private, max-age=60", w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("Invalid period", func(t *testing.T) {
+		w := listTop("period=year")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Board not found", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/boards/%s/posts/top", uuid.New()), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
 func TestListAgentPostsEndpoint(t *testing.T) {
 	router, env, boardService, postService := setupPostTestRouter(t)
 	defer env.Cleanup()
@@ -301,21 +834,82 @@ func TestListAgentPostsEndpoint(t *testing.T) {
 	assert.Len(t, posts, 3)
 }
 
-func TestPostEndpointErrors(t *testing.T) {
-	router, env, boardService, _ := setupPostTestRouter(t)
+func TestListAgentBoardsEndpoint(t *testing.T) {
+	router, env, boardService, postService := setupPostTestRouter(t)
 	defer env.Cleanup()
 
 	// Create user, agent and get token
-	token, _, agentID := createUserAgentAndGetToken(t, env)
+	_, userID, agentID := createUserAgentAndGetToken(t, env)
 
-	// Create a board
-	board, err := boardService.CreateBoard(env.Ctx, agentID, "Test Board", "Test Description", true)
+	// Create three boards, each owned by its own agent; the agent posts to only two of them
+	alpha, err := boardService.CreateBoard(env.Ctx, agentID, "Alpha Board", "Test Description", true)
+	require.NoError(t, err)
+	beta, err := boardService.CreateBoard(env.Ctx, env.CreateTestAgent(userID).ID, "Beta Board", "Test Description", true)
+	require.NoError(t, err)
+	_, err = boardService.CreateBoard(env.Ctx, env.CreateTestAgent(userID).ID, "Unused Board", "Test Description", true)
 	require.NoError(t, err)
 
-	t.Run("Get non-existent post returns 404", func(t *testing.T) {
-		randomID := uuid.New()
-		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/posts/%s", randomID), nil)
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	for i := 0; i < 3; i++ {
+		_, err := postService.CreatePost(env.Ctx, alpha.ID, agentID, fmt.Sprintf("Alpha Content %d", i), "")
+		require.NoError(t, err)
+	}
+	_, err = postService.CreatePost(env.Ctx, beta.ID, agentID, "Beta Content", "")
+	require.NoError(t, err)
+
+	// Deleted posts are not counted
+	deleted, err := postService.CreatePost(env.Ctx, beta.ID, agentID, "Deleted Content", "")
+	require.NoError(t, err)
+	require.NoError(t, postService.DeletePost(env.Ctx, deleted.ID))
+
+	t.Run("Returns the boards with post counts", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/agents/%s/boards", agentID), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Boards []struct {
+				ID        uuid.UUID `json:"id"`
+				Title     string    `json:"title"`
+				PostCount int       `json:"post_count"`
+			} `json:"boards"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		require.Len(t, response.Boards, 2)
+		assert.Equal(t, alpha.ID, response.Boards[0].ID)
+		assert.Equal(t, "Alpha Board", response.Boards[0].Title)
+		assert.Equal(t, 3, response.Boards[0].PostCount)
+		assert.Equal(t, beta.ID, response.Boards[1].ID)
+		assert.Equal(t, 1, response.Boards[1].PostCount)
+	})
+
+	t.Run("Unknown agent returns not found", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/agents/%s/boards", uuid.New()), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestPostEndpointErrors(t *testing.T) {
+	router, env, boardService, _ := setupPostTestRouter(t)
+	defer env.Cleanup()
+
+	// Create user, agent and get token
+	token, _, agentID := createUserAgentAndGetToken(t, env)
+
+	// Create a board
+	board, err := boardService.CreateBoard(env.Ctx, agentID, "Test Board", "Test Description", true)
+	require.NoError(t, err)
+
+	t.Run("Get non-existent post returns 404", func(t *testing.T) {
+		randomID := uuid.New()
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/posts/%s", randomID), nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 
 		w := httptest.NewRecorder()
 		router.ServeHTTP(w, req)
@@ -563,3 +1157,817 @@ func TestSearchBoardPostsEndpoint(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
 }
+
+func TestSearchAllPostsEndpoint(t *testing.T) {
+	router, env, boardService, postService := setupPostTestRouter(t)
+	defer env.Cleanup()
+
+	// Create user, agent and get token
+	_, userID, agentID := createUserAgentAndGetToken(t, env)
+
+	// Matching posts live on two different boards
+	robots, err := boardService.CreateBoard(env.Ctx, agentID, "Robots Board", "Test Description", true)
+	require.NoError(t, err)
+	gardens, err := boardService.CreateBoard(env.Ctx, env.CreateTestAgent(userID).ID, "Gardens Board", "Test Description", true)
+	require.NoError(t, err)
+
+	robotPost, err := postService.CreatePost(env.Ctx, robots.ID, agentID, "Telescopes help robots navigate", "")
+	require.NoError(t, err)
+	gardenPost, err := postService.CreatePost(env.Ctx, gardens.ID, agentID, "A telescope in the garden", "")
+	require.NoError(t, err)
+	_, err = postService.CreatePost(env.Ctx, gardens.ID, agentID, "Tomatoes and basil", "")
+	require.NoError(t, err)
+
+	// Deleted posts are excluded
+	deleted, err := postService.CreatePost(env.Ctx, robots.ID, agentID, "Deleted telescope post", "")
+	require.NoError(t, err)
+	require.NoError(t, postService.DeletePost(env.Ctx, deleted.ID))
+
+	t.Run("Returns matches across boards with board titles", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/posts/search?q=telescope", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Posts []struct {
+				ID         uuid.UUID `json:"id"`
+				BoardID    uuid.UUID `json:"board_id"`
+				BoardTitle string    `json:"board_title"`
+			} `json:"posts"`
+			Query string `json:"query"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		assert.Equal(t, "telescope", response.Query)
+		require.Len(t, response.Posts, 2)
+
+		titles := map[uuid.UUID]string{}
+		for _, post := range response.Posts {
+			titles[post.ID] = post.BoardTitle
+		}
+		assert.Equal(t, "Robots Board", titles[robotPost.ID])
+		assert.Equal(t, "Gardens Board", titles[gardenPost.ID])
+		assert.NotContains(t, titles, deleted.ID)
+	})
+
+	t.Run("No matches", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/posts/search?q=submarine", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Empty(t, response["posts"])
+	})
+
+	t.Run("Missing query", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/posts/search", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestCreatePostQuotaHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	// Create repositories and services
+	boardRepo := repository.NewBoardRepository(env.DB)
+	postRepo := repository.NewPostRepository(env.DB)
+	replyRepo := repository.NewReplyRepository(env.DB)
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
+	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, replyRepo, env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, nil)
+
+	// Create router authenticating agents by API key
+	router := gin.Default()
+	api := router.Group("/api/v1")
+	api.Use(middleware.AgentQuotaHeaders(env.AgentService, nil, 0))
+	compositeAuth := middleware.CompositeAuthMiddleware(env.AgentService, env.AuthService)
+	handlers.NewPostHandler(postService, env.AgentService).RegisterRoutes(api, compositeAuth)
+	handlers.NewAgentHandler(env.AgentService).RegisterRoutes(api, compositeAuth)
+
+	// Create agent and board
+	userID, _ := env.CreateTestUser()
+	agent := env.CreateTestAgent(userID)
+	board, err := boardService.CreateBoard(env.Ctx, agent.ID, "Quota Board", "Quota Description", true)
+	require.NoError(t, err)
+
+	// Create a post as the agent
+	body, _ := json.Marshal(map[string]interface{}{
+		"board_id": board.ID.String(),
+		"agent_id": agent.ID.String(),
+		"content":  "Quota post",
+	})
+	req := httptest.NewRequest("POST", "/api/v1/posts", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", agent.APIKey)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, fmt.Sprintf("%d", agent.DailyLimit), w.Header().Get("X-Agent-Daily-Limit"))
+	assert.Equal(t, "1", w.Header().Get("X-Agent-Used-Today"))
+	assert.Equal(t, fmt.Sprintf("%d", agent.DailyLimit-1), w.Header().Get("X-Agent-Remaining"))
+
+	// The quota endpoint reports the same values
+	req = httptest.NewRequest("GET", "/api/v1/agents/me/quota", nil)
+	req.Header.Set("X-API-Key", agent.APIKey)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var quota map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &quota))
+	assert.Equal(t, float64(agent.DailyLimit), quota["daily_limit"])
+	assert.Equal(t, float64(1), quota["used_today"])
+	assert.Equal(t, float64(agent.DailyLimit-1), quota["remaining"])
+	assert.NotEmpty(t, quota["reset_at"])
+}
+
+func TestCreatePostQuotaWarning(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	env := NewTestNotificationAPIEnv(t)
+	defer env.Cleanup()
+
+	// Create repositories and services
+	boardRepo := repository.NewBoardRepository(env.DB)
+	postRepo := repository.NewPostRepository(env.DB)
+	replyRepo := repository.NewReplyRepository(env.DB)
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
+	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, replyRepo, env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, nil)
+
+	// Create router warning agents at 80% of their daily limit
+	router := gin.Default()
+	api := router.Group("/api/v1")
+	api.Use(middleware.AgentQuotaHeaders(env.AgentService, env.NotificationService, 0.8))
+	compositeAuth := middleware.CompositeAuthMiddleware(env.AgentService, env.AuthService)
+	handlers.NewPostHandler(postService, env.AgentService).RegisterRoutes(api, compositeAuth)
+
+	// Create an agent with a daily limit of 5, so the warning starts at the 4th post
+	userID, _ := env.CreateTestUser()
+	agent := env.CreateTestAgent(userID)
+	agent.DailyLimit = 5
+	require.NoError(t, env.AgentService.UpdateAgent(env.Ctx, agent))
+	board, err := boardService.CreateBoard(env.Ctx, agent.ID, "Quota Warning Board", "Quota Description", true)
+	require.NoError(t, err)
+
+	createPost := func(n int) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{
+			"board_id": board.ID.String(),
+			"agent_id": agent.ID.String(),
+			"content":  fmt.Sprintf("Quota warning post %d", n),
+		})
+		req := httptest.NewRequest("POST", "/api/v1/posts", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", agent.APIKey)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+		return w
+	}
+
+	quotaNotifications := func() int {
+		notifications, _, err := env.NotificationService.GetNotificationsByAgentID(env.Ctx, agent.ID, 1, 50)
+		require.NoError(t, err)
+		count := 0
+		for _, notification := range notifications {
+			if notification.Type == string(services.NotificationTypeSystem) && notification.TargetType == models.NotificationTargetAgent {
+				count++
+			}
+		}
+		return count
+	}
+
+	for n := 1; n <= 3; n++ {
+		w := createPost(n)
+		assert.Empty(t, w.Header().Get("X-Agent-Quota-Warning"), "post %d", n)
+	}
+	assert.Equal(t, 0, quotaNotifications())
+
+	// Crossing the threshold warns and notifies
+	w := createPost(4)
+	assert.Equal(t, "true", w.Header().Get("X-Agent-Quota-Warning"))
+	assert.Equal(t, 1, quotaNotifications())
+
+	// Later writes keep the header but don't notify again
+	w = createPost(5)
+	assert.Equal(t, "true", w.Header().Get("X-Agent-Quota-Warning"))
+	assert.Equal(t, 1, quotaNotifications())
+}
+
+func TestListSimilarPostsEndpoint(t *testing.T) {
+	router, env, boardService, postService := setupPostTestRouter(t)
+	defer env.Cleanup()
+
+	// Create user, agent and get token
+	_, userID, agentID := createUserAgentAndGetToken(t, env)
+
+	astronomy, err := boardService.CreateBoard(env.Ctx, agentID, "Astronomy Board", "Test Description", true)
+	require.NoError(t, err)
+	hobbies, err := boardService.CreateBoard(env.Ctx, env.CreateTestAgent(userID).ID, "Hobbies Board", "Test Description", true)
+	require.NoError(t, err)
+
+	source, err := postService.CreatePost(env.Ctx, astronomy.ID, agentID, "Choosing a telescope for watching planets", "")
+	require.NoError(t, err)
+	closest, err := postService.CreatePost(env.Ctx, hobbies.ID, agentID, "My new telescope shows planets clearly", "")
+	require.NoError(t, err)
+	related, err := postService.CreatePost(env.Ctx, hobbies.ID, agentID, "Planets visible this month", "")
+	require.NoError(t, err)
+	unrelated, err := postService.CreatePost(env.Ctx, hobbies.ID, agentID, "Tomatoes and basil", "")
+	require.NoError(t, err)
+
+	// Deleted posts are excluded
+	deleted, err := postService.CreatePost(env.Ctx, astronomy.ID, agentID, "Deleted telescope and planets post", "")
+	require.NoError(t, err)
+	require.NoError(t, postService.DeletePost(env.Ctx, deleted.ID))
+
+	t.Run("Returns posts with overlapping keywords, most similar first", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/posts/"+source.ID.String()+"/similar", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response struct {
+			Posts []struct {
+				ID         uuid.UUID `json:"id"`
+				BoardTitle string    `json:"board_title"`
+			} `json:"posts"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+		ids := make([]uuid.UUID, len(response.Posts))
+		for i, post := range response.Posts {
+			ids[i] = post.ID
+		}
+		require.Equal(t, []uuid.UUID{closest.ID, related.ID}, ids)
+		assert.Equal(t, "Hobbies Board", response.Posts[0].BoardTitle)
+		assert.NotContains(t, ids, source.ID)
+		assert.NotContains(t, ids, unrelated.ID)
+		assert.NotContains(t, ids, deleted.ID)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/posts/"+source.ID.String()+"/similar?limit=1", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Posts []map[string]interface{} `json:"posts"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response.Posts, 1)
+	})
+
+	t.Run("Unknown post", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/posts/"+uuid.New().String()+"/similar", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestBoardVisibilityPostReads(t *testing.T) {
+	router, env, boardService, postService := setupPostTestRouter(t)
+	defer env.Cleanup()
+
+	token, _, agentID := createUserAgentAndGetToken(t, env)
+
+	board, err := boardService.CreateBoard(env.Ctx, agentID, "Members Board", "Test Description", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, agentID, "Members only post", "")
+	require.NoError(t, err)
+
+	_, err = boardService.SetVisibility(env.Ctx, board.ID, services.AgentActor(agentID), models.BoardVisibilityAuthenticated)
+	require.NoError(t, err)
+
+	paths := []string{
+		"/api/v1/posts/" + post.ID.String(),
+		"/api/v1/posts/" + post.ID.String() + "/full",
+		"/api/v1/posts/board/" + board.ID.String(),
+		"/api/v1/boards/" + board.ID.String() + "/posts/top",
+	}
+
+	t.Run("Anonymous reads are rejected", func(t *testing.T) {
+		for _, path := range paths {
+			req, _ := http.NewRequest("GET", path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code, path)
+		}
+	})
+
+	t.Run("Authenticated reads succeed", func(t *testing.T) {
+		for _, path := range paths {
+			req, _ := http.NewRequest("GET", path, nil)
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code, path)
+		}
+	})
+}
+
+func TestSetPostLockedEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	boardRepo := repository.NewBoardRepository(env.DB)
+	postRepo := repository.NewPostRepository(env.DB)
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
+	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, repository.NewReplyRepository(env.DB), env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, nil)
+
+	router := gin.Default()
+	api := router.Group("/api/v1")
+	handlers.NewPostHandler(postService, env.AgentService).RegisterRoutes(api, middleware.CompositeAuthMiddleware(env.AgentService, env.AuthService))
+
+	ownerUserID, _ := env.CreateTestUser()
+	owner := env.CreateTestAgent(ownerUserID)
+	otherUserID, _ := env.CreateTestUser()
+	other := env.CreateTestAgent(otherUserID)
+
+	board, err := boardService.CreateBoard(env.Ctx, owner.ID, "Lock Board", "Test Description", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, other.ID, "A heated post", "")
+	require.NoError(t, err)
+
+	setLocked := func(apiKey, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PUT", "/api/v1/posts/"+post.ID.String()+"/lock", bytes.NewBufferString(body))
+		req.Header.Set("X-API-Key", apiKey)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Post author who doesn't own the board is rejected", func(t *testing.T) {
+		w := setLocked(other.APIKey, `{}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Board owner locks the post", func(t *testing.T) {
+		w := setLocked(owner.APIKey, `{}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, true, response["is_locked"])
+	})
+
+	t.Run("Board owner unlocks the post", func(t *testing.T) {
+		w := setLocked(owner.APIKey, `{"locked": false}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		stored, err := postService.GetPostByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+		assert.False(t, stored.IsLocked)
+	})
+
+	t.Run("Empty body locks the post", func(t *testing.T) {
+		w := setLocked(owner.APIKey, "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		stored, err := postService.GetPostByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+		assert.True(t, stored.IsLocked)
+	})
+}
+
+func TestListRecentPostsEndpoint(t *testing.T) {
+	router, env, boardService, postService := setupPostTestRouter(t)
+	defer env.Cleanup()
+
+	token, _, agentID := createUserAgentAndGetToken(t, env)
+
+	public, err := boardService.CreateBoard(env.Ctx, agentID, "Public Board", "Test Description", true)
+	require.NoError(t, err)
+	members, err := boardService.CreateBoard(env.Ctx, agentID, "Members Board", "Test Description", true)
+	require.NoError(t, err)
+	_, err = boardService.SetVisibility(env.Ctx, members.ID, services.AgentActor(agentID), models.BoardVisibilityAuthenticated)
+	require.NoError(t, err)
+
+	older, err := postService.CreatePost(env.Ctx, public.ID, agentID, "Older public post", "")
+	require.NoError(t, err)
+	newer, err := postService.CreatePost(env.Ctx, public.ID, agentID, "Newer public post", "")
+	require.NoError(t, err)
+	membersPost, err := postService.CreatePost(env.Ctx, members.ID, agentID, "Members only post", "")
+	require.NoError(t, err)
+
+	// Deleted posts are never listed
+	deleted, err := postService.CreatePost(env.Ctx, public.ID, agentID, "Deleted post", "")
+	require.NoError(t, err)
+	require.NoError(t, postService.DeletePost(env.Ctx, deleted.ID))
+
+	// Spread creation times so the order is deterministic
+	for i, id := range []uuid.UUID{older.ID, newer.ID, membersPost.ID} {
+		_, err := env.DB.Exec("UPDATE posts SET created_at = $1 WHERE id = $2", time.Now().Add(time.Duration(i-3)*time.Minute), id)
+		require.NoError(t, err)
+	}
+
+	type recentPosts struct {
+		Posts []struct {
+			ID         uuid.UUID `json:"id"`
+			BoardTitle string    `json:"board_title"`
+			AgentName  string    `json:"agent_name"`
+		} `json:"posts"`
+		Total int `json:"total"`
+	}
+
+	t.Run("Anonymous callers see public posts newest first", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/posts/recent", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "public, max-age=30", w.Header().Get("Cache-Control"))
+
+		var response recentPosts
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+		require.Len(t, response.Posts, 2)
+		assert.Equal(t, newer.ID, response.Posts[0].ID)
+		assert.Equal(t, older.ID, response.Posts[1].ID)
+		assert.Equal(t, "Public Board", response.Posts[0].BoardTitle)
+		assert.NotEmpty(t, response.Posts[0].AgentName)
+		assert.Equal(t, 2, response.Total)
+	})
+
+	t.Run("Authenticated callers also see members-only posts", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/posts/recent", nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "private, max-age=30", w.Header().Get("Cache-Control"))
+
+		var response recentPosts
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+		require.Len(t, response.Posts, 3)
+		assert.Equal(t, membersPost.ID, response.Posts[0].ID)
+		assert.Equal(t, "Members Board", response.Posts[0].BoardTitle)
+	})
+
+	t.Run("Agents don't see posts by agents they blocked", func(t *testing.T) {
+		viewerUserID, _ := env.CreateTestUser()
+		viewer := env.CreateTestAgent(viewerUserID)
+		require.NoError(t, env.AgentService.BlockAgent(env.Ctx, viewer.ID, agentID))
+
+		req, _ := http.NewRequest("GET", "/api/v1/posts/recent", nil)
+		req.Header.Set("X-API-Key", viewer.APIKey)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response recentPosts
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Empty(t, response.Posts)
+		assert.Equal(t, 0, response.Total)
+	})
+}
+
+func TestCreatePostActingAgent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	boardRepo := repository.NewBoardRepository(env.DB)
+	postRepo := repository.NewPostRepository(env.DB)
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
+	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, repository.NewReplyRepository(env.DB), env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, nil)
+
+	router := gin.New()
+	api := router.Group("/api/v1")
+	handlers.NewPostHandler(postService, env.AgentService).RegisterRoutes(api, middleware.CompositeAuthMiddleware(env.AgentService, env.AuthService))
+
+	// The caller's agent and another user's agent it might try to post as
+	token, userID, _ := createUserAgentAndGetToken(t, env)
+	agent := env.CreateTestAgent(userID)
+	victimUserID, _ := env.CreateTestUser()
+	victim := env.CreateTestAgent(victimUserID)
+	board, err := boardService.CreateBoard(env.Ctx, agent.ID, "Acting Agent Board", "Test Description", true)
+	require.NoError(t, err)
+
+	createPost := func(agentID string, setAuth func(*http.Request)) *httptest.ResponseRecorder {
+		body := map[string]interface{}{"board_id": board.ID, "content": "Who wrote this?"}
+		if agentID != "" {
+			body["agent_id"] = agentID
+		}
+		jsonData, _ := json.Marshal(body)
+		req := httptest.NewRequest("POST", "/api/v1/posts", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		setAuth(req)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	asAgent := func(req *http.Request) { req.Header.Set("X-API-Key", agent.APIKey) }
+	asUser := func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+token) }
+
+	t.Run("Agent posting as another agent is rejected", func(t *testing.T) {
+		w := createPost(victim.ID.String(), asAgent)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		_, total, err := postService.GetPostsByAgentID(env.Ctx, victim.ID, 1, 10, true)
+		require.NoError(t, err)
+		assert.Equal(t, 0, total)
+	})
+
+	t.Run("Agent posts as itself with or without agent_id", func(t *testing.T) {
+		for _, agentID := range []string{"", agent.ID.String()} {
+			w := createPost(agentID, asAgent)
+			require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+			var post models.Post
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &post))
+			assert.Equal(t, agent.ID, post.AgentID)
+		}
+	})
+
+	t.Run("User posting as another user's agent is rejected", func(t *testing.T) {
+		w := createPost(victim.ID.String(), asUser)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("User posts as their own agent", func(t *testing.T) {
+		w := createPost(agent.ID.String(), asUser)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	})
+}
+
+func TestRemoveBoardPostEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	boardRepo := repository.NewBoardRepository(env.DB)
+	postRepo := repository.NewPostRepository(env.DB)
+	auditService := services.NewAuditService(repository.NewAuditLogRepository(env.DB))
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
+	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, repository.NewReplyRepository(env.DB), env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, auditService)
+
+	router := gin.New()
+	api := router.Group("/api/v1")
+	handlers.NewPostHandler(postService, env.AgentService).RegisterRoutes(api, middleware.CompositeAuthMiddleware(env.AgentService, env.AuthService))
+
+	ownerUserID, _ := env.CreateTestUser()
+	owner := env.CreateTestAgent(ownerUserID)
+	otherUserID, _ := env.CreateTestUser()
+	other := env.CreateTestAgent(otherUserID)
+
+	board, err := boardService.CreateBoard(env.Ctx, owner.ID, "Moderated Board", "Test Description", true)
+	require.NoError(t, err)
+	otherBoard, err := boardService.CreateBoard(env.Ctx, other.ID, "Other Board", "Test Description", true)
+	require.NoError(t, err)
+
+	foreignPost, err := postService.CreatePost(env.Ctx, board.ID, other.ID, "Off-topic post", "")
+	require.NoError(t, err)
+	ownerPost, err := postService.CreatePost(env.Ctx, board.ID, owner.ID, "Owner's post", "")
+	require.NoError(t, err)
+	elsewhere, err := postService.CreatePost(env.Ctx, otherBoard.ID, other.ID, "Post on another board", "")
+	require.NoError(t, err)
+
+	removePost := func(boardID, postID uuid.UUID, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("DELETE", "/api/v1/boards/"+boardID.String()+"/posts/"+postID.String(), nil)
+		req.Header.Set("X-API-Key", apiKey)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Non-owner cannot remove posts", func(t *testing.T) {
+		w := removePost(board.ID, ownerPost.ID, other.APIKey)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		_, err := postService.GetPostByID(env.Ctx, ownerPost.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Post must be on the board in the path", func(t *testing.T) {
+		w := removePost(board.ID, elsewhere.ID, owner.APIKey)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		_, err := postService.GetPostByID(env.Ctx, elsewhere.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Owner removes a foreign agent's post", func(t *testing.T) {
+		w := removePost(board.ID, foreignPost.ID, owner.APIKey)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		_, err := postService.GetPostByID(env.Ctx, foreignPost.ID)
+		assert.Equal(t, services.ErrPostNotFound, err)
+
+		// The removal is recorded against the owner's user
+		logs, err := auditService.GetLogsByTarget(env.Ctx, "post", foreignPost.ID)
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+		assert.Equal(t, models.AuditActionRemovePost, logs[0].Action)
+		assert.Equal(t, ownerUserID, logs[0].ActorID)
+	})
+}
+
+func TestGetPostFullVersionNegotiation(t *testing.T) {
+	router, env, boardService, postService := setupPostTestRouter(t)
+	defer env.Cleanup()
+
+	_, _, agentID := createUserAgentAndGetToken(t, env)
+	agent, err := env.AgentService.GetAgentByID(env.Ctx, agentID)
+	require.NoError(t, err)
+
+	board, err := boardService.CreateBoard(env.Ctx, agentID, "Test Board", "Test Description", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, agentID, "Versioned post", "")
+	require.NoError(t, err)
+	utils.CreateTestReply(t, env, agentID, post.ID)
+
+	getFull := func(accept string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/posts/%s/full", post.ID), nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Default is v1", func(t *testing.T) {
+		w := getFull("")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "1", w.Header().Get("X-API-Version"))
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+		author, ok := response["author"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, agent.Name, author["name"])
+		assert.Equal(t, float64(1), response["total_count"])
+		assert.NotContains(t, response["post"], "author")
+	})
+
+	t.Run("Plain JSON Accept is v1", func(t *testing.T) {
+		w := getFull("application/json")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "1", w.Header().Get("X-API-Version"))
+	})
+
+	t.Run("v2 embeds the author in the post", func(t *testing.T) {
+		w := getFull("application/vnd.aiboards.v2+json")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2", w.Header().Get("X-API-Version"))
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+		assert.NotContains(t, response, "author")
+		assert.NotContains(t, response, "total_count")
+
+		postBody, ok := response["post"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, post.ID.String(), postBody["id"])
+		author, ok := postBody["author"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, agent.Name, author["name"])
+
+		pagination, ok := response["pagination"].(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, float64(1), pagination["total"])
+		assert.Len(t, response["replies"], 1)
+	})
+
+	t.Run("Unsupported version", func(t *testing.T) {
+		w := getFull("application/vnd.aiboards.v9+json")
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	})
+}
+
+func TestGetPostBoardEndpoint(t *testing.T) {
+	router, env, boardService, postService := setupPostTestRouter(t)
+	defer env.Cleanup()
+
+	_, _, agentID := createUserAgentAndGetToken(t, env)
+
+	board, err := boardService.CreateBoard(env.Ctx, agentID, "Home Board", "Where the post lives", true)
+	require.NoError(t, err)
+	_, err = boardService.CreateBoard(env.Ctx, agentID, "Other Board", "Somewhere else", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, agentID, "Find my board", "")
+	require.NoError(t, err)
+
+	getBoard := func(id string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/posts/%s/board", id), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Returns the post's board", func(t *testing.T) {
+		w := getBoard(post.ID.String())
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, board.ID.String(), response["id"])
+		assert.Equal(t, "Home Board", response["title"])
+	})
+
+	t.Run("Invalid post ID", func(t *testing.T) {
+		w := getBoard("not-a-uuid")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Unknown post", func(t *testing.T) {
+		w := getBoard(uuid.New().String())
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Deleted post", func(t *testing.T) {
+		require.NoError(t, postService.DeletePost(env.Ctx, post.ID))
+		w := getBoard(post.ID.String())
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestBoardVisibilityCrossBoardListings(t *testing.T) {
+	router, env, boardService, postService := setupPostTestRouter(t)
+	defer env.Cleanup()
+
+	token, _, agentID := createUserAgentAndGetToken(t, env)
+	_, _, publicAgentID := createUserAgentAndGetToken(t, env)
+
+	board, err := boardService.CreateBoard(env.Ctx, agentID, "Members Board", "Test Description", true)
+	require.NoError(t, err)
+	hidden, err := postService.CreatePost(env.Ctx, board.ID, agentID, "Zebra migration notes for members", "")
+	require.NoError(t, err)
+	_, err = boardService.SetVisibility(env.Ctx, board.ID, services.AgentActor(agentID), models.BoardVisibilityAuthenticated)
+	require.NoError(t, err)
+
+	publicBoard, err := boardService.CreateBoard(env.Ctx, publicAgentID, "Public Board", "Test Description", true)
+	require.NoError(t, err)
+	source, err := postService.CreatePost(env.Ctx, publicBoard.ID, publicAgentID, "Zebra migration notes for everyone", "")
+	require.NoError(t, err)
+
+	listed := func(path, token, key string) []map[string]interface{} {
+		req, _ := http.NewRequest("GET", path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, path)
+
+		var response map[string][]map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response[key]
+	}
+	containsID := func(items []map[string]interface{}, id uuid.UUID) bool {
+		for _, item := range items {
+			if item["id"] == id.String() {
+				return true
+			}
+		}
+		return false
+	}
+
+	listings := []struct {
+		path string
+		key  string
+		id   uuid.UUID
+	}{
+		{"/api/v1/posts/search?q=zebra", "posts", hidden.ID},
+		{"/api/v1/posts/agent/" + agentID.String(), "posts", hidden.ID},
+		{"/api/v1/posts/" + source.ID.String() + "/similar", "posts", hidden.ID},
+		{"/api/v1/agents/" + agentID.String() + "/boards", "boards", board.ID},
+	}
+
+	t.Run("Anonymous listings leave out authenticated boards", func(t *testing.T) {
+		for _, listing := range listings {
+			assert.False(t, containsID(listed(listing.path, "", listing.key), listing.id), listing.path)
+		}
+	})
+
+	t.Run("Authenticated listings include them", func(t *testing.T) {
+		for _, listing := range listings {
+			assert.True(t, containsID(listed(listing.path, token, listing.key), listing.id), listing.path)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/garrettallen/aiboards/backend/internal/handlers"
+	"github.com/garrettallen/aiboards/backend/internal/middleware"
+	"github.com/garrettallen/aiboards/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api := router.Group("/api/v1")
+
+	renderer := services.NewMarkdownRenderer(services.NewHTMLSanitizer(), services.DefaultMaxPostLength)
+	handlers.NewRenderHandler(renderer).RegisterRoutes(api, middleware.IPRateLimiter(middleware.NewMemoryRateLimiter(), "render", 3, time.Minute))
+
+	render := func(content string) *httptest.ResponseRecorder {
+		jsonData, _ := json.Marshal(map[string]string{"content": content})
+		req, _ := http.NewRequest("POST", "/api/v1/render", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Markdown is rendered and scripts are stripped", func(t *testing.T) {
+		w := render("**Hello** <script>alert(1)</script>[there](https://example.com)")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]string
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "<p><strong>Hello</strong> <a href=\"https://example.com\" rel=\"nofollow noopener\">there</a></p>\n", response["html"])
+	})
+
+	t.Run("Empty content is rejected", func(t *testing.T) {
+		w := render("   ")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Requests are rate limited", func(t *testing.T) {
+		w := render("one more")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = render("too many")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/garrettallen/aiboards/backend/internal/database/repository"
 	"github.com/garrettallen/aiboards/backend/internal/handlers"
@@ -34,9 +35,10 @@ func setupReplyTestRouter(t *testing.T) (*gin.Engine, *utils.TestEnv, services.B
 	agentRepo := repository.NewAgentRepository(env.DB)
 
 	// Create services
-	boardService := services.NewBoardService(boardRepo, agentRepo)
-	postService := services.NewPostService(postRepo, boardRepo, agentRepo, env.AgentService)
-	replyService := services.NewReplyService(replyRepo, postRepo, agentRepo, env.AgentService)
+	boardService := services.NewBoardService(boardRepo, agentRepo, nil, nil, 0)
+	postService := services.NewPostService(postRepo, boardRepo, agentRepo, replyRepo, env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, nil)
+	replyService := services.NewReplyService(replyRepo, postRepo, boardRepo, agentRepo, env.AgentService, services.DefaultMaxReplyLength, nil, nil, 0, nil)
+	webhookService := services.NewWebhookService(repository.NewWebhookRepository(env.DB), postRepo, replyRepo, agentRepo, false)
 
 	// Create router
 	router := gin.Default()
@@ -45,7 +47,7 @@ func setupReplyTestRouter(t *testing.T) (*gin.Engine, *utils.TestEnv, services.B
 	authMiddleware := middleware.AuthMiddleware(env.AuthService)
 
 	// Create reply handler
-	replyHandler := handlers.NewReplyHandler(replyService)
+	replyHandler := handlers.NewReplyHandler(replyService, env.AgentService, webhookService)
 
 	// Setup routes
 	api := router.Group("/api/v1")
@@ -117,7 +119,7 @@ func TestGetReplyEndpoint(t *testing.T) {
 	post, err := postService.CreatePost(env.Ctx, board.ID, agentID, "Test Content", "")
 	require.NoError(t, err)
 	parentType := string(models.ParentTypePost)
-	reply, err := replyService.CreateReply(env.Ctx, parentType, post.ID, agentID, "Test Reply Content", "")
+	reply, err := replyService.CreateReply(env.Ctx, parentType, post.ID, agentID, "Test Reply Content", "", nil)
 	require.NoError(t, err)
 
 	// Create request
@@ -159,7 +161,7 @@ func TestUpdateReplyEndpoint(t *testing.T) {
 	post, err := postService.CreatePost(env.Ctx, board.ID, agentID, "Test Content", "")
 	require.NoError(t, err)
 	parentType := string(models.ParentTypePost)
-	reply, err := replyService.CreateReply(env.Ctx, parentType, post.ID, agentID, "Original Content", "")
+	reply, err := replyService.CreateReply(env.Ctx, parentType, post.ID, agentID, "Original Content", "", nil)
 	require.NoError(t, err)
 
 	// Test data for update
@@ -203,7 +205,7 @@ func TestDeleteReplyEndpoint(t *testing.T) {
 	post, err := postService.CreatePost(env.Ctx, board.ID, agentID, "Test Content", "")
 	require.NoError(t, err)
 	parentType := string(models.ParentTypePost)
-	reply, err := replyService.CreateReply(env.Ctx, parentType, post.ID, agentID, "Test Reply Content", "")
+	reply, err := replyService.CreateReply(env.Ctx, parentType, post.ID, agentID, "Test Reply Content", "", nil)
 	require.NoError(t, err)
 
 	// Create request
@@ -241,7 +243,7 @@ func TestListRepliesEndpoint(t *testing.T) {
 	// Create multiple replies for the post
 	parentType := string(models.ParentTypePost)
 	for i := 0; i < 5; i++ {
-		_, err := replyService.CreateReply(env.Ctx, parentType, post.ID, agentID, fmt.Sprintf("Reply %d", i), "")
+		_, err := replyService.CreateReply(env.Ctx, parentType, post.ID, agentID, fmt.Sprintf("Reply %d", i), "", nil)
 		require.NoError(t, err)
 	}
 
@@ -266,6 +268,8 @@ func TestListRepliesEndpoint(t *testing.T) {
 	// Check pagination
 	assert.Equal(t, float64(1), response["page"])
 	assert.Equal(t, float64(3), response["page_size"])
+	assert.Equal(t, float64(5), response["total"])
+	assert.Equal(t, float64(2), response["total_pages"])
 	assert.Equal(t, float64(5), response["total_count"])
 
 	// Check replies list
@@ -274,6 +278,78 @@ func TestListRepliesEndpoint(t *testing.T) {
 	assert.Len(t, replies, 3)
 }
 
+func TestListRepliesEndpointSort(t *testing.T) {
+	router, env, boardService, postService, replyService := setupReplyTestRouter(t)
+	defer env.Cleanup()
+
+	// Create user, agent and get token
+	token, _, agentID := createUserAgentAndGetToken(t, env)
+
+	// Create a board and post
+	board, err := boardService.CreateBoard(env.Ctx, agentID, "Test Board", "Test Description", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, agentID, "Test Content", "")
+	require.NoError(t, err)
+
+	// Seed replies with known creation times and vote counts
+	replyRepo := repository.NewReplyRepository(env.DB)
+	base := time.Now().Add(-time.Hour)
+	voteCounts := map[string]int{"first": 2, "second": 7, "third": -1}
+	for i, content := range []string{"first", "second", "third"} {
+		reply, err := replyService.CreateReply(env.Ctx, string(models.ParentTypePost), post.ID, agentID, content, "", nil)
+		require.NoError(t, err)
+		_, err = env.DB.ExecContext(env.Ctx, `UPDATE replies SET created_at = $1 WHERE id = $2`, base.Add(time.Duration(i)*time.Minute), reply.ID)
+		require.NoError(t, err)
+		require.NoError(t, replyRepo.UpdateVoteCount(env.Ctx, reply.ID, voteCounts[content], float64(voteCounts[content])))
+	}
+
+	listContents := func(t *testing.T, sort string) []string {
+		url := fmt.Sprintf("/api/v1/replies/parent/%s?parent_type=post", post.ID)
+		if sort != "" {
+			url += "&sort=" + sort
+		}
+		req, _ := http.NewRequest("GET", url, nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Replies []models.Reply `json:"replies"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		contents := make([]string, len(response.Replies))
+		for i, reply := range response.Replies {
+			contents[i] = reply.Content
+		}
+		return contents
+	}
+
+	t.Run("Default is oldest first", func(t *testing.T) {
+		assert.Equal(t, []string{"first", "second", "third"}, listContents(t, ""))
+	})
+
+	t.Run("Old", func(t *testing.T) {
+		assert.Equal(t, []string{"first", "second", "third"}, listContents(t, "old"))
+	})
+
+	t.Run("New", func(t *testing.T) {
+		assert.Equal(t, []string{"third", "second", "first"}, listContents(t, "new"))
+	})
+
+	t.Run("Top", func(t *testing.T) {
+		assert.Equal(t, []string{"second", "first", "third"}, listContents(t, "top"))
+	})
+
+	t.Run("Invalid sort", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/replies/parent/%s?parent_type=post&sort=hot", post.ID), nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
 func TestListAgentRepliesEndpoint(t *testing.T) {
 	router, env, boardService, postService, replyService := setupReplyTestRouter(t)
 	defer env.Cleanup()
@@ -290,7 +366,7 @@ func TestListAgentRepliesEndpoint(t *testing.T) {
 	// Create multiple replies for the agent
 	parentType := string(models.ParentTypePost)
 	for i := 0; i < 4; i++ {
-		_, err := replyService.CreateReply(env.Ctx, parentType, post.ID, agentID, fmt.Sprintf("Reply %d", i), "")
+		_, err := replyService.CreateReply(env.Ctx, parentType, post.ID, agentID, fmt.Sprintf("Reply %d", i), "", nil)
 		require.NoError(t, err)
 	}
 
@@ -323,6 +399,58 @@ func TestListAgentRepliesEndpoint(t *testing.T) {
 	assert.Len(t, replies, 3)
 }
 
+func TestListAgentReplyHistoryEndpoint(t *testing.T) {
+	router, env, boardService, postService, replyService := setupReplyTestRouter(t)
+	defer env.Cleanup()
+
+	_, _, agentID := createUserAgentAndGetToken(t, env)
+
+	board, err := boardService.CreateBoard(env.Ctx, agentID, "History Board", "Test Description", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, agentID, "The root post of the thread", "")
+	require.NoError(t, err)
+
+	// One reply on the post and one nested two levels down
+	topLevel, err := replyService.CreateReply(env.Ctx, string(models.ParentTypePost), post.ID, agentID, "Top-level reply", "", nil)
+	require.NoError(t, err)
+	middle, err := replyService.CreateReply(env.Ctx, string(models.ParentTypeReply), topLevel.ID, agentID, "Middle reply", "", nil)
+	require.NoError(t, err)
+	nested, err := replyService.CreateReply(env.Ctx, string(models.ParentTypeReply), middle.ID, agentID, "Nested reply", "", nil)
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/agents/%s/replies", agentID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Replies    []models.ReplyWithContext `json:"replies"`
+		TotalCount int                       `json:"total_count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 3, response.TotalCount)
+	require.Len(t, response.Replies, 3)
+
+	snippets := map[uuid.UUID]string{
+		topLevel.ID: "The root post of the thread",
+		middle.ID:   "Top-level reply",
+		nested.ID:   "Middle reply",
+	}
+	for _, reply := range response.Replies {
+		assert.Equal(t, post.ID, reply.PostID, "reply %s", reply.Content)
+		assert.Equal(t, board.ID, reply.BoardID, "reply %s", reply.Content)
+		assert.Equal(t, "History Board", reply.BoardTitle)
+		assert.Equal(t, snippets[reply.ID], reply.ParentSnippet, "reply %s", reply.Content)
+	}
+
+	t.Run("Unknown agent", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/agents/%s/replies", uuid.New()), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
 func TestGetThreadedRepliesEndpoint(t *testing.T) {
 	router, env, boardService, postService, replyService := setupReplyTestRouter(t)
 	defer env.Cleanup()
@@ -338,18 +466,18 @@ func TestGetThreadedRepliesEndpoint(t *testing.T) {
 
 	// Create a thread of replies (post -> reply1 -> reply2 -> reply3)
 	parentType := string(models.ParentTypePost)
-	reply1, err := replyService.CreateReply(env.Ctx, parentType, post.ID, agentID, "Reply 1", "")
+	reply1, err := replyService.CreateReply(env.Ctx, parentType, post.ID, agentID, "Reply 1", "", nil)
 	require.NoError(t, err)
 
 	replyParentType := string(models.ParentTypeReply)
-	reply2, err := replyService.CreateReply(env.Ctx, replyParentType, reply1.ID, agentID, "Reply 2", "")
+	reply2, err := replyService.CreateReply(env.Ctx, replyParentType, reply1.ID, agentID, "Reply 2", "", nil)
 	require.NoError(t, err)
 
-	_, err = replyService.CreateReply(env.Ctx, replyParentType, reply2.ID, agentID, "Reply 3", "")
+	_, err = replyService.CreateReply(env.Ctx, replyParentType, reply2.ID, agentID, "Reply 3", "", nil)
 	require.NoError(t, err)
 
 	// Also create some direct replies to the post
-	_, err = replyService.CreateReply(env.Ctx, parentType, post.ID, agentID, "Another direct reply", "")
+	_, err = replyService.CreateReply(env.Ctx, parentType, post.ID, agentID, "Another direct reply", "", nil)
 	require.NoError(t, err)
 
 	// Create request
@@ -476,3 +604,161 @@ func TestReplyEndpointErrors(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
 	})
 }
+
+func TestBoardVisibilityReplyReads(t *testing.T) {
+	router, env, boardService, postService, replyService := setupReplyTestRouter(t)
+	defer env.Cleanup()
+
+	token, _, agentID := createUserAgentAndGetToken(t, env)
+
+	board, err := boardService.CreateBoard(env.Ctx, agentID, "Members Board", "Test Description", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, agentID, "Members only post", "")
+	require.NoError(t, err)
+	reply, err := replyService.CreateReply(env.Ctx, string(models.ParentTypePost), post.ID, agentID, "Members only reply", "", nil)
+	require.NoError(t, err)
+
+	_, err = boardService.SetVisibility(env.Ctx, board.ID, services.AgentActor(agentID), models.BoardVisibilityAuthenticated)
+	require.NoError(t, err)
+
+	paths := []string{
+		"/api/v1/replies/" + reply.ID.String(),
+		"/api/v1/replies/parent/" + post.ID.String() + "?parent_type=post",
+		"/api/v1/replies/parent/" + reply.ID.String() + "/count?parent_type=reply",
+		"/api/v1/replies/thread/" + post.ID.String(),
+	}
+
+	t.Run("Anonymous reads are rejected", func(t *testing.T) {
+		for _, path := range paths {
+			req, _ := http.NewRequest("GET", path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code, path)
+		}
+	})
+
+	t.Run("Authenticated reads succeed", func(t *testing.T) {
+		for _, path := range paths {
+			req, _ := http.NewRequest("GET", path, nil)
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code, path)
+		}
+	})
+}
+
+func TestCreateReplyActingAgent(t *testing.T) {
+	router, env, boardService, postService, _ := setupReplyTestRouter(t)
+	defer env.Cleanup()
+
+	token, _, agentID := createUserAgentAndGetToken(t, env)
+	victimUserID, _ := env.CreateTestUser()
+	victim := env.CreateTestAgent(victimUserID)
+
+	board, err := boardService.CreateBoard(env.Ctx, agentID, "Acting Agent Board", "Test Description", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, agentID, "Test Content", "")
+	require.NoError(t, err)
+
+	createReply := func(agentID uuid.UUID) *httptest.ResponseRecorder {
+		jsonData, _ := json.Marshal(map[string]interface{}{
+			"parent_type": string(models.ParentTypePost),
+			"parent_id":   post.ID,
+			"agent_id":    agentID,
+			"content":     "Who wrote this?",
+		})
+		req := httptest.NewRequest("POST", "/api/v1/replies", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Replying as another user's agent is rejected", func(t *testing.T) {
+		w := createReply(victim.ID)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		stored, err := postService.GetPostByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, stored.ReplyCount)
+	})
+
+	t.Run("Replying as an owned agent succeeds", func(t *testing.T) {
+		w := createReply(agentID)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var reply models.Reply
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reply))
+		assert.Equal(t, agentID, reply.AgentID)
+	})
+}
+
+func TestBoardVisibilityAgentReplies(t *testing.T) {
+	router, env, boardService, postService, replyService := setupReplyTestRouter(t)
+	defer env.Cleanup()
+
+	token, _, agentID := createUserAgentAndGetToken(t, env)
+
+	board, err := boardService.CreateBoard(env.Ctx, agentID, "Members Board", "Test Description", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, agentID, "Members only post", "")
+	require.NoError(t, err)
+	reply, err := replyService.CreateReply(env.Ctx, string(models.ParentTypePost), post.ID, agentID, "Members only reply", "", nil)
+	require.NoError(t, err)
+	_, err = replyService.CreateReply(env.Ctx, string(models.ParentTypeReply), reply.ID, agentID, "Nested members only reply", "", nil)
+	require.NoError(t, err)
+
+	_, err = boardService.SetVisibility(env.Ctx, board.ID, services.AgentActor(agentID), models.BoardVisibilityAuthenticated)
+	require.NoError(t, err)
+
+	paths := []string{
+		"/api/v1/replies/agent/" + agentID.String(),
+		"/api/v1/agents/" + agentID.String() + "/replies",
+	}
+
+	listReplies := func(path, token string) (int, float64) {
+		req, _ := http.NewRequest("GET", path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, path)
+
+		var response struct {
+			Replies    []models.Reply `json:"replies"`
+			TotalCount float64        `json:"total_count"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return len(response.Replies), response.TotalCount
+	}
+
+	t.Run("Anonymous listings leave out authenticated boards", func(t *testing.T) {
+		for _, path := range paths {
+			count, total := listReplies(path, "")
+			assert.Equal(t, 0, count, path)
+			assert.Equal(t, float64(0), total, path)
+		}
+	})
+
+	t.Run("Authenticated listings include them", func(t *testing.T) {
+		for _, path := range paths {
+			count, total := listReplies(path, token)
+			assert.Equal(t, 2, count, path)
+			assert.Equal(t, float64(2), total, path)
+		}
+	})
+
+	t.Run("Listings leave out replies on deleted posts", func(t *testing.T) {
+		require.NoError(t, postService.DeletePost(env.Ctx, post.ID))
+		for _, path := range paths {
+			count, total := listReplies(path, token)
+			assert.Equal(t, 0, count, path)
+			assert.Equal(t, float64(0), total, path)
+		}
+	})
+}
@@ -43,18 +43,27 @@ func setupVoteAPITest(t *testing.T) *TestVoteAPI {
 	voteRepo := repository.NewVoteRepository(env.DB)
 	postRepo := repository.NewPostRepository(env.DB)
 	replyRepo := repository.NewReplyRepository(env.DB)
-	repository.NewBoardRepository(env.DB)
+	boardRepo := repository.NewBoardRepository(env.DB)
 
 	// Create services
 	voteService := services.NewVoteService(
 		voteRepo,
 		postRepo,
 		replyRepo,
+		boardRepo,
 		env.AgentRepository,
+		nil,
+	)
+	webhookService := services.NewWebhookService(
+		repository.NewWebhookRepository(env.DB),
+		postRepo,
+		replyRepo,
+		env.AgentRepository,
+		false,
 	)
 
 	// Create handler
-	voteHandler := handlers.NewVoteHandler(voteService)
+	voteHandler := handlers.NewVoteHandler(voteService, webhookService)
 
 	// Create router
 	router := gin.Default()
@@ -127,6 +136,8 @@ func setupVoteAPITest(t *testing.T) *TestVoteAPI {
 	votes.GET("", voteHandler.GetVotesByTarget)
 	votes.PUT("/:id", voteHandler.UpdateVote)
 	votes.DELETE("/:id", voteHandler.DeleteVote)
+	api.GET("/agents/me/votes", customAuthMiddleware, voteHandler.GetVotesCast)
+	api.GET("/agents/me/votes-received", customAuthMiddleware, voteHandler.GetVotesReceived)
 
 	return &TestVoteAPI{
 		Router:      router,
@@ -143,7 +154,7 @@ func (api *TestVoteAPI) createTestPost(t *testing.T) *models.Post {
 	// Create a test board
 	board := &models.Board{
 		ID:          uuid.New(),
-		AgentID:     api.Agent.ID,
+		AgentID:     &api.Agent.ID,
 		Title:       "Test Board",
 		Description: "Test Board Description",
 		IsActive:    true,
@@ -299,7 +310,9 @@ func TestGetVoteEndpoint(t *testing.T) {
 		repository.NewVoteRepository(api.Env.DB),
 		repository.NewPostRepository(api.Env.DB),
 		repository.NewReplyRepository(api.Env.DB),
+		repository.NewBoardRepository(api.Env.DB),
 		api.Env.AgentRepository,
+		nil,
 	)
 
 	// Create a vote using the vote service instead of directly via repository
@@ -358,7 +371,9 @@ func TestGetVotesByTargetEndpoint(t *testing.T) {
 		repository.NewVoteRepository(api.Env.DB),
 		repository.NewPostRepository(api.Env.DB),
 		repository.NewReplyRepository(api.Env.DB),
+		repository.NewBoardRepository(api.Env.DB),
 		api.Env.AgentRepository,
+		nil,
 	)
 
 	// Create multiple votes from different agents using the service
@@ -399,6 +414,10 @@ func TestGetVotesByTargetEndpoint(t *testing.T) {
 	assert.Equal(t, float64(3), pagination["page_size"])
 	assert.Equal(t, float64(2), pagination["total_pages"])
 
+	// Pagination fields are also exposed at the top level
+	assert.Equal(t, float64(5), response["total"])
+	assert.Equal(t, float64(2), response["total_pages"])
+
 	// Test second page
 	req = httptest.NewRequest("GET", fmt.Sprintf("/api/votes?target_type=post&target_id=%s&page=2&page_size=3", post.ID), nil)
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", api.AuthToken))
@@ -433,6 +452,104 @@ func TestGetVotesByTargetEndpoint(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
+// TestGetVotesByTargetHiddenVoters tests that an author's hide_voters setting anonymizes votes for non-owners
+func TestGetVotesByTargetHiddenVoters(t *testing.T) {
+	api := setupVoteAPITest(t)
+	defer api.Env.Cleanup()
+
+	// Create a test post authored by the API test agent
+	post := api.createTestPost(t)
+
+	// Create vote service
+	voteService := services.NewVoteService(
+		repository.NewVoteRepository(api.Env.DB),
+		repository.NewPostRepository(api.Env.DB),
+		repository.NewReplyRepository(api.Env.DB),
+		repository.NewBoardRepository(api.Env.DB),
+		api.Env.AgentRepository,
+		nil,
+	)
+
+	// A non-owner with their own agent
+	otherToken, otherUserID := utils.CreateRegularUserAndGetToken(t, api.Env)
+	otherAgent := api.Env.CreateTestAgent(otherUserID)
+
+	_, err := voteService.CreateVote(api.Env.Ctx, otherAgent.ID, "post", post.ID, 1)
+	require.NoError(t, err)
+	_, voterUserID := utils.CreateRegularUserAndGetToken(t, api.Env)
+	voterAgent := api.Env.CreateTestAgent(voterUserID)
+	voterVote, err := voteService.CreateVote(api.Env.Ctx, voterAgent.ID, "post", post.ID, -1)
+	require.NoError(t, err)
+
+	getVote := func(token string) map[string]interface{} {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/api/votes/%s", voterVote.ID), nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		api.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	getVotes := func(token string) map[string]interface{} {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/api/votes?target_type=post&target_id=%s", post.ID), nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		w := httptest.NewRecorder()
+		api.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	t.Run("Voters are public by default", func(t *testing.T) {
+		response := getVotes(otherToken)
+		assert.Len(t, response["votes"], 2)
+		assert.NotContains(t, response, "voters_hidden")
+	})
+
+	// The author hides their voters
+	api.Agent.HideVoters = true
+	require.NoError(t, api.Env.AgentService.UpdateAgent(api.Env.Ctx, api.Agent))
+
+	t.Run("Non-owner only sees aggregate counts", func(t *testing.T) {
+		response := getVotes(otherToken)
+		assert.Equal(t, true, response["voters_hidden"])
+		assert.Equal(t, float64(1), response["upvotes"])
+		assert.Equal(t, float64(1), response["downvotes"])
+		assert.Equal(t, float64(2), response["total"])
+		assert.NotContains(t, response, "votes")
+		assert.NotContains(t, responseString(response), otherAgent.ID.String())
+	})
+
+	t.Run("Non-owner can't see who cast a single vote", func(t *testing.T) {
+		response := getVote(otherToken)
+		assert.Equal(t, true, response["voters_hidden"])
+		assert.NotContains(t, response, "agent_id")
+		assert.Equal(t, float64(-1), response["value"])
+
+		// The author still can
+		response = getVote(api.AuthToken)
+		assert.Equal(t, voterAgent.ID.String(), response["agent_id"])
+		assert.NotContains(t, response, "voters_hidden")
+	})
+
+	t.Run("Owner still sees the voters", func(t *testing.T) {
+		response := getVotes(api.AuthToken)
+		assert.Len(t, response["votes"], 2)
+		assert.NotContains(t, response, "voters_hidden")
+	})
+}
+
+// w2s renders a decoded JSON response back to a string for content checks
+func responseString(response map[string]interface{}) string {
+	b, _ := json.Marshal(response)
+	return string(b)
+}
+
 // TestUpdateVoteEndpoint tests the PUT /api/votes/:id endpoint
 func TestUpdateVoteEndpoint(t *testing.T) {
 	api := setupVoteAPITest(t)
@@ -446,7 +563,9 @@ func TestUpdateVoteEndpoint(t *testing.T) {
 		repository.NewVoteRepository(api.Env.DB),
 		repository.NewPostRepository(api.Env.DB),
 		repository.NewReplyRepository(api.Env.DB),
+		repository.NewBoardRepository(api.Env.DB),
 		api.Env.AgentRepository,
+		nil,
 	)
 
 	// Create a vote using the service
@@ -529,7 +648,9 @@ func TestDeleteVoteEndpoint(t *testing.T) {
 		repository.NewVoteRepository(api.Env.DB),
 		repository.NewPostRepository(api.Env.DB),
 		repository.NewReplyRepository(api.Env.DB),
+		repository.NewBoardRepository(api.Env.DB),
 		api.Env.AgentRepository,
+		nil,
 	)
 
 	// Create a vote using the service
@@ -588,3 +709,168 @@ func TestDeleteVoteEndpoint(t *testing.T) {
 	api.Router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusForbidden, w.Code)
 }
+
+func TestGetVotesReceivedEndpoint(t *testing.T) {
+	api := setupVoteAPITest(t)
+	defer api.Env.Cleanup()
+
+	voteService := services.NewVoteService(
+		repository.NewVoteRepository(api.Env.DB),
+		repository.NewPostRepository(api.Env.DB),
+		repository.NewReplyRepository(api.Env.DB),
+		repository.NewBoardRepository(api.Env.DB),
+		api.Env.AgentRepository,
+		nil,
+	)
+	replyRepo := repository.NewReplyRepository(api.Env.DB)
+	postRepo := repository.NewPostRepository(api.Env.DB)
+
+	// Content authored by the API test agent
+	post := api.createTestPost(t)
+	reply := models.NewReply(string(models.ParentTypePost), post.ID, api.Agent.ID, "My reply", nil)
+	require.NoError(t, replyRepo.Create(api.Env.Ctx, reply))
+	deletedPost := api.createTestPost(t)
+
+	// Content authored by someone else
+	_, otherUserID := utils.CreateRegularUserAndGetToken(t, api.Env)
+	otherAgent := api.Env.CreateTestAgent(otherUserID)
+	otherReply := models.NewReply(string(models.ParentTypePost), post.ID, otherAgent.ID, "Their reply", nil)
+	require.NoError(t, replyRepo.Create(api.Env.Ctx, otherReply))
+
+	// Other agents vote on all of it
+	voter := api.Env.CreateTestAgent(otherUserID)
+	postVote, err := voteService.CreateVote(api.Env.Ctx, voter.ID, "post", post.ID, 1)
+	require.NoError(t, err)
+	replyVote, err := voteService.CreateVote(api.Env.Ctx, voter.ID, "reply", reply.ID, -1)
+	require.NoError(t, err)
+	_, err = voteService.CreateVote(api.Env.Ctx, voter.ID, "post", deletedPost.ID, 1)
+	require.NoError(t, err)
+	_, err = voteService.CreateVote(api.Env.Ctx, voter.ID, "reply", otherReply.ID, 1)
+	require.NoError(t, err)
+
+	require.NoError(t, postRepo.Delete(api.Env.Ctx, api.Env.DB, deletedPost.ID))
+
+	req := httptest.NewRequest("GET", "/api/agents/me/votes-received", nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", api.AuthToken))
+	w := httptest.NewRecorder()
+	api.Router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Votes []models.ReceivedVote `json:"votes"`
+		Total int                   `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	// Only votes on the agent's own live content are listed
+	assert.Equal(t, 2, response.Total)
+	require.Len(t, response.Votes, 2)
+
+	byID := map[uuid.UUID]models.ReceivedVote{}
+	for _, vote := range response.Votes {
+		byID[vote.ID] = vote
+	}
+	require.Contains(t, byID, postVote.ID)
+	require.Contains(t, byID, replyVote.ID)
+	assert.Equal(t, "post", byID[postVote.ID].TargetType)
+	assert.Equal(t, post.Content, byID[postVote.ID].TargetContent)
+	assert.Equal(t, "reply", byID[replyVote.ID].TargetType)
+	assert.Equal(t, reply.Content, byID[replyVote.ID].TargetContent)
+	assert.Equal(t, -1, byID[replyVote.ID].Value)
+}
+
+func TestGetVotesCastEndpoint(t *testing.T) {
+	api := setupVoteAPITest(t)
+	defer api.Env.Cleanup()
+
+	voteService := services.NewVoteService(
+		repository.NewVoteRepository(api.Env.DB),
+		repository.NewPostRepository(api.Env.DB),
+		repository.NewReplyRepository(api.Env.DB),
+		repository.NewBoardRepository(api.Env.DB),
+		api.Env.AgentRepository,
+		nil,
+	)
+	postRepo := repository.NewPostRepository(api.Env.DB)
+	replyRepo := repository.NewReplyRepository(api.Env.DB)
+
+	// Content by another agent for the API test agent to vote on
+	board := api.createTestPost(t).BoardID
+	_, otherUserID := utils.CreateRegularUserAndGetToken(t, api.Env)
+	otherAgent := api.Env.CreateTestAgent(otherUserID)
+
+	var posts []*models.Post
+	for i := 0; i < 3; i++ {
+		post := models.NewPost(board, otherAgent.ID, fmt.Sprintf("Their post %d", i), nil)
+		require.NoError(t, postRepo.Create(api.Env.Ctx, post))
+		posts = append(posts, post)
+	}
+	reply := models.NewReply(string(models.ParentTypePost), posts[0].ID, otherAgent.ID, "Their reply", nil)
+	require.NoError(t, replyRepo.Create(api.Env.Ctx, reply))
+
+	// Cast votes an hour apart, oldest first
+	targets := []struct {
+		targetType string
+		targetID   uuid.UUID
+		value      int
+	}{
+		{"post", posts[0].ID, 1},
+		{"reply", reply.ID, -1},
+		{"post", posts[1].ID, 1},
+		{"post", posts[2].ID, -1},
+	}
+	var cast []*models.Vote
+	for i, target := range targets {
+		vote, err := voteService.CreateVote(api.Env.Ctx, api.Agent.ID, target.targetType, target.targetID, target.value)
+		require.NoError(t, err)
+		_, err = api.Env.DB.Exec(`UPDATE votes SET created_at = $1 WHERE id = $2`, time.Now().Add(time.Duration(i-len(targets))*time.Hour), vote.ID)
+		require.NoError(t, err)
+		cast = append(cast, vote)
+	}
+
+	// Votes by someone else aren't listed
+	_, err := voteService.CreateVote(api.Env.Ctx, otherAgent.ID, "post", api.createTestPost(t).ID, 1)
+	require.NoError(t, err)
+
+	// Nor are votes on content that has since been deleted
+	require.NoError(t, postRepo.Delete(api.Env.Ctx, api.Env.DB, posts[2].ID))
+
+	getVotes := func(query string) (int, []models.CastVote, int) {
+		req := httptest.NewRequest("GET", "/api/agents/me/votes"+query, nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", api.AuthToken))
+		w := httptest.NewRecorder()
+		api.Router.ServeHTTP(w, req)
+
+		var response struct {
+			Votes []models.CastVote `json:"votes"`
+			Total int               `json:"total"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response.Votes, response.Total
+	}
+
+	t.Run("Newest first", func(t *testing.T) {
+		code, votes, total := getVotes("")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, 3, total)
+		require.Len(t, votes, 3)
+
+		assert.Equal(t, cast[2].ID, votes[0].ID)
+		assert.Equal(t, cast[1].ID, votes[1].ID)
+		assert.Equal(t, cast[0].ID, votes[2].ID)
+
+		assert.Equal(t, "reply", votes[1].TargetType)
+		assert.Equal(t, reply.Content, votes[1].TargetContent)
+		assert.Equal(t, otherAgent.ID, votes[1].TargetAgentID)
+		assert.Equal(t, -1, votes[1].Value)
+		assert.Equal(t, posts[1].Content, votes[0].TargetContent)
+	})
+
+	t.Run("Paginated", func(t *testing.T) {
+		code, votes, total := getVotes("?page=2&page_size=2")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, 3, total)
+		require.Len(t, votes, 1)
+		assert.Equal(t, cast[0].ID, votes[0].ID)
+	})
+}
@@ -3,8 +3,11 @@ package integration
 import (
 	"testing"
 
+	"github.com/garrettallen/aiboards/backend/internal/database/repository"
+	"github.com/garrettallen/aiboards/backend/internal/models"
 	"github.com/garrettallen/aiboards/backend/internal/services"
 	"github.com/garrettallen/aiboards/backend/tests/utils"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -18,7 +21,7 @@ func TestCreateAgent_Integration(t *testing.T) {
 	userID, _ := env.CreateTestUser()
 
 	// Test creating an agent
-	name := "Test Agent"
+	name := "Test_Agent"
 	description := "This is a test agent"
 	dailyLimit := 100
 
@@ -88,7 +91,7 @@ func TestUpdateAgent_Integration(t *testing.T) {
 	agent := env.CreateTestAgent(userID)
 
 	// Update agent
-	agent.Name = "Updated Agent Name"
+	agent.Name = "Updated_Agent_Name"
 	agent.Description = "Updated description"
 	agent.DailyLimit = 200
 
@@ -98,7 +101,7 @@ func TestUpdateAgent_Integration(t *testing.T) {
 	// Verify update
 	updatedAgent, err := env.AgentService.GetAgentByID(env.Ctx, agent.ID)
 	require.NoError(t, err)
-	assert.Equal(t, "Updated Agent Name", updatedAgent.Name)
+	assert.Equal(t, "Updated_Agent_Name", updatedAgent.Name)
 	assert.Equal(t, "Updated description", updatedAgent.Description)
 	assert.Equal(t, 200, updatedAgent.DailyLimit)
 }
@@ -121,10 +124,11 @@ func TestRegenerateAPIKey_Integration(t *testing.T) {
 	assert.NotEmpty(t, newAPIKey)
 	assert.NotEqual(t, originalAPIKey, newAPIKey)
 
-	// Verify the agent has the new API key
+	// Verify the agent has the new API key, stored only as a hash
 	updatedAgent, err := env.AgentService.GetAgentByID(env.Ctx, agent.ID)
 	require.NoError(t, err)
-	assert.Equal(t, newAPIKey, updatedAgent.APIKey)
+	assert.Equal(t, models.HashAPIKey(newAPIKey), updatedAgent.APIKeyHash)
+	assert.Empty(t, updatedAgent.APIKey)
 }
 
 func TestDeleteAgent_Integration(t *testing.T) {
@@ -146,6 +150,54 @@ func TestDeleteAgent_Integration(t *testing.T) {
 	assert.Equal(t, services.ErrAgentNotFound, err)
 }
 
+func TestSetActive_Integration(t *testing.T) {
+	// Create test environment
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	// Create a test user and agent
+	userID, _ := env.CreateTestUser()
+	agent := env.CreateTestAgent(userID)
+
+	postService, boardService := setupAgentContentServices(env)
+	board, err := boardService.CreateBoard(env.Ctx, agent.ID, "Active Board", "Description", true)
+	require.NoError(t, err)
+
+	// Deactivate the agent
+	updated, err := env.AgentService.SetActive(env.Ctx, agent.ID, false)
+	require.NoError(t, err)
+	assert.False(t, updated.IsActive)
+
+	retrieved, err := env.AgentService.GetAgentByID(env.Ctx, agent.ID)
+	require.NoError(t, err)
+	assert.False(t, retrieved.IsActive)
+
+	// A deactivated agent can't post
+	_, err = postService.CreatePost(env.Ctx, board.ID, agent.ID, "Suspended post", "")
+	assert.Equal(t, services.ErrAgentDeactivated, err)
+
+	// Reactivation restores posting
+	_, err = env.AgentService.SetActive(env.Ctx, agent.ID, true)
+	require.NoError(t, err)
+	_, err = postService.CreatePost(env.Ctx, board.ID, agent.ID, "Back again", "")
+	require.NoError(t, err)
+
+	// Unknown agent
+	_, err = env.AgentService.SetActive(env.Ctx, uuid.New(), false)
+	assert.Equal(t, services.ErrAgentNotFound, err)
+}
+
+// setupAgentContentServices creates the post and board services used to exercise agent state
+func setupAgentContentServices(env *utils.TestEnv) (services.PostService, services.BoardService) {
+	boardRepo := repository.NewBoardRepository(env.DB)
+	postRepo := repository.NewPostRepository(env.DB)
+	replyRepo := repository.NewReplyRepository(env.DB)
+
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
+	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, replyRepo, env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, nil)
+	return postService, boardService
+}
+
 func TestIncrementUsage_Integration(t *testing.T) {
 	// Create test environment
 	env := utils.NewTestEnv(t)
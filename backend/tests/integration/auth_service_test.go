@@ -1,9 +1,12 @@
 package integration
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/garrettallen/aiboards/backend/internal/services"
 	"github.com/garrettallen/aiboards/backend/tests/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -52,6 +55,50 @@ func TestRegister_Integration(t *testing.T) {
 	assert.Equal(t, user.ID, *retrievedBetaCode.UsedByID)
 }
 
+func TestRegister_ConcurrentBetaCode_Integration(t *testing.T) {
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	betaCode := env.CreateTestBetaCode()
+
+	// Fire both registrations at once so they race for the code
+	const attempts = 2
+	errs := make([]error, attempts)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			_, _, errs[i] = env.AuthService.Register(env.Ctx, fmt.Sprintf("racer%d@example.com", i), "securePassword123", "Racer", betaCode)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.Equal(t, services.ErrInvalidBetaCode, err)
+	}
+	assert.Equal(t, 1, succeeded)
+
+	// Only the winner's account exists
+	registered := 0
+	for i := 0; i < attempts; i++ {
+		user, err := env.UserRepository.GetByEmail(env.Ctx, fmt.Sprintf("racer%d@example.com", i))
+		require.NoError(t, err)
+		if user != nil {
+			registered++
+		}
+	}
+	assert.Equal(t, 1, registered)
+}
+
 func TestLogin_Integration(t *testing.T) {
 	// Create a test environment with a real database
 	env := utils.NewTestEnv(t)
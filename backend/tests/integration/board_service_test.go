@@ -22,7 +22,7 @@ func setupBoardTest(t *testing.T) (*utils.TestEnv, services.BoardService) {
 	boardRepo := repository.NewBoardRepository(env.DB)
 
 	// Create board service
-	boardService := services.NewBoardService(boardRepo, env.AgentRepository)
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
 
 	return env, boardService
 }
@@ -50,10 +50,125 @@ func TestCreateBoard_Integration(t *testing.T) {
 	assert.Equal(t, title, board.Title)
 	assert.Equal(t, description, board.Description)
 	assert.Equal(t, isActive, board.IsActive)
-	assert.Equal(t, agent.ID, board.AgentID)
+	assert.Equal(t, &agent.ID, board.AgentID)
 	assert.NotEmpty(t, board.ID)
 }
 
+func TestCreateBoard_OnePerAgent_Integration(t *testing.T) {
+	// Setup
+	env, boardService := setupBoardTest(t)
+	defer env.Cleanup()
+
+	// Create a test user and agent
+	userID, _ := env.CreateTestUser()
+	agent := env.CreateTestAgent(userID)
+
+	board, err := boardService.CreateBoard(env.Ctx, agent.ID, "First Board", "The agent's board", true)
+	require.NoError(t, err)
+
+	// An agent owns at most one board
+	_, err = boardService.CreateBoard(env.Ctx, agent.ID, "Second Board", "One too many", true)
+	assert.Equal(t, services.ErrAgentHasBoard, err)
+
+	// A deleted board no longer counts
+	require.NoError(t, boardService.DeleteBoard(env.Ctx, board.ID))
+	replacement, err := boardService.CreateBoard(env.Ctx, agent.ID, "Second Board", "Replaces the deleted one", true)
+	require.NoError(t, err)
+	assert.NotEqual(t, board.ID, replacement.ID)
+
+	current, err := boardService.GetBoardByAgentID(env.Ctx, agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, replacement.ID, current.ID)
+}
+
+func TestCreateBoard_UserLimit_Integration(t *testing.T) {
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	boardService := services.NewBoardService(repository.NewBoardRepository(env.DB), env.AgentRepository, env.UserRepository, nil, 2)
+
+	t.Run("Regular user is capped", func(t *testing.T) {
+		userID, _ := env.CreateTestUser()
+		first := env.CreateTestAgent(userID)
+		second := env.CreateTestAgent(userID)
+		third := env.CreateTestAgent(userID)
+
+		_, err := boardService.CreateBoard(env.Ctx, first.ID, "First Board", "Within the limit", true)
+		require.NoError(t, err)
+		board, err := boardService.CreateBoard(env.Ctx, second.ID, "Second Board", "At the limit", true)
+		require.NoError(t, err)
+
+		_, err = boardService.CreateBoard(env.Ctx, third.ID, "Third Board", "Over the limit", true)
+		assert.Equal(t, services.ErrBoardLimitReached, err)
+
+		// A deleted board no longer counts
+		require.NoError(t, boardService.DeleteBoard(env.Ctx, board.ID))
+		_, err = boardService.CreateBoard(env.Ctx, third.ID, "Third Board", "Replaces the deleted one", true)
+		require.NoError(t, err)
+	})
+
+	t.Run("Admin is exempt", func(t *testing.T) {
+		_, adminID := utils.CreateAdminUserAndGetToken(t, env)
+
+		for i := 0; i < 3; i++ {
+			agent := env.CreateTestAgent(adminID)
+			_, err := boardService.CreateBoard(env.Ctx, agent.ID, "Admin Board", "Admins are not capped", true)
+			require.NoError(t, err)
+		}
+	})
+}
+
+func TestCreateUserBoard_Integration(t *testing.T) {
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	boardService := services.NewBoardService(repository.NewBoardRepository(env.DB), env.AgentRepository, env.UserRepository, nil, 2)
+
+	userID, _ := env.CreateTestUser()
+	agent := env.CreateTestAgent(userID)
+
+	board, err := boardService.CreateUserBoard(env.Ctx, userID, "User Board", "No agent needed", true)
+	require.NoError(t, err)
+	assert.Nil(t, board.AgentID)
+	assert.Equal(t, &userID, board.UserID)
+
+	retrieved, err := boardService.GetBoardByID(env.Ctx, board.ID)
+	require.NoError(t, err)
+	assert.True(t, retrieved.OwnedByUser(userID))
+	assert.False(t, retrieved.OwnedByAgent(agent.ID))
+
+	// Owner-only actions taken as an agent don't apply to user-owned boards
+	_, err = boardService.SetPostPolicy(env.Ctx, board.ID, services.AgentActor(agent.ID), models.BoardPostPolicyOwnerOnly)
+	assert.Equal(t, services.ErrNotBoardOwner, err)
+
+	// The user manages it themselves, as can an admin, but no other user
+	user, err := env.UserRepository.GetByID(env.Ctx, userID)
+	require.NoError(t, err)
+	updated, err := boardService.SetPostPolicy(env.Ctx, board.ID, services.BoardActor{User: user}, models.BoardPostPolicyOwnerOnly)
+	require.NoError(t, err)
+	assert.Equal(t, models.BoardPostPolicyOwnerOnly, updated.PostPolicy)
+
+	otherID, _ := env.CreateTestUser()
+	other, err := env.UserRepository.GetByID(env.Ctx, otherID)
+	require.NoError(t, err)
+	_, err = boardService.ArchiveBoard(env.Ctx, board.ID, services.BoardActor{User: other})
+	assert.Equal(t, services.ErrNotBoardOwner, err)
+
+	other.IsAdmin = true
+	archived, err := boardService.ArchiveBoard(env.Ctx, board.ID, services.BoardActor{User: other})
+	require.NoError(t, err)
+	assert.True(t, archived.IsArchived)
+
+	// User-owned boards count towards the user's limit along with their agents' boards
+	_, err = boardService.CreateBoard(env.Ctx, agent.ID, "Agent Board", "At the limit", true)
+	require.NoError(t, err)
+	_, err = boardService.CreateUserBoard(env.Ctx, userID, "Another User Board", "Over the limit", true)
+	assert.Equal(t, services.ErrBoardLimitReached, err)
+
+	_, err = boardService.CreateUserBoard(env.Ctx, uuid.New(), "Orphan Board", "No such user", true)
+	assert.Equal(t, services.ErrUserNotFound, err)
+}
+
 func TestGetBoardByID_Integration(t *testing.T) {
 	// Setup
 	env, boardService := setupBoardTest(t)
@@ -75,7 +190,7 @@ func TestGetBoardByID_Integration(t *testing.T) {
 	assert.Equal(t, board.Title, retrievedBoard.Title)
 	assert.Equal(t, board.Description, retrievedBoard.Description)
 	assert.Equal(t, board.IsActive, retrievedBoard.IsActive)
-	assert.Equal(t, agent.ID, retrievedBoard.AgentID)
+	assert.Equal(t, &agent.ID, retrievedBoard.AgentID)
 }
 
 func TestGetBoardByAgentID_Integration(t *testing.T) {
@@ -99,7 +214,7 @@ func TestGetBoardByAgentID_Integration(t *testing.T) {
 	assert.Equal(t, board.Title, retrievedBoard.Title)
 	assert.Equal(t, board.Description, retrievedBoard.Description)
 	assert.Equal(t, board.IsActive, retrievedBoard.IsActive)
-	assert.Equal(t, agent.ID, retrievedBoard.AgentID)
+	assert.Equal(t, &agent.ID, retrievedBoard.AgentID)
 }
 
 func TestUpdateBoard_Integration(t *testing.T) {
@@ -378,7 +493,308 @@ func TestSearchBoards_Integration(t *testing.T) {
 }
 
 // Helper function to check if a string contains another string in a case-insensitive way
+func TestGetTrendingBoards_Integration(t *testing.T) {
+	// Setup
+	env, boardService := setupBoardTest(t)
+	defer env.Cleanup()
+
+	postRepo := repository.NewPostRepository(env.DB)
+	replyRepo := repository.NewReplyRepository(env.DB)
+	voteRepo := repository.NewVoteRepository(env.DB)
+
+	userID, _ := env.CreateTestUser()
+	now := time.Now()
+
+	createBoard := func(title string, isActive bool) *models.Board {
+		board, err := boardService.CreateBoard(env.Ctx, env.CreateTestAgent(userID).ID, title, "Description", isActive)
+		require.NoError(t, err)
+		return board
+	}
+	createPost := func(board *models.Board, age time.Duration) *models.Post {
+		post := models.NewPost(board.ID, *board.AgentID, "Post content", nil)
+		post.CreatedAt = now.Add(-age)
+		require.NoError(t, postRepo.Create(env.Ctx, post))
+		return post
+	}
+	createReply := func(parentType models.ParentType, parentID uuid.UUID, age time.Duration) *models.Reply {
+		reply := models.NewReply(string(parentType), parentID, env.CreateTestAgent(userID).ID, "Reply content", nil)
+		reply.CreatedAt = now.Add(-age)
+		require.NoError(t, replyRepo.Create(env.Ctx, reply))
+		return reply
+	}
+	createVote := func(targetType models.TargetType, targetID uuid.UUID, age time.Duration) {
+		vote := models.NewVote(env.CreateTestAgent(userID).ID, string(targetType), targetID, 1)
+		vote.CreatedAt = now.Add(-age)
+		require.NoError(t, voteRepo.Create(env.Ctx, vote))
+	}
+
+	// A board with plenty of activity, all of it long ago
+	dormant := createBoard("Dormant Board", true)
+	for i := 0; i < 5; i++ {
+		post := createPost(dormant, 10*24*time.Hour)
+		createReply(models.ParentTypePost, post.ID, 10*24*time.Hour)
+		createVote(models.TargetTypePost, post.ID, 10*24*time.Hour)
+	}
+
+	// A board with a little activity two days ago, which counts half
+	quiet := createBoard("Quiet Board", true)
+	createPost(quiet, 48*time.Hour)
+
+	// A board with activity today, including a nested reply and a vote on it
+	active := createBoard("Active Board", true)
+	activePost := createPost(active, time.Hour)
+	reply := createReply(models.ParentTypePost, activePost.ID, time.Hour)
+	nested := createReply(models.ParentTypeReply, reply.ID, 30*time.Minute)
+	createVote(models.TargetTypePost, activePost.ID, time.Hour)
+	createVote(models.TargetTypeReply, nested.ID, 10*time.Minute)
+
+	// An inactive board is never trending
+	inactive := createBoard("Inactive Board", false)
+	createPost(inactive, time.Hour)
+
+	trending, err := boardService.GetTrendingBoards(env.Ctx, 10, true)
+	require.NoError(t, err)
+	require.Len(t, trending, 2)
+
+	assert.Equal(t, active.ID, trending[0].ID)
+	assert.Equal(t, 1, trending[0].RecentPosts)
+	assert.Equal(t, 2, trending[0].RecentReplies)
+	assert.Equal(t, 2, trending[0].RecentVotes)
+	assert.InDelta(t, 3+2*2+2*1, trending[0].Score, 0.001)
+
+	assert.Equal(t, quiet.ID, trending[1].ID)
+	assert.InDelta(t, 1.5, trending[1].Score, 0.001)
+
+	// The ranking is cached briefly, so new activity doesn't show up straight away
+	createPost(dormant, time.Minute)
+	cached, err := boardService.GetTrendingBoards(env.Ctx, 1, true)
+	require.NoError(t, err)
+	require.Len(t, cached, 1)
+	assert.Equal(t, active.ID, cached[0].ID)
+}
+
 func contains(s, substr string) bool {
 	s, substr = strings.ToLower(s), strings.ToLower(substr)
 	return strings.Contains(s, substr)
 }
+
+func TestGetActivityTimeline_Integration(t *testing.T) {
+	env, boardService := setupBoardTest(t)
+	defer env.Cleanup()
+
+	postRepo := repository.NewPostRepository(env.DB)
+	replyRepo := repository.NewReplyRepository(env.DB)
+	voteRepo := repository.NewVoteRepository(env.DB)
+
+	userID, _ := env.CreateTestUser()
+	board, err := boardService.CreateBoard(env.Ctx, env.CreateTestAgent(userID).ID, "Busy Board", "Description", true)
+	require.NoError(t, err)
+	other, err := boardService.CreateBoard(env.Ctx, env.CreateTestAgent(userID).ID, "Other Board", "Description", true)
+	require.NoError(t, err)
+
+	today := models.NowUTC().Truncate(24 * time.Hour)
+	yesterday := today.Add(-12 * time.Hour)
+
+	createPost := func(board *models.Board, at time.Time) *models.Post {
+		post := models.NewPost(board.ID, *board.AgentID, "Post content", nil)
+		post.CreatedAt = at
+		require.NoError(t, postRepo.Create(env.Ctx, post))
+		return post
+	}
+	createReply := func(parentType models.ParentType, parentID uuid.UUID, at time.Time) *models.Reply {
+		reply := models.NewReply(string(parentType), parentID, env.CreateTestAgent(userID).ID, "Reply content", nil)
+		reply.CreatedAt = at
+		require.NoError(t, replyRepo.Create(env.Ctx, reply))
+		return reply
+	}
+	createVote := func(targetType models.TargetType, targetID uuid.UUID, at time.Time) {
+		vote := models.NewVote(env.CreateTestAgent(userID).ID, string(targetType), targetID, 1)
+		vote.CreatedAt = at
+		require.NoError(t, voteRepo.Create(env.Ctx, vote))
+	}
+
+	// Yesterday: two posts and a reply
+	first := createPost(board, yesterday)
+	createPost(board, yesterday)
+	createReply(models.ParentTypePost, first.ID, yesterday)
+
+	// Today: a post, a nested reply and votes on both
+	second := createPost(board, today)
+	reply := createReply(models.ParentTypePost, first.ID, today)
+	nested := createReply(models.ParentTypeReply, reply.ID, today)
+	createVote(models.TargetTypePost, second.ID, today)
+	createVote(models.TargetTypeReply, nested.ID, today)
+
+	// Activity on another board is left out
+	createPost(other, today)
+
+	t.Run("Daily buckets", func(t *testing.T) {
+		timeline, err := boardService.GetActivityTimeline(env.Ctx, board.ID, today.Add(-48*time.Hour), models.ActivityBucketDay)
+		require.NoError(t, err)
+		require.Len(t, timeline, 3)
+
+		// The bucket before any activity is included, empty
+		assert.True(t, timeline[0].Start.Equal(today.Add(-48*time.Hour)))
+		assert.Equal(t, models.BoardActivity{Start: timeline[0].Start}, *timeline[0])
+
+		assert.True(t, timeline[1].Start.Equal(today.Add(-24*time.Hour)))
+		assert.Equal(t, 2, timeline[1].Posts)
+		assert.Equal(t, 1, timeline[1].Replies)
+		assert.Equal(t, 0, timeline[1].Votes)
+
+		assert.True(t, timeline[2].Start.Equal(today))
+		assert.Equal(t, 1, timeline[2].Posts)
+		assert.Equal(t, 2, timeline[2].Replies)
+		assert.Equal(t, 2, timeline[2].Votes)
+	})
+
+	t.Run("Hourly buckets", func(t *testing.T) {
+		timeline, err := boardService.GetActivityTimeline(env.Ctx, board.ID, yesterday, models.ActivityBucketHour)
+		require.NoError(t, err)
+		require.NotEmpty(t, timeline)
+
+		assert.True(t, timeline[0].Start.Equal(yesterday))
+		assert.Equal(t, 2, timeline[0].Posts)
+		assert.Equal(t, 1, timeline[0].Replies)
+		assert.Equal(t, 0, timeline[1].Posts)
+	})
+
+	t.Run("Invalid bucket", func(t *testing.T) {
+		_, err := boardService.GetActivityTimeline(env.Ctx, board.ID, time.Time{}, "week")
+		assert.Equal(t, services.ErrInvalidActivityBucket, err)
+	})
+
+	t.Run("Unknown board", func(t *testing.T) {
+		_, err := boardService.GetActivityTimeline(env.Ctx, uuid.New(), time.Time{}, models.ActivityBucketDay)
+		assert.Equal(t, services.ErrBoardNotFound, err)
+	})
+}
+
+func TestCreateBoardWithPost_Integration(t *testing.T) {
+	// Setup
+	env, boardService := setupBoardTest(t)
+	defer env.Cleanup()
+
+	userID, _ := env.CreateTestUser()
+	agent := env.CreateTestAgent(userID)
+	boardRepo := repository.NewBoardRepository(env.DB)
+	postRepo := repository.NewPostRepository(env.DB)
+
+	t.Run("Creates both", func(t *testing.T) {
+		board, post, err := boardService.CreateBoardWithPost(env.Ctx, agent.ID, "Intro Board", "Say hello", true, "Welcome to my board")
+		require.NoError(t, err)
+		require.NotNil(t, board)
+		require.NotNil(t, post)
+		assert.Equal(t, board.ID, post.BoardID)
+		assert.Equal(t, agent.ID, post.AgentID)
+
+		storedBoard, err := boardRepo.GetByID(env.Ctx, board.ID)
+		require.NoError(t, err)
+		require.NotNil(t, storedBoard)
+		assert.Equal(t, "Intro Board", storedBoard.Title)
+
+		storedPost, err := postRepo.GetByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+		require.NotNil(t, storedPost)
+		assert.Equal(t, "Welcome to my board", storedPost.Content)
+		assert.Equal(t, board.ID, storedPost.BoardID)
+
+		// The post counts toward the agent's daily usage
+		storedAgent, err := env.AgentRepository.GetByID(env.Ctx, agent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, agent.UsedToday+1, storedAgent.UsedToday)
+	})
+
+	t.Run("Empty post creates nothing", func(t *testing.T) {
+		otherAgent := env.CreateTestAgent(userID)
+
+		_, _, err := boardService.CreateBoardWithPost(env.Ctx, otherAgent.ID, "Intro Board", "Say hello", true, "   ")
+		assert.ErrorIs(t, err, services.ErrEmptyContent)
+
+		board, err := boardRepo.GetByAgentID(env.Ctx, otherAgent.ID)
+		require.NoError(t, err)
+		assert.Nil(t, board)
+	})
+
+	t.Run("Post content is checked like any other post", func(t *testing.T) {
+		replyRepo := repository.NewReplyRepository(env.DB)
+		filter := services.NewBlocklistFilter([]string{"buy followers"}, services.ContentFilterReject)
+		postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, replyRepo, env.AgentService, 40, filter, services.NewHTMLSanitizer(), 0, nil)
+		checkedService := services.NewBoardService(boardRepo, env.AgentRepository, nil, postService, 0)
+
+		otherAgent := env.CreateTestAgent(userID)
+
+		// The configured length limit applies, not the default
+		_, _, err := checkedService.CreateBoardWithPost(env.Ctx, otherAgent.ID, "Intro Board", "Say hello", true, strings.Repeat("a", 41))
+		assert.ErrorIs(t, err, services.ErrContentTooLong)
+
+		_, _, err = checkedService.CreateBoardWithPost(env.Ctx, otherAgent.ID, "Intro Board", "Say hello", true, "You should buy followers")
+		assert.ErrorIs(t, err, services.ErrContentBlocked)
+
+		board, err := boardRepo.GetByAgentID(env.Ctx, otherAgent.ID)
+		require.NoError(t, err)
+		assert.Nil(t, board)
+
+		// The stored post is sanitized
+		board, post, err := checkedService.CreateBoardWithPost(env.Ctx, otherAgent.ID, "Intro Board", "Say hello", true, "**Hi**<script>alert(1)</script> there")
+		require.NoError(t, err)
+		require.NotNil(t, board)
+
+		storedPost, err := postRepo.GetByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+		require.NotNil(t, storedPost)
+		assert.Equal(t, "**Hi** there", storedPost.Content)
+	})
+
+	t.Run("Flagged post content is stored flagged", func(t *testing.T) {
+		replyRepo := repository.NewReplyRepository(env.DB)
+		filter := services.NewBlocklistFilter([]string{"buy followers"}, services.ContentFilterFlag)
+		postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, replyRepo, env.AgentService, services.DefaultMaxPostLength, filter, nil, 0, nil)
+		checkedService := services.NewBoardService(boardRepo, env.AgentRepository, nil, postService, 0)
+
+		otherAgent := env.CreateTestAgent(userID)
+
+		_, post, err := checkedService.CreateBoardWithPost(env.Ctx, otherAgent.ID, "Intro Board", "Say hello", true, "You should buy followers")
+		require.NoError(t, err)
+
+		storedPost, err := postRepo.GetByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+		require.NotNil(t, storedPost)
+		assert.True(t, storedPost.IsFlagged)
+	})
+
+	t.Run("Failed post insert rolls back the board", func(t *testing.T) {
+		otherAgent := env.CreateTestAgent(userID)
+
+		now := time.Now()
+		board := &models.Board{
+			ID:          uuid.New(),
+			AgentID:     &otherAgent.ID,
+			Title:       "Doomed Board",
+			Description: "Never stored",
+			IsActive:    true,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		// The post's author doesn't exist, so its insert violates the agent foreign key
+		post := &models.Post{
+			ID:        uuid.New(),
+			BoardID:   board.ID,
+			AgentID:   uuid.New(),
+			Content:   "Orphaned post",
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		err := boardRepo.CreateWithPost(env.Ctx, board, post)
+		require.Error(t, err)
+
+		storedBoard, err := boardRepo.GetByID(env.Ctx, board.ID)
+		require.NoError(t, err)
+		assert.Nil(t, storedBoard)
+
+		storedPost, err := postRepo.GetByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+		assert.Nil(t, storedPost)
+	})
+}
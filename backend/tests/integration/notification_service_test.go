@@ -1,6 +1,8 @@
 package integration
 
 import (
+	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -18,12 +20,16 @@ type TestNotificationEnv struct {
 	*utils.TestEnv
 	NotificationRepository repository.NotificationRepository
 	NotificationService    services.NotificationService
+	EmailService           *mockEmailService
 	PostRepository         repository.PostRepository
 	ReplyRepository        repository.ReplyRepository
 	VoteRepository         repository.VoteRepository
 	BoardRepository        repository.BoardRepository
 }
 
+// testNotificationDedupWindow is the dedup window used by the notification test environment
+const testNotificationDedupWindow = 5 * time.Minute
+
 // NewTestNotificationEnv creates a new test environment with notification components
 func NewTestNotificationEnv(t *testing.T) *TestNotificationEnv {
 	baseEnv := utils.NewTestEnv(t)
@@ -35,17 +41,24 @@ func NewTestNotificationEnv(t *testing.T) *TestNotificationEnv {
 	voteRepo := repository.NewVoteRepository(baseEnv.DB)
 	boardRepo := repository.NewBoardRepository(baseEnv.DB)
 
+	preferenceRepo := repository.NewNotificationPreferenceRepository(baseEnv.DB)
+	emailService := &mockEmailService{}
+
 	// Create notification service
 	notificationService := services.NewNotificationService(
 		notificationRepo,
+		preferenceRepo,
 		baseEnv.UserRepository,
 		baseEnv.AgentRepository,
+		emailService,
+		testNotificationDedupWindow,
 	)
 
 	return &TestNotificationEnv{
 		TestEnv:                baseEnv,
 		NotificationRepository: notificationRepo,
 		NotificationService:    notificationService,
+		EmailService:           emailService,
 		PostRepository:         postRepo,
 		ReplyRepository:        replyRepo,
 		VoteRepository:         voteRepo,
@@ -53,6 +66,35 @@ func NewTestNotificationEnv(t *testing.T) *TestNotificationEnv {
 	}
 }
 
+// mockEmailService records sent emails instead of delivering them
+type mockEmailService struct {
+	mu   sync.Mutex
+	sent []sentEmail
+}
+
+type sentEmail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+func (m *mockEmailService) Send(ctx context.Context, to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, sentEmail{To: to, Subject: subject, Body: body})
+	return nil
+}
+
+func (m *mockEmailService) IsConfigured() bool {
+	return true
+}
+
+func (m *mockEmailService) Sent() []sentEmail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]sentEmail(nil), m.sent...)
+}
+
 func TestCreateNotification_Integration(t *testing.T) {
 	// Create a test environment with a real database
 	env := NewTestNotificationEnv(t)
@@ -100,6 +142,54 @@ func TestCreateNotification_Integration(t *testing.T) {
 	assert.Equal(t, notification.AgentID, retrievedNotification.AgentID)
 }
 
+func TestCreateNotificationDedup_Integration(t *testing.T) {
+	env := NewTestNotificationEnv(t)
+	defer env.Cleanup()
+
+	userID, _ := env.CreateTestUser()
+	agent := env.CreateTestAgent(userID)
+	targetID := uuid.New()
+
+	first, err := env.NotificationService.CreateNotification(env.Ctx, agent.ID, services.NotificationTypeVote, "Someone upvoted your post", "post", targetID)
+	require.NoError(t, err)
+	require.NoError(t, env.NotificationService.MarkAsRead(env.Ctx, first.ID))
+
+	second, err := env.NotificationService.CreateNotification(env.Ctx, agent.ID, services.NotificationTypeVote, "Someone downvoted your post", "post", targetID)
+	require.NoError(t, err)
+
+	// The second notification refreshes the first instead of adding a row
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, "Someone downvoted your post", second.Content)
+	assert.False(t, second.IsRead)
+	assert.Nil(t, second.ReadAt)
+
+	notifications, total, err := env.NotificationService.GetNotificationsByAgentID(env.Ctx, agent.ID, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, first.ID, notifications[0].ID)
+
+	t.Run("Different type or target is not collapsed", func(t *testing.T) {
+		_, err := env.NotificationService.CreateNotification(env.Ctx, agent.ID, services.NotificationTypeReply, "New reply to your post", "post", targetID)
+		require.NoError(t, err)
+		_, err = env.NotificationService.CreateNotification(env.Ctx, agent.ID, services.NotificationTypeVote, "Someone upvoted your post", "post", uuid.New())
+		require.NoError(t, err)
+
+		_, total, err := env.NotificationService.GetNotificationsByAgentID(env.Ctx, agent.ID, 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+	})
+
+	t.Run("Notifications outside the window are not collapsed", func(t *testing.T) {
+		_, err := env.DB.ExecContext(env.Ctx, "UPDATE notifications SET created_at = $1 WHERE id = $2", time.Now().Add(-2*testNotificationDedupWindow), first.ID)
+		require.NoError(t, err)
+
+		third, err := env.NotificationService.CreateNotification(env.Ctx, agent.ID, services.NotificationTypeVote, "Someone upvoted your post", "post", targetID)
+		require.NoError(t, err)
+		assert.NotEqual(t, first.ID, third.ID)
+	})
+}
+
 func TestGetNotificationsByAgentID_Integration(t *testing.T) {
 	// Create a test environment with a real database
 	env := NewTestNotificationEnv(t)
@@ -240,6 +330,60 @@ func TestDeleteNotification_Integration(t *testing.T) {
 	assert.Nil(t, deletedNotification)
 }
 
+func TestDeleteReadNotifications_Integration(t *testing.T) {
+	env := NewTestNotificationEnv(t)
+	defer env.Cleanup()
+
+	userID, _ := env.CreateTestUser()
+	agent := env.CreateTestAgent(userID)
+	otherAgent := env.CreateTestAgent(userID)
+
+	// createNotification creates a notification for agentID, optionally read, created age ago
+	createNotification := func(agentID uuid.UUID, read bool, age time.Duration) *models.Notification {
+		notification, err := env.NotificationService.CreateNotification(
+			env.Ctx,
+			agentID,
+			services.NotificationTypeSystem,
+			"Test notification",
+			"post",
+			uuid.New(),
+		)
+		require.NoError(t, err)
+
+		if read {
+			require.NoError(t, env.NotificationService.MarkAsRead(env.Ctx, notification.ID))
+		}
+		_, err = env.DB.ExecContext(env.Ctx, "UPDATE notifications SET created_at = $1 WHERE id = $2", time.Now().Add(-age), notification.ID)
+		require.NoError(t, err)
+		return notification
+	}
+
+	oldRead := createNotification(agent.ID, true, 45*24*time.Hour)
+	recentRead := createNotification(agent.ID, true, 24*time.Hour)
+	oldUnread := createNotification(agent.ID, false, 45*24*time.Hour)
+	otherOldRead := createNotification(otherAgent.ID, true, 45*24*time.Hour)
+
+	t.Run("Negative age is rejected", func(t *testing.T) {
+		_, err := env.NotificationService.DeleteReadNotifications(env.Ctx, agent.ID, -time.Hour)
+		assert.Equal(t, services.ErrInvalidRetention, err)
+	})
+
+	t.Run("Deletes only the agent's old read notifications", func(t *testing.T) {
+		deleted, err := env.NotificationService.DeleteReadNotifications(env.Ctx, agent.ID, 30*24*time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, 1, deleted)
+
+		_, err = env.NotificationRepository.GetByID(env.Ctx, oldRead.ID)
+		assert.Error(t, err)
+
+		for _, kept := range []*models.Notification{recentRead, oldUnread, otherOldRead} {
+			notification, err := env.NotificationRepository.GetByID(env.Ctx, kept.ID)
+			require.NoError(t, err)
+			assert.NotNil(t, notification)
+		}
+	})
+}
+
 func TestCountUnread_Integration(t *testing.T) {
 	// Create a test environment with a real database
 	env := NewTestNotificationEnv(t)
@@ -297,7 +441,7 @@ func TestNotifyOnReply_Integration(t *testing.T) {
 	// Create a test board
 	board := &models.Board{
 		ID:          uuid.New(),
-		AgentID:     postOwnerAgent.ID,
+		AgentID:     &postOwnerAgent.ID,
 		Title:       "Test Board",
 		Description: "Test Board Description",
 		IsActive:    true,
@@ -332,7 +476,7 @@ func TestNotifyOnReply_Integration(t *testing.T) {
 	require.NoError(t, err)
 
 	// Notify on reply
-	err = env.NotificationService.NotifyOnReply(env.Ctx, reply, post)
+	err = env.NotificationService.NotifyOnReply(env.Ctx, reply, post, nil)
 	require.NoError(t, err)
 
 	// Verify notification was created
@@ -350,7 +494,7 @@ func TestNotifyOnReply_Integration(t *testing.T) {
 	assert.Equal(t, string(services.NotificationTypeReply), notification.Type)
 	assert.Equal(t, "New reply to your post", notification.Content)
 	assert.Equal(t, "post", notification.TargetType)
-	assert.Equal(t, reply.ID, notification.TargetID)
+	assert.Equal(t, post.ID, notification.TargetID)
 }
 
 func TestNotifyOnVote_Integration(t *testing.T) {
@@ -369,7 +513,7 @@ func TestNotifyOnVote_Integration(t *testing.T) {
 	// Create a test board
 	board := &models.Board{
 		ID:          uuid.New(),
-		AgentID:     postOwnerAgent.ID,
+		AgentID:     &postOwnerAgent.ID,
 		Title:       "Test Board",
 		Description: "Test Board Description",
 		IsActive:    true,
@@ -422,7 +566,7 @@ func TestNotifyOnVote_Integration(t *testing.T) {
 	assert.Equal(t, string(services.NotificationTypeVote), notification.Type)
 	assert.Equal(t, "Someone upvoted your post", notification.Content)
 	assert.Equal(t, "post", notification.TargetType)
-	assert.Equal(t, upvote.ID, notification.TargetID)
+	assert.Equal(t, post.ID, notification.TargetID)
 
 	// Create a test downvote on a reply
 	reply := &models.Reply{
@@ -464,7 +608,7 @@ func TestNotifyOnVote_Integration(t *testing.T) {
 	// Find the downvote notification (should be the newest one)
 	var downvoteNotification *models.Notification
 	for _, n := range notifications {
-		if n.TargetID == downvote.ID {
+		if n.TargetID == reply.ID {
 			downvoteNotification = n
 			break
 		}
@@ -475,5 +619,585 @@ func TestNotifyOnVote_Integration(t *testing.T) {
 	assert.Equal(t, string(services.NotificationTypeVote), downvoteNotification.Type)
 	assert.Equal(t, "Someone downvoted your reply", downvoteNotification.Content)
 	assert.Equal(t, "reply", downvoteNotification.TargetType)
-	assert.Equal(t, downvote.ID, downvoteNotification.TargetID)
+	assert.Equal(t, reply.ID, downvoteNotification.TargetID)
+}
+
+func TestNotificationEmail_Integration(t *testing.T) {
+	// Create a test environment with a real database
+	env := NewTestNotificationEnv(t)
+	defer env.Cleanup()
+
+	// Create a test user and agent
+	userID, _ := env.CreateTestUser()
+	agent := env.CreateTestAgent(userID)
+	user, err := env.UserRepository.GetByID(env.Ctx, userID)
+	require.NoError(t, err)
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		preference, err := env.NotificationService.GetPreferences(env.Ctx, agent.ID)
+		require.NoError(t, err)
+		assert.False(t, preference.EmailEnabled)
+
+		_, err = env.NotificationService.CreateNotification(env.Ctx, agent.ID, services.NotificationTypeReply, "New reply to your post", "post", uuid.New())
+		require.NoError(t, err)
+		assert.Empty(t, env.EmailService.Sent())
+	})
+
+	t.Run("Emails replies when enabled and throttles bursts", func(t *testing.T) {
+		emailEnabled := true
+		preference, err := env.NotificationService.UpdatePreferences(env.Ctx, agent.ID, &emailEnabled, nil, nil)
+		require.NoError(t, err)
+		assert.True(t, preference.EmailEnabled)
+
+		// Votes stay in-app only
+		_, err = env.NotificationService.CreateNotification(env.Ctx, agent.ID, services.NotificationTypeVote, "Someone upvoted your post", "post", uuid.New())
+		require.NoError(t, err)
+		assert.Empty(t, env.EmailService.Sent())
+
+		_, err = env.NotificationService.CreateNotification(env.Ctx, agent.ID, services.NotificationTypeReply, "New reply to your post", "post", uuid.New())
+		require.NoError(t, err)
+
+		// The email is sent in the background
+		require.Eventually(t, func() bool { return len(env.EmailService.Sent()) == 1 }, time.Second, 10*time.Millisecond)
+		sent := env.EmailService.Sent()
+		assert.Equal(t, user.Email, sent[0].To)
+		assert.Contains(t, sent[0].Body, "New reply to your post")
+
+		// A second reply within the throttle interval does not send another email
+		_, err = env.NotificationService.CreateNotification(env.Ctx, agent.ID, services.NotificationTypeReply, "New reply to your post", "post", uuid.New())
+		require.NoError(t, err)
+		assert.Len(t, env.EmailService.Sent(), 1)
+	})
+}
+
+func TestNotificationDigest_Integration(t *testing.T) {
+	// Create a test environment with a real database
+	env := NewTestNotificationEnv(t)
+	defer env.Cleanup()
+
+	// Create a test user with two agents subscribed to daily digests
+	userID, _ := env.CreateTestUser()
+	busyAgent := env.CreateTestAgent(userID)
+	quietAgent := env.CreateTestAgent(userID)
+
+	daily := string(models.DigestFrequencyDaily)
+	for _, agentID := range []uuid.UUID{busyAgent.ID, quietAgent.ID} {
+		preference, err := env.NotificationService.UpdatePreferences(env.Ctx, agentID, nil, &daily, nil)
+		require.NoError(t, err)
+		assert.Equal(t, models.DigestFrequencyDaily, preference.DigestFrequency)
+	}
+
+	// Votes don't trigger per-event emails, so the digest is the only email sent
+	for i := 0; i < 3; i++ {
+		_, err := env.NotificationService.CreateNotification(env.Ctx, busyAgent.ID, services.NotificationTypeVote, "Someone upvoted your post", "post", uuid.New())
+		require.NoError(t, err)
+	}
+
+	t.Run("Includes the unread count", func(t *testing.T) {
+		sent, err := env.NotificationService.SendDigest(env.Ctx, busyAgent.ID)
+		require.NoError(t, err)
+		assert.True(t, sent)
+
+		emails := env.EmailService.Sent()
+		require.Len(t, emails, 1)
+		assert.Contains(t, emails[0].Body, "3 unread notifications")
+		assert.Contains(t, emails[0].Body, "Someone upvoted your post")
+	})
+
+	t.Run("Skips agents with nothing new", func(t *testing.T) {
+		sent, err := env.NotificationService.SendDigest(env.Ctx, quietAgent.ID)
+		require.NoError(t, err)
+		assert.False(t, sent)
+
+		// Notifications already covered by the previous digest aren't sent again
+		sent, err = env.NotificationService.SendDigest(env.Ctx, busyAgent.ID)
+		require.NoError(t, err)
+		assert.False(t, sent)
+
+		assert.Len(t, env.EmailService.Sent(), 1)
+	})
+
+	t.Run("Rejects unknown frequencies", func(t *testing.T) {
+		weekly := "weekly"
+		_, err := env.NotificationService.UpdatePreferences(env.Ctx, busyAgent.ID, nil, &weekly, nil)
+		assert.ErrorIs(t, err, services.ErrInvalidDigestFrequency)
+	})
+}
+
+func TestSendDailyDigests_Integration(t *testing.T) {
+	env := NewTestNotificationEnv(t)
+	defer env.Cleanup()
+
+	userID, _ := env.CreateTestUser()
+	agent := env.CreateTestAgent(userID)
+
+	daily := string(models.DigestFrequencyDaily)
+	_, err := env.NotificationService.UpdatePreferences(env.Ctx, agent.ID, nil, &daily, nil)
+	require.NoError(t, err)
+
+	notify := func() {
+		_, err := env.NotificationService.CreateNotification(env.Ctx, agent.ID, services.NotificationTypeVote, "Someone upvoted your post", "post", uuid.New())
+		require.NoError(t, err)
+	}
+
+	t.Run("Sends to agents that never had a digest", func(t *testing.T) {
+		notify()
+
+		sent, err := env.NotificationService.SendDailyDigests(env.Ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+	})
+
+	t.Run("Waits a day before the next one", func(t *testing.T) {
+		notify()
+
+		// The worker runs hourly; an agent emailed within the last day is skipped
+		sent, err := env.NotificationService.SendDailyDigests(env.Ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, sent)
+
+		_, err = env.DB.Exec(`UPDATE notification_preferences SET last_digest_at = NOW() - INTERVAL '24 hours' WHERE agent_id = $1`, agent.ID)
+		require.NoError(t, err)
+
+		sent, err = env.NotificationService.SendDailyDigests(env.Ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		assert.Len(t, env.EmailService.Sent(), 2)
+	})
+}
+
+func TestNotificationQueriesUseAgentIndexes_Integration(t *testing.T) {
+	env := NewTestNotificationEnv(t)
+	defer env.Cleanup()
+
+	// Seed enough notifications across agents that a sequential scan is clearly worse;
+	// roughly one in ten is unread
+	userID, _ := env.CreateTestUser()
+	var agent *models.Agent
+	for i := 0; i < 20; i++ {
+		agent = env.CreateTestAgent(userID)
+		_, err := env.DB.Exec(`
+			INSERT INTO notifications (id, agent_id, type, content, target_type, target_id, is_read, created_at)
+			SELECT uuid_generate_v4(), $1, 'reply', 'Seeded notification', 'post', uuid_generate_v4(),
+			       n % 10 <> 0, NOW() - n * INTERVAL '1 minute'
+			FROM generate_series(1, 1000) AS n
+		`, agent.ID)
+		require.NoError(t, err)
+	}
+
+	tables := []string{"notifications"}
+
+	t.Run("Listing uses the agent and created_at index", func(t *testing.T) {
+		// Mirrors notificationRepository.GetByAgentID
+		plan := utils.ExplainQuery(t, env.DB, tables, `
+			SELECT id, agent_id, type, content, target_type, target_id, is_read, created_at, read_at
+			FROM notifications
+			WHERE agent_id = $1
+			ORDER BY created_at DESC
+			LIMIT $2 OFFSET $3
+		`, agent.ID, 20, 0)
+
+		assert.Contains(t, plan, "idx_notifications_agent_id_created_at", plan)
+		assert.NotContains(t, plan, "Seq Scan", plan)
+		assert.NotContains(t, plan, "Sort", plan)
+	})
+
+	t.Run("Unread count uses the partial unread index", func(t *testing.T) {
+		// Mirrors notificationRepository.CountUnread
+		plan := utils.ExplainQuery(t, env.DB, tables, `
+			SELECT COUNT(*)
+			FROM notifications
+			WHERE agent_id = $1 AND is_read = false
+		`, agent.ID)
+
+		assert.Contains(t, plan, "idx_notifications_agent_id_unread", plan)
+		assert.NotContains(t, plan, "Seq Scan", plan)
+	})
+
+	t.Run("Unread count matches the seeded data", func(t *testing.T) {
+		count, err := env.NotificationRepository.CountUnread(env.Ctx, agent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 100, count)
+	})
+}
+
+func TestNotifyOnReply_ThreadParticipants_Integration(t *testing.T) {
+	env := NewTestNotificationEnv(t)
+	defer env.Cleanup()
+
+	newAgent := func() *models.Agent {
+		userID, _ := env.CreateTestUser()
+		return env.CreateTestAgent(userID)
+	}
+	postOwner := newAgent()
+	participants := []*models.Agent{newAgent(), newAgent(), newAgent()}
+	newcomer := newAgent()
+
+	board := &models.Board{
+		ID:          uuid.New(),
+		AgentID:     &postOwner.ID,
+		Title:       "Thread Board",
+		Description: "Test Board Description",
+		IsActive:    true,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	require.NoError(t, env.BoardRepository.Create(env.Ctx, board))
+
+	post := &models.Post{
+		ID:        uuid.New(),
+		BoardID:   board.ID,
+		AgentID:   postOwner.ID,
+		Content:   "Thread starter",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, env.PostRepository.Create(env.Ctx, post))
+
+	createReply := func(agent *models.Agent, parentType string, parentID uuid.UUID) *models.Reply {
+		reply := &models.Reply{
+			ID:         uuid.New(),
+			AgentID:    agent.ID,
+			ParentID:   parentID,
+			ParentType: parentType,
+			Content:    "Reply from " + agent.Name,
+			CreatedAt:  time.Now(),
+		}
+		require.NoError(t, env.ReplyRepository.Create(env.Ctx, reply))
+		return reply
+	}
+
+	// The thread: two replies on the post and one nested reply
+	first := createReply(participants[0], "post", post.ID)
+	createReply(participants[1], "reply", first.ID)
+	third := createReply(participants[2], "post", post.ID)
+
+	// Thread notifications for the post collapse into one, so each step starts with
+	// everything read and counts what it made unread
+	everyone := append([]*models.Agent{postOwner, newcomer}, participants...)
+	markAllRead := func() {
+		for _, agent := range everyone {
+			require.NoError(t, env.NotificationService.MarkAllAsRead(env.Ctx, agent.ID))
+		}
+	}
+	unreadOfType := func(notificationType services.NotificationType, agentID, targetID uuid.UUID) int {
+		notifications, _, err := env.NotificationService.GetNotificationsByAgentID(env.Ctx, agentID, 1, 50)
+		require.NoError(t, err)
+		count := 0
+		for _, notification := range notifications {
+			if !notification.IsRead && notification.Type == string(notificationType) && notification.TargetID == targetID {
+				count++
+			}
+		}
+		return count
+	}
+	threadNotifications := func(agentID uuid.UUID) int {
+		return unreadOfType(services.NotificationTypeThreadReply, agentID, post.ID)
+	}
+	directNotifications := func(agentID, parentID uuid.UUID) int {
+		return unreadOfType(services.NotificationTypeReply, agentID, parentID)
+	}
+
+	t.Run("Every participant is notified of a new reply", func(t *testing.T) {
+		markAllRead()
+		reply := createReply(newcomer, "reply", first.ID)
+		require.NoError(t, env.NotificationService.NotifyOnReply(env.Ctx, reply, post, first))
+
+		// The author of the replied-to reply gets a direct reply notification instead
+		assert.Equal(t, 1, directNotifications(participants[0].ID, first.ID))
+		assert.Equal(t, 0, threadNotifications(participants[0].ID))
+		for _, participant := range participants[1:] {
+			assert.Equal(t, 1, threadNotifications(participant.ID), participant.Name)
+		}
+		assert.Equal(t, 0, threadNotifications(newcomer.ID))
+
+		// The post owner didn't reply in the thread, and this isn't a direct reply to their post
+		count, err := env.NotificationService.CountUnread(env.Ctx, postOwner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("The author is not notified of their own reply", func(t *testing.T) {
+		markAllRead()
+		reply := createReply(participants[0], "post", post.ID)
+		require.NoError(t, env.NotificationService.NotifyOnReply(env.Ctx, reply, post, nil))
+
+		assert.Equal(t, 0, threadNotifications(participants[0].ID))
+		assert.Equal(t, 1, threadNotifications(participants[1].ID))
+		assert.Equal(t, 1, threadNotifications(participants[2].ID))
+		assert.Equal(t, 1, threadNotifications(newcomer.ID))
+
+		// The post owner gets a regular reply notification instead
+		notifications, _, err := env.NotificationService.GetNotificationsByAgentID(env.Ctx, postOwner.ID, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, notifications, 1)
+		assert.Equal(t, string(services.NotificationTypeReply), notifications[0].Type)
+	})
+
+	t.Run("Participants can turn thread notifications off", func(t *testing.T) {
+		disabled := false
+		preference, err := env.NotificationService.UpdatePreferences(env.Ctx, participants[2].ID, nil, nil, &disabled)
+		require.NoError(t, err)
+		assert.False(t, preference.ThreadRepliesEnabled)
+
+		markAllRead()
+		reply := createReply(participants[1], "post", post.ID)
+		require.NoError(t, env.NotificationService.NotifyOnReply(env.Ctx, reply, post, nil))
+
+		assert.Equal(t, 1, threadNotifications(participants[0].ID))
+		assert.Equal(t, 0, threadNotifications(participants[2].ID))
+
+		// Direct replies still get through
+		direct := createReply(newcomer, "reply", third.ID)
+		require.NoError(t, env.NotificationService.NotifyOnReply(env.Ctx, direct, post, third))
+
+		assert.Equal(t, 1, directNotifications(participants[2].ID, third.ID))
+		assert.Equal(t, 0, threadNotifications(participants[2].ID))
+	})
+}
+
+func TestNotifyDedup_Integration(t *testing.T) {
+	env := NewTestNotificationEnv(t)
+	defer env.Cleanup()
+
+	newAgent := func() *models.Agent {
+		userID, _ := env.CreateTestUser()
+		return env.CreateTestAgent(userID)
+	}
+	author := newAgent()
+	participant := newAgent()
+	others := []*models.Agent{newAgent(), newAgent()}
+
+	board := models.NewBoard(author.ID, "Dedup Board", "Repeated notifications")
+	require.NoError(t, env.BoardRepository.Create(env.Ctx, board))
+	post := models.NewPost(board.ID, author.ID, "Popular post", nil)
+	require.NoError(t, env.PostRepository.Create(env.Ctx, post))
+
+	rows := func(agentID uuid.UUID, notificationType services.NotificationType) []*models.Notification {
+		notifications, _, err := env.NotificationService.GetNotificationsByAgentID(env.Ctx, agentID, 1, 50)
+		require.NoError(t, err)
+		var matching []*models.Notification
+		for _, notification := range notifications {
+			if notification.Type == string(notificationType) {
+				matching = append(matching, notification)
+			}
+		}
+		return matching
+	}
+
+	t.Run("Votes on the same post collapse", func(t *testing.T) {
+		for _, voter := range others {
+			vote := &models.Vote{ID: uuid.New(), AgentID: voter.ID, TargetID: post.ID, TargetType: "post", Value: 1, CreatedAt: time.Now()}
+			require.NoError(t, env.VoteRepository.Create(env.Ctx, vote))
+			require.NoError(t, env.NotificationService.NotifyOnVote(env.Ctx, vote, author.ID))
+		}
+
+		votes := rows(author.ID, services.NotificationTypeVote)
+		require.Len(t, votes, 1)
+		assert.Equal(t, post.ID, votes[0].TargetID)
+	})
+
+	t.Run("Replies in the same thread collapse", func(t *testing.T) {
+		// The participant has replied before, so they hear about the thread too
+		earlier := &models.Reply{ID: uuid.New(), AgentID: participant.ID, ParentID: post.ID, ParentType: "post", Content: "First!", CreatedAt: time.Now()}
+		require.NoError(t, env.ReplyRepository.Create(env.Ctx, earlier))
+
+		for _, replier := range others {
+			reply := &models.Reply{ID: uuid.New(), AgentID: replier.ID, ParentID: post.ID, ParentType: "post", Content: "Me too", CreatedAt: time.Now()}
+			require.NoError(t, env.ReplyRepository.Create(env.Ctx, reply))
+			require.NoError(t, env.NotificationService.NotifyOnReply(env.Ctx, reply, post, nil))
+		}
+
+		replies := rows(author.ID, services.NotificationTypeReply)
+		require.Len(t, replies, 1)
+		assert.Equal(t, post.ID, replies[0].TargetID)
+
+		threadReplies := rows(participant.ID, services.NotificationTypeThreadReply)
+		require.Len(t, threadReplies, 1)
+		assert.Equal(t, post.ID, threadReplies[0].TargetID)
+	})
+}
+
+func TestNotifyOnReply_BlockedAuthor_Integration(t *testing.T) {
+	env := NewTestNotificationEnv(t)
+	defer env.Cleanup()
+
+	newAgent := func() *models.Agent {
+		userID, _ := env.CreateTestUser()
+		return env.CreateTestAgent(userID)
+	}
+	postOwner := newAgent()
+	blocked := newAgent()
+
+	board := &models.Board{
+		ID:          uuid.New(),
+		AgentID:     &postOwner.ID,
+		Title:       "Block Board",
+		Description: "Test Board Description",
+		IsActive:    true,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	require.NoError(t, env.BoardRepository.Create(env.Ctx, board))
+
+	post := &models.Post{
+		ID:        uuid.New(),
+		BoardID:   board.ID,
+		AgentID:   postOwner.ID,
+		Content:   "Post from the owner",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	require.NoError(t, env.PostRepository.Create(env.Ctx, post))
+
+	require.NoError(t, env.AgentService.BlockAgent(env.Ctx, postOwner.ID, blocked.ID))
+
+	reply := &models.Reply{
+		ID:         uuid.New(),
+		AgentID:    blocked.ID,
+		ParentID:   post.ID,
+		ParentType: "post",
+		Content:    "Reply from a blocked agent",
+		CreatedAt:  time.Now(),
+	}
+	require.NoError(t, env.ReplyRepository.Create(env.Ctx, reply))
+	require.NoError(t, env.NotificationService.NotifyOnReply(env.Ctx, reply, post, nil))
+
+	vote := &models.Vote{
+		ID:         uuid.New(),
+		AgentID:    blocked.ID,
+		TargetType: string(models.TargetTypePost),
+		TargetID:   post.ID,
+		Value:      1,
+	}
+	require.NoError(t, env.NotificationService.NotifyOnVote(env.Ctx, vote, postOwner.ID))
+
+	count, err := env.NotificationService.CountUnread(env.Ctx, postOwner.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestBroadcast_Integration(t *testing.T) {
+	env := NewTestNotificationEnv(t)
+	defer env.Cleanup()
+
+	var agents []*models.Agent
+	for i := 0; i < 5; i++ {
+		userID, _ := env.CreateTestUser()
+		agents = append(agents, env.CreateTestAgent(userID))
+	}
+
+	// Deleted agents aren't notified
+	deletedUserID, _ := env.CreateTestUser()
+	deleted := env.CreateTestAgent(deletedUserID)
+	require.NoError(t, env.AgentRepository.Delete(env.Ctx, deleted.ID))
+
+	systemCount := func(agentID uuid.UUID) int {
+		var count int
+		require.NoError(t, env.DB.Get(&count,
+			`SELECT COUNT(*) FROM notifications WHERE agent_id = $1 AND type = 'system' AND content = 'Maintenance tonight'`, agentID))
+		return count
+	}
+
+	targetID := uuid.New()
+
+	t.Run("Every agent is notified once", func(t *testing.T) {
+		notified, err := env.NotificationService.Broadcast(env.Ctx, "  Maintenance tonight ", "post", targetID)
+		require.NoError(t, err)
+		assert.Equal(t, len(agents), notified)
+
+		for _, agent := range agents {
+			assert.Equal(t, 1, systemCount(agent.ID), "agent %s", agent.ID)
+
+			notifications, err := env.NotificationRepository.GetByAgentID(env.Ctx, agent.ID, 0, 10)
+			require.NoError(t, err)
+			require.Len(t, notifications, 1)
+			assert.False(t, notifications[0].IsRead)
+			assert.Equal(t, "post", notifications[0].TargetType)
+			assert.Equal(t, targetID, notifications[0].TargetID)
+		}
+		assert.Equal(t, 0, systemCount(deleted.ID))
+		assert.Empty(t, env.EmailService.Sent())
+	})
+
+	t.Run("Batches cover every agent exactly once", func(t *testing.T) {
+		notified, err := env.NotificationRepository.CreateForAllAgents(env.Ctx, "system", "Maintenance tonight", "post", targetID, 2)
+		require.NoError(t, err)
+		assert.Equal(t, len(agents), notified)
+
+		for _, agent := range agents {
+			assert.Equal(t, 2, systemCount(agent.ID), "agent %s", agent.ID)
+		}
+	})
+
+	t.Run("Invalid broadcasts", func(t *testing.T) {
+		_, err := env.NotificationService.Broadcast(env.Ctx, "   ", "post", targetID)
+		assert.Equal(t, services.ErrEmptyContent, err)
+
+		_, err = env.NotificationService.Broadcast(env.Ctx, "Maintenance tonight", "board", targetID)
+		assert.Equal(t, services.ErrInvalidTargetType, err)
+	})
+}
+
+func TestReplyAndVoteServicesNotify_Integration(t *testing.T) {
+	env := NewTestNotificationEnv(t)
+	defer env.Cleanup()
+
+	replyService := services.NewReplyService(env.ReplyRepository, env.PostRepository, env.BoardRepository, env.AgentRepository, env.AgentService, services.DefaultMaxReplyLength, nil, nil, 0, env.NotificationService)
+	voteService := services.NewVoteService(env.VoteRepository, env.PostRepository, env.ReplyRepository, env.BoardRepository, env.AgentRepository, env.NotificationService)
+
+	authorUserID, _ := env.CreateTestUser()
+	author := env.CreateTestAgent(authorUserID)
+	otherUserID, _ := env.CreateTestUser()
+	other := env.CreateTestAgent(otherUserID)
+
+	board := models.NewBoard(author.ID, "Notify Board", "Notifications from services")
+	require.NoError(t, env.BoardRepository.Create(env.Ctx, board))
+	post := models.NewPost(board.ID, author.ID, "Notify me", nil)
+	require.NoError(t, env.PostRepository.Create(env.Ctx, post))
+
+	t.Run("Replying to your own post notifies no one", func(t *testing.T) {
+		_, err := replyService.CreateReply(env.Ctx, string(models.ParentTypePost), post.ID, author.ID, "Talking to myself", "", nil)
+		require.NoError(t, err)
+
+		unread, err := env.NotificationService.CountUnread(env.Ctx, author.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, unread)
+	})
+
+	t.Run("A reply notifies the post's author", func(t *testing.T) {
+		_, err := replyService.CreateReply(env.Ctx, string(models.ParentTypePost), post.ID, other.ID, "Hello there", "", nil)
+		require.NoError(t, err)
+
+		notifications, _, err := env.NotificationService.GetNotificationsByAgentID(env.Ctx, author.ID, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, notifications, 1)
+		assert.Equal(t, string(services.NotificationTypeReply), notifications[0].Type)
+		assert.Equal(t, post.ID, notifications[0].TargetID)
+	})
+
+	t.Run("A vote notifies the target's author", func(t *testing.T) {
+		_, err := voteService.CreateVote(env.Ctx, other.ID, string(models.TargetTypePost), post.ID, 1)
+		require.NoError(t, err)
+
+		notifications, _, err := env.NotificationService.GetNotificationsByAgentID(env.Ctx, author.ID, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, notifications, 2)
+		assert.Equal(t, string(services.NotificationTypeVote), notifications[0].Type)
+		assert.Equal(t, post.ID, notifications[0].TargetID)
+	})
+
+	t.Run("Voting on your own post notifies no one", func(t *testing.T) {
+		ownReply, err := replyService.CreateReply(env.Ctx, string(models.ParentTypePost), post.ID, other.ID, "Another reply", "", nil)
+		require.NoError(t, err)
+		before, err := env.NotificationService.CountUnread(env.Ctx, other.ID)
+		require.NoError(t, err)
+
+		_, err = voteService.CreateVote(env.Ctx, other.ID, string(models.TargetTypeReply), ownReply.ID, 1)
+		require.NoError(t, err)
+
+		after, err := env.NotificationService.CountUnread(env.Ctx, other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+	})
 }
@@ -1,7 +1,9 @@
 package integration
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/garrettallen/aiboards/backend/internal/database/repository"
 	"github.com/garrettallen/aiboards/backend/internal/models"
@@ -19,10 +21,11 @@ func setupPostTest(t *testing.T) (*utils.TestEnv, services.BoardService, service
 	// Add repositories to test environment
 	boardRepo := repository.NewBoardRepository(env.DB)
 	postRepo := repository.NewPostRepository(env.DB)
+	replyRepo := repository.NewReplyRepository(env.DB)
 
 	// Create services
-	boardService := services.NewBoardService(boardRepo, env.AgentRepository)
-	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, env.AgentService)
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
+	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, replyRepo, env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, nil)
 
 	return env, boardService, postService
 }
@@ -70,6 +73,31 @@ func TestPostService(t *testing.T) {
 		assert.Nil(t, post.DeletedAt)
 	})
 
+	t.Run("CreatePost_ContentLength", func(t *testing.T) {
+		// Exactly the maximum length is allowed, counted in characters rather than bytes
+		maxContent := strings.Repeat("é", services.DefaultMaxPostLength)
+		post, err := postService.CreatePost(env.Ctx, boardID, agentID, maxContent, "")
+		require.NoError(t, err)
+		assert.Equal(t, maxContent, post.Content)
+
+		// One character over is rejected
+		_, err = postService.CreatePost(env.Ctx, boardID, agentID, maxContent+"x", "")
+		assert.Equal(t, services.ErrContentTooLong, err)
+
+		// Updates are held to the same limit
+		post.Content = maxContent + "x"
+		err = postService.UpdatePost(env.Ctx, post)
+		assert.Equal(t, services.ErrContentTooLong, err)
+	})
+
+	t.Run("CreatePost_BlankContent", func(t *testing.T) {
+		_, err := postService.CreatePost(env.Ctx, boardID, agentID, "", "")
+		assert.Equal(t, services.ErrEmptyContent, err)
+
+		_, err = postService.CreatePost(env.Ctx, boardID, agentID, " \n\t ", "")
+		assert.Equal(t, services.ErrEmptyContent, err)
+	})
+
 	t.Run("GetPostByID", func(t *testing.T) {
 		// Create a post
 		post, err := postService.CreatePost(env.Ctx, boardID, agentID, "Test Get Post", "")
@@ -155,17 +183,123 @@ func TestPostService(t *testing.T) {
 		}
 
 		// Get posts with pagination
-		posts, count, err := postService.GetPostsByAgentID(env.Ctx, agentID, 1, 3)
+		posts, count, err := postService.GetPostsByAgentID(env.Ctx, agentID, 1, 3, true)
 		require.NoError(t, err)
 		assert.Len(t, posts, 3)
 		assert.GreaterOrEqual(t, count, 5)
 
 		// Get next page
-		morePosts, _, err := postService.GetPostsByAgentID(env.Ctx, agentID, 2, 3)
+		morePosts, _, err := postService.GetPostsByAgentID(env.Ctx, agentID, 2, 3, true)
 		require.NoError(t, err)
 		assert.NotEmpty(t, morePosts)
 	})
 
+	t.Run("DeletedPost_HiddenFromListingsButFoundByAdmin", func(t *testing.T) {
+		// Use a dedicated board so counts are predictable
+		deletedBoard, err := boardService.CreateBoard(env.Ctx, env.CreateTestAgent(agent.UserID).ID, "Soft Delete Board", "Description", true)
+		require.NoError(t, err)
+
+		kept, err := postService.CreatePost(env.Ctx, deletedBoard.ID, agentID, "Visible soft delete post", "")
+		require.NoError(t, err)
+		deleted, err := postService.CreatePost(env.Ctx, deletedBoard.ID, agentID, "Hidden soft delete post", "")
+		require.NoError(t, err)
+
+		replyService := services.NewReplyService(
+			repository.NewReplyRepository(env.DB),
+			repository.NewPostRepository(env.DB),
+			repository.NewBoardRepository(env.DB),
+			env.AgentRepository,
+			env.AgentService,
+			services.DefaultMaxReplyLength,
+			nil,
+			nil,
+			0,
+			nil,
+		)
+		_, err = replyService.CreateReply(env.Ctx, string(models.ParentTypePost), deleted.ID, agentID, "Reply on hidden post", "", nil)
+		require.NoError(t, err)
+
+		require.NoError(t, postService.DeletePost(env.Ctx, deleted.ID))
+
+		// Board listing
+		posts, count, err := postService.GetPostsByBoardID(env.Ctx, deletedBoard.ID, 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		require.Len(t, posts, 1)
+		assert.Equal(t, kept.ID, posts[0].ID)
+
+		// Search
+		posts, count, err = postService.SearchPosts(env.Ctx, deletedBoard.ID, "soft delete", 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		require.Len(t, posts, 1)
+		assert.Equal(t, kept.ID, posts[0].ID)
+
+		// Threaded replies of the deleted post
+		_, err = replyService.GetThreadedReplies(env.Ctx, deleted.ID)
+		assert.Equal(t, services.ErrPostNotFound, err)
+
+		// Admin lookup still finds it
+		_, err = postService.FindPostByID(env.Ctx, deleted.ID, false)
+		assert.Equal(t, services.ErrPostNotFound, err)
+		found, err := postService.FindPostByID(env.Ctx, deleted.ID, true)
+		require.NoError(t, err)
+		assert.Equal(t, deleted.ID, found.ID)
+		assert.NotNil(t, found.DeletedAt)
+	})
+
+	t.Run("RecountStats_RepairsCorruptedCounter", func(t *testing.T) {
+		// Create a post with replies, one of which is soft deleted
+		post, err := postService.CreatePost(env.Ctx, boardID, agentID, "Recount Post", "")
+		require.NoError(t, err)
+
+		replyService := services.NewReplyService(
+			repository.NewReplyRepository(env.DB),
+			repository.NewPostRepository(env.DB),
+			repository.NewBoardRepository(env.DB),
+			env.AgentRepository,
+			env.AgentService,
+			services.DefaultMaxReplyLength,
+			nil,
+			nil,
+			0,
+			nil,
+		)
+		parentType := string(models.ParentTypePost)
+		_, err = replyService.CreateReply(env.Ctx, parentType, post.ID, agentID, "Kept Reply", "", nil)
+		require.NoError(t, err)
+		deleted, err := replyService.CreateReply(env.Ctx, parentType, post.ID, agentID, "Deleted Reply", "", nil)
+		require.NoError(t, err)
+		require.NoError(t, replyService.DeleteReply(env.Ctx, deleted.ID))
+
+		// Deliberately corrupt the denormalized counters
+		_, err = env.DB.Exec(`UPDATE posts SET reply_count = 99, vote_count = -7 WHERE id = $1`, post.ID)
+		require.NoError(t, err)
+
+		// Repair the post
+		repaired, err := postService.RecountStats(env.Ctx, post.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, repaired.ReplyCount)
+		assert.Equal(t, 0, repaired.VoteCount)
+
+		// Corrupt again and repair everything
+		_, err = env.DB.Exec(`UPDATE posts SET reply_count = 42 WHERE id = $1`, post.ID)
+		require.NoError(t, err)
+
+		result, err := postService.RecountAll(env.Ctx)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, result.PostsFixed, 1)
+
+		retrievedPost, err := postService.GetPostByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, retrievedPost.ReplyCount)
+	})
+
+	t.Run("RecountStats_NotFound", func(t *testing.T) {
+		_, err := postService.RecountStats(env.Ctx, uuid.New())
+		assert.Equal(t, services.ErrPostNotFound, err)
+	})
+
 	t.Run("CreatePost_InvalidBoard", func(t *testing.T) {
 		// Try to create a post with a non-existent board
 		_, err := postService.CreatePost(env.Ctx, uuid.New(), agentID, "Invalid Board Post", "")
@@ -182,7 +316,7 @@ func TestPostService(t *testing.T) {
 
 	t.Run("CreatePost_InactiveBoard", func(t *testing.T) {
 		// Create a board first
-		inactiveBoard, err := boardService.CreateBoard(env.Ctx, agentID, "Inactive Board", "Description", false)
+		inactiveBoard, err := boardService.CreateBoard(env.Ctx, env.CreateTestAgent(agent.UserID).ID, "Inactive Board", "Description", false)
 		require.NoError(t, err)
 		
 		// Explicitly set the board to inactive to ensure it overrides any default values
@@ -203,7 +337,7 @@ func TestPostService(t *testing.T) {
 	
 	t.Run("SearchPosts", func(t *testing.T) {
 		// Create a new board for search testing
-		searchBoard, err := boardService.CreateBoard(env.Ctx, agentID, "Search Test Board", "For testing search", true)
+		searchBoard, err := boardService.CreateBoard(env.Ctx, env.CreateTestAgent(agent.UserID).ID, "Search Test Board", "For testing search", true)
 		require.NoError(t, err)
 		
 		// Explicitly set the board to active to ensure it overrides any default values
@@ -275,3 +409,267 @@ func TestPostService(t *testing.T) {
 		assert.NotEqual(t, posts[0].ID, morePosts[0].ID)
 	})
 }
+
+func TestPostService_ContentFilter(t *testing.T) {
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	boardRepo := repository.NewBoardRepository(env.DB)
+	postRepo := repository.NewPostRepository(env.DB)
+	replyRepo := repository.NewReplyRepository(env.DB)
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
+
+	_, agent := createUserAndAgent(t, env)
+	board, err := boardService.CreateBoard(env.Ctx, agent.ID, "Filtered Board", "Test Description", true)
+	require.NoError(t, err)
+
+	blocklist := []string{"buy followers"}
+
+	t.Run("Reject", func(t *testing.T) {
+		filter := services.NewBlocklistFilter(blocklist, services.ContentFilterReject)
+		postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, replyRepo, env.AgentService, services.DefaultMaxPostLength, filter, nil, 0, nil)
+
+		_, err := postService.CreatePost(env.Ctx, board.ID, agent.ID, "You should BUY FOLLOWERS today", "")
+		assert.Equal(t, services.ErrContentBlocked, err)
+
+		post, err := postService.CreatePost(env.Ctx, board.ID, agent.ID, "A perfectly clean post", "")
+		require.NoError(t, err)
+		assert.False(t, post.IsFlagged)
+
+		// An edit can't bring blocked content in either
+		edited := *post
+		edited.Content = "Now you should buy followers"
+		assert.Equal(t, services.ErrContentBlocked, postService.UpdatePost(env.Ctx, &edited))
+
+		stored, err := postService.GetPostByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "A perfectly clean post", stored.Content)
+	})
+
+	t.Run("Flag", func(t *testing.T) {
+		filter := services.NewBlocklistFilter(blocklist, services.ContentFilterFlag)
+		postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, replyRepo, env.AgentService, services.DefaultMaxPostLength, filter, nil, 0, nil)
+
+		post, err := postService.CreatePost(env.Ctx, board.ID, agent.ID, "You should buy followers today", "")
+		require.NoError(t, err)
+
+		// The post is stored but flagged for moderation
+		stored, err := postService.GetPostByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+		assert.True(t, stored.IsFlagged)
+
+		// A clean post edited to match is flagged too
+		clean, err := postService.CreatePost(env.Ctx, board.ID, agent.ID, "Nothing to see here", "")
+		require.NoError(t, err)
+		clean.Content = "Nothing to see here, just buy followers"
+		require.NoError(t, postService.UpdatePost(env.Ctx, clean))
+
+		stored, err = postService.GetPostByID(env.Ctx, clean.ID)
+		require.NoError(t, err)
+		assert.True(t, stored.IsFlagged)
+	})
+}
+
+func TestPostService_ContentSanitizer(t *testing.T) {
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	boardRepo := repository.NewBoardRepository(env.DB)
+	postRepo := repository.NewPostRepository(env.DB)
+	replyRepo := repository.NewReplyRepository(env.DB)
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
+	sanitizer := services.NewHTMLSanitizer()
+	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, replyRepo, env.AgentService, services.DefaultMaxPostLength, nil, sanitizer, 0, nil)
+	replyService := services.NewReplyService(replyRepo, postRepo, boardRepo, env.AgentRepository, env.AgentService, services.DefaultMaxReplyLength, nil, sanitizer, 0, nil)
+
+	_, agent := createUserAndAgent(t, env)
+	board, err := boardService.CreateBoard(env.Ctx, agent.ID, "Sanitized Board", "Test Description", true)
+	require.NoError(t, err)
+
+	t.Run("Script tags are stripped before storing", func(t *testing.T) {
+		post, err := postService.CreatePost(env.Ctx, board.ID, agent.ID, "**Hello**<script>alert(1)</script> world", "")
+		require.NoError(t, err)
+
+		stored, err := postService.GetPostByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "**Hello** world", stored.Content)
+
+		reply, err := replyService.CreateReply(env.Ctx, string(models.ParentTypePost), post.ID, agent.ID, "[docs](https://example.com)<img src=x onerror=alert(1)>", "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, `[docs](https://example.com)<img src="x">`, reply.Content)
+	})
+
+	t.Run("Edits are sanitized too", func(t *testing.T) {
+		post, err := postService.CreatePost(env.Ctx, board.ID, agent.ID, "Original", "")
+		require.NoError(t, err)
+
+		post.Content = "Edited <script>alert(1)</script>"
+		require.NoError(t, postService.UpdatePost(env.Ctx, post))
+
+		stored, err := postService.GetPostByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Edited ", stored.Content)
+	})
+
+	t.Run("Content that is only script is empty", func(t *testing.T) {
+		_, err := postService.CreatePost(env.Ctx, board.ID, agent.ID, "<script>alert(1)</script>", "")
+		assert.Equal(t, services.ErrEmptyContent, err)
+	})
+}
+
+func TestPostService_BoardPostPolicy(t *testing.T) {
+	env, boardService, postService := setupPostTest(t)
+	defer env.Cleanup()
+
+	_, owner := createUserAndAgent(t, env)
+	_, other := createUserAndAgent(t, env)
+
+	board, err := boardService.CreateBoard(env.Ctx, owner.ID, "Policy Board", "Who may post", true)
+	require.NoError(t, err)
+	assert.Equal(t, models.BoardPostPolicyOpen, board.PostPolicy)
+
+	tests := []struct {
+		policy       string
+		ownerAllowed bool
+		otherAllowed bool
+	}{
+		{policy: models.BoardPostPolicyOpen, ownerAllowed: true, otherAllowed: true},
+		{policy: models.BoardPostPolicyOwnerOnly, ownerAllowed: true, otherAllowed: false},
+		{policy: models.BoardPostPolicyClosed, ownerAllowed: false, otherAllowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			updated, err := boardService.SetPostPolicy(env.Ctx, board.ID, services.AgentActor(owner.ID), tt.policy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.policy, updated.PostPolicy)
+
+			for _, attempt := range []struct {
+				agentID uuid.UUID
+				allowed bool
+			}{
+				{agentID: owner.ID, allowed: tt.ownerAllowed},
+				{agentID: other.ID, allowed: tt.otherAllowed},
+			} {
+				_, err := postService.CreatePost(env.Ctx, board.ID, attempt.agentID, "Policy test post", "")
+				if attempt.allowed {
+					assert.NoError(t, err)
+				} else {
+					assert.Equal(t, services.ErrBoardPostingDisabled, err)
+				}
+			}
+		})
+	}
+
+	t.Run("Only the owner can change the policy", func(t *testing.T) {
+		_, err := boardService.SetPostPolicy(env.Ctx, board.ID, services.AgentActor(other.ID), models.BoardPostPolicyOpen)
+		assert.Equal(t, services.ErrNotBoardOwner, err)
+
+		stored, err := boardService.GetBoardByID(env.Ctx, board.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.BoardPostPolicyClosed, stored.PostPolicy)
+	})
+
+	t.Run("Invalid policy", func(t *testing.T) {
+		_, err := boardService.SetPostPolicy(env.Ctx, board.ID, services.AgentActor(owner.ID), "members")
+		assert.Equal(t, services.ErrInvalidPostPolicy, err)
+	})
+}
+
+func TestPostService_DuplicatePost(t *testing.T) {
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	boardRepo := repository.NewBoardRepository(env.DB)
+	postRepo := repository.NewPostRepository(env.DB)
+	replyRepo := repository.NewReplyRepository(env.DB)
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
+	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, replyRepo, env.AgentService, services.DefaultMaxPostLength, nil, nil, time.Minute, nil)
+
+	_, agent := createUserAndAgent(t, env)
+	board, err := boardService.CreateBoard(env.Ctx, agent.ID, "Duplicate Board", "Test Description", true)
+	require.NoError(t, err)
+
+	first, err := postService.CreatePost(env.Ctx, board.ID, agent.ID, "Same content", "")
+	require.NoError(t, err)
+
+	// An immediate identical repost is rejected
+	_, err = postService.CreatePost(env.Ctx, board.ID, agent.ID, "Same content", "")
+	assert.Equal(t, services.ErrDuplicatePost, err)
+
+	// Different content goes through
+	_, err = postService.CreatePost(env.Ctx, board.ID, agent.ID, "Different content", "")
+	require.NoError(t, err)
+
+	// Only the latest post is compared, so the earlier content may be posted again
+	_, err = postService.CreatePost(env.Ctx, board.ID, agent.ID, "Same content", "")
+	require.NoError(t, err)
+
+	// Once the window has passed the same content is accepted
+	_, err = env.DB.Exec(`UPDATE posts SET created_at = $1 WHERE board_id = $2`, time.Now().Add(-time.Hour), board.ID)
+	require.NoError(t, err)
+	_, err = postService.CreatePost(env.Ctx, board.ID, agent.ID, "Same content", "")
+	require.NoError(t, err)
+
+	stored, err := postService.GetPostByID(env.Ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Same content", stored.Content)
+}
+
+func TestPostService_DeletePostHidesReplies(t *testing.T) {
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	boardRepo := repository.NewBoardRepository(env.DB)
+	postRepo := repository.NewPostRepository(env.DB)
+	replyRepo := repository.NewReplyRepository(env.DB)
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
+	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, replyRepo, env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, nil)
+
+	_, agent := createUserAndAgent(t, env)
+	board, err := boardService.CreateBoard(env.Ctx, agent.ID, "Thread Board", "Test Description", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, agent.ID, "Post with a thread", "")
+	require.NoError(t, err)
+
+	top := utils.CreateTestReply(t, env, agent.ID, post.ID)
+	removed := utils.CreateTestReply(t, env, agent.ID, post.ID)
+	nested := models.NewReply(string(models.ParentTypeReply), top.ID, agent.ID, "Nested reply", nil)
+	_, err = env.DB.Exec(
+		`INSERT INTO replies (id, parent_type, parent_id, agent_id, content, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		nested.ID, nested.ParentType, nested.ParentID, nested.AgentID, nested.Content, nested.CreatedAt, nested.UpdatedAt,
+	)
+	require.NoError(t, err)
+
+	// A reply deleted on its own before the post
+	require.NoError(t, replyRepo.Delete(env.Ctx, removed.ID))
+
+	thread, err := replyRepo.GetThreadedReplies(env.Ctx, post.ID)
+	require.NoError(t, err)
+	require.Len(t, thread, 2)
+
+	// Deleting the post takes the rest of its thread with it
+	require.NoError(t, postService.DeletePost(env.Ctx, post.ID))
+
+	thread, err = replyRepo.GetThreadedReplies(env.Ctx, post.ID)
+	require.NoError(t, err)
+	assert.Empty(t, thread)
+	for _, id := range []uuid.UUID{top.ID, nested.ID} {
+		reply, err := replyRepo.GetByID(env.Ctx, id)
+		require.NoError(t, err)
+		assert.Nil(t, reply)
+	}
+
+	// Restoring the post brings back only the replies deleted with it
+	require.NoError(t, postService.RestorePost(env.Ctx, post.ID))
+
+	thread, err = replyRepo.GetThreadedReplies(env.Ctx, post.ID)
+	require.NoError(t, err)
+	require.Len(t, thread, 2)
+	assert.Equal(t, top.ID, thread[0].ID)
+	assert.Equal(t, nested.ID, thread[1].ID)
+
+	reply, err := replyRepo.GetByID(env.Ctx, removed.ID)
+	require.NoError(t, err)
+	assert.Nil(t, reply)
+}
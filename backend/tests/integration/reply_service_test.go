@@ -1,7 +1,9 @@
 package integration
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/garrettallen/aiboards/backend/internal/database/repository"
 	"github.com/garrettallen/aiboards/backend/internal/models"
@@ -21,9 +23,9 @@ func setupReplyTest(t *testing.T) (*utils.TestEnv, services.BoardService, servic
 	replyRepo := repository.NewReplyRepository(env.DB)
 
 	// Create services
-	boardService := services.NewBoardService(boardRepo, env.AgentRepository)
-	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, env.AgentService)
-	replyService := services.NewReplyService(replyRepo, postRepo, env.AgentRepository, env.AgentService)
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
+	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, replyRepo, env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, nil)
+	replyService := services.NewReplyService(replyRepo, postRepo, boardRepo, env.AgentRepository, env.AgentService, services.DefaultMaxReplyLength, nil, nil, 0, nil)
 
 	return env, boardService, postService, replyService
 }
@@ -66,7 +68,7 @@ func TestReplyService(t *testing.T) {
 		content := "Test Reply to Post"
 		mediaURL := ""
 		
-		reply, err := replyService.CreateReply(env.Ctx, parentType, postID, agentID, content, mediaURL)
+		reply, err := replyService.CreateReply(env.Ctx, parentType, postID, agentID, content, mediaURL, nil)
 		require.NoError(t, err)
 		assert.NotNil(t, reply)
 		assert.Equal(t, parentType, reply.ParentType)
@@ -84,7 +86,7 @@ func TestReplyService(t *testing.T) {
 	t.Run("GetReplyByID", func(t *testing.T) {
 		// Create a reply
 		parentType := string(models.ParentTypePost)
-		reply, err := replyService.CreateReply(env.Ctx, parentType, postID, agentID, "Test Get Reply", "")
+		reply, err := replyService.CreateReply(env.Ctx, parentType, postID, agentID, "Test Get Reply", "", nil)
 		require.NoError(t, err)
 
 		// Get the reply by ID
@@ -108,7 +110,7 @@ func TestReplyService(t *testing.T) {
 	t.Run("UpdateReply", func(t *testing.T) {
 		// Create a reply
 		parentType := string(models.ParentTypePost)
-		reply, err := replyService.CreateReply(env.Ctx, parentType, postID, agentID, "Original Content", "")
+		reply, err := replyService.CreateReply(env.Ctx, parentType, postID, agentID, "Original Content", "", nil)
 		require.NoError(t, err)
 
 		// Update the reply
@@ -116,7 +118,7 @@ func TestReplyService(t *testing.T) {
 		mediaURL := "https://example.com/image.jpg"
 		reply.MediaURL = &mediaURL
 
-		err = replyService.UpdateReply(env.Ctx, reply)
+		err = replyService.UpdateReply(env.Ctx, reply, false)
 		require.NoError(t, err)
 
 		// Get the updated reply
@@ -127,10 +129,33 @@ func TestReplyService(t *testing.T) {
 		assert.Equal(t, mediaURL, *updatedReply.MediaURL)
 	})
 
+	t.Run("CreateReply_ContentLength", func(t *testing.T) {
+		parentType := string(models.ParentTypePost)
+
+		// Exactly the maximum length is allowed
+		maxContent := strings.Repeat("a", services.DefaultMaxReplyLength)
+		reply, err := replyService.CreateReply(env.Ctx, parentType, postID, agentID, maxContent, "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, maxContent, reply.Content)
+
+		// One character over is rejected
+		_, err = replyService.CreateReply(env.Ctx, parentType, postID, agentID, maxContent+"a", "", nil)
+		assert.Equal(t, services.ErrContentTooLong, err)
+
+		// Updates are held to the same limit
+		reply.Content = maxContent + "a"
+		err = replyService.UpdateReply(env.Ctx, reply, false)
+		assert.Equal(t, services.ErrContentTooLong, err)
+
+		// Whitespace-only content is rejected
+		_, err = replyService.CreateReply(env.Ctx, parentType, postID, agentID, "   ", "", nil)
+		assert.Equal(t, services.ErrEmptyContent, err)
+	})
+
 	t.Run("DeleteReply", func(t *testing.T) {
 		// Create a reply
 		parentType := string(models.ParentTypePost)
-		reply, err := replyService.CreateReply(env.Ctx, parentType, postID, agentID, "Reply to Delete", "")
+		reply, err := replyService.CreateReply(env.Ctx, parentType, postID, agentID, "Reply to Delete", "", nil)
 		require.NoError(t, err)
 
 		// Delete the reply
@@ -146,12 +171,12 @@ func TestReplyService(t *testing.T) {
 	t.Run("CreateReply_ToReply", func(t *testing.T) {
 		// Create a parent reply
 		parentType := string(models.ParentTypePost)
-		parentReply, err := replyService.CreateReply(env.Ctx, parentType, postID, agentID, "Parent Reply", "")
+		parentReply, err := replyService.CreateReply(env.Ctx, parentType, postID, agentID, "Parent Reply", "", nil)
 		require.NoError(t, err)
 
 		// Create a reply to the reply
 		replyParentType := string(models.ParentTypeReply)
-		reply, err := replyService.CreateReply(env.Ctx, replyParentType, parentReply.ID, agentID, "Reply to Reply", "")
+		reply, err := replyService.CreateReply(env.Ctx, replyParentType, parentReply.ID, agentID, "Reply to Reply", "", nil)
 		require.NoError(t, err)
 		assert.Equal(t, replyParentType, reply.ParentType)
 		assert.Equal(t, parentReply.ID, reply.ParentID)
@@ -161,38 +186,72 @@ func TestReplyService(t *testing.T) {
 		// Create multiple replies for a post
 		parentType := string(models.ParentTypePost)
 		for i := 0; i < 5; i++ {
-			_, err := replyService.CreateReply(env.Ctx, parentType, postID, agentID, "Post Reply", "")
+			_, err := replyService.CreateReply(env.Ctx, parentType, postID, agentID, "Post Reply", "", nil)
 			require.NoError(t, err)
 		}
 
 		// Get replies with pagination
-		replies, count, err := replyService.GetRepliesByParentID(env.Ctx, parentType, postID, 1, 3)
+		replies, count, err := replyService.GetRepliesByParentID(env.Ctx, parentType, postID, "", 1, 3)
 		require.NoError(t, err)
 		assert.Len(t, replies, 3)
 		assert.GreaterOrEqual(t, count, 5)
 
 		// Get next page
-		moreReplies, _, err := replyService.GetRepliesByParentID(env.Ctx, parentType, postID, 2, 3)
+		moreReplies, _, err := replyService.GetRepliesByParentID(env.Ctx, parentType, postID, "", 2, 3)
 		require.NoError(t, err)
 		assert.NotEmpty(t, moreReplies)
 	})
 
+	t.Run("CountRepliesByParentID_AfterSoftDelete", func(t *testing.T) {
+		// Create a fresh post so earlier subtests don't affect the count
+		countPost, err := postService.CreatePost(env.Ctx, boardID, agentID, "Count Post", "")
+		require.NoError(t, err)
+
+		parentType := string(models.ParentTypePost)
+		var created []*models.Reply
+		for i := 0; i < 3; i++ {
+			reply, err := replyService.CreateReply(env.Ctx, parentType, countPost.ID, agentID, "Countable Reply", "", nil)
+			require.NoError(t, err)
+			created = append(created, reply)
+		}
+
+		// Soft delete one of the replies
+		err = replyService.DeleteReply(env.Ctx, created[0].ID)
+		require.NoError(t, err)
+
+		// Count via the service
+		count, err := replyService.CountRepliesByParentID(env.Ctx, parentType, countPost.ID)
+		require.NoError(t, err)
+
+		// Compare against the actual non-deleted rows
+		var actual int
+		err = env.DB.Get(&actual, `SELECT COUNT(*) FROM replies WHERE parent_type = $1 AND parent_id = $2 AND deleted_at IS NULL`, parentType, countPost.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.Equal(t, actual, count)
+	})
+
+	t.Run("CountRepliesByParentID_InvalidParentType", func(t *testing.T) {
+		_, err := replyService.CountRepliesByParentID(env.Ctx, "board", postID)
+		assert.Equal(t, services.ErrInvalidParentType, err)
+	})
+
 	t.Run("GetRepliesByAgentID", func(t *testing.T) {
 		// Create multiple replies for the agent
 		parentType := string(models.ParentTypePost)
 		for i := 0; i < 5; i++ {
-			_, err := replyService.CreateReply(env.Ctx, parentType, postID, agentID, "Agent Reply", "")
+			_, err := replyService.CreateReply(env.Ctx, parentType, postID, agentID, "Agent Reply", "", nil)
 			require.NoError(t, err)
 		}
 
 		// Get replies with pagination
-		replies, count, err := replyService.GetRepliesByAgentID(env.Ctx, agentID, 1, 3)
+		replies, count, err := replyService.GetRepliesByAgentID(env.Ctx, agentID, 1, 3, true)
 		require.NoError(t, err)
 		assert.Len(t, replies, 3)
 		assert.GreaterOrEqual(t, count, 5)
 
 		// Get next page
-		moreReplies, _, err := replyService.GetRepliesByAgentID(env.Ctx, agentID, 2, 3)
+		moreReplies, _, err := replyService.GetRepliesByAgentID(env.Ctx, agentID, 2, 3, true)
 		require.NoError(t, err)
 		assert.NotEmpty(t, moreReplies)
 	})
@@ -204,18 +263,18 @@ func TestReplyService(t *testing.T) {
 
 		// Create parent replies
 		parentType := string(models.ParentTypePost)
-		parentReply1, err := replyService.CreateReply(env.Ctx, parentType, newPost.ID, agentID, "Parent Reply 1", "")
+		parentReply1, err := replyService.CreateReply(env.Ctx, parentType, newPost.ID, agentID, "Parent Reply 1", "", nil)
 		require.NoError(t, err)
-		parentReply2, err := replyService.CreateReply(env.Ctx, parentType, newPost.ID, agentID, "Parent Reply 2", "")
+		parentReply2, err := replyService.CreateReply(env.Ctx, parentType, newPost.ID, agentID, "Parent Reply 2", "", nil)
 		require.NoError(t, err)
-		_, err = replyService.CreateReply(env.Ctx, parentType, newPost.ID, agentID, "Parent Reply 3", "")
+		_, err = replyService.CreateReply(env.Ctx, parentType, newPost.ID, agentID, "Parent Reply 3", "", nil)
 		require.NoError(t, err)
 
 		// Create child replies
 		replyParentType := string(models.ParentTypeReply)
-		_, err = replyService.CreateReply(env.Ctx, replyParentType, parentReply1.ID, agentID, "Child of Reply 1", "")
+		_, err = replyService.CreateReply(env.Ctx, replyParentType, parentReply1.ID, agentID, "Child of Reply 1", "", nil)
 		require.NoError(t, err)
-		_, err = replyService.CreateReply(env.Ctx, replyParentType, parentReply2.ID, agentID, "Child of Reply 2", "")
+		_, err = replyService.CreateReply(env.Ctx, replyParentType, parentReply2.ID, agentID, "Child of Reply 2", "", nil)
 		require.NoError(t, err)
 
 		// Get threaded replies
@@ -228,7 +287,7 @@ func TestReplyService(t *testing.T) {
 	t.Run("CreateReply_InvalidParent", func(t *testing.T) {
 		// Try to create a reply with a non-existent parent
 		parentType := string(models.ParentTypePost)
-		_, err := replyService.CreateReply(env.Ctx, parentType, uuid.New(), agentID, "Invalid Parent Reply", "")
+		_, err := replyService.CreateReply(env.Ctx, parentType, uuid.New(), agentID, "Invalid Parent Reply", "", nil)
 		assert.Error(t, err)
 		assert.Equal(t, services.ErrPostNotFound, err)
 	})
@@ -236,8 +295,477 @@ func TestReplyService(t *testing.T) {
 	t.Run("CreateReply_InvalidAgent", func(t *testing.T) {
 		// Try to create a reply with a non-existent agent
 		parentType := string(models.ParentTypePost)
-		_, err := replyService.CreateReply(env.Ctx, parentType, postID, uuid.New(), "Invalid Agent Reply", "")
+		_, err := replyService.CreateReply(env.Ctx, parentType, postID, uuid.New(), "Invalid Agent Reply", "", nil)
 		assert.Error(t, err)
 		assert.Equal(t, services.ErrAgentNotFound, err)
 	})
 }
+
+func TestReplyService_MarkAccepted(t *testing.T) {
+	env, boardService, postService, replyService := setupReplyTest(t)
+	defer env.Cleanup()
+
+	// The post author and another agent replying to it
+	_, author := createTestUserAndAgent(t, env)
+	_, other := createTestUserAndAgent(t, env)
+
+	board, err := boardService.CreateBoard(env.Ctx, author.ID, "Q&A Board", "Questions", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, author.ID, "How do I do this?", "")
+	require.NoError(t, err)
+
+	parentType := string(models.ParentTypePost)
+	first, err := replyService.CreateReply(env.Ctx, parentType, post.ID, other.ID, "First answer", "", nil)
+	require.NoError(t, err)
+	second, err := replyService.CreateReply(env.Ctx, parentType, post.ID, other.ID, "Second answer", "", nil)
+	require.NoError(t, err)
+
+	t.Run("Only the post author can accept", func(t *testing.T) {
+		_, err := replyService.MarkAccepted(env.Ctx, first.ID, other.ID)
+		assert.Equal(t, services.ErrNotPostAuthor, err)
+
+		reply, err := replyService.GetReplyByID(env.Ctx, first.ID)
+		require.NoError(t, err)
+		assert.False(t, reply.IsAccepted)
+	})
+
+	t.Run("Nested replies cannot be accepted", func(t *testing.T) {
+		nested, err := replyService.CreateReply(env.Ctx, string(models.ParentTypeReply), first.ID, other.ID, "Nested", "", nil)
+		require.NoError(t, err)
+
+		_, err = replyService.MarkAccepted(env.Ctx, nested.ID, author.ID)
+		assert.Equal(t, services.ErrReplyNotAcceptable, err)
+	})
+
+	t.Run("Reply not found", func(t *testing.T) {
+		_, err := replyService.MarkAccepted(env.Ctx, uuid.New(), author.ID)
+		assert.Equal(t, services.ErrReplyNotFound, err)
+	})
+
+	t.Run("Accepting a new reply unmarks the old one", func(t *testing.T) {
+		accepted, err := replyService.MarkAccepted(env.Ctx, first.ID, author.ID)
+		require.NoError(t, err)
+		assert.True(t, accepted.IsAccepted)
+
+		_, err = replyService.MarkAccepted(env.Ctx, second.ID, author.ID)
+		require.NoError(t, err)
+
+		reply, err := replyService.GetReplyByID(env.Ctx, first.ID)
+		require.NoError(t, err)
+		assert.False(t, reply.IsAccepted)
+		reply, err = replyService.GetReplyByID(env.Ctx, second.ID)
+		require.NoError(t, err)
+		assert.True(t, reply.IsAccepted)
+
+		// The accepted reply is listed first in the thread
+		threaded, err := replyService.GetThreadedReplies(env.Ctx, post.ID)
+		require.NoError(t, err)
+		require.NotEmpty(t, threaded)
+		assert.Equal(t, second.ID, threaded[0].ID)
+		acceptedCount := 0
+		for _, r := range threaded {
+			if r.IsAccepted {
+				acceptedCount++
+			}
+		}
+		assert.Equal(t, 1, acceptedCount)
+	})
+
+	t.Run("The database allows one accepted reply per post", func(t *testing.T) {
+		_, err := env.DB.Exec(`UPDATE replies SET is_accepted = TRUE WHERE id = $1`, first.ID)
+		assert.Error(t, err)
+	})
+}
+
+func TestReplyService_QuotedReply(t *testing.T) {
+	env, boardService, postService, replyService := setupReplyTest(t)
+	defer env.Cleanup()
+
+	_, agent := createTestUserAndAgent(t, env)
+
+	board, err := boardService.CreateBoard(env.Ctx, agent.ID, "Quote Board", "Quoting", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, agent.ID, "Original post", "")
+	require.NoError(t, err)
+	otherPost, err := postService.CreatePost(env.Ctx, board.ID, agent.ID, "Another post", "")
+	require.NoError(t, err)
+
+	parentType := string(models.ParentTypePost)
+	sibling, err := replyService.CreateReply(env.Ctx, parentType, post.ID, agent.ID, "Sibling reply", "", nil)
+	require.NoError(t, err)
+	otherReply, err := replyService.CreateReply(env.Ctx, parentType, otherPost.ID, agent.ID, "Reply elsewhere", "", nil)
+	require.NoError(t, err)
+
+	t.Run("Quoting a sibling reply", func(t *testing.T) {
+		reply, err := replyService.CreateReply(env.Ctx, parentType, post.ID, agent.ID, "Quoting my sibling", "", &sibling.ID)
+		require.NoError(t, err)
+		require.NotNil(t, reply.QuotedReplyID)
+		assert.Equal(t, sibling.ID, *reply.QuotedReplyID)
+
+		stored, err := replyService.GetReplyByID(env.Ctx, reply.ID)
+		require.NoError(t, err)
+		require.NotNil(t, stored.QuotedReplyID)
+		assert.Equal(t, sibling.ID, *stored.QuotedReplyID)
+
+		threaded, err := replyService.GetThreadedReplies(env.Ctx, post.ID)
+		require.NoError(t, err)
+		found := false
+		for _, r := range threaded {
+			if r.ID == reply.ID {
+				found = true
+				require.NotNil(t, r.QuotedReplyID)
+				assert.Equal(t, sibling.ID, *r.QuotedReplyID)
+			}
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("Nested reply quoting a reply higher up the thread", func(t *testing.T) {
+		child, err := replyService.CreateReply(env.Ctx, string(models.ParentTypeReply), sibling.ID, agent.ID, "Child", "", nil)
+		require.NoError(t, err)
+
+		reply, err := replyService.CreateReply(env.Ctx, string(models.ParentTypeReply), child.ID, agent.ID, "Quoting the top", "", &sibling.ID)
+		require.NoError(t, err)
+		require.NotNil(t, reply.QuotedReplyID)
+		assert.Equal(t, sibling.ID, *reply.QuotedReplyID)
+	})
+
+	t.Run("Quoted reply from another post", func(t *testing.T) {
+		_, err := replyService.CreateReply(env.Ctx, parentType, post.ID, agent.ID, "Cross-thread quote", "", &otherReply.ID)
+		assert.Equal(t, services.ErrQuotedReplyOtherThread, err)
+	})
+
+	t.Run("Quoted reply not found", func(t *testing.T) {
+		missing := uuid.New()
+		_, err := replyService.CreateReply(env.Ctx, parentType, post.ID, agent.ID, "Quoting nothing", "", &missing)
+		assert.Equal(t, services.ErrQuotedReplyNotFound, err)
+	})
+}
+
+func TestBoardArchival(t *testing.T) {
+	env, boardService, postService, replyService := setupReplyTest(t)
+	defer env.Cleanup()
+
+	_, owner := createTestUserAndAgent(t, env)
+	_, other := createTestUserAndAgent(t, env)
+
+	board, err := boardService.CreateBoard(env.Ctx, owner.ID, "Archive Board", "Soon read-only", true)
+	require.NoError(t, err)
+	assert.False(t, board.IsArchived)
+
+	post, err := postService.CreatePost(env.Ctx, board.ID, owner.ID, "Archived searchable post", "")
+	require.NoError(t, err)
+	reply, err := replyService.CreateReply(env.Ctx, "post", post.ID, other.ID, "Reply before archiving", "", nil)
+	require.NoError(t, err)
+
+	t.Run("Only the owner can archive", func(t *testing.T) {
+		_, err := boardService.ArchiveBoard(env.Ctx, board.ID, services.AgentActor(other.ID))
+		assert.Equal(t, services.ErrNotBoardOwner, err)
+	})
+
+	archived, err := boardService.ArchiveBoard(env.Ctx, board.ID, services.AgentActor(owner.ID))
+	require.NoError(t, err)
+	assert.True(t, archived.IsArchived)
+
+	t.Run("Reads still work", func(t *testing.T) {
+		stored, err := boardService.GetBoardByID(env.Ctx, board.ID)
+		require.NoError(t, err)
+		assert.True(t, stored.IsArchived)
+		assert.True(t, stored.IsActive)
+
+		_, err = postService.GetPostByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+
+		posts, total, err := postService.GetPostsByBoardID(env.Ctx, board.ID, 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Len(t, posts, 1)
+
+		posts, total, err = postService.SearchPosts(env.Ctx, board.ID, "searchable", 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Len(t, posts, 1)
+
+		replies, err := replyService.GetThreadedReplies(env.Ctx, post.ID)
+		require.NoError(t, err)
+		assert.Len(t, replies, 1)
+	})
+
+	t.Run("Writes are blocked", func(t *testing.T) {
+		_, err := postService.CreatePost(env.Ctx, board.ID, owner.ID, "Post after archiving", "")
+		assert.Equal(t, services.ErrBoardArchived, err)
+
+		_, err = replyService.CreateReply(env.Ctx, "post", post.ID, other.ID, "Reply after archiving", "", nil)
+		assert.Equal(t, services.ErrBoardArchived, err)
+
+		_, err = replyService.CreateReply(env.Ctx, "reply", reply.ID, other.ID, "Nested reply after archiving", "", nil)
+		assert.Equal(t, services.ErrBoardArchived, err)
+	})
+
+	t.Run("Unarchiving restores posting", func(t *testing.T) {
+		unarchived, err := boardService.UnarchiveBoard(env.Ctx, board.ID, services.AgentActor(owner.ID))
+		require.NoError(t, err)
+		assert.False(t, unarchived.IsArchived)
+
+		_, err = postService.CreatePost(env.Ctx, board.ID, owner.ID, "Post after unarchiving", "")
+		assert.NoError(t, err)
+
+		_, err = replyService.CreateReply(env.Ctx, "reply", reply.ID, other.ID, "Nested reply after unarchiving", "", nil)
+		assert.NoError(t, err)
+	})
+}
+
+func TestPostLocking(t *testing.T) {
+	env, boardService, postService, replyService := setupReplyTest(t)
+	defer env.Cleanup()
+
+	_, owner := createTestUserAndAgent(t, env)
+	_, other := createTestUserAndAgent(t, env)
+	_, adminUserID := utils.CreateAdminUserAndGetToken(t, env)
+	admin := env.CreateTestAgent(adminUserID)
+
+	board, err := boardService.CreateBoard(env.Ctx, owner.ID, "Lock Board", "Heated debates", true)
+	require.NoError(t, err)
+
+	post, err := postService.CreatePost(env.Ctx, board.ID, other.ID, "A heated post", "")
+	require.NoError(t, err)
+	assert.False(t, post.IsLocked)
+	reply, err := replyService.CreateReply(env.Ctx, "post", post.ID, other.ID, "Reply before locking", "", nil)
+	require.NoError(t, err)
+
+	t.Run("Only the board owner or an admin can lock", func(t *testing.T) {
+		_, err := postService.SetLocked(env.Ctx, post.ID, services.AgentActor(other.ID), true)
+		assert.Equal(t, services.ErrNotPostModerator, err)
+
+		_, err = postService.SetLocked(env.Ctx, uuid.New(), services.AgentActor(owner.ID), true)
+		assert.Equal(t, services.ErrPostNotFound, err)
+	})
+
+	locked, err := postService.SetLocked(env.Ctx, post.ID, services.AgentActor(owner.ID), true)
+	require.NoError(t, err)
+	assert.True(t, locked.IsLocked)
+
+	t.Run("Replies are blocked", func(t *testing.T) {
+		_, err := replyService.CreateReply(env.Ctx, "post", post.ID, other.ID, "Reply after locking", "", nil)
+		assert.Equal(t, services.ErrPostLocked, err)
+
+		_, err = replyService.CreateReply(env.Ctx, "reply", reply.ID, other.ID, "Nested reply after locking", "", nil)
+		assert.Equal(t, services.ErrPostLocked, err)
+
+		replies, err := replyService.GetThreadedReplies(env.Ctx, post.ID)
+		require.NoError(t, err)
+		assert.Len(t, replies, 1)
+	})
+
+	t.Run("Unlocking restores replies", func(t *testing.T) {
+		unlocked, err := postService.SetLocked(env.Ctx, post.ID, services.AgentActor(admin.ID), false)
+		require.NoError(t, err)
+		assert.False(t, unlocked.IsLocked)
+
+		stored, err := postService.GetPostByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+		assert.False(t, stored.IsLocked)
+
+		_, err = replyService.CreateReply(env.Ctx, "reply", reply.ID, other.ID, "Nested reply after unlocking", "", nil)
+		assert.NoError(t, err)
+	})
+}
+
+func TestBlockedAgentRepliesHidden(t *testing.T) {
+	env, boardService, postService, replyService := setupReplyTest(t)
+	defer env.Cleanup()
+
+	_, viewer := createTestUserAndAgent(t, env)
+	_, blocked := createTestUserAndAgent(t, env)
+	_, other := createTestUserAndAgent(t, env)
+
+	board, err := boardService.CreateBoard(env.Ctx, viewer.ID, "Block Board", "Testing blocks", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, other.ID, "A post", "")
+	require.NoError(t, err)
+
+	visible, err := replyService.CreateReply(env.Ctx, "post", post.ID, other.ID, "Visible reply", "", nil)
+	require.NoError(t, err)
+	hidden, err := replyService.CreateReply(env.Ctx, "post", post.ID, blocked.ID, "Reply from a blocked agent", "", nil)
+	require.NoError(t, err)
+	_, err = replyService.CreateReply(env.Ctx, "reply", hidden.ID, other.ID, "Answer to the blocked agent", "", nil)
+	require.NoError(t, err)
+
+	t.Run("Invalid blocks are rejected", func(t *testing.T) {
+		assert.Equal(t, services.ErrCannotBlockSelf, env.AgentService.BlockAgent(env.Ctx, viewer.ID, viewer.ID))
+		assert.Equal(t, services.ErrAgentNotFound, env.AgentService.BlockAgent(env.Ctx, viewer.ID, uuid.New()))
+		assert.Equal(t, services.ErrBlockNotFound, env.AgentService.UnblockAgent(env.Ctx, viewer.ID, blocked.ID))
+	})
+
+	require.NoError(t, env.AgentService.BlockAgent(env.Ctx, viewer.ID, blocked.ID))
+	// Blocking twice is not an error
+	require.NoError(t, env.AgentService.BlockAgent(env.Ctx, viewer.ID, blocked.ID))
+
+	t.Run("Blocked agents are listed", func(t *testing.T) {
+		agents, err := env.AgentService.ListBlocked(env.Ctx, viewer.ID)
+		require.NoError(t, err)
+		require.Len(t, agents, 1)
+		assert.Equal(t, blocked.ID, agents[0].ID)
+	})
+
+	t.Run("The blocker's thread view hides the blocked agent's replies", func(t *testing.T) {
+		replies, err := replyService.GetThreadedReplies(env.Ctx, post.ID)
+		require.NoError(t, err)
+		require.Len(t, replies, 3)
+
+		filtered, err := replyService.FilterBlockedReplies(env.Ctx, viewer.ID, replies)
+		require.NoError(t, err)
+		require.Len(t, filtered, 1)
+		assert.Equal(t, visible.ID, filtered[0].ID)
+
+		// Other agents still see the whole thread
+		unfiltered, err := replyService.FilterBlockedReplies(env.Ctx, other.ID, replies)
+		require.NoError(t, err)
+		assert.Len(t, unfiltered, 3)
+	})
+
+	t.Run("The blocker's post list hides the blocked agent's posts", func(t *testing.T) {
+		_, err := postService.CreatePost(env.Ctx, board.ID, blocked.ID, "A post from a blocked agent", "")
+		require.NoError(t, err)
+
+		posts, _, err := postService.GetPostsByBoardID(env.Ctx, board.ID, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, posts, 2)
+
+		filtered, err := postService.FilterBlockedPosts(env.Ctx, viewer.ID, posts)
+		require.NoError(t, err)
+		require.Len(t, filtered, 1)
+		assert.Equal(t, post.ID, filtered[0].ID)
+	})
+
+	t.Run("Unblocking restores the replies", func(t *testing.T) {
+		require.NoError(t, env.AgentService.UnblockAgent(env.Ctx, viewer.ID, blocked.ID))
+
+		replies, err := replyService.GetThreadedReplies(env.Ctx, post.ID)
+		require.NoError(t, err)
+		filtered, err := replyService.FilterBlockedReplies(env.Ctx, viewer.ID, replies)
+		require.NoError(t, err)
+		assert.Len(t, filtered, 3)
+	})
+}
+
+func TestReplyService_GetAncestors(t *testing.T) {
+	env, boardService, postService, replyService := setupReplyTest(t)
+	defer env.Cleanup()
+
+	_, agent := createTestUserAndAgent(t, env)
+
+	board, err := boardService.CreateBoard(env.Ctx, agent.ID, "Ancestor Board", "Deep threads", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, agent.ID, "Root post", "")
+	require.NoError(t, err)
+
+	// A three-level chain: post <- first <- second <- third
+	first, err := replyService.CreateReply(env.Ctx, "post", post.ID, agent.ID, "First level", "", nil)
+	require.NoError(t, err)
+	second, err := replyService.CreateReply(env.Ctx, "reply", first.ID, agent.ID, "Second level", "", nil)
+	require.NoError(t, err)
+	third, err := replyService.CreateReply(env.Ctx, "reply", second.ID, agent.ID, "Third level", "", nil)
+	require.NoError(t, err)
+
+	t.Run("The chain comes back in order ending at the post", func(t *testing.T) {
+		ancestors, err := replyService.GetAncestors(env.Ctx, third.ID)
+		require.NoError(t, err)
+
+		require.Len(t, ancestors.Replies, 3)
+		assert.Equal(t, third.ID, ancestors.Replies[0].ID)
+		assert.Equal(t, second.ID, ancestors.Replies[1].ID)
+		assert.Equal(t, first.ID, ancestors.Replies[2].ID)
+		require.NotNil(t, ancestors.Post)
+		assert.Equal(t, post.ID, ancestors.Post.ID)
+	})
+
+	t.Run("A top-level reply has only the post above it", func(t *testing.T) {
+		ancestors, err := replyService.GetAncestors(env.Ctx, first.ID)
+		require.NoError(t, err)
+
+		require.Len(t, ancestors.Replies, 1)
+		assert.Equal(t, first.ID, ancestors.Replies[0].ID)
+		assert.Equal(t, post.ID, ancestors.Post.ID)
+	})
+
+	t.Run("Unknown reply", func(t *testing.T) {
+		_, err := replyService.GetAncestors(env.Ctx, uuid.New())
+		assert.Equal(t, services.ErrReplyNotFound, err)
+	})
+}
+
+func TestUpdateReply_EditWindow(t *testing.T) {
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	boardRepo := repository.NewBoardRepository(env.DB)
+	postRepo := repository.NewPostRepository(env.DB)
+	replyRepo := repository.NewReplyRepository(env.DB)
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
+	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, replyRepo, env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, nil)
+	replyService := services.NewReplyService(replyRepo, postRepo, boardRepo, env.AgentRepository, env.AgentService, services.DefaultMaxReplyLength, nil, nil, 15*time.Minute, nil)
+
+	_, agent := createTestUserAndAgent(t, env)
+	board, err := boardService.CreateBoard(env.Ctx, agent.ID, "Edit Window Board", "Test Description", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, agent.ID, "Edit Window Post", "")
+	require.NoError(t, err)
+
+	reply, err := replyService.CreateReply(env.Ctx, string(models.ParentTypePost), post.ID, agent.ID, "Original Content", "", nil)
+	require.NoError(t, err)
+
+	// Within the window the author can edit
+	reply.Content = "Edited in time"
+	require.NoError(t, replyService.UpdateReply(env.Ctx, reply, false))
+
+	// Move the reply past the window
+	_, err = env.DB.Exec(`UPDATE replies SET created_at = $1 WHERE id = $2`, time.Now().Add(-time.Hour), reply.ID)
+	require.NoError(t, err)
+
+	reply.Content = "Edited too late"
+	err = replyService.UpdateReply(env.Ctx, reply, false)
+	assert.Equal(t, services.ErrEditWindowExpired, err)
+
+	stored, err := replyService.GetReplyByID(env.Ctx, reply.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Edited in time", stored.Content)
+
+	// Admins are exempt
+	reply.Content = "Edited by an admin"
+	require.NoError(t, replyService.UpdateReply(env.Ctx, reply, true))
+
+	stored, err = replyService.GetReplyByID(env.Ctx, reply.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Edited by an admin", stored.Content)
+}
+
+func TestUpdateReply_ContentFilter(t *testing.T) {
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	boardRepo := repository.NewBoardRepository(env.DB)
+	postRepo := repository.NewPostRepository(env.DB)
+	replyRepo := repository.NewReplyRepository(env.DB)
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
+	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, replyRepo, env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, nil)
+	filter := services.NewBlocklistFilter([]string{"buy followers"}, services.ContentFilterReject)
+	replyService := services.NewReplyService(replyRepo, postRepo, boardRepo, env.AgentRepository, env.AgentService, services.DefaultMaxReplyLength, filter, nil, 0, nil)
+
+	_, agent := createTestUserAndAgent(t, env)
+	board, err := boardService.CreateBoard(env.Ctx, agent.ID, "Filtered Board", "Test Description", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, agent.ID, "Filtered Post", "")
+	require.NoError(t, err)
+
+	reply, err := replyService.CreateReply(env.Ctx, string(models.ParentTypePost), post.ID, agent.ID, "A clean reply", "", nil)
+	require.NoError(t, err)
+
+	// Blocked content is rejected on edit as it is on create
+	reply.Content = "Now you should buy followers"
+	assert.Equal(t, services.ErrContentBlocked, replyService.UpdateReply(env.Ctx, reply, false))
+
+	stored, err := replyService.GetReplyByID(env.Ctx, reply.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "A clean reply", stored.Content)
+}
@@ -1,8 +1,12 @@
 package integration
 
 import (
+	"sync"
 	"testing"
 
+	"github.com/google/uuid"
+
+	"github.com/garrettallen/aiboards/backend/internal/services"
 	"github.com/garrettallen/aiboards/backend/tests/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -124,3 +128,57 @@ func TestDeleteUser_Integration(t *testing.T) {
 	require.NoError(t, err)
 	assert.Nil(t, deletedUser, "User should be soft-deleted")
 }
+
+func TestUpdateUser_ConcurrentAdminDemotion_Integration(t *testing.T) {
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	// Two admins, each demoting the other at the same time
+	adminIDs := make([]uuid.UUID, 2)
+	for i := range adminIDs {
+		adminIDs[i], _ = env.CreateTestUser()
+		user, err := env.UserRepository.GetByID(env.Ctx, adminIDs[i])
+		require.NoError(t, err)
+		user.IsAdmin = true
+		require.NoError(t, env.UserRepository.Update(env.Ctx, user))
+	}
+
+	errs := make([]error, len(adminIDs))
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i, id := range adminIDs {
+		user, err := env.UserRepository.GetByID(env.Ctx, id)
+		require.NoError(t, err)
+		user.IsAdmin = false
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs[i] = env.UserService.UpdateUser(env.Ctx, user)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.Equal(t, services.ErrCannotRemoveLastAdmin, err)
+	}
+	assert.Equal(t, 1, succeeded)
+
+	// One of them is still an admin
+	admins := 0
+	for _, id := range adminIDs {
+		user, err := env.UserRepository.GetByID(env.Ctx, id)
+		require.NoError(t, err)
+		if user.IsAdmin {
+			admins++
+		}
+	}
+	assert.Equal(t, 1, admins)
+}
@@ -1,6 +1,8 @@
 package integration
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -39,8 +41,11 @@ func NewTestVoteEnv(t *testing.T) *TestVoteEnv {
 	// Create notification service for vote notifications
 	notificationService := services.NewNotificationService(
 		notificationRepo,
+		repository.NewNotificationPreferenceRepository(baseEnv.DB),
 		userRepo,
 		baseEnv.AgentRepository,
+		nil, // Email delivery is not exercised by the vote tests
+		0,   // Every notification gets its own row
 	)
 
 	// Create vote service
@@ -48,7 +53,9 @@ func NewTestVoteEnv(t *testing.T) *TestVoteEnv {
 		voteRepo,
 		postRepo,
 		replyRepo,
+		boardRepo,
 		baseEnv.AgentRepository,
+		notificationService,
 	)
 
 	return &TestVoteEnv{
@@ -78,7 +85,7 @@ func TestCreateVote_Integration(t *testing.T) {
 	// Create a test board
 	board := &models.Board{
 		ID:          uuid.New(),
-		AgentID:     postOwnerAgent.ID,
+		AgentID:     &postOwnerAgent.ID,
 		Title:       "Test Board",
 		Description: "Test Board Description",
 		IsActive:    true,
@@ -173,7 +180,7 @@ func TestGetVoteByID_Integration(t *testing.T) {
 	// Create a test board
 	board := &models.Board{
 		ID:          uuid.New(),
-		AgentID:     postOwnerAgent.ID,
+		AgentID:     &postOwnerAgent.ID,
 		Title:       "Test Board",
 		Description: "Test Board Description",
 		IsActive:    true,
@@ -237,7 +244,7 @@ func TestGetVotesByTargetID_Integration(t *testing.T) {
 	// Create a test board
 	board := &models.Board{
 		ID:          uuid.New(),
-		AgentID:     postOwnerAgent.ID,
+		AgentID:     &postOwnerAgent.ID,
 		Title:       "Test Board",
 		Description: "Test Board Description",
 		IsActive:    true,
@@ -306,7 +313,7 @@ func TestUpdateVote_Integration(t *testing.T) {
 	// Create a test board
 	board := &models.Board{
 		ID:          uuid.New(),
-		AgentID:     postOwnerAgent.ID,
+		AgentID:     &postOwnerAgent.ID,
 		Title:       "Test Board",
 		Description: "Test Board Description",
 		IsActive:    true,
@@ -380,7 +387,7 @@ func TestDeleteVote_Integration(t *testing.T) {
 	// Create a test board
 	board := &models.Board{
 		ID:          uuid.New(),
-		AgentID:     postOwnerAgent.ID,
+		AgentID:     &postOwnerAgent.ID,
 		Title:       "Test Board",
 		Description: "Test Board Description",
 		IsActive:    true,
@@ -415,7 +422,7 @@ func TestDeleteVote_Integration(t *testing.T) {
 	err = env.VoteService.DeleteVote(env.Ctx, vote.ID)
 	require.NoError(t, err)
 
-	// Verify vote was deleted (soft delete)
+	// Verify vote was deleted
 	_, err = env.VoteService.GetVoteByID(env.Ctx, vote.ID)
 	assert.Equal(t, services.ErrVoteNotFound, err)
 
@@ -428,3 +435,359 @@ func TestDeleteVote_Integration(t *testing.T) {
 	err = env.VoteService.DeleteVote(env.Ctx, uuid.New())
 	assert.Equal(t, services.ErrVoteNotFound, err)
 }
+
+func TestRevoteAfterDelete_Integration(t *testing.T) {
+	// Create test environment
+	env := NewTestVoteEnv(t)
+	defer env.Cleanup()
+
+	// Create test users and agents
+	postOwnerUserID, _ := env.CreateTestUser()
+	postOwnerAgent := env.CreateTestAgent(postOwnerUserID)
+
+	voterUserID, _ := env.CreateTestUser()
+	voterAgent := env.CreateTestAgent(voterUserID)
+
+	// Create a test board
+	board := &models.Board{
+		ID:          uuid.New(),
+		AgentID:     &postOwnerAgent.ID,
+		Title:       "Test Board",
+		Description: "Test Board Description",
+		IsActive:    true,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	err := env.BoardRepository.Create(env.Ctx, board)
+	require.NoError(t, err)
+
+	// Create a test post
+	post := &models.Post{
+		ID:        uuid.New(),
+		BoardID:   board.ID,
+		AgentID:   postOwnerAgent.ID,
+		Content:   "Test content",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	err = env.PostRepository.Create(env.Ctx, post)
+	require.NoError(t, err)
+
+	// Vote, then remove the vote
+	vote, err := env.VoteService.CreateVote(env.Ctx, voterAgent.ID, "post", post.ID, 1)
+	require.NoError(t, err)
+	err = env.VoteService.DeleteVote(env.Ctx, vote.ID)
+	require.NoError(t, err)
+
+	// Voting again on the same target succeeds
+	revote, err := env.VoteService.CreateVote(env.Ctx, voterAgent.ID, "post", post.ID, -1)
+	require.NoError(t, err)
+	assert.NotEqual(t, vote.ID, revote.ID)
+
+	// The vote count reflects only the new vote
+	updatedPost, err := env.PostRepository.GetByID(env.Ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, -1, updatedPost.VoteCount)
+
+	// A duplicate vote is still rejected
+	_, err = env.VoteService.CreateVote(env.Ctx, voterAgent.ID, "post", post.ID, 1)
+	assert.Equal(t, services.ErrAlreadyVoted, err)
+}
+
+// TestVoteDoesNotSetEditedAt_Integration tests that vote count updates leave edited_at
+// unset while content edits set it
+func TestVoteDoesNotSetEditedAt_Integration(t *testing.T) {
+	env := NewTestVoteEnv(t)
+	defer env.Cleanup()
+
+	postService := services.NewPostService(env.PostRepository, env.BoardRepository, env.AgentRepository, env.ReplyRepository, env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, nil)
+	replyService := services.NewReplyService(env.ReplyRepository, env.PostRepository, env.BoardRepository, env.AgentRepository, env.AgentService, services.DefaultMaxReplyLength, nil, nil, 0, nil)
+	boardService := services.NewBoardService(env.BoardRepository, env.AgentRepository, nil, nil, 0)
+
+	ownerUserID, _ := env.CreateTestUser()
+	ownerAgent := env.CreateTestAgent(ownerUserID)
+	voterUserID, _ := env.CreateTestUser()
+	voterAgent := env.CreateTestAgent(voterUserID)
+
+	board, err := boardService.CreateBoard(env.Ctx, ownerAgent.ID, "Edited Board", "Edited Description", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, ownerAgent.ID, "Original post", "")
+	require.NoError(t, err)
+	reply, err := replyService.CreateReply(env.Ctx, "post", post.ID, ownerAgent.ID, "Original reply", "", nil)
+	require.NoError(t, err)
+	assert.Nil(t, post.EditedAt)
+	assert.Nil(t, reply.EditedAt)
+
+	// Votes change the counts but are not edits
+	_, err = env.VoteService.CreateVote(env.Ctx, voterAgent.ID, "post", post.ID, 1)
+	require.NoError(t, err)
+	_, err = env.VoteService.CreateVote(env.Ctx, voterAgent.ID, "reply", reply.ID, 1)
+	require.NoError(t, err)
+
+	votedPost, err := postService.GetPostByID(env.Ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, votedPost.VoteCount)
+	assert.Nil(t, votedPost.EditedAt)
+
+	votedReply, err := replyService.GetReplyByID(env.Ctx, reply.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, votedReply.VoteCount)
+	assert.Nil(t, votedReply.EditedAt)
+
+	// Saving unchanged content is not an edit either
+	require.NoError(t, postService.UpdatePost(env.Ctx, votedPost))
+	unchangedPost, err := postService.GetPostByID(env.Ctx, post.ID)
+	require.NoError(t, err)
+	assert.Nil(t, unchangedPost.EditedAt)
+
+	// Content edits set edited_at and keep the vote counts
+	votedPost.Content = "Edited post"
+	require.NoError(t, postService.UpdatePost(env.Ctx, votedPost))
+	editedPost, err := postService.GetPostByID(env.Ctx, post.ID)
+	require.NoError(t, err)
+	require.NotNil(t, editedPost.EditedAt)
+	assert.Equal(t, 1, editedPost.VoteCount)
+
+	votedReply.Content = "Edited reply"
+	require.NoError(t, replyService.UpdateReply(env.Ctx, votedReply, false))
+	editedReply, err := replyService.GetReplyByID(env.Ctx, reply.ID)
+	require.NoError(t, err)
+	require.NotNil(t, editedReply.EditedAt)
+	assert.Equal(t, 1, editedReply.VoteCount)
+}
+
+func TestWeightedVoting_Integration(t *testing.T) {
+	env := NewTestVoteEnv(t)
+	defer env.Cleanup()
+
+	postService := services.NewPostService(env.PostRepository, env.BoardRepository, env.AgentRepository, env.ReplyRepository, env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, nil)
+	replyService := services.NewReplyService(env.ReplyRepository, env.PostRepository, env.BoardRepository, env.AgentRepository, env.AgentService, services.DefaultMaxReplyLength, nil, nil, 0, nil)
+	boardService := services.NewBoardService(env.BoardRepository, env.AgentRepository, nil, nil, 0)
+
+	ownerUserID, _ := env.CreateTestUser()
+	ownerAgent := env.CreateTestAgent(ownerUserID)
+	veteranUserID, _ := env.CreateTestUser()
+	veteranAgent := env.CreateTestAgent(veteranUserID)
+	newcomerUserID, _ := env.CreateTestUser()
+	newcomerAgent := env.CreateTestAgent(newcomerUserID)
+
+	board, err := boardService.CreateBoard(env.Ctx, ownerAgent.ID, "Weighted Board", "Votes by reputation", true)
+	require.NoError(t, err)
+	assert.False(t, board.WeightedVoting)
+
+	// The veteran has karma 20 from a well-received post; the newcomer has none
+	veteranPost, err := postService.CreatePost(env.Ctx, board.ID, veteranAgent.ID, "A well-received post", "")
+	require.NoError(t, err)
+	require.NoError(t, env.PostRepository.UpdateVoteCount(env.Ctx, veteranPost.ID, 20, 20))
+	veteranWeight := services.VoteWeight(20)
+
+	t.Run("Unweighted board counts every vote as one", func(t *testing.T) {
+		post, err := postService.CreatePost(env.Ctx, board.ID, ownerAgent.ID, "Unweighted post", "")
+		require.NoError(t, err)
+
+		vote, err := env.VoteService.CreateVote(env.Ctx, veteranAgent.ID, "post", post.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1.0, vote.Weight)
+		_, err = env.VoteService.CreateVote(env.Ctx, newcomerAgent.ID, "post", post.ID, 1)
+		require.NoError(t, err)
+
+		voted, err := postService.GetPostByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, voted.VoteCount)
+		assert.InDelta(t, 2.0, voted.WeightedScore, 1e-9)
+	})
+
+	updated, err := boardService.SetWeightedVoting(env.Ctx, board.ID, services.AgentActor(ownerAgent.ID), true)
+	require.NoError(t, err)
+	assert.True(t, updated.WeightedVoting)
+
+	_, err = boardService.SetWeightedVoting(env.Ctx, board.ID, services.AgentActor(veteranAgent.ID), false)
+	assert.Equal(t, services.ErrNotBoardOwner, err)
+
+	t.Run("Weighted board weights votes by karma", func(t *testing.T) {
+		post, err := postService.CreatePost(env.Ctx, board.ID, ownerAgent.ID, "Weighted post", "")
+		require.NoError(t, err)
+
+		veteranVote, err := env.VoteService.CreateVote(env.Ctx, veteranAgent.ID, "post", post.ID, 1)
+		require.NoError(t, err)
+		assert.InDelta(t, veteranWeight, veteranVote.Weight, 1e-9)
+		newcomerVote, err := env.VoteService.CreateVote(env.Ctx, newcomerAgent.ID, "post", post.ID, -1)
+		require.NoError(t, err)
+		assert.Equal(t, 1.0, newcomerVote.Weight)
+
+		// The raw count cancels out, but the veteran's upvote outweighs the newcomer's downvote
+		voted, err := postService.GetPostByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, voted.VoteCount)
+		assert.InDelta(t, veteranWeight-1, voted.WeightedScore, 1e-9)
+
+		// Changing a vote reverses it with the weight it was cast with, even if karma changed since
+		require.NoError(t, env.PostRepository.UpdateVoteCount(env.Ctx, veteranPost.ID, 100, 100))
+		veteranVote.Value = -1
+		require.NoError(t, env.VoteService.UpdateVote(env.Ctx, veteranVote))
+
+		voted, err = postService.GetPostByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+		assert.Equal(t, -2, voted.VoteCount)
+		assert.InDelta(t, -veteranWeight-1, voted.WeightedScore, 1e-9)
+
+		// Deleting a vote removes exactly its weighted value
+		require.NoError(t, env.VoteService.DeleteVote(env.Ctx, newcomerVote.ID))
+		voted, err = postService.GetPostByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+		assert.Equal(t, -1, voted.VoteCount)
+		assert.InDelta(t, -veteranWeight, voted.WeightedScore, 1e-9)
+
+		require.NoError(t, env.PostRepository.UpdateVoteCount(env.Ctx, veteranPost.ID, -100, -100))
+	})
+
+	t.Run("Replies on a weighted board are weighted", func(t *testing.T) {
+		post, err := postService.CreatePost(env.Ctx, board.ID, ownerAgent.ID, "Post with a reply", "")
+		require.NoError(t, err)
+		reply, err := replyService.CreateReply(env.Ctx, "post", post.ID, ownerAgent.ID, "A reply", "", nil)
+		require.NoError(t, err)
+
+		_, err = env.VoteService.CreateVote(env.Ctx, veteranAgent.ID, "reply", reply.ID, 1)
+		require.NoError(t, err)
+
+		voted, err := replyService.GetReplyByID(env.Ctx, reply.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, voted.VoteCount)
+		assert.InDelta(t, veteranWeight, voted.WeightedScore, 1e-9)
+	})
+}
+
+func TestTimestampsAreUTC_Integration(t *testing.T) {
+	env := NewTestVoteEnv(t)
+	defer env.Cleanup()
+
+	authorUserID, _ := env.CreateTestUser()
+	author := env.CreateTestAgent(authorUserID)
+	voterUserID, _ := env.CreateTestUser()
+	voter := env.CreateTestAgent(voterUserID)
+
+	board := models.NewBoard(author.ID, "UTC Board", "Timestamps")
+	require.NoError(t, env.BoardRepository.Create(env.Ctx, board))
+
+	// A post created with a non-UTC offset is still read back in UTC
+	offset := time.FixedZone("UTC+5", 5*60*60)
+	post := models.NewPost(board.ID, author.ID, "Test content", nil)
+	post.CreatedAt = post.CreatedAt.In(offset)
+	require.NoError(t, env.PostRepository.Create(env.Ctx, post))
+
+	reply := models.NewReply(string(models.ParentTypePost), post.ID, author.ID, "Test reply", nil)
+	assert.Equal(t, time.UTC, reply.CreatedAt.Location())
+	require.NoError(t, env.ReplyRepository.Create(env.Ctx, reply))
+
+	vote, err := env.VoteService.CreateVote(env.Ctx, voter.ID, "post", post.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, vote.CreatedAt.Location())
+
+	storedPost, err := env.PostRepository.GetByID(env.Ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, storedPost.CreatedAt.Location())
+	assert.Equal(t, time.UTC, storedPost.UpdatedAt.Location())
+	assert.True(t, post.CreatedAt.Equal(storedPost.CreatedAt))
+
+	storedReply, err := env.ReplyRepository.GetByID(env.Ctx, reply.ID)
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, storedReply.CreatedAt.Location())
+
+	storedVote, err := env.VoteRepository.GetByID(env.Ctx, vote.ID)
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, storedVote.CreatedAt.Location())
+
+	notifications, _, err := env.NotificationService.GetNotificationsByAgentID(env.Ctx, author.ID, 1, 10)
+	require.NoError(t, err)
+	require.NotEmpty(t, notifications)
+	assert.Equal(t, time.UTC, notifications[0].CreatedAt.Location())
+
+	// Responses serialize timestamps as RFC3339 in UTC
+	data, err := json.Marshal(storedPost)
+	require.NoError(t, err)
+	var response struct {
+		CreatedAt string `json:"created_at"`
+	}
+	require.NoError(t, json.Unmarshal(data, &response))
+	assert.True(t, strings.HasSuffix(response.CreatedAt, "Z"), response.CreatedAt)
+	_, err = time.Parse(time.RFC3339, response.CreatedAt)
+	assert.NoError(t, err)
+}
+
+func TestVoteValueValidation_Integration(t *testing.T) {
+	// Create test environment
+	env := NewTestVoteEnv(t)
+	defer env.Cleanup()
+
+	// Create test users and agents
+	postOwnerUserID, _ := env.CreateTestUser()
+	postOwnerAgent := env.CreateTestAgent(postOwnerUserID)
+
+	voterUserID, _ := env.CreateTestUser()
+	voterAgent := env.CreateTestAgent(voterUserID)
+
+	// Create a test board
+	board := &models.Board{
+		ID:          uuid.New(),
+		AgentID:     &postOwnerAgent.ID,
+		Title:       "Test Board",
+		Description: "Test Board Description",
+		IsActive:    true,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	err := env.BoardRepository.Create(env.Ctx, board)
+	require.NoError(t, err)
+
+	// Create a test post
+	post := &models.Post{
+		ID:        uuid.New(),
+		BoardID:   board.ID,
+		AgentID:   postOwnerAgent.ID,
+		Content:   "Test content",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	err = env.PostRepository.Create(env.Ctx, post)
+	require.NoError(t, err)
+
+	voteCount := func() int {
+		p, err := env.PostRepository.GetByID(env.Ctx, post.ID)
+		require.NoError(t, err)
+		return p.VoteCount
+	}
+
+	// Values other than -1 and +1 are rejected without touching the count
+	for _, value := range []int{0, 5, -2} {
+		_, err := env.VoteService.CreateVote(env.Ctx, voterAgent.ID, "post", post.ID, value)
+		assert.ErrorIs(t, err, services.ErrInvalidVoteValue, "value %d", value)
+	}
+	assert.Equal(t, 0, voteCount())
+
+	// An upvote is accepted and moves the count by one
+	vote, err := env.VoteService.CreateVote(env.Ctx, voterAgent.ID, "post", post.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, voteCount())
+
+	// Updating to an invalid value is rejected and the vote is left as it was
+	for _, value := range []int{0, 5, -2} {
+		invalid := *vote
+		invalid.Value = value
+		err := env.VoteService.UpdateVote(env.Ctx, &invalid)
+		assert.ErrorIs(t, err, services.ErrInvalidVoteValue, "value %d", value)
+	}
+	stored, err := env.VoteService.GetVoteByID(env.Ctx, vote.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.Value)
+	assert.Equal(t, 1, voteCount())
+
+	// Flipping to a downvote moves the count by two
+	vote.Value = -1
+	require.NoError(t, env.VoteService.UpdateVote(env.Ctx, vote))
+	assert.Equal(t, -1, voteCount())
+
+	// Flipping back moves it by two again
+	vote.Value = 1
+	require.NoError(t, env.VoteService.UpdateVote(env.Ctx, vote))
+	assert.Equal(t, 1, voteCount())
+}
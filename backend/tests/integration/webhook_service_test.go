@@ -0,0 +1,155 @@
+package integration
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/garrettallen/aiboards/backend/internal/database/repository"
+	"github.com/garrettallen/aiboards/backend/internal/models"
+	"github.com/garrettallen/aiboards/backend/internal/services"
+	"github.com/garrettallen/aiboards/backend/tests/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookService(t *testing.T) {
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	// Create repositories
+	boardRepo := repository.NewBoardRepository(env.DB)
+	postRepo := repository.NewPostRepository(env.DB)
+	replyRepo := repository.NewReplyRepository(env.DB)
+	webhookRepo := repository.NewWebhookRepository(env.DB)
+
+	// Create services
+	boardService := services.NewBoardService(boardRepo, env.AgentRepository, nil, nil, 0)
+	postService := services.NewPostService(postRepo, boardRepo, env.AgentRepository, replyRepo, env.AgentService, services.DefaultMaxPostLength, nil, nil, 0, nil)
+	replyService := services.NewReplyService(replyRepo, postRepo, boardRepo, env.AgentRepository, env.AgentService, services.DefaultMaxReplyLength, nil, nil, 0, nil)
+	// The endpoints below are httptest servers on loopback
+	webhookService := services.NewWebhookService(webhookRepo, postRepo, replyRepo, env.AgentRepository, true)
+	publicOnlyService := services.NewWebhookService(webhookRepo, postRepo, replyRepo, env.AgentRepository, false)
+
+	// Create the post owner and a second agent that replies
+	userID, _ := env.CreateTestUser()
+	owner := env.CreateTestAgent(userID)
+	otherUserID, _ := env.CreateTestUser()
+	replier := env.CreateTestAgent(otherUserID)
+
+	board, err := boardService.CreateBoard(env.Ctx, owner.ID, "Webhook Board", "Webhook Description", true)
+	require.NoError(t, err)
+	post, err := postService.CreatePost(env.Ctx, board.ID, owner.ID, "Webhook Post", "")
+	require.NoError(t, err)
+
+	t.Run("Register_InvalidURL", func(t *testing.T) {
+		_, err := webhookService.Register(env.Ctx, owner.ID, "ftp://example.com/hook", []string{"reply.created"})
+		assert.Equal(t, services.ErrInvalidWebhookURL, err)
+	})
+
+	t.Run("Register_InvalidEvent", func(t *testing.T) {
+		_, err := webhookService.Register(env.Ctx, owner.ID, "https://example.com/hook", []string{"board.deleted"})
+		assert.Equal(t, services.ErrInvalidWebhookEvent, err)
+	})
+
+	t.Run("Register_UnsentEvent", func(t *testing.T) {
+		_, err := webhookService.Register(env.Ctx, owner.ID, "https://example.com/hook", []string{"mention.created"})
+		assert.Equal(t, services.ErrInvalidWebhookEvent, err)
+	})
+
+	t.Run("Register_PrivateAddress", func(t *testing.T) {
+		for _, webhookURL := range []string{
+			"http://localhost:5432/hook",
+			"http://127.0.0.1/hook",
+			"http://10.0.0.8/hook",
+			"http://192.168.1.1/hook",
+			"http://169.254.169.254/latest/meta-data",
+			"http://[::1]/hook",
+			"http://0.0.0.0/hook",
+		} {
+			_, err := publicOnlyService.Register(env.Ctx, owner.ID, webhookURL, []string{"reply.created"})
+			assert.Equal(t, services.ErrInvalidWebhookURL, err, webhookURL)
+		}
+	})
+
+	t.Run("Dispatch_RefusesPrivateAddress", func(t *testing.T) {
+		hits := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits++
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		// Stored directly, as if the host had resolved to a public address when it was registered
+		webhook := models.NewWebhook(owner.ID, server.URL, "secret", []string{string(models.WebhookEventVoteCreated)})
+		require.NoError(t, webhookRepo.Create(env.Ctx, webhook))
+
+		err := publicOnlyService.Dispatch(env.Ctx, owner.ID, models.WebhookEventVoteCreated, map[string]string{"hello": "world"})
+		require.NoError(t, err)
+		assert.Equal(t, 0, hits)
+
+		records, err := webhookRepo.GetDeliveriesByWebhookID(env.Ctx, webhook.ID)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, string(models.WebhookDeliveryFailed), records[0].Status)
+	})
+
+	t.Run("DispatchReplyCreated_DeliversSignedPayload", func(t *testing.T) {
+		type received struct {
+			body      []byte
+			signature string
+			event     string
+		}
+		deliveries := make(chan received, 1)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			deliveries <- received{
+				body:      body,
+				signature: r.Header.Get(services.WebhookSignatureHeader),
+				event:     r.Header.Get(services.WebhookEventHeader),
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		webhook, err := webhookService.Register(env.Ctx, owner.ID, server.URL, []string{string(models.WebhookEventReplyCreated)})
+		require.NoError(t, err)
+		require.NotEmpty(t, webhook.Secret)
+
+		// Another agent replies to the owner's post
+		reply, err := replyService.CreateReply(env.Ctx, string(models.ParentTypePost), post.ID, replier.ID, "Hello owner", "", nil)
+		require.NoError(t, err)
+
+		err = webhookService.DispatchReplyCreated(env.Ctx, reply)
+		require.NoError(t, err)
+
+		var got received
+		select {
+		case got = <-deliveries:
+		default:
+			t.Fatal("webhook was not delivered")
+		}
+
+		// Verify the signature and payload
+		assert.Equal(t, string(models.WebhookEventReplyCreated), got.event)
+		assert.Equal(t, services.SignWebhookPayload(webhook.Secret, got.body), got.signature)
+
+		var payload struct {
+			Event string       `json:"event"`
+			Data  models.Reply `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(got.body, &payload))
+		assert.Equal(t, string(models.WebhookEventReplyCreated), payload.Event)
+		assert.Equal(t, reply.ID, payload.Data.ID)
+
+		// Verify the delivery was recorded
+		records, err := webhookRepo.GetDeliveriesByWebhookID(env.Ctx, webhook.ID)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, string(models.WebhookDeliveryDelivered), records[0].Status)
+		assert.Equal(t, 1, records[0].Attempts)
+	})
+}
@@ -1,12 +1,22 @@
 package unit
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/garrettallen/aiboards/backend/internal/database/repository"
+	"github.com/garrettallen/aiboards/backend/internal/middleware"
 	"github.com/garrettallen/aiboards/backend/internal/models"
 	"github.com/garrettallen/aiboards/backend/internal/services"
 	"github.com/garrettallen/aiboards/backend/tests/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateAgent(t *testing.T) {
@@ -23,7 +33,7 @@ func TestCreateAgent(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Test creating an agent
-	name := "Test Agent"
+	name := "Test_Agent"
 	description := "This is a test agent"
 	dailyLimit := 100
 
@@ -73,7 +83,7 @@ func TestGetAgentsByUserID(t *testing.T) {
 		_, err := env.AgentService.CreateAgent(
 			env.Ctx,
 			testUser.ID,
-			"Agent "+string(rune(i+65)), // A, B, C
+			"Agent_"+string(rune(i+65)), // A, B, C
 			"Description "+string(rune(i+65)),
 			100,
 		)
@@ -95,7 +105,7 @@ func TestUpdateAgent(t *testing.T) {
 	_, agent := createTestUserAndAgent(t, env)
 
 	// Update agent
-	agent.Name = "Updated Agent Name"
+	agent.Name = "Updated_Agent_Name"
 	agent.Description = "Updated description"
 	agent.DailyLimit = 200
 
@@ -105,7 +115,7 @@ func TestUpdateAgent(t *testing.T) {
 	// Verify update
 	updatedAgent, err := env.AgentService.GetAgentByID(env.Ctx, agent.ID)
 	assert.NoError(t, err)
-	assert.Equal(t, "Updated Agent Name", updatedAgent.Name)
+	assert.Equal(t, "Updated_Agent_Name", updatedAgent.Name)
 	assert.Equal(t, "Updated description", updatedAgent.Description)
 	assert.Equal(t, 200, updatedAgent.DailyLimit)
 }
@@ -127,10 +137,11 @@ func TestRegenerateAPIKey(t *testing.T) {
 	assert.NotEmpty(t, newAPIKey)
 	assert.NotEqual(t, originalAPIKey, newAPIKey)
 
-	// Verify the agent has the new API key
+	// Verify the agent has the new API key, stored only as a hash
 	updatedAgent, err := env.AgentService.GetAgentByID(env.Ctx, agent.ID)
 	assert.NoError(t, err)
-	assert.Equal(t, newAPIKey, updatedAgent.APIKey)
+	assert.Equal(t, models.HashAPIKey(newAPIKey), updatedAgent.APIKeyHash)
+	assert.Empty(t, updatedAgent.APIKey)
 }
 
 func TestDeleteAgent(t *testing.T) {
@@ -183,7 +194,7 @@ func TestCheckRateLimit(t *testing.T) {
 	err = env.UserRepository.Create(env.Ctx, testUser)
 	assert.NoError(t, err)
 
-	agent, err := env.AgentService.CreateAgent(env.Ctx, testUser.ID, "Rate Limited Agent", "Test", 2)
+	agent, err := env.AgentService.CreateAgent(env.Ctx, testUser.ID, "Rate_Limited_Agent", "Test", 2)
 	assert.NoError(t, err)
 
 	// Initially should not be rate limited
@@ -217,7 +228,7 @@ func createTestUserAndAgent(t *testing.T, env *utils.TestEnv) (*models.User, *mo
 	agent, err := env.AgentService.CreateAgent(
 		env.Ctx,
 		testUser.ID,
-		"Test Agent",
+		"Test_Agent",
 		"This is a test agent",
 		100,
 	)
@@ -225,3 +236,223 @@ func createTestUserAndAgent(t *testing.T, env *utils.TestEnv) (*models.User, *mo
 
 	return testUser, agent
 }
+
+func TestCreateAgent_AgentLimit(t *testing.T) {
+	// Create test environment
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	// Use a small limit so the boundary is cheap to reach
+	const limit = 3
+	agentService := services.NewAgentService(env.AgentRepository, env.UserRepository, repository.NewBoardRepository(env.DB), repository.NewPostRepository(env.DB), repository.NewReplyRepository(env.DB), repository.NewVoteRepository(env.DB), limit, services.DefaultAgentDailyLimit, 0, nil)
+
+	t.Run("Regular user is limited", func(t *testing.T) {
+		testUser, err := models.NewUser("agent-limit@example.com", "password123", "Limited User")
+		assert.NoError(t, err)
+		assert.NoError(t, env.UserRepository.Create(env.Ctx, testUser))
+
+		for i := 1; i <= limit; i++ {
+			_, err := agentService.CreateAgent(env.Ctx, testUser.ID, fmt.Sprintf("Limited_Agent_%d", i), "", 0)
+			assert.NoError(t, err, "agent %d should be allowed", i)
+		}
+
+		_, err = agentService.CreateAgent(env.Ctx, testUser.ID, "Limited_Agent_Extra", "", 0)
+		assert.ErrorIs(t, err, services.ErrAgentLimitReached)
+	})
+
+	t.Run("Admin is exempt", func(t *testing.T) {
+		adminUser, err := models.NewUser("agent-limit-admin@example.com", "password123", "Admin User")
+		assert.NoError(t, err)
+		adminUser.IsAdmin = true
+		assert.NoError(t, env.UserRepository.Create(env.Ctx, adminUser))
+
+		for i := 1; i <= limit+1; i++ {
+			_, err := agentService.CreateAgent(env.Ctx, adminUser.ID, fmt.Sprintf("Admin_Agent_%d", i), "", 0)
+			assert.NoError(t, err)
+		}
+	})
+}
+
+func TestCreateAgent_DefaultDailyLimit(t *testing.T) {
+	// Create test environment
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	const defaultDailyLimit = 120
+	agentService := services.NewAgentService(env.AgentRepository, env.UserRepository, repository.NewBoardRepository(env.DB), repository.NewPostRepository(env.DB), repository.NewReplyRepository(env.DB), repository.NewVoteRepository(env.DB), services.DefaultMaxAgentsPerUser, defaultDailyLimit, 0, nil)
+
+	testUser, err := models.NewUser("daily-limit@example.com", "password123", "Daily Limit User")
+	assert.NoError(t, err)
+	assert.NoError(t, env.UserRepository.Create(env.Ctx, testUser))
+
+	t.Run("Zero gets the configured default", func(t *testing.T) {
+		agent, err := agentService.CreateAgent(env.Ctx, testUser.ID, "Default_Limit_Agent", "", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, defaultDailyLimit, agent.DailyLimit)
+	})
+
+	t.Run("Explicit limit is kept", func(t *testing.T) {
+		agent, err := agentService.CreateAgent(env.Ctx, testUser.ID, "Explicit_Limit_Agent", "", 900)
+		assert.NoError(t, err)
+		assert.Equal(t, 900, agent.DailyLimit)
+	})
+
+	t.Run("Unset default falls back to the built-in default", func(t *testing.T) {
+		fallbackService := services.NewAgentService(env.AgentRepository, env.UserRepository, repository.NewBoardRepository(env.DB), repository.NewPostRepository(env.DB), repository.NewReplyRepository(env.DB), repository.NewVoteRepository(env.DB), services.DefaultMaxAgentsPerUser, 0, 0, nil)
+		agent, err := fallbackService.CreateAgent(env.Ctx, testUser.ID, "Fallback_Limit_Agent", "", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, services.DefaultAgentDailyLimit, agent.DailyLimit)
+	})
+}
+
+func TestCreateAgent_NameValidation(t *testing.T) {
+	// Create test environment
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	agentService := services.NewAgentService(env.AgentRepository, env.UserRepository, repository.NewBoardRepository(env.DB), repository.NewPostRepository(env.DB), repository.NewReplyRepository(env.DB), repository.NewVoteRepository(env.DB), services.DefaultMaxAgentsPerUser, services.DefaultAgentDailyLimit, 0, []string{"admin", "System"})
+
+	testUser, err := models.NewUser("agent-names@example.com", "password123", "Agent Name User")
+	assert.NoError(t, err)
+	assert.NoError(t, env.UserRepository.Create(env.Ctx, testUser))
+
+	tests := []struct {
+		name     string
+		expected error
+	}{
+		{name: "helper_bot-2", expected: nil},
+		{name: "ab", expected: services.ErrAgentNameLength},
+		{name: strings.Repeat("a", services.MaxAgentNameLength+1), expected: services.ErrAgentNameLength},
+		{name: "has space", expected: services.ErrAgentNameInvalidChars},
+		{name: "@mention", expected: services.ErrAgentNameInvalidChars},
+		{name: "émile", expected: services.ErrAgentNameInvalidChars},
+		{name: "Admin", expected: services.ErrAgentNameReserved},
+		{name: "system", expected: services.ErrAgentNameReserved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent, err := agentService.CreateAgent(env.Ctx, testUser.ID, tt.name, "", 0)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.name, agent.Name)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+
+	t.Run("Admins may use reserved names", func(t *testing.T) {
+		adminUser, err := models.NewUser("agent-names-admin@example.com", "password123", "Admin User")
+		assert.NoError(t, err)
+		adminUser.IsAdmin = true
+		assert.NoError(t, env.UserRepository.Create(env.Ctx, adminUser))
+
+		agent, err := agentService.CreateAgent(env.Ctx, adminUser.ID, "system", "", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "system", agent.Name)
+	})
+}
+
+func TestPresenceStatus(t *testing.T) {
+	now := time.Now()
+	ago := func(d time.Duration) *time.Time {
+		at := now.Add(-d)
+		return &at
+	}
+
+	tests := []struct {
+		name         string
+		lastActiveAt *time.Time
+		expected     string
+	}{
+		{name: "Never active", lastActiveAt: nil, expected: models.PresenceOffline},
+		{name: "Just now", lastActiveAt: ago(0), expected: models.PresenceOnline},
+		{name: "Within the online window", lastActiveAt: ago(services.PresenceOnlineWindow - time.Second), expected: models.PresenceOnline},
+		{name: "At the online window", lastActiveAt: ago(services.PresenceOnlineWindow), expected: models.PresenceAway},
+		{name: "Within the away window", lastActiveAt: ago(services.PresenceAwayWindow - time.Second), expected: models.PresenceAway},
+		{name: "At the away window", lastActiveAt: ago(services.PresenceAwayWindow), expected: models.PresenceOffline},
+		{name: "Long ago", lastActiveAt: ago(30 * 24 * time.Hour), expected: models.PresenceOffline},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, services.PresenceStatus(tt.lastActiveAt, now))
+		})
+	}
+}
+
+func TestAgentActivityAndPresence(t *testing.T) {
+	// Create test environment
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	userID, _ := env.CreateTestUser()
+	agent := env.CreateTestAgent(userID)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ActivityTracker(env.UserService, env.AgentService, time.Hour))
+	router.GET("/ping", func(c *gin.Context) {
+		// Load the agent as the auth middleware would
+		current, err := env.AgentRepository.GetByID(env.Ctx, agent.ID)
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		c.Set("agent", current)
+		if c.Query("impersonated") != "" {
+			c.Set("impersonated_by", uuid.New())
+		}
+		c.Status(http.StatusOK)
+	})
+
+	// An admin impersonating the agent doesn't count as the agent being active
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/ping?impersonated=1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	stored, err := env.AgentRepository.GetByID(env.Ctx, agent.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.LastActiveAt)
+
+	// An agent that has never acted is offline
+	presence, err := env.AgentService.GetPresence(env.Ctx, agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PresenceOffline, presence.Status)
+	assert.Nil(t, presence.LastActiveAt)
+
+	// An authenticated action stamps its activity
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/ping", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	stored, err = env.AgentRepository.GetByID(env.Ctx, agent.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.LastActiveAt)
+	assert.WithinDuration(t, time.Now(), *stored.LastActiveAt, time.Minute)
+
+	presence, err = env.AgentService.GetPresence(env.Ctx, agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PresenceOnline, presence.Status)
+
+	// Presence follows the time since the agent was last active
+	setLastActive := func(ago time.Duration) {
+		_, err := env.DB.Exec(`UPDATE agents SET last_active_at = $1 WHERE id = $2`, time.Now().Add(-ago), agent.ID)
+		require.NoError(t, err)
+	}
+
+	setLastActive(30 * time.Minute)
+	presence, err = env.AgentService.GetPresence(env.Ctx, agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PresenceAway, presence.Status)
+
+	setLastActive(2 * time.Hour)
+	presence, err = env.AgentService.GetPresence(env.Ctx, agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PresenceOffline, presence.Status)
+
+	// Unknown agents have no presence
+	_, err = env.AgentService.GetPresence(env.Ctx, uuid.New())
+	assert.Equal(t, services.ErrAgentNotFound, err)
+}
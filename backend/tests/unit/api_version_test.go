@@ -0,0 +1,70 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/garrettallen/aiboards/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAPIVersionRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.APIVersion())
+	router.GET("/version", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"version": middleware.RequestedAPIVersion(c)})
+	})
+
+	return router
+}
+
+func TestAPIVersion(t *testing.T) {
+	router := setupAPIVersionRouter()
+
+	tests := []struct {
+		name     string
+		accept   string
+		expected int
+		version  string
+	}{
+		{name: "No Accept header", accept: "", expected: http.StatusOK, version: "1"},
+		{name: "Plain JSON", accept: "application/json", expected: http.StatusOK, version: "1"},
+		{name: "Wildcard", accept: "*/*", expected: http.StatusOK, version: "1"},
+		{name: "Explicit v1", accept: "application/vnd.aiboards.v1+json", expected: http.StatusOK, version: "1"},
+		{name: "v2", accept: "application/vnd.aiboards.v2+json", expected: http.StatusOK, version: "2"},
+		{name: "v2 among other types", accept: "text/html, application/vnd.aiboards.v2+json;q=0.9", expected: http.StatusOK, version: "2"},
+		{name: "Unknown version", accept: "application/vnd.aiboards.v3+json", expected: http.StatusNotAcceptable},
+		{name: "Version zero", accept: "application/vnd.aiboards.v0+json", expected: http.StatusNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/version", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+			if tt.expected == http.StatusOK {
+				assert.Equal(t, tt.version, w.Header().Get("X-API-Version"))
+				assert.JSONEq(t, `{"version":`+tt.version+`}`, w.Body.String())
+				assert.Equal(t, "Accept", w.Header().Get("Vary"))
+			} else {
+				assert.Contains(t, w.Body.String(), "NOT_ACCEPTABLE")
+			}
+		})
+	}
+}
+
+func TestRequestedAPIVersion_WithoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	assert.Equal(t, middleware.DefaultAPIVersion, middleware.RequestedAPIVersion(c))
+}
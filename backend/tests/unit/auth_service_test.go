@@ -2,6 +2,10 @@ package unit
 
 import (
 	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 
 	"github.com/garrettallen/aiboards/backend/internal/services"
 	"github.com/garrettallen/aiboards/backend/tests/utils"
@@ -272,6 +276,58 @@ func TestValidateToken_InvalidToken(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestValidateToken_IssuerAndAudience(t *testing.T) {
+	// Token validation doesn't touch the repositories
+	authService := services.NewAuthService(nil, nil, utils.TestJWTSecret, time.Hour, time.Hour, "aiboards", "aiboards-api")
+
+	mint := func(claims jwt.MapClaims) string {
+		claims["sub"] = uuid.New().String()
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(utils.TestJWTSecret))
+		require.NoError(t, err)
+		return tokenString
+	}
+
+	t.Run("Own tokens are accepted", func(t *testing.T) {
+		tokens, err := authService.GenerateImpersonationToken(uuid.New(), uuid.New())
+		require.NoError(t, err)
+
+		token, err := authService.ValidateToken(tokens.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, "aiboards", token.Claims.(jwt.MapClaims)["iss"])
+		assert.Equal(t, "aiboards-api", token.Claims.(jwt.MapClaims)["aud"])
+	})
+
+	t.Run("A token from another issuer sharing the secret is rejected", func(t *testing.T) {
+		staging := services.NewAuthService(nil, nil, utils.TestJWTSecret, time.Hour, time.Hour, "aiboards-staging", "aiboards-api")
+		tokens, err := staging.GenerateImpersonationToken(uuid.New(), uuid.New())
+		require.NoError(t, err)
+
+		_, err = authService.ValidateToken(tokens.AccessToken)
+		assert.Equal(t, services.ErrInvalidToken, err)
+	})
+
+	t.Run("Wrong or missing claims are rejected", func(t *testing.T) {
+		for name, claims := range map[string]jwt.MapClaims{
+			"wrong audience":   {"iss": "aiboards", "aud": "other-api"},
+			"missing issuer":   {"aud": "aiboards-api"},
+			"missing audience": {"iss": "aiboards"},
+		} {
+			_, err := authService.ValidateToken(mint(claims))
+			assert.Equal(t, services.ErrInvalidToken, err, name)
+		}
+
+		_, err := authService.ValidateToken(mint(jwt.MapClaims{"iss": "aiboards", "aud": "aiboards-api"}))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Checks are skipped when not configured", func(t *testing.T) {
+		unchecked := services.NewAuthService(nil, nil, utils.TestJWTSecret, time.Hour, time.Hour, "", "")
+		_, err := unchecked.ValidateToken(mint(jwt.MapClaims{}))
+		assert.NoError(t, err)
+	})
+}
+
 func TestGetUserFromToken_Success(t *testing.T) {
 	// Create a test environment with real repositories
 	env := utils.NewTestEnv(t)
@@ -338,3 +394,55 @@ func TestGetUserFromToken_UserNotFound(t *testing.T) {
 	// Assert error
 	assert.Error(t, err)
 }
+
+func TestLogin_ConfiguredAccessTokenTTL(t *testing.T) {
+	// Create a test environment with real repositories
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	// An auth service with a very short access token lifetime
+	authService := services.NewAuthService(env.UserRepository, env.BetaCodeRepository, utils.TestJWTSecret, time.Second, time.Hour, utils.TestJWTIssuer, utils.TestJWTAudience)
+
+	// Create a test user
+	userID, password := env.CreateTestUser()
+	user, err := env.UserRepository.GetByID(env.Ctx, userID)
+	require.NoError(t, err)
+
+	_, tokens, err := authService.Login(env.Ctx, user.Email, password)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Second), tokens.ExpiresAt, time.Second)
+
+	// The token is valid right away
+	token, err := authService.ValidateToken(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, token.Valid)
+
+	// ...and rejected once it has expired
+	time.Sleep(2 * time.Second)
+	_, err = authService.ValidateToken(tokens.AccessToken)
+	assert.Error(t, err)
+}
+
+func TestLogin_RecordsLastLogin(t *testing.T) {
+	// Create a test environment with real repositories
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	// Create a test user who has never logged in
+	userID, password := env.CreateTestUser()
+	user, err := env.UserRepository.GetByID(env.Ctx, userID)
+	require.NoError(t, err)
+	assert.Nil(t, user.LastLoginAt)
+
+	before := time.Now().Add(-time.Second)
+	_, _, err = env.AuthService.Login(env.Ctx, user.Email, password)
+	require.NoError(t, err)
+
+	// The login is persisted and counts as activity
+	user, err = env.UserRepository.GetByID(env.Ctx, userID)
+	require.NoError(t, err)
+	require.NotNil(t, user.LastLoginAt)
+	require.NotNil(t, user.LastActiveAt)
+	assert.True(t, user.LastLoginAt.After(before))
+	assert.True(t, user.LastActiveAt.Equal(*user.LastLoginAt))
+}
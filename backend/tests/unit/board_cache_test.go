@@ -0,0 +1,193 @@
+package unit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garrettallen/aiboards/backend/internal/database/repository"
+	"github.com/garrettallen/aiboards/backend/internal/models"
+	"github.com/garrettallen/aiboards/backend/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingBoardRepository keeps boards in memory and counts GetByID calls.
+// Methods the cache tests don't need fall through to the nil embedded interface.
+type countingBoardRepository struct {
+	repository.BoardRepository
+
+	mu       sync.Mutex
+	boards   map[uuid.UUID]models.Board
+	getCalls int
+}
+
+func newCountingBoardRepository(boards ...*models.Board) *countingBoardRepository {
+	repo := &countingBoardRepository{boards: make(map[uuid.UUID]models.Board)}
+	for _, board := range boards {
+		repo.boards[board.ID] = *board
+	}
+	return repo
+}
+
+func (r *countingBoardRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Board, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.getCalls++
+	board, ok := r.boards[id]
+	if !ok {
+		return nil, nil
+	}
+	return &board, nil
+}
+
+func (r *countingBoardRepository) Update(ctx context.Context, board *models.Board) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.boards[board.ID] = *board
+	return nil
+}
+
+func (r *countingBoardRepository) SetActive(ctx context.Context, id uuid.UUID, isActive bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	board := r.boards[id]
+	board.IsActive = isActive
+	r.boards[id] = board
+	return nil
+}
+
+func (r *countingBoardRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.boards, id)
+	return nil
+}
+
+func (r *countingBoardRepository) GetCalls() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.getCalls
+}
+
+func TestBoardCache_CachedReadSkipsRepository(t *testing.T) {
+	board := models.NewBoard(uuid.New(), "Hot Board", "Lots of readers")
+	repo := newCountingBoardRepository(board)
+	boardService := services.NewCachedBoardService(repo, nil, nil, nil, 0, time.Minute)
+	ctx := context.Background()
+
+	first, err := boardService.GetBoardByID(ctx, board.ID)
+	require.NoError(t, err)
+	assert.Equal(t, board.Title, first.Title)
+
+	second, err := boardService.GetBoardByID(ctx, board.ID)
+	require.NoError(t, err)
+	assert.Equal(t, board.Title, second.Title)
+	assert.Equal(t, 1, repo.GetCalls())
+
+	// Mutating a returned board must not change the cached copy
+	second.Title = "Changed locally"
+	third, err := boardService.GetBoardByID(ctx, board.ID)
+	require.NoError(t, err)
+	assert.Equal(t, board.Title, third.Title)
+	assert.Equal(t, 1, repo.GetCalls())
+}
+
+func TestBoardCache_Disabled(t *testing.T) {
+	board := models.NewBoard(uuid.New(), "Board", "Description")
+	repo := newCountingBoardRepository(board)
+	boardService := services.NewBoardService(repo, nil, nil, nil, 0)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_, err := boardService.GetBoardByID(ctx, board.ID)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, repo.GetCalls())
+}
+
+func TestBoardCache_Expiry(t *testing.T) {
+	board := models.NewBoard(uuid.New(), "Board", "Description")
+	repo := newCountingBoardRepository(board)
+	boardService := services.NewCachedBoardService(repo, nil, nil, nil, 0, 10*time.Millisecond)
+	ctx := context.Background()
+
+	_, err := boardService.GetBoardByID(ctx, board.ID)
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	_, err = boardService.GetBoardByID(ctx, board.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, repo.GetCalls())
+}
+
+func TestBoardCache_Invalidation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("UpdateBoard", func(t *testing.T) {
+		board := models.NewBoard(uuid.New(), "Original", "Description")
+		repo := newCountingBoardRepository(board)
+		boardService := services.NewCachedBoardService(repo, nil, nil, nil, 0, time.Minute)
+
+		cached, err := boardService.GetBoardByID(ctx, board.ID)
+		require.NoError(t, err)
+
+		cached.Title = "Updated"
+		require.NoError(t, boardService.UpdateBoard(ctx, cached))
+
+		fresh, err := boardService.GetBoardByID(ctx, board.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Updated", fresh.Title)
+	})
+
+	t.Run("SetBoardActive", func(t *testing.T) {
+		board := models.NewBoard(uuid.New(), "Board", "Description")
+		repo := newCountingBoardRepository(board)
+		boardService := services.NewCachedBoardService(repo, nil, nil, nil, 0, time.Minute)
+
+		_, err := boardService.GetBoardByID(ctx, board.ID)
+		require.NoError(t, err)
+		require.NoError(t, boardService.SetBoardActive(ctx, board.ID, false))
+
+		fresh, err := boardService.GetBoardByID(ctx, board.ID)
+		require.NoError(t, err)
+		assert.False(t, fresh.IsActive)
+	})
+
+	t.Run("DeleteBoard", func(t *testing.T) {
+		board := models.NewBoard(uuid.New(), "Board", "Description")
+		repo := newCountingBoardRepository(board)
+		boardService := services.NewCachedBoardService(repo, nil, nil, nil, 0, time.Minute)
+
+		_, err := boardService.GetBoardByID(ctx, board.ID)
+		require.NoError(t, err)
+		require.NoError(t, boardService.DeleteBoard(ctx, board.ID))
+
+		_, err = boardService.GetBoardByID(ctx, board.ID)
+		assert.Equal(t, services.ErrBoardNotFound, err)
+	})
+}
+
+func TestBoardCache_ConcurrentAccess(t *testing.T) {
+	board := models.NewBoard(uuid.New(), "Board", "Description")
+	repo := newCountingBoardRepository(board)
+	boardService := services.NewCachedBoardService(repo, nil, nil, nil, 0, time.Minute)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%5 == 0 {
+				_ = boardService.SetBoardActive(ctx, board.ID, true)
+				return
+			}
+			_, err := boardService.GetBoardByID(ctx, board.ID)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,112 @@
+package unit
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/garrettallen/aiboards/backend/internal/handlers"
+	"github.com/garrettallen/aiboards/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupBodySizeRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.MaxBodySizeWithOverrides(16, map[string]int64{
+		"/upload": 64,
+	}))
+
+	echo := func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"size": len(body)})
+	}
+	router.POST("/echo", echo)
+	router.POST("/upload", echo)
+
+	return router
+}
+
+func TestMaxBodySize(t *testing.T) {
+	router := setupBodySizeRouter()
+
+	tests := []struct {
+		name     string
+		path     string
+		size     int
+		expected int
+	}{
+		{name: "Under the limit", path: "/echo", size: 10, expected: http.StatusOK},
+		{name: "At the limit", path: "/echo", size: 16, expected: http.StatusOK},
+		{name: "Over the limit", path: "/echo", size: 17, expected: http.StatusRequestEntityTooLarge},
+		{name: "Override allows more", path: "/upload", size: 64, expected: http.StatusOK},
+		{name: "Over the override", path: "/upload", size: 65, expected: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, bytes.NewReader(bytes.Repeat([]byte("a"), tt.size)))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestMaxBodySize_UndeclaredLength(t *testing.T) {
+	router := setupBodySizeRouter()
+
+	// Without a Content-Length the body is cut off while it's being read
+	req := httptest.NewRequest("POST", "/echo", bytes.NewReader(bytes.Repeat([]byte("a"), 100)))
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "request body too large")
+}
+
+func TestMaxBodySize_UndeclaredLengthJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.MaxBodySize(16))
+	router.POST("/bind", func(c *gin.Context) {
+		var req struct {
+			Content string `json:"content"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			handlers.RespondBindError(c, err)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/bind", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.ContentLength = -1
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// A body cut off by the limit is reported as too large, not as malformed
+	w := send(`{"content":"` + strings.Repeat("a", 100) + `"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "PAYLOAD_TOO_LARGE")
+
+	// Malformed JSON within the limit is still a bad request
+	w = send(`{"content":`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
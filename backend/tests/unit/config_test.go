@@ -0,0 +1,511 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/garrettallen/aiboards/backend/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig_TokenTTL(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 1*time.Hour, cfg.AccessTokenDuration)
+		assert.Equal(t, 7*24*time.Hour, cfg.RefreshTokenDuration)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Setenv("ACCESS_TOKEN_TTL", "15m")
+		t.Setenv("REFRESH_TOKEN_TTL", "24h")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 15*time.Minute, cfg.AccessTokenDuration)
+		assert.Equal(t, 24*time.Hour, cfg.RefreshTokenDuration)
+	})
+
+	t.Run("Zero access TTL is rejected", func(t *testing.T) {
+		t.Setenv("ACCESS_TOKEN_TTL", "0s")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+
+	t.Run("Negative refresh TTL is rejected", func(t *testing.T) {
+		t.Setenv("REFRESH_TOKEN_TTL", "-1h")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadConfig_JWTIssuerAndAudience(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, "aiboards", cfg.JWTIssuer)
+		assert.Equal(t, "aiboards-api", cfg.JWTAudience)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Setenv("JWT_ISSUER", "aiboards-staging")
+		t.Setenv("JWT_AUDIENCE", "aiboards-staging-api")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, "aiboards-staging", cfg.JWTIssuer)
+		assert.Equal(t, "aiboards-staging-api", cfg.JWTAudience)
+	})
+}
+
+func TestLoadConfig_BoardCache(t *testing.T) {
+	t.Run("Disabled by default", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.False(t, cfg.BoardCacheEnabled)
+		assert.Equal(t, 30*time.Second, cfg.BoardCacheTTL)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Setenv("BOARD_CACHE_ENABLED", "true")
+		t.Setenv("BOARD_CACHE_TTL", "2m")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.True(t, cfg.BoardCacheEnabled)
+		assert.Equal(t, 2*time.Minute, cfg.BoardCacheTTL)
+	})
+
+	t.Run("Zero TTL is rejected when enabled", func(t *testing.T) {
+		t.Setenv("BOARD_CACHE_ENABLED", "true")
+		t.Setenv("BOARD_CACHE_TTL", "0s")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadConfig_AdminRateLimit(t *testing.T) {
+	t.Run("Default exempts admins", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 0, cfg.AdminRateLimit)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Setenv("ADMIN_RATE_LIMIT", "1000")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 1000, cfg.AdminRateLimit)
+	})
+
+	t.Run("Negative limit is rejected", func(t *testing.T) {
+		t.Setenv("ADMIN_RATE_LIMIT", "-1")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadConfig_AuthRateLimit(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 10, cfg.AuthRateLimit)
+		assert.Equal(t, time.Minute, cfg.AuthRateLimitWindow)
+		assert.Empty(t, cfg.TrustedProxies)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Setenv("AUTH_RATE_LIMIT", "5")
+		t.Setenv("AUTH_RATE_LIMIT_WINDOW", "15m")
+		t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8,192.168.1.1")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 5, cfg.AuthRateLimit)
+		assert.Equal(t, 15*time.Minute, cfg.AuthRateLimitWindow)
+		assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1"}, cfg.TrustedProxies)
+	})
+
+	t.Run("Zero limit is rejected", func(t *testing.T) {
+		t.Setenv("AUTH_RATE_LIMIT", "0")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadConfig_RenderRateLimit(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 30, cfg.RenderRateLimit)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Setenv("RENDER_RATE_LIMIT", "5")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 5, cfg.RenderRateLimit)
+	})
+
+	t.Run("Zero is rejected", func(t *testing.T) {
+		t.Setenv("RENDER_RATE_LIMIT", "0")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadConfig_RateLimitStore(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, "memory", cfg.RateLimitStore)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Setenv("RATE_LIMIT_STORE", "database")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, "database", cfg.RateLimitStore)
+	})
+
+	t.Run("Unknown store is rejected", func(t *testing.T) {
+		t.Setenv("RATE_LIMIT_STORE", "redis")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadConfig_QuotaWarningThreshold(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 0.8, cfg.QuotaWarningThreshold)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Setenv("QUOTA_WARNING_THRESHOLD", "0.9")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 0.9, cfg.QuotaWarningThreshold)
+	})
+
+	t.Run("Above one is rejected", func(t *testing.T) {
+		t.Setenv("QUOTA_WARNING_THRESHOLD", "1.5")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadConfig_DefaultAgentDailyLimit(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 5000, cfg.DefaultAgentDailyLimit)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Setenv("DEFAULT_AGENT_DAILY_LIMIT", "200")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 200, cfg.DefaultAgentDailyLimit)
+	})
+
+	t.Run("Zero is rejected", func(t *testing.T) {
+		t.Setenv("DEFAULT_AGENT_DAILY_LIMIT", "0")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadConfig_MaxBoardsPerUser(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 10, cfg.MaxBoardsPerUser)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Setenv("MAX_BOARDS_PER_USER", "0")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 0, cfg.MaxBoardsPerUser)
+	})
+
+	t.Run("Negative is rejected", func(t *testing.T) {
+		t.Setenv("MAX_BOARDS_PER_USER", "-1")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadConfig_ReplyEditWindow(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), cfg.ReplyEditWindow)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Setenv("REPLY_EDIT_WINDOW", "15m")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 15*time.Minute, cfg.ReplyEditWindow)
+	})
+
+	t.Run("Negative is rejected", func(t *testing.T) {
+		t.Setenv("REPLY_EDIT_WINDOW", "-1m")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadConfig_DuplicatePostWindow(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), cfg.DuplicatePostWindow)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Setenv("DUPLICATE_POST_WINDOW", "30s")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Second, cfg.DuplicatePostWindow)
+	})
+
+	t.Run("Negative is rejected", func(t *testing.T) {
+		t.Setenv("DUPLICATE_POST_WINDOW", "-1s")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadConfig_BoardInactivityPeriod(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 90*24*time.Hour, cfg.BoardInactivityPeriod)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Setenv("BOARD_INACTIVITY_PERIOD", "720h")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 30*24*time.Hour, cfg.BoardInactivityPeriod)
+	})
+
+	t.Run("Zero is rejected", func(t *testing.T) {
+		t.Setenv("BOARD_INACTIVITY_PERIOD", "0s")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadConfig_MaxPageSize(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 100, cfg.MaxPageSize)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Setenv("MAX_PAGE_SIZE", "50")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 50, cfg.MaxPageSize)
+	})
+
+	t.Run("Zero is rejected", func(t *testing.T) {
+		t.Setenv("MAX_PAGE_SIZE", "0")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadConfig_RequestTimeout(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Second, cfg.RequestTimeout)
+	})
+
+	t.Run("Zero disables", func(t *testing.T) {
+		t.Setenv("REQUEST_TIMEOUT", "0s")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), cfg.RequestTimeout)
+	})
+
+	t.Run("Negative is rejected", func(t *testing.T) {
+		t.Setenv("REQUEST_TIMEOUT", "-1s")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadConfig_ReservedAgentNames(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Contains(t, cfg.ReservedAgentNames, "admin")
+		assert.Contains(t, cfg.ReservedAgentNames, "system")
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Setenv("RESERVED_AGENT_NAMES", "root,staff")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"root", "staff"}, cfg.ReservedAgentNames)
+	})
+}
+
+func TestLoadConfig_SlowQueryThreshold(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 200*time.Millisecond, cfg.SlowQueryThreshold)
+	})
+
+	t.Run("Zero disables", func(t *testing.T) {
+		t.Setenv("SLOW_QUERY_THRESHOLD", "0s")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), cfg.SlowQueryThreshold)
+	})
+
+	t.Run("Negative is rejected", func(t *testing.T) {
+		t.Setenv("SLOW_QUERY_THRESHOLD", "-1s")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadConfig_SoftDeleteRetention(t *testing.T) {
+	t.Run("Disabled by default", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), cfg.SoftDeleteRetention)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Setenv("SOFT_DELETE_RETENTION", "720h")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, 720*time.Hour, cfg.SoftDeleteRetention)
+	})
+
+	t.Run("Negative is rejected", func(t *testing.T) {
+		t.Setenv("SOFT_DELETE_RETENTION", "-1h")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadConfig_TrustedProxies(t *testing.T) {
+	t.Run("Required in production", func(t *testing.T) {
+		t.Setenv("ENVIRONMENT", "production")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+
+	t.Run("None trusts no proxy", func(t *testing.T) {
+		t.Setenv("ENVIRONMENT", "production")
+		t.Setenv("TRUSTED_PROXIES", "none")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Empty(t, cfg.TrustedProxies)
+	})
+
+	t.Run("Configured in production", func(t *testing.T) {
+		t.Setenv("ENVIRONMENT", "production")
+		t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.0/8"}, cfg.TrustedProxies)
+	})
+}
+
+func TestLoadConfig_TLS(t *testing.T) {
+	t.Run("Disabled by default", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.False(t, cfg.TLSEnabled())
+		assert.Equal(t, 365*24*time.Hour, cfg.HSTSMaxAge)
+	})
+
+	t.Run("Enabled with cert and key", func(t *testing.T) {
+		t.Setenv("TLS_CERT_FILE", "/etc/aiboards/cert.pem")
+		t.Setenv("TLS_KEY_FILE", "/etc/aiboards/key.pem")
+		t.Setenv("HTTP_REDIRECT_PORT", "8081")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.True(t, cfg.TLSEnabled())
+		assert.Equal(t, 8081, cfg.HTTPRedirectPort)
+	})
+
+	t.Run("Cert without key is rejected", func(t *testing.T) {
+		t.Setenv("TLS_CERT_FILE", "/etc/aiboards/cert.pem")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+
+	t.Run("Redirect port without TLS is rejected", func(t *testing.T) {
+		t.Setenv("HTTP_REDIRECT_PORT", "8081")
+
+		_, err := config.LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadConfig_ContentSanitize(t *testing.T) {
+	t.Run("Enabled by default", func(t *testing.T) {
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.True(t, cfg.ContentSanitizeEnabled)
+	})
+
+	t.Run("Can be disabled", func(t *testing.T) {
+		t.Setenv("CONTENT_SANITIZE_ENABLED", "false")
+
+		cfg, err := config.LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.False(t, cfg.ContentSanitizeEnabled)
+	})
+}
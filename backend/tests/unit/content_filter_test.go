@@ -0,0 +1,46 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/garrettallen/aiboards/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlocklistFilter(t *testing.T) {
+	filter := services.NewBlocklistFilter([]string{"spam", "buy followers", "  "}, services.ContentFilterReject)
+
+	tests := []struct {
+		name     string
+		content  string
+		expected services.ContentFilterVerdict
+	}{
+		{name: "Clean", content: "A thoughtful post about transformers", expected: services.ContentAllowed},
+		{name: "BlockedWord", content: "This is spam", expected: services.ContentBlocked},
+		{name: "BlockedPhrase", content: "Click here to buy followers now", expected: services.ContentBlocked},
+		{name: "PhraseAcrossWhitespace", content: "buy\n  followers", expected: services.ContentBlocked},
+		{name: "CaseInsensitive", content: "SPAM and more Spam", expected: services.ContentBlocked},
+		{name: "PhraseCaseInsensitive", content: "Buy Followers", expected: services.ContentBlocked},
+		{name: "WordBoundary", content: "The spammers were banned", expected: services.ContentAllowed},
+		{name: "PartialPhrase", content: "buy groceries, gain followers", expected: services.ContentAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, filter.Check(tt.content))
+		})
+	}
+}
+
+func TestBlocklistFilter_FlagAction(t *testing.T) {
+	filter := services.NewBlocklistFilter([]string{"spam"}, services.ContentFilterFlag)
+
+	assert.Equal(t, services.ContentFlagged, filter.Check("this is spam"))
+	assert.Equal(t, services.ContentAllowed, filter.Check("this is fine"))
+}
+
+func TestBlocklistFilter_EmptyList(t *testing.T) {
+	filter := services.NewBlocklistFilter(nil, services.ContentFilterReject)
+
+	assert.Equal(t, services.ContentAllowed, filter.Check("anything goes"))
+}
@@ -0,0 +1,64 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/garrettallen/aiboards/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTMLSanitizer(t *testing.T) {
+	sanitizer := services.NewHTMLSanitizer()
+
+	t.Run("Script tags are stripped with their content", func(t *testing.T) {
+		assert.Equal(t, "Hello  world", sanitizer.Sanitize(`Hello <script>alert("xss")</script> world`))
+		assert.Equal(t, "Hello ", sanitizer.Sanitize(`Hello <SCRIPT src="https://evil.example/x.js">`))
+		assert.Equal(t, "before after", sanitizer.Sanitize(`before <iframe src="https://evil.example"></iframe>after`))
+	})
+
+	t.Run("Event handlers and script URLs are removed", func(t *testing.T) {
+		assert.Equal(t, `<img src="cat.png">`, sanitizer.Sanitize(`<img src="cat.png" onerror="alert(1)">`))
+		assert.Equal(t, `click`, sanitizer.Sanitize(`<a href="javascript:alert(1)">click</a>`))
+		assert.Equal(t, `[click](#)`, sanitizer.Sanitize(`[click](javascript:void)`))
+	})
+
+	t.Run("Only allowlisted HTML is kept", func(t *testing.T) {
+		assert.Equal(t, `<img src="x">`, sanitizer.Sanitize(`<img/onerror=alert(1) src=x>`))
+		assert.Equal(t, `click`, sanitizer.Sanitize(`<a href="&#106;avascript:alert(1)">click</a>`))
+		assert.Equal(t, `<a href="https://example.com" rel="nofollow">ok</a>`, sanitizer.Sanitize(`<a href="https://example.com">ok</a>`))
+		assert.Equal(t, "text", sanitizer.Sanitize(`<marquee onstart="alert(1)">text</marquee>`))
+	})
+
+	t.Run("Tags rebuilt from removed tags are removed too", func(t *testing.T) {
+		assert.NotContains(t, sanitizer.Sanitize(`<scr<b></b>ipt>alert(1)</script>`), "<script")
+		assert.NotContains(t, sanitizer.Sanitize(`<<script></script>img src=x onerror=alert(1)>`), "onerror")
+	})
+
+	t.Run("Unsafe markdown link targets are replaced whole", func(t *testing.T) {
+		assert.Equal(t, `[x](#)`, sanitizer.Sanitize(`[x](javascript:alert(1))`))
+		assert.Equal(t, `[x](#)`, sanitizer.Sanitize(`[x](&#106;avascript:alert(1))`))
+		assert.Equal(t, `[x](#)`, sanitizer.Sanitize(`[x](javascript\:alert(1))`))
+		assert.Equal(t, `![x](# "title")`, sanitizer.Sanitize(`![x](data:text/html,hi "title")`))
+		assert.Equal(t, `[x](https://example.com/a_(b))`, sanitizer.Sanitize(`[x](https://example.com/a_(b))`))
+	})
+
+	t.Run("Markdown autolinks are kept when safe", func(t *testing.T) {
+		assert.Equal(t, "See <https://example.com> or <someone@example.com>", sanitizer.Sanitize("See <https://example.com> or <someone@example.com>"))
+		assert.Equal(t, "See ", sanitizer.Sanitize("See <javascript:alert(1)>"))
+	})
+
+	t.Run("Markdown is preserved", func(t *testing.T) {
+		markdown := "# Title\n\n**Bold**, _italic_ and a [link](https://example.com).\n\n- one\n- two\n\n> quoted\n\n![cat](https://example.com/cat.png)"
+		assert.Equal(t, markdown, sanitizer.Sanitize(markdown))
+	})
+
+	t.Run("Code is left alone", func(t *testing.T) {
+		code := "Use `<script>` tags like this:\n\n```html\n<script>console.log(1)</script>\n```"
+		assert.Equal(t, code, sanitizer.Sanitize(code))
+	})
+
+	t.Run("Harmless HTML and plain comparisons are kept", func(t *testing.T) {
+		assert.Equal(t, "<b>bold</b> and 1 < 2 > 0", sanitizer.Sanitize("<b>bold</b> and 1 < 2 > 0"))
+		assert.Equal(t, "Who describes javascript: as a language?", sanitizer.Sanitize("Who describes javascript: as a language?"))
+	})
+}
@@ -0,0 +1,60 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/garrettallen/aiboards/backend/internal/models"
+	"github.com/garrettallen/aiboards/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTargetType(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected models.TargetType
+		err      error
+	}{
+		{name: "Post", input: "post", expected: models.TargetTypePost},
+		{name: "Reply", input: "reply", expected: models.TargetTypeReply},
+		{name: "Empty", input: "", err: models.ErrInvalidTargetType},
+		{name: "Unknown", input: "board", err: models.ErrInvalidTargetType},
+		{name: "WrongCase", input: "Post", err: models.ErrInvalidTargetType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetType, err := models.ParseTargetType(tt.input)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.expected, targetType)
+		})
+	}
+
+	// The service error must stay the same value so handlers can match on it
+	assert.Equal(t, services.ErrInvalidTargetType, models.ErrInvalidTargetType)
+}
+
+func TestParseParentType(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected models.ParentType
+		err      error
+	}{
+		{name: "Post", input: "post", expected: models.ParentTypePost},
+		{name: "Reply", input: "reply", expected: models.ParentTypeReply},
+		{name: "Empty", input: "", err: models.ErrInvalidParentType},
+		{name: "Unknown", input: "vote", err: models.ErrInvalidParentType},
+		{name: "Padded", input: " post ", err: models.ErrInvalidParentType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parentType, err := models.ParseParentType(tt.input)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.expected, parentType)
+		})
+	}
+
+	assert.Equal(t, services.ErrInvalidParentType, models.ErrInvalidParentType)
+}
@@ -0,0 +1,40 @@
+package unit
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/garrettallen/aiboards/backend/config"
+	"github.com/garrettallen/aiboards/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMTPSendGivesUpOnUnresponsiveServer(t *testing.T) {
+	// A server that accepts connections but never sends its greeting
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	emailService := services.NewEmailService(&config.Config{SMTPHost: "127.0.0.1", SMTPPort: addr.Port, SMTPFrom: "noreply@example.com"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = emailService.Send(ctx, "someone@example.com", "Subject", "Body")
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,94 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/garrettallen/aiboards/backend/internal/apierror"
+	"github.com/garrettallen/aiboards/backend/internal/handlers"
+	"github.com/garrettallen/aiboards/backend/internal/middleware"
+	"github.com/garrettallen/aiboards/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func performErrorRequest(t *testing.T, router *gin.Engine) (int, apierror.Response) {
+	req := httptest.NewRequest("GET", "/test", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var body apierror.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return w.Code, body
+}
+
+func TestRespondError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string // the error's own message when empty
+	}{
+		{"Agent not found", services.ErrAgentNotFound, http.StatusNotFound, "AGENT_NOT_FOUND", ""},
+		{"Already voted", services.ErrAlreadyVoted, http.StatusConflict, "ALREADY_VOTED", ""},
+		{"Content blocked", services.ErrContentBlocked, http.StatusUnprocessableEntity, "CONTENT_BLOCKED", ""},
+		{"Rate limited", services.ErrAgentRateLimited, http.StatusTooManyRequests, "AGENT_RATE_LIMITED", ""},
+		{"Wrapped sentinel", fmt.Errorf("loading post: %w", services.ErrPostNotFound), http.StatusNotFound, "POST_NOT_FOUND", ""},
+		{"Unknown error", errors.New("connection reset"), http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error"},
+		{"Database error", errors.New(`pq: duplicate key value violates unique constraint "boards_agent_id_key"`), http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/test", func(c *gin.Context) {
+				handlers.RespondError(c, tt.err)
+			})
+
+			status, body := performErrorRequest(t, router)
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.code, body.Error.Code)
+			message := tt.message
+			if message == "" {
+				message = tt.err.Error()
+			}
+			assert.Equal(t, message, body.Error.Message)
+		})
+	}
+}
+
+func TestRespondErrorStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/test", func(c *gin.Context) {
+		handlers.RespondErrorStatus(c, http.StatusBadRequest, "Invalid post ID")
+	})
+
+	status, body := performErrorRequest(t, router)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "BAD_REQUEST", body.Error.Code)
+	assert.Equal(t, "Invalid post ID", body.Error.Message)
+}
+
+func TestMiddlewareErrorEnvelope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/test", middleware.AuthMiddleware(nil), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	status, body := performErrorRequest(t, router)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, "UNAUTHORIZED", body.Error.Code)
+	assert.Equal(t, "Authorization header is required", body.Error.Message)
+}
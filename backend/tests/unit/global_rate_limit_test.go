@@ -0,0 +1,89 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/garrettallen/aiboards/backend/internal/middleware"
+	"github.com/garrettallen/aiboards/backend/internal/models"
+	"github.com/garrettallen/aiboards/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// tokenAuthService maps fixed tokens to users. Methods the rate limit tests don't need
+// fall through to the nil embedded interface.
+type tokenAuthService struct {
+	services.AuthService
+	users map[string]*models.User
+}
+
+func (s *tokenAuthService) GetUserFromToken(tokenString string) (*models.User, error) {
+	if user, ok := s.users[tokenString]; ok {
+		return user, nil
+	}
+	return nil, services.ErrInvalidToken
+}
+
+func setupGlobalRateLimitRouter(limit, adminLimit int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	authService := &tokenAuthService{users: map[string]*models.User{
+		"admin-token": {ID: uuid.New(), IsAdmin: true},
+		"user-token":  {ID: uuid.New()},
+	}}
+
+	router := gin.New()
+	router.Use(middleware.GlobalRateLimiter(middleware.NewMemoryRateLimiter(), limit, adminLimit, authService))
+	router.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func ping(router *gin.Engine, remoteAddr, token string) int {
+	req := httptest.NewRequest("GET", "/ping", nil)
+	req.RemoteAddr = remoteAddr
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestGlobalRateLimiter_Admins(t *testing.T) {
+	t.Run("Regular users are throttled", func(t *testing.T) {
+		router := setupGlobalRateLimitRouter(3, 0)
+
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusOK, ping(router, "203.0.113.1:1234", "user-token"))
+		}
+		assert.Equal(t, http.StatusTooManyRequests, ping(router, "203.0.113.1:1234", "user-token"))
+		assert.Equal(t, http.StatusTooManyRequests, ping(router, "203.0.113.1:1234", ""))
+	})
+
+	t.Run("Admins are exempt by default", func(t *testing.T) {
+		router := setupGlobalRateLimitRouter(3, 0)
+
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, http.StatusOK, ping(router, "203.0.113.2:1234", "admin-token"))
+		}
+
+		// The admin's exemption doesn't extend to others on the same IP
+		assert.Equal(t, http.StatusTooManyRequests, ping(router, "203.0.113.2:1234", "user-token"))
+		assert.Equal(t, http.StatusTooManyRequests, ping(router, "203.0.113.2:1234", "invalid-token"))
+	})
+
+	t.Run("Admins get their own higher limit", func(t *testing.T) {
+		router := setupGlobalRateLimitRouter(3, 5)
+
+		// Three requests fit the IP limit, five more the admin limit
+		for i := 0; i < 8; i++ {
+			assert.Equal(t, http.StatusOK, ping(router, "203.0.113.3:1234", "admin-token"))
+		}
+		assert.Equal(t, http.StatusTooManyRequests, ping(router, "203.0.113.3:1234", "admin-token"))
+	})
+}
@@ -0,0 +1,111 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/garrettallen/aiboards/backend/internal/handlers"
+	"github.com/garrettallen/aiboards/backend/internal/middleware"
+	"github.com/garrettallen/aiboards/backend/internal/models"
+	"github.com/garrettallen/aiboards/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rejectingAuthService fails every login, like an attacker guessing passwords would see.
+// Methods the rate limit tests don't need fall through to the nil embedded interface.
+type rejectingAuthService struct {
+	services.AuthService
+}
+
+func (s *rejectingAuthService) Login(ctx context.Context, email, password string) (*models.User, *services.TokenPair, error) {
+	return nil, nil, services.ErrInvalidCredentials
+}
+
+func setupAuthRateLimitRouter(t *testing.T, limit int, trustedProxies []string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	require.NoError(t, router.SetTrustedProxies(trustedProxies))
+
+	api := router.Group("/api/v1")
+	handlers.NewAuthHandler(&rejectingAuthService{}).RegisterRoutes(api, middleware.IPRateLimiter(middleware.NewMemoryRateLimiter(), "auth", limit, time.Minute))
+
+	return router
+}
+
+func attemptLogin(router *gin.Engine, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+	body := []byte(`{"email":"victim@example.com","password":"guess"}`)
+	req := httptest.NewRequest("POST", "/api/v1/auth/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIPRateLimiter_Login(t *testing.T) {
+	router := setupAuthRateLimitRouter(t, 3, nil)
+
+	for i := 0; i < 3; i++ {
+		w := attemptLogin(router, "203.0.113.1:1234", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+
+	// The attacking IP is now throttled, even from a different port
+	w := attemptLogin(router, "203.0.113.1:5678", "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// Another IP is unaffected
+	w = attemptLogin(router, "198.51.100.7:1234", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestIPRateLimiter_SharedAcrossAuthRoutes(t *testing.T) {
+	router := setupAuthRateLimitRouter(t, 1, nil)
+
+	w := attemptLogin(router, "203.0.113.1:1234", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req := httptest.NewRequest("POST", "/api/v1/auth/signup", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "203.0.113.1:1234"
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestIPRateLimiter_TrustedProxies(t *testing.T) {
+	t.Run("Untrusted peer can't spoof X-Forwarded-For", func(t *testing.T) {
+		router := setupAuthRateLimitRouter(t, 1, nil)
+
+		w := attemptLogin(router, "203.0.113.1:1234", "192.0.2.10")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		// A fresh forwarded address doesn't reset the budget of the real peer
+		w = attemptLogin(router, "203.0.113.1:1234", "192.0.2.11")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	})
+
+	t.Run("Trusted proxy forwards client IPs", func(t *testing.T) {
+		router := setupAuthRateLimitRouter(t, 1, []string{"10.0.0.0/8"})
+
+		w := attemptLogin(router, "10.0.0.2:1234", "192.0.2.10")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		w = attemptLogin(router, "10.0.0.2:1234", "192.0.2.10")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+		// A different client behind the same proxy is unaffected
+		w = attemptLogin(router, "10.0.0.2:1234", "192.0.2.11")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,65 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/garrettallen/aiboards/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdownRenderer(t *testing.T) {
+	renderer := services.NewMarkdownRenderer(services.NewHTMLSanitizer(), services.DefaultMaxPostLength)
+
+	render := func(t *testing.T, content string) string {
+		rendered, err := renderer.Render(content)
+		require.NoError(t, err)
+		return rendered
+	}
+
+	t.Run("Markdown is rendered", func(t *testing.T) {
+		markdown := "# Title\n\n**Bold**, _italic_ and a [link](https://example.com).\n\n- one\n- two\n\n1. first\n\n> quoted\n\n![cat](https://example.com/cat.png)"
+		expected := "<h1>Title</h1>\n" +
+			"<p><strong>Bold</strong>, <em>italic</em> and a <a href=\"https://example.com\" rel=\"nofollow noopener\">link</a>.</p>\n" +
+			"<ul>\n<li>one</li>\n<li>two</li>\n</ul>\n" +
+			"<ol>\n<li>first</li>\n</ol>\n" +
+			"<blockquote>\n<p>quoted</p>\n</blockquote>\n" +
+			"<p><img src=\"https://example.com/cat.png\" alt=\"cat\"></p>\n"
+		assert.Equal(t, expected, render(t, markdown))
+	})
+
+	t.Run("Scripts are stripped", func(t *testing.T) {
+		assert.Equal(t, "<p>Hello  world</p>\n", render(t, `Hello <script>alert("xss")</script> world`))
+		assert.Equal(t, "<p><a href=\"#\" rel=\"nofollow noopener\">click</a></p>\n", render(t, "[click](javascript:void)"))
+	})
+
+	t.Run("Unsafe links are dropped without a sanitizer", func(t *testing.T) {
+		unsanitized := services.NewMarkdownRenderer(nil, 0)
+		rendered, err := unsanitized.Render("[click](javascript:void) <script>x</script>")
+		require.NoError(t, err)
+		assert.Equal(t, "<p>click &lt;script&gt;x&lt;/script&gt;</p>\n", rendered)
+	})
+
+	t.Run("Remaining HTML is escaped", func(t *testing.T) {
+		assert.Equal(t, "<p>&lt;b&gt;bold&lt;/b&gt; &amp; more</p>\n", render(t, "<b>bold</b> & more"))
+		assert.Equal(t, "<p>&lt;img src=&#34;cat.png&#34;&gt;</p>\n", render(t, `<img src="cat.png" onerror="alert(1)">`))
+	})
+
+	t.Run("Code is rendered as text", func(t *testing.T) {
+		assert.Equal(t, "<p>Use <code>&lt;b&gt; **not bold**</code> here</p>\n", render(t, "Use `<b> **not bold**` here"))
+		assert.Equal(t, "<pre><code class=\"language-html\">&lt;script&gt;alert(1)&lt;/script&gt;</code></pre>\n", render(t, "```html\n<script>alert(1)</script>\n```"))
+	})
+
+	t.Run("Emphasis markers in URLs are kept", func(t *testing.T) {
+		assert.Equal(t, "<p><a href=\"https://example.com/_a_/*b*\" rel=\"nofollow noopener\">link</a></p>\n", render(t, "[link](https://example.com/_a_/*b*)"))
+	})
+
+	t.Run("Content is validated like stored posts", func(t *testing.T) {
+		_, err := renderer.Render("   ")
+		assert.Equal(t, services.ErrEmptyContent, err)
+
+		_, err = renderer.Render(strings.Repeat("a", services.DefaultMaxPostLength+1))
+		assert.Equal(t, services.ErrContentTooLong, err)
+	})
+}
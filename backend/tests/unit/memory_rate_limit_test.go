@@ -0,0 +1,38 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/garrettallen/aiboards/backend/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRateLimiterPruneKeepsLongerWindows(t *testing.T) {
+	ctx := context.Background()
+	limiter := middleware.NewMemoryRateLimiter()
+
+	// A key limited to one hit per hour uses up its hit
+	allowed, _, err := limiter.Allow(ctx, "hourly", 1, time.Hour)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	// Enough short-window keys to trigger a sweep, all expired by the time it runs
+	for i := 0; i < 10000; i++ {
+		_, _, err := limiter.Allow(ctx, fmt.Sprintf("short-%d", i), 1, time.Millisecond)
+		require.NoError(t, err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// This call sweeps with a one millisecond window, which mustn't discard the hourly hit
+	_, _, err = limiter.Allow(ctx, "sweeper", 1, time.Millisecond)
+	require.NoError(t, err)
+
+	allowed, retryAfter, err := limiter.Allow(ctx, "hourly", 1, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Greater(t, retryAfter, 59*time.Minute)
+}
@@ -0,0 +1,126 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/garrettallen/aiboards/backend/internal/handlers"
+	"github.com/garrettallen/aiboards/backend/internal/metrics"
+	"github.com/garrettallen/aiboards/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupMetricsRouter(token string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.Metrics())
+	router.GET("/things/:id", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id")})
+	})
+	router.GET("/missing", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	})
+	handlers.NewMetricsHandler(metrics.Default, token).RegisterRoutes(router)
+
+	return router
+}
+
+func scrapeMetrics(t *testing.T, router *gin.Engine, token string) (int, string) {
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code, w.Body.String()
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	router := setupMetricsRouter("")
+
+	before := metrics.HTTPRequestsTotal.Value("GET", "/things/:id", "200")
+	for _, path := range []string{"/things/1", "/things/2", "/missing"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	}
+	assert.Equal(t, before+2, metrics.HTTPRequestsTotal.Value("GET", "/things/:id", "200"))
+
+	code, body := scrapeMetrics(t, router, "")
+	assert.Equal(t, http.StatusOK, code)
+
+	// Requests are labeled by route template, not raw path
+	assert.Contains(t, body, "# TYPE http_requests_total counter")
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/things/:id",status="200"}`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/missing",status="404"}`)
+	assert.NotContains(t, body, `route="/things/1"`)
+
+	assert.Contains(t, body, "# TYPE http_request_duration_seconds histogram")
+	assert.Contains(t, body, `http_request_duration_seconds_bucket{method="GET",route="/things/:id",status="200",le="+Inf"}`)
+	assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/things/:id",status="200"}`)
+	assert.Contains(t, body, "# TYPE http_requests_in_flight gauge")
+
+	// Business counters are always exported
+	assert.Contains(t, body, "# TYPE posts_created_total counter")
+	assert.Contains(t, body, "# TYPE votes_created_total counter")
+}
+
+func TestMetricsUnknownMethod(t *testing.T) {
+	router := setupMetricsRouter("")
+
+	before := metrics.HTTPRequestsTotal.Value("OTHER", "unmatched", "404")
+	for _, method := range []string{"FOO", "BAR"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, "/things/1", nil))
+	}
+
+	// Nonstandard methods share a single label rather than each adding one
+	assert.Equal(t, before+2, metrics.HTTPRequestsTotal.Value("OTHER", "unmatched", "404"))
+	assert.Zero(t, metrics.HTTPRequestsTotal.Value("FOO", "unmatched", "404"))
+}
+
+func TestMetricsEndpointToken(t *testing.T) {
+	router := setupMetricsRouter("scrape-secret")
+
+	code, _ := scrapeMetrics(t, router, "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = scrapeMetrics(t, router, "wrong")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, body := scrapeMetrics(t, router, "scrape-secret")
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, strings.Contains(body, "http_requests_total"))
+}
+
+func TestMetricsRegistryText(t *testing.T) {
+	registry := metrics.NewRegistry()
+	counter := registry.NewCounter("jobs_total", "Jobs processed.", "queue")
+	histogram := registry.NewHistogram("job_seconds", "Job duration.", []float64{0.1, 1})
+
+	counter.Inc("emails")
+	counter.Add(2, `we"ird`)
+	histogram.Observe(0.05)
+	histogram.Observe(0.5)
+	histogram.Observe(5)
+
+	var sb strings.Builder
+	assert.NoError(t, registry.WriteText(&sb))
+
+	expected := `# HELP jobs_total Jobs processed.
+# TYPE jobs_total counter
+jobs_total{queue="emails"} 1
+jobs_total{queue="we\"ird"} 2
+# HELP job_seconds Job duration.
+# TYPE job_seconds histogram
+job_seconds_bucket{le="0.1"} 1
+job_seconds_bucket{le="1"} 2
+job_seconds_bucket{le="+Inf"} 3
+job_seconds_sum 5.55
+job_seconds_count 3
+`
+	assert.Equal(t, expected, sb.String())
+}
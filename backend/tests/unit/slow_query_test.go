@@ -0,0 +1,70 @@
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/garrettallen/aiboards/backend/internal/database/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureLog redirects the standard logger into a buffer for the rest of the test
+func captureLog(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+func TestTimeQuery(t *testing.T) {
+	t.Cleanup(func() { repository.SetSlowQueryThreshold(repository.DefaultSlowQueryThreshold) })
+
+	slowQuery := func() error {
+		time.Sleep(30 * time.Millisecond)
+		return nil
+	}
+
+	t.Run("Slow query is logged", func(t *testing.T) {
+		buf := captureLog(t)
+		repository.SetSlowQueryThreshold(10 * time.Millisecond)
+
+		err := repository.TimeQuery("test.slow", slowQuery)
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), "Slow query test.slow took")
+	})
+
+	t.Run("Fast query is not logged", func(t *testing.T) {
+		buf := captureLog(t)
+		repository.SetSlowQueryThreshold(time.Second)
+
+		err := repository.TimeQuery("test.fast", func() error { return nil })
+		assert.NoError(t, err)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("Zero threshold disables logging", func(t *testing.T) {
+		buf := captureLog(t)
+		repository.SetSlowQueryThreshold(0)
+
+		err := repository.TimeQuery("test.slow", slowQuery)
+		assert.NoError(t, err)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("Error is returned and still logged", func(t *testing.T) {
+		buf := captureLog(t)
+		repository.SetSlowQueryThreshold(10 * time.Millisecond)
+		queryErr := errors.New("query failed")
+
+		err := repository.TimeQuery("test.failing", func() error {
+			_ = slowQuery()
+			return queryErr
+		})
+		assert.Equal(t, queryErr, err)
+		assert.Contains(t, buf.String(), "Slow query test.failing took")
+	})
+}
@@ -0,0 +1,97 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garrettallen/aiboards/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRateLimitStore keeps counters in memory, standing in for the table replicas share
+type fakeRateLimitStore struct {
+	mu       sync.Mutex
+	counters map[string]int
+	err      error
+}
+
+func newFakeRateLimitStore() *fakeRateLimitStore {
+	return &fakeRateLimitStore{counters: make(map[string]int)}
+}
+
+func (s *fakeRateLimitStore) Increment(ctx context.Context, key string, windowStart, expiresAt time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+	counter := key + "@" + windowStart.String()
+	s.counters[counter]++
+	return s.counters[counter], nil
+}
+
+func (s *fakeRateLimitStore) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	return 0, nil
+}
+
+// setupReplicaRouter builds one server replica whose /ping route is limited through store
+func setupReplicaRouter(store middleware.RateLimitStore, limit int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.IPRateLimiter(middleware.NewStoreRateLimiter(store), "ping", limit, time.Hour))
+	router.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestStoreRateLimiter_SharedAcrossInstances(t *testing.T) {
+	store := newFakeRateLimitStore()
+	replicaA := setupReplicaRouter(store, 4)
+	replicaB := setupReplicaRouter(store, 4)
+
+	// Alternate between replicas; the four allowed hits are counted together
+	for i := 0; i < 4; i++ {
+		router := replicaA
+		if i%2 == 1 {
+			router = replicaB
+		}
+		assert.Equal(t, http.StatusOK, ping(router, "203.0.113.1:1234", ""), "hit %d", i+1)
+	}
+
+	// Both replicas now turn the client away
+	assert.Equal(t, http.StatusTooManyRequests, ping(replicaA, "203.0.113.1:1234", ""))
+	assert.Equal(t, http.StatusTooManyRequests, ping(replicaB, "203.0.113.1:1234", ""))
+
+	// Another client has its own budget
+	assert.Equal(t, http.StatusOK, ping(replicaB, "198.51.100.7:1234", ""))
+}
+
+func TestStoreRateLimiter_Retry(t *testing.T) {
+	limiter := middleware.NewStoreRateLimiter(newFakeRateLimitStore())
+
+	allowed, _, err := limiter.Allow(context.Background(), "key", 1, time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, retryAfter, err := limiter.Allow(context.Background(), "key", 1, time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.True(t, retryAfter > 0 && retryAfter <= time.Hour, "retry after %s", retryAfter)
+}
+
+func TestStoreRateLimiter_StoreUnavailable(t *testing.T) {
+	store := newFakeRateLimitStore()
+	store.err = errors.New("connection refused")
+	router := setupReplicaRouter(store, 1)
+
+	// Requests are let through rather than failing while the store is down
+	assert.Equal(t, http.StatusOK, ping(router, "203.0.113.1:1234", ""))
+	assert.Equal(t, http.StatusOK, ping(router, "203.0.113.1:1234", ""))
+}
@@ -0,0 +1,107 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/garrettallen/aiboards/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTimeoutRouter(timeout time.Duration, sawCancel chan<- error) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	// As in main, so handlers can use the gin context as their context
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(middleware.TimeoutWithExemptions(timeout, []string{"/stream"}))
+
+	fast := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+	slow := func(c *gin.Context) {
+		select {
+		case <-time.After(10 * timeout):
+			c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		case <-c.Request.Context().Done():
+			sawCancel <- c.Request.Context().Err()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "query cancelled"})
+		}
+	}
+	// Most handlers pass c itself to services rather than c.Request.Context()
+	slowOnGinContext := func(c *gin.Context) {
+		var ctx context.Context = c
+		select {
+		case <-time.After(10 * timeout):
+			c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		case <-ctx.Done():
+			sawCancel <- ctx.Err()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "query cancelled"})
+		}
+	}
+	sleepy := func(c *gin.Context) {
+		time.Sleep(5 * timeout)
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+	router.GET("/fast", fast)
+	router.GET("/slow", slow)
+	router.GET("/slow-gin-context", slowOnGinContext)
+	router.GET("/sleepy", sleepy)
+	router.GET("/stream", sleepy)
+
+	return router
+}
+
+func TestTimeout(t *testing.T) {
+	sawCancel := make(chan error, 1)
+	router := setupTimeoutRouter(20*time.Millisecond, sawCancel)
+
+	t.Run("Fast request", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/fast", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Context is cancelled", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/slow", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), "Request timed out")
+		assert.True(t, errors.Is(<-sawCancel, context.DeadlineExceeded))
+	})
+
+	t.Run("Gin context is cancelled", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/slow-gin-context", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		select {
+		case err := <-sawCancel:
+			assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		default:
+			t.Fatal("handler did not see the deadline")
+		}
+	})
+
+	t.Run("Handler sleeps past the timeout", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/sleepy", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.NotContains(t, w.Body.String(), `"status"`)
+	})
+
+	t.Run("Exempt route", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/stream", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
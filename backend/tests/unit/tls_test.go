@@ -0,0 +1,87 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/garrettallen/aiboards/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTLSRouter(httpsPort int, hstsMaxAge time.Duration) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.HTTPSRedirect(httpsPort))
+	router.Use(middleware.HSTS(hstsMaxAge))
+	router.GET("/ping", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	router.POST("/ping", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	return router
+}
+
+func TestHSTS(t *testing.T) {
+	t.Run("Sent over TLS", func(t *testing.T) {
+		srv := httptest.NewTLSServer(setupTLSRouter(443, 365*24*time.Hour))
+		defer srv.Close()
+
+		resp, err := srv.Client().Get(srv.URL + "/ping")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "max-age=31536000; includeSubDomains", resp.Header.Get("Strict-Transport-Security"))
+	})
+
+	t.Run("Zero max age disables the header", func(t *testing.T) {
+		srv := httptest.NewTLSServer(setupTLSRouter(443, 0))
+		defer srv.Close()
+
+		resp, err := srv.Client().Get(srv.URL + "/ping")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Strict-Transport-Security"))
+	})
+}
+
+func TestHTTPSRedirect(t *testing.T) {
+	router := setupTLSRouter(8443, time.Hour)
+
+	t.Run("GET is moved permanently", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "http://example.com:8080/ping?x=1", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusMovedPermanently, w.Code)
+		assert.Equal(t, "https://example.com:8443/ping?x=1", w.Header().Get("Location"))
+		assert.Empty(t, w.Header().Get("Strict-Transport-Security"))
+	})
+
+	t.Run("POST keeps its method", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "http://example.com/ping", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+		assert.Equal(t, "https://example.com:8443/ping", w.Header().Get("Location"))
+	})
+
+	t.Run("Default HTTPS port is omitted", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "http://example.com/ping", nil)
+		w := httptest.NewRecorder()
+		setupTLSRouter(443, time.Hour).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusMovedPermanently, w.Code)
+		assert.Equal(t, "https://example.com/ping", w.Header().Get("Location"))
+	})
+}
@@ -1,13 +1,19 @@
 package unit
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/garrettallen/aiboards/backend/internal/middleware"
 	"github.com/garrettallen/aiboards/backend/internal/models"
 	"github.com/garrettallen/aiboards/backend/internal/services"
 	"github.com/garrettallen/aiboards/backend/tests/utils"
+	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetUserByID(t *testing.T) {
@@ -142,3 +148,53 @@ func TestGetUserByID_NotFound(t *testing.T) {
 	assert.Equal(t, services.ErrUserNotFound, err)
 	assert.Nil(t, user, "User should be nil for non-existent ID")
 }
+
+func TestRecordActivity_Throttled(t *testing.T) {
+	// Create test environment
+	env := utils.NewTestEnv(t)
+	defer env.Cleanup()
+
+	// Create a test user with no recorded activity
+	userID, _ := env.CreateTestUser()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ActivityTracker(env.UserService, nil, time.Hour))
+	router.GET("/ping", func(c *gin.Context) {
+		// Load the user as the auth middleware would
+		user, err := env.UserRepository.GetByID(env.Ctx, userID)
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		c.Set("user", user)
+		c.Status(http.StatusOK)
+	})
+
+	ping := func() *time.Time {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/ping", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		user, err := env.UserRepository.GetByID(env.Ctx, userID)
+		require.NoError(t, err)
+		return user.LastActiveAt
+	}
+
+	// The first request stamps activity
+	first := ping()
+	require.NotNil(t, first)
+
+	// An immediately following request is within the interval and doesn't write
+	second := ping()
+	require.NotNil(t, second)
+	assert.True(t, first.Equal(*second))
+
+	// The repository-level guard also refuses a write inside the interval
+	user, err := env.UserRepository.GetByID(env.Ctx, userID)
+	require.NoError(t, err)
+	user.LastActiveAt = nil
+	updated, err := env.UserService.RecordActivity(env.Ctx, user, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, updated)
+}
@@ -0,0 +1,22 @@
+package unit
+
+import (
+	"math"
+	"testing"
+
+	"github.com/garrettallen/aiboards/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVoteWeight(t *testing.T) {
+	t.Run("No karma counts as one vote", func(t *testing.T) {
+		assert.Equal(t, 1.0, services.VoteWeight(0))
+		assert.Equal(t, 1.0, services.VoteWeight(-50))
+	})
+
+	t.Run("Weight grows logarithmically with karma", func(t *testing.T) {
+		assert.InDelta(t, 1+math.Log(101), services.VoteWeight(100), 1e-9)
+		assert.Greater(t, services.VoteWeight(10), services.VoteWeight(1))
+		assert.Greater(t, services.VoteWeight(1000), services.VoteWeight(100))
+	})
+}
@@ -55,13 +55,14 @@ func CreateRegularUserAndGetToken(t *testing.T, env *TestEnv) (string, uuid.UUID
 
 // CreateTestPost creates a test post and returns it
 func CreateTestPost(t *testing.T, env *TestEnv, agentID uuid.UUID) *models.Post {
-	// Create a test board first
+	// Create a test board first; an agent owns at most one board, so reuse it if it exists
 	board := models.NewBoard(agentID, "Test Board", "Test board description")
 
 	// Insert board directly into database
 	query := `
 		INSERT INTO boards (id, agent_id, title, description, is_active, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (agent_id) WHERE deleted_at IS NULL DO NOTHING
 	`
 	_, err := env.DB.Exec(
 		query,
@@ -74,6 +75,8 @@ func CreateTestPost(t *testing.T, env *TestEnv, agentID uuid.UUID) *models.Post
 		board.UpdatedAt,
 	)
 	require.NoError(t, err)
+	err = env.DB.Get(&board.ID, `SELECT id FROM boards WHERE agent_id = $1 AND deleted_at IS NULL`, agentID)
+	require.NoError(t, err)
 
 	// Create a post
 	post := models.NewPost(board.ID, agentID, "Test post content", nil)
@@ -3,6 +3,7 @@ package utils
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -42,6 +43,11 @@ func clearTables(t *testing.T, db *sqlx.DB) {
 		"boards",
 		"notifications",
 		"votes",
+		"webhooks",
+		"webhook_deliveries",
+		"notification_preferences",
+		"audit_logs",
+		"post_attachments",
 		// Add other tables as they are created
 	}
 
@@ -145,3 +151,20 @@ func CreateTestBetaCode(t *testing.T, db *sqlx.DB) string {
 
 	return code
 }
+
+// ExplainQuery returns the planner's text plan for query, with ANALYZE run on the given
+// tables first so the plan reflects freshly seeded data
+func ExplainQuery(t *testing.T, db *sqlx.DB, analyzeTables []string, query string, args ...interface{}) string {
+	for _, table := range analyzeTables {
+		if _, err := db.Exec("ANALYZE " + table); err != nil {
+			t.Fatalf("Failed to analyze table %s: %v", table, err)
+		}
+	}
+
+	var lines []string
+	if err := db.Select(&lines, "EXPLAIN "+query, args...); err != nil {
+		t.Fatalf("Failed to explain query: %v", err)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/garrettallen/aiboards/backend/internal/database"
 	"github.com/garrettallen/aiboards/backend/internal/database/repository"
 	"github.com/garrettallen/aiboards/backend/internal/models"
 	"github.com/garrettallen/aiboards/backend/internal/services"
@@ -16,6 +17,13 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+// JWT settings the test environment's AuthService signs and validates tokens with
+const (
+	TestJWTSecret   = "test-secret-key"
+	TestJWTIssuer   = "aiboards-test"
+	TestJWTAudience = "aiboards-test-api"
+)
+
 // TestEnv provides a complete test environment
 type TestEnv struct {
 	T                  *testing.T
@@ -45,7 +53,7 @@ func NewTestEnv(t *testing.T) *TestEnv {
 	}
 
 	// Connect to the test database
-	db, err := sqlx.Connect("postgres", dbURL)
+	db, err := sqlx.Connect("postgres", database.WithUTCTimeZone(dbURL))
 	if err != nil {
 		t.Fatalf("Failed to connect to test database: %v", err)
 	}
@@ -78,7 +86,7 @@ func NewTestEnv(t *testing.T) *TestEnv {
 	agentRepo := repository.NewAgentRepository(db)
 
 	// Create JWT secret for testing
-	jwtSecret := "test-secret-key"
+	jwtSecret := TestJWTSecret
 
 	// Default token expiration times
 	accessExp := time.Hour
@@ -91,9 +99,11 @@ func NewTestEnv(t *testing.T) *TestEnv {
 		jwtSecret,
 		accessExp,
 		refreshExp,
+		TestJWTIssuer,
+		TestJWTAudience,
 	)
 	userService := services.NewUserService(userRepo)
-	agentService := services.NewAgentService(agentRepo, userRepo)
+	agentService := services.NewAgentService(agentRepo, userRepo, repository.NewBoardRepository(db), repository.NewPostRepository(db), repository.NewReplyRepository(db), repository.NewVoteRepository(db), services.DefaultMaxAgentsPerUser, services.DefaultAgentDailyLimit, 0, nil)
 	betaCodeService := services.NewBetaCodeService(betaCodeRepo, userRepo)
 
 	// Create cleanup functions
@@ -137,20 +147,23 @@ func (e *TestEnv) CreateTestBetaCode() string {
 
 // CreateTestAgent creates a test agent for testing
 func (e *TestEnv) CreateTestAgent(userID uuid.UUID) *models.Agent {
+	apiKey := fmt.Sprintf("test-api-key-%s", uuid.New().String())
 	agent := &models.Agent{
 		ID:          uuid.New(),
 		UserID:      userID,
 		Name:        fmt.Sprintf("Test Agent %s", time.Now().Format("20060102150405")),
 		Description: "Test agent description",
-		APIKey:      fmt.Sprintf("test-api-key-%s", uuid.New().String()),
+		APIKey:      apiKey,
+		APIKeyHash:  models.HashAPIKey(apiKey),
 		DailyLimit:  100,
 		UsedToday:   0,
+		IsActive:    true,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 
 	query := `
-		INSERT INTO agents (id, user_id, name, description, api_key, daily_limit, used_today, created_at, updated_at)
+		INSERT INTO agents (id, user_id, name, description, api_key_hash, daily_limit, used_today, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
@@ -160,7 +173,7 @@ func (e *TestEnv) CreateTestAgent(userID uuid.UUID) *models.Agent {
 		agent.UserID,
 		agent.Name,
 		agent.Description,
-		agent.APIKey,
+		agent.APIKeyHash,
 		agent.DailyLimit,
 		agent.UsedToday,
 		agent.CreatedAt,